@@ -6,8 +6,10 @@
 
 use self::block_device_handler::{VirtioBlkMmioDeviceHandler, VirtioBlkPciDeviceHandler};
 use self::nvdimm_device_handler::VirtioNvdimmDeviceHandler;
-use self::scsi_device_handler::ScsiDeviceHandler;
+use self::scsi_device_handler::{ScsiDeviceHandler, ScsiGenericDeviceHandler};
 use self::vfio_device_handler::{VfioApDeviceHandler, VfioPciDeviceHandler};
+use self::virtio_serial_device_handler::VirtioSerialDeviceHandler;
+use self::vsock_char_device_handler::VsockCharDeviceHandler;
 use crate::pci;
 use crate::sandbox::PciHostGuestMapping;
 use crate::sandbox::Sandbox;
@@ -39,6 +41,8 @@ pub mod network_device_handler;
 pub mod nvdimm_device_handler;
 pub mod scsi_device_handler;
 pub mod vfio_device_handler;
+pub mod virtio_serial_device_handler;
+pub mod vsock_char_device_handler;
 
 pub const BLOCK: &str = "block";
 
@@ -104,6 +108,9 @@ struct DevUpdate {
     // an optional new path to update the device to in the "inner" container
     // specification
     final_path: Option<String>,
+    // an optional guest path bind mounted on the device in the "inner"
+    // container, for the devices which can't be created with mknod
+    bind_source: Option<String>,
 }
 
 impl DevUpdate {
@@ -113,6 +120,13 @@ impl DevUpdate {
             ..DeviceInfo::new(vm_path, true)?.into()
         })
     }
+
+    fn new_bind(vm_path: &str) -> Result<Self> {
+        Ok(DevUpdate {
+            bind_source: Some(vm_path.to_owned()),
+            ..DeviceInfo::new(vm_path, true)?.into()
+        })
+    }
 }
 
 impl From<DeviceInfo> for DevUpdate {
@@ -120,6 +134,7 @@ impl From<DeviceInfo> for DevUpdate {
         DevUpdate {
             info,
             final_path: None,
+            bind_source: None,
         }
     }
 }
@@ -167,8 +182,11 @@ lazy_static! {
             Arc::new(VirtioBlkPciDeviceHandler {}),
             Arc::new(VirtioNvdimmDeviceHandler {}),
             Arc::new(ScsiDeviceHandler {}),
+            Arc::new(ScsiGenericDeviceHandler {}),
             Arc::new(VfioPciDeviceHandler {}),
             Arc::new(VfioApDeviceHandler {}),
+            Arc::new(VirtioSerialDeviceHandler {}),
+            Arc::new(VsockCharDeviceHandler {}),
             #[cfg(target_arch = "s390x")]
             Arc::new(self::block_device_handler::VirtioBlkCcwDeviceHandler {}),
         ];
@@ -488,6 +506,7 @@ fn update_spec_devices(
         .as_mut()
         .ok_or_else(|| anyhow!("Spec didn't contain linux field"))?;
     let mut res_updates = HashMap::<(String, i64, i64), DeviceInfo>::with_capacity(updates.len());
+    let mut binds = Vec::new();
 
     let mut default_devices = Vec::new();
     let linux_devices = linux.devices_mut().as_mut().unwrap_or(&mut default_devices);
@@ -508,6 +527,7 @@ fn update_spec_devices(
                 "guest_major" => update.info.guest_major,
                 "guest_minor" => update.info.guest_minor,
                 "final_path" => update.final_path.as_ref(),
+                "bind_source" => update.bind_source.as_ref(),
             );
 
             specdev.set_major(update.info.guest_major);
@@ -515,6 +535,9 @@ fn update_spec_devices(
             if let Some(final_path) = update.final_path {
                 specdev.set_path(PathBuf::from(&final_path));
             }
+            if let Some(bind_source) = update.bind_source {
+                binds.push((specdev.path().clone(), bind_source));
+            }
 
             if res_updates
                 .insert((devtype, host_major, host_minor), update.info)
@@ -529,6 +552,9 @@ fn update_spec_devices(
         }
     }
 
+    // The bind mounted devices are not created in the container
+    linux_devices.retain(|d| !binds.iter().any(|(path, _)| path == d.path()));
+
     // Make sure we applied all of our updates
     if !updates.is_empty() {
         return Err(anyhow!(
@@ -567,6 +593,16 @@ fn update_spec_devices(
         }
     }
 
+    let mounts = spec.mounts_mut().get_or_insert_with(Vec::new);
+    for (path, source) in binds {
+        let mut mount = oci::Mount::default();
+        mount.set_destination(path);
+        mount.set_typ(Some("bind".to_string()));
+        mount.set_source(Some(PathBuf::from(source)));
+        mount.set_options(Some(vec!["bind".to_string(), "rw".to_string()]));
+        mounts.push(mount);
+    }
+
     Ok(())
 }
 
@@ -1052,6 +1088,70 @@ mod tests {
         assert_eq!(&PathBuf::from(final_path), specdevices[0].path());
     }
 
+    #[test]
+    fn test_update_spec_devices_bind() {
+        let logger = slog::Logger::root(slog::Discard, o!());
+
+        let null_rdev = fs::metadata("/dev/null").unwrap().rdev();
+        let guest_major = stat::major(null_rdev) as i64;
+        let guest_minor = stat::minor(null_rdev) as i64;
+
+        let container_path = "/dev/ttyUSB0";
+        let host_major: i64 = 188;
+        let host_minor: i64 = 0;
+
+        let mut spec = SpecBuilder::default()
+            .linux(
+                LinuxBuilder::default()
+                    .devices(vec![LinuxDeviceBuilder::default()
+                        .path(PathBuf::from(container_path))
+                        .typ(LinuxDeviceType::C)
+                        .major(host_major)
+                        .minor(host_minor)
+                        .build()
+                        .unwrap()])
+                    .resources(
+                        LinuxResourcesBuilder::default()
+                            .devices(vec![LinuxDeviceCgroupBuilder::default()
+                                .typ(LinuxDeviceType::C)
+                                .major(host_major)
+                                .minor(host_minor)
+                                .build()
+                                .unwrap()])
+                            .build()
+                            .unwrap(),
+                    )
+                    .build()
+                    .unwrap(),
+            )
+            .build()
+            .unwrap();
+
+        let vm_path = "/dev/null";
+
+        let res = update_spec_devices(
+            &logger,
+            &mut spec,
+            HashMap::from_iter(vec![(container_path, DevUpdate::new_bind(vm_path).unwrap())]),
+        );
+        assert!(res.is_ok());
+
+        // The device is bind mounted instead of created
+        let specdevices = &spec.linux().as_ref().unwrap().devices().clone().unwrap();
+        assert!(specdevices.is_empty());
+
+        let mounts = spec.mounts().clone().unwrap();
+        assert_eq!(mounts.len(), 1);
+        assert_eq!(&PathBuf::from(container_path), mounts[0].destination());
+        assert_eq!(Some(PathBuf::from(vm_path)), mounts[0].source().clone());
+        assert_eq!(Some("bind".to_string()), mounts[0].typ().clone());
+
+        let resources = spec.linux().as_ref().unwrap().resources().as_ref().unwrap();
+        let devices = resources.devices().as_ref().unwrap();
+        assert_eq!(Some(guest_major), devices[0].major());
+        assert_eq!(Some(guest_minor), devices[0].minor());
+    }
+
     #[test]
     fn test_update_env_pci() {
         let example_map = [
@@ -9,7 +9,7 @@ use crate::linux_abi::*;
 use crate::sandbox::Sandbox;
 use crate::uevent::{wait_for_uevent, Uevent, UeventMatcher};
 use anyhow::{anyhow, Context, Result};
-use kata_types::device::DRIVER_SCSI_TYPE;
+use kata_types::device::{DRIVER_SCSI_GENERIC_TYPE, DRIVER_SCSI_TYPE};
 use protocols::agent::Device;
 use std::fs;
 use std::path::PathBuf;
@@ -17,9 +17,14 @@ use std::sync::Arc;
 use tokio::sync::Mutex;
 use tracing::instrument;
 
+const SCSI_GENERIC: &str = "scsi_generic";
+
 #[derive(Debug)]
 pub struct ScsiDeviceHandler {}
 
+#[derive(Debug)]
+pub struct ScsiGenericDeviceHandler {}
+
 #[async_trait::async_trait]
 impl DeviceHandler for ScsiDeviceHandler {
     #[instrument]
@@ -37,6 +42,23 @@ impl DeviceHandler for ScsiDeviceHandler {
     }
 }
 
+#[async_trait::async_trait]
+impl DeviceHandler for ScsiGenericDeviceHandler {
+    #[instrument]
+    fn driver_types(&self) -> &[&str] {
+        &[DRIVER_SCSI_GENERIC_TYPE]
+    }
+
+    #[instrument]
+    async fn device_handler(&self, device: &Device, ctx: &mut DeviceContext) -> Result<SpecUpdate> {
+        let vm_path = get_scsi_generic_device_name(ctx.sandbox, &device.id).await?;
+
+        Ok(DeviceInfo::new(&vm_path, true)
+            .context("New device info")?
+            .into())
+    }
+}
+
 #[instrument]
 pub async fn get_scsi_device_name(
     sandbox: &Arc<Mutex<Sandbox>>,
@@ -49,6 +71,19 @@ pub async fn get_scsi_device_name(
     Ok(format!("{}/{}", SYSTEM_DEV_PATH, &uev.devname))
 }
 
+/// Get the SCSI generic device, /dev/sgN, of the given SCSI address.
+#[instrument]
+pub async fn get_scsi_generic_device_name(
+    sandbox: &Arc<Mutex<Sandbox>>,
+    scsi_addr: &str,
+) -> Result<String> {
+    let matcher = ScsiGenericMatcher::new(scsi_addr);
+
+    scan_scsi_bus(scsi_addr)?;
+    let uev = wait_for_uevent(sandbox, matcher).await?;
+    Ok(format!("{}/{}", SYSTEM_DEV_PATH, &uev.devname))
+}
+
 // FIXME: This matcher is only correct if the guest has at most one
 // SCSI host.
 #[derive(Debug)]
@@ -70,6 +105,29 @@ impl UeventMatcher for ScsiBlockMatcher {
     }
 }
 
+// FIXME: This matcher is only correct if the guest has at most one
+// SCSI host.
+#[derive(Debug)]
+pub struct ScsiGenericMatcher {
+    search: String,
+}
+
+impl ScsiGenericMatcher {
+    pub fn new(scsi_addr: &str) -> ScsiGenericMatcher {
+        let search = format!(r"/0:0:{scsi_addr}/scsi_generic/");
+
+        ScsiGenericMatcher { search }
+    }
+}
+
+impl UeventMatcher for ScsiGenericMatcher {
+    fn is_match(&self, uev: &Uevent) -> bool {
+        uev.subsystem == SCSI_GENERIC
+            && uev.devpath.contains(&self.search)
+            && !uev.devname.is_empty()
+    }
+}
+
 /// Scan SCSI bus for the given SCSI address(SCSI-Id and LUN)
 #[instrument]
 fn scan_scsi_bus(scsi_addr: &str) -> Result<()> {
@@ -133,4 +191,41 @@ mod tests {
         assert!(!matcher_b.is_match(&uev_a));
         assert!(!matcher_a.is_match(&uev_b));
     }
+
+    #[tokio::test]
+    #[allow(clippy::redundant_clone)]
+    async fn test_scsi_generic_matcher() {
+        let root_bus = create_pci_root_bus_path("00");
+
+        let mut uev_a = crate::uevent::Uevent::default();
+        let addr_a = "0:0";
+        uev_a.action = crate::linux_abi::U_EVENT_ACTION_ADD.to_string();
+        uev_a.subsystem = SCSI_GENERIC.to_string();
+        uev_a.devname = "sg0".to_string();
+        uev_a.devpath = format!(
+            "{root_bus}/0000:00:00.0/virtio0/host0/target0:0:0/0:0:{addr_a}/scsi_generic/sg0"
+        );
+        let matcher_a = ScsiGenericMatcher::new(addr_a);
+
+        let mut uev_b = uev_a.clone();
+        let addr_b = "2:0";
+        uev_b.devname = "sg1".to_string();
+        uev_b.devpath = format!(
+            "{root_bus}/0000:00:00.0/virtio0/host0/target0:0:2/0:0:{addr_b}/scsi_generic/sg1"
+        );
+        let matcher_b = ScsiGenericMatcher::new(addr_b);
+
+        // The block device of a SCSI disk is not its SCSI generic device
+        let mut uev_block = uev_a.clone();
+        uev_block.subsystem = BLOCK.to_string();
+        uev_block.devname = "sda".to_string();
+        uev_block.devpath =
+            format!("{root_bus}/0000:00:00.0/virtio0/host0/target0:0:0/0:0:{addr_a}/block/sda");
+
+        assert!(matcher_a.is_match(&uev_a));
+        assert!(matcher_b.is_match(&uev_b));
+        assert!(!matcher_b.is_match(&uev_a));
+        assert!(!matcher_a.is_match(&uev_b));
+        assert!(!matcher_a.is_match(&uev_block));
+    }
 }
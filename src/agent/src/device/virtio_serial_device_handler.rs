@@ -0,0 +1,130 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+use crate::device::{DeviceContext, DeviceHandler, DeviceInfo, SpecUpdate};
+use crate::linux_abi::{SYSFS_VIRTIO_PORTS_PATH, SYSTEM_DEV_PATH};
+use crate::AGENT_CONFIG;
+use anyhow::{anyhow, Context, Result};
+use kata_types::device::DRIVER_VIRTIO_SERIAL_TYPE;
+use protocols::agent::Device;
+use std::fs;
+use std::io::ErrorKind;
+use tokio::time::Duration;
+use tracing::instrument;
+
+const VIRTIO_PORT_POLL_INTERVAL: Duration = Duration::from_millis(50);
+
+/// Handles the host character devices relayed through a virtio-serial port,
+/// the device id is the name of the port.
+#[derive(Debug)]
+pub struct VirtioSerialDeviceHandler {}
+
+#[async_trait::async_trait]
+impl DeviceHandler for VirtioSerialDeviceHandler {
+    #[instrument]
+    fn driver_types(&self) -> &[&str] {
+        &[DRIVER_VIRTIO_SERIAL_TYPE]
+    }
+
+    #[instrument]
+    async fn device_handler(
+        &self,
+        device: &Device,
+        _ctx: &mut DeviceContext,
+    ) -> Result<SpecUpdate> {
+        let vm_path = get_virtio_port_device_name(&device.id).await?;
+
+        Ok(DeviceInfo::new(&vm_path, true)
+            .context("New device info")?
+            .into())
+    }
+}
+
+/// Get the device of the virtio-serial port named `name`, waiting for the
+/// port to be hot plugged. The port name is only known once the port is
+/// created, so the port is looked up in sysfs rather than from its uevent.
+#[instrument]
+pub async fn get_virtio_port_device_name(name: &str) -> Result<String> {
+    tokio::time::timeout(AGENT_CONFIG.hotplug_timeout, wait_for_virtio_port(name))
+        .await
+        .map_err(|_| {
+            anyhow!(
+                "Timeout after {:?} waiting for virtio-serial port {}",
+                AGENT_CONFIG.hotplug_timeout,
+                name
+            )
+        })?
+}
+
+async fn wait_for_virtio_port(name: &str) -> Result<String> {
+    loop {
+        if let Some(port) = find_virtio_port(SYSFS_VIRTIO_PORTS_PATH, name)? {
+            return Ok(format!("{}/{}", SYSTEM_DEV_PATH, port));
+        }
+        tokio::time::sleep(VIRTIO_PORT_POLL_INTERVAL).await;
+    }
+}
+
+fn find_virtio_port(sysfs_path: &str, name: &str) -> Result<Option<String>> {
+    let entries = match fs::read_dir(sysfs_path) {
+        Ok(entries) => entries,
+        Err(e) if e.kind() == ErrorKind::NotFound => return Ok(None),
+        Err(e) => return Err(e.into()),
+    };
+
+    for entry in entries {
+        let entry = entry?;
+        // The name is empty until the host sends it
+        let port_name = match fs::read_to_string(entry.path().join("name")) {
+            Ok(port_name) => port_name,
+            Err(_) => continue,
+        };
+        if port_name.trim() == name {
+            return Ok(Some(entry.file_name().to_string_lossy().into_owned()));
+        }
+    }
+
+    Ok(None)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use tempfile::tempdir;
+
+    #[test]
+    fn test_find_virtio_port() {
+        let sysfs = tempdir().expect("failed to create tmpdir");
+        let sysfs_path = sysfs.path().to_str().unwrap();
+
+        assert_eq!(find_virtio_port(sysfs_path, "char0").unwrap(), None);
+
+        for (port, name) in [
+            ("vport0p0", ""),
+            ("vport1p1", "char0"),
+            ("vport1p2", "char1\n"),
+        ] {
+            let dir = sysfs.path().join(port);
+            fs::create_dir(&dir).unwrap();
+            fs::write(dir.join("name"), name).unwrap();
+        }
+
+        assert_eq!(
+            find_virtio_port(sysfs_path, "char0").unwrap(),
+            Some("vport1p1".to_string())
+        );
+        assert_eq!(
+            find_virtio_port(sysfs_path, "char1").unwrap(),
+            Some("vport1p2".to_string())
+        );
+        assert_eq!(find_virtio_port(sysfs_path, "char2").unwrap(), None);
+
+        let missing = sysfs.path().join("missing");
+        assert_eq!(
+            find_virtio_port(missing.to_str().unwrap(), "char0").unwrap(),
+            None
+        );
+    }
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+use crate::device::{DevUpdate, DeviceContext, DeviceHandler, SpecUpdate};
+use anyhow::{anyhow, Context, Result};
+use kata_types::device::DRIVER_VSOCK_CHAR_TYPE;
+use lazy_static::lazy_static;
+use nix::fcntl::{self, FcntlArg, FdFlag};
+use nix::pty::openpty;
+use nix::sys::termios::{self, SetArg};
+use nix::unistd;
+use protocols::agent::Device;
+use rustjail::pipestream::PipeStream;
+use std::collections::HashMap;
+use std::os::unix::io::RawFd;
+use tokio::select;
+use tokio::sync::Mutex;
+use tokio_vsock::VsockListener;
+use tracing::instrument;
+
+lazy_static! {
+    // The pseudo terminal standing for the host device of each vsock port
+    static ref VSOCK_CHAR_DEVICES: Mutex<HashMap<u32, String>> = Mutex::new(HashMap::new());
+}
+
+// Convenience function to obtain the scope logger.
+fn sl() -> slog::Logger {
+    slog_scope::logger().new(o!("subsystem" => "vsock_char_device"))
+}
+
+/// Handles the host character devices relayed through a vsock port, the
+/// device id is the port. The device is a pseudo terminal relayed to the
+/// connections the host makes on the port, it is bind mounted in the
+/// container as a pseudo terminal can't be created with mknod.
+#[derive(Debug)]
+pub struct VsockCharDeviceHandler {}
+
+#[async_trait::async_trait]
+impl DeviceHandler for VsockCharDeviceHandler {
+    #[instrument]
+    fn driver_types(&self) -> &[&str] {
+        &[DRIVER_VSOCK_CHAR_TYPE]
+    }
+
+    #[instrument]
+    async fn device_handler(
+        &self,
+        device: &Device,
+        _ctx: &mut DeviceContext,
+    ) -> Result<SpecUpdate> {
+        let port: u32 = device
+            .id
+            .parse()
+            .map_err(|e| anyhow!("invalid vsock port {}: {:?}", device.id, e))?;
+
+        let vm_path = start_vsock_char_device(port).await?;
+
+        Ok(DevUpdate::new_bind(&vm_path)
+            .context("New device update")?
+            .into())
+    }
+}
+
+/// Get the pseudo terminal relayed to the vsock port `port`, creating it and
+/// listening on the port the first time.
+async fn start_vsock_char_device(port: u32) -> Result<String> {
+    let mut devices = VSOCK_CHAR_DEVICES.lock().await;
+    if let Some(path) = devices.get(&port) {
+        return Ok(path.clone());
+    }
+
+    let pseudo = openpty(None, None)?;
+    let _ = fcntl::fcntl(pseudo.master, FcntlArg::F_SETFD(FdFlag::FD_CLOEXEC));
+    let _ = fcntl::fcntl(pseudo.slave, FcntlArg::F_SETFD(FdFlag::FD_CLOEXEC));
+
+    let setup = || -> Result<(String, VsockListener)> {
+        // The data is relayed as is, like the host device does
+        let mut attrs = termios::tcgetattr(pseudo.slave)?;
+        termios::cfmakeraw(&mut attrs);
+        termios::tcsetattr(pseudo.slave, SetArg::TCSANOW, &attrs)?;
+
+        let path = unistd::ttyname(pseudo.slave)?.to_string_lossy().into_owned();
+        let listener = VsockListener::bind(libc::VMADDR_CID_ANY, port)
+            .with_context(|| format!("listen on vsock port {}", port))?;
+
+        Ok((path, listener))
+    };
+    let (path, listener) = match setup() {
+        Ok(res) => res,
+        Err(e) => {
+            let _ = unistd::close(pseudo.master);
+            let _ = unistd::close(pseudo.slave);
+            return Err(e);
+        }
+    };
+
+    info!(sl(), "relay vsock port {} to {}", port, path);
+    tokio::spawn(relay_vsock_char_device(listener, pseudo.master, pseudo.slave, port));
+
+    devices.insert(port, path.clone());
+    Ok(path)
+}
+
+// Relay the pseudo terminal to the connections made on the port. The host
+// connects again when its relay is restarted, so the connections are
+// accepted for as long as the agent runs. The slave is kept open so that
+// the master doesn't hang up when the container closes the device.
+async fn relay_vsock_char_device(
+    mut listener: VsockListener,
+    master: RawFd,
+    _slave: RawFd,
+    port: u32,
+) {
+    let (mut master_reader, mut master_writer) = tokio::io::split(PipeStream::from_fd(master));
+
+    loop {
+        let stream = match listener.accept().await {
+            Ok((stream, _)) => stream,
+            Err(e) => {
+                warn!(sl(), "failed to accept on vsock port {}: {:?}", port, e);
+                continue;
+            }
+        };
+        info!(sl(), "accept connection on vsock port {}", port);
+
+        let (mut stream_reader, mut stream_writer) = tokio::io::split(stream);
+        select! {
+            res = tokio::io::copy(&mut master_reader, &mut stream_writer) => {
+                debug!(sl(), "device closed on vsock port {}: {:?}", port, res);
+            }
+            res = tokio::io::copy(&mut stream_reader, &mut master_writer) => {
+                debug!(sl(), "connection closed on vsock port {}: {:?}", port, res);
+            }
+        }
+    }
+}
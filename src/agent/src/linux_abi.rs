@@ -105,8 +105,10 @@ pub const SYSFS_MEMORY_BLOCK_SIZE_PATH: &str = "/sys/devices/system/memory/block
 pub const SYSFS_MEMORY_HOTPLUG_PROBE_PATH: &str = "/sys/devices/system/memory/probe";
 pub const SYSFS_MEMORY_ONLINE_PATH: &str = "/sys/devices/system/memory";
 
+pub const SYSFS_DEV_BLOCK_PATH: &str = "/sys/dev/block";
 pub const SYSFS_SCSI_HOST_PATH: &str = "/sys/class/scsi_host";
 pub const SYSFS_NET_PATH: &str = "/sys/class/net";
+pub const SYSFS_VIRTIO_PORTS_PATH: &str = "/sys/class/virtio-ports";
 
 pub const SYSFS_BUS_PCI_PATH: &str = "/sys/bus/pci";
 
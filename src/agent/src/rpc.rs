@@ -36,10 +36,11 @@ use oci_spec::runtime as oci;
 use protobuf::MessageDyn;
 use protobuf::MessageField;
 use protocols::agent::{
-    AddSwapPathRequest, AddSwapRequest, AgentDetails, CopyFileRequest, GetIPTablesRequest,
-    GetIPTablesResponse, GuestDetailsResponse, Interfaces, Metrics, OOMEvent, ReadStreamResponse,
-    Routes, SetIPTablesRequest, SetIPTablesResponse, StatsContainerResponse, VolumeStatsRequest,
-    WaitProcessResponse, WriteStreamResponse,
+    AddSwapPathRequest, AddSwapRequest, AgentDetails, CloneDirectoryRequest, CopyFileRequest,
+    GetIPTablesRequest, GetIPTablesResponse, GuestDetailsResponse, Interfaces, Metrics, OOMEvent,
+    ReadStreamResponse, RemovePathRequest, ResizeVolumeRequest, Routes, SetIPTablesRequest,
+    SetIPTablesResponse, StatsContainerResponse, VolumeStatsRequest, WaitProcessResponse,
+    WriteStreamResponse,
 };
 use protocols::csi::{
     volume_usage::Unit as VolumeUsage_Unit, VolumeCondition, VolumeStatsResponse, VolumeUsage,
@@ -107,7 +108,8 @@ use std::process::{Command, Stdio};
 use nix::unistd::{Gid, Uid};
 use std::fs::{File, OpenOptions};
 use std::io::{BufRead, BufReader, Write};
-use std::os::unix::fs::FileExt;
+use std::os::unix::fs::{FileExt, MetadataExt};
+use std::os::unix::io::AsRawFd;
 use std::path::PathBuf;
 
 use kata_types::k8s;
@@ -1604,6 +1606,32 @@ impl agent_ttrpc::AgentService for AgentService {
         Ok(Empty::new())
     }
 
+    async fn clone_directory(
+        &self,
+        ctx: &TtrpcContext,
+        req: protocols::agent::CloneDirectoryRequest,
+    ) -> ttrpc::Result<Empty> {
+        trace_rpc_call!(ctx, "clone_directory", req);
+        is_allowed(&req).await?;
+
+        do_clone_directory(&req).map_ttrpc_err(same)?;
+
+        Ok(Empty::new())
+    }
+
+    async fn remove_path(
+        &self,
+        ctx: &TtrpcContext,
+        req: protocols::agent::RemovePathRequest,
+    ) -> ttrpc::Result<Empty> {
+        trace_rpc_call!(ctx, "remove_path", req);
+        is_allowed(&req).await?;
+
+        do_remove_path(&req).map_ttrpc_err(same)?;
+
+        Ok(Empty::new())
+    }
+
     async fn get_metrics(
         &self,
         ctx: &TtrpcContext,
@@ -1676,6 +1704,26 @@ impl agent_ttrpc::AgentService for AgentService {
         Ok(resp)
     }
 
+    async fn resize_volume(
+        &self,
+        ctx: &TtrpcContext,
+        req: ResizeVolumeRequest,
+    ) -> ttrpc::Result<Empty> {
+        trace_rpc_call!(ctx, "resize_volume", req);
+        is_allowed(&req).await?;
+
+        info!(sl(), "resize volume";
+            "path" => &req.volume_guest_path,
+            "size" => req.size);
+
+        wait_for_volume_device_size(&req.volume_guest_path, req.size)
+            .await
+            .map_ttrpc_err(same)?;
+        resize_volume_fs(&req.volume_guest_path, req.size).map_ttrpc_err(same)?;
+
+        Ok(Empty::new())
+    }
+
     async fn add_swap(
         &self,
         ctx: &TtrpcContext,
@@ -1873,6 +1921,64 @@ fn get_volume_capacity_stats(path: &str) -> Result<VolumeUsage> {
     Ok(usage)
 }
 
+// The block device of a volume is resized by the hypervisor, which notifies
+// the guest kernel asynchronously.
+const VOLUME_RESIZE_TIMEOUT: Duration = Duration::from_secs(10);
+const VOLUME_RESIZE_POLL_INTERVAL: Duration = Duration::from_millis(100);
+
+// EXT4_IOC_RESIZE_FS grows a mounted ext4 filesystem to the given block count.
+nix::ioctl_write_ptr!(ext4_resize_fs, b'f', 16, u64);
+
+fn volume_device_size(path: &str) -> Result<u64> {
+    let dev = stat::stat(path)?.st_dev;
+    let sectors_path = format!(
+        "{}/{}:{}/size",
+        SYSFS_DEV_BLOCK_PATH,
+        stat::major(dev),
+        stat::minor(dev)
+    );
+    let sectors: u64 = fs::read_to_string(&sectors_path)
+        .with_context(|| format!("failed to read {}", sectors_path))?
+        .trim()
+        .parse()
+        .with_context(|| format!("invalid block device size in {}", sectors_path))?;
+
+    // The size is always reported in 512 byte sectors.
+    Ok(sectors * 512)
+}
+
+async fn wait_for_volume_device_size(path: &str, size: u64) -> Result<()> {
+    let wait = async {
+        loop {
+            if volume_device_size(path)? >= size {
+                return Ok(());
+            }
+            tokio::time::sleep(VOLUME_RESIZE_POLL_INTERVAL).await;
+        }
+    };
+
+    timeout(VOLUME_RESIZE_TIMEOUT, wait)
+        .await
+        .map_err(|_| anyhow!("block device of volume {} did not grow to {}", path, size))?
+}
+
+fn resize_volume_fs(path: &str, size: u64) -> Result<()> {
+    let stat = statfs::statfs(path)?;
+    if stat.filesystem_type() != statfs::EXT4_SUPER_MAGIC {
+        return Err(anyhow!(
+            "cannot resize volume {}: only ext4 can be grown online",
+            path
+        ));
+    }
+
+    let blocks = size / stat.block_size() as u64;
+    let dir = File::open(path)?;
+    unsafe { ext4_resize_fs(dir.as_raw_fd(), &blocks) }
+        .with_context(|| format!("failed to grow the filesystem of volume {}", path))?;
+
+    Ok(())
+}
+
 fn get_volume_inode_stats(path: &str) -> Result<VolumeUsage> {
     let mut usage = VolumeUsage::new();
 
@@ -2193,28 +2299,32 @@ fn do_copy_file(req: &CopyFileRequest) -> Result<()> {
 
     // Handle symlink creation
     if sflag.contains(stat::SFlag::S_IFLNK) {
-        // Clean up existing path (whether symlink, dir, or file)
-        if path.exists() || path.is_symlink() {
-            // Use appropriate removal method based on path type
-            if path.is_symlink() {
-                unistd::unlink(&path)?;
-            } else if path.is_dir() {
-                fs::remove_dir_all(&path)?;
-            } else {
-                fs::remove_file(&path)?;
-            }
+        // A directory cannot be replaced by a rename
+        if path.is_dir() && !path.is_symlink() {
+            fs::remove_dir_all(&path)?;
+        }
+
+        // Create the new symbolic link next to the path and rename it over
+        // the path, so that an existing link, like the ..data one of the
+        // kubernetes volumes, is replaced atomically.
+        let mut tmplink = path.clone().into_os_string();
+        tmplink.push(".tmp");
+        let tmplink = PathBuf::from(tmplink);
+        if tmplink.is_symlink() || tmplink.exists() {
+            fs::remove_file(&tmplink)?;
         }
 
-        // Create new symbolic link
         let symlink_target = PathBuf::from(OsStr::from_bytes(&req.data));
-        unistd::symlinkat(&symlink_target, None, &path)?;
+        unistd::symlinkat(&symlink_target, None, &tmplink)?;
 
         // Set symlink ownership (permissions not supported for symlinks)
-        let path_str = CString::new(path.as_os_str().as_bytes())?;
+        let path_str = CString::new(tmplink.as_os_str().as_bytes())?;
 
         let ret = unsafe { libc::lchown(path_str.as_ptr(), req.uid as u32, req.gid as u32) };
         Errno::result(ret).map(drop)?;
 
+        fs::rename(&tmplink, &path)?;
+
         return Ok(());
     }
 
@@ -2256,6 +2366,103 @@ fn do_copy_file(req: &CopyFileRequest) -> Result<()> {
     Ok(())
 }
 
+// Check that the path is strictly below the container base directory, and
+// does not escape it through a parent directory component.
+fn check_container_path(path: &Path) -> Result<()> {
+    if !path.starts_with(CONTAINER_BASE)
+        || path == Path::new(CONTAINER_BASE)
+        || path
+            .components()
+            .any(|c| c == std::path::Component::ParentDir)
+    {
+        return Err(anyhow!("Path {:?} is not below {}", path, CONTAINER_BASE));
+    }
+
+    Ok(())
+}
+
+fn do_clone_directory(req: &CloneDirectoryRequest) -> Result<()> {
+    let path = PathBuf::from(req.path.as_str());
+    let source = PathBuf::from(req.source.as_str());
+
+    check_container_path(&path)?;
+    check_container_path(&source)?;
+
+    if !fs::symlink_metadata(&source)?.is_dir() {
+        return Err(anyhow!("Clone source {:?} is not a directory", source));
+    }
+
+    // The directory is created the way it is copied, then the content of
+    // the source is cloned to it, so that only the files which changed need
+    // to be copied.
+    do_copy_file(&CopyFileRequest {
+        path: req.path.clone(),
+        file_mode: req.file_mode | stat::SFlag::S_IFDIR.bits(),
+        dir_mode: req.dir_mode,
+        uid: req.uid,
+        gid: req.gid,
+        ..Default::default()
+    })?;
+
+    clone_dir(&source, &path)
+}
+
+fn do_remove_path(req: &RemovePathRequest) -> Result<()> {
+    let path = PathBuf::from(req.path.as_str());
+
+    check_container_path(&path)?;
+
+    remove_path(&path)
+}
+
+// Clone the content of the src directory to the dst one, linking the files
+// rather than copying them. The files are later replaced through a rename,
+// never written in place, which leaves the files of src unchanged.
+fn clone_dir(src: &Path, dst: &Path) -> Result<()> {
+    for entry in fs::read_dir(src)? {
+        let entry = entry?;
+        let target = dst.join(entry.file_name());
+
+        if entry.file_type()?.is_dir() {
+            let metadata = entry.metadata()?;
+            fs::create_dir(&target).or_else(|e| {
+                if e.kind() != std::io::ErrorKind::AlreadyExists {
+                    return Err(e);
+                }
+                Ok(())
+            })?;
+            fs::set_permissions(&target, metadata.permissions())?;
+            unistd::chown(
+                &target,
+                Some(Uid::from_raw(metadata.uid())),
+                Some(Gid::from_raw(metadata.gid())),
+            )?;
+
+            clone_dir(&entry.path(), &target)?;
+            continue;
+        }
+
+        remove_path(&target)?;
+        fs::hard_link(entry.path(), &target)?;
+    }
+
+    Ok(())
+}
+
+// Remove the path, whatever its type, if it exists.
+fn remove_path(path: &Path) -> Result<()> {
+    let result = if path.is_dir() && !path.is_symlink() {
+        fs::remove_dir_all(path)
+    } else {
+        fs::remove_file(path)
+    };
+
+    match result {
+        Err(e) if e.kind() == std::io::ErrorKind::NotFound => Ok(()),
+        r => r.map_err(|e| e.into()),
+    }
+}
+
 async fn do_add_swap(sandbox: &Arc<Mutex<Sandbox>>, req: &AddSwapRequest) -> Result<()> {
     let mut slots = Vec::new();
     for slot in &req.PCIPath {
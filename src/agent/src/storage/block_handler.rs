@@ -19,6 +19,7 @@ use kata_types::device::{
 use kata_types::mount::StorageDevice;
 use nix::sys::stat::{major, minor};
 use protocols::agent::Storage;
+use tokio::io::AsyncWriteExt;
 use tracing::instrument;
 
 #[cfg(target_arch = "s390x")]
@@ -31,7 +32,8 @@ use crate::device::block_device_handler::{
 use crate::device::nvdimm_device_handler::wait_for_pmem_device;
 use crate::device::scsi_device_handler::get_scsi_device_name;
 use crate::storage::{
-    common_storage_handler, new_device, set_ownership, StorageContext, StorageHandler,
+    common_storage_handler, new_device, set_ownership, StorageContext, StorageDeviceGeneric,
+    StorageHandler,
 };
 use slog::Logger;
 #[cfg(target_arch = "s390x")]
@@ -58,6 +60,11 @@ async fn handle_block_storage(
         .driver_options
         .contains(&"encryption_key=ephemeral".to_string());
 
+    let key_file = storage
+        .driver_options
+        .iter()
+        .find_map(|o| o.strip_prefix("encryption_key_file="));
+
     if has_ephemeral_encryption {
         crate::rpc::cdh_secure_mount(
             "block-device",
@@ -69,12 +76,123 @@ async fn handle_block_storage(
         .await?;
         set_ownership(logger, storage)?;
         new_device(storage.mount_point.clone())
+    } else if let Some(key_file) = key_file {
+        let mut storage = storage.clone();
+        let name = encrypted_device_name(dev_num);
+        storage.source = open_encrypted_device(logger, &storage.source, &name, key_file).await?;
+        let path = match common_storage_handler(logger, &storage) {
+            Ok(path) => path,
+            Err(e) => {
+                if let Err(err) = close_encrypted_device(&name) {
+                    warn!(
+                        logger,
+                        "failed to close encrypted volume";
+                        "name" => &name,
+                        "error" => ?err
+                    );
+                }
+                return Err(e);
+            }
+        };
+        Ok(Arc::new(EncryptedStorageDevice {
+            device: StorageDeviceGeneric::new(path),
+            name,
+        }))
     } else {
         let path = common_storage_handler(logger, storage)?;
         new_device(path)
     }
 }
 
+// encrypted_device_name returns the device mapper name of the decrypted
+// device of the block device dev_num.
+fn encrypted_device_name(dev_num: &str) -> String {
+    format!("kata-volume-{}", dev_num.replace(':', "-"))
+}
+
+// open_encrypted_device opens the LUKS device with the key the runtime sent
+// in the key file, and returns the path of the decrypted device. The key file
+// is removed once read, the key is not kept in the guest.
+async fn open_encrypted_device(
+    logger: &Logger,
+    device: &str,
+    name: &str,
+    key_file: &str,
+) -> Result<String> {
+    let key = fs::read(key_file).context(format!("read volume key {:?}", key_file));
+    let _ = fs::remove_file(key_file);
+    let key = key?;
+
+    let mapped = format!("/dev/mapper/{}", name);
+    if Path::new(&mapped).exists() {
+        return Ok(mapped);
+    }
+
+    info!(logger, "opening encrypted volume"; "device" => device, "name" => name);
+
+    let mut child = tokio::process::Command::new("cryptsetup")
+        .args(["open", "--type", "luks", "--key-file", "-", device, name])
+        .stdin(std::process::Stdio::piped())
+        .spawn()
+        .context("run cryptsetup")?;
+    if let Some(mut stdin) = child.stdin.take() {
+        stdin.write_all(&key).await.context("write volume key")?;
+    }
+    let status = child.wait().await.context("wait for cryptsetup")?;
+    if !status.success() {
+        return Err(anyhow!(
+            "failed to open encrypted volume {}: {}",
+            device,
+            status
+        ));
+    }
+
+    Ok(mapped)
+}
+
+// close_encrypted_device closes the decrypted device opened by
+// open_encrypted_device.
+fn close_encrypted_device(name: &str) -> Result<()> {
+    if !Path::new(&format!("/dev/mapper/{}", name)).exists() {
+        return Ok(());
+    }
+
+    // StorageDevice::cleanup() is not async, the device is closed synchronously
+    // like the volume is unmounted.
+    let status = std::process::Command::new("cryptsetup")
+        .args(["close", name])
+        .status()
+        .context("run cryptsetup")?;
+    if !status.success() {
+        return Err(anyhow!(
+            "failed to close encrypted volume {}: {}",
+            name,
+            status
+        ));
+    }
+
+    Ok(())
+}
+
+// EncryptedStorageDevice is a volume mounted from a decrypted device, the
+// device is closed once the volume is unmounted.
+#[derive(Debug)]
+struct EncryptedStorageDevice {
+    device: StorageDeviceGeneric,
+    name: String,
+}
+
+impl StorageDevice for EncryptedStorageDevice {
+    fn path(&self) -> Option<&str> {
+        self.device.path()
+    }
+
+    fn cleanup(&self) -> Result<()> {
+        self.device.cleanup()?;
+        close_encrypted_device(&self.name)
+    }
+}
+
 #[derive(Debug)]
 pub struct VirtioBlkMmioHandler {}
 
@@ -7,6 +7,7 @@ package agent_policy
 
 default AddARPNeighborsRequest := true
 default AddSwapRequest := true
+default CloneDirectoryRequest := true
 default CloseStdinRequest := true
 default CopyFileRequest := true
 default CreateContainerRequest := true
@@ -25,6 +26,7 @@ default PauseContainerRequest := true
 default PullImageRequest := true
 default ReadStreamRequest := true
 default RemoveContainerRequest := true
+default RemovePathRequest := true
 default RemoveStaleVirtiofsShareMountsRequest := true
 default ReseedRandomDevRequest := true
 default ResumeContainerRequest := true
@@ -13,8 +13,17 @@ pub const DRIVER_BLK_CCW_TYPE: &str = "blk-ccw";
 pub const DRIVER_BLK_MMIO_TYPE: &str = "mmioblk";
 /// DRIVER_SCSI_TYPE is the device driver for virtio-scsi
 pub const DRIVER_SCSI_TYPE: &str = "scsi";
+/// DRIVER_SCSI_GENERIC_TYPE is the device driver for a SCSI generic device
+/// passed through to the virtio-scsi bus
+pub const DRIVER_SCSI_GENERIC_TYPE: &str = "scsi-generic";
 /// DRIVER_NVDIMM_TYPE is the device driver for nvdimm
 pub const DRIVER_NVDIMM_TYPE: &str = "nvdimm";
+/// DRIVER_VIRTIO_SERIAL_TYPE is the device driver for a host character device
+/// relayed through a virtio-serial port
+pub const DRIVER_VIRTIO_SERIAL_TYPE: &str = "virtio-serial";
+/// DRIVER_VSOCK_CHAR_TYPE is the device driver for a host character device
+/// relayed through a vsock port
+pub const DRIVER_VSOCK_CHAR_TYPE: &str = "vsock-char";
 /// DRIVER_VFIO_PCI_GK_TYPE is the device driver for vfio-pci
 /// while the device will be bound to a guest kernel driver
 pub const DRIVER_VFIO_PCI_GK_TYPE: &str = "vfio-pci-gk";
@@ -0,0 +1,314 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	containerdshim "github.com/kata-containers/kata-containers/src/runtime/pkg/containerd-shim-v2"
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/katautils"
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/utils/shimclient"
+	pbTypes "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/pkg/agent/protocols"
+	"github.com/urfave/cli"
+)
+
+const (
+	networkInterfacesView = "interfaces"
+	networkRoutesView     = "routes"
+	networkNeighborsView  = "neighbors"
+	networkDiffView       = "diff"
+)
+
+// Kinds of drift between the guest network and the host network endpoints.
+const (
+	driftMissingInterface = "missing-interface"
+	driftMACMismatch      = "mac-mismatch"
+	driftMTUMismatch      = "mtu-mismatch"
+	driftMissingAddress   = "missing-address"
+	driftMissingRoute     = "missing-route"
+)
+
+// networkDrift is a difference between the network configuration the host
+// network endpoints expect and the one found in the guest.
+type networkDrift struct {
+	Kind      string `json:"kind"`
+	Interface string `json:"interface"`
+	Expected  string `json:"expected"`
+	Actual    string `json:"actual"`
+}
+
+var kataNetworkCommand = cli.Command{
+	Name:      "network",
+	Usage:     "inspect the network of a Kata Containers sandbox",
+	ArgsUsage: "<sandbox-id> {" + strings.Join([]string{networkInterfacesView, networkRoutesView, networkNeighborsView, networkDiffView}, ",") + "}",
+	Description: `Show the interfaces, routes and ARP neighbors of a sandbox.
+
+   The interfaces and routes are listed from the guest. The guest does not
+   report its ARP neighbors, so the neighbors are the ones the network
+   endpoints configure, as discovered from the host network namespace.
+
+   The diff view compares the guest network with the host network endpoints,
+   and reports missing interfaces, addresses and routes, as well as MAC and
+   MTU mismatches.`,
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "json",
+			Usage: "output in JSON format",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if c.NArg() != 2 {
+			return fmt.Errorf("usage: %s network %s", c.App.Name, c.Command.ArgsUsage)
+		}
+
+		id, view := c.Args().Get(0), c.Args().Get(1)
+
+		// verify sandbox exists:
+		if err := katautils.VerifyContainerID(id); err != nil {
+			return err
+		}
+
+		var result interface{}
+		var err error
+		switch view {
+		case networkInterfacesView:
+			result, err = getGuestInterfaces(id)
+		case networkRoutesView:
+			result, err = getGuestRoutes(id)
+		case networkNeighborsView:
+			var endpoints *containerdshim.NetworkEndpoints
+			if endpoints, err = getNetworkEndpoints(id); err == nil {
+				result = endpoints.Neighbors
+			}
+		case networkDiffView:
+			result, err = getNetworkDrift(id)
+		default:
+			return fmt.Errorf("unknown network view %q", view)
+		}
+		if err != nil {
+			return err
+		}
+
+		if c.Bool("json") {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(result)
+		}
+
+		return writeNetworkView(os.Stdout, result)
+	},
+}
+
+func getGuestInterfaces(sandboxID string) ([]*pbTypes.Interface, error) {
+	body, err := shimclient.DoGet(sandboxID, defaultTimeout, containerdshim.NetworkInterfacesURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var interfaces []*pbTypes.Interface
+	if err := json.Unmarshal(body, &interfaces); err != nil {
+		return nil, fmt.Errorf("failed to decode the guest interfaces: %v", err)
+	}
+	return interfaces, nil
+}
+
+func getGuestRoutes(sandboxID string) ([]*pbTypes.Route, error) {
+	body, err := shimclient.DoGet(sandboxID, defaultTimeout, containerdshim.NetworkRoutesURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var routes []*pbTypes.Route
+	if err := json.Unmarshal(body, &routes); err != nil {
+		return nil, fmt.Errorf("failed to decode the guest routes: %v", err)
+	}
+	return routes, nil
+}
+
+func getNetworkEndpoints(sandboxID string) (*containerdshim.NetworkEndpoints, error) {
+	body, err := shimclient.DoGet(sandboxID, defaultTimeout, containerdshim.NetworkEndpointsURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints containerdshim.NetworkEndpoints
+	if err := json.Unmarshal(body, &endpoints); err != nil {
+		return nil, fmt.Errorf("failed to decode the network endpoints: %v", err)
+	}
+	return &endpoints, nil
+}
+
+func getNetworkDrift(sandboxID string) ([]networkDrift, error) {
+	endpoints, err := getNetworkEndpoints(sandboxID)
+	if err != nil {
+		return nil, err
+	}
+
+	interfaces, err := getGuestInterfaces(sandboxID)
+	if err != nil {
+		return nil, err
+	}
+
+	routes, err := getGuestRoutes(sandboxID)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffNetwork(endpoints, interfaces, routes), nil
+}
+
+// diffNetwork compares the network configuration expected by the host network
+// endpoints with the guest one. Guest interfaces are matched by name, falling
+// back to the MAC address as the guest may have renamed them.
+func diffNetwork(expected *containerdshim.NetworkEndpoints, interfaces []*pbTypes.Interface, routes []*pbTypes.Route) []networkDrift {
+	drifts := []networkDrift{}
+
+	byName := make(map[string]*pbTypes.Interface)
+	byMAC := make(map[string]*pbTypes.Interface)
+	for _, iface := range interfaces {
+		byName[iface.Name] = iface
+		if iface.HwAddr != "" {
+			byMAC[strings.ToLower(iface.HwAddr)] = iface
+		}
+	}
+
+	for _, want := range expected.Interfaces {
+		got, ok := byName[want.Name]
+		if !ok {
+			got, ok = byMAC[strings.ToLower(want.HwAddr)]
+		}
+		if !ok {
+			drifts = append(drifts, networkDrift{
+				Kind:      driftMissingInterface,
+				Interface: want.Name,
+				Expected:  want.HwAddr,
+			})
+			continue
+		}
+
+		if want.HwAddr != "" && !strings.EqualFold(want.HwAddr, got.HwAddr) {
+			drifts = append(drifts, networkDrift{
+				Kind:      driftMACMismatch,
+				Interface: want.Name,
+				Expected:  want.HwAddr,
+				Actual:    got.HwAddr,
+			})
+		}
+
+		if want.Mtu != 0 && want.Mtu != got.Mtu {
+			drifts = append(drifts, networkDrift{
+				Kind:      driftMTUMismatch,
+				Interface: want.Name,
+				Expected:  fmt.Sprint(want.Mtu),
+				Actual:    fmt.Sprint(got.Mtu),
+			})
+		}
+
+		addrs := make(map[string]bool)
+		for _, addr := range got.IPAddresses {
+			addrs[formatIPAddress(addr)] = true
+		}
+		for _, addr := range want.IPAddresses {
+			if !addrs[formatIPAddress(addr)] {
+				drifts = append(drifts, networkDrift{
+					Kind:      driftMissingAddress,
+					Interface: want.Name,
+					Expected:  formatIPAddress(addr),
+				})
+			}
+		}
+	}
+
+	guestRoutes := make(map[string]bool)
+	for _, route := range routes {
+		guestRoutes[routeKey(route)] = true
+	}
+	for _, route := range expected.Routes {
+		if !guestRoutes[routeKey(route)] {
+			drifts = append(drifts, networkDrift{
+				Kind:      driftMissingRoute,
+				Interface: route.Device,
+				Expected:  formatRoute(route),
+			})
+		}
+	}
+
+	return drifts
+}
+
+func routeKey(route *pbTypes.Route) string {
+	return strings.Join([]string{route.Dest, route.Gateway, route.Device}, "|")
+}
+
+func formatIPAddress(addr *pbTypes.IPAddress) string {
+	if addr == nil {
+		return ""
+	}
+	if addr.Mask == "" {
+		return addr.Address
+	}
+	return addr.Address + "/" + addr.Mask
+}
+
+func formatRoute(route *pbTypes.Route) string {
+	dest := route.Dest
+	if dest == "" {
+		dest = "default"
+	}
+	if route.Gateway != "" {
+		return fmt.Sprintf("%s via %s dev %s", dest, route.Gateway, route.Device)
+	}
+	return fmt.Sprintf("%s dev %s", dest, route.Device)
+}
+
+// writeNetworkView writes a network view in a human readable form.
+func writeNetworkView(out io.Writer, view interface{}) error {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+
+	switch v := view.(type) {
+	case []*pbTypes.Interface:
+		fmt.Fprintln(w, "NAME\tMAC\tMTU\tADDRESSES")
+		for _, iface := range v {
+			addrs := make([]string, 0, len(iface.IPAddresses))
+			for _, addr := range iface.IPAddresses {
+				addrs = append(addrs, formatIPAddress(addr))
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", iface.Name, iface.HwAddr, iface.Mtu, strings.Join(addrs, ","))
+		}
+	case []*pbTypes.Route:
+		fmt.Fprintln(w, "DESTINATION\tGATEWAY\tDEVICE\tSOURCE\tSCOPE")
+		for _, route := range v {
+			dest := route.Dest
+			if dest == "" {
+				dest = "default"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n", dest, route.Gateway, route.Device, route.Source, route.Scope)
+		}
+	case []*pbTypes.ARPNeighbor:
+		fmt.Fprintln(w, "ADDRESS\tLLADDR\tDEVICE\tSTATE")
+		for _, neigh := range v {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", formatIPAddress(neigh.ToIPAddress), neigh.Lladdr, neigh.Device, neigh.State)
+		}
+	case []networkDrift:
+		if len(v) == 0 {
+			fmt.Fprintln(w, "No drift between the guest network and the network endpoints")
+			break
+		}
+		fmt.Fprintln(w, "DRIFT\tINTERFACE\tEXPECTED\tACTUAL")
+		for _, drift := range v {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", drift.Kind, drift.Interface, drift.Expected, drift.Actual)
+		}
+	default:
+		return fmt.Errorf("unknown network view type %T", view)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	containerdshim "github.com/kata-containers/kata-containers/src/runtime/pkg/containerd-shim-v2"
+	pbTypes "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/pkg/agent/protocols"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffNetwork(t *testing.T) {
+	assert := assert.New(t)
+
+	expected := &containerdshim.NetworkEndpoints{
+		Interfaces: []*pbTypes.Interface{
+			{
+				Name:        "eth0",
+				HwAddr:      "02:00:ca:fe:00:01",
+				Mtu:         1500,
+				IPAddresses: []*pbTypes.IPAddress{{Address: "10.0.0.2", Mask: "24"}},
+			},
+			{
+				Name:   "eth1",
+				HwAddr: "02:00:ca:fe:00:02",
+				Mtu:    9000,
+			},
+			{
+				Name:   "eth2",
+				HwAddr: "02:00:ca:fe:00:03",
+			},
+		},
+		Routes: []*pbTypes.Route{
+			{Dest: "10.0.0.0/24", Device: "eth0"},
+			{Gateway: "10.0.0.1", Device: "eth0"},
+		},
+	}
+
+	// No drift
+	drifts := diffNetwork(expected, expected.Interfaces, expected.Routes)
+	assert.Empty(drifts)
+
+	interfaces := []*pbTypes.Interface{
+		{
+			Name:   "eth0",
+			HwAddr: "02:00:CA:FE:00:0A",
+			Mtu:    1500,
+		},
+		{
+			// renamed in the guest, matched by MAC
+			Name:   "ens4",
+			HwAddr: "02:00:ca:fe:00:02",
+			Mtu:    1500,
+		},
+	}
+	routes := []*pbTypes.Route{
+		{Dest: "10.0.0.0/24", Device: "eth0"},
+	}
+
+	drifts = diffNetwork(expected, interfaces, routes)
+	assert.Equal([]networkDrift{
+		{Kind: driftMACMismatch, Interface: "eth0", Expected: "02:00:ca:fe:00:01", Actual: "02:00:CA:FE:00:0A"},
+		{Kind: driftMissingAddress, Interface: "eth0", Expected: "10.0.0.2/24"},
+		{Kind: driftMTUMismatch, Interface: "eth1", Expected: "9000", Actual: "1500"},
+		{Kind: driftMissingInterface, Interface: "eth2", Expected: "02:00:ca:fe:00:03"},
+		{Kind: driftMissingRoute, Interface: "eth0", Expected: "default via 10.0.0.1 dev eth0"},
+	}, drifts)
+}
+
+func TestWriteNetworkView(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+	err := writeNetworkView(&out, []*pbTypes.Interface{
+		{
+			Name:        "eth0",
+			HwAddr:      "02:00:ca:fe:00:01",
+			Mtu:         1500,
+			IPAddresses: []*pbTypes.IPAddress{{Address: "10.0.0.2", Mask: "24"}},
+		},
+	})
+	assert.NoError(err)
+	assert.Contains(out.String(), "NAME")
+	assert.Contains(out.String(), "10.0.0.2/24")
+
+	out.Reset()
+	err = writeNetworkView(&out, []*pbTypes.Route{{Gateway: "10.0.0.1", Device: "eth0"}})
+	assert.NoError(err)
+	assert.Contains(out.String(), "default")
+
+	out.Reset()
+	err = writeNetworkView(&out, []networkDrift{})
+	assert.NoError(err)
+	assert.Contains(out.String(), "No drift")
+
+	out.Reset()
+	err = writeNetworkView(&out, []networkDrift{{Kind: driftMissingRoute, Interface: "eth0", Expected: "default via 10.0.0.1 dev eth0"}})
+	assert.NoError(err)
+	assert.Contains(out.String(), driftMissingRoute)
+
+	err = writeNetworkView(&out, "interfaces")
+	assert.Error(err)
+}
@@ -127,6 +127,7 @@ var runtimeCommands = []cli.Command{
 	kataVolumeCommand,
 	kataIPTablesCommand,
 	kataPolicyCommand,
+	kataNetworkCommand,
 }
 
 // runtimeBeforeSubcommands is the function to run before command-line
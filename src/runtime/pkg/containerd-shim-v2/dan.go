@@ -0,0 +1,79 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package containerdshim
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/oci"
+)
+
+// watchDanConfig reloads the DAN config of the sandbox whenever it is
+// updated, so that the network devices it describes get hot plugged or hot
+// unplugged without restarting the pod.
+func watchDanConfig(ctx context.Context, s *service) {
+	if s.sandbox == nil || s.config == nil || s.config.DanConfig == "" {
+		return
+	}
+
+	danConfigPath := filepath.Clean(oci.DanConfigPath(s.config.DanConfig, s.id))
+	if _, err := os.Stat(danConfigPath); err != nil {
+		// The sandbox network is not described by a DAN config.
+		return
+	}
+
+	logger := shimLog.WithField("dan-config", danConfigPath)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		logger.WithError(err).Error("failed to create DAN config watcher")
+		return
+	}
+	defer watcher.Close()
+
+	// Watch the directory rather than the file itself, as the DAN config
+	// is usually updated by renaming a new file over the old one.
+	if err := watcher.Add(filepath.Dir(danConfigPath)); err != nil {
+		logger.WithError(err).Error("failed to watch DAN config")
+		return
+	}
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+
+			// The interfaces of a DAN config removed, or renamed
+			// away, are removed from the sandbox.
+			if filepath.Clean(event.Name) != danConfigPath || event.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Remove|fsnotify.Rename) == 0 {
+				continue
+			}
+
+			logger.WithField("event", event).Info("DAN config changed, reloading")
+
+			s.mu.Lock()
+			err := s.sandbox.ReloadDanConfig(ctx)
+			s.mu.Unlock()
+
+			if err != nil {
+				logger.WithError(err).Error("failed to reload DAN config")
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			logger.WithError(err).Warn("DAN config watcher error")
+		}
+	}
+}
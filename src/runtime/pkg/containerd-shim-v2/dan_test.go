@@ -0,0 +1,110 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package containerdshim
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/oci"
+	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/pkg/vcmock"
+)
+
+func TestWatchDanConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	danConfigDir := t.TempDir()
+	danConfigPath := oci.DanConfigPath(danConfigDir, testSandboxID)
+	assert.NoError(os.WriteFile(danConfigPath, []byte(`{"devices": []}`), 0600))
+
+	reloaded := make(chan struct{}, 10)
+	sandbox := &vcmock.Sandbox{
+		MockID: testSandboxID,
+	}
+	sandbox.ReloadDanConfigFunc = func() error {
+		reloaded <- struct{}{}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := &service{
+		id:      testSandboxID,
+		sandbox: sandbox,
+		config:  &oci.RuntimeConfig{DanConfig: danConfigDir},
+		ctx:     ctx,
+	}
+
+	done := make(chan struct{})
+	go func() {
+		watchDanConfig(ctx, s)
+		close(done)
+	}()
+
+	// Changes to other files are ignored, while an update of the sandbox
+	// DAN config, even through a rename, triggers a reload.
+	update := func() {
+		assert.NoError(os.WriteFile(filepath.Join(danConfigDir, "other.json"), []byte(`{}`), 0600))
+		tmp := filepath.Join(danConfigDir, "dan.tmp")
+		assert.NoError(os.WriteFile(tmp, []byte(`{"devices": []}`), 0600))
+		assert.NoError(os.Rename(tmp, danConfigPath))
+	}
+
+	// The watcher may not be set up yet when the first update happens.
+	deadline := time.After(5 * time.Second)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	update()
+out:
+	for {
+		select {
+		case <-reloaded:
+			break out
+		case <-ticker.C:
+			update()
+		case <-deadline:
+			t.Fatal("DAN config was not reloaded")
+		}
+	}
+
+	// Removing the DAN config triggers a reload too, which removes its
+	// interfaces.
+	for len(reloaded) > 0 {
+		<-reloaded
+	}
+	assert.NoError(os.Remove(danConfigPath))
+	select {
+	case <-reloaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("DAN config was not reloaded once removed")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("DAN config watcher did not stop")
+	}
+}
+
+func TestWatchDanConfigNoConfig(t *testing.T) {
+	s := &service{
+		id:      testSandboxID,
+		sandbox: &vcmock.Sandbox{MockID: testSandboxID},
+		config:  &oci.RuntimeConfig{DanConfig: t.TempDir()},
+		ctx:     context.Background(),
+	}
+
+	// Without a DAN config for the sandbox, there is nothing to watch.
+	watchDanConfig(context.Background(), s)
+}
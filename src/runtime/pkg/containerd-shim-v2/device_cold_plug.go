@@ -41,21 +41,25 @@ func coldPlugDevices(ctx context.Context, s *service, ociSpec *specs.Spec) error
 
 	kubeletSock := s.config.PodResourceAPISock
 	if kubeletSock != "" {
-		return coldPlugWithAPI(ctx, s, ociSpec)
+		if err := coldPlugWithAPI(ctx, s, ociSpec); err != nil {
+			return err
+		}
+	} else {
+		shimLog.Debug("config.PodResourceAPISock not set, skip k8s based device cold plug")
+
+		// Here we deal with CDI devices that are cold-plugged
+		// for the single_container (nerdctl, podman, ...) use-case.
+		// We can provide additional directories where to search for
+		// CDI specs if needed. immutable OS's only have specific
+		// directories where applications can write too. For instance /opt/cdi
+		_, err := config.WithCDI(ociSpec.Annotations, []string{}, ociSpec)
+		if err != nil {
+			return fmt.Errorf("CDI device injection failed: %w", err)
+		}
 	}
 
-	shimLog.Debug("config.PodResourceAPISock not set, skip k8s based device cold plug")
-
-	// Here we deal with CDI devices that are cold-plugged
-	// for the single_container (nerdctl, podman, ...) use-case.
-	// We can provide additional directories where to search for
-	// CDI specs if needed. immutable OS's only have specific
-	// directories where applications can write too. For instance /opt/cdi
-	_, err := config.WithCDI(ociSpec.Annotations, []string{}, ociSpec)
-	if err != nil {
-		return fmt.Errorf("CDI device injection failed: %w", err)
-	}
-	return nil
+	// Fail before the VM is created if a device cannot be cold plugged
+	return preflightColdPlugDevices(s.config.HypervisorConfig, ociSpec)
 }
 
 func coldPlugWithAPI(ctx context.Context, s *service, ociSpec *specs.Spec) error {
@@ -0,0 +1,155 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package containerdshim
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	pkgDevice "github.com/kata-containers/kata-containers/src/runtime/pkg/device"
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/drivers"
+	deviceManager "github.com/kata-containers/kata-containers/src/runtime/pkg/device/manager"
+	vc "github.com/kata-containers/kata-containers/src/runtime/virtcontainers"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// errPCIePortCapacity means that the cold plugged devices do not fit on the
+// PCIe ports of the VM.
+var errPCIePortCapacity = vc.ErrPCIePortCapacity
+
+// coldPlugDeviceError is the reason a cold plugged device cannot be passed
+// through to the VM.
+type coldPlugDeviceError struct {
+	// Path is the path of the device in the container
+	Path string
+	// HostPath is the path of the device on the host
+	HostPath string
+	// Err is the reason, wrapping one of the drivers.ErrVFIO* or
+	// drivers.ErrIOMMUGroupInconsistent errors
+	Err error
+}
+
+func (e *coldPlugDeviceError) Error() string {
+	return fmt.Sprintf("cold plug: device %s (host %s): %v", e.Path, e.HostPath, e.Err)
+}
+
+func (e *coldPlugDeviceError) Unwrap() error {
+	return e.Err
+}
+
+// preflightColdPlugDevices validates the VFIO devices resolved from CDI before
+// the VM is created, so that a device which cannot be passed through fails the
+// sandbox creation with its reason instead of the VM boot. All the devices are
+// validated, a coldPlugDeviceError is returned for each failing device. The
+// devices are looked up under the HostSysfsRoot of the hypervisor config.
+func preflightColdPlugDevices(hypervisorConfig vc.HypervisorConfig, ociSpec *specs.Spec) error {
+	if ociSpec.Linux == nil {
+		return nil
+	}
+
+	var errs []error
+	var pcieDevices uint32
+
+	// The devices are checked before the sandbox device manager exists,
+	// through the sysfs it will be created with.
+	sysfs := hypervisorConfig.HostSysfs()
+
+	for _, d := range ociSpec.Linux.Devices {
+		if !deviceManager.IsVFIODevice(d.Path) {
+			continue
+		}
+
+		hostPath, bdfs, err := preflightVFIODevice(sysfs, d, hypervisorConfig.VFIOAutoBind)
+		if err != nil {
+			errs = append(errs, &coldPlugDeviceError{
+				Path:     d.Path,
+				HostPath: hostPath,
+				Err:      err,
+			})
+			continue
+		}
+
+		for _, bdf := range bdfs {
+			if drivers.IsPCIeDevice(sysfs, bdf) {
+				pcieDevices++
+			}
+		}
+	}
+
+	if err := checkPCIePortCapacity(hypervisorConfig, pcieDevices); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// preflightVFIODevice checks that a VFIO device exists and that its IOMMU
+// group can be passed through, and returns its host path and the BDFs of the
+// PCI devices passed through with it.
+func preflightVFIODevice(sysfs config.SysfsProvider, d specs.LinuxDevice, bind bool) (string, []string, error) {
+	hostPath, err := config.GetHostPath(sysfs, config.DeviceInfo{
+		ContainerPath: d.Path,
+		DevType:       d.Type,
+		Major:         d.Major,
+		Minor:         d.Minor,
+	}, false, "")
+	if err != nil {
+		return d.Path, nil, fmt.Errorf("%w: %v", drivers.ErrVFIODeviceNotFound, err)
+	}
+
+	// There is one PCI device per IOMMUFD device, which only exists once
+	// the device is bound to vfio-pci.
+	if strings.HasPrefix(hostPath, pkgDevice.IommufdDevPath) {
+		if _, err := sysfs.Stat(hostPath); err != nil {
+			return hostPath, nil, fmt.Errorf("%w: %v", drivers.ErrVFIODeviceNotFound, err)
+		}
+
+		bdf, err := drivers.GetBDFFromVFIODev(sysfs, uint32(d.Major), uint32(d.Minor))
+		if err != nil {
+			return hostPath, nil, fmt.Errorf("%w: %v", drivers.ErrVFIODeviceNotFound, err)
+		}
+
+		group, err := drivers.GetIOMMUGroup(sysfs, bdf)
+		if err != nil {
+			return hostPath, nil, err
+		}
+
+		if _, err := drivers.CheckIOMMUGroup(sysfs, group, false); err != nil {
+			return hostPath, nil, err
+		}
+
+		return hostPath, []string{bdf}, nil
+	}
+
+	// The VFIO group only exists once bound to vfio-pci, which is done
+	// when attaching it if bind is set.
+	if bind {
+		group, err := drivers.ResolveIOMMUGroup(sysfs, hostPath)
+		if err != nil {
+			return hostPath, nil, err
+		}
+
+		bdfs, err := drivers.CheckIOMMUGroup(sysfs, group, bind)
+		return hostPath, bdfs, err
+	}
+
+	if _, err := sysfs.Stat(hostPath); err != nil {
+		return hostPath, nil, fmt.Errorf("%w: %v", drivers.ErrVFIODeviceNotFound, err)
+	}
+
+	bdfs, err := drivers.CheckIOMMUGroup(sysfs, filepath.Base(hostPath), bind)
+	return hostPath, bdfs, err
+}
+
+// checkPCIePortCapacity checks that the PCIe devices fit on the ports they are
+// cold plugged to.
+func checkPCIePortCapacity(hypervisorConfig vc.HypervisorConfig, pcieDevices uint32) error {
+	_, err := vc.PlanPCIePorts(&hypervisorConfig, hypervisorConfig.ColdPlugVFIO, pcieDevices)
+	return err
+}
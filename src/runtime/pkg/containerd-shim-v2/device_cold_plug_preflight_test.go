@@ -0,0 +1,131 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package containerdshim
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/drivers"
+	vc "github.com/kata-containers/kata-containers/src/runtime/virtcontainers"
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupPreflightSysfs builds a host tree with the VFIO groups 12, holding a
+// NIC bound to its host driver, and 17, holding a GPU bound to vfio-pci. The
+// group 15 has a device node but no group in sysfs.
+func setupPreflightSysfs(t *testing.T) string {
+	root := t.TempDir()
+
+	write := func(file, content string) {
+		assert.NoError(t, os.MkdirAll(filepath.Join(root, filepath.Dir(file)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(root, file), []byte(content), 0644))
+	}
+	link := func(target, name string) {
+		assert.NoError(t, os.MkdirAll(filepath.Join(root, filepath.Dir(name)), 0755))
+		assert.NoError(t, os.Symlink(target, filepath.Join(root, name)))
+	}
+
+	for group, dev := range map[string]struct{ bdf, class, driver string }{
+		"12": {"0000:01:00.0", "0x020000", "i40e"},
+		"17": {"0000:04:00.0", "0x030200", "vfio-pci"},
+	} {
+		sysfsDev := "/sys/devices/pci0000:00/0000:00:01.0/" + dev.bdf
+		write(sysfsDev+"/config", strings.Repeat("\x00", 4096))
+		write(sysfsDev+"/class", dev.class+"\n")
+		link("../../../../bus/pci/drivers/"+dev.driver, sysfsDev+"/driver")
+		link("../../../devices/pci0000:00/0000:00:01.0/"+dev.bdf, "/sys/bus/pci/devices/"+dev.bdf)
+		link("../../../../devices/pci0000:00/0000:00:01.0/"+dev.bdf, "/sys/kernel/iommu_groups/"+group+"/devices/"+dev.bdf)
+		write("/dev/vfio/"+group, "")
+	}
+	write("/dev/vfio/15", "")
+
+	return root
+}
+
+func vfioSpec(groups ...string) *specs.Spec {
+	spec := &specs.Spec{Linux: &specs.Linux{}}
+	for _, group := range groups {
+		spec.Linux.Devices = append(spec.Linux.Devices, specs.LinuxDevice{
+			Path:  "/dev/vfio/" + group,
+			Type:  "c",
+			Major: 243,
+			Minor: 0,
+		})
+	}
+	return spec
+}
+
+func TestPreflightColdPlugDevices(t *testing.T) {
+	assert := assert.New(t)
+
+	root := setupPreflightSysfs(t)
+
+	hypervisorConfig := vc.HypervisorConfig{ColdPlugVFIO: config.RootPort, HostSysfsRoot: root}
+
+	// The VFIO control device is not passed through
+	assert.NoError(preflightColdPlugDevices(hypervisorConfig, vfioSpec("vfio", "17")))
+	assert.NoError(preflightColdPlugDevices(hypervisorConfig, &specs.Spec{}))
+
+	err := preflightColdPlugDevices(hypervisorConfig, vfioSpec("12", "15", "17", "18"))
+	assert.Error(err)
+
+	// Each failing device has its own error
+	var devErrs []*coldPlugDeviceError
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var devErr *coldPlugDeviceError
+		assert.True(errors.As(e, &devErr))
+		devErrs = append(devErrs, devErr)
+	}
+	assert.Len(devErrs, 3)
+	assert.Equal("/dev/vfio/12", devErrs[0].Path)
+	assert.ErrorIs(devErrs[0], drivers.ErrVFIODeviceNotBound)
+	assert.Equal("/dev/vfio/15", devErrs[1].Path)
+	assert.ErrorIs(devErrs[1], drivers.ErrIOMMUGroupInconsistent)
+	assert.Equal("/dev/vfio/18", devErrs[2].Path)
+	assert.ErrorIs(devErrs[2], drivers.ErrVFIODeviceNotFound)
+
+	// The groups are bound to vfio-pci when attached
+	hypervisorConfig.VFIOAutoBind = true
+	assert.NoError(preflightColdPlugDevices(hypervisorConfig, vfioSpec("12", "17")))
+
+	// The devices are looked up in the sysfs of each configuration only
+	hypervisorConfig.VFIOAutoBind = false
+	hypervisorConfig.HostSysfsRoot = t.TempDir()
+	err = preflightColdPlugDevices(hypervisorConfig, vfioSpec("17"))
+	assert.ErrorIs(err, drivers.ErrVFIODeviceNotFound)
+}
+
+func TestCheckPCIePortCapacity(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, tc := range []struct {
+		hypervisorConfig vc.HypervisorConfig
+		pcieDevices      uint32
+		fits             bool
+	}{
+		{vc.HypervisorConfig{ColdPlugVFIO: config.RootPort}, config.MaxPCIeRootPort, true},
+		{vc.HypervisorConfig{ColdPlugVFIO: config.RootPort}, config.MaxPCIeRootPort + 1, false},
+		{vc.HypervisorConfig{ColdPlugVFIO: config.RootPort, PCIeRootPort: config.MaxPCIeRootPort + 1}, 1, false},
+		{vc.HypervisorConfig{ColdPlugVFIO: config.SwitchPort, PCIeSwitchPort: 4}, config.MaxPCIeSwitchPort, true},
+		{vc.HypervisorConfig{ColdPlugVFIO: config.SwitchPort}, config.MaxPCIeSwitchPort + 1, false},
+		{vc.HypervisorConfig{ColdPlugVFIO: config.RootPort, HotPlugVFIO: config.RootPort, PCIeRootPort: 4}, config.MaxPCIeRootPort, true},
+		{vc.HypervisorConfig{ColdPlugVFIO: config.RootPort, HotPlugVFIO: config.RootPort, PCIeRootPort: 4}, config.MaxPCIeRootPort + 1, false},
+		{vc.HypervisorConfig{ColdPlugVFIO: config.BridgePort}, config.MaxPCIeRootPort + 1, true},
+	} {
+		err := checkPCIePortCapacity(tc.hypervisorConfig, tc.pcieDevices)
+		if tc.fits {
+			assert.NoError(err, "%+v", tc)
+		} else {
+			assert.ErrorIs(err, errPCIePortCapacity, "%+v", tc)
+		}
+	}
+}
@@ -338,6 +338,8 @@ func getTopic(e interface{}) string {
 		return cdruntime.TaskResumedEventTopic
 	case *eventstypes.TaskCheckpointed:
 		return cdruntime.TaskCheckpointedEventTopic
+	case *TaskRootfsUnhealthy:
+		return TaskRootfsUnhealthyEventTopic
 	default:
 		shimLog.WithField("event-type", e).Warn("no topic for event type")
 	}
@@ -24,6 +24,7 @@ import (
 	cdshim "github.com/containerd/containerd/runtime/v2/shim"
 	mutils "github.com/kata-containers/kata-containers/src/runtime/pkg/utils"
 	vc "github.com/kata-containers/kata-containers/src/runtime/virtcontainers"
+	pbTypes "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/pkg/agent/protocols"
 	vcAnnotations "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/pkg/annotations"
 	"github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/prometheus/client_golang/prometheus"
@@ -41,6 +42,11 @@ const (
 	PolicyURL             = "/policy"
 	IP6TablesURL          = "/ip6tables"
 	MetricsURL            = "/metrics"
+	NetworkBandwidthURL   = "/network/bandwidth"
+	NetworkInterfacesURL  = "/network/interfaces"
+	NetworkRoutesURL      = "/network/routes"
+	NetworkEndpointsURL   = "/network/endpoints"
+	PCIeTopologyURL       = "/pcie-topology"
 )
 
 var (
@@ -53,6 +59,22 @@ type ResizeRequest struct {
 	Size       uint64
 }
 
+// BandwidthRequest changes the bandwidth limits of a sandbox network
+// interface, in bits per second. A zero rate removes the limit.
+type BandwidthRequest struct {
+	Interface string
+	Ingress   uint64
+	Egress    uint64
+}
+
+// NetworkEndpoints is the guest network configuration the sandbox network
+// endpoints are expected to set up, as discovered from the host.
+type NetworkEndpoints struct {
+	Interfaces []*pbTypes.Interface   `json:"interfaces"`
+	Routes     []*pbTypes.Route       `json:"routes"`
+	Neighbors  []*pbTypes.ARPNeighbor `json:"neighbors"`
+}
+
 // agentURL returns URL for agent
 func (s *service) agentURL(w http.ResponseWriter, r *http.Request) {
 	url, err := s.sandbox.GetAgentURL()
@@ -200,6 +222,156 @@ func (s *service) serveVolumeResize(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(""))
 }
 
+func (s *service) networkBandwidthHandler(w http.ResponseWriter, r *http.Request) {
+	logger := shimMgtLog.WithFields(logrus.Fields{"handler": "network-bandwidth"})
+
+	switch r.Method {
+	case http.MethodPut:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			logger.WithError(err).Error("failed to read request body")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		var bandwidthReq BandwidthRequest
+		if err = json.Unmarshal(body, &bandwidthReq); err != nil {
+			logger.WithError(err).Error("failed to unmarshal the http request body")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		bandwidth := vc.NetworkBandwidth{
+			Ingress: bandwidthReq.Ingress,
+			Egress:  bandwidthReq.Egress,
+		}
+		// The endpoints are changed by the shim service calls
+		s.mu.Lock()
+		err = s.sandbox.UpdateInterfaceBandwidth(context.Background(), bandwidthReq.Interface, bandwidth)
+		s.mu.Unlock()
+		if err != nil {
+			logger.WithError(err).WithField("interface", bandwidthReq.Interface).Error("failed to update the network bandwidth")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		w.Write([]byte(""))
+
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+}
+
+// writeJSON encodes the handler response as JSON.
+func writeJSON(w http.ResponseWriter, logger *logrus.Entry, v interface{}) {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		logger.WithError(err).Error("failed to marshal the response")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(buf)
+}
+
+func (s *service) networkInterfacesHandler(w http.ResponseWriter, r *http.Request) {
+	logger := shimMgtLog.WithFields(logrus.Fields{"handler": "network-interfaces"})
+
+	switch r.Method {
+	case http.MethodGet:
+		s.mu.Lock()
+		interfaces, err := s.sandbox.ListInterfaces(context.Background())
+		s.mu.Unlock()
+		if err != nil {
+			logger.WithError(err).Error("failed to list the guest interfaces")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		writeJSON(w, logger, interfaces)
+
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+}
+
+func (s *service) networkRoutesHandler(w http.ResponseWriter, r *http.Request) {
+	logger := shimMgtLog.WithFields(logrus.Fields{"handler": "network-routes"})
+
+	switch r.Method {
+	case http.MethodGet:
+		s.mu.Lock()
+		routes, err := s.sandbox.ListRoutes(context.Background())
+		s.mu.Unlock()
+		if err != nil {
+			logger.WithError(err).Error("failed to list the guest routes")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		writeJSON(w, logger, routes)
+
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+}
+
+func (s *service) networkEndpointsHandler(w http.ResponseWriter, r *http.Request) {
+	logger := shimMgtLog.WithFields(logrus.Fields{"handler": "network-endpoints"})
+
+	switch r.Method {
+	case http.MethodGet:
+		// The endpoints are changed by the shim service calls
+		s.mu.Lock()
+		interfaces, routes, neighbors, err := s.sandbox.ListNetworkEndpoints(context.Background())
+		s.mu.Unlock()
+		if err != nil {
+			logger.WithError(err).Error("failed to list the network endpoints")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		writeJSON(w, logger, NetworkEndpoints{
+			Interfaces: interfaces,
+			Routes:     routes,
+			Neighbors:  neighbors,
+		})
+
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+}
+
+func (s *service) pcieTopologyHandler(w http.ResponseWriter, r *http.Request) {
+	logger := shimMgtLog.WithFields(logrus.Fields{"handler": "pcie-topology"})
+
+	switch r.Method {
+	case http.MethodGet:
+		// The devices are plugged to the ports by the shim service calls
+		s.mu.Lock()
+		topology, err := s.sandbox.PCIeTopology(context.Background())
+		s.mu.Unlock()
+		if err != nil {
+			logger.WithError(err).Error("failed to get the PCIe topology")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		writeJSON(w, logger, topology)
+
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+}
+
 func (s *service) policyHandler(w http.ResponseWriter, r *http.Request) {
 	logger := shimMgtLog.WithFields(logrus.Fields{"handler": "policy"})
 
@@ -295,6 +467,11 @@ func (s *service) startManagementServer(ctx context.Context, ociSpec *specs.Spec
 	m.Handle(IPTablesURL, http.HandlerFunc(s.ipTablesHandler))
 	m.Handle(PolicyURL, http.HandlerFunc(s.policyHandler))
 	m.Handle(IP6TablesURL, http.HandlerFunc(s.ip6TablesHandler))
+	m.Handle(NetworkBandwidthURL, http.HandlerFunc(s.networkBandwidthHandler))
+	m.Handle(NetworkInterfacesURL, http.HandlerFunc(s.networkInterfacesHandler))
+	m.Handle(NetworkRoutesURL, http.HandlerFunc(s.networkRoutesHandler))
+	m.Handle(NetworkEndpointsURL, http.HandlerFunc(s.networkEndpointsHandler))
+	m.Handle(PCIeTopologyURL, http.HandlerFunc(s.pcieTopologyHandler))
 	s.mountPprofHandle(m, ociSpec)
 
 	// register shim metrics
@@ -6,12 +6,15 @@
 package containerdshim
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	vc "github.com/kata-containers/kata-containers/src/runtime/virtcontainers"
+	pbTypes "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/pkg/agent/protocols"
 	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/pkg/vcmock"
 
 	"github.com/stretchr/testify/assert"
@@ -61,3 +64,156 @@ go_threads 23
 	body = rr.Body.String()
 	assert.Equal(true, len(strings.Split(body, "\n")) > 0)
 }
+
+func TestNetworkBandwidthHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	sandbox := &vcmock.Sandbox{
+		MockID: testSandboxID,
+	}
+
+	s := &service{
+		id:         testSandboxID,
+		sandbox:    sandbox,
+		containers: make(map[string]*container),
+	}
+
+	var gotName string
+	var gotBandwidth vc.NetworkBandwidth
+	sandbox.UpdateInterfaceBandwidthFunc = func(name string, bandwidth vc.NetworkBandwidth) error {
+		gotName = name
+		gotBandwidth = bandwidth
+		return nil
+	}
+
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPut, NetworkBandwidthURL, strings.NewReader(`{"Interface": "eth0", "Ingress": 1000000, "Egress": 2000000}`))
+	s.networkBandwidthHandler(rr, r)
+	assert.Equal(http.StatusOK, rr.Code)
+	assert.Equal("eth0", gotName)
+	assert.Equal(vc.NetworkBandwidth{Ingress: 1000000, Egress: 2000000}, gotBandwidth)
+
+	rr = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPut, NetworkBandwidthURL, strings.NewReader(`not json`))
+	s.networkBandwidthHandler(rr, r)
+	assert.Equal(http.StatusBadRequest, rr.Code)
+
+	sandbox.UpdateInterfaceBandwidthFunc = func(name string, bandwidth vc.NetworkBandwidth) error {
+		return fmt.Errorf("no such interface %s", name)
+	}
+	rr = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPut, NetworkBandwidthURL, strings.NewReader(`{"Interface": "eth1"}`))
+	s.networkBandwidthHandler(rr, r)
+	assert.Equal(http.StatusInternalServerError, rr.Code)
+
+	rr = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodGet, NetworkBandwidthURL, nil)
+	s.networkBandwidthHandler(rr, r)
+	assert.Equal(http.StatusNotImplemented, rr.Code)
+}
+
+func TestNetworkListHandlers(t *testing.T) {
+	assert := assert.New(t)
+
+	sandbox := &vcmock.Sandbox{
+		MockID: testSandboxID,
+	}
+
+	s := &service{
+		id:         testSandboxID,
+		sandbox:    sandbox,
+		containers: make(map[string]*container),
+	}
+
+	sandbox.ListInterfacesFunc = func() ([]*pbTypes.Interface, error) {
+		return []*pbTypes.Interface{{Name: "eth0", HwAddr: "02:00:ca:fe:00:01", Mtu: 1500}}, nil
+	}
+	sandbox.ListRoutesFunc = func() ([]*pbTypes.Route, error) {
+		return []*pbTypes.Route{{Dest: "10.0.0.0/24", Device: "eth0"}}, nil
+	}
+	sandbox.ListNetworkEndpointsFunc = func() ([]*pbTypes.Interface, []*pbTypes.Route, []*pbTypes.ARPNeighbor, error) {
+		return []*pbTypes.Interface{{Name: "eth0", Mtu: 9000}},
+			[]*pbTypes.Route{{Gateway: "10.0.0.1", Device: "eth0"}},
+			[]*pbTypes.ARPNeighbor{{Device: "eth0", Lladdr: "02:00:ca:fe:00:02"}},
+			nil
+	}
+
+	rr := httptest.NewRecorder()
+	s.networkInterfacesHandler(rr, httptest.NewRequest(http.MethodGet, NetworkInterfacesURL, nil))
+	assert.Equal(http.StatusOK, rr.Code)
+	var interfaces []*pbTypes.Interface
+	assert.NoError(json.Unmarshal(rr.Body.Bytes(), &interfaces))
+	assert.Len(interfaces, 1)
+	assert.Equal("02:00:ca:fe:00:01", interfaces[0].HwAddr)
+
+	rr = httptest.NewRecorder()
+	s.networkRoutesHandler(rr, httptest.NewRequest(http.MethodGet, NetworkRoutesURL, nil))
+	assert.Equal(http.StatusOK, rr.Code)
+	var routes []*pbTypes.Route
+	assert.NoError(json.Unmarshal(rr.Body.Bytes(), &routes))
+	assert.Len(routes, 1)
+	assert.Equal("10.0.0.0/24", routes[0].Dest)
+
+	rr = httptest.NewRecorder()
+	s.networkEndpointsHandler(rr, httptest.NewRequest(http.MethodGet, NetworkEndpointsURL, nil))
+	assert.Equal(http.StatusOK, rr.Code)
+	var endpoints NetworkEndpoints
+	assert.NoError(json.Unmarshal(rr.Body.Bytes(), &endpoints))
+	assert.Equal(uint64(9000), endpoints.Interfaces[0].Mtu)
+	assert.Equal("10.0.0.1", endpoints.Routes[0].Gateway)
+	assert.Equal("02:00:ca:fe:00:02", endpoints.Neighbors[0].Lladdr)
+
+	sandbox.ListRoutesFunc = func() ([]*pbTypes.Route, error) {
+		return nil, fmt.Errorf("agent unreachable")
+	}
+	rr = httptest.NewRecorder()
+	s.networkRoutesHandler(rr, httptest.NewRequest(http.MethodGet, NetworkRoutesURL, nil))
+	assert.Equal(http.StatusInternalServerError, rr.Code)
+
+	rr = httptest.NewRecorder()
+	s.networkInterfacesHandler(rr, httptest.NewRequest(http.MethodPut, NetworkInterfacesURL, nil))
+	assert.Equal(http.StatusNotImplemented, rr.Code)
+}
+
+func TestPCIeTopologyHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	sandbox := &vcmock.Sandbox{
+		MockID: testSandboxID,
+	}
+
+	s := &service{
+		id:         testSandboxID,
+		sandbox:    sandbox,
+		containers: make(map[string]*container),
+	}
+
+	sandbox.PCIeTopologyFunc = func() (vc.PCIeTopology, error) {
+		return vc.PCIeTopology{
+			RootPorts: []vc.PCIeBus{
+				{ID: "rp0", Capacity: 1, Devices: []vc.PCIeSlot{{Device: "vfio-gpu", PciPath: "02/00"}}},
+				{ID: "rp1", Capacity: 1},
+			},
+		}, nil
+	}
+
+	rr := httptest.NewRecorder()
+	s.pcieTopologyHandler(rr, httptest.NewRequest(http.MethodGet, PCIeTopologyURL, nil))
+	assert.Equal(http.StatusOK, rr.Code)
+	var topology vc.PCIeTopology
+	assert.NoError(json.Unmarshal(rr.Body.Bytes(), &topology))
+	assert.Len(topology.RootPorts, 2)
+	assert.Equal("02/00", topology.RootPorts[0].Devices[0].PciPath)
+	assert.Empty(topology.RootPorts[1].Devices)
+
+	sandbox.PCIeTopologyFunc = func() (vc.PCIeTopology, error) {
+		return vc.PCIeTopology{}, fmt.Errorf("hypervisor not running")
+	}
+	rr = httptest.NewRecorder()
+	s.pcieTopologyHandler(rr, httptest.NewRequest(http.MethodGet, PCIeTopologyURL, nil))
+	assert.Equal(http.StatusInternalServerError, rr.Code)
+
+	rr = httptest.NewRecorder()
+	s.pcieTopologyHandler(rr, httptest.NewRequest(http.MethodPut, PCIeTopologyURL, nil))
+	assert.Equal(http.StatusNotImplemented, rr.Code)
+}
@@ -62,6 +62,8 @@ func startContainer(ctx context.Context, s *service, c *container) (retErr error
 		// We use s.ctx(`ctx` derived from `s.ctx`) to check for cancellation of the
 		// shim context and the context passed to startContainer for tracing.
 		go watchOOMEvents(ctx, s)
+		go watchSandboxEvents(ctx, s)
+		go watchDanConfig(ctx, s)
 	} else {
 		_, err := s.sandbox.StartContainer(ctx, c.id)
 		if err != nil {
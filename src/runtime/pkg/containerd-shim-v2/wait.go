@@ -14,13 +14,31 @@ import (
 	"github.com/containerd/containerd/api/events"
 	"github.com/containerd/containerd/api/types/task"
 	"github.com/containerd/containerd/mount"
+	"github.com/containerd/typeurl/v2"
 	"github.com/sirupsen/logrus"
 
 	"github.com/kata-containers/kata-containers/src/runtime/pkg/oci"
+	vc "github.com/kata-containers/kata-containers/src/runtime/virtcontainers"
 )
 
 const defaultCheckInterval = 1 * time.Second
 
+// TaskRootfsUnhealthyEventTopic is the topic of the TaskRootfsUnhealthy
+// events.
+const TaskRootfsUnhealthyEventTopic = "/tasks/rootfs-unhealthy"
+
+// TaskRootfsUnhealthy is the event published when the sandbox reports the
+// rootfs of a container unhealthy, the files the container had open being
+// lost.
+type TaskRootfsUnhealthy struct {
+	ContainerID string `json:"container_id"`
+	Message     string `json:"message"`
+}
+
+func init() {
+	typeurl.Register(&TaskRootfsUnhealthy{}, "io.containerd.kata.v2.events", "TaskRootfsUnhealthy")
+}
+
 func wait(ctx context.Context, s *service, c *container, execID string) (int32, error) {
 	var execs *exec
 	var err error
@@ -193,3 +211,41 @@ func watchOOMEvents(ctx context.Context, s *service) {
 		}
 	}
 }
+
+func watchSandboxEvents(ctx context.Context, s *service) {
+	if s.sandbox == nil {
+		return
+	}
+
+	for {
+		event, err := s.sandbox.GetSandboxEvent(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			shimLog.WithError(err).Info("failed to get sandbox event")
+			time.Sleep(defaultCheckInterval)
+			continue
+		}
+
+		if event.Type != vc.RootfsUnhealthyEvent {
+			continue
+		}
+
+		s.mu.Lock()
+		for _, containerID := range event.ContainerIDs {
+			if _, ok := s.containers[containerID]; !ok {
+				continue
+			}
+
+			shimLog.WithField("container", containerID).Warnf("rootfs unhealthy: %s", event.Message)
+
+			// publish event for containerd
+			s.send(&TaskRootfsUnhealthy{
+				ContainerID: containerID,
+				Message:     event.Message,
+			})
+		}
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package containerdshim
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/containerd/typeurl/v2"
+	"github.com/stretchr/testify/assert"
+
+	vc "github.com/kata-containers/kata-containers/src/runtime/virtcontainers"
+	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/pkg/vcmock"
+)
+
+func TestWatchSandboxEvents(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan vc.SandboxEvent, 1)
+	events <- vc.SandboxEvent{
+		Type:         vc.RootfsUnhealthyEvent,
+		ContainerIDs: []string{testContainerID, "unknown"},
+		Message:      "virtio-fs daemon quit, sandbox stopped",
+	}
+
+	sandbox := &vcmock.Sandbox{
+		MockID: testSandboxID,
+	}
+	sandbox.GetSandboxEventFunc = func() (vc.SandboxEvent, error) {
+		select {
+		case event := <-events:
+			return event, nil
+		case <-ctx.Done():
+			return vc.SandboxEvent{}, ctx.Err()
+		}
+	}
+
+	s := &service{
+		id:      testSandboxID,
+		sandbox: sandbox,
+		containers: map[string]*container{
+			testContainerID: {id: testContainerID},
+		},
+		events: make(chan interface{}, 2),
+		ctx:    ctx,
+	}
+
+	done := make(chan struct{})
+	go func() {
+		watchSandboxEvents(ctx, s)
+		close(done)
+	}()
+
+	// The event is published for the known containers only
+	var event interface{}
+	select {
+	case event = <-s.events:
+	case <-time.After(5 * time.Second):
+		t.Fatal("rootfs unhealthy event not published")
+	}
+	assert.Equal(&TaskRootfsUnhealthy{
+		ContainerID: testContainerID,
+		Message:     "virtio-fs daemon quit, sandbox stopped",
+	}, event)
+	assert.Equal(TaskRootfsUnhealthyEventTopic, getTopic(event))
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("sandbox events watcher did not stop")
+	}
+	assert.Empty(s.events)
+
+	// and it can be forwarded to containerd
+	any, err := typeurl.MarshalAny(event)
+	assert.NoError(err)
+	decoded, err := typeurl.UnmarshalAny(any)
+	assert.NoError(err)
+	assert.Equal(event, decoded)
+}
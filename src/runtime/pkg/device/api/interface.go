@@ -95,4 +95,7 @@ type DeviceManager interface {
 	GetAllDevices() []Device
 	LoadDevices([]config.DeviceState)
 	FindDevice(*config.DeviceInfo) Device
+	// RestoreHostDrivers binds the VFIO devices bound to vfio-pci when
+	// attaching them back to their host drivers, once the VM is stopped.
+	RestoreHostDrivers() error
 }
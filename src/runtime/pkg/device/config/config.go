@@ -34,6 +34,12 @@ const (
 	// DeviceGeneric is a generic device type
 	DeviceGeneric DeviceType = "generic"
 
+	// DeviceSCSIPassthrough is a host SCSI device passed through to the guest
+	DeviceSCSIPassthrough DeviceType = "scsi-passthrough"
+
+	// DeviceCharPassthrough is a host character device relayed to the guest
+	DeviceCharPassthrough DeviceType = "char-passthrough"
+
 	//VhostUserSCSI - SCSI based vhost-user type
 	VhostUserSCSI = "vhost-user-scsi-pci"
 
@@ -85,6 +91,10 @@ const (
 	// VirtioFSNydus means use nydus for the shared file system
 	VirtioFSNydus = "virtio-fs-nydus"
 
+	// VirtioFSExternal means use virtio-fs for the shared file system,
+	// served by a vhost-user-fs daemon the operator runs
+	VirtioFSExternal = "virtio-fs-external"
+
 	// NoSharedFS means *no* shared file system solution will be used
 	// and files will be copied into the guest system.
 	//
@@ -97,6 +107,16 @@ const (
 	NoSharedFS = "none"
 )
 
+const (
+	// CharDeviceVirtioSerial means relaying the host character devices
+	// through virtio-serial ports
+	CharDeviceVirtioSerial = "virtio-serial"
+
+	// CharDeviceVsock means relaying the host character devices over
+	// vhost-vsock ports the agent listens on
+	CharDeviceVsock = "vsock"
+)
+
 const (
 	// Define the string key for DriverOptions in DeviceInfo struct
 	FsTypeOpt      = "fstype"
@@ -113,6 +133,22 @@ const (
 	VhostUserSCSIMajor = 242
 )
 
+const (
+	// SCSIGenericMajor is the major number of the SCSI generic
+	// character devices, /dev/sgN.
+	SCSIGenericMajor = 21
+)
+
+const (
+	// SCSIGeneric is the QEMU device passing a SCSI generic character
+	// device through.
+	SCSIGeneric = "scsi-generic"
+
+	// SCSIBlock is the QEMU device passing a SCSI block device, or a
+	// multipath device, through.
+	SCSIBlock = "scsi-block"
+)
+
 const (
 
 	// The timeout for reconnecting on non-server sockets when the remote end
@@ -180,6 +216,15 @@ const (
 	InvalidPort = "invalid-port"
 )
 
+const (
+	// MaxPCIeRootPort is the number of PCIe root ports a VM can have,
+	// a limitation from QEMU.
+	MaxPCIeRootPort = 16
+	// MaxPCIeSwitchPort is the number of PCIe switch ports a VM can have,
+	// a limitation from QEMU.
+	MaxPCIeSwitchPort = 16
+)
+
 func (p PCIePort) String() string {
 	switch p {
 	case RootPort:
@@ -285,6 +330,24 @@ type DeviceInfo struct {
 
 	// Specifies the PCIe port type to which the device is attached
 	Port PCIePort
+
+	// BindVFIO binds the devices of the VFIO group to vfio-pci when
+	// attaching it, and back to their host drivers when detaching it.
+	BindVFIO bool
+
+	// SCSIPassthrough passes the SCSI device through to the guest SCSI
+	// bus, so that the guest issues the SCSI commands, e.g. persistent
+	// reservations, to the host device.
+	SCSIPassthrough bool
+
+	// CharPassthrough relays the character device to the guest through a
+	// virtio-serial port, instead of only creating its node in the
+	// container.
+	CharPassthrough bool
+
+	// LeaseKey is the key of the lease claiming the device across the
+	// sandboxes of the node, set by the device manager.
+	LeaseKey string
 }
 
 // BlockDrive represents a block storage drive which may be used in case the storage
@@ -335,6 +398,54 @@ type BlockDrive struct {
 	Swap bool
 }
 
+// SCSIPassthroughDrive represents a host SCSI device passed through to the
+// guest SCSI bus.
+type SCSIPassthroughDrive struct {
+	// File is the host device, a SCSI generic character device or a block device
+	File string
+
+	// ID is used to identify this drive in the hypervisor options.
+	ID string
+
+	// Driver is the QEMU device used to pass the device through,
+	// scsi-generic or scsi-block.
+	Driver string
+
+	// SCSI Address of the device, in the format SCSI-Id:LUN
+	SCSIAddr string
+
+	// Index assigned to the drive, the SCSI address derives from it
+	Index int
+
+	// ReadOnly sets the device file readonly
+	ReadOnly bool
+}
+
+// CharDrive represents a host character device relayed to the guest through
+// a virtio-serial port or a vsock port.
+type CharDrive struct {
+	// File is the host character device
+	File string
+
+	// ID is used to identify the character device in the hypervisor options.
+	ID string
+
+	// PortName is the name of the virtio-serial port in the guest
+	PortName string
+
+	// SocketPath is the socket the hypervisor listens on for the port, the
+	// runtime relays the host character device to it.
+	SocketPath string
+
+	// VsockContextID is the context ID of the VM, when the character
+	// device is relayed over vsock instead of a virtio-serial port.
+	VsockContextID uint32
+
+	// VsockPort is the guest vsock port the agent relays the character
+	// device on.
+	VsockPort uint32
+}
+
 // VFIOMode indicates e behaviour mode for handling devices in the VM
 type VFIOModeType uint32
 
@@ -497,8 +608,9 @@ var GetVhostUserNodeStatFunc = GetVhostUserNodeStat
 
 // GetHostPath is used to fetch the host path for the device.
 // The path passed in the spec refers to the path that should appear inside the container.
-// We need to find the actual device path on the host based on the major-minor numbers of the device.
-func GetHostPath(devInfo DeviceInfo, vhostUserStoreEnabled bool, vhostUserStorePath string) (string, error) {
+// We need to find the actual device path on the host based on the major-minor numbers of the device,
+// from sysfs.
+func GetHostPath(sysfs SysfsProvider, devInfo DeviceInfo, vhostUserStoreEnabled bool, vhostUserStorePath string) (string, error) {
 	if devInfo.ContainerPath == "" {
 		return "", fmt.Errorf("Empty path provided for device")
 	}
@@ -514,7 +626,7 @@ func GetHostPath(devInfo DeviceInfo, vhostUserStoreEnabled bool, vhostUserStoreP
 	}
 
 	ueventPath := filepath.Join(getSysDevPath(devInfo), "uevent")
-	if _, err := os.Stat(ueventPath); err != nil {
+	if _, err := sysfs.Stat(ueventPath); err != nil {
 		// Some devices(eg. /dev/fuse, /dev/cuse) do not always implement sysfs interface under /sys/dev
 		// These devices are passed by default by docker.
 		//
@@ -528,7 +640,12 @@ func GetHostPath(devInfo DeviceInfo, vhostUserStoreEnabled bool, vhostUserStoreP
 		return "", err
 	}
 
-	content, err := ini.Load(ueventPath)
+	uevent, err := sysfs.ReadFile(ueventPath)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := ini.Load(uevent)
 	if err != nil {
 		return "", err
 	}
@@ -542,9 +659,9 @@ func GetHostPath(devInfo DeviceInfo, vhostUserStoreEnabled bool, vhostUserStoreP
 }
 
 // getBackingFile is used to fetch the backing file for the device.
-func getBackingFile(devInfo DeviceInfo) (string, error) {
+func getBackingFile(sysfs SysfsProvider, devInfo DeviceInfo) (string, error) {
 	backingFilePath := filepath.Join(getSysDevPath(devInfo), "loop", "backing_file")
-	data, err := os.ReadFile(backingFilePath)
+	data, err := sysfs.ReadFile(backingFilePath)
 	if err != nil {
 		return "", err
 	}
@@ -639,6 +756,12 @@ type DeviceState struct {
 	// BlockDrive is specific for block device driver
 	BlockDrive *BlockDrive `json:",omitempty"`
 
+	// SCSIPassthroughDrive is specific for SCSI passthrough device driver
+	SCSIPassthroughDrive *SCSIPassthroughDrive `json:",omitempty"`
+
+	// CharDrive is specific for character device passthrough driver
+	CharDrive *CharDrive `json:",omitempty"`
+
 	ID string
 
 	// Type is used to specify driver type
@@ -655,6 +778,10 @@ type DeviceState struct {
 	// VFIODev is specific VFIO device driver
 	VFIODevs []*VFIODev `json:",omitempty"`
 
+	// VFIOHostDrivers maps the BDFs of the devices the runtime bound to
+	// vfio-pci to the drivers they must be bound back to.
+	VFIOHostDrivers map[string]string `json:",omitempty"`
+
 	RefCount    uint
 	AttachCount uint
 
@@ -665,6 +792,10 @@ type DeviceState struct {
 	// ColdPlug specifies whether the device must be cold plugged (true)
 	// or hot plugged (false).
 	ColdPlug bool
+
+	// LeaseKey is the key of the lease claiming the device, so that it is
+	// released once the device is removed after a restart of the shim.
+	LeaseKey string `json:",omitempty"`
 }
 
 // CDI (Container Device Interface), is a specification, for container- runtimes,
@@ -26,7 +26,7 @@ func TestGetBackingFile(t *testing.T) {
 	defer func() { getSysDevPath = orgGetSysDevPath }()
 
 	info := DeviceInfo{}
-	path, err := getBackingFile(info)
+	path, err := getBackingFile(NewRootedSysfs("/"), info)
 	assert.Error(err)
 	assert.Empty(path)
 
@@ -39,7 +39,7 @@ func TestGetBackingFile(t *testing.T) {
 	err = os.WriteFile(filepath.Join(loopDir, "backing_file"), []byte(backingFile), os.FileMode(0755))
 	assert.NoError(err)
 
-	path, err = getBackingFile(info)
+	path, err = getBackingFile(NewRootedSysfs("/"), info)
 	assert.NoError(err)
 	assert.Equal(backingFile, path)
 }
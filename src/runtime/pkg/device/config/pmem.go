@@ -38,8 +38,8 @@ func SetLogger(logger *logrus.Entry) {
 
 // PmemDeviceInfo returns a DeviceInfo if a loop device
 // is mounted on source, and the backing file of the loop device
-// has the PFN signature.
-func PmemDeviceInfo(source, destination string) (*DeviceInfo, error) {
+// has the PFN signature. The backing file is found from sysfs.
+func PmemDeviceInfo(sysfs SysfsProvider, source, destination string) (*DeviceInfo, error) {
 	stat := syscall.Stat_t{}
 	err := syscall.Stat(source, &stat)
 	if err != nil {
@@ -63,7 +63,7 @@ func PmemDeviceInfo(source, destination string) (*DeviceInfo, error) {
 			"minor": device.Minor,
 		}).Debug("looking for backing file")
 
-	device.HostPath, err = getBackingFile(*device)
+	device.HostPath, err = getBackingFile(sysfs, *device)
 	if err != nil {
 		return nil, err
 	}
@@ -87,6 +87,32 @@ func PmemDeviceInfo(source, destination string) (*DeviceInfo, error) {
 	return device, nil
 }
 
+// PmemFileDeviceInfo returns a DeviceInfo to attach the host file or block
+// device path as a NVDIMM, if it has the PFN signature. The filesystem of type
+// fstype on it is then mounted with DAX in the guest.
+func PmemFileDeviceInfo(path, destination, fstype string, readonly bool) (*DeviceInfo, error) {
+	if !hasPFNSignature(path) {
+		return nil, fmt.Errorf("file %v has not PFN signature", path)
+	}
+
+	if fstype == "" {
+		fstype = "ext4"
+	}
+
+	return &DeviceInfo{
+		HostPath:      path,
+		ContainerPath: destination,
+		DevType:       "b",
+		Major:         -1,
+		Minor:         0,
+		Pmem:          true,
+		ReadOnly:      readonly,
+		DriverOptions: map[string]string{
+			FsTypeOpt: fstype,
+		},
+	}, nil
+}
+
 // returns true if the file/device path has the PFN signature
 // required to use it as PMEM device and enable DAX.
 // See [1] to know more about the PFN signature.
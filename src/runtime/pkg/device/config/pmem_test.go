@@ -46,3 +46,31 @@ func TestHasPFNSignature(t *testing.T) {
 	b = hasPFNSignature(pfnFile)
 	assert.True(b)
 }
+
+func TestPmemFileDeviceInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+
+	f, err := os.Create(filepath.Join(dir, "nopfn"))
+	assert.NoError(err)
+	f.Close()
+
+	_, err = PmemFileDeviceInfo(f.Name(), "/data", "xfs", false)
+	assert.Error(err)
+
+	pfnPath := createPFNFile(assert, dir)
+
+	di, err := PmemFileDeviceInfo(pfnPath, "/data", "xfs", true)
+	assert.NoError(err)
+	assert.Equal(pfnPath, di.HostPath)
+	assert.Equal("/data", di.ContainerPath)
+	assert.Equal(int64(-1), di.Major)
+	assert.True(di.Pmem)
+	assert.True(di.ReadOnly)
+	assert.Equal("xfs", di.DriverOptions[FsTypeOpt])
+
+	di, err = PmemFileDeviceInfo(pfnPath, "/data", "", false)
+	assert.NoError(err)
+	assert.Equal("ext4", di.DriverOptions[FsTypeOpt])
+}
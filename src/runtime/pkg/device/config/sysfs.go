@@ -0,0 +1,87 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SysfsProvider gives access to the sysfs and devfs entries used to discover
+// and bind host devices. Paths are the host ones, e.g.
+// /sys/bus/pci/devices/0000:00:02.0/config, and the paths returned by
+// EvalSymlinks are host paths too.
+type SysfsProvider interface {
+	Stat(path string) (os.FileInfo, error)
+	ReadFile(path string) ([]byte, error)
+	// WriteFile writes to an existing file, as sysfs attributes
+	// cannot be created.
+	WriteFile(path string, data []byte) error
+	ReadDir(path string) ([]os.DirEntry, error)
+	Readlink(path string) (string, error)
+	EvalSymlinks(path string) (string, error)
+}
+
+// rootedSysfs is a SysfsProvider resolving the host paths under a root
+// directory.
+type rootedSysfs struct {
+	root string
+}
+
+// NewRootedSysfs returns a SysfsProvider resolving the host paths under root.
+// This is "/" for the host itself, the host root mount point for a runtime
+// running in a container, or a fixture tree in the tests. The symlinks of the
+// tree must be relative for them to resolve under root.
+func NewRootedSysfs(root string) SysfsProvider {
+	return &rootedSysfs{root: filepath.Clean(root)}
+}
+
+func (s *rootedSysfs) path(path string) string {
+	return filepath.Join(s.root, path)
+}
+
+func (s *rootedSysfs) Stat(path string) (os.FileInfo, error) {
+	return os.Stat(s.path(path))
+}
+
+func (s *rootedSysfs) ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(s.path(path))
+}
+
+func (s *rootedSysfs) WriteFile(path string, data []byte) error {
+	f, err := os.OpenFile(s.path(path), os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(data)
+	return err
+}
+
+func (s *rootedSysfs) ReadDir(path string) ([]os.DirEntry, error) {
+	return os.ReadDir(s.path(path))
+}
+
+func (s *rootedSysfs) Readlink(path string) (string, error) {
+	return os.Readlink(s.path(path))
+}
+
+func (s *rootedSysfs) EvalSymlinks(path string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(s.path(path))
+	if err != nil || s.root == "/" {
+		return resolved, err
+	}
+
+	// The root may itself be behind a symlink
+	root, err := filepath.EvalSymlinks(s.root)
+	if err != nil {
+		return "", err
+	}
+
+	return "/" + strings.TrimPrefix(strings.TrimPrefix(resolved, root), "/"), nil
+}
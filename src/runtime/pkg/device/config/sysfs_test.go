@@ -0,0 +1,58 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRootedSysfs(t *testing.T) {
+	assert := assert.New(t)
+
+	root := t.TempDir()
+	deviceDir := filepath.Join(root, "sys/devices/pci0000:00/0000:00:02.0")
+	assert.NoError(os.MkdirAll(deviceDir, 0755))
+	assert.NoError(os.MkdirAll(filepath.Join(root, "sys/bus/pci/devices"), 0755))
+	assert.NoError(os.Symlink("../../../devices/pci0000:00/0000:00:02.0", filepath.Join(root, "sys/bus/pci/devices/0000:00:02.0")))
+	assert.NoError(os.WriteFile(filepath.Join(deviceDir, "vendor"), []byte("0x8086\n"), 0644))
+
+	// The root is reached through a symlink
+	link := filepath.Join(t.TempDir(), "root")
+	assert.NoError(os.Symlink(root, link))
+	sysfs := NewRootedSysfs(link)
+
+	data, err := sysfs.ReadFile("/sys/bus/pci/devices/0000:00:02.0/vendor")
+	assert.NoError(err)
+	assert.Equal("0x8086\n", string(data))
+
+	resolved, err := sysfs.EvalSymlinks("/sys/bus/pci/devices/0000:00:02.0")
+	assert.NoError(err)
+	assert.Equal("/sys/devices/pci0000:00/0000:00:02.0", resolved)
+
+	target, err := sysfs.Readlink("/sys/bus/pci/devices/0000:00:02.0")
+	assert.NoError(err)
+	assert.Equal("../../../devices/pci0000:00/0000:00:02.0", target)
+
+	entries, err := sysfs.ReadDir("/sys/bus/pci/devices")
+	assert.NoError(err)
+	assert.Len(entries, 1)
+
+	fi, err := sysfs.Stat("/sys/bus/pci/devices/0000:00:02.0/vendor")
+	assert.NoError(err)
+	assert.Equal(int64(7), fi.Size())
+
+	assert.NoError(sysfs.WriteFile("/sys/bus/pci/devices/0000:00:02.0/vendor", []byte("0x1af4")))
+	data, err = sysfs.ReadFile("/sys/devices/pci0000:00/0000:00:02.0/vendor")
+	assert.NoError(err)
+	assert.Equal("0x1af4", string(data))
+
+	// sysfs attributes cannot be created
+	assert.Error(sysfs.WriteFile("/sys/bus/pci/devices/0000:00:02.0/driver_override", []byte("vfio-pci")))
+}
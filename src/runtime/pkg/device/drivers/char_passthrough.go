@@ -0,0 +1,118 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package drivers
+
+import (
+	"context"
+
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/api"
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
+	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/utils"
+)
+
+// CharPassthroughDevice refers to a host character device, e.g. a serial
+// adapter, relayed to the guest through a virtio-serial port. The guest port
+// is then exposed to the container in place of the host device.
+type CharPassthroughDevice struct {
+	*GenericDevice
+	CharDrive *config.CharDrive
+}
+
+// NewCharPassthroughDevice creates a new character passthrough device based
+// on DeviceInfo
+func NewCharPassthroughDevice(devInfo *config.DeviceInfo) *CharPassthroughDevice {
+	return &CharPassthroughDevice{
+		GenericDevice: &GenericDevice{
+			ID:         devInfo.ID,
+			DeviceInfo: devInfo,
+		},
+	}
+}
+
+// Attach is standard interface of api.Device, it's used to add device to some
+// DeviceReceiver
+func (device *CharPassthroughDevice) Attach(ctx context.Context, devReceiver api.DeviceReceiver) (err error) {
+	skip, err := device.bumpAttachCount(true)
+	if err != nil {
+		return err
+	}
+	if skip {
+		return nil
+	}
+
+	defer func() {
+		if err != nil {
+			device.bumpAttachCount(false)
+		}
+	}()
+
+	id := utils.MakeNameID("char", device.DeviceInfo.ID, maxDevIDSize)
+	device.CharDrive = &config.CharDrive{
+		File:     device.DeviceInfo.HostPath,
+		ID:       id,
+		PortName: id,
+	}
+
+	deviceLogger().WithField("device", device.DeviceInfo.HostPath).WithField("port", id).Info("Attaching character device")
+	return devReceiver.HotplugAddDevice(ctx, device, config.DeviceCharPassthrough)
+}
+
+// Detach is standard interface of api.Device, it's used to remove device from some
+// DeviceReceiver
+func (device *CharPassthroughDevice) Detach(ctx context.Context, devReceiver api.DeviceReceiver) (err error) {
+	skip, err := device.bumpAttachCount(false)
+	if err != nil {
+		return err
+	}
+	if skip {
+		return nil
+	}
+
+	defer func() {
+		if err != nil {
+			device.bumpAttachCount(true)
+		}
+	}()
+
+	deviceLogger().WithField("device", device.DeviceInfo.HostPath).Info("Unplugging character device")
+
+	if err = devReceiver.HotplugRemoveDevice(ctx, device, config.DeviceCharPassthrough); err != nil {
+		deviceLogger().WithError(err).Error("Failed to unplug character device")
+		return err
+	}
+	return nil
+}
+
+// DeviceType is standard interface of api.Device, it returns device type
+func (device *CharPassthroughDevice) DeviceType() config.DeviceType {
+	return config.DeviceCharPassthrough
+}
+
+// GetDeviceInfo returns device information used for creating
+func (device *CharPassthroughDevice) GetDeviceInfo() interface{} {
+	return device.CharDrive
+}
+
+// Save converts Device to DeviceState
+func (device *CharPassthroughDevice) Save() config.DeviceState {
+	ds := device.GenericDevice.Save()
+	ds.Type = string(device.DeviceType())
+
+	ds.CharDrive = device.CharDrive
+
+	return ds
+}
+
+// Load loads DeviceState and converts it to specific device
+func (device *CharPassthroughDevice) Load(ds config.DeviceState) {
+	device.GenericDevice = &GenericDevice{}
+	device.GenericDevice.Load(ds)
+
+	device.CharDrive = ds.CharDrive
+}
+
+// It should implement GetAttachCount() and DeviceID() as api.Device implementation
+// here it shares function from *GenericDevice so we don't need duplicate codes
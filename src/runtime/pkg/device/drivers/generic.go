@@ -142,6 +142,7 @@ func (device *GenericDevice) Save() config.DeviceState {
 		dss.Minor = info.Minor
 		dss.DriverOptions = info.DriverOptions
 		dss.ColdPlug = info.ColdPlug
+		dss.LeaseKey = info.LeaseKey
 	}
 	return dss
 }
@@ -158,5 +159,6 @@ func (device *GenericDevice) Load(ds config.DeviceState) {
 		Minor:         ds.Minor,
 		DriverOptions: ds.DriverOptions,
 		ColdPlug:      ds.ColdPlug,
+		LeaseKey:      ds.LeaseKey,
 	}
 }
@@ -0,0 +1,151 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package drivers
+
+import (
+	"context"
+
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/api"
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
+	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/utils"
+)
+
+// SCSIPassthroughDevice refers to a host SCSI device passed through to the
+// guest SCSI bus. Unlike an emulated SCSI disk, the guest SCSI commands,
+// e.g. the SCSI-3 persistent reservations, reach the host device.
+type SCSIPassthroughDevice struct {
+	*GenericDevice
+	Drive *config.SCSIPassthroughDrive
+}
+
+// NewSCSIPassthroughDevice creates a new SCSI passthrough device based on
+// DeviceInfo
+func NewSCSIPassthroughDevice(devInfo *config.DeviceInfo) *SCSIPassthroughDevice {
+	return &SCSIPassthroughDevice{
+		GenericDevice: &GenericDevice{
+			ID:         devInfo.ID,
+			DeviceInfo: devInfo,
+		},
+	}
+}
+
+// scsiPassthroughDriver returns the QEMU device passing the host device
+// through: SCSI generic character devices are passed with scsi-generic,
+// SCSI disks and multipath devices with scsi-block.
+func scsiPassthroughDriver(devInfo *config.DeviceInfo) string {
+	if devInfo.DevType == "c" {
+		return config.SCSIGeneric
+	}
+	return config.SCSIBlock
+}
+
+// Attach is standard interface of api.Device, it's used to add device to some
+// DeviceReceiver
+func (device *SCSIPassthroughDevice) Attach(ctx context.Context, devReceiver api.DeviceReceiver) (err error) {
+	skip, err := device.bumpAttachCount(true)
+	if err != nil {
+		return err
+	}
+	if skip {
+		return nil
+	}
+
+	// The device sits on the SCSI bus of the block devices, it takes its
+	// SCSI address from the same sandbox block index.
+	index, err := devReceiver.GetAndSetSandboxBlockIndex()
+
+	defer func() {
+		if err != nil {
+			devReceiver.UnsetSandboxBlockIndex(index)
+			device.bumpAttachCount(false)
+		}
+	}()
+
+	if err != nil {
+		return err
+	}
+
+	scsiAddr, err := utils.GetSCSIAddress(index)
+	if err != nil {
+		return err
+	}
+
+	drive := &config.SCSIPassthroughDrive{
+		File:     device.DeviceInfo.HostPath,
+		ID:       utils.MakeNameID("drive", device.DeviceInfo.ID, maxDevIDSize),
+		Driver:   scsiPassthroughDriver(device.DeviceInfo),
+		SCSIAddr: scsiAddr,
+		Index:    index,
+		ReadOnly: device.DeviceInfo.ReadOnly,
+	}
+
+	deviceLogger().WithField("device", device.DeviceInfo.HostPath).WithField("SCSIAddr", drive.SCSIAddr).Infof("Attaching %s device", drive.Driver)
+	device.Drive = drive
+	if err = devReceiver.HotplugAddDevice(ctx, device, config.DeviceSCSIPassthrough); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Detach is standard interface of api.Device, it's used to remove device from some
+// DeviceReceiver
+func (device *SCSIPassthroughDevice) Detach(ctx context.Context, devReceiver api.DeviceReceiver) (err error) {
+	skip, err := device.bumpAttachCount(false)
+	if err != nil {
+		return err
+	}
+	if skip {
+		return nil
+	}
+
+	defer func() {
+		if err != nil {
+			device.bumpAttachCount(true)
+		} else {
+			devReceiver.UnsetSandboxBlockIndex(device.Drive.Index)
+		}
+	}()
+
+	deviceLogger().WithField("device", device.DeviceInfo.HostPath).Info("Unplugging SCSI passthrough device")
+
+	if err = devReceiver.HotplugRemoveDevice(ctx, device, config.DeviceSCSIPassthrough); err != nil {
+		deviceLogger().WithError(err).Error("Failed to unplug SCSI passthrough device")
+		return err
+	}
+	return nil
+}
+
+// DeviceType is standard interface of api.Device, it returns device type
+func (device *SCSIPassthroughDevice) DeviceType() config.DeviceType {
+	return config.DeviceSCSIPassthrough
+}
+
+// GetDeviceInfo returns device information used for creating
+func (device *SCSIPassthroughDevice) GetDeviceInfo() interface{} {
+	return device.Drive
+}
+
+// Save converts Device to DeviceState
+func (device *SCSIPassthroughDevice) Save() config.DeviceState {
+	ds := device.GenericDevice.Save()
+	ds.Type = string(device.DeviceType())
+
+	ds.SCSIPassthroughDrive = device.Drive
+
+	return ds
+}
+
+// Load loads DeviceState and converts it to specific device
+func (device *SCSIPassthroughDevice) Load(ds config.DeviceState) {
+	device.GenericDevice = &GenericDevice{}
+	device.GenericDevice.Load(ds)
+
+	device.Drive = ds.SCSIPassthroughDrive
+}
+
+// It should implement GetAttachCount() and DeviceID() as api.Device implementation
+// here it shares function from *GenericDevice so we don't need duplicate codes
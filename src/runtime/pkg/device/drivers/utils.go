@@ -8,7 +8,6 @@ package drivers
 
 import (
 	"fmt"
-	"os"
 	"path"
 	"path/filepath"
 	"regexp"
@@ -54,13 +53,13 @@ func deviceLogger() *logrus.Entry {
 
 // IsPCIeDevice identifies PCIe device by reading the size of the PCI config space
 // Plain PCI device have 256 bytes of config space where PCIe devices have 4K
-func IsPCIeDevice(bdf string) bool {
+func IsPCIeDevice(sysfs config.SysfsProvider, bdf string) bool {
 	if len(strings.Split(bdf, ":")) == 2 {
 		bdf = PCIDomain + ":" + bdf
 	}
 
 	configPath := filepath.Join(config.SysBusPciDevicesPath, bdf, "config")
-	fi, err := os.Stat(configPath)
+	fi, err := sysfs.Stat(configPath)
 	if err != nil {
 		deviceLogger().WithField("dev-bdf", bdf).WithError(err).Warning("Couldn't stat() configuration space file")
 		return false //Who knows?
@@ -72,12 +71,12 @@ func IsPCIeDevice(bdf string) bool {
 }
 
 // read from /sys/bus/pci/devices/xxx/property
-func GetPCIDeviceProperty(bdf string, property PCISysFsProperty) string {
+func GetPCIDeviceProperty(sysfs config.SysfsProvider, bdf string, property PCISysFsProperty) string {
 	if len(strings.Split(bdf, ":")) == 2 {
 		bdf = PCIDomain + ":" + bdf
 	}
 	propertyPath := filepath.Join(config.SysBusPciDevicesPath, bdf, string(property))
-	rlt, err := readPCIProperty(propertyPath)
+	rlt, err := readPCIProperty(sysfs, propertyPath)
 	if err != nil {
 		deviceLogger().WithError(err).WithField("path", propertyPath).Warn("failed to read pci device property")
 		return ""
@@ -85,18 +84,18 @@ func GetPCIDeviceProperty(bdf string, property PCISysFsProperty) string {
 	return rlt
 }
 
-func readPCIProperty(propertyPath string) (string, error) {
+func readPCIProperty(sysfs config.SysfsProvider, propertyPath string) (string, error) {
 	var (
 		buf []byte
 		err error
 	)
-	if buf, err = os.ReadFile(propertyPath); err != nil {
+	if buf, err = sysfs.ReadFile(propertyPath); err != nil {
 		return "", fmt.Errorf("failed to read pci sysfs %v, error:%v", propertyPath, err)
 	}
 	return strings.Split(string(buf), "\n")[0], nil
 }
 
-func GetVFIODeviceType(deviceFilePath string) (config.VFIODeviceType, error) {
+func GetVFIODeviceType(sysfs config.SysfsProvider, deviceFilePath string) (config.VFIODeviceType, error) {
 	deviceFileName := filepath.Base(deviceFilePath)
 
 	//For example, 0000:04:00.0
@@ -111,7 +110,7 @@ func GetVFIODeviceType(deviceFilePath string) (config.VFIODeviceType, error) {
 		return config.VFIODeviceErrorType, fmt.Errorf("Incorrect tokens found while parsing VFIO details: %s", deviceFileName)
 	}
 
-	deviceSysfsDev, err := GetSysfsDev(deviceFilePath)
+	deviceSysfsDev, err := GetSysfsDev(sysfs, deviceFilePath)
 	if err != nil {
 		return config.VFIODeviceErrorType, err
 	}
@@ -126,14 +125,14 @@ func GetVFIODeviceType(deviceFilePath string) (config.VFIODeviceType, error) {
 // GetSysfsDev returns the sysfsdev of mediated device
 // Expected input string format is absolute path to the sysfs dev node
 // eg. /sys/kernel/iommu_groups/0/devices/f79944e4-5a3d-11e8-99ce-479cbab002e4
-func GetSysfsDev(sysfsDevStr string) (string, error) {
-	return filepath.EvalSymlinks(sysfsDevStr)
+func GetSysfsDev(sysfs config.SysfsProvider, sysfsDevStr string) (string, error) {
+	return sysfs.EvalSymlinks(sysfsDevStr)
 }
 
 // GetAPVFIODevices retrieves all APQNs associated with a mediated VFIO-AP
 // device
-func GetAPVFIODevices(sysfsdev string) ([]string, error) {
-	data, err := os.ReadFile(filepath.Join(sysfsdev, "matrix"))
+func GetAPVFIODevices(sysfs config.SysfsProvider, sysfsdev string) ([]string, error) {
+	data, err := sysfs.ReadFile(filepath.Join(sysfsdev, "matrix"))
 	if err != nil {
 		return []string{}, err
 	}
@@ -160,8 +159,8 @@ func checkIgnorePCIClass(pciClass string, deviceBDF string, bitmask uint64) (boo
 	return false, nil
 }
 
-func GetMajorMinorFromDevPath(devPath string) (uint32, uint32, error) {
-	fi, err := os.Stat(devPath)
+func GetMajorMinorFromDevPath(sysfs config.SysfsProvider, devPath string) (uint32, uint32, error) {
+	fi, err := sysfs.Stat(devPath)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -181,9 +180,9 @@ func extractIndex(devicePath string) (string, error) {
 	return strings.TrimPrefix(base, prefix), nil
 }
 
-func GetBDFFromVFIODev(major uint32, minor uint32) (string, error) {
-	devPath := fmt.Sprintf("/sys/dev/char/%d:%d", major, minor)
-	realPath, err := filepath.EvalSymlinks(devPath)
+func GetBDFFromVFIODev(sysfs config.SysfsProvider, major uint32, minor uint32) (string, error) {
+	devPath := filepath.Join(config.SysDevPrefix, "char", fmt.Sprintf("%d:%d", major, minor))
+	realPath, err := sysfs.EvalSymlinks(devPath)
 	if err != nil {
 		return "", fmt.Errorf("Failed to resolve symlink for %s: %v", devPath, err)
 	}
@@ -198,31 +197,31 @@ func GetBDFFromVFIODev(major uint32, minor uint32) (string, error) {
 
 // GetDeviceFromVFIODev return the host device associated with the VFIO device
 // There is only one device per VFIO device in the case of IOMMUFD
-func GetDeviceFromVFIODev(device config.DeviceInfo) ([]*config.VFIODev, error) {
+func GetDeviceFromVFIODev(sysfs config.SysfsProvider, device config.DeviceInfo) ([]*config.VFIODev, error) {
 	// The way we get the host BDF is by reading the symlink of the char
 	// device major:minor entries in /sys/chart/major:minor
 	// $ ls -l /dev/vfio/devices/vfio0
 	// crw------- 1 root root 237, 0 Jan 15 16:53 /dev/vfio/devices/vfio0
-	major, minor, err := GetMajorMinorFromDevPath(device.HostPath)
+	major, minor, err := GetMajorMinorFromDevPath(sysfs, device.HostPath)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to get major:minor from %s: %v", device.HostPath, err)
 	}
 	// $ ls -l /sys/dev/char/237:0
 	// /sys/dev/char/237:0 -> ../../devices/pci0000:64/0000:64:00.0/0000:65:00.0/vfio-dev/vfio0
-	deviceBDF, err := GetBDFFromVFIODev(major, minor)
+	deviceBDF, err := GetBDFFromVFIODev(sysfs, major, minor)
 	if err != nil {
 		return nil, err
 	}
 
 	deviceSysfsDev := path.Join(config.SysBusPciDevicesPath, deviceBDF)
-	vfioDeviceType, err := GetVFIODeviceType(deviceSysfsDev)
+	vfioDeviceType, err := GetVFIODeviceType(sysfs, deviceSysfsDev)
 	if err != nil {
 		return nil, err
 	}
 
-	vendorID := GetPCIDeviceProperty(deviceBDF, PCISysFsDevicesVendor)
-	deviceID := GetPCIDeviceProperty(deviceBDF, PCISysFsDevicesDevice)
-	pciClass := GetPCIDeviceProperty(deviceBDF, PCISysFsDevicesClass)
+	vendorID := GetPCIDeviceProperty(sysfs, deviceBDF, PCISysFsDevicesVendor)
+	deviceID := GetPCIDeviceProperty(sysfs, deviceBDF, PCISysFsDevicesDevice)
+	pciClass := GetPCIDeviceProperty(sysfs, deviceBDF, PCISysFsDevicesClass)
 
 	i, err := extractIndex(device.HostPath)
 	if err != nil {
@@ -236,7 +235,7 @@ func GetDeviceFromVFIODev(device config.DeviceInfo) ([]*config.VFIODev, error) {
 		BDF:      deviceBDF,
 		SysfsDev: deviceSysfsDev,
 		DevfsDev: device.HostPath,
-		IsPCIe:   IsPCIeDevice(deviceBDF),
+		IsPCIe:   IsPCIeDevice(sysfs, deviceBDF),
 		Class:    pciClass,
 		VendorID: vendorID,
 		DeviceID: deviceID,
@@ -250,14 +249,14 @@ func GetDeviceFromVFIODev(device config.DeviceInfo) ([]*config.VFIODev, error) {
 
 // GetAllVFIODevicesFromIOMMUGroup returns all the VFIO devices in the IOMMU group
 // We can reuse this function at various levels, sandbox, container.
-func GetAllVFIODevicesFromIOMMUGroup(device config.DeviceInfo) ([]*config.VFIODev, error) {
+func GetAllVFIODevicesFromIOMMUGroup(sysfs config.SysfsProvider, device config.DeviceInfo) ([]*config.VFIODev, error) {
 
 	vfioDevs := []*config.VFIODev{}
 
 	vfioGroup := filepath.Base(device.HostPath)
 	iommuDevicesPath := filepath.Join(config.SysIOMMUGroupPath, vfioGroup, "devices")
 
-	deviceFiles, err := os.ReadDir(iommuDevicesPath)
+	deviceFiles, err := sysfs.ReadDir(iommuDevicesPath)
 	if err != nil {
 		return nil, err
 	}
@@ -265,7 +264,7 @@ func GetAllVFIODevicesFromIOMMUGroup(device config.DeviceInfo) ([]*config.VFIODe
 	// Pass all devices in iommu group
 	for i, deviceFile := range deviceFiles {
 		//Get bdf of device eg 0000:00:1c.0
-		deviceBDF, deviceSysfsDev, vfioDeviceType, err := GetVFIODetails(deviceFile.Name(), iommuDevicesPath)
+		deviceBDF, deviceSysfsDev, vfioDeviceType, err := GetVFIODetails(sysfs, deviceFile.Name(), iommuDevicesPath)
 		if err != nil {
 			return nil, err
 		}
@@ -276,7 +275,7 @@ func GetAllVFIODevicesFromIOMMUGroup(device config.DeviceInfo) ([]*config.VFIODe
 		switch vfioDeviceType {
 		case config.VFIOPCIDeviceNormalType, config.VFIOPCIDeviceMediatedType:
 			// This is vfio-pci and vfio-mdev specific
-			pciClass := GetPCIDeviceProperty(deviceBDF, PCISysFsDevicesClass)
+			pciClass := GetPCIDeviceProperty(sysfs, deviceBDF, PCISysFsDevicesClass)
 			// We need to ignore Host or PCI Bridges that are in the same IOMMU group as the
 			// passed-through devices. One CANNOT pass-through a PCI bridge or Host bridge.
 			// Class 0x0604 is PCI bridge, 0x0600 is Host bridge
@@ -288,8 +287,8 @@ func GetAllVFIODevicesFromIOMMUGroup(device config.DeviceInfo) ([]*config.VFIODe
 				continue
 			}
 			// Fetch the PCI Vendor ID and Device ID
-			vendorID := GetPCIDeviceProperty(deviceBDF, PCISysFsDevicesVendor)
-			deviceID := GetPCIDeviceProperty(deviceBDF, PCISysFsDevicesDevice)
+			vendorID := GetPCIDeviceProperty(sysfs, deviceBDF, PCISysFsDevicesVendor)
+			deviceID := GetPCIDeviceProperty(sysfs, deviceBDF, PCISysFsDevicesDevice)
 
 			// Do not directly assign to `vfio` -- need to access field still
 			vfio = config.VFIODev{
@@ -297,7 +296,7 @@ func GetAllVFIODevicesFromIOMMUGroup(device config.DeviceInfo) ([]*config.VFIODe
 				Type:     vfioDeviceType,
 				BDF:      deviceBDF,
 				SysfsDev: deviceSysfsDev,
-				IsPCIe:   IsPCIeDevice(deviceBDF),
+				IsPCIe:   IsPCIeDevice(sysfs, deviceBDF),
 				Class:    pciClass,
 				VendorID: vendorID,
 				DeviceID: deviceID,
@@ -306,7 +305,7 @@ func GetAllVFIODevicesFromIOMMUGroup(device config.DeviceInfo) ([]*config.VFIODe
 			}
 
 		case config.VFIOAPDeviceMediatedType:
-			devices, err := GetAPVFIODevices(deviceSysfsDev)
+			devices, err := GetAPVFIODevices(sysfs, deviceSysfsDev)
 			if err != nil {
 				return nil, err
 			}
@@ -8,6 +8,7 @@ package drivers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -18,10 +19,10 @@ import (
 	pkgDevice "github.com/kata-containers/kata-containers/src/runtime/pkg/device"
 	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/api"
 	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
-	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/utils"
 )
 
-// bind/unbind paths to aid in SRIOV VF bring-up/restore
+// bind/unbind paths to aid in SRIOV VF bring-up/restore, resolved through
+// a SysfsProvider
 const (
 	pciDriverUnbindPath   = "/sys/bus/pci/devices/%s/driver/unbind"
 	pciDriverOverridePath = "/sys/bus/pci/devices/%s/driver_override"
@@ -29,6 +30,22 @@ const (
 	iommuGroupPath        = "/sys/bus/pci/devices/%s/iommu_group"
 	vfioDevPath           = "/dev/vfio/%s"
 	vfioAPSysfsDir        = "/sys/devices/vfio_ap"
+	pciDriverPath         = "/sys/bus/pci/devices/%s/driver"
+)
+
+const vfioPCIDriver = "vfio-pci"
+
+var (
+	// ErrVFIODeviceNotFound means that a VFIO device has no host device.
+	ErrVFIODeviceNotFound = errors.New("VFIO device not found")
+
+	// ErrVFIODeviceNotBound means that a device of an IOMMU group is not
+	// bound to vfio-pci.
+	ErrVFIODeviceNotBound = errors.New("device not bound to vfio-pci")
+
+	// ErrIOMMUGroupInconsistent means that an IOMMU group does not exist
+	// or has no device to pass through.
+	ErrIOMMUGroupInconsistent = errors.New("inconsistent IOMMU group")
 )
 
 // VFIODevice is a vfio device meant to be passed to the hypervisor
@@ -36,15 +53,23 @@ const (
 type VFIODevice struct {
 	*GenericDevice
 	VfioDevs []*config.VFIODev
+
+	// HostDrivers maps the BDFs of the devices bound to vfio-pci when
+	// attaching the device to the drivers they were bound to.
+	HostDrivers map[string]string
+
+	// sysfs is where the host devices are discovered and bound
+	sysfs config.SysfsProvider
 }
 
-// NewVFIODevice create a new VFIO device
-func NewVFIODevice(devInfo *config.DeviceInfo) *VFIODevice {
+// NewVFIODevice create a new VFIO device, discovered and bound through sysfs
+func NewVFIODevice(devInfo *config.DeviceInfo, sysfs config.SysfsProvider) *VFIODevice {
 	return &VFIODevice{
 		GenericDevice: &GenericDevice{
 			ID:         devInfo.ID,
 			DeviceInfo: devInfo,
 		},
+		sysfs: sysfs,
 	}
 }
 
@@ -65,18 +90,53 @@ func (device *VFIODevice) Attach(ctx context.Context, devReceiver api.DeviceRece
 		}
 	}()
 
+	devInfo := *device.DeviceInfo
+
+	// An IOMMUFD device only exists once bound to vfio-pci
+	if device.DeviceInfo.BindVFIO && !strings.HasPrefix(device.DeviceInfo.HostPath, pkgDevice.IommufdDevPath) {
+		group, err := ResolveIOMMUGroup(device.sysfs, device.DeviceInfo.HostPath)
+		if err != nil {
+			return err
+		}
+
+		bound, err := BindIOMMUGroupToVFIO(device.sysfs, group)
+		if err != nil {
+			return err
+		}
+
+		// The VFIO group device exists now that the group is bound
+		devInfo.HostPath = fmt.Sprintf(vfioDevPath, group)
+
+		defer func() {
+			if retErr != nil {
+				if err := RestoreHostDrivers(device.sysfs, bound); err != nil {
+					deviceLogger().WithError(err).Error("Failed to bind VFIO devices back to their host drivers")
+				}
+			}
+
+			// Keep track of the devices to bind back, including the
+			// ones the rollback failed for.
+			if len(bound) > 0 && device.HostDrivers == nil {
+				device.HostDrivers = make(map[string]string)
+			}
+			for bdf, driver := range bound {
+				device.HostDrivers[bdf] = driver
+			}
+		}()
+	}
+
 	// This work for IOMMUFD enabled kernels > 6.x
 	// In the case of IOMMUFD the device.HostPath will look like
 	// /dev/vfio/devices/vfio0
 	// (1) Check if we have the new IOMMUFD or old container based VFIO
-	if strings.HasPrefix(device.DeviceInfo.HostPath, pkgDevice.IommufdDevPath) {
-		device.VfioDevs, err = GetDeviceFromVFIODev(*device.DeviceInfo)
+	if strings.HasPrefix(devInfo.HostPath, pkgDevice.IommufdDevPath) {
+		device.VfioDevs, err = GetDeviceFromVFIODev(device.sysfs, devInfo)
 		if err != nil {
 			return err
 		}
 	} else {
 		// Once we have
-		device.VfioDevs, err = GetAllVFIODevicesFromIOMMUGroup(*device.DeviceInfo)
+		device.VfioDevs, err = GetAllVFIODevicesFromIOMMUGroup(device.sysfs, devInfo)
 		if err != nil {
 			return err
 		}
@@ -111,6 +171,7 @@ func (device *VFIODevice) Attach(ctx context.Context, devReceiver api.DeviceRece
 		// hotplug a VFIO device is actually hotplugging a group of iommu devices
 		if err := devReceiver.HotplugAddDevice(ctx, device, config.DeviceVFIO); err != nil {
 			deviceLogger().WithError(err).Error("Failed to add device")
+			device.releasePCIePorts()
 			return err
 		}
 	}
@@ -140,7 +201,8 @@ func (device *VFIODevice) Detach(ctx context.Context, devReceiver api.DeviceRece
 	}()
 
 	if device.DeviceInfo.ColdPlug {
-		// nothing to detach, device was cold plugged
+		// nothing to detach, device was cold plugged. It is bound
+		// back to its host driver once the VM is stopped.
 		deviceLogger().WithFields(logrus.Fields{
 			"device-group": device.DeviceInfo.HostPath,
 			"device-type":  "vfio-passthrough",
@@ -153,6 +215,24 @@ func (device *VFIODevice) Detach(ctx context.Context, devReceiver api.DeviceRece
 		deviceLogger().WithError(err).Error("Failed to remove device")
 		return err
 	}
+	device.releasePCIePorts()
+
+	// The devices failing to be bound back are retried when the
+	// device is removed, and then when the sandbox is deleted.
+	if err := device.RestoreHostDrivers(); err != nil {
+		deviceLogger().WithError(err).Warn("Failed to bind VFIO devices back to their host drivers")
+	}
+
+	deviceLogger().WithFields(logrus.Fields{
+		"device-group": device.DeviceInfo.HostPath,
+		"device-type":  "vfio-passthrough",
+	}).Info("Device group detached")
+	return nil
+}
+
+// releasePCIePorts forgets the PCIe ports the devices of the group were
+// assigned to.
+func (device *VFIODevice) releasePCIePorts() {
 	for _, vfio := range device.VfioDevs {
 		if vfio.IsPCIe {
 			for ix, dev := range config.PCIeDevicesPerPort[vfio.Port] {
@@ -163,12 +243,6 @@ func (device *VFIODevice) Detach(ctx context.Context, devReceiver api.DeviceRece
 			}
 		}
 	}
-
-	deviceLogger().WithFields(logrus.Fields{
-		"device-group": device.DeviceInfo.HostPath,
-		"device-type":  "vfio-passthrough",
-	}).Info("Device group detached")
-	return nil
 }
 
 // DeviceType is standard interface of api.Device, it returns device type
@@ -192,6 +266,7 @@ func (device *VFIODevice) Save() config.DeviceState {
 			ds.VFIODevs = append(ds.VFIODevs, dev)
 		}
 	}
+	ds.VFIOHostDrivers = device.HostDrivers
 	return ds
 }
 
@@ -199,6 +274,7 @@ func (device *VFIODevice) Save() config.DeviceState {
 func (device *VFIODevice) Load(ds config.DeviceState) {
 	device.GenericDevice = &GenericDevice{}
 	device.GenericDevice.Load(ds)
+	device.HostDrivers = ds.VFIOHostDrivers
 
 	for _, dev := range ds.VFIODevs {
 		var vfio config.VFIODev
@@ -229,9 +305,9 @@ func (device *VFIODevice) Load(ds config.DeviceState) {
 
 // It should implement GetAttachCount() and DeviceID() as api.Device implementation
 // here it shares function from *GenericDevice so we don't need duplicate codes
-func GetVFIODetails(deviceFileName, iommuDevicesPath string) (deviceBDF, deviceSysfsDev string, vfioDeviceType config.VFIODeviceType, err error) {
+func GetVFIODetails(sysfs config.SysfsProvider, deviceFileName, iommuDevicesPath string) (deviceBDF, deviceSysfsDev string, vfioDeviceType config.VFIODeviceType, err error) {
 	sysfsDevStr := filepath.Join(iommuDevicesPath, deviceFileName)
-	vfioDeviceType, err = GetVFIODeviceType(sysfsDevStr)
+	vfioDeviceType, err = GetVFIODeviceType(sysfs, sysfsDevStr)
 	if err != nil {
 		return deviceBDF, deviceSysfsDev, vfioDeviceType, err
 	}
@@ -249,11 +325,11 @@ func GetVFIODetails(deviceFileName, iommuDevicesPath string) (deviceBDF, deviceS
 	case config.VFIOPCIDeviceMediatedType:
 		// Get sysfsdev of device eg. /sys/devices/pci0000:00/0000:00:02.0/f79944e4-5a3d-11e8-99ce-479cbab002e4
 		sysfsDevStr := filepath.Join(iommuDevicesPath, deviceFileName)
-		deviceSysfsDev, err = GetSysfsDev(sysfsDevStr)
+		deviceSysfsDev, err = GetSysfsDev(sysfs, sysfsDevStr)
 		deviceBDF = GetBDF(getMediatedBDF(deviceSysfsDev))
 	case config.VFIOAPDeviceMediatedType:
 		sysfsDevStr := filepath.Join(iommuDevicesPath, deviceFileName)
-		deviceSysfsDev, err = GetSysfsDev(sysfsDevStr)
+		deviceSysfsDev, err = GetSysfsDev(sysfs, sysfsDevStr)
 	default:
 		err = fmt.Errorf("Incorrect tokens found while parsing vfio details: %s", deviceFileName)
 	}
@@ -281,9 +357,9 @@ func GetBDF(deviceSysStr string) string {
 	return tokens[1]
 }
 
-func GetVFIODevPath(bdf string) (string, error) {
+func GetVFIODevPath(sysfs config.SysfsProvider, bdf string) (string, error) {
 	// Determine the iommu group that the device belongs to.
-	groupPath, err := os.Readlink(fmt.Sprintf(iommuGroupPath, bdf))
+	groupPath, err := sysfs.Readlink(fmt.Sprintf(iommuGroupPath, bdf))
 	if err != nil {
 		return "", err
 	}
@@ -294,7 +370,7 @@ func GetVFIODevPath(bdf string) (string, error) {
 // BindDevicetoVFIO binds the device to vfio driver after unbinding from host
 // driver if present.
 // Will be called by a network interface or a generic pcie device.
-func BindDevicetoVFIO(bdf, hostDriver string) (string, error) {
+func BindDevicetoVFIO(sysfs config.SysfsProvider, bdf, hostDriver string) (string, error) {
 
 	overrideDriverPath := fmt.Sprintf(pciDriverOverridePath, bdf)
 	deviceLogger().WithFields(logrus.Fields{
@@ -304,7 +380,7 @@ func BindDevicetoVFIO(bdf, hostDriver string) (string, error) {
 
 	// Write vfio-pci to driver_override file to allow the device to bind to vfio-pci
 	// Reference: https://www.kernel.org/doc/Documentation/ABI/testing/sysfs-bus-platform
-	if err := utils.WriteToFile(overrideDriverPath, []byte("vfio-pci")); err != nil {
+	if err := sysfs.WriteFile(overrideDriverPath, []byte("vfio-pci")); err != nil {
 		return "", err
 	}
 
@@ -316,7 +392,7 @@ func BindDevicetoVFIO(bdf, hostDriver string) (string, error) {
 
 	// Unbind device from the host driver. In some cases, a driver may not be bound
 	// to the device, in which case this step may fail. Hence ignore error for this step.
-	utils.WriteToFile(unbindDriverPath, []byte(bdf))
+	sysfs.WriteFile(unbindDriverPath, []byte(bdf))
 
 	deviceLogger().WithFields(logrus.Fields{
 		"device-bdf":         bdf,
@@ -325,16 +401,16 @@ func BindDevicetoVFIO(bdf, hostDriver string) (string, error) {
 
 	// Invoke drivers_probe so that the driver matching driver_override, in our case
 	// the vfio-pci driver will probe the device.
-	if err := utils.WriteToFile(driversProbePath, []byte(bdf)); err != nil {
+	if err := sysfs.WriteFile(driversProbePath, []byte(bdf)); err != nil {
 		return "", err
 	}
 
-	return GetVFIODevPath(bdf)
+	return GetVFIODevPath(sysfs, bdf)
 }
 
 // BindDevicetoHost unbinds the device from vfio-pci driver and binds it to the
 // previously bound driver.
-func BindDevicetoHost(bdf, hostDriver string) error {
+func BindDevicetoHost(sysfs config.SysfsProvider, bdf, hostDriver string) error {
 	overrideDriverPath := fmt.Sprintf(pciDriverOverridePath, bdf)
 	api.DeviceLogger().WithFields(logrus.Fields{
 		"device-bdf":           bdf,
@@ -344,7 +420,7 @@ func BindDevicetoHost(bdf, hostDriver string) error {
 	// write previously bound host driver to driver_override to allow the
 	// device to bind to it. This could be empty which means the device will not be
 	// bound to any driver later on.
-	if err := utils.WriteToFile(overrideDriverPath, []byte(hostDriver)); err != nil {
+	if err := sysfs.WriteFile(overrideDriverPath, []byte(hostDriver)); err != nil {
 		return err
 	}
 
@@ -355,7 +431,7 @@ func BindDevicetoHost(bdf, hostDriver string) error {
 		"driver-path": unbindDriverPath,
 	}).Info("Unbinding device from driver")
 
-	if err := utils.WriteToFile(unbindDriverPath, []byte(bdf)); err != nil {
+	if err := sysfs.WriteFile(unbindDriverPath, []byte(bdf)); err != nil {
 		return err
 	}
 
@@ -366,5 +442,194 @@ func BindDevicetoHost(bdf, hostDriver string) error {
 
 	// Invoke drivers_probe so that the driver matching driver_override, in this case
 	// the previous host driver will probe the device.
-	return utils.WriteToFile(driversProbePath, []byte(bdf))
+	return sysfs.WriteFile(driversProbePath, []byte(bdf))
+}
+
+// RestoreHostDrivers binds the devices bound to vfio-pci when attaching the
+// device back to their host drivers. This must only be done once the VM does
+// not use them anymore.
+func (device *VFIODevice) RestoreHostDrivers() error {
+	return RestoreHostDrivers(device.sysfs, device.HostDrivers)
+}
+
+// GetPCIDeviceDriver returns the driver a PCI device is bound to, or an empty
+// string if it is not bound to any driver.
+func GetPCIDeviceDriver(sysfs config.SysfsProvider, bdf string) (string, error) {
+	driverPath, err := sysfs.Readlink(fmt.Sprintf(pciDriverPath, bdf))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Base(driverPath), nil
+}
+
+// BindIOMMUGroupToVFIO binds the PCI devices of an IOMMU group that are not
+// bound to vfio-pci yet, and returns the drivers they were bound to. PCI
+// bridges are left to their drivers as they cannot be passed through. If one
+// of the devices fails to be bound, the others are bound back to their drivers.
+func BindIOMMUGroupToVFIO(sysfs config.SysfsProvider, group string) (_ map[string]string, retErr error) {
+	deviceFiles, err := sysfs.ReadDir(filepath.Join(config.SysIOMMUGroupPath, group, "devices"))
+	if err != nil {
+		return nil, err
+	}
+
+	hostDrivers := make(map[string]string)
+	defer func() {
+		if retErr != nil {
+			if err := RestoreHostDrivers(sysfs, hostDrivers); err != nil {
+				deviceLogger().WithError(err).WithField("iommu-group", group).Error("Failed to roll back IOMMU group binding")
+			}
+		}
+	}()
+
+	for _, deviceFile := range deviceFiles {
+		bdf := deviceFile.Name()
+
+		// Mediated devices are bound through their parent device
+		if len(strings.Split(bdf, ":")) != 3 {
+			continue
+		}
+
+		ignore, err := checkIgnorePCIClass(GetPCIDeviceProperty(sysfs, bdf, PCISysFsDevicesClass), bdf, 0x0600)
+		if err != nil {
+			return nil, err
+		}
+		if ignore {
+			continue
+		}
+
+		driver, err := GetPCIDeviceDriver(sysfs, bdf)
+		if err != nil {
+			return nil, err
+		}
+		if driver == vfioPCIDriver {
+			continue
+		}
+
+		// Record the driver first, binding may fail after unbinding
+		// the device from it.
+		hostDrivers[bdf] = driver
+		if _, err := BindDevicetoVFIO(sysfs, bdf, driver); err != nil {
+			return nil, fmt.Errorf("failed to bind %s to %s: %w", bdf, vfioPCIDriver, err)
+		}
+	}
+
+	return hostDrivers, nil
+}
+
+// RestoreHostDrivers binds the devices of a BDF to driver map back to their
+// drivers, whether they are bound to vfio-pci or to no driver at all. The
+// devices bound back are removed from the map.
+func RestoreHostDrivers(sysfs config.SysfsProvider, hostDrivers map[string]string) error {
+	var errs []error
+
+	for bdf, driver := range hostDrivers {
+		// Clear driver_override for a device that was not bound to any
+		// driver, it is only unbound from vfio-pci.
+		override := driver
+		if override == "" {
+			override = "\n"
+		}
+
+		deviceLogger().WithFields(logrus.Fields{
+			"device-bdf":  bdf,
+			"host-driver": driver,
+		}).Info("Binding device back to its host driver")
+
+		if err := sysfs.WriteFile(fmt.Sprintf(pciDriverOverridePath, bdf), []byte(override)); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		// The device is not bound to any driver if binding it to
+		// vfio-pci failed, hence ignore error for this step.
+		sysfs.WriteFile(fmt.Sprintf(pciDriverUnbindPath, bdf), []byte(bdf))
+
+		if driver != "" {
+			if err := sysfs.WriteFile(driversProbePath, []byte(bdf)); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+
+		delete(hostDrivers, bdf)
+	}
+
+	return errors.Join(errs...)
+}
+
+// CheckIOMMUGroup checks that the devices of an IOMMU group can be passed
+// through to a VM, that is bound to vfio-pci unless bind is set, in which case
+// they are bound when the group is attached. PCI bridges are left out as they
+// are not passed through, as are mediated devices which are bound through
+// their parent device. The BDFs of the PCI devices to pass through are
+// returned.
+func CheckIOMMUGroup(sysfs config.SysfsProvider, group string, bind bool) ([]string, error) {
+	deviceFiles, err := sysfs.ReadDir(filepath.Join(config.SysIOMMUGroupPath, group, "devices"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: group %s: %v", ErrIOMMUGroupInconsistent, group, err)
+	}
+	if len(deviceFiles) == 0 {
+		return nil, fmt.Errorf("%w: group %s has no device", ErrIOMMUGroupInconsistent, group)
+	}
+
+	var bdfs []string
+	for _, deviceFile := range deviceFiles {
+		bdf := deviceFile.Name()
+		if len(strings.Split(bdf, ":")) != 3 {
+			continue
+		}
+
+		ignore, err := checkIgnorePCIClass(GetPCIDeviceProperty(sysfs, bdf, PCISysFsDevicesClass), bdf, 0x0600)
+		if err != nil {
+			return nil, err
+		}
+		if ignore {
+			continue
+		}
+
+		if !bind {
+			driver, err := GetPCIDeviceDriver(sysfs, bdf)
+			if err != nil {
+				return nil, err
+			}
+			if driver != vfioPCIDriver {
+				return nil, fmt.Errorf("%w: %s of group %s is bound to %q", ErrVFIODeviceNotBound, bdf, group, driver)
+			}
+		}
+
+		bdfs = append(bdfs, bdf)
+	}
+
+	return bdfs, nil
+}
+
+// ResolveIOMMUGroup returns the IOMMU group of a VFIO device to bind to
+// vfio-pci, from sysfs since its /dev/vfio/<group> device only exists once
+// bound. The device is either the VFIO group device, /dev/vfio/<group>, or a
+// PCI device of the group, /sys/bus/pci/devices/<bdf>.
+func ResolveIOMMUGroup(sysfs config.SysfsProvider, hostPath string) (string, error) {
+	if filepath.Dir(hostPath) == config.SysBusPciDevicesPath {
+		return GetIOMMUGroup(sysfs, filepath.Base(hostPath))
+	}
+
+	group := filepath.Base(hostPath)
+	if _, err := sysfs.Stat(filepath.Join(config.SysIOMMUGroupPath, group)); err != nil {
+		return "", fmt.Errorf("%w: group %s: %v", ErrIOMMUGroupInconsistent, group, err)
+	}
+
+	return group, nil
+}
+
+// GetIOMMUGroup returns the IOMMU group of a PCI device.
+func GetIOMMUGroup(sysfs config.SysfsProvider, bdf string) (string, error) {
+	groupPath, err := sysfs.Readlink(fmt.Sprintf(iommuGroupPath, bdf))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s has no IOMMU group: %v", ErrIOMMUGroupInconsistent, bdf, err)
+	}
+
+	return filepath.Base(groupPath), nil
 }
@@ -7,8 +7,13 @@
 package drivers
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/api"
 	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
 	"github.com/stretchr/testify/assert"
 )
@@ -29,7 +34,7 @@ func TestGetVFIODetails(t *testing.T) {
 	}
 
 	for _, d := range data {
-		deviceBDF, deviceSysfsDev, vfioDeviceType, err := GetVFIODetails(d.deviceStr, "")
+		deviceBDF, deviceSysfsDev, vfioDeviceType, err := GetVFIODetails(config.NewRootedSysfs("/"), d.deviceStr, "")
 
 		switch vfioDeviceType {
 		case config.VFIOPCIDeviceNormalType:
@@ -48,3 +53,338 @@ func TestGetVFIODetails(t *testing.T) {
 	}
 
 }
+
+const (
+	fixtureMdevUUID = "f79944e4-5a3d-11e8-99ce-479cbab002e4"
+	fixtureAPUUID   = "83b8f4f2-509f-382f-3c1e-e6bfe0fa1001"
+)
+
+// setupSysfsFixture builds a sysfs tree with:
+// - IOMMU group 12 holding the PCIe NIC 0000:01:00.0 and a host bridge
+// - IOMMU group 13 holding a mediated device of the GPU 0000:00:02.0
+// - IOMMU group 14 holding a VFIO-AP mediated device
+// - IOMMU group 16 holding the functions of a NIC, the second one missing its
+// driver_override
+// - IOMMU group 17 holding a device bound to vfio-pci
+// It returns the root of the tree and the provider resolving the paths under
+// it.
+func setupSysfsFixture(t *testing.T) (string, config.SysfsProvider) {
+	root := t.TempDir()
+
+	mkdir := func(dir string) {
+		assert.NoError(t, os.MkdirAll(filepath.Join(root, dir), 0755))
+	}
+	write := func(file, content string) {
+		mkdir(filepath.Dir(file))
+		assert.NoError(t, os.WriteFile(filepath.Join(root, file), []byte(content), 0644))
+	}
+	link := func(target, name string) {
+		mkdir(filepath.Dir(name))
+		assert.NoError(t, os.Symlink(target, filepath.Join(root, name)))
+	}
+
+	nic := "/sys/devices/pci0000:00/0000:00:01.0/0000:01:00.0"
+	write(nic+"/config", strings.Repeat("\x00", 4096))
+	write(nic+"/class", "0x020000\n")
+	write(nic+"/vendor", "0x8086\n")
+	write(nic+"/device", "0x1572\n")
+	write(nic+"/driver_override", "")
+	write("/sys/bus/pci/drivers/i40e/unbind", "")
+	link("../../../../bus/pci/drivers/i40e", nic+"/driver")
+	mkdir(nic + "/vfio-dev/vfio0")
+	link("../../../kernel/iommu_groups/12", nic+"/iommu_group")
+	link("../../../devices/pci0000:00/0000:00:01.0/0000:01:00.0", "/sys/bus/pci/devices/0000:01:00.0")
+	link("../../../../devices/pci0000:00/0000:00:01.0/0000:01:00.0", "/sys/kernel/iommu_groups/12/devices/0000:01:00.0")
+	link("../../devices/pci0000:00/0000:00:01.0/0000:01:00.0/vfio-dev/vfio0", "/sys/dev/char/237:0")
+
+	bridge := "/sys/devices/pci0000:00/0000:00:00.0"
+	write(bridge+"/config", strings.Repeat("\x00", 256))
+	write(bridge+"/class", "0x060000\n")
+	link("../../../devices/pci0000:00/0000:00:00.0", "/sys/bus/pci/devices/0000:00:00.0")
+	link("../../../../devices/pci0000:00/0000:00:00.0", "/sys/kernel/iommu_groups/12/devices/0000:00:00.0")
+
+	gpu := "/sys/devices/pci0000:00/0000:00:02.0"
+	write(gpu+"/config", strings.Repeat("\x00", 256))
+	write(gpu+"/class", "0x030000\n")
+	write(gpu+"/vendor", "0x8086\n")
+	write(gpu+"/device", "0x3e92\n")
+	mkdir(gpu + "/" + fixtureMdevUUID)
+	link("../../../devices/pci0000:00/0000:00:02.0", "/sys/bus/pci/devices/0000:00:02.0")
+	link("../../../../devices/pci0000:00/0000:00:02.0/"+fixtureMdevUUID, "/sys/kernel/iommu_groups/13/devices/"+fixtureMdevUUID)
+
+	write("/sys/devices/vfio_ap/matrix/"+fixtureAPUUID+"/matrix", "01.0004\n01.0005\n")
+	link("../../../../devices/vfio_ap/matrix/"+fixtureAPUUID, "/sys/kernel/iommu_groups/14/devices/"+fixtureAPUUID)
+
+	for _, bdf := range []string{"0000:03:00.0", "0000:03:00.1"} {
+		dev := "/sys/devices/pci0000:00/0000:00:03.0/" + bdf
+		write(dev+"/class", "0x020000\n")
+		link("../../../../bus/pci/drivers/ixgbe", dev+"/driver")
+		link("../../../kernel/iommu_groups/16", dev+"/iommu_group")
+		link("../../../devices/pci0000:00/0000:00:03.0/"+bdf, "/sys/bus/pci/devices/"+bdf)
+		link("../../../../devices/pci0000:00/0000:00:03.0/"+bdf, "/sys/kernel/iommu_groups/16/devices/"+bdf)
+	}
+	write("/sys/devices/pci0000:00/0000:00:03.0/0000:03:00.0/driver_override", "")
+	write("/sys/bus/pci/drivers/ixgbe/unbind", "")
+
+	bound := "/sys/devices/pci0000:00/0000:00:04.0/0000:04:00.0"
+	write(bound+"/class", "0x030200\n")
+	write("/sys/bus/pci/drivers/vfio-pci/unbind", "")
+	link("../../../../bus/pci/drivers/vfio-pci", bound+"/driver")
+	link("../../../devices/pci0000:00/0000:00:04.0/0000:04:00.0", "/sys/bus/pci/devices/0000:04:00.0")
+	link("../../../../devices/pci0000:00/0000:00:04.0/0000:04:00.0", "/sys/kernel/iommu_groups/17/devices/0000:04:00.0")
+
+	write("/sys/bus/pci/drivers_probe", "")
+
+	return root, config.NewRootedSysfs(root)
+}
+
+func TestIsPCIeDeviceSysfs(t *testing.T) {
+	assert := assert.New(t)
+
+	_, sysfs := setupSysfsFixture(t)
+
+	assert.True(IsPCIeDevice(sysfs, "0000:01:00.0"))
+	assert.True(IsPCIeDevice(sysfs, "01:00.0"))
+	assert.False(IsPCIeDevice(sysfs, "0000:00:00.0"))
+	assert.False(IsPCIeDevice(sysfs, "0000:05:00.0"))
+
+	assert.Equal("0x8086", GetPCIDeviceProperty(sysfs, "01:00.0", PCISysFsDevicesVendor))
+	assert.Equal("", GetPCIDeviceProperty(sysfs, "0000:00:00.0", PCISysFsDevicesVendor))
+}
+
+func TestGetAllVFIODevicesFromIOMMUGroupSysfs(t *testing.T) {
+	assert := assert.New(t)
+
+	_, sysfs := setupSysfsFixture(t)
+
+	// The host bridge sharing the IOMMU group is skipped
+	devs, err := GetAllVFIODevicesFromIOMMUGroup(sysfs, config.DeviceInfo{ID: "nic", HostPath: "/dev/vfio/12", Port: config.RootPort})
+	assert.NoError(err)
+	assert.Len(devs, 1)
+	assert.Equal(config.VFIOPCIDeviceNormalType, devs[0].Type)
+	assert.Equal("0000:01:00.0", devs[0].BDF)
+	assert.Equal("/sys/bus/pci/devices/0000:01:00.0", devs[0].SysfsDev)
+	assert.True(devs[0].IsPCIe)
+	assert.Equal("0x020000", devs[0].Class)
+	assert.Equal("0x8086", devs[0].VendorID)
+	assert.Equal("0x1572", devs[0].DeviceID)
+	assert.Equal(config.RootPort, devs[0].Port)
+
+	devs, err = GetAllVFIODevicesFromIOMMUGroup(sysfs, config.DeviceInfo{ID: "gpu", HostPath: "/dev/vfio/13"})
+	assert.NoError(err)
+	assert.Len(devs, 1)
+	assert.Equal(config.VFIOPCIDeviceMediatedType, devs[0].Type)
+	assert.Equal("00:02.0", devs[0].BDF)
+	assert.Equal("/sys/devices/pci0000:00/0000:00:02.0/"+fixtureMdevUUID, devs[0].SysfsDev)
+	assert.False(devs[0].IsPCIe)
+	assert.Equal("0x3e92", devs[0].DeviceID)
+
+	devs, err = GetAllVFIODevicesFromIOMMUGroup(sysfs, config.DeviceInfo{ID: "ap", HostPath: "/dev/vfio/14"})
+	assert.NoError(err)
+	assert.Len(devs, 1)
+	assert.Equal(config.VFIOAPDeviceMediatedType, devs[0].Type)
+	assert.Equal([]string{"01.0004", "01.0005"}, devs[0].APDevices)
+
+	_, err = GetAllVFIODevicesFromIOMMUGroup(sysfs, config.DeviceInfo{ID: "none", HostPath: "/dev/vfio/15"})
+	assert.Error(err)
+}
+
+func TestGetBDFFromVFIODevSysfs(t *testing.T) {
+	assert := assert.New(t)
+
+	_, sysfs := setupSysfsFixture(t)
+
+	bdf, err := GetBDFFromVFIODev(sysfs, 237, 0)
+	assert.NoError(err)
+	assert.Equal("0000:01:00.0", bdf)
+
+	_, err = GetBDFFromVFIODev(sysfs, 237, 1)
+	assert.Error(err)
+}
+
+func TestBindDevicetoVFIOSysfs(t *testing.T) {
+	assert := assert.New(t)
+
+	root, sysfs := setupSysfsFixture(t)
+	nic := filepath.Join(root, "sys/devices/pci0000:00/0000:00:01.0/0000:01:00.0")
+
+	vfioPath, err := BindDevicetoVFIO(sysfs, "0000:01:00.0", "i40e")
+	assert.NoError(err)
+	assert.Equal("/dev/vfio/12", vfioPath)
+
+	data, err := os.ReadFile(filepath.Join(nic, "driver_override"))
+	assert.NoError(err)
+	assert.Equal("vfio-pci", string(data))
+	data, err = os.ReadFile(filepath.Join(root, "sys/bus/pci/drivers/i40e/unbind"))
+	assert.NoError(err)
+	assert.Equal("0000:01:00.0", string(data))
+	data, err = os.ReadFile(filepath.Join(root, "sys/bus/pci/drivers_probe"))
+	assert.NoError(err)
+	assert.Equal("0000:01:00.0", string(data))
+
+	assert.NoError(BindDevicetoHost(sysfs, "0000:01:00.0", "i40e"))
+	data, err = os.ReadFile(filepath.Join(nic, "driver_override"))
+	assert.NoError(err)
+	assert.Equal("i40e", string(data))
+
+	// Not a device of the tree
+	_, err = BindDevicetoVFIO(sysfs, "0000:05:00.0", "i40e")
+	assert.Error(err)
+}
+
+func TestBindIOMMUGroupToVFIOSysfs(t *testing.T) {
+	assert := assert.New(t)
+
+	root, sysfs := setupSysfsFixture(t)
+	readFile := func(file string) string {
+		data, err := os.ReadFile(filepath.Join(root, file))
+		assert.NoError(err)
+		return string(data)
+	}
+
+	driver, err := GetPCIDeviceDriver(sysfs, "0000:01:00.0")
+	assert.NoError(err)
+	assert.Equal("i40e", driver)
+	driver, err = GetPCIDeviceDriver(sysfs, "0000:00:02.0")
+	assert.NoError(err)
+	assert.Empty(driver)
+
+	// The host bridge is not bound
+	hostDrivers, err := BindIOMMUGroupToVFIO(sysfs, "12")
+	assert.NoError(err)
+	assert.Equal(map[string]string{"0000:01:00.0": "i40e"}, hostDrivers)
+	assert.Equal("vfio-pci", readFile("sys/bus/pci/devices/0000:01:00.0/driver_override"))
+
+	assert.NoError(RestoreHostDrivers(sysfs, hostDrivers))
+	assert.Empty(hostDrivers)
+	assert.Equal("i40e", readFile("sys/bus/pci/devices/0000:01:00.0/driver_override"))
+
+	// Already bound to vfio-pci
+	hostDrivers, err = BindIOMMUGroupToVFIO(sysfs, "17")
+	assert.NoError(err)
+	assert.Empty(hostDrivers)
+
+	// The first function is bound back when the second fails
+	hostDrivers, err = BindIOMMUGroupToVFIO(sysfs, "16")
+	assert.Error(err)
+	assert.Nil(hostDrivers)
+	assert.Equal("ixgbe", readFile("sys/bus/pci/devices/0000:03:00.0/driver_override"))
+	assert.Equal("0000:03:00.0", readFile("sys/bus/pci/drivers_probe"))
+
+	_, err = BindIOMMUGroupToVFIO(sysfs, "15")
+	assert.Error(err)
+}
+
+func TestVFIODeviceBindLifecycle(t *testing.T) {
+	assert := assert.New(t)
+
+	root, sysfs := setupSysfsFixture(t)
+	driverOverride := func() string {
+		data, err := os.ReadFile(filepath.Join(root, "sys/bus/pci/devices/0000:01:00.0/driver_override"))
+		assert.NoError(err)
+		return string(data)
+	}
+
+	savedDevicesPerPort := config.PCIeDevicesPerPort
+	config.PCIeDevicesPerPort = map[config.PCIePort][]config.VFIODev{config.RootPort: {}}
+	defer func() {
+		config.PCIeDevicesPerPort = savedDevicesPerPort
+	}()
+
+	devReceiver := &api.MockDeviceReceiver{}
+
+	// Hot plugged devices are bound back when detached
+	device := NewVFIODevice(&config.DeviceInfo{ID: "nic", HostPath: "/dev/vfio/12", Port: config.RootPort, BindVFIO: true}, sysfs)
+	assert.NoError(device.Attach(context.Background(), devReceiver))
+	assert.Equal(map[string]string{"0000:01:00.0": "i40e"}, device.HostDrivers)
+	assert.Equal("vfio-pci", driverOverride())
+
+	assert.NoError(device.Detach(context.Background(), devReceiver))
+	assert.Empty(device.HostDrivers)
+	assert.Equal("i40e", driverOverride())
+
+	// The group of a PCI device is resolved before it is bound
+	device = NewVFIODevice(&config.DeviceInfo{ID: "nic", HostPath: "/sys/bus/pci/devices/0000:01:00.0", Port: config.RootPort, BindVFIO: true}, sysfs)
+	assert.NoError(device.Attach(context.Background(), devReceiver))
+	assert.Equal(map[string]string{"0000:01:00.0": "i40e"}, device.HostDrivers)
+	assert.Equal("/sys/bus/pci/devices/0000:01:00.0", device.DeviceInfo.HostPath)
+
+	assert.NoError(device.Detach(context.Background(), devReceiver))
+	assert.Equal("i40e", driverOverride())
+
+	// Rolled back when the attach fails
+	device = NewVFIODevice(&config.DeviceInfo{ID: "nic", HostPath: "/dev/vfio/12", BindVFIO: true}, sysfs)
+	assert.Error(device.Attach(context.Background(), devReceiver))
+	assert.Empty(device.HostDrivers)
+	assert.Equal("i40e", driverOverride())
+
+	// Cold plugged devices are bound back once the VM is stopped
+	device = NewVFIODevice(&config.DeviceInfo{ID: "nic", HostPath: "/dev/vfio/12", Port: config.RootPort, BindVFIO: true, ColdPlug: true}, sysfs)
+	assert.NoError(device.Attach(context.Background(), devReceiver))
+	assert.NoError(device.Detach(context.Background(), devReceiver))
+	assert.Equal(map[string]string{"0000:01:00.0": "i40e"}, device.HostDrivers)
+	assert.Equal("vfio-pci", driverOverride())
+
+	// The host drivers survive a restart
+	loaded := NewVFIODevice(&config.DeviceInfo{}, sysfs)
+	loaded.Load(device.Save())
+	assert.Equal(device.HostDrivers, loaded.HostDrivers)
+
+	assert.NoError(loaded.RestoreHostDrivers())
+	assert.Empty(loaded.HostDrivers)
+	assert.Equal("i40e", driverOverride())
+}
+
+func TestCheckIOMMUGroupSysfs(t *testing.T) {
+	assert := assert.New(t)
+
+	_, sysfs := setupSysfsFixture(t)
+
+	// The host bridge is not passed through
+	bdfs, err := CheckIOMMUGroup(sysfs, "17", false)
+	assert.NoError(err)
+	assert.Equal([]string{"0000:04:00.0"}, bdfs)
+
+	_, err = CheckIOMMUGroup(sysfs, "12", false)
+	assert.ErrorIs(err, ErrVFIODeviceNotBound)
+
+	// The group is bound when attached
+	bdfs, err = CheckIOMMUGroup(sysfs, "12", true)
+	assert.NoError(err)
+	assert.Equal([]string{"0000:01:00.0"}, bdfs)
+
+	// Mediated devices are bound through their parent
+	bdfs, err = CheckIOMMUGroup(sysfs, "13", false)
+	assert.NoError(err)
+	assert.Empty(bdfs)
+
+	_, err = CheckIOMMUGroup(sysfs, "15", false)
+	assert.ErrorIs(err, ErrIOMMUGroupInconsistent)
+
+	group, err := GetIOMMUGroup(sysfs, "0000:01:00.0")
+	assert.NoError(err)
+	assert.Equal("12", group)
+
+	_, err = GetIOMMUGroup(sysfs, "0000:05:00.0")
+	assert.ErrorIs(err, ErrIOMMUGroupInconsistent)
+}
+
+func TestResolveIOMMUGroupSysfs(t *testing.T) {
+	assert := assert.New(t)
+
+	_, sysfs := setupSysfsFixture(t)
+
+	group, err := ResolveIOMMUGroup(sysfs, "/dev/vfio/12")
+	assert.NoError(err)
+	assert.Equal("12", group)
+
+	group, err = ResolveIOMMUGroup(sysfs, "/sys/bus/pci/devices/0000:01:00.0")
+	assert.NoError(err)
+	assert.Equal("12", group)
+
+	_, err = ResolveIOMMUGroup(sysfs, "/dev/vfio/42")
+	assert.ErrorIs(err, ErrIOMMUGroupInconsistent)
+
+	_, err = ResolveIOMMUGroup(sysfs, "/sys/bus/pci/devices/0000:05:00.0")
+	assert.ErrorIs(err, ErrIOMMUGroupInconsistent)
+}
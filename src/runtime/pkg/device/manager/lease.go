@@ -0,0 +1,255 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package manager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	pkgDevice "github.com/kata-containers/kata-containers/src/runtime/pkg/device"
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/drivers"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	leasesFile     = "devices/leases.json"
+	leasesLockFile = "devices/leases.lock"
+)
+
+// ErrDeviceLeased represents that the device is used by another sandbox
+var ErrDeviceLeased = errors.New("device is used by another sandbox")
+
+// LeaseMode is the access a sandbox requires to a device.
+type LeaseMode string
+
+const (
+	// LeaseExclusive is a device only one sandbox can use.
+	LeaseExclusive LeaseMode = "exclusive"
+	// LeaseShared is a read-only device several sandboxes can use.
+	LeaseShared LeaseMode = "shared"
+)
+
+// LeaseStore is the node-wide storage of the device leases, as provided by
+// the persist driver.
+type LeaseStore interface {
+	GlobalWrite(relativePath string, data []byte) error
+	GlobalRead(relativePath string) ([]byte, error)
+	GlobalLock(relativePath string, exclusive bool) (func() error, error)
+}
+
+type deviceLease struct {
+	Mode    LeaseMode `json:"mode"`
+	Holders []string  `json:"holders"`
+}
+
+// DeviceLeases is the node-local registry of the block devices, passed through
+// character devices and VFIO groups claimed by the sandboxes. It prevents two sandboxes
+// from using the same device, unless both only read it.
+type DeviceLeases struct {
+	store     LeaseStore
+	sandboxID string
+
+	// isAlive reports whether a sandbox holding leases still exists, the
+	// leases of the sandboxes gone without releasing them are dropped.
+	isAlive func(sandboxID string) bool
+}
+
+// NewDeviceLeases returns the device lease registry for a sandbox.
+func NewDeviceLeases(store LeaseStore, sandboxID string, isAlive func(sandboxID string) bool) *DeviceLeases {
+	return &DeviceLeases{
+		store:     store,
+		sandboxID: sandboxID,
+		isAlive:   isAlive,
+	}
+}
+
+// deviceLeaseKey returns the lease key and mode of a device, and false if the
+// device does not need a lease. The VFIO devices are resolved through sysfs.
+func deviceLeaseKey(sysfs config.SysfsProvider, devInfo config.DeviceInfo) (string, LeaseMode, bool, error) {
+	// A pmem volume file is mapped shared by the hypervisor, the guest
+	// writes land directly in it. The volumes have no major number unlike
+	// the guest image, the loop device backing file of which is not
+	// claimed.
+	if devInfo.Pmem {
+		if devInfo.Major != -1 {
+			return "", "", false, nil
+		}
+
+		mode := LeaseExclusive
+		if devInfo.ReadOnly {
+			mode = LeaseShared
+		}
+		return "pmem:" + filepath.Clean(devInfo.HostPath), mode, true, nil
+	}
+
+	if isVFIO(devInfo) {
+		key, err := vfioLeaseKey(sysfs, devInfo.HostPath)
+		return key, LeaseExclusive, err == nil, err
+	}
+
+	if isBlock(devInfo) {
+		mode := LeaseExclusive
+		if devInfo.ReadOnly {
+			mode = LeaseShared
+		}
+		return fmt.Sprintf("block:%d:%d", devInfo.Major, devInfo.Minor), mode, true, nil
+	}
+
+	// The character devices passed to the guest, a SCSI generic device
+	// issuing the guest SCSI commands or a device relayed through a
+	// virtio-serial port, cannot be shared with another sandbox.
+	if (devInfo.SCSIPassthrough || devInfo.CharPassthrough) && devInfo.DevType == "c" {
+		return fmt.Sprintf("char:%d:%d", devInfo.Major, devInfo.Minor), LeaseExclusive, true, nil
+	}
+
+	return "", "", false, nil
+}
+
+// vfioLeaseKey returns the lease key of a VFIO device, that is of its IOMMU
+// group as the devices of a group are passed through together. The device is
+// either the VFIO group device, a PCI device of the group to bind or an
+// IOMMUFD device, all resolving to the same key.
+func vfioLeaseKey(sysfs config.SysfsProvider, hostPath string) (string, error) {
+	var group string
+	var err error
+
+	if strings.HasPrefix(hostPath, pkgDevice.IommufdDevPath) {
+		var major, minor uint32
+		if major, minor, err = drivers.GetMajorMinorFromDevPath(sysfs, hostPath); err != nil {
+			return "", fmt.Errorf("Failed to get major:minor from %s: %v", hostPath, err)
+		}
+
+		var bdf string
+		if bdf, err = drivers.GetBDFFromVFIODev(sysfs, major, minor); err != nil {
+			return "", err
+		}
+		group, err = drivers.GetIOMMUGroup(sysfs, bdf)
+	} else {
+		group, err = drivers.ResolveIOMMUGroup(sysfs, hostPath)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return "vfio:" + group, nil
+}
+
+// Acquire claims a device for the sandbox. It fails if another sandbox uses
+// the device, unless both only read it.
+func (l *DeviceLeases) Acquire(key string, mode LeaseMode) error {
+	return l.update(func(leases map[string]*deviceLease) error {
+		lease, ok := leases[key]
+		if !ok || len(lease.Holders) == 0 {
+			lease = &deviceLease{}
+			leases[key] = lease
+		}
+
+		held := slices.Contains(lease.Holders, l.sandboxID)
+		for _, holder := range lease.Holders {
+			if holder == l.sandboxID {
+				continue
+			}
+			if mode == LeaseExclusive || lease.Mode == LeaseExclusive {
+				return fmt.Errorf("%w: %s is used %s by sandbox %s", ErrDeviceLeased, key, lease.Mode, holder)
+			}
+		}
+
+		if !held {
+			lease.Holders = append(lease.Holders, l.sandboxID)
+		}
+		if lease.Mode != LeaseExclusive {
+			lease.Mode = mode
+		}
+		return nil
+	})
+}
+
+// Release releases a device claimed by the sandbox.
+func (l *DeviceLeases) Release(key string) error {
+	return l.update(func(leases map[string]*deviceLease) error {
+		if lease, ok := leases[key]; ok {
+			lease.Holders = slices.DeleteFunc(lease.Holders, func(holder string) bool {
+				return holder == l.sandboxID
+			})
+		}
+		return nil
+	})
+}
+
+// ReleaseAll releases all the devices claimed by the sandbox.
+func (l *DeviceLeases) ReleaseAll() error {
+	return l.update(func(leases map[string]*deviceLease) error {
+		for _, lease := range leases {
+			lease.Holders = slices.DeleteFunc(lease.Holders, func(holder string) bool {
+				return holder == l.sandboxID
+			})
+		}
+		return nil
+	})
+}
+
+// update applies fn to the leases of the node, under the lease registry lock.
+func (l *DeviceLeases) update(fn func(map[string]*deviceLease) error) error {
+	unlock, err := l.store.GlobalLock(leasesLockFile, true)
+	if err != nil {
+		return fmt.Errorf("failed to lock device leases: %w", err)
+	}
+	defer unlock()
+
+	leases := make(map[string]*deviceLease)
+	data, err := l.store.GlobalRead(leasesFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read device leases: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &leases); err != nil {
+			return fmt.Errorf("failed to decode device leases: %w", err)
+		}
+	}
+
+	l.dropStaleHolders(leases)
+
+	if err := fn(leases); err != nil {
+		return err
+	}
+
+	for key, lease := range leases {
+		if len(lease.Holders) == 0 {
+			delete(leases, key)
+		}
+	}
+
+	data, err = json.Marshal(leases)
+	if err != nil {
+		return err
+	}
+	return l.store.GlobalWrite(leasesFile, data)
+}
+
+func (l *DeviceLeases) dropStaleHolders(leases map[string]*deviceLease) {
+	if l.isAlive == nil {
+		return
+	}
+
+	for key, lease := range leases {
+		lease.Holders = slices.DeleteFunc(lease.Holders, func(holder string) bool {
+			if holder == l.sandboxID || l.isAlive(holder) {
+				return false
+			}
+			deviceLogger().WithFields(logrus.Fields{
+				"device":  key,
+				"sandbox": holder,
+			}).Warn("Dropping device lease of a sandbox that no longer exists")
+			return true
+		})
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package manager
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockLeaseStore is an in-memory LeaseStore.
+type mockLeaseStore struct {
+	sync.Mutex
+	files map[string][]byte
+}
+
+func newMockLeaseStore() *mockLeaseStore {
+	return &mockLeaseStore{files: make(map[string][]byte)}
+}
+
+func (m *mockLeaseStore) GlobalWrite(relativePath string, data []byte) error {
+	m.files[relativePath] = data
+	return nil
+}
+
+func (m *mockLeaseStore) GlobalRead(relativePath string) ([]byte, error) {
+	data, ok := m.files[relativePath]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return data, nil
+}
+
+func (m *mockLeaseStore) GlobalLock(relativePath string, exclusive bool) (func() error, error) {
+	m.Lock()
+	return func() error {
+		m.Unlock()
+		return nil
+	}, nil
+}
+
+func TestDeviceLeaseKey(t *testing.T) {
+	assert := assert.New(t)
+
+	root := t.TempDir()
+	assert.NoError(os.MkdirAll(filepath.Join(root, "sys/kernel/iommu_groups/12/devices/0000:01:00.0"), 0755))
+	assert.NoError(os.MkdirAll(filepath.Join(root, "sys/bus/pci/devices/0000:01:00.0"), 0755))
+	assert.NoError(os.Symlink("../../../../kernel/iommu_groups/12", filepath.Join(root, "sys/bus/pci/devices/0000:01:00.0/iommu_group")))
+
+	sysfs := config.NewRootedSysfs(root)
+
+	// A VFIO group and a PCI device of the group to bind share the lease
+	key, mode, ok, err := deviceLeaseKey(sysfs, config.DeviceInfo{HostPath: "/dev/vfio/12", DevType: "c"})
+	assert.NoError(err)
+	assert.True(ok)
+	assert.Equal("vfio:12", key)
+	assert.Equal(LeaseExclusive, mode)
+
+	key, mode, ok, err = deviceLeaseKey(sysfs, config.DeviceInfo{HostPath: "/sys/bus/pci/devices/0000:01:00.0", ContainerPath: "/dev/vfio/12", DevType: "c", BindVFIO: true})
+	assert.NoError(err)
+	assert.True(ok)
+	assert.Equal("vfio:12", key)
+	assert.Equal(LeaseExclusive, mode)
+
+	_, _, _, err = deviceLeaseKey(sysfs, config.DeviceInfo{HostPath: "/dev/vfio/13", DevType: "c"})
+	assert.Error(err)
+
+	key, mode, ok, _ = deviceLeaseKey(sysfs, config.DeviceInfo{HostPath: "/dev/sdb", DevType: "b", Major: 8, Minor: 16})
+	assert.True(ok)
+	assert.Equal("block:8:16", key)
+	assert.Equal(LeaseExclusive, mode)
+
+	_, mode, ok, _ = deviceLeaseKey(sysfs, config.DeviceInfo{HostPath: "/dev/sdb", DevType: "b", Major: 8, Minor: 16, ReadOnly: true})
+	assert.True(ok)
+	assert.Equal(LeaseShared, mode)
+
+	key, mode, ok, _ = deviceLeaseKey(sysfs, config.DeviceInfo{HostPath: "/var/lib/pmem//volume.img", DevType: "b", Major: -1, Pmem: true})
+	assert.True(ok)
+	assert.Equal("pmem:/var/lib/pmem/volume.img", key)
+	assert.Equal(LeaseExclusive, mode)
+
+	_, mode, ok, _ = deviceLeaseKey(sysfs, config.DeviceInfo{HostPath: "/var/lib/pmem/volume.img", DevType: "b", Major: -1, Pmem: true, ReadOnly: true})
+	assert.True(ok)
+	assert.Equal(LeaseShared, mode)
+
+	// The pmem guest image is not claimed
+	_, _, ok, _ = deviceLeaseKey(sysfs, config.DeviceInfo{HostPath: "/usr/share/kata-containers/kata-containers.img", DevType: "b", Major: 7, Minor: 0, Pmem: true})
+	assert.False(ok)
+
+	_, _, ok, _ = deviceLeaseKey(sysfs, config.DeviceInfo{HostPath: "/dev/null", DevType: "c", Major: 1, Minor: 3})
+	assert.False(ok)
+
+	key, mode, ok, _ = deviceLeaseKey(sysfs, config.DeviceInfo{HostPath: "/dev/sg1", DevType: "c", Major: config.SCSIGenericMajor, Minor: 1, SCSIPassthrough: true, ReadOnly: true})
+	assert.True(ok)
+	assert.Equal("char:21:1", key)
+	assert.Equal(LeaseExclusive, mode)
+}
+
+func TestDeviceLeases(t *testing.T) {
+	assert := assert.New(t)
+
+	store := newMockLeaseStore()
+	alive := map[string]bool{"foo": true, "bar": true, "baz": true}
+	isAlive := func(id string) bool { return alive[id] }
+
+	foo := NewDeviceLeases(store, "foo", isAlive)
+	bar := NewDeviceLeases(store, "bar", isAlive)
+	baz := NewDeviceLeases(store, "baz", isAlive)
+
+	// Exclusive devices
+	assert.NoError(foo.Acquire("vfio:12", LeaseExclusive))
+	assert.NoError(foo.Acquire("vfio:12", LeaseExclusive))
+	err := bar.Acquire("vfio:12", LeaseExclusive)
+	assert.True(errors.Is(err, ErrDeviceLeased))
+	assert.Contains(err.Error(), "sandbox foo")
+	assert.Error(bar.Acquire("vfio:12", LeaseShared))
+
+	// Shared read-only devices
+	assert.NoError(foo.Acquire("block:8:16", LeaseShared))
+	assert.NoError(bar.Acquire("block:8:16", LeaseShared))
+	err = baz.Acquire("block:8:16", LeaseExclusive)
+	assert.True(errors.Is(err, ErrDeviceLeased))
+
+	// Released devices can be claimed again
+	assert.NoError(foo.Release("vfio:12"))
+	assert.NoError(bar.Acquire("vfio:12", LeaseExclusive))
+
+	assert.NoError(foo.ReleaseAll())
+	assert.NoError(bar.ReleaseAll())
+	assert.NoError(baz.Acquire("block:8:16", LeaseExclusive))
+	assert.NoError(baz.Acquire("vfio:12", LeaseExclusive))
+
+	// The leases of the sandboxes gone are dropped
+	alive["baz"] = false
+	assert.NoError(foo.Acquire("block:8:16", LeaseExclusive))
+	assert.NoError(foo.ReleaseAll())
+	assert.Equal("{}", string(store.files[leasesFile]))
+}
+
+func TestDeviceManagerLeases(t *testing.T) {
+	assert := assert.New(t)
+
+	store := newMockLeaseStore()
+	isAlive := func(string) bool { return true }
+
+	savedGetHostPath := config.GetHostPathFunc
+	config.GetHostPathFunc = func(_ config.SysfsProvider, devInfo config.DeviceInfo, _ bool, _ string) (string, error) {
+		return devInfo.HostPath, nil
+	}
+	defer func() {
+		config.GetHostPathFunc = savedGetHostPath
+	}()
+
+	fooDm := NewDeviceManager(config.VirtioBlock, false, "", 0, nil, NewDeviceLeases(store, "foo", isAlive), nil)
+	barDm := NewDeviceManager(config.VirtioBlock, false, "", 0, nil, NewDeviceLeases(store, "bar", isAlive), nil)
+
+	devInfo := config.DeviceInfo{
+		HostPath:      "/dev/sdb",
+		ContainerPath: filepath.Join("/dev", "xvdb"),
+		DevType:       "b",
+		Major:         8,
+		Minor:         16,
+	}
+
+	device, err := fooDm.NewDevice(devInfo)
+	assert.NoError(err)
+
+	// Shared by the containers of a sandbox
+	again, err := fooDm.NewDevice(devInfo)
+	assert.NoError(err)
+	assert.Equal(device.DeviceID(), again.DeviceID())
+
+	_, err = barDm.NewDevice(devInfo)
+	assert.True(errors.Is(err, ErrDeviceLeased))
+
+	// Released with the last reference
+	assert.NoError(fooDm.RemoveDevice(device.DeviceID()))
+	_, err = barDm.NewDevice(devInfo)
+	assert.True(errors.Is(err, ErrDeviceLeased))
+	assert.NoError(fooDm.RemoveDevice(device.DeviceID()))
+	_, err = barDm.NewDevice(devInfo)
+	assert.NoError(err)
+
+	// Character devices are not claimed
+	_, err = fooDm.NewDevice(config.DeviceInfo{HostPath: "/dev/null", ContainerPath: "/dev/null", DevType: "c", Major: 1, Minor: 3})
+	assert.NoError(err)
+	_, err = barDm.NewDevice(config.DeviceInfo{HostPath: "/dev/null", ContainerPath: "/dev/null", DevType: "c", Major: 1, Minor: 3})
+	assert.NoError(err)
+}
+
+func TestDeviceManagerVFIOGroupLease(t *testing.T) {
+	assert := assert.New(t)
+
+	root := t.TempDir()
+	for _, bdf := range []string{"0000:01:00.0", "0000:01:00.1"} {
+		assert.NoError(os.MkdirAll(filepath.Join(root, "sys/kernel/iommu_groups/12/devices", bdf), 0755))
+		assert.NoError(os.MkdirAll(filepath.Join(root, "sys/bus/pci/devices", bdf), 0755))
+		assert.NoError(os.Symlink("../../../../kernel/iommu_groups/12", filepath.Join(root, "sys/bus/pci/devices", bdf, "iommu_group")))
+	}
+	sysfs := config.NewRootedSysfs(root)
+
+	store := newMockLeaseStore()
+	isAlive := func(string) bool { return true }
+
+	fooDm := NewDeviceManager(config.VirtioBlock, false, "", 0, nil, NewDeviceLeases(store, "foo", isAlive), sysfs)
+	barDm := NewDeviceManager(config.VirtioBlock, false, "", 0, nil, NewDeviceLeases(store, "bar", isAlive), sysfs)
+
+	// Two functions of the same IOMMU group share its lease
+	first, err := fooDm.NewDevice(config.DeviceInfo{HostPath: "/sys/bus/pci/devices/0000:01:00.0", ContainerPath: "/dev/vfio/12", DevType: "c", Major: 240, Minor: 0, BindVFIO: true})
+	assert.NoError(err)
+	second, err := fooDm.NewDevice(config.DeviceInfo{HostPath: "/sys/bus/pci/devices/0000:01:00.1", ContainerPath: "/dev/vfio/12", DevType: "c", Major: 240, Minor: 1, BindVFIO: true})
+	assert.NoError(err)
+	assert.NotEqual(first.DeviceID(), second.DeviceID())
+
+	// The group is held until its last device is removed
+	assert.NoError(fooDm.RemoveDevice(first.DeviceID()))
+	_, err = barDm.NewDevice(config.DeviceInfo{HostPath: "/sys/bus/pci/devices/0000:01:00.0", ContainerPath: "/dev/vfio/12", DevType: "c", Major: 240, Minor: 0, BindVFIO: true})
+	assert.True(errors.Is(err, ErrDeviceLeased))
+
+	assert.NoError(fooDm.RemoveDevice(second.DeviceID()))
+	_, err = barDm.NewDevice(config.DeviceInfo{HostPath: "/sys/bus/pci/devices/0000:01:00.0", ContainerPath: "/dev/vfio/12", DevType: "c", Major: 240, Minor: 0, BindVFIO: true})
+	assert.NoError(err)
+}
+
+func TestDeviceManagerLeasesRestored(t *testing.T) {
+	assert := assert.New(t)
+
+	store := newMockLeaseStore()
+	isAlive := func(string) bool { return true }
+
+	savedGetHostPath := config.GetHostPathFunc
+	config.GetHostPathFunc = func(_ config.SysfsProvider, devInfo config.DeviceInfo, _ bool, _ string) (string, error) {
+		return devInfo.HostPath, nil
+	}
+	defer func() {
+		config.GetHostPathFunc = savedGetHostPath
+	}()
+
+	devInfo := config.DeviceInfo{
+		HostPath:      "/dev/sdb",
+		ContainerPath: filepath.Join("/dev", "xvdb"),
+		DevType:       "b",
+		Major:         8,
+		Minor:         16,
+	}
+
+	fooDm := NewDeviceManager(config.VirtioBlock, false, "", 0, nil, NewDeviceLeases(store, "foo", isAlive), nil)
+	device, err := fooDm.NewDevice(devInfo)
+	assert.NoError(err)
+
+	// The lease key is saved with the device
+	states := []config.DeviceState{device.Save()}
+	assert.Equal("block:8:16", states[0].LeaseKey)
+
+	// and the lease is released once the device is removed after a restart
+	// of the shim
+	restartedDm := NewDeviceManager(config.VirtioBlock, false, "", 0, nil, NewDeviceLeases(store, "foo", isAlive), nil)
+	restartedDm.LoadDevices(states)
+
+	barDm := NewDeviceManager(config.VirtioBlock, false, "", 0, nil, NewDeviceLeases(store, "bar", isAlive), nil)
+	_, err = barDm.NewDevice(devInfo)
+	assert.True(errors.Is(err, ErrDeviceLeased))
+
+	assert.NoError(restartedDm.RemoveDevice(device.DeviceID()))
+	_, err = barDm.NewDevice(devInfo)
+	assert.NoError(err)
+}
@@ -29,6 +29,15 @@ var (
 	ErrDeviceNotExist = errors.New("device with specified ID hasn't been created")
 	// ErrDeviceNotAttached represents the device isn't attached
 	ErrDeviceNotAttached = errors.New("device isn't attached")
+	// ErrColdPlugVFIOInUse means that a cold plugged VFIO device is kept
+	// until the VM stops using it, to bind it back to its host drivers
+	// then with RestoreHostDrivers.
+	ErrColdPlugVFIOInUse = errors.New("cold plugged VFIO device is used until the VM stops")
+	// ErrVFIOHostDriversNotRestored means that a hot unplugged VFIO
+	// device could not be bound back to its host drivers, it is kept for
+	// the sandbox deletion to retry.
+	ErrVFIOHostDriversNotRestored = errors.New("VFIO device not bound back to its host drivers")
+
 	// ErrRemoveAttachedDevice represents the device isn't detached
 	// so not allow to remove from list
 	ErrRemoveAttachedDevice = errors.New("can't remove attached device")
@@ -45,6 +54,14 @@ type deviceManager struct {
 	vhostUserStoreEnabled bool
 
 	vhostUserReconnectTimeout uint32
+
+	// leases claims the devices across the sandboxes of the node, the
+	// lease keys of the devices are indexed by device ID.
+	leases    *DeviceLeases
+	leaseKeys map[string]string
+
+	// sysfs is where the host devices are discovered and bound
+	sysfs config.SysfsProvider
 }
 
 func deviceLogger() *logrus.Entry {
@@ -52,12 +69,17 @@ func deviceLogger() *logrus.Entry {
 }
 
 // NewDeviceManager creates a deviceManager object behaved as api.DeviceManager
-func NewDeviceManager(blockDriver string, vhostUserStoreEnabled bool, vhostUserStorePath string, vhostUserReconnect uint32, devices []api.Device) api.DeviceManager {
+// The devices are claimed through leases if not nil. The host devices are
+// discovered and bound through sysfs if not nil, through the host root
+// otherwise.
+func NewDeviceManager(blockDriver string, vhostUserStoreEnabled bool, vhostUserStorePath string, vhostUserReconnect uint32, devices []api.Device, leases *DeviceLeases, sysfs config.SysfsProvider) api.DeviceManager {
 	dm := &deviceManager{
 		vhostUserStoreEnabled:     vhostUserStoreEnabled,
 		vhostUserStorePath:        vhostUserStorePath,
 		vhostUserReconnectTimeout: vhostUserReconnect,
 		devices:                   make(map[string]api.Device),
+		leases:                    leases,
+		leaseKeys:                 make(map[string]string),
 	}
 
 	switch blockDriver {
@@ -72,6 +94,11 @@ func NewDeviceManager(blockDriver string, vhostUserStoreEnabled bool, vhostUserS
 	config.PCIeDevicesPerPort[config.SwitchPort] = make([]config.VFIODev, 0)
 	config.PCIeDevicesPerPort[config.BridgePort] = make([]config.VFIODev, 0)
 
+	dm.sysfs = sysfs
+	if dm.sysfs == nil {
+		dm.sysfs = config.NewRootedSysfs("/")
+	}
+
 	for _, dev := range devices {
 		dm.devices[dev.DeviceID()] = dev
 	}
@@ -104,7 +131,7 @@ func (dm *deviceManager) createDevice(devInfo config.DeviceInfo) (dev api.Device
 	// pmem device may points to block devices or raw files,
 	// do not change its HostPath.
 	if !devInfo.Pmem {
-		path, err := config.GetHostPathFunc(devInfo, dm.vhostUserStoreEnabled, dm.vhostUserStorePath)
+		path, err := config.GetHostPathFunc(dm.sysfs, devInfo, dm.vhostUserStoreEnabled, dm.vhostUserStorePath)
 		if err != nil {
 			return nil, err
 		}
@@ -126,8 +153,16 @@ func (dm *deviceManager) createDevice(devInfo config.DeviceInfo) (dev api.Device
 	if devInfo.ID, err = dm.newDeviceID(); err != nil {
 		return nil, err
 	}
-	if IsVFIODevice(devInfo.HostPath) {
-		return drivers.NewVFIODevice(&devInfo), nil
+
+	if devInfo.LeaseKey, err = dm.acquireLease(devInfo); err != nil {
+		return nil, err
+	}
+	if isVFIO(devInfo) {
+		return drivers.NewVFIODevice(&devInfo, dm.sysfs), nil
+	} else if devInfo.SCSIPassthrough {
+		return drivers.NewSCSIPassthroughDevice(&devInfo), nil
+	} else if devInfo.CharPassthrough {
+		return drivers.NewCharPassthroughDevice(&devInfo), nil
 	} else if IsVhostUserBlk(devInfo) {
 		if devInfo.DriverOptions == nil {
 			devInfo.DriverOptions = make(map[string]string)
@@ -171,7 +206,23 @@ func (dm *deviceManager) RemoveDevice(id string) error {
 		if dev.GetAttachCount() > 0 {
 			return ErrRemoveAttachedDevice
 		}
+		if vfio, ok := dev.(*drivers.VFIODevice); ok && len(vfio.HostDrivers) > 0 {
+			// Cold plugged VFIO devices are used by the VM until
+			// it stops, keep them to bind them back to their host
+			// drivers then.
+			if vfio.DeviceInfo.ColdPlug {
+				return ErrColdPlugVFIOInUse
+			}
+
+			// A hot unplugged VFIO device which could not be bound
+			// back when detached is retried, and kept for the
+			// sandbox deletion to retry again on failure.
+			if err := vfio.RestoreHostDrivers(); err != nil {
+				return fmt.Errorf("%w: %w", ErrVFIOHostDriversNotRestored, err)
+			}
+		}
 		delete(dm.devices, id)
+		dm.releaseLease(dev)
 	}
 	return nil
 }
@@ -226,6 +277,51 @@ func (dm *deviceManager) DetachDevice(ctx context.Context, id string, dr api.Dev
 	return nil
 }
 
+// RestoreHostDrivers binds the VFIO devices bound to vfio-pci when attaching
+// them back to their host drivers. The devices no longer referenced are
+// removed.
+func (dm *deviceManager) RestoreHostDrivers() error {
+	dm.Lock()
+	defer dm.Unlock()
+
+	var errs []error
+	for id, dev := range dm.devices {
+		vfio, ok := dev.(*drivers.VFIODevice)
+		if !ok || len(vfio.HostDrivers) == 0 {
+			continue
+		}
+
+		if err := vfio.RestoreHostDrivers(); err != nil {
+			errs = append(errs, fmt.Errorf("device %s: %w", id, err))
+			continue
+		}
+
+		if vfio.RefCount == 0 {
+			delete(dm.devices, id)
+			dm.releaseLease(dev)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RestoreHostDriversFromState binds the VFIO devices recorded in saved device
+// states back to their host drivers. It recovers the devices of a sandbox whose
+// device manager cannot be restored after a runtime crash, and must only be
+// called once its VM is gone.
+func RestoreHostDriversFromState(sysfs config.SysfsProvider, devStates []config.DeviceState) error {
+	var errs []error
+	for _, ds := range devStates {
+		if len(ds.VFIOHostDrivers) == 0 {
+			continue
+		}
+
+		if err := drivers.RestoreHostDrivers(sysfs, ds.VFIOHostDrivers); err != nil {
+			errs = append(errs, fmt.Errorf("device %s: %w", ds.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func (dm *deviceManager) GetDeviceByID(id string) api.Device {
 	dm.RLock()
 	defer dm.RUnlock()
@@ -269,7 +365,11 @@ func (dm *deviceManager) LoadDevices(devStates []config.DeviceState) {
 		case config.DeviceBlock:
 			dev = &drivers.BlockDevice{}
 		case config.DeviceVFIO:
-			dev = &drivers.VFIODevice{}
+			dev = drivers.NewVFIODevice(&config.DeviceInfo{}, dm.sysfs)
+		case config.DeviceSCSIPassthrough:
+			dev = &drivers.SCSIPassthroughDevice{}
+		case config.DeviceCharPassthrough:
+			dev = &drivers.CharPassthroughDevice{}
 		case config.VhostUserSCSI:
 			dev = &drivers.VhostUserSCSIDevice{}
 		case config.VhostUserBlk:
@@ -284,5 +384,53 @@ func (dm *deviceManager) LoadDevices(devStates []config.DeviceState) {
 
 		dev.Load(ds)
 		dm.devices[dev.DeviceID()] = dev
+
+		// The leases are still held, release them with the devices.
+		if ds.LeaseKey != "" {
+			dm.leaseKeys[ds.ID] = ds.LeaseKey
+		}
+	}
+}
+
+// acquireLease claims the device and returns the key of its lease, if any.
+func (dm *deviceManager) acquireLease(devInfo config.DeviceInfo) (string, error) {
+	if dm.leases == nil {
+		return "", nil
+	}
+
+	key, mode, ok, err := deviceLeaseKey(dm.sysfs, devInfo)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", nil
+	}
+	if err := dm.leases.Acquire(key, mode); err != nil {
+		return "", err
+	}
+
+	dm.leaseKeys[devInfo.ID] = key
+	return key, nil
+}
+
+// releaseLease releases the lease of a removed device, unless another device
+// of the sandbox shares it, as the devices of a VFIO group do.
+func (dm *deviceManager) releaseLease(dev api.Device) {
+	key, ok := dm.leaseKeys[dev.DeviceID()]
+	if !ok || dm.leases == nil {
+		return
+	}
+
+	for id, other := range dm.leaseKeys {
+		if id != dev.DeviceID() && other == key {
+			delete(dm.leaseKeys, dev.DeviceID())
+			return
+		}
+	}
+
+	if err := dm.leases.Release(key); err != nil {
+		deviceLogger().WithError(err).WithField("device", key).Error("Failed to release device lease")
+		return
 	}
+	delete(dm.leaseKeys, dev.DeviceID())
 }
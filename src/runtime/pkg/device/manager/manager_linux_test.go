@@ -35,6 +35,7 @@ func TestAttachVhostUserBlkDevice(t *testing.T) {
 		devices:               make(map[string]api.Device),
 		vhostUserStoreEnabled: true,
 		vhostUserStorePath:    tmpDir,
+		sysfs:                 config.NewRootedSysfs("/"),
 	}
 
 	vhostUserDevNodePath := filepath.Join(tmpDir, "/block/devices/")
@@ -28,6 +28,7 @@ func TestNewDevice(t *testing.T) {
 	dm := &deviceManager{
 		blockDriver: config.VirtioBlock,
 		devices:     make(map[string]api.Device),
+		sysfs:       config.NewRootedSysfs("/"),
 	}
 	savedSysDevPrefix := config.SysDevPrefix
 
@@ -97,6 +98,7 @@ func TestAttachVFIOAPDevice(t *testing.T) {
 
 	dm := &deviceManager{
 		devices: make(map[string]api.Device),
+		sysfs:   config.NewRootedSysfs("/"),
 	}
 
 	tmpDir := t.TempDir()
@@ -164,6 +166,7 @@ func TestAttachVFIOAPDevice(t *testing.T) {
 	// If we omit the port setting we should fail
 	failDm := &deviceManager{
 		devices: make(map[string]api.Device),
+		sysfs:   config.NewRootedSysfs("/"),
 	}
 
 	failDeviceInfo := config.DeviceInfo{
@@ -188,6 +191,7 @@ func TestAttachVFIODevice(t *testing.T) {
 	dm := &deviceManager{
 		blockDriver: config.VirtioBlock,
 		devices:     make(map[string]api.Device),
+		sysfs:       config.NewRootedSysfs("/"),
 	}
 	tmpDir := t.TempDir()
 
@@ -247,6 +251,7 @@ func TestAttachGenericDevice(t *testing.T) {
 	dm := &deviceManager{
 		blockDriver: config.VirtioBlock,
 		devices:     make(map[string]api.Device),
+		sysfs:       config.NewRootedSysfs("/"),
 	}
 	path := "/dev/tty2"
 	deviceInfo := config.DeviceInfo{
@@ -272,6 +277,7 @@ func TestAttachBlockDevice(t *testing.T) {
 	dm := &deviceManager{
 		blockDriver: config.VirtioBlock,
 		devices:     make(map[string]api.Device),
+		sysfs:       config.NewRootedSysfs("/"),
 	}
 	path := "/dev/hda"
 	deviceInfo := config.DeviceInfo{
@@ -303,8 +309,92 @@ func TestAttachBlockDevice(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestAttachSCSIPassthroughDevice(t *testing.T) {
+	assert := assert.New(t)
+
+	dm := &deviceManager{
+		blockDriver: config.VirtioSCSI,
+		devices:     make(map[string]api.Device),
+		sysfs:       config.NewRootedSysfs("/"),
+	}
+	devReceiver := &api.MockDeviceReceiver{}
+
+	for _, d := range []struct {
+		devType string
+		path    string
+		driver  string
+	}{
+		{"c", "/dev/sg0", config.SCSIGeneric},
+		{"b", "/dev/dm-0", config.SCSIBlock},
+	} {
+		device, err := dm.NewDevice(config.DeviceInfo{
+			HostPath:        d.path,
+			ContainerPath:   d.path,
+			DevType:         d.devType,
+			Major:           -1,
+			SCSIPassthrough: true,
+		})
+		assert.NoError(err)
+		scsiDevice, ok := device.(*drivers.SCSIPassthroughDevice)
+		assert.True(ok)
+		assert.Equal(config.DeviceSCSIPassthrough, device.DeviceType())
+
+		assert.NoError(device.Attach(context.Background(), devReceiver))
+		assert.Equal(d.path, scsiDevice.Drive.File)
+		assert.Equal(d.driver, scsiDevice.Drive.Driver)
+		assert.Equal("0:0", scsiDevice.Drive.SCSIAddr)
+
+		ds := device.Save()
+		assert.Equal(string(config.DeviceSCSIPassthrough), ds.Type)
+		dm.LoadDevices([]config.DeviceState{ds})
+		loaded, ok := dm.GetDeviceByID(device.DeviceID()).(*drivers.SCSIPassthroughDevice)
+		assert.True(ok)
+		assert.Equal(scsiDevice.Drive, loaded.Drive)
+
+		assert.NoError(loaded.Detach(context.Background(), devReceiver))
+		assert.NoError(dm.RemoveDevice(device.DeviceID()))
+	}
+}
+
+func TestAttachCharPassthroughDevice(t *testing.T) {
+	assert := assert.New(t)
+
+	dm := &deviceManager{
+		devices: make(map[string]api.Device),
+		sysfs:   config.NewRootedSysfs("/"),
+	}
+	devReceiver := &api.MockDeviceReceiver{}
+
+	device, err := dm.NewDevice(config.DeviceInfo{
+		HostPath:        "/dev/ttyUSB0",
+		ContainerPath:   "/dev/ttyUSB0",
+		DevType:         "c",
+		Major:           -1,
+		CharPassthrough: true,
+	})
+	assert.NoError(err)
+	charDevice, ok := device.(*drivers.CharPassthroughDevice)
+	assert.True(ok)
+	assert.Equal(config.DeviceCharPassthrough, device.DeviceType())
+
+	assert.NoError(device.Attach(context.Background(), devReceiver))
+	assert.Equal("/dev/ttyUSB0", charDevice.CharDrive.File)
+	assert.Equal(charDevice.CharDrive.ID, charDevice.CharDrive.PortName)
+	assert.Equal(uint(1), device.GetAttachCount())
+
+	ds := device.Save()
+	assert.Equal(string(config.DeviceCharPassthrough), ds.Type)
+	dm.LoadDevices([]config.DeviceState{ds})
+	loaded, ok := dm.GetDeviceByID(device.DeviceID()).(*drivers.CharPassthroughDevice)
+	assert.True(ok)
+	assert.Equal(charDevice.CharDrive, loaded.CharDrive)
+
+	assert.NoError(loaded.Detach(context.Background(), devReceiver))
+	assert.Equal(uint(0), loaded.GetAttachCount())
+}
+
 func TestAttachDetachDevice(t *testing.T) {
-	dm := NewDeviceManager(config.VirtioSCSI, false, "", 0, nil)
+	dm := NewDeviceManager(config.VirtioSCSI, false, "", 0, nil, nil, nil)
 
 	path := "/dev/hda"
 	deviceInfo := config.DeviceInfo{
@@ -353,3 +443,120 @@ func TestAttachDetachDevice(t *testing.T) {
 	err = dm.RemoveDevice(device.DeviceID())
 	assert.Nil(t, err)
 }
+
+func TestRestoreHostDrivers(t *testing.T) {
+	assert := assert.New(t)
+
+	root := t.TempDir()
+	deviceDir := filepath.Join(root, "sys/bus/pci/devices/0000:01:00.0")
+	assert.NoError(os.MkdirAll(deviceDir, dirMode))
+	assert.NoError(os.WriteFile(filepath.Join(deviceDir, "driver_override"), []byte("vfio-pci"), fileMode0640))
+	assert.NoError(os.WriteFile(filepath.Join(root, "sys/bus/pci/drivers_probe"), nil, fileMode0640))
+
+	sysfs := config.NewRootedSysfs(root)
+
+	vfio := drivers.NewVFIODevice(&config.DeviceInfo{ID: "vfio", HostPath: "/dev/vfio/12", ColdPlug: true}, sysfs)
+	vfio.HostDrivers = map[string]string{"0000:01:00.0": "i40e"}
+	vfio.Reference()
+	dm := &deviceManager{
+		devices: map[string]api.Device{"vfio": vfio},
+		sysfs:   config.NewRootedSysfs("/"),
+	}
+
+	// Kept until the VM is stopped
+	assert.ErrorIs(dm.RemoveDevice("vfio"), ErrColdPlugVFIOInUse)
+	assert.NotNil(dm.GetDeviceByID("vfio"))
+
+	assert.NoError(dm.RestoreHostDrivers())
+	assert.Nil(dm.GetDeviceByID("vfio"))
+	assert.Empty(vfio.HostDrivers)
+
+	data, err := os.ReadFile(filepath.Join(deviceDir, "driver_override"))
+	assert.NoError(err)
+	assert.Equal("i40e", string(data))
+
+	// Retried on failure
+	vfio.HostDrivers = map[string]string{"0000:02:00.0": "i40e"}
+	dm.devices["vfio"] = vfio
+	assert.Error(dm.RestoreHostDrivers())
+	assert.NotNil(dm.GetDeviceByID("vfio"))
+	assert.Len(vfio.HostDrivers, 1)
+
+	// A hot unplugged device is bound back when removed
+	hotplugged := drivers.NewVFIODevice(&config.DeviceInfo{ID: "hotplugged", HostPath: "/dev/vfio/12"}, sysfs)
+	hotplugged.HostDrivers = map[string]string{"0000:01:00.0": "ixgbe"}
+	hotplugged.Reference()
+	dm.devices["hotplugged"] = hotplugged
+	assert.NoError(dm.RemoveDevice("hotplugged"))
+	assert.Nil(dm.GetDeviceByID("hotplugged"))
+	assert.Empty(hotplugged.HostDrivers)
+
+	data, err = os.ReadFile(filepath.Join(deviceDir, "driver_override"))
+	assert.NoError(err)
+	assert.Equal("ixgbe", string(data))
+
+	// And kept for the sandbox deletion on failure
+	hotplugged.HostDrivers = map[string]string{"0000:02:00.0": "ixgbe"}
+	hotplugged.Reference()
+	dm.devices["hotplugged"] = hotplugged
+	assert.ErrorIs(dm.RemoveDevice("hotplugged"), ErrVFIOHostDriversNotRestored)
+	assert.NotNil(dm.GetDeviceByID("hotplugged"))
+}
+
+func TestRestoreHostDriversFromState(t *testing.T) {
+	assert := assert.New(t)
+
+	root := t.TempDir()
+	deviceDir := filepath.Join(root, "sys/bus/pci/devices/0000:01:00.0")
+	assert.NoError(os.MkdirAll(deviceDir, dirMode))
+	assert.NoError(os.WriteFile(filepath.Join(deviceDir, "driver_override"), []byte("vfio-pci"), fileMode0640))
+	assert.NoError(os.WriteFile(filepath.Join(root, "sys/bus/pci/drivers_probe"), nil, fileMode0640))
+
+	sysfs := config.NewRootedSysfs(root)
+
+	devStates := []config.DeviceState{
+		{ID: "block", Type: string(config.DeviceBlock)},
+		{ID: "vfio", Type: string(config.DeviceVFIO), VFIOHostDrivers: map[string]string{"0000:01:00.0": "i40e"}},
+	}
+	assert.NoError(RestoreHostDriversFromState(sysfs, devStates))
+
+	data, err := os.ReadFile(filepath.Join(deviceDir, "driver_override"))
+	assert.NoError(err)
+	assert.Equal("i40e", string(data))
+
+	devStates = []config.DeviceState{
+		{ID: "vfio", Type: string(config.DeviceVFIO), VFIOHostDrivers: map[string]string{"0000:02:00.0": "i40e"}},
+	}
+	assert.Error(RestoreHostDriversFromState(sysfs, devStates))
+}
+
+func TestDeviceManagerSysfs(t *testing.T) {
+	assert := assert.New(t)
+
+	// The devices of a device manager are discovered through its provider
+	sysfs := config.NewRootedSysfs(t.TempDir())
+	dm := NewDeviceManager(config.VirtioSCSI, false, "", 0, nil, nil, sysfs).(*deviceManager)
+	assert.Equal(sysfs, dm.sysfs)
+
+	// Another device manager does not share it
+	dm = NewDeviceManager(config.VirtioSCSI, false, "", 0, nil, nil, nil).(*deviceManager)
+	assert.Equal(config.NewRootedSysfs("/"), dm.sysfs)
+
+	// Nor do the devices it restores
+	root := t.TempDir()
+	deviceDir := filepath.Join(root, "sys/bus/pci/devices/0000:01:00.0")
+	assert.NoError(os.MkdirAll(deviceDir, dirMode))
+	assert.NoError(os.WriteFile(filepath.Join(deviceDir, "driver_override"), []byte("vfio-pci"), fileMode0640))
+	assert.NoError(os.WriteFile(filepath.Join(root, "sys/bus/pci/drivers_probe"), nil, fileMode0640))
+
+	vfio := drivers.NewVFIODevice(&config.DeviceInfo{ID: "vfio", HostPath: "/dev/vfio/12", ColdPlug: true}, nil)
+	vfio.HostDrivers = map[string]string{"0000:01:00.0": "i40e"}
+
+	dm = NewDeviceManager(config.VirtioSCSI, false, "", 0, nil, nil, config.NewRootedSysfs(root)).(*deviceManager)
+	dm.LoadDevices([]config.DeviceState{vfio.Save()})
+	assert.NoError(dm.RestoreHostDrivers())
+
+	data, err := os.ReadFile(filepath.Join(deviceDir, "driver_override"))
+	assert.NoError(err)
+	assert.Equal("i40e", string(data))
+}
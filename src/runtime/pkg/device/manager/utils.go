@@ -7,6 +7,7 @@
 package manager
 
 import (
+	"fmt"
 	"path/filepath"
 	"strings"
 
@@ -38,6 +39,12 @@ func IsVFIODevice(hostPath string) bool {
 	return false
 }
 
+// isVFIO checks if the device is a VFIO device. A VFIO group to bind may be
+// given by one of its PCI devices, as its group device only exists once bound.
+func isVFIO(devInfo config.DeviceInfo) bool {
+	return IsVFIODevice(devInfo.HostPath) || devInfo.BindVFIO && IsVFIODevice(devInfo.ContainerPath)
+}
+
 // isBlock checks if the device is a block device.
 func isBlock(devInfo config.DeviceInfo) bool {
 	return devInfo.DevType == "b"
@@ -52,3 +59,42 @@ func IsVhostUserBlk(devInfo config.DeviceInfo) bool {
 func isVhostUserSCSI(devInfo config.DeviceInfo) bool {
 	return devInfo.DevType == "b" && devInfo.Major == config.VhostUserSCSIMajor
 }
+
+// IsSCSIDevice checks if the device is a host SCSI device which can be passed
+// through to the guest SCSI bus: a SCSI generic character device, a SCSI disk
+// or a multipath device, from sysfs.
+func IsSCSIDevice(sysfs config.SysfsProvider, devInfo config.DeviceInfo) bool {
+	switch devInfo.DevType {
+	case "c":
+		return devInfo.Major == config.SCSIGenericMajor
+	case "b":
+		sysPath := filepath.Join(config.SysDevPrefix, "block", fmt.Sprintf("%d:%d", devInfo.Major, devInfo.Minor))
+		if _, err := sysfs.Stat(filepath.Join(sysPath, "device", "scsi_device")); err == nil {
+			return true
+		}
+		uuid, err := sysfs.ReadFile(filepath.Join(sysPath, "dm", "uuid"))
+		return err == nil && strings.HasPrefix(string(uuid), "mpath-")
+	}
+	return false
+}
+
+// IsCharPassthroughDevice checks if the device is a host character device
+// relayed to the guest, its host path, found from sysfs, matching one of the
+// patterns.
+func IsCharPassthroughDevice(sysfs config.SysfsProvider, devInfo config.DeviceInfo, patterns []string) bool {
+	if devInfo.DevType != "c" || len(patterns) == 0 {
+		return false
+	}
+
+	hostPath, err := config.GetHostPathFunc(sysfs, devInfo, false, "")
+	if err != nil || IsVFIODevice(hostPath) || IsVFIOControlDevice(hostPath) {
+		return false
+	}
+
+	for _, pattern := range patterns {
+		if matched, _ := filepath.Match(pattern, hostPath); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -7,6 +7,8 @@
 package manager
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
@@ -103,3 +105,100 @@ func TestIsVhostUserSCSI(t *testing.T) {
 		assert.Equal(t, d.expected, isVhostUserSCSI)
 	}
 }
+
+func TestIsSCSIDevice(t *testing.T) {
+	assert := assert.New(t)
+
+	root := t.TempDir()
+	// 8:0 is a SCSI disk, 253:0 a multipath device, 253:1 an LVM volume
+	// and 254:0 a virtio-blk disk
+	for _, dir := range []string{
+		"sys/dev/block/8:0/device/scsi_device",
+		"sys/dev/block/253:0/dm",
+		"sys/dev/block/253:1/dm",
+		"sys/dev/block/254:0/device",
+	} {
+		assert.NoError(os.MkdirAll(filepath.Join(root, dir), 0755))
+	}
+	assert.NoError(os.WriteFile(filepath.Join(root, "sys/dev/block/253:0/dm/uuid"), []byte("mpath-3600a098038303053453f463045727a6b\n"), 0644))
+	assert.NoError(os.WriteFile(filepath.Join(root, "sys/dev/block/253:1/dm/uuid"), []byte("LVM-Qp8wdE3rGYcVhkAMgKhZUnGKbjFyVlWC\n"), 0644))
+
+	sysfs := config.NewRootedSysfs(root)
+
+	type testData struct {
+		devType  string
+		major    int64
+		minor    int64
+		expected bool
+	}
+
+	data := []testData{
+		{"c", config.SCSIGenericMajor, 0, true},
+		{"c", 1, 3, false},
+		{"b", 8, 0, true},
+		{"b", 253, 0, true},
+		{"b", 253, 1, false},
+		{"b", 254, 0, false},
+		{"b", 7, 0, false},
+	}
+
+	for _, d := range data {
+		isSCSI := IsSCSIDevice(sysfs, config.DeviceInfo{
+			DevType: d.devType,
+			Major:   d.major,
+			Minor:   d.minor,
+		})
+		assert.Equal(d.expected, isSCSI, "%s %d:%d", d.devType, d.major, d.minor)
+	}
+}
+
+func TestIsCharPassthroughDevice(t *testing.T) {
+	assert := assert.New(t)
+
+	root := t.TempDir()
+	for dev, name := range map[string]string{
+		"188:0": "ttyUSB0",
+		"4:64":  "ttyS0",
+		"237:0": "vfio/12",
+	} {
+		dir := filepath.Join(root, "sys/dev/char", dev)
+		assert.NoError(os.MkdirAll(dir, 0755))
+		assert.NoError(os.WriteFile(filepath.Join(dir, "uevent"), []byte("DEVNAME="+name+"\n"), 0644))
+	}
+
+	sysfs := config.NewRootedSysfs(root)
+
+	patterns := []string{"/dev/ttyUSB*", "/dev/vfio/*"}
+
+	type testData struct {
+		devType  string
+		major    int64
+		minor    int64
+		expected bool
+	}
+
+	data := []testData{
+		{"c", 188, 0, true},
+		{"c", 4, 64, false},
+		// VFIO groups are passed through as VFIO devices
+		{"c", 237, 0, false},
+		{"b", 188, 0, false},
+	}
+
+	for _, d := range data {
+		isChar := IsCharPassthroughDevice(sysfs, config.DeviceInfo{
+			ContainerPath: "/dev/foo",
+			DevType:       d.devType,
+			Major:         d.major,
+			Minor:         d.minor,
+		}, patterns)
+		assert.Equal(d.expected, isChar, "%s %d:%d", d.devType, d.major, d.minor)
+	}
+
+	assert.False(IsCharPassthroughDevice(sysfs, config.DeviceInfo{
+		ContainerPath: "/dev/foo",
+		DevType:       "c",
+		Major:         188,
+		Minor:         0,
+	}, nil))
+}
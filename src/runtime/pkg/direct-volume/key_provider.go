@@ -0,0 +1,218 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package volume
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// EncryptionKeyEphemeral is the encryption key instructing the agent to
+// generate a one-time key for the volume, which is formatted on first use.
+const EncryptionKeyEphemeral = "ephemeral"
+
+// maxKeySize is the maximum size of the key material of a volume.
+const maxKeySize = 8192
+
+// KeyProvider provides the key material of the encrypted direct volumes.
+//
+// The encryption key of a volume, in its EncryptionKeyMetadataKey metadata,
+// is either "ephemeral" or a key reference: an URL whose scheme selects the
+// key provider, e.g. file:///etc/kata/keys/volume.key or
+// unix:///run/kms.sock?id=volume. The key material is fetched by the runtime
+// when the volume is mounted, and is delivered to the agent apart from the
+// storage metadata.
+type KeyProvider interface {
+	// Validate checks that the key reference is valid for the provider,
+	// without fetching the key.
+	Validate(ref *url.URL) error
+
+	// Key returns the key material of the key reference.
+	Key(ctx context.Context, ref *url.URL) ([]byte, error)
+}
+
+var (
+	keyProvidersLock sync.RWMutex
+	keyProviders     = map[string]KeyProvider{
+		"file": fileKeyProvider{},
+		"unix": unixKeyProvider{},
+	}
+)
+
+// RegisterKeyProvider registers the key provider of the key references with
+// the scheme, replacing the provider previously registered for it.
+func RegisterKeyProvider(scheme string, provider KeyProvider) {
+	keyProvidersLock.Lock()
+	defer keyProvidersLock.Unlock()
+
+	keyProviders[scheme] = provider
+}
+
+// IsProvidedEncryptionKey returns whether the encryption key is a key
+// reference, whose key material is provided by a key provider.
+func IsProvidedEncryptionKey(encryptionKey string) bool {
+	return encryptionKey != "" && encryptionKey != EncryptionKeyEphemeral
+}
+
+// ValidateEncryptionKey checks the encryption key of a volume.
+func ValidateEncryptionKey(encryptionKey string) error {
+	if !IsProvidedEncryptionKey(encryptionKey) {
+		return nil
+	}
+
+	provider, ref, err := keyProvider(encryptionKey)
+	if err != nil {
+		return err
+	}
+	if err := provider.Validate(ref); err != nil {
+		return fmt.Errorf("invalid encryption key %q: %w", encryptionKey, err)
+	}
+	return nil
+}
+
+// GetEncryptionKey returns the key material of the key reference.
+func GetEncryptionKey(ctx context.Context, encryptionKey string) ([]byte, error) {
+	provider, ref, err := keyProvider(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := provider.Key(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("getting encryption key %q: %w", encryptionKey, err)
+	}
+	if len(key) == 0 || len(key) > maxKeySize {
+		return nil, fmt.Errorf("encryption key %q has an invalid size %d", encryptionKey, len(key))
+	}
+	return key, nil
+}
+
+func keyProvider(encryptionKey string) (KeyProvider, *url.URL, error) {
+	ref, err := url.Parse(encryptionKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid encryption key reference %q: %w", encryptionKey, err)
+	}
+
+	keyProvidersLock.RLock()
+	defer keyProvidersLock.RUnlock()
+
+	provider, ok := keyProviders[ref.Scheme]
+	if !ok {
+		schemes := make([]string, 0, len(keyProviders))
+		for scheme := range keyProviders {
+			schemes = append(schemes, scheme+"://")
+		}
+		sort.Strings(schemes)
+		return nil, nil, fmt.Errorf("encryption key %q is neither %q nor a key reference with one of the schemes %s",
+			encryptionKey, EncryptionKeyEphemeral, strings.Join(schemes, ", "))
+	}
+	return provider, ref, nil
+}
+
+// fileKeyProvider provides the keys stored in files, file:///path/to/key.
+// The key files must not be accessible to the group and others.
+type fileKeyProvider struct{}
+
+func (fileKeyProvider) Validate(ref *url.URL) error {
+	if !filepath.IsAbs(ref.Path) {
+		return fmt.Errorf("key file path %q is not absolute", ref.Path)
+	}
+
+	info, err := os.Stat(ref.Path)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("key file %s is not a regular file", ref.Path)
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		return fmt.Errorf("key file %s is accessible to the group or others", ref.Path)
+	}
+	if info.Size() == 0 || info.Size() > maxKeySize {
+		return fmt.Errorf("key file %s has an invalid size %d", ref.Path, info.Size())
+	}
+	return nil
+}
+
+func (p fileKeyProvider) Key(ctx context.Context, ref *url.URL) ([]byte, error) {
+	if err := p.Validate(ref); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(ref.Path)
+}
+
+// keyRequest is the request of a key to a unix socket KMS.
+type keyRequest struct {
+	ID string `json:"id"`
+}
+
+// keyResponse is the response of a unix socket KMS.
+type keyResponse struct {
+	Key   []byte `json:"key,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// unixKeyProvider provides the keys of a KMS listening on a unix socket,
+// unix:///path/to/socket?id=<key id>. A JSON key request is sent on a new
+// connection for each key, {"id": "<key id>"}, and the KMS replies with the
+// key, {"key": "<base64 key>"}, or an error, {"error": "<message>"}.
+type unixKeyProvider struct{}
+
+func (unixKeyProvider) Validate(ref *url.URL) error {
+	if ref.Query().Get("id") == "" {
+		return fmt.Errorf("no key id in %q", ref.String())
+	}
+
+	info, err := os.Stat(ref.Path)
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s is not a socket", ref.Path)
+	}
+	return nil
+}
+
+func (p unixKeyProvider) Key(ctx context.Context, ref *url.URL) ([]byte, error) {
+	if err := p.Validate(ref); err != nil {
+		return nil, err
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", ref.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := json.NewEncoder(conn).Encode(keyRequest{ID: ref.Query().Get("id")}); err != nil {
+		return nil, err
+	}
+
+	var resp keyResponse
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&resp); err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("key provider error: %s", resp.Error)
+	}
+	return resp.Key, nil
+}
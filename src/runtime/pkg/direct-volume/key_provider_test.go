@@ -0,0 +1,115 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package volume
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileKeyProvider(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	keyPath := filepath.Join(t.TempDir(), "volume.key")
+	assert.NoError(os.WriteFile(keyPath, []byte("secret"), 0600))
+	encryptionKey := "file://" + keyPath
+
+	assert.NoError(ValidateEncryptionKey(encryptionKey))
+	key, err := GetEncryptionKey(ctx, encryptionKey)
+	assert.NoError(err)
+	assert.Equal([]byte("secret"), key)
+
+	// The keys readable by others are rejected
+	assert.NoError(os.Chmod(keyPath, 0644))
+	assert.Error(ValidateEncryptionKey(encryptionKey))
+	_, err = GetEncryptionKey(ctx, encryptionKey)
+	assert.Error(err)
+
+	assert.Error(ValidateEncryptionKey("file://" + filepath.Join(t.TempDir(), "missing.key")))
+}
+
+func TestUnixKeyProvider(t *testing.T) {
+	assert := assert.New(t)
+
+	socket := filepath.Join(t.TempDir(), "kms.sock")
+	l, err := net.Listen("unix", socket)
+	assert.NoError(err)
+	defer l.Close()
+
+	keys := map[string][]byte{"volume": []byte("secret")}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			var req keyRequest
+			var resp keyResponse
+			if err := json.NewDecoder(conn).Decode(&req); err != nil {
+				resp.Error = err.Error()
+			} else if key, ok := keys[req.ID]; ok {
+				resp.Key = key
+			} else {
+				resp.Error = "unknown key"
+			}
+			json.NewEncoder(conn).Encode(resp)
+			conn.Close()
+		}
+	}()
+
+	encryptionKey := "unix://" + socket + "?id=volume"
+	assert.NoError(ValidateEncryptionKey(encryptionKey))
+	key, err := GetEncryptionKey(context.Background(), encryptionKey)
+	assert.NoError(err)
+	assert.Equal([]byte("secret"), key)
+
+	_, err = GetEncryptionKey(context.Background(), "unix://"+socket+"?id=other")
+	assert.ErrorContains(err, "unknown key")
+
+	// A key id is required
+	assert.Error(ValidateEncryptionKey("unix://" + socket))
+}
+
+func TestValidateEncryptedVolume(t *testing.T) {
+	assert := assert.New(t)
+	kataDirectVolumeRootPath = t.TempDir()
+
+	// The ephemeral keys are not provided
+	assert.NoError(ValidateEncryptionKey(""))
+	assert.NoError(ValidateEncryptionKey(EncryptionKeyEphemeral))
+	assert.Error(ValidateEncryptionKey("vault://volume"))
+
+	// Neither ephemeral nor a key reference
+	err := ValidateEncryptionKey("Ephemeral")
+	assert.ErrorContains(err, `neither "ephemeral" nor a key reference with one of the schemes file://, unix://`)
+
+	mountInfo := func(volumeType, encryptionKey string) string {
+		b, err := json.Marshal(MountInfo{
+			VolumeType: volumeType,
+			Device:     "/dev/sda",
+			FsType:     "ext4",
+			Metadata:   map[string]string{EncryptionKeyMetadataKey: encryptionKey},
+		})
+		assert.NoError(err)
+		return string(b)
+	}
+
+	keyPath := filepath.Join(t.TempDir(), "volume.key")
+	assert.NoError(os.WriteFile(keyPath, []byte("secret"), 0600))
+
+	assert.NoError(Add("/a/b/c", mountInfo("block", "file://"+keyPath)))
+	assert.Error(Add("/a/b/d", mountInfo("block", "file:///missing.key")))
+	assert.Error(Add("/a/b/e", mountInfo(PmemVolumeType, "file://"+keyPath)))
+	assert.ErrorContains(Add("/a/b/f", mountInfo("block", "secret")), "invalid encryptionKey metadata")
+}
@@ -20,6 +20,10 @@ const (
 	EncryptionKeyMetadataKey       = "encryptionKey"
 	FSGroupMetadataKey             = "fsGroup"
 	FSGroupChangePolicyMetadataKey = "fsGroupChangePolicy"
+
+	// PmemVolumeType is the type of a volume on a host file with the PFN
+	// signature, attached as a NVDIMM and mounted with DAX in the guest.
+	PmemVolumeType = "pmem"
 )
 
 // FSGroupChangePolicy holds policies that will be used for applying fsGroup to a volume.
@@ -54,6 +58,23 @@ type MountInfo struct {
 	Options []string `json:"options,omitempty"`
 }
 
+// validate checks the mount info, the encrypted volumes must be block volumes
+// whose key is available.
+func (m *MountInfo) validate() error {
+	encryptionKey := m.Metadata[EncryptionKeyMetadataKey]
+	if encryptionKey == "" {
+		return nil
+	}
+
+	if m.VolumeType == PmemVolumeType {
+		return fmt.Errorf("%s volumes cannot be encrypted", PmemVolumeType)
+	}
+	if err := ValidateEncryptionKey(encryptionKey); err != nil {
+		return fmt.Errorf("invalid %s metadata: %w", EncryptionKeyMetadataKey, err)
+	}
+	return nil
+}
+
 // Add writes the mount info of a direct volume into a filesystem path known to Kata Container.
 func Add(volumePath string, mountInfo string) error {
 	volumeDir := filepath.Join(kataDirectVolumeRootPath, b64.URLEncoding.EncodeToString([]byte(volumePath)))
@@ -74,6 +95,9 @@ func Add(volumePath string, mountInfo string) error {
 	if err := json.Unmarshal([]byte(mountInfo), &deserialized); err != nil {
 		return err
 	}
+	if err := deserialized.validate(); err != nil {
+		return err
+	}
 
 	return os.WriteFile(filepath.Join(volumeDir, mountInfoFileName), []byte(mountInfo), 0600)
 }
@@ -148,6 +148,12 @@ const (
 
 	// SpaprTPMProxy is used for enabling guest to run in secure mode on ppc64le.
 	SpaprTPMProxy DeviceDriver = "spapr-tpm-proxy"
+
+	// TPMCRB is the TPM Command Response Buffer interface device.
+	TPMCRB DeviceDriver = "tpm-crb"
+
+	// TPMTIS is the TPM Interface Specification device.
+	TPMTIS DeviceDriver = "tpm-tis"
 )
 
 func isDimmSupported(config *Config) bool {
@@ -874,6 +880,13 @@ const (
 
 	// VHOSTUSER is a vhost-user port (socket)
 	VHOSTUSER NetDeviceType = "vhostuser"
+
+	// VHOSTVDPA is a vhost-vdpa device (character device)
+	VHOSTVDPA NetDeviceType = "vhost-vdpa"
+
+	// STREAM is a unix stream socket connected to a user-mode networking
+	// backend, e.g. passt
+	STREAM NetDeviceType = "stream"
 )
 
 // QemuNetdevParam converts to the QEMU -netdev parameter notation
@@ -901,6 +914,10 @@ func (n NetDeviceType) QemuNetdevParam(netdev *NetDevice, config *Config) string
 			log.Fatal("vhost-user devices are not supported on IBM Z")
 		}
 		return "vhost-user" // -netdev type=vhost-user (no device)
+	case VHOSTVDPA:
+		return "vhost-vdpa" // -netdev type=vhost-vdpa -device virtio-net-pci
+	case STREAM:
+		return "stream" // -netdev type=stream -device virtio-net-pci
 	default:
 		return ""
 
@@ -934,6 +951,10 @@ func (n NetDeviceType) QemuDeviceParam(netdev *NetDevice, config *Config) Device
 			log.Fatal("vhost-user devices are not supported on IBM Z")
 		}
 		return "" // -netdev type=vhost-user (no device)
+	case VHOSTVDPA:
+		device = "virtio-net" // -netdev type=vhost-vdpa -device virtio-net-pci
+	case STREAM:
+		device = "virtio-net" // -netdev type=stream -device virtio-net-pci
 	default:
 		return ""
 	}
@@ -977,6 +998,16 @@ type NetDevice struct {
 	// Script is the tap interface configuration script.
 	Script string
 
+	// VhostDev is the vhost-vdpa character device path.
+	VhostDev string
+
+	// Queues is the number of queue pairs of a vhost-vdpa device, the
+	// device is multi-queue when it has more than one.
+	Queues int
+
+	// SocketPath is the path to the unix socket of a stream netdev.
+	SocketPath string
+
 	// FDs represents the list of already existing file descriptors to be used.
 	// This is mostly useful for mq support.
 	FDs      []*os.File
@@ -1011,6 +1042,14 @@ var VirtioNetTransport = map[VirtioTransport]string{
 
 // Valid returns true if the NetDevice structure is valid and complete.
 func (netdev NetDevice) Valid() bool {
+	if netdev.Type == VHOSTVDPA {
+		return netdev.ID != "" && netdev.VhostDev != ""
+	}
+
+	if netdev.Type == STREAM {
+		return netdev.ID != "" && netdev.SocketPath != ""
+	}
+
 	if netdev.ID == "" || netdev.IFName == "" {
 		return false
 	}
@@ -1042,7 +1081,11 @@ func (netdev NetDevice) mqParameter(config *Config) string {
 		// Clearlinux automatically sets up the queues properly
 		// The agent implementation should do this to ensure that it is
 		// always set
-		vectors := len(netdev.FDs)*2 + 2
+		queues := len(netdev.FDs)
+		if netdev.Type == VHOSTVDPA {
+			queues = netdev.Queues
+		}
+		vectors := queues*2 + 2
 		p = append(p, fmt.Sprintf("vectors=%d", vectors))
 	}
 
@@ -1076,7 +1119,7 @@ func (netdev NetDevice) QemuDeviceParams(config *Config) []string {
 		deviceParams = append(deviceParams, s)
 	}
 
-	if len(netdev.FDs) > 0 {
+	if len(netdev.FDs) > 0 || (netdev.Type == VHOSTVDPA && netdev.Queues > 1) {
 		// Note: We are appending to the device params here
 		deviceParams = append(deviceParams, netdev.mqParameter(config))
 	}
@@ -1119,7 +1162,16 @@ func (netdev NetDevice) QemuNetdevParams(config *Config) []string {
 		}
 	}
 
-	if len(netdev.FDs) > 0 {
+	if netdev.Type == VHOSTVDPA {
+		netdevParams = append(netdevParams, fmt.Sprintf("vhostdev=%s", netdev.VhostDev))
+		if netdev.Queues > 1 {
+			netdevParams = append(netdevParams, fmt.Sprintf("queues=%d", netdev.Queues))
+		}
+	} else if netdev.Type == STREAM {
+		netdevParams = append(netdevParams, "server=off")
+		netdevParams = append(netdevParams, "addr.type=unix")
+		netdevParams = append(netdevParams, fmt.Sprintf("addr.path=%s", netdev.SocketPath))
+	} else if len(netdev.FDs) > 0 {
 		var fdParams []string
 
 		qemuFDs := config.appendFDs(netdev.FDs)
@@ -2551,6 +2603,53 @@ func (dev IommuDev) QemuParams(_ *Config) []string {
 	return qemuParams
 }
 
+// TPMDevice represents a TPM device backed by a TPM emulator, e.g. swtpm,
+// listening on a unix socket.
+type TPMDevice struct {
+	// ID is the TPM backend ID.
+	ID string
+
+	// Driver is the TPM interface device, tpm-crb or tpm-tis.
+	Driver DeviceDriver
+
+	// SocketPath is the control socket of the TPM emulator.
+	SocketPath string
+}
+
+// Valid returns true if the TPMDevice structure is valid and complete.
+func (dev TPMDevice) Valid() bool {
+	if dev.ID == "" || dev.SocketPath == "" {
+		return false
+	}
+
+	switch dev.Driver {
+	case TPMCRB, TPMTIS:
+		return true
+	}
+
+	return false
+}
+
+// QemuParams returns the qemu parameters built out of the TPMDevice.
+func (dev TPMDevice) QemuParams(_ *Config) []string {
+	var qemuParams []string
+
+	charDevID := "chr" + dev.ID
+
+	//-chardev socket,id=chrtpm0,path=/run/vc/vm/<id>/swtpm.sock
+	charDevParams := []string{"socket", "id=" + charDevID, "path=" + dev.SocketPath}
+	//-tpmdev emulator,id=tpm0,chardev=chrtpm0
+	tpmDevParams := []string{"emulator", "id=" + dev.ID, "chardev=" + charDevID}
+	//-device tpm-crb,tpmdev=tpm0
+	deviceParams := []string{string(dev.Driver), "tpmdev=" + dev.ID}
+
+	qemuParams = append(qemuParams, "-chardev", strings.Join(charDevParams, ","))
+	qemuParams = append(qemuParams, "-tpmdev", strings.Join(tpmDevParams, ","))
+	qemuParams = append(qemuParams, "-device", strings.Join(deviceParams, ","))
+
+	return qemuParams
+}
+
 // RTCBaseType is the qemu RTC base time type.
 type RTCBaseType string
 
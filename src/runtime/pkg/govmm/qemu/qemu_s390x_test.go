@@ -14,6 +14,9 @@ var (
 	deviceFSIOMMUString            = "-device virtio-9p-ccw,fsdev=workload9p,mount_tag=rootfs,iommu_platform=on,devno=" + DevNo + " -fsdev local,id=workload9p,path=/var/lib/docker/devicemapper/mnt/e31ebda2,security_model=none,multidevs=remap"
 	deviceNetworkString            = "-netdev tap,id=tap0,vhost=on,ifname=ceth0,downscript=no,script=no -device driver=virtio-net-ccw,netdev=tap0,mac=01:02:de:ad:be:ef,devno=" + DevNo
 	deviceNetworkStringMq          = "-netdev tap,id=tap0,vhost=on,fds=3:4 -device driver=virtio-net-ccw,netdev=tap0,mac=01:02:de:ad:be:ef,mq=on,devno=" + DevNo
+	deviceNetworkVdpaString        = "-netdev vhost-vdpa,id=vdpa0,vhostdev=/dev/vhost-vdpa-0 -device driver=virtio-net-ccw,netdev=vdpa0,mac=01:02:de:ad:be:ef,devno=" + DevNo
+	deviceNetworkVdpaMQString      = "-netdev vhost-vdpa,id=vdpa0,vhostdev=/dev/vhost-vdpa-0,queues=4 -device driver=virtio-net-ccw,netdev=vdpa0,mac=01:02:de:ad:be:ef,mq=on,devno=" + DevNo
+	deviceNetworkStreamString      = "-netdev stream,id=passt0,server=off,addr.type=unix,addr.path=/run/passt.socket -device driver=virtio-net-ccw,netdev=passt0,mac=01:02:de:ad:be:ef,devno=" + DevNo
 	deviceSerialString             = "-device virtio-serial-ccw,id=serial0,devno=" + DevNo
 	deviceVSOCKString              = "-device vhost-vsock-ccw,id=vhost-vsock-pci0,guest-cid=4,devno=" + DevNo
 	deviceVFIOString               = "-device vfio-ccw,host=02:10.0,devno=" + DevNo
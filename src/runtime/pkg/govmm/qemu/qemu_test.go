@@ -205,6 +205,70 @@ func TestAppendDeviceNetwork(t *testing.T) {
 	testAppend(netdev, deviceNetworkString, t)
 }
 
+func TestAppendDeviceNetworkVdpa(t *testing.T) {
+	netdev := NetDevice{
+		Driver:        VirtioNet,
+		Type:          VHOSTVDPA,
+		ID:            "vdpa0",
+		VhostDev:      "/dev/vhost-vdpa-0",
+		MACAddress:    "01:02:de:ad:be:ef",
+		DisableModern: true,
+		ROMFile:       romfile,
+	}
+
+	if netdev.Transport.isVirtioPCI(nil) {
+		netdev.Bus = "/pci-bus/pcie.0"
+		netdev.Addr = "255"
+	} else if netdev.Transport.isVirtioCCW(nil) {
+		netdev.DevNo = DevNo
+	}
+
+	testAppend(netdev, deviceNetworkVdpaString, t)
+}
+
+func TestAppendDeviceNetworkVdpaMQ(t *testing.T) {
+	netdev := NetDevice{
+		Driver:        VirtioNet,
+		Type:          VHOSTVDPA,
+		ID:            "vdpa0",
+		VhostDev:      "/dev/vhost-vdpa-0",
+		Queues:        4,
+		MACAddress:    "01:02:de:ad:be:ef",
+		DisableModern: true,
+		ROMFile:       romfile,
+	}
+
+	if netdev.Transport.isVirtioPCI(nil) {
+		netdev.Bus = "/pci-bus/pcie.0"
+		netdev.Addr = "255"
+	} else if netdev.Transport.isVirtioCCW(nil) {
+		netdev.DevNo = DevNo
+	}
+
+	testAppend(netdev, deviceNetworkVdpaMQString, t)
+}
+
+func TestAppendDeviceNetworkStream(t *testing.T) {
+	netdev := NetDevice{
+		Driver:        VirtioNet,
+		Type:          STREAM,
+		ID:            "passt0",
+		SocketPath:    "/run/passt.socket",
+		MACAddress:    "01:02:de:ad:be:ef",
+		DisableModern: true,
+		ROMFile:       romfile,
+	}
+
+	if netdev.Transport.isVirtioPCI(nil) {
+		netdev.Bus = "/pci-bus/pcie.0"
+		netdev.Addr = "255"
+	} else if netdev.Transport.isVirtioCCW(nil) {
+		netdev.DevNo = DevNo
+	}
+
+	testAppend(netdev, deviceNetworkStreamString, t)
+}
+
 func TestAppendDeviceNetworkMq(t *testing.T) {
 	foo, _ := os.CreateTemp(os.TempDir(), "govmm-qemu-test")
 	bar, _ := os.CreateTemp(os.TempDir(), "govmm-qemu-test")
@@ -1215,6 +1279,44 @@ func TestIommu(t *testing.T) {
 
 }
 
+var tpmString = "-chardev socket,id=chrtpm0,path=/run/vc/vm/sandbox/swtpm.sock -tpmdev emulator,id=tpm0,chardev=chrtpm0 -device tpm-crb,tpmdev=tpm0"
+
+func TestAppendTPMDevice(t *testing.T) {
+	tpm := TPMDevice{
+		ID:         "tpm0",
+		Driver:     TPMCRB,
+		SocketPath: "/run/vc/vm/sandbox/swtpm.sock",
+	}
+
+	testAppend(tpm, tpmString, t)
+
+	tpm.Driver = TPMTIS
+	testAppend(tpm, strings.Replace(tpmString, "tpm-crb", "tpm-tis", 1), t)
+}
+
+func TestTPMDeviceValid(t *testing.T) {
+	tpm := TPMDevice{
+		ID:         "tpm0",
+		Driver:     TPMCRB,
+		SocketPath: "/run/vc/vm/sandbox/swtpm.sock",
+	}
+
+	if !tpm.Valid() {
+		t.Fatalf("tpm should be valid")
+	}
+
+	tpm.Driver = SpaprTPMProxy
+	if tpm.Valid() {
+		t.Fatalf("tpm should be not valid with driver %s", tpm.Driver)
+	}
+
+	tpm.Driver = TPMTIS
+	tpm.SocketPath = ""
+	if tpm.Valid() {
+		t.Fatalf("tpm should be not valid when socket path is empty")
+	}
+}
+
 func TestAppendFwcfg(t *testing.T) {
 	fwcfgString := "-fw_cfg name=opt/com.mycompany/blob,file=./my_blob.bin"
 	fwcfg := FwCfg{
@@ -165,6 +165,48 @@ type HotpluggableCPU struct {
 	QOMPath    string        `json:"qom-path"`
 }
 
+// PCIDeviceInfo describes a PCI device, as listed by query-pci
+// nolint: govet
+type PCIDeviceInfo struct {
+	Bus       int            `json:"bus"`
+	Slot      int            `json:"slot"`
+	Function  int            `json:"function"`
+	QdevID    string         `json:"qdev_id"`
+	PCIBridge *PCIBridgeInfo `json:"pci_bridge,omitempty"`
+}
+
+// PCIBridgeInfo describes the secondary bus of a PCI bridge
+type PCIBridgeInfo struct {
+	Devices []PCIDeviceInfo `json:"devices"`
+}
+
+// PCIInfo describes a root PCI bus and its devices
+type PCIInfo struct {
+	Bus     int             `json:"bus"`
+	Devices []PCIDeviceInfo `json:"devices"`
+}
+
+// BlockInsertedInfo describes the medium of a block backend
+type BlockInsertedInfo struct {
+	NodeName string `json:"node-name"`
+	File     string `json:"file"`
+	ReadOnly bool   `json:"ro"`
+}
+
+// BlockInfo describes a block backend, as listed by query-block
+type BlockInfo struct {
+	Device   string             `json:"device"`
+	Qdev     string             `json:"qdev"`
+	Inserted *BlockInsertedInfo `json:"inserted,omitempty"`
+}
+
+// ObjectPropertyInfo describes a property of a QOM object, as listed by
+// qom-list. The children of an object are its child<...> properties.
+type ObjectPropertyInfo struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
 // MemoryDevicesData cotains the data describes a memory device
 // nolint: govet
 type MemoryDevicesData struct {
@@ -903,7 +945,8 @@ func (q *QMP) ExecuteDeviceAdd(ctx context.Context, blockdevID, devID, driver, b
 // using a SCSI driver with the device_add command.  blockdevID should match the
 // blockdevID passed to a previous call to ExecuteBlockdevAdd.  devID is the id of
 // the device to add.  Both strings must be valid QMP identifiers.  driver is the name of the
-// scsi driver,e.g., scsi-hd, and bus is the name of a SCSI controller bus.
+// scsi driver,e.g., scsi-hd, or scsi-generic and scsi-block to pass a host
+// SCSI device through, and bus is the name of a SCSI controller bus.
 // scsiID is the SCSI id, lun is logical unit number. scsiID and lun are optional, a negative value
 // for scsiID and lun is ignored. shared denotes if the drive can be shared allowing it
 // to be passed more than once.
@@ -911,8 +954,7 @@ func (q *QMP) ExecuteDeviceAdd(ctx context.Context, blockdevID, devID, driver, b
 // former version 0.9, as there is a KVM bug that occurs when using virtio
 // 1.0 in nested environments.
 func (q *QMP) ExecuteSCSIDeviceAdd(ctx context.Context, blockdevID, devID, driver, bus, romfile string, scsiID, lun int, shared, disableModern bool) error {
-	// TBD: Add drivers for scsi passthrough like scsi-generic and scsi-block
-	drivers := []string{"scsi-hd", "scsi-cd", "scsi-disk"}
+	drivers := []string{"scsi-hd", "scsi-cd", "scsi-disk", "scsi-generic", "scsi-block"}
 
 	isSCSIDriver := false
 	for _, d := range drivers {
@@ -957,6 +999,17 @@ func (q *QMP) ExecuteBlockdevDel(ctx context.Context, blockdevID string) error {
 	return q.executeCommand(ctx, "blockdev-del", args, nil)
 }
 
+// ExecuteBlockResize resizes the block device node nodeName to size bytes by
+// sending a block_resize command. The guest is notified of the new capacity.
+func (q *QMP) ExecuteBlockResize(ctx context.Context, nodeName string, size uint64) error {
+	args := map[string]interface{}{
+		"node-name": nodeName,
+		"size":      size,
+	}
+
+	return q.executeCommand(ctx, "block_resize", args, nil)
+}
+
 // ExecuteChardevDel deletes a char device by sending a chardev-remove command.
 // chardevID is the id of the char device to be deleted. Typically, this will
 // match the id passed to ExecuteCharDevUnixSocketAdd. It must be a valid QMP id.
@@ -1002,6 +1055,41 @@ func (q *QMP) ExecuteNetdevChardevAdd(ctx context.Context, netdevType, netdevID,
 	return q.executeCommand(ctx, "netdev_add", args, nil)
 }
 
+// ExecuteNetdevVhostVdpaAdd adds a vhost-vdpa Net device to a QEMU instance
+// using the netdev_add command. netdevID is the id of the device to add.
+// Must be valid QMP identifier. vhostdev is the path to the vhost-vdpa
+// character device, and queues the number of queue pairs of the device.
+func (q *QMP) ExecuteNetdevVhostVdpaAdd(ctx context.Context, netdevID, vhostdev string, queues int) error {
+	args := map[string]interface{}{
+		"type":     "vhost-vdpa",
+		"id":       netdevID,
+		"vhostdev": vhostdev,
+	}
+	if queues > 1 {
+		args["queues"] = queues
+	}
+
+	return q.executeCommand(ctx, "netdev_add", args, nil)
+}
+
+// ExecuteNetdevStreamAdd adds a stream Net device to a QEMU instance
+// using the netdev_add command. netdevID is the id of the device to add.
+// Must be valid QMP identifier. socketPath is the path to the unix socket
+// the device connects to.
+func (q *QMP) ExecuteNetdevStreamAdd(ctx context.Context, netdevID, socketPath string) error {
+	args := map[string]interface{}{
+		"type":   "stream",
+		"id":     netdevID,
+		"server": false,
+		"addr": map[string]interface{}{
+			"type": "unix",
+			"path": socketPath,
+		},
+	}
+
+	return q.executeCommand(ctx, "netdev_add", args, nil)
+}
+
 // ExecuteNetdevAddByFds adds a Net device to a QEMU instance
 // using the netdev_add command by fds and vhostfds. netdevID is the id of the device to add.
 // Must be valid QMP identifier.
@@ -1343,6 +1431,71 @@ func (q *QMP) ExecuteQueryHotpluggableCPUs(ctx context.Context) ([]HotpluggableC
 	return cpus, nil
 }
 
+// ExecuteQueryPCI returns the PCI buses and the devices plugged on them
+func (q *QMP) ExecuteQueryPCI(ctx context.Context) ([]PCIInfo, error) {
+	response, err := q.executeCommandWithResponse(ctx, "query-pci", nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("unable to extract PCI information: %v", err)
+	}
+
+	var buses []PCIInfo
+	if err = json.Unmarshal(data, &buses); err != nil {
+		return nil, fmt.Errorf("unable to convert json to PCI information: %v", err)
+	}
+
+	return buses, nil
+}
+
+// ExecuteQueryBlock returns the block backends of the devices
+func (q *QMP) ExecuteQueryBlock(ctx context.Context) ([]BlockInfo, error) {
+	response, err := q.executeCommandWithResponse(ctx, "query-block", nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("unable to extract block information: %v", err)
+	}
+
+	var blocks []BlockInfo
+	if err = json.Unmarshal(data, &blocks); err != nil {
+		return nil, fmt.Errorf("unable to convert json to block information: %v", err)
+	}
+
+	return blocks, nil
+}
+
+// ExecuteQomList returns the properties of the QOM object at path, e.g. the
+// devices with an ID are the children of /machine/peripheral.
+func (q *QMP) ExecuteQomList(ctx context.Context, path string) ([]ObjectPropertyInfo, error) {
+	args := map[string]interface{}{
+		"path": path,
+	}
+
+	response, err := q.executeCommandWithResponse(ctx, "qom-list", args, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("unable to extract object properties: %v", err)
+	}
+
+	var properties []ObjectPropertyInfo
+	if err = json.Unmarshal(data, &properties); err != nil {
+		return nil, fmt.Errorf("unable to convert json to object properties: %v", err)
+	}
+
+	return properties, nil
+}
+
 // ExecSetMigrationCaps sets migration capabilities
 func (q *QMP) ExecSetMigrationCaps(ctx context.Context, caps []map[string]interface{}) error {
 	args := map[string]interface{}{
@@ -1496,6 +1649,13 @@ func (q *QMP) ExecHotplugMemory(ctx context.Context, qomtype, id, mempath string
 // the data size of the device. pmem is to guarantee the persistence of QEMU writes
 // to the vNVDIMM backend.
 func (q *QMP) ExecuteNVDIMMDeviceAdd(ctx context.Context, id, mempath string, size int64, pmem *bool) error {
+	return q.ExecuteNVDIMMDeviceAddWithReadOnly(ctx, id, mempath, size, pmem, false)
+}
+
+// ExecuteNVDIMMDeviceAddWithReadOnly is like ExecuteNVDIMMDeviceAdd, but
+// maps the backend read-only if readOnly is true. The NVDIMM is then unarmed,
+// the guest cannot write to it and the backend can be shared by several VMs.
+func (q *QMP) ExecuteNVDIMMDeviceAddWithReadOnly(ctx context.Context, id, mempath string, size int64, pmem *bool, readOnly bool) error {
 	args := map[string]interface{}{
 		"qom-type": "memory-backend-file",
 		"id":       "nvdimmbackmem" + id,
@@ -1508,6 +1668,10 @@ func (q *QMP) ExecuteNVDIMMDeviceAdd(ctx context.Context, id, mempath string, si
 		args["pmem"] = *pmem
 	}
 
+	if readOnly {
+		args["readonly"] = true
+	}
+
 	err := q.executeCommand(ctx, "object-add", args, nil)
 	if err != nil {
 		return err
@@ -1518,6 +1682,9 @@ func (q *QMP) ExecuteNVDIMMDeviceAdd(ctx context.Context, id, mempath string, si
 		"id":     "nvdimm" + id,
 		"memdev": "nvdimmbackmem" + id,
 	}
+	if readOnly {
+		args["unarmed"] = true
+	}
 	if err = q.executeCommand(ctx, "device_add", args, nil); err != nil {
 		q.cfg.Logger.Errorf("Unable to hotplug NVDIMM device: %v", err)
 		err2 := q.executeCommand(ctx, "object-del", map[string]interface{}{"id": "nvdimmbackmem" + id}, nil)
@@ -1611,12 +1778,22 @@ func (q *QMP) ExecuteCharDevUnixSocketAdd(ctx context.Context, id, path string,
 // id is an identifier for the virtserialport, name is a name for the virtserialport and
 // it will be visible in the VM, chardev is the character device id previously added.
 func (q *QMP) ExecuteVirtSerialPortAdd(ctx context.Context, id, name, chardev string) error {
+	return q.ExecuteVirtSerialPortAddWithBus(ctx, id, name, chardev, "")
+}
+
+// ExecuteVirtSerialPortAddWithBus has one more parameter bus than
+// ExecuteVirtSerialPortAdd. bus is the virtio-serial bus the port is added
+// to, e.g. serial1.0, QEMU picks the first virtio-serial bus if empty.
+func (q *QMP) ExecuteVirtSerialPortAddWithBus(ctx context.Context, id, name, chardev, bus string) error {
 	args := map[string]interface{}{
 		"driver":  VirtioSerialPort,
 		"id":      id,
 		"name":    name,
 		"chardev": chardev,
 	}
+	if bus != "" {
+		args["bus"] = bus
+	}
 
 	return q.executeCommand(ctx, "device_add", args, nil)
 }
@@ -552,6 +552,50 @@ func TestQMPNetdevChardevAdd(t *testing.T) {
 	<-disconnectedCh
 }
 
+// Checks that the netdev_add command for a vhost-vdpa device is correctly sent.
+//
+// We start a QMPLoop, send the netdev_add command and stop the loop.
+//
+// The netdev_add command should be correctly sent and the QMP loop should
+// exit gracefully.
+func TestQMPNetdevVhostVdpaAdd(t *testing.T) {
+	connectedCh := make(chan *QMPVersion)
+	disconnectedCh := make(chan struct{})
+	buf := newQMPTestCommandBuffer(t)
+	buf.AddCommand("netdev_add", nil, "return", nil)
+	cfg := QMPConfig{Logger: qmpTestLogger{}}
+	q := startQMPLoop(buf, cfg, connectedCh, disconnectedCh)
+	q.version = checkVersion(t, connectedCh)
+	err := q.ExecuteNetdevVhostVdpaAdd(context.Background(), "vdpa0", "/dev/vhost-vdpa-0", 4)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	q.Shutdown()
+	<-disconnectedCh
+}
+
+// Checks that the netdev_add command for a stream device is correctly sent.
+//
+// We start a QMPLoop, send the netdev_add command and stop the loop.
+//
+// The netdev_add command should be correctly sent and the QMP loop should
+// exit gracefully.
+func TestQMPNetdevStreamAdd(t *testing.T) {
+	connectedCh := make(chan *QMPVersion)
+	disconnectedCh := make(chan struct{})
+	buf := newQMPTestCommandBuffer(t)
+	buf.AddCommand("netdev_add", nil, "return", nil)
+	cfg := QMPConfig{Logger: qmpTestLogger{}}
+	q := startQMPLoop(buf, cfg, connectedCh, disconnectedCh)
+	q.version = checkVersion(t, connectedCh)
+	err := q.ExecuteNetdevStreamAdd(context.Background(), "passt0", "/run/passt.socket")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	q.Shutdown()
+	<-disconnectedCh
+}
+
 // Checks that the netdev_add command with fds is correctly sent.
 //
 // We start a QMPLoop, send the netdev_add command with fds and stop the loop.
@@ -683,6 +727,35 @@ func TestQMPSCSIDeviceAdd(t *testing.T) {
 	<-disconnectedCh
 }
 
+// Checks that the device_add command for a SCSI passthrough device is
+// correctly sent, and that unknown SCSI drivers are rejected.
+func TestQMPSCSIPassthroughDeviceAdd(t *testing.T) {
+	connectedCh := make(chan *QMPVersion)
+	disconnectedCh := make(chan struct{})
+	buf := newQMPTestCommandBuffer(t)
+	buf.AddCommand("device_add", nil, "return", nil)
+	buf.AddCommand("device_add", nil, "return", nil)
+	cfg := QMPConfig{Logger: qmpTestLogger{}}
+	q := startQMPLoop(buf, cfg, connectedCh, disconnectedCh)
+	q.version = checkVersion(t, connectedCh)
+	blockdevID := fmt.Sprintf("drive_%s", volumeUUID)
+	devID := fmt.Sprintf("device_%s", volumeUUID)
+	for _, driver := range []string{"scsi-generic", "scsi-block"} {
+		err := q.ExecuteSCSIDeviceAdd(context.Background(), blockdevID, devID,
+			driver, "scsi0.0", "", 1, 2, true, false)
+		if err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+	}
+	err := q.ExecuteSCSIDeviceAdd(context.Background(), blockdevID, devID,
+		"scsi-tape", "scsi0.0", "", 1, 2, true, false)
+	if err == nil {
+		t.Fatalf("Expected error for an invalid SCSI driver")
+	}
+	q.Shutdown()
+	<-disconnectedCh
+}
+
 // Checks that the blockdev-del command is correctly sent.
 //
 // We start a QMPLoop, send the blockdev-del command and stop the loop.
@@ -706,6 +779,29 @@ func TestQMPBlockdevDel(t *testing.T) {
 	<-disconnectedCh
 }
 
+// Checks that the block_resize command is correctly sent.
+//
+// We start a QMPLoop, send the block_resize command and stop the loop.
+//
+// The block_resize command should be correctly sent and the QMP loop should
+// exit gracefully.
+func TestQMPBlockResize(t *testing.T) {
+	connectedCh := make(chan *QMPVersion)
+	disconnectedCh := make(chan struct{})
+	buf := newQMPTestCommandBuffer(t)
+	buf.AddCommand("block_resize", nil, "return", nil)
+	cfg := QMPConfig{Logger: qmpTestLogger{}}
+	q := startQMPLoop(buf, cfg, connectedCh, disconnectedCh)
+	q.version = checkVersion(t, connectedCh)
+	err := q.ExecuteBlockResize(context.Background(),
+		fmt.Sprintf("drive_%s", volumeUUID), 1<<30)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	q.Shutdown()
+	<-disconnectedCh
+}
+
 // Checks that the chardev-remove command is correctly sent.
 //
 // We start a QMPLoop, send the chardev-remove command and stop the loop.
@@ -1314,6 +1410,102 @@ func TestQMPExecuteQueryHotpluggableCPUs(t *testing.T) {
 	<-disconnectedCh
 }
 
+// Checks that PCI devices are listed correctly
+func TestQMPExecuteQueryPCI(t *testing.T) {
+	connectedCh := make(chan *QMPVersion)
+	disconnectedCh := make(chan struct{})
+	buf := newQMPTestCommandBuffer(t)
+	bus := PCIInfo{
+		Bus: 0,
+		Devices: []PCIDeviceInfo{
+			{
+				Slot:   2,
+				QdevID: "pci-bridge-0",
+				PCIBridge: &PCIBridgeInfo{
+					Devices: []PCIDeviceInfo{
+						{Bus: 1, Slot: 1, QdevID: "virtio-drive-abc"},
+					},
+				},
+			},
+			{Slot: 3, QdevID: "vfio-device-0"},
+		},
+	}
+	buf.AddCommand("query-pci", nil, "return", []interface{}{bus})
+	cfg := QMPConfig{Logger: qmpTestLogger{}}
+	q := startQMPLoop(buf, cfg, connectedCh, disconnectedCh)
+	checkVersion(t, connectedCh)
+	buses, err := q.ExecuteQueryPCI(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(buses) != 1 {
+		t.Fatalf("Expected PCI buses length equals to 1\n")
+	}
+	if reflect.DeepEqual(buses[0], bus) == false {
+		t.Fatalf("Expected %v equals to %v", buses[0], bus)
+	}
+	q.Shutdown()
+	<-disconnectedCh
+}
+
+// Checks that block backends are listed correctly
+func TestQMPExecuteQueryBlock(t *testing.T) {
+	connectedCh := make(chan *QMPVersion)
+	disconnectedCh := make(chan struct{})
+	buf := newQMPTestCommandBuffer(t)
+	block := BlockInfo{
+		Device: "",
+		Qdev:   "virtio-drive-abc",
+		Inserted: &BlockInsertedInfo{
+			NodeName: "drive-abc",
+			File:     "/dev/sdb",
+		},
+	}
+	buf.AddCommand("query-block", nil, "return", []interface{}{block})
+	cfg := QMPConfig{Logger: qmpTestLogger{}}
+	q := startQMPLoop(buf, cfg, connectedCh, disconnectedCh)
+	checkVersion(t, connectedCh)
+	blocks, err := q.ExecuteQueryBlock(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(blocks) != 1 {
+		t.Fatalf("Expected block backends length equals to 1\n")
+	}
+	if reflect.DeepEqual(blocks[0], block) == false {
+		t.Fatalf("Expected %v equals to %v", blocks[0], block)
+	}
+	q.Shutdown()
+	<-disconnectedCh
+}
+
+// Checks that the properties of a QOM object are listed correctly
+func TestQMPExecuteQomList(t *testing.T) {
+	connectedCh := make(chan *QMPVersion)
+	disconnectedCh := make(chan struct{})
+	buf := newQMPTestCommandBuffer(t)
+	property := ObjectPropertyInfo{
+		Name: "virtio-serial-abc",
+		Type: "child<virtserialport>",
+	}
+	buf.AddCommand("qom-list", map[string]interface{}{"path": "/machine/peripheral"}, "return", []interface{}{property})
+	cfg := QMPConfig{Logger: qmpTestLogger{}}
+	q := startQMPLoop(buf, cfg, connectedCh, disconnectedCh)
+	checkVersion(t, connectedCh)
+	properties, err := q.ExecuteQomList(context.Background(), "/machine/peripheral")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(properties) != 1 {
+		t.Fatalf("Expected properties length equals to 1\n")
+	}
+	if reflect.DeepEqual(properties[0], property) == false {
+		t.Fatalf("Expected %v equals to %v", properties[0], property)
+	}
+	q.Shutdown()
+	<-disconnectedCh
+}
+
 // Checks that memory devices are listed correctly
 func TestQMPExecuteQueryMemoryDevices(t *testing.T) {
 	connectedCh := make(chan *QMPVersion)
@@ -1588,6 +1780,23 @@ func TestExecuteVirtSerialPortAdd(t *testing.T) {
 	<-disconnectedCh
 }
 
+// Check add a virtserialport on a given bus
+func TestExecuteVirtSerialPortAddWithBus(t *testing.T) {
+	connectedCh := make(chan *QMPVersion)
+	disconnectedCh := make(chan struct{})
+	buf := newQMPTestCommandBuffer(t)
+	buf.AddCommand("device_add", nil, "return", nil)
+	cfg := QMPConfig{Logger: qmpTestLogger{}}
+	q := startQMPLoop(buf, cfg, connectedCh, disconnectedCh)
+	checkVersion(t, connectedCh)
+	err := q.ExecuteVirtSerialPortAddWithBus(context.Background(), "foo", "foo.channel", "foo", "serial1.0")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	q.Shutdown()
+	<-disconnectedCh
+}
+
 // Check migration incoming
 func TestExecuteMigrationIncoming(t *testing.T) {
 	connectedCh := make(chan *QMPVersion)
@@ -1775,6 +1984,32 @@ func TestExecuteNVDIMMDeviceAdd(t *testing.T) {
 	<-disconnectedCh
 }
 
+func TestExecuteNVDIMMDeviceAddWithReadOnly(t *testing.T) {
+	connectedCh := make(chan *QMPVersion)
+	disconnectedCh := make(chan struct{})
+	buf := newQMPTestCommandBuffer(t)
+	buf.AddCommand("object-add", map[string]interface{}{
+		"id":       "nvdimmbackmemnvdimm0",
+		"mem-path": "/srv/dataset.img",
+		"readonly": true,
+	}, "return", nil)
+	buf.AddCommand("device_add", map[string]interface{}{
+		"driver":  "nvdimm",
+		"memdev":  "nvdimmbackmemnvdimm0",
+		"unarmed": true,
+	}, "return", nil)
+	cfg := QMPConfig{Logger: qmpTestLogger{}}
+	q := startQMPLoop(buf, cfg, connectedCh, disconnectedCh)
+	checkVersion(t, connectedCh)
+	pmem := true
+	err := q.ExecuteNVDIMMDeviceAddWithReadOnly(context.Background(), "nvdimm0", "/srv/dataset.img", 1024, &pmem, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	q.Shutdown()
+	<-disconnectedCh
+}
+
 func TestMainLoopEventBeforeGreeting(t *testing.T) {
 	const (
 		seconds      = int64(1352167040730)
@@ -44,6 +44,8 @@ type HypervisorState struct {
 
 	HotpluggedMemory  int
 	VirtiofsDaemonPid int
+	SwtpmPid          int
+	PasstPid          int
 	Pid               int
 	HotPlugVFIO       config.PCIePort
 	ColdPlugVFIO      config.PCIePort
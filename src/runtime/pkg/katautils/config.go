@@ -101,6 +101,9 @@ type hypervisor struct {
 	SharedFS                       string                    `toml:"shared_fs"`
 	VirtioFSDaemon                 string                    `toml:"virtio_fs_daemon"`
 	VirtioFSCache                  string                    `toml:"virtio_fs_cache"`
+	VirtioFSExternalSocket         string                    `toml:"virtio_fs_external_socket"`
+	VirtioFSExternalRestartHook    string                    `toml:"virtio_fs_external_restart_hook"`
+	VirtioFSExternalHealthCheck    string                    `toml:"virtio_fs_external_health_check"`
 	VhostUserStorePath             string                    `toml:"vhost_user_store_path"`
 	FileBackedMemRootDir           string                    `toml:"file_mem_backend"`
 	GuestHookPath                  string                    `toml:"guest_hook_path"`
@@ -166,8 +169,18 @@ type hypervisor struct {
 	DisableImageNvdimm             bool                      `toml:"disable_image_nvdimm"`
 	HotPlugVFIO                    config.PCIePort           `toml:"hot_plug_vfio"`
 	ColdPlugVFIO                   config.PCIePort           `toml:"cold_plug_vfio"`
+	VFIOAutoBind                   bool                      `toml:"vfio_auto_bind"`
+	HostSysfsRoot                  string                    `toml:"host_sysfs_root"`
+	SCSIPassthrough                bool                      `toml:"scsi_passthrough"`
+	CharDevicePassthrough          []string                  `toml:"char_device_passthrough"`
+	CharDeviceTransport            string                    `toml:"char_device_transport"`
+	EnableTPM                      bool                      `toml:"enable_tpm"`
+	SwtpmPath                      string                    `toml:"swtpm_path"`
+	TPMModel                       string                    `toml:"tpm_model"`
+	TPMStatePath                   string                    `toml:"tpm_state_path"`
 	PCIeRootPort                   uint32                    `toml:"pcie_root_port"`
 	PCIeSwitchPort                 uint32                    `toml:"pcie_switch_port"`
+	PCIeHotplugPorts               uint32                    `toml:"pcie_hotplug_ports"`
 	DisableVhostNet                bool                      `toml:"disable_vhost_net"`
 	GuestMemoryDumpPaging          bool                      `toml:"guest_memory_dump_paging"`
 	ConfidentialGuest              bool                      `toml:"confidential_guest"`
@@ -202,9 +215,14 @@ type runtime struct {
 	EmptyDirMode              string   `toml:"emptydir_mode"`
 	CreateContainerTimeout    uint64   `toml:"create_container_timeout"`
 	DanConf                   string   `toml:"dan_conf"`
+	NetworkPolicy             string   `toml:"network_policy"`
+	UserModeNetworking        bool     `toml:"user_mode_networking"`
+	PasstPath                 string   `toml:"passt_path"`
 	ForceGuestPull            bool     `toml:"experimental_force_guest_pull"`
 	PodResourceAPISock        string   `toml:"pod_resource_api_sock"`
 	KubeletRootDir            string   `toml:"kubelet_root_dir"`
+	ErofsLayerCache           bool     `toml:"erofs_layer_cache"`
+	ErofsLayerCacheDir        string   `toml:"erofs_layer_cache_dir"`
 }
 
 // emptyDirMode returns a valid emptydir_mode value, defaulting to shared-fs
@@ -596,6 +614,22 @@ func (h hypervisor) blockDeviceDriver() (string, error) {
 	return "", fmt.Errorf("Invalid hypervisor block storage driver %v specified (supported drivers: %v)", h.BlockDeviceDriver, supportedBlockDrivers)
 }
 
+func (h hypervisor) charDeviceTransport() (string, error) {
+	supportedTransports := []string{config.CharDeviceVirtioSerial, config.CharDeviceVsock}
+
+	if h.CharDeviceTransport == "" {
+		return config.CharDeviceVirtioSerial, nil
+	}
+
+	for _, t := range supportedTransports {
+		if t == h.CharDeviceTransport {
+			return h.CharDeviceTransport, nil
+		}
+	}
+
+	return "", fmt.Errorf("Invalid character device transport %v specified (supported transports: %v)", h.CharDeviceTransport, supportedTransports)
+}
+
 func (h hypervisor) blockDeviceLogicalSectorSize() (uint32, error) {
 	if err := validateBlockDeviceSectorSize(cfgBlockDeviceLogicalSectorSize, h.BlockDeviceLogicalSectorSize); err != nil {
 		return 0, err
@@ -643,7 +677,7 @@ func (h hypervisor) extraMonitorSocket() (govmmQemu.MonitorProtocol, error) {
 }
 
 func (h hypervisor) sharedFS() (string, error) {
-	supportedSharedFS := []string{config.Virtio9P, config.VirtioFS, config.VirtioFSNydus, config.NoSharedFS}
+	supportedSharedFS := append([]string{config.Virtio9P, config.NoSharedFS}, vc.VirtioFSBackends()...)
 
 	if h.SharedFS == "" {
 		return config.VirtioFS, nil
@@ -658,6 +692,23 @@ func (h hypervisor) sharedFS() (string, error) {
 	return "", fmt.Errorf("Invalid hypervisor shared file system %v specified (supported file systems: %v)", h.SharedFS, supportedSharedFS)
 }
 
+// checkVirtioFSDaemon checks that the daemon serving the virtio-fs shared file
+// system is configured.
+func (h hypervisor) checkVirtioFSDaemon(sharedFS string) error {
+	switch {
+	case sharedFS == config.VirtioFSExternal:
+		if h.VirtioFSExternalSocket == "" {
+			return fmt.Errorf("cannot enable %s without external daemon socket in configuration file", sharedFS)
+		}
+	case vc.IsVirtioFS(sharedFS):
+		if h.VirtioFSDaemon == "" {
+			return fmt.Errorf("cannot enable %s without daemon path in configuration file", sharedFS)
+		}
+	}
+
+	return nil
+}
+
 func (h hypervisor) msize9p() uint32 {
 	if h.Msize9p == 0 {
 		return defaultMsize9p
@@ -681,6 +732,38 @@ func (h hypervisor) guestHookPath() string {
 	return h.GuestHookPath
 }
 
+func (h hypervisor) swtpmPath() string {
+	if h.SwtpmPath == "" {
+		return defaultSwtpmPath
+	}
+	return h.SwtpmPath
+}
+
+func (h hypervisor) tpmModel() (string, error) {
+	switch h.TPMModel {
+	case "":
+		return defaultTPMModel, nil
+	case string(govmmQemu.TPMCRB), string(govmmQemu.TPMTIS):
+		return h.TPMModel, nil
+	}
+
+	return "", fmt.Errorf("Invalid TPM model %q, must be one of %s or %s", h.TPMModel, govmmQemu.TPMCRB, govmmQemu.TPMTIS)
+}
+
+func (h hypervisor) tpmStatePath() string {
+	if h.TPMStatePath == "" {
+		return defaultTPMStatePath
+	}
+	return h.TPMStatePath
+}
+
+func (h hypervisor) hostSysfsRoot() (string, error) {
+	if h.HostSysfsRoot != "" && !filepath.IsAbs(h.HostSysfsRoot) {
+		return "", fmt.Errorf("Invalid host sysfs root %q, must be an absolute path", h.HostSysfsRoot)
+	}
+	return h.HostSysfsRoot, nil
+}
+
 func (h hypervisor) vhostUserStorePath() string {
 	if h.VhostUserStorePath == "" {
 		return defaultVhostUserStorePath
@@ -994,14 +1077,33 @@ func newQemuHypervisorConfig(h hypervisor) (vc.HypervisorConfig, error) {
 		return vc.HypervisorConfig{}, err
 	}
 
+	if h.SCSIPassthrough && blockDriver != config.VirtioSCSI {
+		return vc.HypervisorConfig{},
+			fmt.Errorf("cannot enable scsi_passthrough with block device driver %s, it requires %s", blockDriver, config.VirtioSCSI)
+	}
+
+	tpmModel, err := h.tpmModel()
+	if err != nil {
+		return vc.HypervisorConfig{}, err
+	}
+
+	hostSysfsRoot, err := h.hostSysfsRoot()
+	if err != nil {
+		return vc.HypervisorConfig{}, err
+	}
+
+	charDeviceTransport, err := h.charDeviceTransport()
+	if err != nil {
+		return vc.HypervisorConfig{}, err
+	}
+
 	sharedFS, err := h.sharedFS()
 	if err != nil {
 		return vc.HypervisorConfig{}, err
 	}
 
-	if (sharedFS == config.VirtioFS || sharedFS == config.VirtioFSNydus) && h.VirtioFSDaemon == "" {
-		return vc.HypervisorConfig{},
-			fmt.Errorf("cannot enable %s without daemon path in configuration file", sharedFS)
+	if err := h.checkVirtioFSDaemon(sharedFS); err != nil {
+		return vc.HypervisorConfig{}, err
 	}
 
 	if vSock, err := utils.SupportsVsocks(); !vSock {
@@ -1059,6 +1161,9 @@ func newQemuHypervisorConfig(h hypervisor) (vc.HypervisorConfig, error) {
 		DisableBlockDeviceUse:         h.DisableBlockDeviceUse,
 		SharedFS:                      sharedFS,
 		VirtioFSDaemon:                h.VirtioFSDaemon,
+		VirtioFSExternalSocket:        h.VirtioFSExternalSocket,
+		VirtioFSExternalRestartHook:   h.VirtioFSExternalRestartHook,
+		VirtioFSExternalHealthCheck:   h.VirtioFSExternalHealthCheck,
 		VirtioFSDaemonList:            h.VirtioFSDaemonList,
 		HypervisorLoglevel:            h.defaultHypervisorLoglevel(),
 		VirtioFSCacheSize:             h.VirtioFSCacheSize,
@@ -1088,8 +1193,18 @@ func newQemuHypervisorConfig(h hypervisor) (vc.HypervisorConfig, error) {
 		DisableImageNvdimm:            h.DisableImageNvdimm,
 		HotPlugVFIO:                   h.hotPlugVFIO(),
 		ColdPlugVFIO:                  h.coldPlugVFIO(),
+		VFIOAutoBind:                  h.VFIOAutoBind,
+		HostSysfsRoot:                 hostSysfsRoot,
+		SCSIPassthrough:               h.SCSIPassthrough,
+		CharDevicePassthrough:         h.CharDevicePassthrough,
+		CharDeviceTransport:           charDeviceTransport,
+		EnableTPM:                     h.EnableTPM,
+		SwtpmPath:                     h.swtpmPath(),
+		TPMModel:                      tpmModel,
+		TPMStatePath:                  h.tpmStatePath(),
 		PCIeRootPort:                  h.pcieRootPort(),
 		PCIeSwitchPort:                h.pcieSwitchPort(),
+		PCIeHotplugPorts:              h.PCIeHotplugPorts,
 		DisableVhostNet:               h.DisableVhostNet,
 		EnableVhostUserStore:          h.EnableVhostUserStore,
 		VhostUserStorePath:            h.vhostUserStorePath(),
@@ -1162,19 +1277,23 @@ func newClhHypervisorConfig(h hypervisor) (vc.HypervisorConfig, error) {
 		return vc.HypervisorConfig{}, err
 	}
 
+	hostSysfsRoot, err := h.hostSysfsRoot()
+	if err != nil {
+		return vc.HypervisorConfig{}, err
+	}
+
 	sharedFS, err := h.sharedFS()
 	if err != nil {
 		return vc.HypervisorConfig{}, err
 	}
 
-	if sharedFS != config.VirtioFS && sharedFS != config.VirtioFSNydus && sharedFS != config.NoSharedFS {
+	if !vc.IsVirtioFS(sharedFS) && sharedFS != config.NoSharedFS {
 		return vc.HypervisorConfig{},
 			fmt.Errorf("Cloud Hypervisor does not support %s shared filesystem option", sharedFS)
 	}
 
-	if (sharedFS == config.VirtioFS || sharedFS == config.VirtioFSNydus) && h.VirtioFSDaemon == "" {
-		return vc.HypervisorConfig{},
-			fmt.Errorf("cannot enable %s without daemon path in configuration file", sharedFS)
+	if err := h.checkVirtioFSDaemon(sharedFS); err != nil {
+		return vc.HypervisorConfig{}, err
 	}
 
 	return vc.HypervisorConfig{
@@ -1202,6 +1321,9 @@ func newClhHypervisorConfig(h hypervisor) (vc.HypervisorConfig, error) {
 		DisableBlockDeviceUse:          h.DisableBlockDeviceUse,
 		SharedFS:                       sharedFS,
 		VirtioFSDaemon:                 h.VirtioFSDaemon,
+		VirtioFSExternalSocket:         h.VirtioFSExternalSocket,
+		VirtioFSExternalRestartHook:    h.VirtioFSExternalRestartHook,
+		VirtioFSExternalHealthCheck:    h.VirtioFSExternalHealthCheck,
 		VirtioFSDaemonList:             h.VirtioFSDaemonList,
 		HypervisorLoglevel:             h.defaultHypervisorLoglevel(),
 		VirtioFSCacheSize:              h.VirtioFSCacheSize,
@@ -1222,6 +1344,11 @@ func newClhHypervisorConfig(h hypervisor) (vc.HypervisorConfig, error) {
 		DisableImageNvdimm:             h.DisableImageNvdimm,
 		ColdPlugVFIO:                   h.coldPlugVFIO(),
 		HotPlugVFIO:                    h.hotPlugVFIO(),
+		VFIOAutoBind:                   h.VFIOAutoBind,
+		HostSysfsRoot:                  hostSysfsRoot,
+		EnableTPM:                      h.EnableTPM,
+		SwtpmPath:                      h.swtpmPath(),
+		TPMStatePath:                   h.tpmStatePath(),
 		PCIeRootPort:                   h.pcieRootPort(),
 		PCIeSwitchPort:                 h.pcieSwitchPort(),
 		DisableVhostNet:                true,
@@ -1332,14 +1459,13 @@ func newStratovirtHypervisorConfig(h hypervisor) (vc.HypervisorConfig, error) {
 		return vc.HypervisorConfig{}, err
 	}
 
-	if sharedFS != config.VirtioFS && sharedFS != config.VirtioFSNydus && sharedFS != config.NoSharedFS {
+	if !vc.IsVirtioFS(sharedFS) && sharedFS != config.NoSharedFS {
 		return vc.HypervisorConfig{},
 			fmt.Errorf("Stratovirt Hypervisor does not support %s shared filesystem option", sharedFS)
 	}
 
-	if (sharedFS == config.VirtioFS || sharedFS == config.VirtioFSNydus) && h.VirtioFSDaemon == "" {
-		return vc.HypervisorConfig{},
-			fmt.Errorf("cannot enable %s without daemon path in configuration file", sharedFS)
+	if err := h.checkVirtioFSDaemon(sharedFS); err != nil {
+		return vc.HypervisorConfig{}, err
 	}
 
 	blockLogicalSectorSize, err := h.blockDeviceLogicalSectorSize()
@@ -1618,6 +1744,9 @@ func GetDefaultHypervisorConfig() vc.HypervisorConfig {
 		PCIeSwitchPort:           defaultPCIeSwitchPort,
 		GuestHookPath:            defaultGuestHookPath,
 		VhostUserStorePath:       defaultVhostUserStorePath,
+		SwtpmPath:                defaultSwtpmPath,
+		TPMModel:                 defaultTPMModel,
+		TPMStatePath:             defaultTPMStatePath,
 		VhostUserDeviceReconnect: defaultVhostUserDeviceReconnect,
 		HypervisorLoglevel:       defaultHypervisorLoglevel,
 		VirtioFSCache:            defaultVirtioFSCacheMode,
@@ -1744,6 +1873,19 @@ func LoadConfiguration(configPath string, ignoreLogging bool) (resolvedConfigPat
 	config.DisableGuestEmptyDir = tomlConf.Runtime.DisableGuestEmptyDir
 
 	config.DanConfig = tomlConf.Runtime.DanConf
+	if tomlConf.Runtime.NetworkPolicy != "" {
+		config.NetworkPolicy, err = types.LoadNetworkPolicy(tomlConf.Runtime.NetworkPolicy)
+		if err != nil {
+			return "", config, fmt.Errorf("Invalid network policy %q: %v", tomlConf.Runtime.NetworkPolicy, err)
+		}
+	}
+
+	config.UserModeNetworking = tomlConf.Runtime.UserModeNetworking
+	config.PasstPath = tomlConf.Runtime.PasstPath
+	if config.PasstPath == "" {
+		config.PasstPath = defaultPasstPath
+	}
+
 	if err := checkConfig(config); err != nil {
 		return "", config, err
 	}
@@ -1751,6 +1893,8 @@ func LoadConfiguration(configPath string, ignoreLogging bool) (resolvedConfigPat
 	config.ForceGuestPull = tomlConf.Runtime.ForceGuestPull
 	config.PodResourceAPISock = tomlConf.Runtime.PodResourceAPISock
 	config.KubeletRootDir = tomlConf.Runtime.KubeletRootDir
+	config.ErofsLayerCache = tomlConf.Runtime.ErofsLayerCache
+	config.ErofsLayerCacheDir = tomlConf.Runtime.ErofsLayerCacheDir
 
 	return resolved, config, nil
 }
@@ -1986,6 +2130,10 @@ func checkConfig(config oci.RuntimeConfig) error {
 		return err
 	}
 
+	if err := checkUserModeNetworkingConfig(config); err != nil {
+		return err
+	}
+
 	if err := checkHypervisorConfig(config.HypervisorConfig); err != nil {
 		return err
 	}
@@ -2060,6 +2208,16 @@ func checkNetNsConfig(config oci.RuntimeConfig) error {
 	return nil
 }
 
+// checkUserModeNetworkingConfig ensures the hypervisor can be connected to
+// the passt user-mode networking backend.
+func checkUserModeNetworkingConfig(config oci.RuntimeConfig) error {
+	if config.UserModeNetworking && config.HypervisorType != vc.QemuHypervisor {
+		return fmt.Errorf("user_mode_networking is only supported with the %s hypervisor", vc.QemuHypervisor)
+	}
+
+	return nil
+}
+
 // checkFactoryConfig ensures the VM factory configuration is valid.
 func checkFactoryConfig(config oci.RuntimeConfig) error {
 	if config.FactoryConfig.Template {
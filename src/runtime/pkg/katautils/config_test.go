@@ -183,6 +183,9 @@ func createAllRuntimeConfigFiles(dir, hypervisor string) (testConfig testRuntime
 		EntropySource:         defaultEntropySource,
 		GuestHookPath:         defaultGuestHookPath,
 		VhostUserStorePath:    defaultVhostUserStorePath,
+		SwtpmPath:             defaultSwtpmPath,
+		TPMModel:              defaultTPMModel,
+		TPMStatePath:          defaultTPMStatePath,
 		SharedFS:              sharedFS,
 		HypervisorLoglevel:    defaultHypervisorLoglevel,
 		VirtioFSDaemon:        virtioFSdaemon,
@@ -572,6 +575,9 @@ func TestMinimalRuntimeConfig(t *testing.T) {
 		Msize9p:               defaultMsize9p,
 		GuestHookPath:         defaultGuestHookPath,
 		VhostUserStorePath:    defaultVhostUserStorePath,
+		SwtpmPath:             defaultSwtpmPath,
+		TPMModel:              defaultTPMModel,
+		TPMStatePath:          defaultTPMStatePath,
 		HypervisorLoglevel:    defaultHypervisorLoglevel,
 		VirtioFSCache:         defaultVirtioFSCacheMode,
 		BlockDeviceAIO:        defaultBlockDeviceAIO,
@@ -1169,6 +1175,70 @@ func TestHypervisorDefaultsGuestHookPath(t *testing.T) {
 	assert.Equal(guestHookPath, testGuestHookPath, "custom guest hook path wrong")
 }
 
+func TestHypervisorDefaultsTPM(t *testing.T) {
+	assert := assert.New(t)
+
+	h := hypervisor{}
+	assert.Equal(defaultSwtpmPath, h.swtpmPath(), "default swtpm path wrong")
+	assert.Equal(defaultTPMStatePath, h.tpmStatePath(), "default TPM state path wrong")
+
+	model, err := h.tpmModel()
+	assert.NoError(err)
+	assert.Equal(defaultTPMModel, model, "default TPM model wrong")
+
+	h = hypervisor{
+		SwtpmPath:    "/test/swtpm",
+		TPMModel:     "tpm-tis",
+		TPMStatePath: "/test/tpm/state",
+	}
+	assert.Equal("/test/swtpm", h.swtpmPath(), "custom swtpm path wrong")
+	assert.Equal("/test/tpm/state", h.tpmStatePath(), "custom TPM state path wrong")
+
+	model, err = h.tpmModel()
+	assert.NoError(err)
+	assert.Equal("tpm-tis", model, "custom TPM model wrong")
+
+	h.TPMModel = "tpm-spapr"
+	_, err = h.tpmModel()
+	assert.Error(err)
+}
+
+func TestHypervisorDefaultsHostSysfsRoot(t *testing.T) {
+	assert := assert.New(t)
+
+	h := hypervisor{}
+	root, err := h.hostSysfsRoot()
+	assert.NoError(err)
+	assert.Empty(root, "default host sysfs root wrong")
+
+	h.HostSysfsRoot = "/host"
+	root, err = h.hostSysfsRoot()
+	assert.NoError(err)
+	assert.Equal("/host", root, "custom host sysfs root wrong")
+
+	h.HostSysfsRoot = "host"
+	_, err = h.hostSysfsRoot()
+	assert.Error(err)
+}
+
+func TestHypervisorDefaultsCharDeviceTransport(t *testing.T) {
+	assert := assert.New(t)
+
+	h := hypervisor{}
+	transport, err := h.charDeviceTransport()
+	assert.NoError(err)
+	assert.Equal(config.CharDeviceVirtioSerial, transport, "default character device transport wrong")
+
+	h.CharDeviceTransport = config.CharDeviceVsock
+	transport, err = h.charDeviceTransport()
+	assert.NoError(err)
+	assert.Equal(config.CharDeviceVsock, transport, "custom character device transport wrong")
+
+	h.CharDeviceTransport = "virtio-console"
+	_, err = h.charDeviceTransport()
+	assert.Error(err)
+}
+
 func TestHypervisorDefaultsVhostUserStorePath(t *testing.T) {
 	assert := assert.New(t)
 
@@ -1298,6 +1368,28 @@ func TestDefaultVirtioFSCache(t *testing.T) {
 	assert.Equal("never", cache)
 }
 
+func TestCheckVirtioFSDaemon(t *testing.T) {
+	assert := assert.New(t)
+
+	h := hypervisor{SharedFS: config.VirtioFSExternal}
+
+	sharedFS, err := h.sharedFS()
+	assert.NoError(err)
+	assert.Equal(config.VirtioFSExternal, sharedFS)
+
+	// The external daemon socket is required, not the daemon path
+	assert.Error(h.checkVirtioFSDaemon(config.VirtioFSExternal))
+	h.VirtioFSExternalSocket = "/run/vhost-user-fs/{sandbox_id}.sock"
+	assert.NoError(h.checkVirtioFSDaemon(config.VirtioFSExternal))
+
+	assert.Error(h.checkVirtioFSDaemon(config.VirtioFS))
+	assert.Error(h.checkVirtioFSDaemon(config.VirtioFSNydus))
+	h.VirtioFSDaemon = "/usr/libexec/virtiofsd"
+	assert.NoError(h.checkVirtioFSDaemon(config.VirtioFS))
+
+	assert.NoError(hypervisor{}.checkVirtioFSDaemon(config.Virtio9P))
+}
+
 func TestDefaultFirmware(t *testing.T) {
 	assert := assert.New(t)
 
@@ -1646,6 +1738,22 @@ func TestCheckNetNsConfig(t *testing.T) {
 	assert.Error(err)
 }
 
+func TestCheckUserModeNetworkingConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	config := oci.RuntimeConfig{
+		HypervisorType:     vc.QemuHypervisor,
+		UserModeNetworking: true,
+	}
+	assert.NoError(checkUserModeNetworkingConfig(config))
+
+	config.HypervisorType = vc.ClhHypervisor
+	assert.Error(checkUserModeNetworkingConfig(config))
+
+	config.UserModeNetworking = false
+	assert.NoError(checkUserModeNetworkingConfig(config))
+}
+
 func TestCheckEmptyDirMode(t *testing.T) {
 	assert := assert.New(t)
 
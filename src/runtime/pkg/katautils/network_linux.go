@@ -42,6 +42,13 @@ func SetupNetworkNamespace(config *vc.NetworkConfig) error {
 		return nil
 	}
 
+	if config.NetworkID == "" && config.UserModeNetworking {
+		// A new namespace would have no connectivity for passt to
+		// provide to the guest.
+		kataUtilsLogger.Info("User-mode networking is on, hypervisor is running in the caller netns")
+		return nil
+	}
+
 	if config.NetworkID == "" {
 		var (
 			err error
@@ -68,8 +75,8 @@ func SetupNetworkNamespace(config *vc.NetworkConfig) error {
 	if err != nil {
 		return err
 	}
-	if isHostNs {
-		return fmt.Errorf("Host networking requested, not supported by runtime")
+	if isHostNs && !config.UserModeNetworking {
+		return fmt.Errorf("Host networking requested, only supported by runtime with user-mode networking")
 	}
 
 	return nil
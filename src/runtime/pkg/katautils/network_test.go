@@ -148,6 +148,21 @@ func TestSetupNetworkNamespace(t *testing.T) {
 	config = &vc.NetworkConfig{DisableNewNetwork: true}
 	err = SetupNetworkNamespace(config)
 	assert.NoError(err)
+
+	// Network namespace same as the host, with user-mode networking
+	config = &vc.NetworkConfig{
+		NetworkID:          "/proc/self/ns/net",
+		UserModeNetworking: true,
+	}
+	err = SetupNetworkNamespace(config)
+	assert.NoError(err)
+
+	// Empty netns path, with user-mode networking
+	config = &vc.NetworkConfig{UserModeNetworking: true}
+	err = SetupNetworkNamespace(config)
+	assert.NoError(err)
+	assert.Empty(config.NetworkID)
+	assert.False(config.NetworkCreated)
 }
 
 func TestMountinfoFsType(t *testing.T) {
@@ -176,6 +176,16 @@ type RuntimeConfig struct {
 	// Base directory of directly attachable network config
 	DanConfig string
 
+	// NetworkPolicy restricts the egress traffic of the sandboxes.
+	NetworkPolicy *types.NetworkPolicy
+
+	// UserModeNetworking connects the sandboxes through passt, which
+	// does not need any privilege and supports host networking.
+	UserModeNetworking bool
+
+	// PasstPath is the path to the passt binary.
+	PasstPath string
+
 	// ForceGuestPull enforces guest pull independent of snapshotter annotations.
 	ForceGuestPull bool
 
@@ -201,6 +211,13 @@ type RuntimeConfig struct {
 	// KubeletRootDir is the kubelet root directory used to match ConfigMap/Secret
 	// volume paths (e.g. /var/lib/k0s/kubelet for k0s). If empty, default is used.
 	KubeletRootDir string
+
+	// ErofsLayerCache shares the erofs layers of the containers by digest.
+	ErofsLayerCache bool
+
+	// ErofsLayerCacheDir is the directory of the node-level erofs layer
+	// cache. If empty, default is used.
+	ErofsLayerCacheDir string
 }
 
 // AddKernelParam allows the addition of new kernel parameters to an existing
@@ -372,7 +389,8 @@ func containerDeviceInfos(spec specs.Spec) ([]config.DeviceInfo, error) {
 	return devices, nil
 }
 
-func getDanConfigPath(danConfigDir string, sandboxID string) string {
+// DanConfigPath returns the path of the DAN config of a sandbox.
+func DanConfigPath(danConfigDir string, sandboxID string) string {
 	return filepath.Join(danConfigDir, sandboxID+".json")
 }
 
@@ -395,9 +413,12 @@ func networkConfig(ocispec specs.Spec, sandboxID string, config RuntimeConfig) (
 	}
 	netConf.InterworkingModel = config.InterNetworkModel
 	netConf.DisableNewNetwork = config.DisableNewNetNs
+	netConf.NetworkPolicy = config.NetworkPolicy
+	netConf.UserModeNetworking = config.UserModeNetworking
+	netConf.PasstPath = config.PasstPath
 
 	// if dan config exits, it will be used to config network in guest VM
-	danConfig := getDanConfigPath(config.DanConfig, sandboxID)
+	danConfig := DanConfigPath(config.DanConfig, sandboxID)
 	if _, err := os.Stat(danConfig); err == nil {
 		netConf.DanConfigPath = danConfig
 	}
@@ -781,6 +802,12 @@ func addHypervisorMemoryOverrides(ocispec specs.Spec, sbConfig *vc.SandboxConfig
 		return err
 	}
 
+	if err := newAnnotationConfiguration(ocispec, vcAnnotations.EnableTPM).setBool(func(enableTPM bool) {
+		sbConfig.HypervisorConfig.EnableTPM = enableTPM
+	}); err != nil {
+		return err
+	}
+
 	if err := newAnnotationConfiguration(ocispec, vcAnnotations.EnableGuestSwap).setBool(func(enableGuestSwap bool) {
 		sbConfig.HypervisorConfig.GuestSwap = enableGuestSwap
 	}); err != nil {
@@ -957,7 +984,7 @@ func addHypervisorBlockOverrides(ocispec specs.Spec, sbConfig *vc.SandboxConfig)
 
 func addHypervisorVirtioFsOverrides(ocispec specs.Spec, sbConfig *vc.SandboxConfig, runtime RuntimeConfig) error {
 	if value, ok := ocispec.Annotations[vcAnnotations.SharedFS]; ok {
-		supportedSharedFS := []string{config.Virtio9P, config.VirtioFS}
+		supportedSharedFS := append([]string{config.Virtio9P}, vc.VirtioFSBackends()...)
 		valid := false
 		for _, fs := range supportedSharedFS {
 			if fs == value {
@@ -969,6 +996,10 @@ func addHypervisorVirtioFsOverrides(ocispec specs.Spec, sbConfig *vc.SandboxConf
 		if !valid {
 			return fmt.Errorf("Invalid hypervisor shared file system %v specified for annotation shared_fs, (supported file systems: %v)", value, supportedSharedFS)
 		}
+
+		if value == config.VirtioFSExternal && sbConfig.HypervisorConfig.VirtioFSExternalSocket == "" {
+			return fmt.Errorf("shared file system %v specified for annotation shared_fs requires virtio_fs_external_socket to be configured", value)
+		}
 	}
 
 	if value, ok := ocispec.Annotations[vcAnnotations.VirtioFSDaemon]; ok {
@@ -1141,6 +1172,69 @@ func addRuntimeConfigOverrides(ocispec specs.Spec, sbConfig *vc.SandboxConfig, r
 		sbConfig.NetworkConfig.InterworkingModel = runtimeConfig.InterNetworkModel
 	}
 
+	if value, ok := ocispec.Annotations[vcAnnotations.NetworkPolicy]; ok {
+		if runtime.NetworkPolicy != nil {
+			return fmt.Errorf("Network policy annotation %s cannot override the configured network policy", vcAnnotations.NetworkPolicy)
+		}
+
+		policy, err := types.ParseNetworkPolicy([]byte(value))
+		if err != nil {
+			return fmt.Errorf("Invalid network policy specified in annotation %s: %v", vcAnnotations.NetworkPolicy, err)
+		}
+
+		sbConfig.NetworkConfig.NetworkPolicy = policy
+	}
+
+	if err := addNetworkBandwidthOverrides(ocispec, sbConfig); err != nil {
+		return err
+	}
+
+	if value, ok := ocispec.Annotations[vcAnnotations.NetworkEndpointTypes]; ok {
+		var endpointTypes map[string]string
+		if err := json.Unmarshal([]byte(value), &endpointTypes); err != nil {
+			return fmt.Errorf("Invalid network endpoint types specified in annotation %s: %v", vcAnnotations.NetworkEndpointTypes, err)
+		}
+
+		sbConfig.NetworkConfig.EndpointTypes = make(map[string]vc.EndpointType, len(endpointTypes))
+		for name, value := range endpointTypes {
+			endpointType := vc.EndpointType(value)
+			if !vc.IsPluginEndpointType(endpointType) {
+				return fmt.Errorf("Unregistered network endpoint type %s for interface %s in annotation %s", value, name, vcAnnotations.NetworkEndpointTypes)
+			}
+			sbConfig.NetworkConfig.EndpointTypes[name] = endpointType
+		}
+	}
+
+	if err := newAnnotationConfiguration(ocispec, vcAnnotations.UserModeNetworking).setBool(func(userModeNetworking bool) {
+		sbConfig.NetworkConfig.UserModeNetworking = userModeNetworking
+	}); err != nil {
+		return err
+	}
+
+	if _, ok := ocispec.Annotations[vcAnnotations.UserModeNetworking]; ok && sbConfig.NetworkConfig.UserModeNetworking {
+		if runtime.HypervisorType != vc.QemuHypervisor {
+			return fmt.Errorf("User-mode networking requested in annotation %s is only supported with the %s hypervisor",
+				vcAnnotations.UserModeNetworking, vc.QemuHypervisor)
+		}
+
+		// Only the runtime configuration may connect the sandboxes of
+		// the host network namespace, as passt then forwards the host
+		// ports and traffic to the guest.
+		if sbConfig.NetworkConfig.NetworkID == "" {
+			return fmt.Errorf("User-mode networking requested in annotation %s is not supported for host network sandboxes",
+				vcAnnotations.UserModeNetworking)
+		}
+	}
+
+	if value, ok := ocispec.Annotations[vcAnnotations.NetworkForwardedPorts]; ok {
+		forwardedPorts, err := vc.ParsePortForwards(value)
+		if err != nil {
+			return fmt.Errorf("Invalid forwarded ports specified in annotation %s: %v", vcAnnotations.NetworkForwardedPorts, err)
+		}
+
+		sbConfig.NetworkConfig.ForwardedPorts = forwardedPorts
+	}
+
 	if value, ok := ocispec.Annotations[vcAnnotations.VfioMode]; ok {
 		if err := sbConfig.VfioMode.VFIOSetMode(value); err != nil {
 			return fmt.Errorf("Unknown VFIO mode \"%s\" in annotation %s",
@@ -1151,6 +1245,69 @@ func addRuntimeConfigOverrides(ocispec specs.Spec, sbConfig *vc.SandboxConfig, r
 	return nil
 }
 
+// interfaceBandwidth is the per-interface value of the NetworkBandwidth annotation.
+type interfaceBandwidth struct {
+	Ingress string `json:"ingress"`
+	Egress  string `json:"egress"`
+}
+
+// parseBandwidth converts a resource quantity to a rate in bits per second.
+func parseBandwidth(annotation, value string) (uint64, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid bandwidth %q specified in annotation %s: %v", value, annotation, err)
+	}
+
+	rate, ok := quantity.AsInt64()
+	if !ok || rate <= 0 {
+		return 0, fmt.Errorf("Invalid bandwidth %q specified in annotation %s", value, annotation)
+	}
+
+	return uint64(rate), nil
+}
+
+func addNetworkBandwidthOverrides(ocispec specs.Spec, sbConfig *vc.SandboxConfig) error {
+	var err error
+
+	bandwidth := &sbConfig.NetworkConfig.Bandwidth
+	if value, ok := ocispec.Annotations[vcAnnotations.IngressBandwidth]; ok {
+		if bandwidth.Ingress, err = parseBandwidth(vcAnnotations.IngressBandwidth, value); err != nil {
+			return err
+		}
+	}
+
+	if value, ok := ocispec.Annotations[vcAnnotations.EgressBandwidth]; ok {
+		if bandwidth.Egress, err = parseBandwidth(vcAnnotations.EgressBandwidth, value); err != nil {
+			return err
+		}
+	}
+
+	if value, ok := ocispec.Annotations[vcAnnotations.NetworkBandwidth]; ok {
+		var interfaces map[string]interfaceBandwidth
+		if err := json.Unmarshal([]byte(value), &interfaces); err != nil {
+			return fmt.Errorf("Invalid network bandwidth specified in annotation %s: %v", vcAnnotations.NetworkBandwidth, err)
+		}
+
+		sbConfig.NetworkConfig.InterfaceBandwidth = make(map[string]vc.NetworkBandwidth, len(interfaces))
+		for name, iface := range interfaces {
+			var ifaceBandwidth vc.NetworkBandwidth
+			if ifaceBandwidth.Ingress, err = parseBandwidth(vcAnnotations.NetworkBandwidth, iface.Ingress); err != nil {
+				return err
+			}
+			if ifaceBandwidth.Egress, err = parseBandwidth(vcAnnotations.NetworkBandwidth, iface.Egress); err != nil {
+				return err
+			}
+			sbConfig.NetworkConfig.InterfaceBandwidth[name] = ifaceBandwidth
+		}
+	}
+
+	return nil
+}
+
 func addAgentConfigOverrides(ocispec specs.Spec, config *vc.SandboxConfig) error {
 	c := config.AgentConfig
 	updateConfig := false
@@ -1258,12 +1415,21 @@ func SandboxConfig(ocispec specs.Spec, runtime RuntimeConfig, bundlePath, cid st
 		ForceGuestPull: runtime.ForceGuestPull,
 
 		KubeletRootDir: runtime.KubeletRootDir,
+
+		ErofsLayerCache:    runtime.ErofsLayerCache,
+		ErofsLayerCacheDir: runtime.ErofsLayerCacheDir,
 	}
 
 	if err := addAnnotations(ocispec, &sandboxConfig, runtime); err != nil {
 		return vc.SandboxConfig{}, err
 	}
 
+	// The TPM state of a pod outlives its sandboxes. It is only identified
+	// by the pod, so that no pod gets the TPM state of another one.
+	if podUID := ocispec.Annotations[ctrAnnotations.SandboxUID]; validTPMStateID(podUID) == nil {
+		sandboxConfig.HypervisorConfig.TPMStateID = podUID
+	}
+
 	// If we are utilizing static resource management for the sandbox, ensure that the hypervisor is started
 	// with the base number of CPU/memory (which is equal to the default CPU/memory specified for the runtime
 	// configuration or annotations) as well as any specified workload resources.
@@ -1288,6 +1454,15 @@ func SandboxConfig(ocispec specs.Spec, runtime RuntimeConfig, bundlePath, cid st
 	return sandboxConfig, nil
 }
 
+// validTPMStateID checks the TPM state identity can name the directory the
+// state is kept in.
+func validTPMStateID(id string) error {
+	if id == "" || id == "." || id == ".." || strings.ContainsRune(id, filepath.Separator) {
+		return fmt.Errorf("Invalid TPM state identity %q", id)
+	}
+	return nil
+}
+
 // ContainerConfig converts an OCI compatible runtime configuration
 // file to a virtcontainers container configuration structure.
 func ContainerConfig(ocispec specs.Spec, bundlePath, cid string, detach bool) (vc.ContainerConfig, error) {
@@ -60,7 +60,7 @@ func TestMinimalSandboxConfig(t *testing.T) {
 	savedFunc := config.GetHostPathFunc
 
 	// Simply assign container path to host path for device.
-	config.GetHostPathFunc = func(devInfo config.DeviceInfo, vhostUserStoreEnabled bool,
+	config.GetHostPathFunc = func(_ config.SysfsProvider, devInfo config.DeviceInfo, vhostUserStoreEnabled bool,
 		vhostUserStorePath string) (string, error) {
 		return devInfo.ContainerPath, nil
 	}
@@ -182,6 +182,13 @@ func TestMinimalSandboxConfig(t *testing.T) {
 	assert.NoError(err)
 
 	assert.Exactly(sandboxConfig, expectedSandboxConfig)
+
+	// The TPM state is kept under the pod UID
+	spec.Annotations = map[string]string{ctrAnnotations.SandboxUID: "pod-uid"}
+	sandboxConfig, err = SandboxConfig(spec, runtimeConfig, tempBundlePath, containerID, false, true)
+	assert.NoError(err)
+	assert.Equal("pod-uid", sandboxConfig.HypervisorConfig.TPMStateID)
+
 	assert.NoError(os.Remove(configPath))
 }
 
@@ -660,6 +667,7 @@ func TestAddHypervisorAnnotations(t *testing.T) {
 	ocispec.Annotations[vcAnnotations.PCIeRootPort] = "1"
 	ocispec.Annotations[vcAnnotations.PCIeSwitchPort] = "1"
 	ocispec.Annotations[vcAnnotations.IOMMUPlatform] = "true"
+	ocispec.Annotations[vcAnnotations.EnableTPM] = "true"
 	ocispec.Annotations[vcAnnotations.SGXEPC] = "64Mi"
 	ocispec.Annotations[vcAnnotations.UseLegacySerial] = "true"
 	// 10Mbit
@@ -704,6 +712,7 @@ func TestAddHypervisorAnnotations(t *testing.T) {
 	assert.Equal(sbConfig.HypervisorConfig.PCIeRootPort, uint32(1))
 	assert.Equal(sbConfig.HypervisorConfig.PCIeSwitchPort, uint32(1))
 	assert.Equal(sbConfig.HypervisorConfig.IOMMUPlatform, true)
+	assert.Equal(sbConfig.HypervisorConfig.EnableTPM, true)
 	assert.Equal(sbConfig.HypervisorConfig.SGXEPCSize, int64(67108864))
 	assert.Equal(sbConfig.HypervisorConfig.LegacySerial, true)
 	assert.Equal(sbConfig.HypervisorConfig.RxRateLimiterMaxRate, uint64(10000000))
@@ -999,6 +1008,167 @@ algorithm = "sha256"
 
 }
 
+func TestAddNetworkPolicyAnnotation(t *testing.T) {
+	assert := assert.New(t)
+
+	config := vc.SandboxConfig{
+		Annotations: make(map[string]string),
+	}
+
+	ocispec := specs.Spec{
+		Annotations: make(map[string]string),
+	}
+
+	runtimeConfig := RuntimeConfig{
+		HypervisorType: vc.QemuHypervisor,
+	}
+
+	ocispec.Annotations[vcAnnotations.NetworkPolicy] = `{"egress": [{"cidr": "10.0.0.0/8", "protocol": "tcp", "ports": [443]}]}`
+	err := addAnnotations(ocispec, &config, runtimeConfig)
+	assert.NoError(err)
+	assert.NotNil(config.NetworkConfig.NetworkPolicy)
+	assert.Equal("10.0.0.0/8", config.NetworkConfig.NetworkPolicy.Egress[0].CIDR)
+
+	ocispec.Annotations[vcAnnotations.NetworkPolicy] = `{"egress": [{"cidr": "10.0.0.0/8", "ports": [443]}]}`
+	err = addAnnotations(ocispec, &config, runtimeConfig)
+	assert.Error(err)
+
+	// The annotation cannot override the configured policy
+	ocispec.Annotations[vcAnnotations.NetworkPolicy] = `{"egress": []}`
+	runtimeConfig.NetworkPolicy = &types.NetworkPolicy{}
+	err = addAnnotations(ocispec, &config, runtimeConfig)
+	assert.Error(err)
+}
+
+func TestAddNetworkBandwidthAnnotations(t *testing.T) {
+	assert := assert.New(t)
+
+	config := vc.SandboxConfig{
+		Annotations: make(map[string]string),
+	}
+
+	ocispec := specs.Spec{
+		Annotations: make(map[string]string),
+	}
+
+	runtimeConfig := RuntimeConfig{
+		HypervisorType: vc.QemuHypervisor,
+	}
+
+	ocispec.Annotations[vcAnnotations.IngressBandwidth] = "10M"
+	ocispec.Annotations[vcAnnotations.EgressBandwidth] = "1G"
+	ocispec.Annotations[vcAnnotations.NetworkBandwidth] = `{"net1": {"ingress": "100k"}}`
+	err := addAnnotations(ocispec, &config, runtimeConfig)
+	assert.NoError(err)
+	assert.Equal(vc.NetworkBandwidth{Ingress: 10000000, Egress: 1000000000}, config.NetworkConfig.Bandwidth)
+	assert.Equal(map[string]vc.NetworkBandwidth{"net1": {Ingress: 100000}}, config.NetworkConfig.InterfaceBandwidth)
+
+	ocispec.Annotations[vcAnnotations.IngressBandwidth] = "fast"
+	err = addAnnotations(ocispec, &config, runtimeConfig)
+	assert.Error(err)
+
+	ocispec.Annotations[vcAnnotations.IngressBandwidth] = "-1M"
+	err = addAnnotations(ocispec, &config, runtimeConfig)
+	assert.Error(err)
+
+	ocispec.Annotations[vcAnnotations.IngressBandwidth] = "10M"
+	ocispec.Annotations[vcAnnotations.NetworkBandwidth] = `{"net1": "100k"}`
+	err = addAnnotations(ocispec, &config, runtimeConfig)
+	assert.Error(err)
+}
+
+type testEndpointFactory struct{}
+
+func (f testEndpointFactory) NewEndpoint(idx int, netInfo vc.NetworkInfo, model vc.NetInterworkingModel) (vc.PluggableEndpoint, error) {
+	return nil, nil
+}
+
+func (f testEndpointFactory) LoadEndpoint(state []byte) (vc.PluggableEndpoint, error) {
+	return nil, nil
+}
+
+func TestAddNetworkEndpointTypesAnnotation(t *testing.T) {
+	assert := assert.New(t)
+
+	config := vc.SandboxConfig{
+		Annotations: make(map[string]string),
+	}
+
+	ocispec := specs.Spec{
+		Annotations: make(map[string]string),
+	}
+
+	runtimeConfig := RuntimeConfig{
+		HypervisorType: vc.QemuHypervisor,
+	}
+
+	ocispec.Annotations[vcAnnotations.NetworkEndpointTypes] = `{"net1": "unregistered"}`
+	err := addAnnotations(ocispec, &config, runtimeConfig)
+	assert.Error(err)
+
+	// Built-in endpoint types cannot be forced
+	ocispec.Annotations[vcAnnotations.NetworkEndpointTypes] = `{"net1": "vfio"}`
+	err = addAnnotations(ocispec, &config, runtimeConfig)
+	assert.Error(err)
+
+	// The registry is global, the type may have been registered by a previous run.
+	_ = vc.RegisterEndpointType("oci-test", testEndpointFactory{})
+	assert.True(vc.IsPluginEndpointType("oci-test"))
+	ocispec.Annotations[vcAnnotations.NetworkEndpointTypes] = `{"net1": "oci-test"}`
+	err = addAnnotations(ocispec, &config, runtimeConfig)
+	assert.NoError(err)
+	assert.Equal(map[string]vc.EndpointType{"net1": "oci-test"}, config.NetworkConfig.EndpointTypes)
+
+	ocispec.Annotations[vcAnnotations.NetworkEndpointTypes] = `net1=vfio`
+	err = addAnnotations(ocispec, &config, runtimeConfig)
+	assert.Error(err)
+}
+
+func TestAddUserModeNetworkingAnnotations(t *testing.T) {
+	assert := assert.New(t)
+
+	config := vc.SandboxConfig{
+		Annotations: make(map[string]string),
+	}
+
+	ocispec := specs.Spec{
+		Annotations: make(map[string]string),
+	}
+
+	runtimeConfig := RuntimeConfig{
+		HypervisorType: vc.QemuHypervisor,
+	}
+
+	// Host network sandboxes cannot opt in
+	ocispec.Annotations[vcAnnotations.UserModeNetworking] = "true"
+	err := addAnnotations(ocispec, &config, runtimeConfig)
+	assert.Error(err)
+
+	config.NetworkConfig.NetworkID = "/var/run/netns/pod"
+
+	// Nor the sandboxes of other hypervisors
+	err = addAnnotations(ocispec, &config, RuntimeConfig{HypervisorType: vc.ClhHypervisor})
+	assert.Error(err)
+
+	ocispec.Annotations[vcAnnotations.NetworkForwardedPorts] = "8080:80,53/udp"
+	err = addAnnotations(ocispec, &config, runtimeConfig)
+	assert.NoError(err)
+	assert.True(config.NetworkConfig.UserModeNetworking)
+	assert.Equal([]vc.PortForward{
+		{Protocol: "tcp", HostPort: 8080, GuestPort: 80},
+		{Protocol: "udp", HostPort: 53, GuestPort: 53},
+	}, config.NetworkConfig.ForwardedPorts)
+
+	ocispec.Annotations[vcAnnotations.NetworkForwardedPorts] = "8080/sctp"
+	err = addAnnotations(ocispec, &config, runtimeConfig)
+	assert.Error(err)
+
+	ocispec.Annotations[vcAnnotations.NetworkForwardedPorts] = ""
+	ocispec.Annotations[vcAnnotations.UserModeNetworking] = "yes please"
+	err = addAnnotations(ocispec, &config, runtimeConfig)
+	assert.Error(err)
+}
+
 func TestRegexpContains(t *testing.T) {
 	assert := assert.New(t)
 
@@ -1461,3 +1631,36 @@ func TestNewMount(t *testing.T) {
 		assert.Equal(tt.out.ReadOnly, actualMount.ReadOnly, "unexpected mount ReadOnly")
 	}
 }
+
+func TestAddHypervisorSharedFSAnnotation(t *testing.T) {
+	assert := assert.New(t)
+
+	runtimeConfig := RuntimeConfig{
+		HypervisorType: vc.QemuHypervisor,
+	}
+	runtimeConfig.HypervisorConfig.EnableAnnotations = []string{".*"}
+
+	ocispec := specs.Spec{
+		Annotations: make(map[string]string),
+	}
+	sbConfig := vc.SandboxConfig{
+		Annotations: make(map[string]string),
+	}
+	sbConfig.HypervisorConfig.VirtioFSDaemon = "/usr/libexec/virtiofsd"
+
+	// Every virtio-fs backend is supported
+	for _, sharedFS := range vc.VirtioFSBackends() {
+		sbConfig.HypervisorConfig.VirtioFSExternalSocket = "/run/vhost-fs/{sandbox_id}.sock"
+		ocispec.Annotations[vcAnnotations.SharedFS] = sharedFS
+		assert.NoError(addAnnotations(ocispec, &sbConfig, runtimeConfig))
+		assert.Equal(sharedFS, sbConfig.HypervisorConfig.SharedFS)
+	}
+
+	ocispec.Annotations[vcAnnotations.SharedFS] = "virtio-fs-unknown"
+	assert.Error(addAnnotations(ocispec, &sbConfig, runtimeConfig))
+
+	// The external backend needs its socket
+	sbConfig.HypervisorConfig.VirtioFSExternalSocket = ""
+	ocispec.Annotations[vcAnnotations.SharedFS] = config.VirtioFSExternal
+	assert.Error(addAnnotations(ocispec, &sbConfig, runtimeConfig))
+}
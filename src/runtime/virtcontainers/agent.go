@@ -166,6 +166,9 @@ type agent interface {
 	// listRoutes will tell the agent to list routes of an existed Sandbox
 	listRoutes(ctx context.Context) ([]*pbTypes.Route, error)
 
+	// addARPNeighbors will tell the agent to add ARP neighbors to an existed Sandbox
+	addARPNeighbors(ctx context.Context, neighs []*pbTypes.ARPNeighbor) error
+
 	// getGuestDetails will tell the agent to get some information of guest
 	getGuestDetails(context.Context, *grpc.GuestDetailsRequest) (*grpc.GuestDetailsResponse, error)
 
@@ -175,6 +178,18 @@ type agent interface {
 	// copyFile copies file from host to container's rootfs
 	copyFile(ctx context.Context, src, dst string) error
 
+	// cloneDir creates the directory dst in container's rootfs, with the
+	// attributes of the host directory src and the content of the guest
+	// directory clone
+	cloneDir(ctx context.Context, src, dst, clone string) error
+
+	// removeFile removes the file or directory from container's rootfs
+	removeFile(ctx context.Context, path string) error
+
+	// writeKeyFile writes key material to a file only readable by root in
+	// the guest
+	writeKeyFile(ctx context.Context, dst string, key []byte) error
+
 	// Tell the agent to setup the swapfile in the guest
 	addSwap(ctx context.Context, PCIPath types.PciPath) error
 
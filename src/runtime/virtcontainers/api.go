@@ -137,6 +137,9 @@ func CleanupContainer(ctx context.Context, sandboxID, containerID string, force
 
 	s, err := fetchSandbox(ctx, sandboxID)
 	if err != nil {
+		if force {
+			cleanupVFIOHostDrivers(sandboxID)
+		}
 		return err
 	}
 	defer s.Release(ctx)
@@ -160,8 +163,19 @@ func CleanupContainer(ctx context.Context, sandboxID, containerID string, force
 	}
 
 	if err = s.Delete(ctx); err != nil {
+		if force {
+			cleanupVFIOHostDrivers(sandboxID)
+		}
 		return err
 	}
 
 	return nil
 }
+
+// cleanupVFIOHostDrivers recovers the VFIO devices of a sandbox which could
+// not be cleaned up, on a best-effort basis.
+func cleanupVFIOHostDrivers(sandboxID string) {
+	if err := restoreVFIOHostDrivers(sandboxID); err != nil {
+		virtLog.WithError(err).WithField("sandbox", sandboxID).Warn("failed to bind VFIO devices back to their host drivers")
+	}
+}
@@ -0,0 +1,272 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
+	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/types"
+	"github.com/mdlayher/vsock"
+	"golang.org/x/sys/unix"
+)
+
+// charDeviceVsockPortBase is the first guest vsock port the host character
+// devices are relayed on, above the ports of the agent.
+const charDeviceVsockPortBase uint32 = 0x10000
+
+var (
+	// charDeviceVsockDialTimeout is how long the relay waits for the agent
+	// to listen on the vsock port, which it does once the container using
+	// the device is created.
+	charDeviceVsockDialTimeout = time.Minute
+
+	charDeviceVsockDialInterval = 100 * time.Millisecond
+
+	charDeviceVsockDial = func(contextID, port uint32) (net.Conn, error) {
+		return vsock.Dial(contextID, port, nil)
+	}
+)
+
+// charDeviceRelay relays a host character device to the socket the
+// hypervisor exposes a virtio-serial port on, or to the vsock port the agent
+// listens on, so that the guest reads and writes the host device through
+// the port.
+type charDeviceRelay struct {
+	drive  *config.CharDrive
+	device *os.File
+	conn   net.Conn
+	done   chan struct{}
+	lock   sync.Mutex
+	once   sync.Once
+}
+
+// startCharDeviceRelay opens the host character device and connects it to
+// the port of the drive. The relay stops when either side is closed.
+func startCharDeviceRelay(drive *config.CharDrive) (*charDeviceRelay, error) {
+	device, err := os.OpenFile(drive.File, os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open character device %s: %w", drive.File, err)
+	}
+
+	if err := makeRawTerminal(device); err != nil {
+		device.Close()
+		return nil, fmt.Errorf("failed to set character device %s raw: %w", drive.File, err)
+	}
+
+	r := &charDeviceRelay{
+		drive:  drive,
+		device: device,
+		done:   make(chan struct{}),
+	}
+
+	if drive.VsockPort != 0 {
+		go func() {
+			conn, err := dialCharDeviceVsock(r.done, drive.VsockContextID, drive.VsockPort)
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					virtLog.WithError(err).WithField("device", drive.File).Warn("Character device relay failed")
+				}
+				r.close()
+				return
+			}
+			r.relay(conn)
+		}()
+		return r, nil
+	}
+
+	conn, err := net.Dial("unix", drive.SocketPath)
+	if err != nil {
+		device.Close()
+		return nil, fmt.Errorf("failed to connect to virtio-serial port socket %s: %w", drive.SocketPath, err)
+	}
+	r.relay(conn)
+
+	return r, nil
+}
+
+// dialCharDeviceVsock connects to the vsock port the agent relays a
+// character device on, until the agent listens on it or the relay stops.
+func dialCharDeviceVsock(done <-chan struct{}, contextID, port uint32) (net.Conn, error) {
+	deadline := time.Now().Add(charDeviceVsockDialTimeout)
+	for {
+		conn, err := charDeviceVsockDial(contextID, port)
+		if err == nil {
+			return conn, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("failed to connect to vsock port %d of VM %d: %w", port, contextID, err)
+		}
+
+		select {
+		case <-done:
+			return nil, net.ErrClosed
+		case <-time.After(charDeviceVsockDialInterval):
+		}
+	}
+}
+
+func (r *charDeviceRelay) relay(conn net.Conn) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	select {
+	case <-r.done:
+		conn.Close()
+		return
+	default:
+	}
+
+	r.conn = conn
+	go r.copy(conn, r.device)
+	go r.copy(r.device, conn)
+}
+
+func (r *charDeviceRelay) copy(dst io.Writer, src io.Reader) {
+	if _, err := io.Copy(dst, src); err != nil && !errors.Is(err, os.ErrClosed) && !errors.Is(err, net.ErrClosed) {
+		virtLog.WithError(err).WithField("device", r.device.Name()).Warn("Character device relay failed")
+	}
+	r.close()
+}
+
+func (r *charDeviceRelay) close() {
+	r.once.Do(func() {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+
+		close(r.done)
+		if r.conn != nil {
+			r.conn.Close()
+		}
+		r.device.Close()
+	})
+}
+
+// stop stops the relay. A device which cannot be polled is only released
+// once its pending read returns.
+func (r *charDeviceRelay) stop() {
+	r.close()
+}
+
+// makeRawTerminal passes the bytes of a host terminal, e.g. a serial adapter,
+// through untouched. Other character devices are left as they are.
+func makeRawTerminal(device *os.File) error {
+	rawConn, err := device.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var termiosErr error
+	if err := rawConn.Control(func(fd uintptr) {
+		termios, err := unix.IoctlGetTermios(int(fd), unix.TCGETS)
+		if err != nil {
+			// Not a terminal
+			return
+		}
+
+		termios.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+		termios.Oflag &^= unix.OPOST
+		termios.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+		termios.Cflag &^= unix.CSIZE | unix.PARENB
+		termios.Cflag |= unix.CS8
+		termios.Cc[unix.VMIN] = 1
+		termios.Cc[unix.VTIME] = 0
+
+		termiosErr = unix.IoctlSetTermios(int(fd), unix.TCSETS, termios)
+	}); err != nil {
+		return err
+	}
+
+	return termiosErr
+}
+
+// startCharDeviceRelay relays a character device hot plugged in the sandbox.
+// A drive relayed over vsock is given the first free vsock port.
+func (s *Sandbox) startCharDeviceRelay(drive *config.CharDrive) error {
+	s.charDeviceRelaysLock.Lock()
+	defer s.charDeviceRelaysLock.Unlock()
+
+	if _, ok := s.charDeviceRelays[drive.ID]; ok {
+		return nil
+	}
+
+	if drive.VsockContextID != 0 && drive.VsockPort == 0 {
+		drive.VsockPort = s.freeCharDeviceVsockPort()
+	}
+
+	relay, err := startCharDeviceRelay(drive)
+	if err != nil {
+		return err
+	}
+
+	if s.charDeviceRelays == nil {
+		s.charDeviceRelays = make(map[string]*charDeviceRelay)
+	}
+	s.charDeviceRelays[drive.ID] = relay
+
+	return nil
+}
+
+// freeCharDeviceVsockPort returns the first vsock port no character device
+// is relayed on. The caller holds charDeviceRelaysLock.
+func (s *Sandbox) freeCharDeviceVsockPort() uint32 {
+	used := make(map[uint32]bool)
+	for _, relay := range s.charDeviceRelays {
+		used[relay.drive.VsockPort] = true
+	}
+
+	port := charDeviceVsockPortBase
+	for used[port] {
+		port++
+	}
+	return port
+}
+
+// guestVsockContextID returns the context ID of the VM the agent is reached
+// on, the character devices relayed over vsock are relayed to it.
+func (s *Sandbox) guestVsockContextID() (uint32, error) {
+	agentURL, err := s.agent.getAgentURL()
+	if err != nil {
+		return 0, err
+	}
+
+	u, err := url.Parse(agentURL)
+	if err != nil {
+		return 0, err
+	}
+	if u.Scheme != types.VSockScheme {
+		return 0, fmt.Errorf("relaying character devices over vsock requires a vsock agent socket, not %s", agentURL)
+	}
+
+	contextID, err := strconv.ParseUint(u.Hostname(), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid vsock context ID in %s: %w", agentURL, err)
+	}
+	return uint32(contextID), nil
+}
+
+// stopCharDeviceRelay stops relaying a character device, all of them if id
+// is empty.
+func (s *Sandbox) stopCharDeviceRelay(id string) {
+	s.charDeviceRelaysLock.Lock()
+	defer s.charDeviceRelaysLock.Unlock()
+
+	for relayID, relay := range s.charDeviceRelays {
+		if id == "" || relayID == id {
+			relay.stop()
+			delete(s.charDeviceRelays, relayID)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"io"
+	"net"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/drivers"
+	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCharDeviceRelay(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+
+	// A FIFO opened read-write returns what is written to it, the relay
+	// thus echoes the port data back to the port.
+	devicePath := filepath.Join(dir, "ttyFAKE0")
+	assert.NoError(syscall.Mkfifo(devicePath, 0600))
+
+	socketPath := filepath.Join(dir, "char.sock")
+	listener, err := net.Listen("unix", socketPath)
+	assert.NoError(err)
+	defer listener.Close()
+
+	s := &Sandbox{id: "relay-sandbox"}
+	drive := &config.CharDrive{
+		File:       devicePath,
+		ID:         "char-0",
+		PortName:   "char-0",
+		SocketPath: socketPath,
+	}
+	assert.NoError(s.startCharDeviceRelay(drive))
+	assert.Len(s.charDeviceRelays, 1)
+
+	port, err := listener.Accept()
+	assert.NoError(err)
+	defer port.Close()
+
+	_, err = port.Write([]byte("AT\r\n"))
+	assert.NoError(err)
+
+	buf := make([]byte, 4)
+	assert.NoError(port.SetReadDeadline(time.Now().Add(5 * time.Second)))
+	_, err = io.ReadFull(port, buf)
+	assert.NoError(err)
+	assert.Equal("AT\r\n", string(buf))
+
+	// The port is closed once the relay stops
+	s.stopCharDeviceRelay(drive.ID)
+	assert.Empty(s.charDeviceRelays)
+	_, err = port.Read(buf)
+	assert.Equal(io.EOF, err)
+
+	// No socket to relay to
+	drive.SocketPath = filepath.Join(dir, "missing.sock")
+	assert.Error(s.startCharDeviceRelay(drive))
+
+	// No host device
+	drive.File = filepath.Join(dir, "missing")
+	assert.Error(s.startCharDeviceRelay(drive))
+	assert.Empty(s.charDeviceRelays)
+}
+
+// echoPort checks that the data written to a relayed port comes back from the
+// FIFO standing for the host device.
+func echoPort(assert *assert.Assertions, port net.Conn) {
+	_, err := port.Write([]byte("AT\r\n"))
+	assert.NoError(err)
+
+	buf := make([]byte, 4)
+	assert.NoError(port.SetReadDeadline(time.Now().Add(5 * time.Second)))
+	_, err = io.ReadFull(port, buf)
+	assert.NoError(err)
+	assert.Equal("AT\r\n", string(buf))
+}
+
+func TestCharDeviceVsockRelay(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	devicePath := filepath.Join(dir, "ttyFAKE0")
+	assert.NoError(syscall.Mkfifo(devicePath, 0600))
+
+	// The agent listens on the port once the container is created
+	socketPath := filepath.Join(dir, "vsock.sock")
+	var dialedPort uint32
+	savedDial := charDeviceVsockDial
+	charDeviceVsockDial = func(contextID, port uint32) (net.Conn, error) {
+		dialedPort = port
+		return net.Dial("unix", socketPath)
+	}
+	defer func() {
+		charDeviceVsockDial = savedDial
+	}()
+
+	s := &Sandbox{id: "relay-sandbox"}
+	drive := &config.CharDrive{
+		File:           devicePath,
+		ID:             "char-0",
+		VsockContextID: 3,
+	}
+	assert.NoError(s.startCharDeviceRelay(drive))
+	assert.Equal(charDeviceVsockPortBase, drive.VsockPort)
+
+	listener, err := net.Listen("unix", socketPath)
+	assert.NoError(err)
+	defer listener.Close()
+
+	port, err := listener.Accept()
+	assert.NoError(err)
+	defer port.Close()
+	echoPort(assert, port)
+	assert.Equal(charDeviceVsockPortBase, dialedPort)
+
+	// Each device has its own port
+	other := &config.CharDrive{
+		File:           devicePath,
+		ID:             "char-1",
+		VsockContextID: 3,
+	}
+	assert.NoError(s.startCharDeviceRelay(other))
+	assert.Equal(charDeviceVsockPortBase+1, other.VsockPort)
+	s.stopCharDeviceRelay(other.ID)
+
+	s.stopCharDeviceRelay(drive.ID)
+	assert.Empty(s.charDeviceRelays)
+	_, err = port.Read(make([]byte, 1))
+	assert.Equal(io.EOF, err)
+
+	// The relay stops if the agent never listens
+	savedTimeout := charDeviceVsockDialTimeout
+	charDeviceVsockDialTimeout = 0
+	defer func() {
+		charDeviceVsockDialTimeout = savedTimeout
+	}()
+	socketPath = filepath.Join(dir, "missing.sock")
+	relay, err := startCharDeviceRelay(drive)
+	assert.NoError(err)
+	select {
+	case <-relay.done:
+	case <-time.After(5 * time.Second):
+		assert.Fail("relay not stopped")
+	}
+}
+
+func TestCharDeviceHotplugHypervisor(t *testing.T) {
+	assert := assert.New(t)
+
+	device := &drivers.CharPassthroughDevice{
+		GenericDevice: &drivers.GenericDevice{ID: "char"},
+		CharDrive:     &config.CharDrive{ID: "char-0"},
+	}
+
+	// Only QEMU relays character devices
+	s := &Sandbox{
+		id:     "relay-sandbox",
+		config: &SandboxConfig{HypervisorType: ClhHypervisor},
+	}
+	err := s.HotplugAddDevice(context.Background(), device, config.DeviceCharPassthrough)
+	assert.ErrorContains(err, "not supported by hypervisor clh")
+
+	// Over vsock, the agent is reached on
+	s.config.HypervisorType = QemuHypervisor
+	s.config.HypervisorConfig.CharDeviceTransport = config.CharDeviceVsock
+	s.agent = &kataAgent{vmSocket: types.VSock{ContextID: 3, Port: 1024}}
+	contextID, err := s.guestVsockContextID()
+	assert.NoError(err)
+	assert.Equal(uint32(3), contextID)
+
+	s.agent = &kataAgent{vmSocket: types.HybridVSock{UdsPath: "/run/vc/kata.hvsock", Port: 1024}}
+	err = s.HotplugAddDevice(context.Background(), device, config.DeviceCharPassthrough)
+	assert.ErrorContains(err, "requires a vsock agent socket")
+}
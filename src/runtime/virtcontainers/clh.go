@@ -112,8 +112,12 @@ type clhClient interface {
 	VmAddDevicePut(ctx context.Context, deviceConfig chclient.DeviceConfig) (chclient.PciDeviceInfo, *http.Response, error)
 	// Add a new disk device to the VM
 	VmAddDiskPut(ctx context.Context, diskConfig chclient.DiskConfig) (chclient.PciDeviceInfo, *http.Response, error)
+	// Add a new vDPA device to the VM
+	VmAddVdpaPut(ctx context.Context, vdpaConfig chclient.VdpaConfig) (chclient.PciDeviceInfo, *http.Response, error)
 	// Remove a device from the VM
 	VmRemoveDevicePut(ctx context.Context, vmRemoveDevice chclient.VmRemoveDevice) (*http.Response, error)
+	// Resize a disk of the VM
+	VmResizeDiskPut(ctx context.Context, vmResizeDisk chclient.VmResizeDisk) (*http.Response, error)
 }
 
 type clhClientApi struct {
@@ -153,15 +157,23 @@ func (c *clhClientApi) VmAddDiskPut(ctx context.Context, diskConfig chclient.Dis
 	return c.ApiInternal.VmAddDiskPut(ctx).DiskConfig(diskConfig).Execute()
 }
 
+func (c *clhClientApi) VmAddVdpaPut(ctx context.Context, vdpaConfig chclient.VdpaConfig) (chclient.PciDeviceInfo, *http.Response, error) {
+	return c.ApiInternal.VmAddVdpaPut(ctx).VdpaConfig(vdpaConfig).Execute()
+}
+
 func (c *clhClientApi) VmRemoveDevicePut(ctx context.Context, vmRemoveDevice chclient.VmRemoveDevice) (*http.Response, error) {
 	return c.ApiInternal.VmRemoveDevicePut(ctx).VmRemoveDevice(vmRemoveDevice).Execute()
 }
 
+func (c *clhClientApi) VmResizeDiskPut(ctx context.Context, vmResizeDisk chclient.VmResizeDisk) (*http.Response, error) {
+	return c.ApiInternal.VmResizeDiskPut(ctx).VmResizeDisk(vmResizeDisk).Execute()
+}
+
 // This is done in order to be able to override such a function as part of
 // our unit tests, as when testing bootVM we're on a mocked scenario already.
-var vmAddNetPutRequest = func(clh *cloudHypervisor) ([]chclient.PciDeviceInfo, error) {
+var vmAddNetPutRequest = func(clh *cloudHypervisor, netDevices []chclient.NetConfig) ([]chclient.PciDeviceInfo, error) {
 	var netDevicesPciInfo []chclient.PciDeviceInfo
-	if clh.netDevices == nil {
+	if len(netDevices) == 0 {
 		clh.Logger().Info("No network device has been configured by the upper layer")
 		return nil, nil
 	}
@@ -177,7 +189,7 @@ var vmAddNetPutRequest = func(clh *cloudHypervisor) ([]chclient.PciDeviceInfo, e
 	}
 	defer conn.Close()
 
-	for _, netDevice := range *clh.netDevices {
+	for _, netDevice := range netDevices {
 		clh.Logger().Infof("Adding the net device to the Cloud Hypervisor VM configuration: %+v", netDevice)
 
 		netDeviceAsJson, err := json.Marshal(netDevice)
@@ -254,18 +266,21 @@ type CloudHypervisorState struct {
 	apiSocket         string
 	PID               int
 	VirtiofsDaemonPid int
+	SwtpmPid          int
 	state             clhState
 }
 
 func (s *CloudHypervisorState) reset() {
 	s.PID = 0
 	s.VirtiofsDaemonPid = 0
+	s.SwtpmPid = 0
 	s.state = clhNotReady
 }
 
 type cloudHypervisor struct {
 	console         console.Console
 	virtiofsDaemon  VirtiofsDaemon
+	swtpm           *swtpm
 	APIClient       clhClient
 	ctx             context.Context
 	id              string
@@ -344,33 +359,19 @@ func (clh *cloudHypervisor) createVirtiofsDaemon(sharedPath string) (VirtiofsDae
 		return nil, err
 	}
 
-	if clh.config.SharedFS == config.VirtioFSNydus {
-		apiSockPath, err := clh.nydusdAPISocketPath(clh.id)
-		if err != nil {
-			clh.Logger().WithError(err).Error("Invalid api socket path for nydusd")
-			return nil, err
-		}
-		nd := &nydusd{
-			path:        clh.config.VirtioFSDaemon,
-			sockPath:    virtiofsdSocketPath,
-			apiSockPath: apiSockPath,
-			sourcePath:  sharedPath,
-			debug:       clh.config.Debug,
-			extraArgs:   clh.config.VirtioFSExtraArgs,
-			startFn:     startInShimNS,
-		}
-		nd.setupShareDirFn = nd.setupPassthroughFS
-		return nd, nil
+	apiSockPath, err := clh.nydusdAPISocketPath(clh.id)
+	if err != nil {
+		clh.Logger().WithError(err).Error("Invalid api socket path for nydusd")
+		return nil, err
 	}
 
-	// default: use virtiofsd
-	return &virtiofsd{
-		path:       clh.config.VirtioFSDaemon,
-		sourcePath: sharedPath,
-		socketPath: virtiofsdSocketPath,
-		extraArgs:  clh.config.VirtioFSExtraArgs,
-		cache:      clh.config.VirtioFSCache,
-	}, nil
+	return newVirtiofsDaemon(VirtiofsDaemonParams{
+		Config:        &clh.config,
+		SandboxID:     clh.id,
+		SocketPath:    virtiofsdSocketPath,
+		APISocketPath: apiSockPath,
+		SharedPath:    sharedPath,
+	})
 }
 
 func (clh *cloudHypervisor) setupVirtiofsDaemon(ctx context.Context) error {
@@ -416,6 +417,37 @@ func (clh *cloudHypervisor) stopVirtiofsDaemon(ctx context.Context) (err error)
 	return nil
 }
 
+func (clh *cloudHypervisor) setupSwtpm(ctx context.Context) error {
+	if clh.swtpm == nil {
+		return errors.New("Missing swtpm configuration")
+	}
+
+	pid, err := clh.swtpm.Start(ctx, func() {
+		clh.StopVM(ctx, false)
+	})
+	if err != nil {
+		return err
+	}
+	clh.state.SwtpmPid = pid
+
+	return nil
+}
+
+func (clh *cloudHypervisor) stopSwtpm(ctx context.Context) error {
+	if clh.state.SwtpmPid == 0 || clh.swtpm == nil {
+		clh.Logger().Warn("The swtpm had stopped")
+		return nil
+	}
+
+	if err := clh.swtpm.Stop(ctx); err != nil {
+		return err
+	}
+
+	clh.state.SwtpmPid = 0
+
+	return nil
+}
+
 func (clh *cloudHypervisor) loadVirtiofsDaemon(sharedPath string) (VirtiofsDaemon, error) {
 	virtiofsdSocketPath, err := clh.virtioFsSocketPath(clh.id)
 	if err != nil {
@@ -529,6 +561,12 @@ func (clh *cloudHypervisor) CreateVM(ctx context.Context, id string, network Net
 		}
 		clh.virtiofsDaemon = virtiofsDaemon
 
+		if clh.config.EnableTPM {
+			if clh.swtpm, err = newSwtpm(&clh.config, clh.id, clh.state.SwtpmPid); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	}
 
@@ -608,6 +646,13 @@ func (clh *cloudHypervisor) CreateVM(ctx context.Context, id string, network Net
 	clh.vmconfig.Rng = chclient.NewRngConfig(clh.config.EntropySource)
 	clh.vmconfig.Rng.SetIommu(clh.config.IOMMU)
 
+	if clh.config.EnableTPM {
+		if clh.swtpm, err = newSwtpm(&clh.config, clh.id, 0); err != nil {
+			return err
+		}
+		clh.vmconfig.Tpm = chclient.NewTpmConfig(clh.swtpm.socketPath)
+	}
+
 	// set the initial root/boot disk of hypervisor
 	assetPath, assetType, err := clh.config.ImageOrInitrdAssetPath()
 	if err != nil {
@@ -752,13 +797,29 @@ func (clh *cloudHypervisor) StartVM(ctx context.Context, timeout int) error {
 			return
 		}
 
-		if clh.config.SharedFS == config.VirtioFS || clh.config.SharedFS == config.VirtioFSNydus {
+		if IsVirtioFS(clh.config.SharedFS) {
 			if shutdownErr := clh.stopVirtiofsDaemon(ctx); shutdownErr != nil {
 				clh.Logger().WithError(shutdownErr).Warn("error shutting down VirtiofsDaemon")
 			}
 		}
 	}()
 
+	if clh.config.EnableTPM {
+		err = clh.setupSwtpm(ctx)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err == nil {
+				return
+			}
+
+			if shutdownErr := clh.stopSwtpm(ctx); shutdownErr != nil {
+				clh.Logger().WithError(shutdownErr).Warn("error shutting down swtpm")
+			}
+		}()
+	}
+
 	err = clh.launchClh()
 	if err != nil {
 		return fmt.Errorf("failed to launch cloud-hypervisor: %q", err)
@@ -1052,7 +1113,54 @@ func (clh *cloudHypervisor) hotPlugVFIODevice(device *config.VFIODev) error {
 	return err
 }
 
+// vdpaConfig returns the Cloud Hypervisor configuration of a vdpa endpoint,
+// the vdpa device name being used as the device identifier.
+func (clh *cloudHypervisor) vdpaConfig(e *VdpaEndpoint) chclient.VdpaConfig {
+	vdpa := *chclient.NewVdpaConfig(e.VhostVdpaPath, int32(e.NumQueues))
+	vdpa.SetId(e.VdpaDevName)
+	vdpa.SetIommu(clh.config.IOMMU)
+
+	return vdpa
+}
+
+// coldPlugVdpaDevice appends a vdpa device to the VM configuration.
+func (clh *cloudHypervisor) coldPlugVdpaDevice(e *VdpaEndpoint) {
+	vdpa := clh.vdpaConfig(e)
+
+	if clh.vmconfig.Vdpa != nil {
+		*clh.vmconfig.Vdpa = append(*clh.vmconfig.Vdpa, vdpa)
+	} else {
+		clh.vmconfig.Vdpa = &[]chclient.VdpaConfig{vdpa}
+	}
+
+	clh.devicesIds[e.VdpaDevName] = e.VdpaDevName
+}
+
+func (clh *cloudHypervisor) hotPlugVdpaDevice(e *VdpaEndpoint) error {
+	cl := clh.client()
+	ctx, cancel := context.WithTimeout(context.Background(), clhHotPlugAPITimeout*time.Second)
+	defer cancel()
+
+	pciInfo, _, err := cl.VmAddVdpaPut(ctx, clh.vdpaConfig(e))
+	if err != nil {
+		return fmt.Errorf("Failed to hotplug vdpa device %s %s", e.VdpaDevName, openAPIClientError(err))
+	}
+	clh.devicesIds[e.VdpaDevName] = pciInfo.GetId()
+
+	pciPath, err := clhPciInfoToPath(pciInfo)
+	if err != nil {
+		return err
+	}
+	e.SetPciPath(pciPath)
+
+	return nil
+}
+
 func (clh *cloudHypervisor) hotplugAddNetDevice(e Endpoint) error {
+	if vdpa, ok := e.(*VdpaEndpoint); ok {
+		return clh.hotPlugVdpaDevice(vdpa)
+	}
+
 	err := clh.addNet(e)
 	if err != nil {
 		return err
@@ -1113,6 +1221,19 @@ func (clh *cloudHypervisor) HotplugRemoveDevice(ctx context.Context, devInfo int
 		deviceID = clhDriveIndexToID(devInfo.(*config.BlockDrive).Index)
 	case VfioDev:
 		deviceID = devInfo.(*config.VFIODev).ID
+	case NetDev:
+		// Only vdpa devices can be hot removed, as the other network
+		// devices are not tracked.
+		switch endpoint := devInfo.(type) {
+		case *VdpaEndpoint:
+			deviceID = endpoint.VdpaDevName
+		case *pluginEndpoint:
+			// The plugin endpoints are hot added through their tap, which
+			// Cloud Hypervisor does not give an ID to remove it with.
+			return nil, fmt.Errorf("Could not hot remove network device: %s endpoints cannot be hot removed from %s", endpoint.Type(), ClhHypervisor)
+		default:
+			return nil, fmt.Errorf("Could not hot remove network device: unsupported endpoint: %v", devInfo)
+		}
 	default:
 		clh.Logger().WithFields(log.Fields{"devInfo": devInfo,
 			"deviceType": devType}).Error("HotplugRemoveDevice: unsupported device")
@@ -1136,6 +1257,47 @@ func (clh *cloudHypervisor) HotplugRemoveDevice(ctx context.Context, devInfo int
 	return nil, err
 }
 
+// IsDevicePlugged reports whether the device is plugged in the VM, from the
+// disks and the devices of the VM configuration, matched by their host path
+// as the IDs cloud-hypervisor gives the hot plugged devices are not kept
+// across the runtime restarts. The ID of a plugged device is tracked again,
+// for the device to be hot unplugged.
+func (clh *cloudHypervisor) IsDevicePlugged(ctx context.Context, devInfo interface{}, devType DeviceType) (bool, error) {
+	span, _ := katatrace.Trace(ctx, clh.Logger(), "IsDevicePlugged", clhTracingTags, map[string]string{"sandbox_id": clh.id})
+	defer span.End()
+
+	if devType != BlockDev && devType != VfioDev {
+		return false, fmt.Errorf("cannot query device: unsupported device type '%v'", devType)
+	}
+
+	info, err := clh.vmInfo()
+	if err != nil {
+		return false, err
+	}
+	vmConfig := info.GetConfig()
+
+	switch devType {
+	case BlockDev:
+		drive := devInfo.(*config.BlockDrive)
+		for _, disk := range vmConfig.GetDisks() {
+			if disk.GetPath() == drive.File {
+				clh.devicesIds[clhDriveIndexToID(drive.Index)] = disk.GetId()
+				return true, nil
+			}
+		}
+	case VfioDev:
+		device := devInfo.(*config.VFIODev)
+		for _, dev := range vmConfig.GetDevices() {
+			if dev.GetPath() == device.SysfsDev {
+				clh.devicesIds[device.ID] = dev.GetId()
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
 func (clh *cloudHypervisor) HypervisorConfig() HypervisorConfig {
 	return clh.config
 }
@@ -1332,6 +1494,7 @@ func (clh *cloudHypervisor) Save() (s hv.HypervisorState) {
 	s.Pid = clh.state.PID
 	s.Type = string(ClhHypervisor)
 	s.VirtiofsDaemonPid = clh.state.VirtiofsDaemonPid
+	s.SwtpmPid = clh.state.SwtpmPid
 	s.APISocket = clh.state.apiSocket
 	return
 }
@@ -1339,6 +1502,7 @@ func (clh *cloudHypervisor) Save() (s hv.HypervisorState) {
 func (clh *cloudHypervisor) Load(s hv.HypervisorState) {
 	clh.state.PID = s.Pid
 	clh.state.VirtiofsDaemonPid = s.VirtiofsDaemonPid
+	clh.state.SwtpmPid = s.SwtpmPid
 	clh.state.apiSocket = s.APISocket
 }
 
@@ -1358,7 +1522,12 @@ func (clh *cloudHypervisor) Check() error {
 }
 
 func (clh *cloudHypervisor) GetPids() []int {
-	return []int{clh.state.PID}
+	pids := []int{clh.state.PID}
+	if clh.state.SwtpmPid != 0 {
+		pids = append(pids, clh.state.SwtpmPid)
+	}
+
+	return pids
 }
 
 func (clh *cloudHypervisor) GetVirtioFsPid() *int {
@@ -1372,6 +1541,8 @@ func (clh *cloudHypervisor) AddDevice(ctx context.Context, devInfo interface{},
 	var err error
 
 	switch v := devInfo.(type) {
+	case *VdpaEndpoint:
+		clh.coldPlugVdpaDevice(v)
 	case Endpoint:
 		if err := clh.addNet(v); err != nil {
 			return err
@@ -1446,7 +1617,7 @@ func (clh *cloudHypervisor) terminate(ctx context.Context, waitOnly bool) (err e
 		return err
 	}
 
-	if clh.config.SharedFS == config.VirtioFS || clh.config.SharedFS == config.VirtioFSNydus {
+	if IsVirtioFS(clh.config.SharedFS) {
 		clh.Logger().Debug("stop virtiofsDaemon")
 
 		if err = clh.stopVirtiofsDaemon(ctx); err != nil {
@@ -1454,6 +1625,14 @@ func (clh *cloudHypervisor) terminate(ctx context.Context, waitOnly bool) (err e
 		}
 	}
 
+	if clh.config.EnableTPM {
+		clh.Logger().Debug("stop swtpm")
+
+		if err = clh.stopSwtpm(ctx); err != nil {
+			clh.Logger().WithError(err).Error("failed to stop swtpm")
+		}
+	}
+
 	return
 }
 
@@ -1686,7 +1865,10 @@ func openAPIClientError(err error) error {
 }
 
 func (clh *cloudHypervisor) vmAddNetPut() ([]chclient.PciDeviceInfo, error) {
-	return vmAddNetPutRequest(clh)
+	if clh.netDevices == nil {
+		return vmAddNetPutRequest(clh, nil)
+	}
+	return vmAddNetPutRequest(clh, *clh.netDevices)
 }
 
 func (clh *cloudHypervisor) bootVM(ctx context.Context) error {
@@ -1833,7 +2015,7 @@ func (clh *cloudHypervisor) addNet(e Endpoint) error {
 
 // Add shared Volume using virtiofs
 func (clh *cloudHypervisor) addVolume(volume types.Volume) error {
-	if clh.config.SharedFS != config.VirtioFS && clh.config.SharedFS != config.VirtioFSNydus {
+	if !IsVirtioFS(clh.config.SharedFS) {
 		return fmt.Errorf("shared fs method not supported %s", clh.config.SharedFS)
 	}
 
@@ -1974,8 +2156,40 @@ func (clh *cloudHypervisor) vmInfo() (chclient.VmInfo, error) {
 	return info, openAPIClientError(err)
 }
 
+// IsRateLimiterBuiltin returns false as the rate limiter of a Cloud Hypervisor
+// network device is shared by both directions and can't be changed without
+// unplugging the device. The endpoint bandwidth is enforced with tc, on top
+// of the net_rate_limiter_* limits of the configuration.
 func (clh *cloudHypervisor) IsRateLimiterBuiltin() bool {
-	return true
+	return false
+}
+
+func (clh *cloudHypervisor) UpdateNetRateLimiter(ctx context.Context, endpoint Endpoint, bandwidth NetworkBandwidth) error {
+	return fmt.Errorf("Cloud Hypervisor does not support updating built-in network rate limiters")
+}
+
+// ResizeBlockDevice resizes a hotplugged disk, cloud-hypervisor then notifies
+// the guest of the new capacity.
+func (clh *cloudHypervisor) ResizeBlockDevice(ctx context.Context, drive *config.BlockDrive, size uint64) error {
+	span, _ := katatrace.Trace(ctx, clh.Logger(), "ResizeBlockDevice", clhTracingTags, map[string]string{"sandbox_id": clh.id})
+	defer span.End()
+
+	diskID, ok := clh.devicesIds[clhDriveIndexToID(drive.Index)]
+	if !ok {
+		return fmt.Errorf("Cannot find the disk of drive %s", drive.ID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), clh.getClhAPITimeout()*time.Second)
+	defer cancel()
+
+	resize := *chclient.NewVmResizeDisk()
+	resize.SetId(diskID)
+	resize.SetDesiredSize(int64(size))
+	if _, err := clh.client().VmResizeDiskPut(ctx, resize); err != nil {
+		return fmt.Errorf("failed to resize block device %s %s", drive.ID, openAPIClientError(err))
+	}
+
+	return nil
 }
 
 func pathExists(path string) bool {
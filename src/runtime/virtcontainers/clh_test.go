@@ -115,11 +115,21 @@ func (c *clhClientMock) VmAddDiskPut(ctx context.Context, diskConfig chclient.Di
 	return chclient.PciDeviceInfo{Bdf: "0000:00:0a.0"}, nil, nil
 }
 
+//nolint:golint
+func (c *clhClientMock) VmAddVdpaPut(ctx context.Context, vdpaConfig chclient.VdpaConfig) (chclient.PciDeviceInfo, *http.Response, error) {
+	return chclient.PciDeviceInfo{Id: vdpaConfig.GetId(), Bdf: "0000:00:0b.0"}, nil, nil
+}
+
 //nolint:golint
 func (c *clhClientMock) VmRemoveDevicePut(ctx context.Context, vmRemoveDevice chclient.VmRemoveDevice) (*http.Response, error) {
 	return nil, nil
 }
 
+//nolint:golint
+func (c *clhClientMock) VmResizeDiskPut(ctx context.Context, vmResizeDisk chclient.VmResizeDisk) (*http.Response, error) {
+	return nil, nil
+}
+
 func TestCloudHypervisorAddVSock(t *testing.T) {
 	assert := assert.New(t)
 	clh := cloudHypervisor{}
@@ -398,7 +408,9 @@ func TestCloudHypervisorBootVM(t *testing.T) {
 	clh.APIClient = &clhClientMock{}
 
 	savedVmAddNetPutRequestFunc := vmAddNetPutRequest
-	vmAddNetPutRequest = func(clh *cloudHypervisor) ([]chclient.PciDeviceInfo, error) { return nil, nil }
+	vmAddNetPutRequest = func(clh *cloudHypervisor, netDevices []chclient.NetConfig) ([]chclient.PciDeviceInfo, error) {
+		return nil, nil
+	}
 	defer func() {
 		vmAddNetPutRequest = savedVmAddNetPutRequestFunc
 	}()
@@ -527,7 +539,9 @@ func TestCloudHypervisorStartSandbox(t *testing.T) {
 	assert.NoError(err)
 
 	savedVmAddNetPutRequestFunc := vmAddNetPutRequest
-	vmAddNetPutRequest = func(clh *cloudHypervisor) ([]chclient.PciDeviceInfo, error) { return nil, nil }
+	vmAddNetPutRequest = func(clh *cloudHypervisor, netDevices []chclient.NetConfig) ([]chclient.PciDeviceInfo, error) {
+		return nil, nil
+	}
 	defer func() {
 		vmAddNetPutRequest = savedVmAddNetPutRequestFunc
 	}()
@@ -680,6 +694,119 @@ func TestCloudHypervisorHotplugRemoveDevice(t *testing.T) {
 
 	_, err = clh.HotplugRemoveDevice(context.Background(), nil, NetDev)
 	assert.Error(err, "Hotplug remove pmem block device expected error")
+
+	_, err = clh.HotplugRemoveDevice(context.Background(), &pluginEndpoint{&testPluginEndpoint{}}, NetDev)
+	assert.ErrorContains(err, "test-plugin endpoints cannot be hot removed from clh")
+}
+
+func TestCloudHypervisorIsDevicePlugged(t *testing.T) {
+	assert := assert.New(t)
+
+	clhConfig, err := newClhConfig()
+	assert.NoError(err)
+
+	disk := *chclient.NewDiskConfig()
+	disk.SetPath("/dev/sdb")
+	disk.SetId("_disk3")
+	device := *chclient.NewDeviceConfig("/sys/bus/pci/devices/0000:01:00.0")
+	device.SetId("_device4")
+
+	clh := &cloudHypervisor{}
+	clh.config = clhConfig
+	clh.APIClient = &clhClientMock{
+		vmInfo: chclient.VmInfo{
+			Config: chclient.VmConfig{
+				Disks:   &[]chclient.DiskConfig{disk},
+				Devices: &[]chclient.DeviceConfig{device},
+			},
+		},
+	}
+	clh.devicesIds = make(map[string]string)
+
+	// The plugged devices are tracked again for their removal
+	plugged, err := clh.IsDevicePlugged(context.Background(), &config.BlockDrive{File: "/dev/sdb", Index: 1}, BlockDev)
+	assert.NoError(err)
+	assert.True(plugged)
+	assert.Equal("_disk3", clh.devicesIds[clhDriveIndexToID(1)])
+
+	plugged, err = clh.IsDevicePlugged(context.Background(), &config.VFIODev{ID: "vfio-1", SysfsDev: "/sys/bus/pci/devices/0000:01:00.0"}, VfioDev)
+	assert.NoError(err)
+	assert.True(plugged)
+	assert.Equal("_device4", clh.devicesIds["vfio-1"])
+
+	plugged, err = clh.IsDevicePlugged(context.Background(), &config.BlockDrive{File: "/dev/sdc", Index: 2}, BlockDev)
+	assert.NoError(err)
+	assert.False(plugged)
+
+	_, err = clh.IsDevicePlugged(context.Background(), &config.CharDrive{}, VirtioSerialPortDev)
+	assert.Error(err)
+}
+
+func TestCloudHypervisorResizeBlockDevice(t *testing.T) {
+	assert := assert.New(t)
+
+	clhConfig, err := newClhConfig()
+	assert.NoError(err)
+
+	clh := &cloudHypervisor{}
+	clh.config = clhConfig
+	clh.APIClient = &clhClientMock{}
+	clh.devicesIds = make(map[string]string)
+
+	drive := &config.BlockDrive{ID: "drive-1", Index: 1}
+
+	// The drive has not been hotplugged
+	err = clh.ResizeBlockDevice(context.Background(), drive, 1<<30)
+	assert.Error(err)
+
+	clh.devicesIds[clhDriveIndexToID(drive.Index)] = "_disk3"
+	err = clh.ResizeBlockDevice(context.Background(), drive, 1<<30)
+	assert.NoError(err)
+}
+
+func TestCloudHypervisorVdpaDevice(t *testing.T) {
+	assert := assert.New(t)
+
+	clhConfig, err := newClhConfig()
+	assert.NoError(err)
+
+	clh := &cloudHypervisor{}
+	clh.config = clhConfig
+	clh.APIClient = &clhClientMock{}
+	clh.devicesIds = make(map[string]string)
+	clh.vmconfig = *chclient.NewVmConfig(*chclient.NewPayloadConfig())
+
+	ep := &VdpaEndpoint{
+		VdpaDevName:   "vdpa0",
+		VhostVdpaPath: "/dev/vhost-vdpa-0",
+		NumQueues:     2,
+		EndpointType:  VdpaEndpointType,
+	}
+
+	err = clh.AddDevice(context.Background(), ep, NetDev)
+	assert.NoError(err)
+	assert.NotNil(clh.vmconfig.Vdpa)
+	assert.Len(*clh.vmconfig.Vdpa, 1)
+	assert.Equal("/dev/vhost-vdpa-0", (*clh.vmconfig.Vdpa)[0].Path)
+	assert.Equal(int32(2), (*clh.vmconfig.Vdpa)[0].NumQueues)
+	assert.Equal("vdpa0", (*clh.vmconfig.Vdpa)[0].GetId())
+	assert.Nil(clh.netDevices)
+
+	ep = &VdpaEndpoint{
+		VdpaDevName:   "vdpa1",
+		VhostVdpaPath: "/dev/vhost-vdpa-1",
+		NumQueues:     2,
+		EndpointType:  VdpaEndpointType,
+	}
+
+	_, err = clh.HotplugAddDevice(context.Background(), ep, NetDev)
+	assert.NoError(err)
+	assert.Equal("vdpa1", clh.devicesIds["vdpa1"])
+	assert.Equal("0b", ep.PciPath().String())
+
+	_, err = clh.HotplugRemoveDevice(context.Background(), ep, NetDev)
+	assert.NoError(err)
+	assert.NotContains(clh.devicesIds, "vdpa1")
 }
 
 func TestCloudHypervisorColdPlugVFIODevice(t *testing.T) {
@@ -308,6 +308,10 @@ type ContainerDevice struct {
 
 	// Shared indicates whether the device is shared across containers.
 	Shared bool
+
+	// ErofsLayer indicates whether the device is a layer blob of the erofs
+	// layer cache, released from the cache once detached.
+	ErofsLayer bool
 }
 
 // EphemeralDisk holds information about an ephemeral disk created for
@@ -615,6 +619,17 @@ func filterDevices(c *Container, devices []ContainerDevice) (ret []ContainerDevi
 	return
 }
 
+// isPmemMount reports whether the mount is a file attached as a NVDIMM,
+// either through the mount option or the direct assigned volume type.
+func isPmemMount(m Mount) bool {
+	if HasOption(m.Options, vcAnnotations.IsFilePmemDevice) {
+		return true
+	}
+
+	mntInfo, err := volume.VolumeMountInfo(m.Source)
+	return err == nil && mntInfo.VolumeType == volume.PmemVolumeType
+}
+
 // Add any mount based block devices to the device manager and Save the
 // device ID for the particular mount. This'll occur when the mountpoint source
 // is a block device.
@@ -623,8 +638,9 @@ func (c *Container) createBlockDevices(ctx context.Context) error {
 	for i := range c.mounts {
 		// If block devices are disabled, we selectively only hotplug if
 		// the mount is an encrypted block-based emptyDir, to avoid
-		// cases that could regress 20ca4d2.
-		if !c.checkBlockDeviceSupport(ctx) && (c.sandbox.config.EmptyDirMode != EmptyDirModeVirtioBlkEncrypted || !Isk8sHostEmptyDir(c.mounts[i].Source)) {
+		// cases that could regress 20ca4d2. The pmem volumes are not
+		// block devices, and can't be shared without losing DAX.
+		if !c.checkBlockDeviceSupport(ctx) && !isPmemMount(c.mounts[i]) && (c.sandbox.config.EmptyDirMode != EmptyDirModeVirtioBlkEncrypted || !Isk8sHostEmptyDir(c.mounts[i].Source)) {
 			c.Logger().Warn("Block device not supported")
 			continue
 		}
@@ -637,7 +653,8 @@ func (c *Container) createBlockDevices(ctx context.Context) error {
 		}
 
 		isBlockFile := HasOption(c.mounts[i].Options, vcAnnotations.IsFileBlockDevice)
-		if c.mounts[i].Type != "bind" && !isBlockFile {
+		isPmemFile := HasOption(c.mounts[i].Options, vcAnnotations.IsFilePmemDevice)
+		if c.mounts[i].Type != "bind" && !isBlockFile && !isPmemFile {
 			// We only handle for bind and block device mounts.
 			continue
 		}
@@ -661,6 +678,7 @@ func (c *Container) createBlockDevices(ctx context.Context) error {
 			if err == nil && fileInfo.Mode().IsRegular() {
 				isBlockFile = true
 			}
+			isPmemFile = mntInfo.VolumeType == volume.PmemVolumeType
 
 			readonly := false
 			for _, flag := range mntInfo.Options {
@@ -698,11 +716,29 @@ func (c *Container) createBlockDevices(ctx context.Context) error {
 			}
 		}
 
-		// Check if mount is a block device file. If it is, the block device will be attached to the host
-		// instead of passing this as a shared mount.
-		di, err := c.createDeviceInfo(c.mounts[i].Source, c.mounts[i].Destination, c.mounts[i].ReadOnly, isBlockFile)
+		var di *config.DeviceInfo
+		var err error
+		if isPmemFile {
+			// The file is attached as a NVDIMM, its filesystem is mounted with DAX.
+			fstype := c.mounts[i].Type
+			if fstype == "bind" {
+				fstype = ""
+			}
+			// Mounting it as a shared directory instead would lose DAX,
+			// hence a file which cannot be attached is an error.
+			if di, err = config.PmemFileDeviceInfo(c.mounts[i].Source, c.mounts[i].Destination, fstype, c.mounts[i].ReadOnly); err != nil {
+				return fmt.Errorf("failed to attach pmem volume %s: %w", c.mounts[i].Source, err)
+			}
+		} else {
+			// Check if mount is a block device file. If it is, the block device will be attached to the host
+			// instead of passing this as a shared mount.
+			di, err = c.createDeviceInfo(c.mounts[i].Source, c.mounts[i].Destination, c.mounts[i].ReadOnly, isBlockFile)
+		}
 		if err == nil && di != nil {
 			b, err := c.sandbox.devManager.NewDevice(*di)
+			if err != nil && isPmemFile {
+				return fmt.Errorf("failed to attach pmem volume %s: %w", c.mounts[i].Source, err)
+			}
 			if err != nil {
 				// Do not return an error, try to create
 				// devices for other mounts
@@ -832,7 +868,7 @@ func (c *Container) createDeviceInfo(source, destination string, readonly, isBlo
 			ReadOnly:      readonly,
 		}
 		// Check whether source can be used as a pmem device
-	} else if di, err = config.PmemDeviceInfo(source, destination); err != nil {
+	} else if di, err = config.PmemDeviceInfo(c.sandbox.config.HypervisorConfig.HostSysfs(), source, destination); err != nil {
 		c.Logger().WithError(err).
 			WithField("mount-source", source).
 			Debug("no loop device")
@@ -981,32 +1017,44 @@ func parseOverlayUpperFs(mounts []*mountinfo.Info, path string) (*mountinfo.Info
 	return nil, fmt.Errorf("upper mount not found for %s", path)
 }
 
-func (c *Container) createErofsDevices(ctx context.Context) ([]config.DeviceInfo, error) {
+// createErofsDevices returns the devices of the erofs rootfs, and the host
+// paths of the layer blobs acquired from the erofs layer cache.
+func (c *Container) createErofsDevices(ctx context.Context) ([]config.DeviceInfo, map[string]bool, error) {
 	var deviceInfos []config.DeviceInfo
+	cachedLayers := make(map[string]bool)
 	if IsErofsRootFS(c.rootFs) {
 		mounts, err := mountinfo.GetMounts(nil)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		lowerdirs, upperdir := parseErofsRootFsOptions(c.rootFs.Options)
 		for _, path := range lowerdirs {
 			s, err := findErofsMountSource(mounts, path)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			if strings.HasPrefix(s, "/dev/loop") {
 				b, err := os.ReadFile(fmt.Sprintf("/sys/block/loop%s/loop/backing_file", strings.TrimPrefix(s, "/dev/loop")))
 				if err != nil {
-					return nil, err
+					return nil, nil, err
 				}
 				s = strings.TrimSuffix(string(b), "\n")
 			}
 			if filepath.Base(s) != "layer.erofs" {
-				return nil, fmt.Errorf("unsupported mount source %s for %s", s, path)
+				return nil, nil, fmt.Errorf("unsupported mount source %s for %s", s, path)
+			}
+			// Attach the blob cached for the layer, so that the device
+			// manager shares its device with the containers having the
+			// same layer.
+			if c.sandbox.config.ErofsLayerCache {
+				if s, err = c.sandbox.erofsLayers().acquire(c.sandbox.id, s); err != nil {
+					return nil, nil, err
+				}
+				cachedLayers[s] = true
 			}
 			di, err := c.createDeviceInfo(s, s, true, true)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			deviceInfos = append(deviceInfos, *di)
 		}
@@ -1018,29 +1066,29 @@ func (c *Container) createErofsDevices(ctx context.Context) ([]config.DeviceInfo
 				if strings.HasPrefix(s, "/dev/loop") {
 					b, err := os.ReadFile(fmt.Sprintf("/sys/block/loop%s/loop/backing_file", strings.TrimPrefix(s, "/dev/loop")))
 					if err != nil {
-						return nil, err
+						return nil, nil, err
 					}
 					s = strings.TrimSuffix(string(b), "\n")
 				}
 				if filepath.Base(s) != "rwlayer.img" {
-					return nil, fmt.Errorf("unsupported upper blockfile %s for %s", s, upperdir)
+					return nil, nil, fmt.Errorf("unsupported upper blockfile %s for %s", s, upperdir)
 				}
 				// XXX: we cannot umount here because it's in another mntns,
 				//      therefore remountRo for safety; should adapt containerd
 				//      custom mount type instead.
 				if err := remount(ctx, syscall.MS_RDONLY, m.Mountpoint); err != nil {
-					return nil, fmt.Errorf("failed to unmount rwlayer %s", m.Mountpoint)
+					return nil, nil, fmt.Errorf("failed to unmount rwlayer %s", m.Mountpoint)
 				}
 
 				di, err := c.createDeviceInfo(s, s, false, true)
 				if err != nil {
-					return nil, err
+					return nil, nil, err
 				}
 				deviceInfos = append(deviceInfos, *di)
 			}
 		}
 	}
-	return deviceInfos, nil
+	return deviceInfos, cachedLayers, nil
 }
 
 func (c *Container) createDevices(ctx context.Context, contConfig *ContainerConfig) error {
@@ -1053,7 +1101,20 @@ func (c *Container) createDevices(ctx context.Context, contConfig *ContainerConf
 	}
 	deviceInfos := append(virtualVolumesDeviceInfos, contConfig.DeviceInfos...)
 
-	erofsDeviceInfos, err := c.createErofsDevices(ctx)
+	// Pass the SCSI devices of the container through to the guest SCSI bus,
+	// and relay its character devices through virtio-serial ports. The
+	// virtual volumes remain emulated block devices.
+	hypervisorConfig := c.sandbox.config.HypervisorConfig
+	sysfs := hypervisorConfig.HostSysfs()
+	for i := len(virtualVolumesDeviceInfos); i < len(deviceInfos); i++ {
+		if hypervisorConfig.SCSIPassthrough && deviceManager.IsSCSIDevice(sysfs, deviceInfos[i]) {
+			deviceInfos[i].SCSIPassthrough = true
+		} else if deviceManager.IsCharPassthroughDevice(sysfs, deviceInfos[i], hypervisorConfig.CharDevicePassthrough) {
+			deviceInfos[i].CharPassthrough = true
+		}
+	}
+
+	erofsDeviceInfos, cachedLayers, err := c.createErofsDevices(ctx)
 	if err != nil {
 		return err
 	}
@@ -1130,6 +1191,7 @@ func (c *Container) createDevices(ctx context.Context, contConfig *ContainerConf
 			FileMode:      info.FileMode,
 			UID:           info.UID,
 			GID:           info.GID,
+			ErofsLayer:    cachedLayers[info.HostPath],
 		})
 	}
 	c.devices = filterDevices(c, storedDevices)
@@ -1342,38 +1404,39 @@ func (c *Container) siblingAnnotation(devPath string, siblings []DeviceRelation)
 	// 2. Reuse the BDFs for sibling matching without redundant sysfs reads
 	isKnownCDIDevice := false
 	var devBDFs []string
+	sysfs := c.sandbox.config.HypervisorConfig.HostSysfs()
 
 	if strings.HasPrefix(filepath.Base(devPath), "vfio") {
 		// IOMMUFD device (/dev/vfio/devices/vfio<NUM>): single device per char dev
-		major, minor, err := deviceUtils.GetMajorMinorFromDevPath(devPath)
+		major, minor, err := deviceUtils.GetMajorMinorFromDevPath(sysfs, devPath)
 		if err != nil {
 			return err
 		}
-		bdf, err := deviceUtils.GetBDFFromVFIODev(major, minor)
+		bdf, err := deviceUtils.GetBDFFromVFIODev(sysfs, major, minor)
 		if err != nil {
 			return err
 		}
 		devBDFs = []string{bdf}
-		vendorID := deviceUtils.GetPCIDeviceProperty(bdf, deviceUtils.PCISysFsDevicesVendor)
-		class := deviceUtils.GetPCIDeviceProperty(bdf, deviceUtils.PCISysFsDevicesClass)
+		vendorID := deviceUtils.GetPCIDeviceProperty(sysfs, bdf, deviceUtils.PCISysFsDevicesVendor)
+		class := deviceUtils.GetPCIDeviceProperty(sysfs, bdf, deviceUtils.PCISysFsDevicesClass)
 		_, isKnownCDIDevice = cdiKindForDevice(vendorID, class)
 	} else {
 		// Legacy VFIO group (/dev/vfio/<GROUP>): may contain multiple devices
 		vfioGroup := filepath.Base(devPath)
 		iommuDevicesPath := filepath.Join(config.SysIOMMUGroupPath, vfioGroup, "devices")
-		deviceFiles, err := os.ReadDir(iommuDevicesPath)
+		deviceFiles, err := sysfs.ReadDir(iommuDevicesPath)
 		if err != nil {
 			return err
 		}
 		for _, deviceFile := range deviceFiles {
-			deviceBDF, _, _, err := deviceUtils.GetVFIODetails(deviceFile.Name(), iommuDevicesPath)
+			deviceBDF, _, _, err := deviceUtils.GetVFIODetails(sysfs, deviceFile.Name(), iommuDevicesPath)
 			if err != nil {
 				return err
 			}
 			devBDFs = append(devBDFs, deviceBDF)
 			if !isKnownCDIDevice {
-				vendorID := deviceUtils.GetPCIDeviceProperty(deviceBDF, deviceUtils.PCISysFsDevicesVendor)
-				class := deviceUtils.GetPCIDeviceProperty(deviceBDF, deviceUtils.PCISysFsDevicesClass)
+				vendorID := deviceUtils.GetPCIDeviceProperty(sysfs, deviceBDF, deviceUtils.PCISysFsDevicesVendor)
+				class := deviceUtils.GetPCIDeviceProperty(sysfs, deviceBDF, deviceUtils.PCISysFsDevicesClass)
 				if _, ok := cdiKindForDevice(vendorID, class); ok {
 					isKnownCDIDevice = true
 				}
@@ -1941,7 +2004,11 @@ func (c *Container) detachDevices(ctx context.Context) error {
 			return err
 		}
 
-		if err = c.sandbox.devManager.RemoveDevice(dev.ID); err != nil {
+		device := c.sandbox.devManager.GetDeviceByID(dev.ID)
+
+		if err = c.sandbox.devManager.RemoveDevice(dev.ID); errors.Is(err, deviceManager.ErrColdPlugVFIOInUse) {
+			c.Logger().WithField("device-id", dev.ID).Info("VFIO device bound back to its host drivers once the VM stops")
+		} else if err != nil {
 			c.Logger().WithFields(logrus.Fields{
 				"container": c.id,
 				"device-id": dev.ID,
@@ -1952,6 +2019,13 @@ func (c *Container) detachDevices(ctx context.Context) error {
 				return err
 			}
 		}
+
+		// The last container using the erofs layer is gone
+		if device != nil && dev.ErofsLayer && c.sandbox.devManager.GetDeviceByID(dev.ID) == nil {
+			if err := c.sandbox.erofsLayers().release(c.sandbox.id, device.GetHostPath()); err != nil {
+				c.Logger().WithError(err).WithField("layer", device.GetHostPath()).Warn("failed to release erofs layer")
+			}
+		}
 	}
 	return nil
 }
@@ -16,6 +16,7 @@ import (
 	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
 	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/drivers"
 	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/manager"
+	vcAnnotations "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/pkg/annotations"
 	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/types"
 	"github.com/stretchr/testify/assert"
 )
@@ -82,7 +83,7 @@ func TestContainerRemoveDrive(t *testing.T) {
 	sandbox := &Sandbox{
 		ctx:        context.Background(),
 		id:         "sandbox",
-		devManager: manager.NewDeviceManager(config.VirtioSCSI, false, "", 0, nil),
+		devManager: manager.NewDeviceManager(config.VirtioSCSI, false, "", 0, nil, nil, nil),
 		config:     &SandboxConfig{},
 	}
 
@@ -381,6 +382,14 @@ func TestMountSharedDirMounts(t *testing.T) {
 	assert.NoError(err)
 }
 
+func TestIsPmemMount(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(isPmemMount(Mount{Source: "/var/lib/pmem/volume.img", Type: "bind", Options: []string{vcAnnotations.IsFilePmemDevice}}))
+	assert.False(isPmemMount(Mount{Source: "/var/lib/data", Type: "bind", Options: []string{"rbind"}}))
+	assert.False(isPmemMount(Mount{Source: "/var/lib/disk.img", Type: "bind", Options: []string{vcAnnotations.IsFileBlockDevice}}))
+}
+
 func TestGetContainerId(t *testing.T) {
 	containerIDs := []string{"abc", "foobar", "123"}
 	containers := [3]*Container{}
@@ -74,6 +74,15 @@ const (
 	// does not need a host network interface and instead has its network network configured
 	// through DAN.
 	VfioEndpointType EndpointType = "vfio"
+
+	// VdpaEndpointType is a vhost-vdpa device backing a network interface,
+	// which provides the guest with a virtio-net device offloaded to the
+	// hardware.
+	VdpaEndpointType EndpointType = "vdpa"
+
+	// PasstEndpointType is a user-mode networking interface, backed by a
+	// passt process that does not need any privilege on the host.
+	PasstEndpointType EndpointType = "passt"
 )
 
 // Set sets an endpoint type based on the input string.
@@ -106,7 +115,17 @@ func (endpointType *EndpointType) Set(value string) error {
 	case "vfio":
 		*endpointType = VfioEndpointType
 		return nil
+	case "vdpa":
+		*endpointType = VdpaEndpointType
+		return nil
+	case "passt":
+		*endpointType = PasstEndpointType
+		return nil
 	default:
+		if _, ok := endpointFactories[EndpointType(value)]; ok {
+			*endpointType = EndpointType(value)
+			return nil
+		}
 		return fmt.Errorf("Unknown endpoint type %s", value)
 	}
 }
@@ -132,11 +151,31 @@ func (endpointType *EndpointType) String() string {
 		return string(IPVlanEndpointType)
 	case VfioEndpointType:
 		return string(VfioEndpointType)
+	case VdpaEndpointType:
+		return string(VdpaEndpointType)
+	case PasstEndpointType:
+		return string(PasstEndpointType)
 	default:
+		if _, ok := endpointFactories[*endpointType]; ok {
+			return string(*endpointType)
+		}
 		return ""
 	}
 }
 
+// builtin returns true if the endpoint type is implemented by virtcontainers.
+func (endpointType EndpointType) builtin() bool {
+	switch endpointType {
+	case PhysicalEndpointType, VethEndpointType, VhostUserEndpointType,
+		MacvlanEndpointType, MacvtapEndpointType, TapEndpointType,
+		TuntapEndpointType, IPVlanEndpointType, VfioEndpointType,
+		VdpaEndpointType, PasstEndpointType:
+		return true
+	default:
+		return false
+	}
+}
+
 func saveTapIf(tapif *TapInterface) *persistapi.TapInterface {
 	if tapif == nil {
 		return nil
@@ -185,9 +224,14 @@ func saveNetIfPair(pair *NetworkInterfacePair) *persistapi.NetworkInterfacePair
 	}
 
 	return &persistapi.NetworkInterfacePair{
-		TapInterface:         *tapif,
-		VirtIface:            virtif,
-		NetInterworkingModel: int(pair.NetInterworkingModel),
+		TapInterface:          *tapif,
+		VirtIface:             virtif,
+		NetInterworkingModel:  int(pair.NetInterworkingModel),
+		NetworkPolicy:         pair.NetworkPolicy,
+		SharedClsact:          pair.SharedClsact,
+		NetworkPolicyPriority: pair.NetworkPolicyPriority,
+		RxRateLimiterMaxRate:  pair.Bandwidth.Ingress,
+		TxRateLimiterMaxRate:  pair.Bandwidth.Egress,
 	}
 }
 
@@ -207,9 +251,16 @@ func loadNetIfPair(pair *persistapi.NetworkInterfacePair) *NetworkInterfacePair
 	}
 
 	return &NetworkInterfacePair{
-		TapInterface:         *tapif,
-		VirtIface:            virtif,
-		NetInterworkingModel: NetInterworkingModel(pair.NetInterworkingModel),
+		TapInterface:          *tapif,
+		VirtIface:             virtif,
+		NetInterworkingModel:  NetInterworkingModel(pair.NetInterworkingModel),
+		NetworkPolicy:         pair.NetworkPolicy,
+		SharedClsact:          pair.SharedClsact,
+		NetworkPolicyPriority: pair.NetworkPolicyPriority,
+		Bandwidth: NetworkBandwidth{
+			Ingress: pair.RxRateLimiterMaxRate,
+			Egress:  pair.TxRateLimiterMaxRate,
+		},
 	}
 }
 
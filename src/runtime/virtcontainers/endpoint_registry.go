@@ -0,0 +1,171 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"fmt"
+
+	persistapi "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/persist/api"
+	vcTypes "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/types"
+)
+
+// PluggableEndpoint is a network endpoint implemented out of virtcontainers.
+// It provides the exported methods of Endpoint, and serializes its own
+// state so that it can be persisted along with the sandbox.
+type PluggableEndpoint interface {
+	Properties() NetworkInfo
+	Name() string
+	HardwareAddr() string
+	Type() EndpointType
+	PciPath() vcTypes.PciPath
+	CcwDevice() *vcTypes.CcwDevice
+	NetworkPair() *NetworkInterfacePair
+
+	SetProperties(NetworkInfo)
+	SetPciPath(vcTypes.PciPath)
+	SetCcwDevice(vcTypes.CcwDevice)
+	Attach(context.Context, *Sandbox) error
+	Detach(ctx context.Context, netNsCreated bool, netNsPath string) error
+	HotAttach(context.Context, *Sandbox) error
+	HotDetach(ctx context.Context, s *Sandbox, netNsCreated bool, netNsPath string) error
+
+	GetRxRateLimiter() bool
+	SetRxRateLimiter() error
+	GetTxRateLimiter() bool
+	SetTxRateLimiter() error
+
+	// SaveState returns the state of the endpoint to persist.
+	SaveState() ([]byte, error)
+}
+
+// EndpointFactory creates the endpoints of an out-of-tree endpoint type.
+type EndpointFactory interface {
+	// NewEndpoint creates the endpoint of a network interface. idx is
+	// unique among the sandbox endpoints, and can be used to name the
+	// host resources of the endpoint.
+	NewEndpoint(idx int, netInfo NetworkInfo, model NetInterworkingModel) (PluggableEndpoint, error)
+
+	// LoadEndpoint restores an endpoint from the state returned by
+	// its SaveState method.
+	LoadEndpoint(state []byte) (PluggableEndpoint, error)
+}
+
+var (
+	endpointFactories     = make(map[EndpointType]EndpointFactory)
+	linkTypeEndpointTypes = make(map[string]EndpointType)
+)
+
+// RegisterEndpointType registers the factory of an out-of-tree endpoint type.
+// The endpoints of the network interfaces of one of the link types, or mapped
+// to the endpoint type by the network_endpoint_types annotation, are created
+// by the factory. The registered link types take precedence over the built-in
+// endpoint types.
+// It is not safe for concurrent use, and is expected to be called from init
+// functions.
+func RegisterEndpointType(endpointType EndpointType, factory EndpointFactory, linkTypes ...string) error {
+	if endpointType == "" || factory == nil {
+		return fmt.Errorf("endpoint type must have a valid name and factory")
+	}
+
+	if endpointType.builtin() {
+		return fmt.Errorf("Endpoint type %q is a built-in endpoint type", endpointType)
+	}
+
+	if _, ok := endpointFactories[endpointType]; ok {
+		return fmt.Errorf("Endpoint type %q had been registered before", endpointType)
+	}
+
+	for _, linkType := range linkTypes {
+		if registered, ok := linkTypeEndpointTypes[linkType]; ok {
+			return fmt.Errorf("Link type %q had been registered before by endpoint type %q", linkType, registered)
+		}
+	}
+
+	endpointFactories[endpointType] = factory
+	for _, linkType := range linkTypes {
+		linkTypeEndpointTypes[linkType] = endpointType
+	}
+
+	return nil
+}
+
+// IsPluginEndpointType returns true if the endpoint type has been registered
+// with RegisterEndpointType.
+func IsPluginEndpointType(endpointType EndpointType) bool {
+	_, ok := endpointFactories[endpointType]
+	return ok
+}
+
+// newPluginEndpoint creates the endpoint of a network interface with the
+// factory of a registered endpoint type.
+func newPluginEndpoint(endpointType EndpointType, idx int, netInfo NetworkInfo, model NetInterworkingModel) (Endpoint, error) {
+	factory, ok := endpointFactories[endpointType]
+	if !ok {
+		return nil, fmt.Errorf("Unknown endpoint type %s for interface %s", endpointType, netInfo.Iface.Name)
+	}
+
+	ep, err := factory.NewEndpoint(idx, netInfo, model)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pluginEndpoint{ep}, nil
+}
+
+// loadPluginEndpoint restores a persisted endpoint of a registered endpoint type.
+func loadPluginEndpoint(s persistapi.NetworkEndpoint) (Endpoint, error) {
+	factory, ok := endpointFactories[EndpointType(s.Type)]
+	if !ok {
+		return nil, fmt.Errorf("Unknown endpoint type %s", s.Type)
+	}
+
+	var state []byte
+	if s.Plugin != nil {
+		state = s.Plugin.State
+	}
+
+	ep, err := factory.LoadEndpoint(state)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pluginEndpoint{ep}, nil
+}
+
+// pluginEndpoint adapts a PluggableEndpoint to the Endpoint interface.
+type pluginEndpoint struct {
+	PluggableEndpoint
+}
+
+func (endpoint *pluginEndpoint) save() persistapi.NetworkEndpoint {
+	s, err := endpoint.saveState()
+	if err != nil {
+		networkLogger().WithError(err).WithField("endpoint-type", endpoint.Type()).Error("failed to save endpoint state")
+	}
+
+	return s
+}
+
+// saveState is save, returning the error of the plugin serializing the
+// state of the endpoint.
+func (endpoint *pluginEndpoint) saveState() (persistapi.NetworkEndpoint, error) {
+	state, err := endpoint.SaveState()
+	if err != nil {
+		return persistapi.NetworkEndpoint{}, fmt.Errorf("failed to save the state of %s endpoint %s: %w", endpoint.Type(), endpoint.Name(), err)
+	}
+
+	return persistapi.NetworkEndpoint{
+		Type: string(endpoint.Type()),
+		Plugin: &persistapi.PluginEndpoint{
+			State: state,
+		},
+	}, nil
+}
+
+// load is a no-op, as plugin endpoints are restored by their factory.
+func (endpoint *pluginEndpoint) load(s persistapi.NetworkEndpoint) {
+}
@@ -0,0 +1,65 @@
+//go:build linux
+
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+
+	"github.com/containernetworking/plugins/pkg/ns"
+)
+
+// AttachEndpoint connects the network pair of a plugin endpoint, if any, to
+// the VM, and adds the endpoint to the hypervisor as a network device. It is
+// meant to be called from the Attach and HotAttach methods of plugin
+// endpoints which do not manage the devices themselves.
+func AttachEndpoint(ctx context.Context, s *Sandbox, ep PluggableEndpoint, hotplug bool) error {
+	endpoint := &pluginEndpoint{ep}
+	h := s.hypervisor
+
+	if ep.NetworkPair() != nil {
+		if err := xConnectVMNetwork(ctx, endpoint, h); err != nil {
+			networkLogger().WithError(err).WithField("endpoint-type", ep.Type()).Error("Error bridging plugin endpoint")
+			return err
+		}
+	}
+
+	if hotplug {
+		_, err := h.HotplugAddDevice(ctx, endpoint, NetDev)
+		return err
+	}
+
+	return h.AddDevice(ctx, endpoint, NetDev)
+}
+
+// DetachEndpoint is the counterpart of AttachEndpoint. It disconnects the
+// network pair of a plugin endpoint, if any, from the VM, and hot unplugs the
+// endpoint from the hypervisor if hotplug is set. The sandbox is only used to
+// hot unplug the endpoint, and can be nil otherwise.
+func DetachEndpoint(ctx context.Context, s *Sandbox, ep PluggableEndpoint, netNsCreated bool, netNsPath string, hotplug bool) error {
+	endpoint := &pluginEndpoint{ep}
+
+	// The network namespace would have been deleted at this point
+	// if it has not been created by virtcontainers.
+	if netNsCreated && ep.NetworkPair() != nil {
+		if err := doNetNS(netNsPath, func(_ ns.NetNS) error {
+			return xDisconnectVMNetwork(ctx, endpoint)
+		}); err != nil {
+			if !hotplug {
+				return err
+			}
+			networkLogger().WithError(err).WithField("endpoint-type", ep.Type()).Warn("Error un-bridging plugin endpoint")
+		}
+	}
+
+	if !hotplug {
+		return nil
+	}
+
+	_, err := s.hypervisor.HotplugRemoveDevice(ctx, endpoint, NetDev)
+	return err
+}
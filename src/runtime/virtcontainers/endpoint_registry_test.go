@@ -0,0 +1,237 @@
+//go:build linux
+
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	govmmQemu "github.com/kata-containers/kata-containers/src/runtime/pkg/govmm/qemu"
+	persistapi "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/persist/api"
+	vcTypes "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPluginEndpointType EndpointType = "test-plugin"
+
+type testPluginEndpoint struct {
+	name       string
+	properties NetworkInfo
+	netPair    *NetworkInterfacePair
+	saveErr    error
+	attached   bool
+	hotplugged bool
+}
+
+func (e *testPluginEndpoint) Properties() NetworkInfo              { return e.properties }
+func (e *testPluginEndpoint) Name() string                         { return e.name }
+func (e *testPluginEndpoint) HardwareAddr() string                 { return e.properties.Iface.HardwareAddr.String() }
+func (e *testPluginEndpoint) Type() EndpointType                   { return testPluginEndpointType }
+func (e *testPluginEndpoint) PciPath() vcTypes.PciPath             { return vcTypes.PciPath{} }
+func (e *testPluginEndpoint) CcwDevice() *vcTypes.CcwDevice        { return nil }
+func (e *testPluginEndpoint) NetworkPair() *NetworkInterfacePair   { return e.netPair }
+func (e *testPluginEndpoint) SetProperties(properties NetworkInfo) { e.properties = properties }
+func (e *testPluginEndpoint) SetPciPath(vcTypes.PciPath)           {}
+func (e *testPluginEndpoint) SetCcwDevice(vcTypes.CcwDevice)       {}
+func (e *testPluginEndpoint) GetRxRateLimiter() bool               { return false }
+func (e *testPluginEndpoint) SetRxRateLimiter() error              { return nil }
+func (e *testPluginEndpoint) GetTxRateLimiter() bool               { return false }
+func (e *testPluginEndpoint) SetTxRateLimiter() error              { return nil }
+
+func (e *testPluginEndpoint) Attach(ctx context.Context, s *Sandbox) error {
+	e.attached = true
+	return nil
+}
+
+func (e *testPluginEndpoint) Detach(ctx context.Context, netNsCreated bool, netNsPath string) error {
+	e.attached = false
+	return nil
+}
+
+func (e *testPluginEndpoint) HotAttach(ctx context.Context, s *Sandbox) error {
+	e.attached = true
+	e.hotplugged = true
+	return nil
+}
+
+func (e *testPluginEndpoint) HotDetach(ctx context.Context, s *Sandbox, netNsCreated bool, netNsPath string) error {
+	e.attached = false
+	return nil
+}
+
+func (e *testPluginEndpoint) SaveState() ([]byte, error) {
+	if e.saveErr != nil {
+		return nil, e.saveErr
+	}
+	return []byte(e.name), nil
+}
+
+type testPluginEndpointFactory struct{}
+
+func (f testPluginEndpointFactory) NewEndpoint(idx int, netInfo NetworkInfo, model NetInterworkingModel) (PluggableEndpoint, error) {
+	return &testPluginEndpoint{name: fmt.Sprintf("plugin%d", idx)}, nil
+}
+
+func (f testPluginEndpointFactory) LoadEndpoint(state []byte) (PluggableEndpoint, error) {
+	if len(state) == 0 {
+		return nil, fmt.Errorf("missing endpoint state")
+	}
+	return &testPluginEndpoint{name: string(state)}, nil
+}
+
+func registerTestPluginEndpoint(t *testing.T, linkTypes ...string) {
+	assert.NoError(t, RegisterEndpointType(testPluginEndpointType, testPluginEndpointFactory{}, linkTypes...))
+
+	t.Cleanup(func() {
+		delete(endpointFactories, testPluginEndpointType)
+		for _, linkType := range linkTypes {
+			delete(linkTypeEndpointTypes, linkType)
+		}
+	})
+}
+
+func TestRegisterEndpointType(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Error(RegisterEndpointType("", testPluginEndpointFactory{}))
+	assert.Error(RegisterEndpointType(testPluginEndpointType, nil))
+	assert.Error(RegisterEndpointType(VethEndpointType, testPluginEndpointFactory{}))
+
+	var endpointType EndpointType
+	assert.Error(endpointType.Set(string(testPluginEndpointType)))
+
+	registerTestPluginEndpoint(t, "test-link")
+
+	assert.Error(RegisterEndpointType(testPluginEndpointType, testPluginEndpointFactory{}))
+	assert.Error(RegisterEndpointType("other-plugin", testPluginEndpointFactory{}, "test-link"))
+	_, ok := endpointFactories["other-plugin"]
+	assert.False(ok)
+
+	assert.NoError(endpointType.Set(string(testPluginEndpointType)))
+	assert.Equal(testPluginEndpointType, endpointType)
+	assert.Equal(string(testPluginEndpointType), endpointType.String())
+}
+
+func TestPluginEndpointSaveLoad(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := newPluginEndpoint(testPluginEndpointType, 0, NetworkInfo{}, NetXConnectTCFilterModel)
+	assert.Error(err)
+
+	registerTestPluginEndpoint(t)
+
+	ep, err := newPluginEndpoint(testPluginEndpointType, 3, NetworkInfo{}, NetXConnectTCFilterModel)
+	assert.NoError(err)
+	assert.Equal("plugin3", ep.Name())
+	assert.Equal(testPluginEndpointType, ep.Type())
+
+	saved := ep.save()
+	assert.Equal(string(testPluginEndpointType), saved.Type)
+	assert.NotNil(saved.Plugin)
+
+	loaded, err := loadPluginEndpoint(saved)
+	assert.NoError(err)
+	assert.Equal("plugin3", loaded.Name())
+
+	_, err = loadPluginEndpoint(persistapi.NetworkEndpoint{Type: string(testPluginEndpointType)})
+	assert.Error(err)
+
+	network := LoadNetwork(persistapi.NetworkInfo{
+		NetworkID: "net-123",
+		Endpoints: []persistapi.NetworkEndpoint{saved, {Type: "unknown"}},
+	})
+	assert.Len(network.Endpoints(), 1)
+	assert.Equal("plugin3", network.Endpoints()[0].Name())
+
+	// The sandbox state is not saved without the state of the endpoint
+	ep.(*pluginEndpoint).PluggableEndpoint.(*testPluginEndpoint).saveErr = fmt.Errorf("no state")
+	s := &Sandbox{network: &LinuxNetwork{eps: []Endpoint{ep}}}
+	var ss persistapi.SandboxState
+	assert.ErrorContains(s.dumpNetwork(&ss), "no state")
+}
+
+func TestAddPluginEndpoint(t *testing.T) {
+	assert := assert.New(t)
+
+	registerTestPluginEndpoint(t, "test-link")
+
+	network := &LinuxNetwork{
+		eps:               []Endpoint{},
+		interworkingModel: NetXConnectTCFilterModel,
+		endpointTypes:     map[string]EndpointType{"net1": testPluginEndpointType},
+	}
+	s := &Sandbox{hypervisor: &mockHypervisor{}}
+	ctx := context.Background()
+
+	// Mapped by link type
+	netInfo := NetworkInfo{}
+	netInfo.Iface.Name = "eth0"
+	netInfo.Iface.Type = "test-link"
+	ep, err := network.addSingleEndpoint(ctx, s, netInfo, false)
+	assert.NoError(err)
+	assert.Equal(testPluginEndpointType, ep.Type())
+	assert.Equal("plugin0", ep.Name())
+	assert.Equal("eth0", ep.Properties().Iface.Name)
+	assert.True(ep.(*pluginEndpoint).PluggableEndpoint.(*testPluginEndpoint).attached)
+
+	// Mapped by interface name
+	netInfo.Iface.Name = "net1"
+	netInfo.Iface.Type = "veth"
+	ep, err = network.addSingleEndpoint(ctx, s, netInfo, true)
+	assert.NoError(err)
+	assert.Equal(testPluginEndpointType, ep.Type())
+	assert.Equal("plugin1", ep.Name())
+	assert.True(ep.(*pluginEndpoint).PluggableEndpoint.(*testPluginEndpoint).hotplugged)
+
+	assert.Len(network.Endpoints(), 2)
+}
+
+func TestPluginEndpointQemu(t *testing.T) {
+	assert := assert.New(t)
+
+	netPair := &NetworkInterfacePair{
+		TapInterface: TapInterface{
+			ID:   "uniqueTestID-0",
+			Name: "br0_kata",
+			TAPIface: NetworkInterface{
+				Name:     "tap0_kata",
+				HardAddr: "02:00:ca:fe:00:00",
+			},
+		},
+		NetInterworkingModel: NetXConnectTCFilterModel,
+	}
+	endpoint := &pluginEndpoint{&testPluginEndpoint{name: "plugin0", netPair: netPair}}
+
+	// The plugin endpoints are cold plugged as the tap of their network pair
+	d, err := genericNetwork(endpoint, false, false, 0)
+	assert.NoError(err)
+	assert.Equal(govmmQemu.NetDevice{
+		Type:       networkModelToQemuType(netPair.NetInterworkingModel),
+		Driver:     govmmQemu.VirtioNet,
+		ID:         "network-0",
+		IFName:     "tap0_kata",
+		MACAddress: "02:00:ca:fe:00:00",
+		DownScript: "no",
+		Script:     "no",
+	}, d)
+
+	// and hot plugged as well
+	tap, vhostVdpaPath, passtSocketPath, err := hotplugNetTap(endpoint)
+	assert.NoError(err)
+	assert.Equal(netPair.TapInterface, tap)
+	assert.Empty(vhostVdpaPath)
+	assert.Empty(passtSocketPath)
+
+	// The plugin endpoints without network pair manage their own devices
+	endpoint = &pluginEndpoint{&testPluginEndpoint{name: "plugin1"}}
+	_, err = genericNetwork(endpoint, false, false, 0)
+	assert.Error(err)
+	_, _, _, err = hotplugNetTap(endpoint)
+	assert.Error(err)
+}
@@ -46,6 +46,10 @@ func TestVfioEndpointTypeSet(t *testing.T) {
 	testEndpointTypeSet(t, "vfio", VfioEndpointType)
 }
 
+func TestVdpaEndpointTypeSet(t *testing.T) {
+	testEndpointTypeSet(t, "vdpa", VdpaEndpointType)
+}
+
 func TestEndpointTypeSetFailure(t *testing.T) {
 	var endpointType EndpointType
 
@@ -82,6 +86,11 @@ func TestMacvtapEndpointTypeString(t *testing.T) {
 	testEndpointTypeString(t, &endpointType, string(MacvtapEndpointType))
 }
 
+func TestVdpaEndpointTypeString(t *testing.T) {
+	endpointType := VdpaEndpointType
+	testEndpointTypeString(t, &endpointType, string(VdpaEndpointType))
+}
+
 func TestIncorrectEndpointTypeString(t *testing.T) {
 	var endpointType EndpointType
 	testEndpointTypeString(t, &endpointType, "")
@@ -0,0 +1,361 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+// defaultErofsLayerCacheDir is the default directory of the node-level erofs
+// layer cache.
+const defaultErofsLayerCacheDir = "/run/kata-containers/erofs-layers"
+
+const (
+	erofsLayerCacheLock   = ".lock"
+	erofsLayerCacheIndex  = "index"
+	erofsLayerCacheLayers = "layers"
+	erofsLayerCacheBlob   = "blob"
+	erofsLayerCacheUsers  = "users"
+
+	// erofsLayerDigestXattr is the extended attribute holding the
+	// uncompressed digest label of the snapshot of a layer blob.
+	erofsLayerDigestXattr = "user.containerd.io/uncompressed"
+)
+
+// erofsLayerCache is the node-level cache of the erofs layer blobs, keyed by
+// layer digest and shared by the sandboxes of the node. The erofs snapshotter
+// keeps a blob per snapshot, the same layer pulled for different images is a
+// different blob: the cache maps all of them to a single blob, so that the
+// identical layers of the containers of a sandbox are attached once to its VM
+// as one read-only block device, reference counted by the device manager.
+//
+// The cache is laid out as follows, and updated under a file lock since the
+// shims of the node share it:
+//
+//	<dir>/index/<dev>-<ino>-<size>-<mtime>: digest of the layer blob
+//	<dir>/layers/<digest>/blob: path of the blob attached for the layer
+//	<dir>/layers/<digest>/users/<sandbox ID>: sandbox using the layer
+//
+// A layer is removed from the cache once no sandbox uses it anymore. The
+// sandboxes whose shim crashed never release their layers, they are dropped
+// from the users of the layers once they no longer exist.
+type erofsLayerCache struct {
+	// isAlive reports whether a sandbox using layers still exists, the
+	// users of the sandboxes gone without releasing them are dropped.
+	isAlive func(sandboxID string) bool
+	dir     string
+}
+
+func newErofsLayerCache(dir string, isAlive func(sandboxID string) bool) *erofsLayerCache {
+	return &erofsLayerCache{dir: dir, isAlive: isAlive}
+}
+
+// acquire returns the blob to attach for the layer blob, recording that the
+// sandbox uses it.
+func (e *erofsLayerCache) acquire(sandboxID, blob string) (string, error) {
+	var cached string
+
+	key, err := erofsLayerIndexKey(blob)
+	if err != nil {
+		return "", fmt.Errorf("caching erofs layer %s: %w", blob, err)
+	}
+
+	var digest string
+	if err := e.locked(func() error {
+		digest = e.indexedDigest(key)
+		return nil
+	}); err != nil {
+		return "", fmt.Errorf("caching erofs layer %s: %w", blob, err)
+	}
+
+	// Hashing a layer blob takes a while, do not hold the lock of the
+	// shims of the node meanwhile. The blob is only hashed when the
+	// snapshotter recorded no digest for it.
+	if digest == "" {
+		digest = erofsRecordedDigest(blob)
+	}
+	if digest == "" {
+		if digest, err = erofsLayerDigest(blob); err != nil {
+			return "", fmt.Errorf("caching erofs layer %s: %w", blob, err)
+		}
+	}
+
+	err = e.locked(func() error {
+		if err := e.dropStaleUsers(sandboxID); err != nil {
+			return err
+		}
+
+		indexPath := filepath.Join(e.dir, erofsLayerCacheIndex, key)
+		if err := os.MkdirAll(filepath.Dir(indexPath), DirMode); err != nil {
+			return err
+		}
+		if err := os.WriteFile(indexPath, []byte(digest), 0640); err != nil {
+			return err
+		}
+
+		layerDir := e.layerDir(digest)
+		if err := os.MkdirAll(filepath.Join(layerDir, erofsLayerCacheUsers), DirMode); err != nil {
+			return err
+		}
+
+		// The blob of the layer is the first one seen, as long as the
+		// snapshot it belongs to is not removed.
+		blobPath := filepath.Join(layerDir, erofsLayerCacheBlob)
+		if b, err := os.ReadFile(blobPath); err == nil {
+			if _, err := os.Stat(string(b)); err == nil {
+				cached = string(b)
+			}
+		}
+		if cached == "" {
+			if err := os.WriteFile(blobPath, []byte(blob), 0640); err != nil {
+				return err
+			}
+			cached = blob
+		}
+
+		return os.WriteFile(filepath.Join(layerDir, erofsLayerCacheUsers, sandboxID), nil, 0640)
+	})
+	if err != nil {
+		return "", fmt.Errorf("caching erofs layer %s: %w", blob, err)
+	}
+
+	return cached, nil
+}
+
+// release records that the sandbox does not use the layer of the blob
+// anymore, removing the layer from the cache when it was its last user.
+func (e *erofsLayerCache) release(sandboxID, blob string) error {
+	if _, err := os.Stat(e.dir); os.IsNotExist(err) {
+		return nil
+	}
+
+	key, err := erofsLayerIndexKey(blob)
+	if err != nil {
+		// The blob is not in the cache.
+		return nil
+	}
+
+	return e.locked(func() error {
+		digest := e.indexedDigest(key)
+		if digest == "" {
+			// The blob is not in the cache.
+			return nil
+		}
+
+		return e.releaseLayer(sandboxID, digest)
+	})
+}
+
+// releaseSandbox records that the sandbox does not use any layer anymore.
+func (e *erofsLayerCache) releaseSandbox(sandboxID string) error {
+	if _, err := os.Stat(e.dir); os.IsNotExist(err) {
+		return nil
+	}
+
+	return e.locked(func() error {
+		if err := e.dropStaleUsers(sandboxID); err != nil {
+			return err
+		}
+
+		layers, err := os.ReadDir(filepath.Join(e.dir, erofsLayerCacheLayers))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		for _, layer := range layers {
+			if err := e.releaseLayer(sandboxID, layer.Name()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// dropStaleUsers releases the layers of the sandboxes that no longer exist,
+// other than the sandbox updating the cache. The lock must be held.
+func (e *erofsLayerCache) dropStaleUsers(sandboxID string) error {
+	if e.isAlive == nil {
+		return nil
+	}
+
+	layers, err := os.ReadDir(filepath.Join(e.dir, erofsLayerCacheLayers))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	for _, layer := range layers {
+		users, err := os.ReadDir(filepath.Join(e.layerDir(layer.Name()), erofsLayerCacheUsers))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		for _, user := range users {
+			if user.Name() == sandboxID || e.isAlive(user.Name()) {
+				continue
+			}
+
+			virtLog.WithFields(logrus.Fields{
+				"layer":   layer.Name(),
+				"sandbox": user.Name(),
+			}).Warn("Dropping erofs layer user of a sandbox that no longer exists")
+
+			if err := e.releaseLayer(user.Name(), layer.Name()); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// releaseLayer removes the sandbox from the users of the layer, and the layer
+// from the cache when it has no user left. The lock must be held.
+func (e *erofsLayerCache) releaseLayer(sandboxID, digest string) error {
+	layerDir := e.layerDir(digest)
+	usersDir := filepath.Join(layerDir, erofsLayerCacheUsers)
+
+	if err := os.Remove(filepath.Join(usersDir, sandboxID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	users, err := os.ReadDir(usersDir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(users) > 0 {
+		return nil
+	}
+
+	if err := os.RemoveAll(layerDir); err != nil {
+		return err
+	}
+
+	// Forget the blobs of the layer.
+	indexDir := filepath.Join(e.dir, erofsLayerCacheIndex)
+	entries, err := os.ReadDir(indexDir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, entry := range entries {
+		path := filepath.Join(indexDir, entry.Name())
+		if d, err := os.ReadFile(path); err == nil && string(d) == digest {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// erofsLayerIndexKey returns the key of the layer blob in the index.
+func erofsLayerIndexKey(blob string) (string, error) {
+	info, err := os.Stat(blob)
+	if err != nil {
+		return "", err
+	}
+
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return fmt.Sprintf("%d-%d-%d-%d", uint64(st.Dev), st.Ino, info.Size(), info.ModTime().UnixNano()), nil
+	}
+	return fmt.Sprintf("%d-%d-%d-%d", 0, 0, info.Size(), info.ModTime().UnixNano()), nil
+}
+
+// indexedDigest returns the digest of the layer blob indexed with the key, or
+// an empty digest for the blobs not indexed. The lock must be held.
+func (e *erofsLayerCache) indexedDigest(key string) string {
+	d, err := os.ReadFile(filepath.Join(e.dir, erofsLayerCacheIndex, key))
+	if err != nil {
+		return ""
+	}
+	return string(d)
+}
+
+// erofsRecordedDigest returns the digest recorded by the snapshotter for the
+// layer blob, or an empty digest when none is. The digest is either part of
+// the blob path, for content addressed layouts, or the uncompressed digest
+// label of the snapshot, recorded as an extended attribute of the blob or of
+// its snapshot directory.
+func erofsRecordedDigest(blob string) string {
+	for dir := filepath.Dir(blob); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if d, err := digest.Parse(filepath.Base(filepath.Dir(dir)) + ":" + filepath.Base(dir)); err == nil {
+			return erofsLayerDigestName(d)
+		}
+		if d, err := digest.Parse(filepath.Base(dir)); err == nil {
+			return erofsLayerDigestName(d)
+		}
+	}
+
+	for _, path := range []string{blob, filepath.Dir(blob)} {
+		buf := make([]byte, 128)
+		n, err := unix.Getxattr(path, erofsLayerDigestXattr, buf)
+		if err != nil {
+			continue
+		}
+		if d, err := digest.Parse(string(buf[:n])); err == nil {
+			return erofsLayerDigestName(d)
+		}
+	}
+
+	return ""
+}
+
+// erofsLayerDigestName returns the name of the layer of the digest in the
+// cache.
+func erofsLayerDigestName(d digest.Digest) string {
+	return d.Algorithm().String() + "-" + d.Encoded()
+}
+
+// erofsLayerDigest computes the digest of the layer blob.
+func erofsLayerDigest(blob string) (string, error) {
+	f, err := os.Open(blob)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return erofsLayerDigestName(digest.NewDigestFromEncoded(digest.SHA256, hex.EncodeToString(h.Sum(nil)))), nil
+}
+
+func (e *erofsLayerCache) layerDir(digest string) string {
+	// The digest comes from the index, make sure it stays in the cache.
+	return filepath.Join(e.dir, erofsLayerCacheLayers, strings.ReplaceAll(digest, string(filepath.Separator), "-"))
+}
+
+// locked runs the function with the cache locked.
+func (e *erofsLayerCache) locked(fn func() error) error {
+	if err := os.MkdirAll(e.dir, DirMode); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filepath.Join(e.dir, erofsLayerCacheLock), os.O_CREATE|os.O_RDONLY, 0640)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		return err
+	}
+	defer syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+
+	return fn()
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+func writeErofsLayer(t *testing.T, snapshot, content string) string {
+	blob := filepath.Join(t.TempDir(), snapshot, "layer.erofs")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(blob), 0755))
+	assert.NoError(t, os.WriteFile(blob, []byte(content), 0644))
+	return blob
+}
+
+func TestErofsLayerCache(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := filepath.Join(t.TempDir(), "erofs-layers")
+	cache := newErofsLayerCache(dir, nil)
+
+	layer := writeErofsLayer(t, "1", "layer")
+	sameLayer := writeErofsLayer(t, "2", "layer")
+	otherLayer := writeErofsLayer(t, "3", "other")
+
+	// Nothing to release before the cache is used
+	assert.NoError(cache.release("sandbox1", layer))
+	assert.NoError(cache.releaseSandbox("sandbox1"))
+	assert.NoDirExists(dir)
+
+	// The blobs of the same layer are attached as the first one
+	blob, err := cache.acquire("sandbox1", layer)
+	assert.NoError(err)
+	assert.Equal(layer, blob)
+
+	blob, err = cache.acquire("sandbox1", sameLayer)
+	assert.NoError(err)
+	assert.Equal(layer, blob)
+
+	blob, err = cache.acquire("sandbox2", sameLayer)
+	assert.NoError(err)
+	assert.Equal(layer, blob)
+
+	blob, err = cache.acquire("sandbox1", otherLayer)
+	assert.NoError(err)
+	assert.Equal(otherLayer, blob)
+
+	layers, err := os.ReadDir(filepath.Join(dir, erofsLayerCacheLayers))
+	assert.NoError(err)
+	assert.Len(layers, 2)
+
+	// The layer is kept as long as a sandbox uses it
+	assert.NoError(cache.release("sandbox1", layer))
+	layers, err = os.ReadDir(filepath.Join(dir, erofsLayerCacheLayers))
+	assert.NoError(err)
+	assert.Len(layers, 2)
+
+	assert.NoError(cache.releaseSandbox("sandbox2"))
+	layers, err = os.ReadDir(filepath.Join(dir, erofsLayerCacheLayers))
+	assert.NoError(err)
+	assert.Len(layers, 1)
+
+	// and the blobs of the layer are forgotten with it
+	index, err := os.ReadDir(filepath.Join(dir, erofsLayerCacheIndex))
+	assert.NoError(err)
+	assert.Len(index, 1)
+
+	assert.NoError(cache.releaseSandbox("sandbox1"))
+	layers, err = os.ReadDir(filepath.Join(dir, erofsLayerCacheLayers))
+	assert.NoError(err)
+	assert.Empty(layers)
+	index, err = os.ReadDir(filepath.Join(dir, erofsLayerCacheIndex))
+	assert.NoError(err)
+	assert.Empty(index)
+
+	// A layer whose blob was removed is attached as the next one
+	_, err = cache.acquire("sandbox1", layer)
+	assert.NoError(err)
+	assert.NoError(os.Remove(layer))
+
+	blob, err = cache.acquire("sandbox2", sameLayer)
+	assert.NoError(err)
+	assert.Equal(sameLayer, blob)
+}
+
+func TestErofsLayerCacheStaleUsers(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := filepath.Join(t.TempDir(), "erofs-layers")
+	alive := map[string]bool{"sandbox1": true, "sandbox2": true}
+	cache := newErofsLayerCache(dir, func(sandboxID string) bool {
+		return alive[sandboxID]
+	})
+
+	layer := writeErofsLayer(t, "1", "layer")
+	otherLayer := writeErofsLayer(t, "2", "other")
+
+	_, err := cache.acquire("sandbox1", layer)
+	assert.NoError(err)
+	_, err = cache.acquire("sandbox2", layer)
+	assert.NoError(err)
+	_, err = cache.acquire("sandbox2", otherLayer)
+	assert.NoError(err)
+
+	// The shim of sandbox2 crashed without releasing its layers
+	delete(alive, "sandbox2")
+
+	_, err = cache.acquire("sandbox1", layer)
+	assert.NoError(err)
+	layers, err := os.ReadDir(filepath.Join(dir, erofsLayerCacheLayers))
+	assert.NoError(err)
+	assert.Len(layers, 1)
+
+	users, err := os.ReadDir(filepath.Join(dir, erofsLayerCacheLayers, layers[0].Name(), erofsLayerCacheUsers))
+	assert.NoError(err)
+	assert.Len(users, 1)
+	assert.Equal("sandbox1", users[0].Name())
+
+	// The sandbox updating the cache is never dropped
+	delete(alive, "sandbox1")
+	_, err = cache.acquire("sandbox1", otherLayer)
+	assert.NoError(err)
+	layers, err = os.ReadDir(filepath.Join(dir, erofsLayerCacheLayers))
+	assert.NoError(err)
+	assert.Len(layers, 2)
+
+	assert.NoError(cache.releaseSandbox("sandbox1"))
+	layers, err = os.ReadDir(filepath.Join(dir, erofsLayerCacheLayers))
+	assert.NoError(err)
+	assert.Empty(layers)
+}
+
+func TestErofsRecordedDigest(t *testing.T) {
+	assert := assert.New(t)
+
+	encoded := "0b3d5f6e2d4c8a1f9e7b6a5c4d3e2f1a0b9c8d7e6f5a4b3c2d1e0f9a8b7c6d5e"
+
+	// The digest is part of the blob path
+	blob := writeErofsLayer(t, filepath.Join("sha256", encoded), "layer")
+	assert.Equal("sha256-"+encoded, erofsRecordedDigest(blob))
+
+	blob = writeErofsLayer(t, "sha256:"+encoded, "layer")
+	assert.Equal("sha256-"+encoded, erofsRecordedDigest(blob))
+
+	// or the label of the snapshot directory
+	blob = writeErofsLayer(t, "1", "layer")
+	assert.Empty(erofsRecordedDigest(blob))
+	if err := unix.Setxattr(filepath.Dir(blob), erofsLayerDigestXattr, []byte("sha256:"+encoded), 0); err != nil {
+		t.Skipf("user extended attributes not supported: %v", err)
+	}
+	assert.Equal("sha256-"+encoded, erofsRecordedDigest(blob))
+
+	// and the layers with the same recorded digest are cached as one
+	otherBlob := writeErofsLayer(t, "2", "other")
+	assert.NoError(unix.Setxattr(otherBlob, erofsLayerDigestXattr, []byte("sha256:"+encoded), 0))
+
+	cache := newErofsLayerCache(filepath.Join(t.TempDir(), "erofs-layers"), nil)
+	cached, err := cache.acquire("sandbox1", blob)
+	assert.NoError(err)
+	assert.Equal(blob, cached)
+	cached, err = cache.acquire("sandbox1", otherBlob)
+	assert.NoError(err)
+	assert.Equal(blob, cached)
+}
+
+func TestSandboxErofsLayersDir(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &Sandbox{id: "sandbox1", config: &SandboxConfig{}}
+	assert.Equal(defaultErofsLayerCacheDir, s.erofsLayers().dir)
+
+	s.config.ErofsLayerCacheDir = "/run/erofs-layers"
+	assert.Equal("/run/erofs-layers", s.erofsLayers().dir)
+}
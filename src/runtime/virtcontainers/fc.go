@@ -941,48 +941,23 @@ func (fc *firecracker) fcAddNetDevice(ctx context.Context, endpoint Endpoint) {
 		f.Close()
 	}
 
-	// The implementation of rate limiter is based on TBF.
-	// Rate Limiter defines a token bucket with a maximum capacity (size) to store tokens, and an interval for refilling purposes (refill_time).
-	// The refill-rate is derived from size and refill_time, and it is the constant rate at which the tokens replenish.
-	refillTime := uint64(utils.DefaultRateLimiterRefillTimeMilliSecs)
-	var rxRateLimiter models.RateLimiter
-	rxSize := fc.config.RxRateLimiterMaxRate
-	if rxSize > 0 {
-		fc.Logger().Info("Add rx rate limiter")
-
-		// kata-defined rxSize is in bits with scaling factors of 1000, but firecracker-defined
-		// rxSize is in bytes with scaling factors of 1024, need reversion.
-		rxSize = utils.RevertBytes(rxSize / 8)
+	bandwidth := NetworkBandwidth{
+		Ingress: fc.config.RxRateLimiterMaxRate,
+		Egress:  fc.config.TxRateLimiterMaxRate,
+	}
+	if netPair := endpoint.NetworkPair(); netPair != nil {
+		bandwidth = bandwidth.limit(netPair.Bandwidth)
+	}
 
-		iRefillTime := int64(refillTime)
-		iRxSize := int64(rxSize)
-		rxTokenBucket := models.TokenBucket{
-			RefillTime: &iRefillTime,
-			Size:       &iRxSize,
-		}
-		rxRateLimiter = models.RateLimiter{
-			Bandwidth: &rxTokenBucket,
-		}
+	if bandwidth.Ingress > 0 {
+		fc.Logger().Info("Add rx rate limiter")
 	}
+	rxRateLimiter := fcRateLimiter(bandwidth.Ingress)
 
-	var txRateLimiter models.RateLimiter
-	txSize := fc.config.TxRateLimiterMaxRate
-	if txSize > 0 {
+	if bandwidth.Egress > 0 {
 		fc.Logger().Info("Add tx rate limiter")
-
-		// kata-defined txSize is in bits with scaling factors of 1000, but firecracker-defined
-		// txSize is in bytes with scaling factors of 1024, need reversion.
-		txSize = utils.RevertBytes(txSize / 8)
-		iRefillTime := int64(refillTime)
-		iTxSize := int64(txSize)
-		txTokenBucket := models.TokenBucket{
-			RefillTime: &iRefillTime,
-			Size:       &iTxSize,
-		}
-		txRateLimiter = models.RateLimiter{
-			Bandwidth: &txTokenBucket,
-		}
 	}
+	txRateLimiter := fcRateLimiter(bandwidth.Egress)
 
 	ifaceCfg := &models.NetworkInterface{
 		GuestMac:      endpoint.HardwareAddr(),
@@ -995,6 +970,64 @@ func (fc *firecracker) fcAddNetDevice(ctx context.Context, endpoint Endpoint) {
 	fc.fcConfig.NetworkInterfaces = append(fc.fcConfig.NetworkInterfaces, ifaceCfg)
 }
 
+// fcRateLimiter returns the firecracker rate limiter enforcing maxRate, in
+// bits per second. A zero rate means no limit.
+func fcRateLimiter(maxRate uint64) models.RateLimiter {
+	var rateLimiter models.RateLimiter
+	if maxRate == 0 {
+		return rateLimiter
+	}
+
+	// The implementation of rate limiter is based on TBF.
+	// Rate Limiter defines a token bucket with a maximum capacity (size) to store tokens, and an interval for refilling purposes (refill_time).
+	// The refill-rate is derived from size and refill_time, and it is the constant rate at which the tokens replenish.
+	refillTime := int64(utils.DefaultRateLimiterRefillTimeMilliSecs)
+
+	// kata-defined rate is in bits with scaling factors of 1000, but firecracker-defined
+	// size is in bytes with scaling factors of 1024, need reversion.
+	size := int64(utils.RevertBytes(maxRate / 8))
+	rateLimiter.Bandwidth = &models.TokenBucket{
+		RefillTime: &refillTime,
+		Size:       &size,
+	}
+
+	return rateLimiter
+}
+
+// Firecracker supports updating the rate limiters of a network interface
+// once the VM has booted up.
+// ResizeBlockDevice makes firecracker rescan the backing file of a drive,
+// which must already have been grown to size bytes on the host.
+func (fc *firecracker) ResizeBlockDevice(ctx context.Context, drive *config.BlockDrive, size uint64) error {
+	span, _ := katatrace.Trace(ctx, fc.Logger(), "ResizeBlockDevice", fcTracingTags, map[string]string{"sandbox_id": fc.id})
+	defer span.End()
+
+	return fc.fcUpdateBlockDrive(ctx, drive.File, fcDriveIndexToID(drive.Index))
+}
+
+func (fc *firecracker) UpdateNetRateLimiter(ctx context.Context, endpoint Endpoint, bandwidth NetworkBandwidth) error {
+	span, _ := katatrace.Trace(ctx, fc.Logger(), "UpdateNetRateLimiter", fcTracingTags, map[string]string{"sandbox_id": fc.id})
+	defer span.End()
+
+	ifaceID := endpoint.Name()
+	rxRateLimiter := fcRateLimiter(bandwidth.Ingress)
+	txRateLimiter := fcRateLimiter(bandwidth.Egress)
+
+	ifaceParams := ops.NewPatchGuestNetworkInterfaceByIDParams()
+	ifaceParams.SetIfaceID(ifaceID)
+	ifaceParams.SetBody(&models.PartialNetworkInterface{
+		IfaceID:       &ifaceID,
+		RxRateLimiter: &rxRateLimiter,
+		TxRateLimiter: &txRateLimiter,
+	})
+
+	if _, err := fc.client(ctx).Operations.PatchGuestNetworkInterfaceByID(ifaceParams); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (fc *firecracker) fcAddBlockDrive(ctx context.Context, drive config.BlockDrive) error {
 	span, _ := katatrace.Trace(ctx, fc.Logger(), "fcAddBlockDrive", fcTracingTags, map[string]string{"sandbox_id": fc.id})
 	defer span.End()
@@ -1146,6 +1179,10 @@ func (fc *firecracker) HotplugRemoveDevice(ctx context.Context, devInfo interfac
 	}
 }
 
+func (fc *firecracker) IsDevicePlugged(ctx context.Context, devInfo interface{}, devType DeviceType) (bool, error) {
+	return false, fmt.Errorf("Could not query device: unsupported device type '%v'", devType)
+}
+
 // GetVMConsole builds the path of the console where we can read logs coming
 // from the sandbox.
 func (fc *firecracker) GetVMConsole(ctx context.Context, id string) (string, string, error) {
@@ -13,6 +13,7 @@ import (
 	"testing"
 
 	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/types"
+	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -151,6 +152,18 @@ func TestFCIsRateLimiterBuiltin(t *testing.T) {
 	assert.True(rl)
 }
 
+func TestFCRateLimiter(t *testing.T) {
+	assert := assert.New(t)
+
+	rl := fcRateLimiter(0)
+	assert.Nil(rl.Bandwidth)
+
+	rl = fcRateLimiter(8000)
+	assert.NotNil(rl.Bandwidth)
+	assert.Equal(int64(utils.RevertBytes(1000)), *rl.Bandwidth.Size)
+	assert.Equal(int64(utils.DefaultRateLimiterRefillTimeMilliSecs), *rl.Bandwidth.RefillTime)
+}
+
 func TestFCCheck(t *testing.T) {
 	assert := assert.New(t)
 
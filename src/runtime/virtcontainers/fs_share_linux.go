@@ -16,6 +16,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
 	"syscall"
@@ -47,13 +48,6 @@ var defaultKubernetesRootDir = "/var/lib/kubelet"
 // /var/lib/kubelet/pods/f51ae853-557e-4ce1-b60b-a1101b555612/volumes/kubernetes.io~downward-api
 var configVolRegexString = "/pods/[a-fA-F0-9\\-]{36}/volumes/kubernetes\\.io~(configmap|secret|projected|downward-api)"
 
-// Regex for the temp directory with timestamp that is used to handle the updates by K8s
-// Examples
-// /var/lib/kubelet/pods/e33907eb-54c7-4113-a3dc-447f247084cc/volumes/kubernetes.io~secret/foosecret/..2023_07_27_07_13_00.1257228
-// /var/lib/kubelet/pods/e33907eb-54c7-4113-a3dc-447f247084cc/volumes/kubernetes.io~downward-api/fooinfo/..2023_07_27_07_13_00.3704578339
-// The timestamp is of the format 2023_07_27_07_13_00.3704578339 or 2023_07_27_07_13_00.1257228
-var timestampDirRegexString = ".*[0-9]{4}_[0-9]{2}_[0-9]{2}_[0-9]{2}_[0-9]{2}_[0-9]{2}.[0-9]+$"
-
 func unmountNoFollow(path string) error {
 	return syscall.Unmount(path, syscall.MNT_DETACH|UmountNoFollow)
 }
@@ -82,11 +76,8 @@ type FilesystemShare struct {
 	watcher *fsnotify.Watcher
 	// Regex to match directory structure for k8's volume mounts.
 	configVolRegex *regexp.Regexp
-	// Regex to match only the timestamped directory inside the k8's volume mount
-	timestampDirRegex *regexp.Regexp
-	// The same volume mount can be shared by multiple containers in the same sandbox (pod)
-	srcDstMap            map[string][]string
-	srcDstMapLock        sync.Mutex
+	// volumeSync syncs the k8's volume mounts updates to the guest
+	volumeSync           *configVolumeSync
 	eventLoopStarted     bool
 	eventLoopStartedLock sync.Mutex
 	watcherDoneChannel   chan bool
@@ -107,17 +98,17 @@ func NewFilesystemShare(s *Sandbox) (*FilesystemShare, error) {
 	kubernetesRootDir := resolveRootDirWithBase(baseRoot)
 	quotedRoot := regexp.QuoteMeta(kubernetesRootDir)
 	configVolRegex := regexp.MustCompile("^" + quotedRoot + configVolRegexString)
-	timestampDirRegex := regexp.MustCompile("^" + quotedRoot + configVolRegexString + timestampDirRegexString)
 
-	return &FilesystemShare{
+	f := &FilesystemShare{
 		prepared:           false,
 		sandbox:            s,
 		watcherDoneChannel: make(chan bool),
-		srcDstMap:          make(map[string][]string),
 		watcher:            watcher,
 		configVolRegex:     configVolRegex,
-		timestampDirRegex:  timestampDirRegex,
-	}, nil
+	}
+	f.volumeSync = newConfigVolumeSync(s, f.watchDir, f.Logger())
+
+	return f, nil
 }
 
 // Logger returns a logrus logger appropriate for logging filesystem sharing messages
@@ -320,6 +311,24 @@ func (f *FilesystemShare) ShareFile(ctx context.Context, c *Container, m *Mount)
 		var ignored bool
 		srcRoot := filepath.Clean(m.Source)
 
+		// The configmap, secret, projected and downward-api volumes are
+		// kept in sync with their updates by the kubelet.
+		// The cm dir is of the form /var/lib/kubelet/pods/<uid>/volumes/kubernetes.io~configmap/foo/{..data, key1, key2,...}
+		// The secret dir is of the form /var/lib/kubelet/pods/<uid>/volumes/kubernetes.io~secret/foo/{..data, key1, key2,...}
+		// The projected dir is of the form /var/lib/kubelet/pods/<uid>/volumes/kubernetes.io~projected/foo/{..data, key1, key2,...}
+		// The downward-api dir is of the form /var/lib/kubelet/pods/<uid>/volumes/kubernetes.io~downward-api/foo/{..data, key1, key2,...}
+		if f.configVolRegex.MatchString(srcRoot) && isConfigVolume(srcRoot) {
+			f.Logger().Infof("ShareFile: Syncing config volume from src (%s) to dest (%s)", srcRoot, guestPath)
+			if err := f.volumeSync.add(ctx, srcRoot, guestPath); err != nil {
+				c.Logger().WithField("failed-file", m.Source).Debugf("failed to sync config volume to sandbox: %v", err)
+				return nil, err
+			}
+
+			return &SharedFile{
+				guestPath: guestPath,
+			}, nil
+		}
+
 		walk := func(srcPath string, d fs.DirEntry, err error) error {
 
 			if err != nil {
@@ -352,36 +361,6 @@ func (f *FilesystemShare) ShareFile(ctx context.Context, c *Container, m *Mount)
 				f.Logger().WithError(err).Error("Failed to copy file")
 				return err
 			}
-
-			if f.configVolRegex.MatchString(srcPath) {
-				// fsNotify doesn't add watcher recursively.
-				// So we need to add the watcher for directories under kubernetes.io~configmap, kubernetes.io~secret,
-				// kubernetes.io~downward-api and kubernetes.io~projected
-
-				// Add watcher only to the timestamped directory containing secrets to prevent
-				// multiple events received from also watching the parent directory.
-				if info.Mode().IsDir() && f.timestampDirRegex.MatchString(srcPath) {
-					// The cm dir is of the form /var/lib/kubelet/pods/<uid>/volumes/kubernetes.io~configmap/foo/{..data, key1, key2,...}
-					// The secret dir is of the form /var/lib/kubelet/pods/<uid>/volumes/kubernetes.io~secret/foo/{..data, key1, key2,...}
-					// The projected dir is of the form /var/lib/kubelet/pods/<uid>/volumes/kubernetes.io~projected/foo/{..data, key1, key2,...}
-					// The downward-api dir is of the form /var/lib/kubelet/pods/<uid>/volumes/kubernetes.io~downward-api/foo/{..data, key1, key2,...}
-					f.Logger().Infof("ShareFile: srcPath(%s) is a directory", srcPath)
-					err := f.watchDir(srcPath)
-					if err != nil {
-						f.Logger().WithError(err).Error("Failed to watch directory")
-						return err
-					}
-				} else {
-					f.Logger().Infof("ShareFile: srcPath(%s) is not a timestamped directory", srcPath)
-				}
-				// Add the source and destination to the global map which will be used by the event loop
-				// to copy the modified content to the destination
-				f.Logger().Infof("ShareFile: Adding srcPath(%s) dstPath(%s) to srcDstMap", srcPath, dstPath)
-				// Lock the map before adding the entry
-				f.srcDstMapLock.Lock()
-				defer f.srcDstMapLock.Unlock()
-				f.srcDstMap[srcPath] = append(f.srcDstMap[srcPath], dstPath)
-			}
 			return nil
 		}
 
@@ -571,6 +550,13 @@ func (f *FilesystemShare) shareRootFilesystemWithErofs(ctx context.Context, c *C
 			vol.Options = append(vol.Options, "ro")
 			vol.MountPoint = filepath.Join(defaultKataGuestVirtualVolumedir, filename)
 			c.devices[i].ContainerPath = vol.MountPoint
+			// Identical layers of the image share their device, keep
+			// the top-most one only.
+			if slices.ContainsFunc(rootFsStorages, func(s *grpc.Storage) bool {
+				return s.MountPoint == vol.MountPoint
+			}) {
+				continue
+			}
 			rootFsStorages = append(rootFsStorages, vol)
 			// ref: https://github.com/containerd/containerd/blob/v2.2.0/plugins/snapshots/erofs/erofs.go#L161
 		} else if filepath.Base(d.ContainerPath) == "rwlayer.img" {
@@ -832,201 +818,41 @@ func (f *FilesystemShare) StartFileEventWatcher(ctx context.Context) error {
 	f.eventLoopStarted = true
 	f.eventLoopStartedLock.Unlock()
 
-	f.Logger().Debugf("StartFileEventWatcher: srcDstMap dump %v", f.srcDstMap)
-
 	for {
 		select {
 		case event, ok := <-f.watcher.Events:
 			if !ok {
 				return fmt.Errorf("StartFileEventWatcher: watcher events channel closed")
 			}
-			f.Logger().Infof("StartFileEventWatcher: got an event %s %s", event.Op, event.Name)
-			if event.Op&fsnotify.Remove == fsnotify.Remove {
-				// Ref: (kubernetes) pkg/volume/util/atomic_writer.go to understand the configmap/secret update algo
-				//
-				// Write does an atomic projection of the given payload into the writer's target
-				// directory.  Input paths must not begin with '..'.
-				//
-				// The Write algorithm is:
-				//
-				//  1.  The payload is validated; if the payload is invalid, the function returns
-				//  2.  The current timestamped directory is detected by reading the data directory
-				//      symlink
-				//  3.  The old version of the volume is walked to determine whether any
-				//      portion of the payload was deleted and is still present on disk.
-				//  4.  The data in the current timestamped directory is compared to the projected
-				//      data to determine if an update is required.
-				//  5.  A new timestamped dir is created
-				//  6.  The payload is written to the new timestamped directory
-				//  7.  Symlinks and directory for new user-visible files are created (if needed).
-				//
-				//      For example, consider the files:
-				//        <target-dir>/podName
-				//        <target-dir>/user/labels
-				//        <target-dir>/k8s/annotations
-				//
-				//      The user visible files are symbolic links into the internal data directory:
-				//        <target-dir>/podName         -> ..data/podName
-				//        <target-dir>/usr -> ..data/usr
-				//        <target-dir>/k8s -> ..data/k8s
-				//
-				//      The data directory itself is a link to a timestamped directory with
-				//      the real data:
-				//        <target-dir>/..data          -> ..2016_02_01_15_04_05.12345678/
-				//  8.  A symlink to the new timestamped directory ..data_tmp is created that will
-				//      become the new data directory
-				//  9.  The new data directory symlink is renamed to the data directory; rename is atomic
-				// 10.  Old paths are removed from the user-visible portion of the target directory
-				// 11.  The previous timestamped directory is removed, if it exists
-
-				// In this code, we are relying on the REMOVE event to initate a copy of the updated data.
-				// This ensures that the required data is updated and available for copying.
-				// For REMOVE event, the event.Name (source) will be of the form:
-				// /var/lib/kubelet/pods/<uid>/volumes/<k8s-special-dir>/foo/..2023_02_11_09_21_08.2202253910
-				// For example, the event.Name (source) for configmap update will like this:
-				// /var/lib/kubelet/pods/b44e3261-7cf0-48d3-83b4-6094bba95dc8/volumes/kubernetes.io~configmap/foo/..2023_02_11_09_21_08.2202253910
-
-				source := event.Name
-				f.Logger().Infof("StartFileEventWatcher: source for the event: %s", source)
-				if f.timestampDirRegex.FindString(source) != "" {
-					// This block will be entered when the timestamped directory is removed.
-					// This also indicates that foo/..data contains the updated info
-
-					volumeDir := filepath.Dir(source)
-					f.Logger().Infof("StartFileEventWatcher: volumeDir (%s)", volumeDir)
-					// eg. volumDir = /var/lib/kubelet/pods/b44e3261-7cf0-48d3-83b4-6094bba95dc8/volumes/kubernetes.io~configmap/foo
-
-					dataDir := filepath.Join(volumeDir, "..data")
-					f.Logger().Infof("StartFileEventWatcher: dataDir (%s)", dataDir)
-					// eg. dataDir = /var/lib/kubelet/pods/b44e3261-7cf0-48d3-83b4-6094bba95dc8/volumes/kubernetes.io~configmap/foo/..data
-
-					// Handle different destination for the same source
-					// Acquire srcDstMapLock before reading srcDstMap
-					f.srcDstMapLock.Lock()
-					for _, destination := range f.srcDstMap[dataDir] {
-						f.Logger().Infof("StartFileEventWatcher: Copy file from src (%s) to dst (%s)", dataDir, destination)
-						// We explicitly ignore any errors here. Copy will continue for other files
-						// Errors are logged in the copyFilesFromDataDir method
-						_ = f.copyUpdatedFiles(dataDir, destination, source)
-					}
-					f.srcDstMapLock.Unlock()
-				}
-			}
+			f.Logger().Debugf("StartFileEventWatcher: got an event %s %s", event.Op, event.Name)
+
+			// Ref: (kubernetes) pkg/volume/util/atomic_writer.go to understand the configmap/secret update algo
+			//
+			// The data of the volume is written to a new timestamped directory, the
+			// ..data symlink is swapped to it through a rename, and the previous
+			// timestamped directory is removed. Each step is an event in the volume
+			// directory, they are gathered and the volume is synced once.
+			f.volumeSync.notify(event.Name)
 		case err, ok := <-f.watcher.Errors:
 			if !ok {
 				return fmt.Errorf("StartFileEventWatcher: watcher error channel closed")
 			}
 			// We continue explicitly here to avoid exiting the watcher loop
 			f.Logger().Infof("StartFileEventWatcher: got an error event (%v)", err)
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				// Events were lost, sync all the volumes.
+				f.volumeSync.resync()
+			}
 			continue
 		case <-f.watcherDoneChannel:
 			f.Logger().Info("StartFileEventWatcher: watcher closed")
+			f.volumeSync.stop()
 			f.watcher.Close()
 			return nil
 		}
 	}
 }
 
-func (f *FilesystemShare) copyUpdatedFiles(src, dst, oldtsDir string) error {
-	f.Logger().Infof("copyUpdatedFiles: Copy src:%s to dst:%s from old src:%s", src, dst, oldtsDir)
-
-	// 1. Read the symlink and get the actual data directory
-	// Get the symlink target
-	// eg. srcdir = ..2023_02_09_06_40_51.2326009790
-	srcnewtsdir, err := os.Readlink(src)
-	if err != nil {
-		f.Logger().WithError(err).Errorf("copyUpdatedFiles: Reading data symlink %s returned error", src)
-		return err
-	}
-
-	// 2. Construct the path to new timestamped directory in host
-	srcBasePath := filepath.Dir(src)
-	srcNewTsPath := filepath.Join(srcBasePath, srcnewtsdir)
-
-	// 3. Construct the path to copy new timestamped directory in guest
-	dstBasePath := filepath.Dir(dst)
-	dstNewTsPath := filepath.Join(dstBasePath, srcnewtsdir)
-
-	// 4. Create a hashmap to add newly added secrets (not present in the old ts directory)
-	// for creating user visible symlinks
-	newSecrets := make(map[string]string)
-
-	f.Logger().Infof("copyUpdatedFiles: new src dir: %s && new dst dir:%s", srcNewTsPath, dstNewTsPath)
-
-	// 5. Copy all the files from the new timestamped directory to the guest
-	walk := func(srcPath string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-
-		info, err := d.Info()
-		if err != nil {
-			return err
-		}
-		dstPath := dstNewTsPath
-		if !info.Mode().IsDir() {
-			// Construct the path for the files to be copied to.
-			dstPath = filepath.Join(dstPath, filepath.Base(srcPath))
-
-			// Determine if this secret was present in the old timestamped directory.
-			// If not, add it to the newSecrets map to create user visible symlinks.
-			oldSecret := filepath.Join(oldtsDir, filepath.Base(srcPath))
-			if _, ok := f.srcDstMap[oldSecret]; !ok {
-				// these are symlinks to '..data' inside the k8's volume
-				symlinkSrc := filepath.Join(filepath.Dir(srcNewTsPath), filepath.Base(srcPath))
-				symlinkDst := filepath.Join(filepath.Dir(dstNewTsPath), filepath.Base(srcPath))
-				newSecrets[symlinkSrc] = symlinkDst
-			}
-		}
-
-		err = f.sandbox.agent.copyFile(context.Background(), srcPath, dstPath)
-		if err != nil {
-			f.Logger().WithError(err).Error("Failed to copy file")
-			return err
-		}
-
-		// Create a new entry in the globalMap to be used in the event loop
-		f.Logger().Infof("copyUpdatedFiles: Adding srcPath(%s) dstPath(%s) to srcDstMap", srcPath, dstPath)
-		f.srcDstMap[srcPath] = append(f.srcDstMap[srcPath], dstPath)
-		return nil
-	}
-
-	if err := filepath.WalkDir(srcNewTsPath, walk); err != nil {
-		f.Logger().WithError(err).Error("copyUpdatedFiles: failed to copy files.")
-		return err
-	}
-
-	// 6. Add watcher to the new timestamped directory in host
-	err = f.watchDir(srcNewTsPath)
-	if err != nil {
-		f.Logger().WithError(err).Error("copyUpdatedFiles: Failed to add watcher on new ts source.")
-		return err
-	}
-
-	// 7. Update the '..data' symlink to fix user visible files
-	srcDataPath := filepath.Join(filepath.Dir(srcNewTsPath), "..data")
-	dstDataPath := filepath.Join(filepath.Dir(dstNewTsPath), "..data")
-	err = f.sandbox.agent.copyFile(context.Background(), srcDataPath, dstDataPath)
-	if err != nil {
-		f.Logger().WithError(err).Errorf("copyUpdatedFiles: Failed to update data symlink")
-		return err
-	}
-
-	// 8. Create user visible symlinks for any newly created secrets
-	// For existing secrets, the update to '..data' symlink above will fix the user visible files.
-	// TODO: For deleted secrets, the existing symlink will point to non-existing entity after
-	// update to '..data' symlink. Since there is NO DELETE-API in agent, the symlinks will exist
-	for k, v := range newSecrets {
-		err = f.sandbox.agent.copyFile(context.Background(), k, v)
-		if err != nil {
-			f.Logger().WithError(err).Error("copyUpdatedFiles: Failed to copy newly created secret")
-			return err
-		}
-	}
-
-	return nil
-}
-
 func (f *FilesystemShare) StopFileEventWatcher(ctx context.Context) {
 
 	f.Logger().Info("StopFileEventWatcher: Closing watcher")
@@ -0,0 +1,546 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	grpcStatus "google.golang.org/grpc/status"
+)
+
+const (
+	// k8sVolumeDataDir is the symlink to the timestamped directory holding
+	// the data of a kubernetes configmap, secret, projected or downward-api
+	// volume, swapped by the kubelet on updates.
+	k8sVolumeDataDir = "..data"
+
+	// configVolumeSyncBatch is how long the changes to the config volumes
+	// are gathered before they are synced, an update by the kubelet being a
+	// burst of events.
+	configVolumeSyncBatch = 100 * time.Millisecond
+)
+
+// configVolumeSync syncs the kubernetes config volumes, configmaps, secrets,
+// projected and downward-api volumes, to the guest when the file system is
+// not shared with it.
+//
+// The guest copy of a volume mirrors its host layout: the user visible files
+// link to the ..data symlink, which links to a timestamped directory with the
+// actual data. When the kubelet updates the volume, the new timestamped
+// directory is cloned in the guest from the previous one, the files which
+// changed are copied to it and the ones which were removed are removed from
+// it, then ..data is swapped and the previous directory removed.
+type configVolumeSync struct {
+	sandbox *Sandbox
+	// watch adds a watch on a host directory
+	watch  func(path string) error
+	logger *logrus.Entry
+
+	// batch is how long the changes are gathered before they are synced
+	batch time.Duration
+
+	// oldAgent is set once the agent turns out not to support the clone
+	// directory and remove path requests, the volumes being then copied
+	// in full on updates and the stale entries left behind.
+	oldAgent bool
+
+	// volumes are the guest copies of the volumes synced, by host volume
+	// directory. The lock is held while volumes are synced.
+	volumes map[string][]*syncedVolume
+	sync.Mutex
+
+	// pending are the volumes changed since they were last synced, with
+	// the time of their first change.
+	pending     map[string]time.Time
+	timer       *time.Timer
+	pendingLock sync.Mutex
+}
+
+// syncedVolume is the state of a guest copy of a config volume, there is one
+// per container mounting the volume.
+type syncedVolume struct {
+	// destination is the guest directory the volume is copied to
+	destination string
+	// dataDir is the timestamped directory last synced
+	dataDir string
+	// files are the files of the timestamped directory last synced, by
+	// path relative to it
+	files map[string]configVolumeFile
+	// visible are the user visible entries of the volume synced
+	visible map[string]struct{}
+}
+
+func newConfigVolumeSync(sandbox *Sandbox, watch func(string) error, logger *logrus.Entry) *configVolumeSync {
+	return &configVolumeSync{
+		sandbox: sandbox,
+		watch:   watch,
+		logger:  logger.WithField("subsystem", "config_volume_sync"),
+		batch:   configVolumeSyncBatch,
+		volumes: make(map[string][]*syncedVolume),
+		pending: make(map[string]time.Time),
+	}
+}
+
+// isConfigVolume returns whether the host directory is a config volume
+// updated by the kubelet through the ..data symlink swap.
+func isConfigVolume(source string) bool {
+	info, err := os.Lstat(filepath.Join(source, k8sVolumeDataDir))
+	return err == nil && info.Mode()&os.ModeSymlink != 0
+}
+
+// add copies the config volume to the guest directory, and syncs it on
+// updates from then on.
+func (s *configVolumeSync) add(ctx context.Context, source, destination string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	// Watch the volume directory itself, the kubelet swaps the ..data
+	// symlink in it on updates.
+	if _, ok := s.volumes[source]; !ok {
+		if err := s.watch(source); err != nil {
+			return err
+		}
+	}
+
+	dataDir, files, err := readConfigVolume(source)
+	if err != nil {
+		return err
+	}
+
+	v := &syncedVolume{destination: destination}
+	if err := s.syncVolume(ctx, source, dataDir, files, v); err != nil {
+		return err
+	}
+	s.volumes[source] = append(s.volumes[source], v)
+
+	return nil
+}
+
+// notify records a change of the host path, which is synced with the other
+// changes of the batch.
+func (s *configVolumeSync) notify(path string) {
+	// Only the volume directories are watched, the volumes which are not
+	// synced anymore are skipped when the batch is synced.
+	source := filepath.Dir(path)
+
+	s.pendingLock.Lock()
+	defer s.pendingLock.Unlock()
+
+	if _, ok := s.pending[source]; !ok {
+		s.pending[source] = time.Now()
+	}
+	s.scheduleLocked()
+}
+
+// resync syncs all the volumes, when changes may have been missed.
+func (s *configVolumeSync) resync() {
+	s.Lock()
+	sources := make([]string, 0, len(s.volumes))
+	for source := range s.volumes {
+		sources = append(sources, source)
+	}
+	s.Unlock()
+
+	s.logger.WithField("volumes", len(sources)).Warn("resyncing all the config volumes")
+
+	s.pendingLock.Lock()
+	defer s.pendingLock.Unlock()
+
+	now := time.Now()
+	for _, source := range sources {
+		if _, ok := s.pending[source]; !ok {
+			s.pending[source] = now
+		}
+	}
+	s.scheduleLocked()
+}
+
+// scheduleLocked schedules the sync of the pending volumes at the end of the
+// batch, the pending lock must be held.
+func (s *configVolumeSync) scheduleLocked() {
+	if s.timer == nil && len(s.pending) > 0 {
+		s.timer = time.AfterFunc(s.batch, s.flush)
+	}
+}
+
+// stop cancels the sync of the pending volumes.
+func (s *configVolumeSync) stop() {
+	s.pendingLock.Lock()
+	defer s.pendingLock.Unlock()
+
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.pending = make(map[string]time.Time)
+}
+
+// flush syncs the pending volumes.
+func (s *configVolumeSync) flush() {
+	s.pendingLock.Lock()
+	pending := s.pending
+	s.pending = make(map[string]time.Time)
+	s.timer = nil
+	s.pendingLock.Unlock()
+
+	s.Lock()
+	defer s.Unlock()
+
+	sources := make([]string, 0, len(pending))
+	for source := range pending {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	for _, source := range sources {
+		if err := s.syncSource(context.Background(), source); err != nil {
+			// We explicitly ignore the errors here, the volume is
+			// synced again on its next change.
+			s.logger.WithError(err).WithField("source", source).Error("failed to sync config volume")
+			continue
+		}
+
+		configVolumeSyncLag.Observe(time.Since(pending[source]).Seconds())
+	}
+}
+
+// syncSource syncs the guest copies of the volume, the lock must be held.
+func (s *configVolumeSync) syncSource(ctx context.Context, source string) error {
+	volumes := s.volumes[source]
+	if len(volumes) == 0 {
+		return nil
+	}
+
+	dataDir, files, err := readConfigVolume(source)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range volumes {
+		if err := s.syncVolume(ctx, source, dataDir, files, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readConfigVolume returns the timestamped directory the ..data symlink of the
+// volume links to, and its files.
+func readConfigVolume(source string) (string, map[string]configVolumeFile, error) {
+	dataLink := filepath.Join(source, k8sVolumeDataDir)
+	target, err := os.Readlink(dataLink)
+	if err != nil {
+		return "", nil, fmt.Errorf("reading config volume data symlink %s: %w", dataLink, err)
+	}
+	dataDir := filepath.Base(target)
+
+	files, err := hashConfigVolumeFiles(filepath.Join(source, dataDir))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return dataDir, files, nil
+}
+
+// syncVolume syncs the files of the timestamped directory to the guest copy of
+// the volume, then the user visible entries and the ..data symlink.
+func (s *configVolumeSync) syncVolume(ctx context.Context, source, dataDir string, files map[string]configVolumeFile, v *syncedVolume) error {
+	if v.dataDir != "" && sameConfigVolumeFiles(files, v.files) {
+		// The kubelet projected the same data again, the guest copy
+		// is up to date.
+		s.logger.WithFields(logrus.Fields{
+			"source":      source,
+			"destination": v.destination,
+		}).Debug("config volume unchanged, skipping sync")
+		return nil
+	}
+
+	logger := s.logger.WithFields(logrus.Fields{
+		"source":      source,
+		"destination": v.destination,
+		"data-dir":    dataDir,
+	})
+
+	// The new timestamped directory starts as a clone of the previous
+	// one, so that only the changes are sent to the guest.
+	previousDataDir := ""
+	synced := v.files
+	if dataDir != v.dataDir && v.dataDir != "" {
+		previousDataDir = v.dataDir
+		cloned, err := s.cloneInGuest(ctx, v, filepath.Join(source, dataDir), dataDir, previousDataDir)
+		if err != nil {
+			return err
+		}
+		if !cloned {
+			synced = nil
+		}
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	// Parent directories sort before their content.
+	sort.Strings(paths)
+
+	copied := 0
+	for _, path := range paths {
+		file := files[path]
+		if previous, ok := synced[path]; ok && previous == file {
+			continue
+		}
+
+		if err := s.copyToGuest(ctx, v, filepath.Join(source, dataDir, path), filepath.Join(dataDir, path)); err != nil {
+			return err
+		}
+		if !file.isDir {
+			copied++
+		}
+	}
+
+	// Remove the files of the keys removed, the content of a directory
+	// removed or replaced by a file being gone with it.
+	var removedPaths []string
+	for path := range synced {
+		if _, ok := files[path]; ok {
+			continue
+		}
+		if parent := filepath.Dir(path); parent != "." && !files[parent].isDir {
+			continue
+		}
+		removedPaths = append(removedPaths, path)
+	}
+	sort.Strings(removedPaths)
+
+	for _, path := range removedPaths {
+		if err := s.removeInGuest(ctx, v, filepath.Join(dataDir, path)); err != nil {
+			return err
+		}
+	}
+
+	// Copy the user visible entries which are new, the existing ones link
+	// to ..data already.
+	entries, err := os.ReadDir(source)
+	if err != nil {
+		return err
+	}
+
+	visible := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, "..") {
+			continue
+		}
+
+		visible[name] = struct{}{}
+		if _, ok := v.visible[name]; ok {
+			continue
+		}
+
+		if err := s.copyToGuest(ctx, v, filepath.Join(source, name), name); err != nil {
+			return err
+		}
+	}
+
+	// Swap ..data, the agent replaces the symlink atomically.
+	if dataDir != v.dataDir {
+		dataLink := filepath.Join(source, k8sVolumeDataDir)
+		if err := s.copyToGuest(ctx, v, dataLink, k8sVolumeDataDir); err != nil {
+			return err
+		}
+	}
+
+	// Then remove the user visible entries of the keys removed, and the
+	// previous timestamped directory.
+	var staleNames []string
+	for name := range v.visible {
+		if _, ok := visible[name]; !ok {
+			staleNames = append(staleNames, name)
+		}
+	}
+	sort.Strings(staleNames)
+	if previousDataDir != "" {
+		staleNames = append(staleNames, previousDataDir)
+	}
+
+	for _, name := range staleNames {
+		if err := s.removeInGuest(ctx, v, name); err != nil {
+			return err
+		}
+	}
+
+	v.dataDir = dataDir
+	v.files = files
+	v.visible = visible
+
+	logger.WithFields(logrus.Fields{
+		"copied":  copied,
+		"removed": len(removedPaths),
+	}).Info("config volume synced")
+
+	return nil
+}
+
+// copyToGuest copies the host file to the path relative to the guest copy of
+// the volume.
+func (s *configVolumeSync) copyToGuest(ctx context.Context, v *syncedVolume, hostPath, path string) error {
+	if err := s.sandbox.agent.copyFile(ctx, hostPath, filepath.Join(v.destination, path)); err != nil {
+		return fmt.Errorf("copying %s to the guest: %w", hostPath, err)
+	}
+	return nil
+}
+
+// cloneInGuest clones the previous timestamped directory of the guest copy of
+// the volume to the new one, with the attributes of the host directory. It
+// returns false when the agent is too old to clone directories, the new
+// directory having then to be copied in full.
+func (s *configVolumeSync) cloneInGuest(ctx context.Context, v *syncedVolume, hostPath, dataDir, previousDataDir string) (bool, error) {
+	if s.oldAgent {
+		return false, nil
+	}
+
+	err := s.sandbox.agent.cloneDir(ctx, hostPath, filepath.Join(v.destination, dataDir), filepath.Join(v.destination, previousDataDir))
+	if err == nil {
+		return true, nil
+	}
+	if grpcStatus.Convert(err).Code() == codes.Unimplemented {
+		s.agentTooOld()
+		return false, nil
+	}
+	return false, fmt.Errorf("cloning %s in the guest: %w", previousDataDir, err)
+}
+
+// removeInGuest removes the path relative to the guest copy of the volume. The
+// path is left in place when the agent is too old to remove it.
+func (s *configVolumeSync) removeInGuest(ctx context.Context, v *syncedVolume, path string) error {
+	if s.oldAgent {
+		return nil
+	}
+
+	if err := s.sandbox.agent.removeFile(ctx, filepath.Join(v.destination, path)); err != nil {
+		if grpcStatus.Convert(err).Code() == codes.Unimplemented {
+			s.agentTooOld()
+			return nil
+		}
+		return fmt.Errorf("removing %s in the guest: %w", path, err)
+	}
+	return nil
+}
+
+func (s *configVolumeSync) agentTooOld() {
+	s.oldAgent = true
+	s.logger.Warn("config volumes cannot be synced incrementally due to old agent, please upgrade Kata Containers image version")
+}
+
+// configVolumeFile is a file of the timestamped directory of a config volume.
+type configVolumeFile struct {
+	// hash is the hash of the file content, mode and ownership
+	hash  string
+	uid   uint32
+	gid   uint32
+	isDir bool
+}
+
+// hashConfigVolumeFiles hashes the files of the timestamped directory, by path
+// relative to it.
+func hashConfigVolumeFiles(dataDir string) (map[string]configVolumeFile, error) {
+	files := make(map[string]configVolumeFile)
+
+	walk := func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path == dataDir {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dataDir, path)
+		if err != nil {
+			return err
+		}
+
+		file := configVolumeFile{isDir: info.IsDir()}
+		if st, ok := info.Sys().(*syscall.Stat_t); ok {
+			file.uid = st.Uid
+			file.gid = st.Gid
+		}
+
+		if !file.isDir {
+			if file.hash, err = hashConfigVolumeFile(path, info, file.uid, file.gid); err != nil {
+				return err
+			}
+		}
+
+		files[rel] = file
+		return nil
+	}
+
+	if err := filepath.WalkDir(dataDir, walk); err != nil {
+		return nil, fmt.Errorf("hashing config volume %s: %w", dataDir, err)
+	}
+
+	return files, nil
+}
+
+func hashConfigVolumeFile(path string, info fs.FileInfo, uid, gid uint32) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%o:%d:%d:", info.Mode(), uid, gid)
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return "", err
+		}
+		io.WriteString(h, target)
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+
+		if _, err := io.Copy(h, f); err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// sameConfigVolumeFiles returns whether the files hashed are the ones synced.
+func sameConfigVolumeFiles(files, synced map[string]configVolumeFile) bool {
+	if len(files) != len(synced) {
+		return false
+	}
+
+	for path, file := range files {
+		if previous, ok := synced[path]; !ok || previous != file {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,261 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	grpcStatus "google.golang.org/grpc/status"
+)
+
+// syncRecordingAgent records the files copied, cloned and removed in the guest.
+type syncRecordingAgent struct {
+	mockAgent
+	copies   []string
+	clones   map[string]string
+	removals []string
+	// oldAgent makes the agent fail the clones and removals as unknown
+	// requests.
+	oldAgent bool
+	sync.Mutex
+}
+
+func (a *syncRecordingAgent) copyFile(ctx context.Context, src, dst string) error {
+	a.Lock()
+	defer a.Unlock()
+	a.copies = append(a.copies, dst)
+	return nil
+}
+
+func (a *syncRecordingAgent) cloneDir(ctx context.Context, src, dst, clone string) error {
+	a.Lock()
+	defer a.Unlock()
+	if a.oldAgent {
+		return grpcStatus.Error(codes.Unimplemented, "method CloneDirectory")
+	}
+	a.clones[dst] = clone
+	return nil
+}
+
+func (a *syncRecordingAgent) removeFile(ctx context.Context, path string) error {
+	a.Lock()
+	defer a.Unlock()
+	if a.oldAgent {
+		return grpcStatus.Error(codes.Unimplemented, "method RemovePath")
+	}
+	a.removals = append(a.removals, path)
+	return nil
+}
+
+func (a *syncRecordingAgent) reset() ([]string, map[string]string, []string) {
+	a.Lock()
+	defer a.Unlock()
+	copies, clones, removals := a.copies, a.clones, a.removals
+	a.copies, a.clones, a.removals = nil, make(map[string]string), nil
+	return copies, clones, removals
+}
+
+// writeConfigVolume projects the files to a new timestamped directory of the
+// volume the way the kubelet does.
+func writeConfigVolume(t *testing.T, volume, dataDir string, files map[string]string) {
+	assert := assert.New(t)
+
+	for path, content := range files {
+		path = filepath.Join(volume, dataDir, path)
+		assert.NoError(os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(os.WriteFile(path, []byte(content), 0644))
+	}
+
+	previous, _ := os.Readlink(filepath.Join(volume, k8sVolumeDataDir))
+
+	dataTmp := filepath.Join(volume, "..data_tmp")
+	assert.NoError(os.Symlink(dataDir, dataTmp))
+	assert.NoError(os.Rename(dataTmp, filepath.Join(volume, k8sVolumeDataDir)))
+
+	entries, err := os.ReadDir(filepath.Join(volume, dataDir))
+	assert.NoError(err)
+	keys := make(map[string]bool)
+	for _, entry := range entries {
+		keys[entry.Name()] = true
+		visible := filepath.Join(volume, entry.Name())
+		if _, err := os.Lstat(visible); err != nil {
+			assert.NoError(os.Symlink(filepath.Join(k8sVolumeDataDir, entry.Name()), visible))
+		}
+	}
+
+	entries, err = os.ReadDir(volume)
+	assert.NoError(err)
+	for _, entry := range entries {
+		if name := entry.Name(); !strings.HasPrefix(name, "..") && !keys[name] {
+			assert.NoError(os.Remove(filepath.Join(volume, name)))
+		}
+	}
+
+	if previous != "" {
+		assert.NoError(os.RemoveAll(filepath.Join(volume, previous)))
+	}
+}
+
+func TestConfigVolumeSync(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	volume := t.TempDir()
+	guest := "/run/kata-containers/shared/containers/volume"
+
+	agent := &syncRecordingAgent{clones: make(map[string]string)}
+	var watched []string
+	s := newConfigVolumeSync(&Sandbox{agent: agent}, func(path string) error {
+		watched = append(watched, path)
+		return nil
+	}, virtLog)
+	s.batch = time.Hour
+
+	writeConfigVolume(t, volume, "..1", map[string]string{"a": "a", "b": "b", "sub/c": "c"})
+	assert.True(isConfigVolume(volume))
+
+	// The volume is copied all at first
+	assert.NoError(s.add(ctx, volume, guest))
+	assert.Equal([]string{volume}, watched)
+
+	copies, clones, removals := agent.reset()
+	assert.Equal([]string{
+		guest + "/..1/a", guest + "/..1/b", guest + "/..1/sub", guest + "/..1/sub/c",
+		guest + "/a", guest + "/b", guest + "/sub",
+		guest + "/..data",
+	}, copies)
+	assert.Empty(clones)
+	assert.Empty(removals)
+
+	// then the new timestamped directory is cloned from the previous one,
+	// only the files which changed are copied to it, and the previous one
+	// is removed once ..data is swapped
+	writeConfigVolume(t, volume, "..2", map[string]string{"a": "a", "b": "B", "sub/c": "c"})
+	s.notify(filepath.Join(volume, k8sVolumeDataDir))
+	s.flush()
+
+	copies, clones, removals = agent.reset()
+	assert.Equal(map[string]string{guest + "/..2": guest + "/..1"}, clones)
+	assert.Equal([]string{guest + "/..2/b", guest + "/..data"}, copies)
+	assert.Equal([]string{guest + "/..1"}, removals)
+
+	// the same data projected again is not synced
+	writeConfigVolume(t, volume, "..3", map[string]string{"a": "a", "b": "B", "sub/c": "c"})
+	s.resync()
+	s.flush()
+
+	copies, clones, removals = agent.reset()
+	assert.Empty(copies)
+	assert.Empty(clones)
+	assert.Empty(removals)
+
+	// the new keys are made visible
+	writeConfigVolume(t, volume, "..4", map[string]string{"a": "a", "b": "B", "sub/c": "c", "d": "d"})
+	s.notify(filepath.Join(volume, k8sVolumeDataDir))
+	s.flush()
+
+	copies, clones, removals = agent.reset()
+	assert.Equal(map[string]string{guest + "/..4": guest + "/..2"}, clones)
+	assert.Equal([]string{guest + "/..4/d", guest + "/d", guest + "/..data"}, copies)
+	assert.Equal([]string{guest + "/..2"}, removals)
+
+	// and the removed keys are removed, along with their visible entries
+	writeConfigVolume(t, volume, "..5", map[string]string{"a": "a", "d": "d"})
+	s.notify(filepath.Join(volume, k8sVolumeDataDir))
+	s.flush()
+
+	copies, clones, removals = agent.reset()
+	assert.Equal(map[string]string{guest + "/..5": guest + "/..4"}, clones)
+	assert.Equal([]string{guest + "/..data"}, copies)
+	assert.Equal([]string{
+		guest + "/..5/b", guest + "/..5/sub",
+		guest + "/b", guest + "/sub",
+		guest + "/..4",
+	}, removals)
+
+	// A second guest copy of the volume is copied all
+	other := "/run/kata-containers/shared/containers/other"
+	assert.NoError(s.add(ctx, volume, other))
+	assert.Equal([]string{volume}, watched)
+
+	copies, clones, _ = agent.reset()
+	assert.Contains(copies, other+"/..5/a")
+	assert.Contains(copies, other+"/..data")
+	assert.Empty(clones)
+}
+
+func TestConfigVolumeSyncOldAgent(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	volume := t.TempDir()
+	guest := "/run/kata-containers/shared/containers/volume"
+
+	agent := &syncRecordingAgent{clones: make(map[string]string), oldAgent: true}
+	s := newConfigVolumeSync(&Sandbox{agent: agent}, func(string) error { return nil }, virtLog)
+	s.batch = time.Hour
+
+	writeConfigVolume(t, volume, "..1", map[string]string{"a": "a", "b": "b"})
+	assert.NoError(s.add(ctx, volume, guest))
+	agent.reset()
+
+	// The new timestamped directory is copied in full when the agent
+	// cannot clone the previous one, which is left in place.
+	writeConfigVolume(t, volume, "..2", map[string]string{"a": "a", "b": "B"})
+	s.notify(filepath.Join(volume, k8sVolumeDataDir))
+	s.flush()
+
+	copies, clones, removals := agent.reset()
+	assert.True(s.oldAgent)
+	assert.Equal([]string{guest + "/..2/a", guest + "/..2/b", guest + "/..data"}, copies)
+	assert.Empty(clones)
+	assert.Empty(removals)
+}
+
+func TestConfigVolumeSyncBatch(t *testing.T) {
+	assert := assert.New(t)
+
+	volume := t.TempDir()
+	guest := "/run/kata-containers/shared/containers/volume"
+
+	agent := &syncRecordingAgent{clones: make(map[string]string)}
+	s := newConfigVolumeSync(&Sandbox{agent: agent}, func(string) error { return nil }, virtLog)
+	s.batch = 10 * time.Millisecond
+
+	writeConfigVolume(t, volume, "..1", map[string]string{"a": "a"})
+	assert.NoError(s.add(context.Background(), volume, guest))
+	agent.reset()
+
+	// The events of an update are synced at once
+	writeConfigVolume(t, volume, "..2", map[string]string{"a": "A"})
+	for _, name := range []string{"..2", "..data_tmp", k8sVolumeDataDir, "..1"} {
+		s.notify(filepath.Join(volume, name))
+	}
+
+	assert.Eventually(func() bool {
+		agent.Lock()
+		defer agent.Unlock()
+		return len(agent.copies) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	s.stop()
+
+	copies, _, _ := agent.reset()
+	assert.Equal([]string{guest + "/..2/a", guest + "/..data"}, copies)
+
+	// The events out of the volumes are ignored
+	s.notify(filepath.Join(t.TempDir(), "file"))
+	s.flush()
+	copies, _, _ = agent.reset()
+	assert.Empty(copies)
+}
@@ -0,0 +1,245 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/api"
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
+	persistapi "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/persist/api"
+	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/types"
+	"github.com/sirupsen/logrus"
+)
+
+// journalHotplug records the hotplug of the device in the hotplug journal
+// before running it, and marks it done once it succeeded. The intent is kept
+// until the sandbox state is saved, so that a hotplug the runtime did not
+// live to save can be reconciled with the VM when the sandbox is fetched.
+func (s *Sandbox) journalHotplug(op persistapi.HotplugOp, device api.Device, devType config.DeviceType, hotplug func() error) error {
+	if devType == config.DeviceGeneric || s.store == nil {
+		return hotplug()
+	}
+
+	intent := &persistapi.HotplugIntent{
+		Op:         op,
+		DeviceType: string(devType),
+		Device:     device.Save(),
+	}
+
+	s.hotplugIntentsLock.Lock()
+	s.hotplugIntents = append(s.hotplugIntents, intent)
+	if err := s.writeHotplugJournal(); err != nil {
+		s.dropHotplugIntent(intent)
+		s.hotplugIntentsLock.Unlock()
+		return fmt.Errorf("failed to journal device hotplug: %v", err)
+	}
+	s.hotplugIntentsLock.Unlock()
+
+	hotplugErr := hotplug()
+
+	s.hotplugIntentsLock.Lock()
+	defer s.hotplugIntentsLock.Unlock()
+
+	if hotplugErr != nil {
+		s.dropHotplugIntent(intent)
+	} else {
+		intent.Done = true
+	}
+	if err := s.writeHotplugJournal(); err != nil {
+		s.Logger().WithError(err).WithField("device", intent.Device.ID).Warn("Could not update hotplug journal")
+	}
+
+	return hotplugErr
+}
+
+// dropHotplugIntent removes the intent from the in-memory journal, the
+// caller holds hotplugIntentsLock.
+func (s *Sandbox) dropHotplugIntent(intent *persistapi.HotplugIntent) {
+	for i, journaled := range s.hotplugIntents {
+		if journaled == intent {
+			s.hotplugIntents = append(s.hotplugIntents[:i], s.hotplugIntents[i+1:]...)
+			return
+		}
+	}
+}
+
+// writeHotplugJournal persists the in-memory journal, the caller holds
+// hotplugIntentsLock.
+func (s *Sandbox) writeHotplugJournal() error {
+	intents := make([]persistapi.HotplugIntent, 0, len(s.hotplugIntents))
+	for _, intent := range s.hotplugIntents {
+		intents = append(intents, *intent)
+	}
+	return s.store.ToJournal(s.id, intents)
+}
+
+// pruneHotplugJournal forgets the hotplugs done, once the sandbox state
+// reflecting them is saved.
+func (s *Sandbox) pruneHotplugJournal() error {
+	s.hotplugIntentsLock.Lock()
+	defer s.hotplugIntentsLock.Unlock()
+
+	var pending []*persistapi.HotplugIntent
+	for _, intent := range s.hotplugIntents {
+		if !intent.Done {
+			pending = append(pending, intent)
+		}
+	}
+	if len(pending) == len(s.hotplugIntents) {
+		return nil
+	}
+
+	s.hotplugIntents = pending
+	return s.writeHotplugJournal()
+}
+
+// reconcileHotplugJournal brings the saved device state in line with the VM
+// for the hotplugs left in the journal by a previous runtime instance. A
+// device plugged but never saved as attached is unplugged again, a device
+// unplugged but still saved as attached is detached. When the VM cannot be
+// queried, the saved device state is kept.
+func (s *Sandbox) reconcileHotplugJournal(ctx context.Context) error {
+	intents, err := s.store.FromJournal(s.id)
+	if err != nil {
+		return err
+	}
+	if len(intents) == 0 {
+		return nil
+	}
+
+	running := s.state.State == types.StateRunning || s.state.State == types.StatePaused
+
+	for i := range intents {
+		intent := &intents[i]
+		logger := s.Logger().WithFields(logrus.Fields{
+			"device": intent.Device.ID,
+			"op":     intent.Op,
+			"done":   intent.Done,
+		})
+
+		if !running {
+			logger.Info("Dropping device hotplug, the VM is not running")
+			continue
+		}
+
+		if err := s.reconcileHotplugIntent(ctx, intent); err != nil {
+			logger.WithError(err).Warn("Could not reconcile device hotplug, keeping the saved device state")
+		}
+	}
+
+	if err := s.Save(); err != nil {
+		return err
+	}
+
+	return s.store.ToJournal(s.id, nil)
+}
+
+func (s *Sandbox) reconcileHotplugIntent(ctx context.Context, intent *persistapi.HotplugIntent) error {
+	devType := config.DeviceType(intent.DeviceType)
+	saved := s.devManager.GetDeviceByID(intent.Device.ID)
+	attached := saved != nil && saved.GetAttachCount() > 0
+
+	switch intent.Op {
+	case persistapi.HotplugAdd:
+		if attached {
+			return nil
+		}
+
+		// The device is only known from the journal, load it to
+		// query it and to unplug it.
+		s.devManager.LoadDevices([]config.DeviceState{intent.Device})
+		device := s.devManager.GetDeviceByID(intent.Device.ID)
+		defer s.restoreSavedDevice(saved, intent.Device)
+
+		plugged, err := s.isDevicePlugged(ctx, device, devType)
+		if err != nil || !plugged {
+			return err
+		}
+
+		s.Logger().WithField("device", intent.Device.ID).Info("Rolling back device hotplug")
+		return s.hotplugRemoveDevice(ctx, device, devType)
+	case persistapi.HotplugRemove:
+		if !attached {
+			return nil
+		}
+
+		plugged, err := s.isDevicePlugged(ctx, saved, devType)
+		if err != nil || plugged {
+			return err
+		}
+
+		s.Logger().WithField("device", intent.Device.ID).Info("Completing device hot unplug")
+		ds := saved.Save()
+		ds.AttachCount = 0
+		s.devManager.LoadDevices([]config.DeviceState{ds})
+		return nil
+	default:
+		return fmt.Errorf("unknown hotplug operation %q", intent.Op)
+	}
+}
+
+// restoreSavedDevice puts back the saved state of a device loaded from the
+// journal, the device is forgotten if it was not saved.
+func (s *Sandbox) restoreSavedDevice(saved api.Device, journaled config.DeviceState) {
+	if saved != nil {
+		s.devManager.LoadDevices([]config.DeviceState{saved.Save()})
+		return
+	}
+
+	journaled.AttachCount = 0
+	journaled.RefCount = 1
+	s.devManager.LoadDevices([]config.DeviceState{journaled})
+	if err := s.devManager.RemoveDevice(journaled.ID); err != nil {
+		s.Logger().WithError(err).WithField("device", journaled.ID).Warn("Could not remove journaled device")
+	}
+}
+
+// isDevicePlugged reports whether the device is plugged in the VM, a VFIO
+// group is plugged as soon as one of its devices is.
+func (s *Sandbox) isDevicePlugged(ctx context.Context, device api.Device, devType config.DeviceType) (bool, error) {
+	switch devType {
+	case config.DeviceVFIO:
+		vfioDevices, ok := device.GetDeviceInfo().([]*config.VFIODev)
+		if !ok {
+			return false, fmt.Errorf("device type mismatch, expect device type to be %s", devType)
+		}
+		for _, dev := range vfioDevices {
+			plugged, err := s.hypervisor.IsDevicePlugged(ctx, dev, VfioDev)
+			if err != nil || plugged {
+				return plugged, err
+			}
+		}
+		return false, nil
+	case config.DeviceBlock:
+		drive, ok := device.GetDeviceInfo().(*config.BlockDrive)
+		if !ok || drive == nil {
+			return false, fmt.Errorf("device type mismatch, expect device type to be %s", devType)
+		}
+		return s.hypervisor.IsDevicePlugged(ctx, drive, BlockDev)
+	case config.DeviceSCSIPassthrough:
+		drive, ok := device.GetDeviceInfo().(*config.SCSIPassthroughDrive)
+		if !ok || drive == nil {
+			return false, fmt.Errorf("device type mismatch, expect device type to be %s", devType)
+		}
+		return s.hypervisor.IsDevicePlugged(ctx, drive, SCSIPassthroughDev)
+	case config.DeviceCharPassthrough:
+		drive, ok := device.GetDeviceInfo().(*config.CharDrive)
+		if !ok || drive == nil {
+			return false, fmt.Errorf("device type mismatch, expect device type to be %s", devType)
+		}
+		return s.hypervisor.IsDevicePlugged(ctx, drive, VirtioSerialPortDev)
+	case config.VhostUserBlk:
+		vhostUserDeviceAttrs, ok := device.GetDeviceInfo().(*config.VhostUserDeviceAttrs)
+		if !ok || vhostUserDeviceAttrs == nil {
+			return false, fmt.Errorf("device type mismatch, expect device type to be %s", devType)
+		}
+		return s.hypervisor.IsDevicePlugged(ctx, vhostUserDeviceAttrs, VhostuserDev)
+	default:
+		return false, fmt.Errorf("cannot query device: unsupported device type %s", devType)
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/api"
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/drivers"
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/manager"
+	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/persist"
+	persistapi "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/persist/api"
+	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newHotplugJournalSandbox(t *testing.T, id string) *Sandbox {
+	network, err := NewNetwork()
+	assert.NoError(t, err)
+
+	store, err := persist.GetDriver()
+	assert.NoError(t, err)
+
+	s := &Sandbox{
+		id:         id,
+		containers: map[string]*Container{},
+		devManager: manager.NewDeviceManager(config.VirtioBlock, false, "", 0, nil, nil, nil),
+		hypervisor: &mockHypervisor{},
+		network:    network,
+		store:      store,
+		ctx:        context.Background(),
+		config:     &SandboxConfig{ID: id},
+		state: types.SandboxState{
+			State:         types.StateRunning,
+			BlockIndexMap: make(map[int]struct{}),
+		},
+	}
+	t.Cleanup(func() { store.Destroy(id) })
+
+	return s
+}
+
+func newHotplugJournalDevice(t *testing.T, s *Sandbox) api.Device {
+	device, err := s.devManager.NewDevice(config.DeviceInfo{
+		HostPath:      "/dev/hda",
+		ContainerPath: "/dev/hda",
+		DevType:       "b",
+	})
+	assert.NoError(t, err)
+
+	device.(*drivers.BlockDevice).BlockDrive = &config.BlockDrive{
+		File: "/dev/hda",
+		ID:   "drive-hda",
+	}
+
+	return device
+}
+
+func TestJournalHotplug(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newHotplugJournalSandbox(t, "test-journal-hotplug")
+	device := newHotplugJournalDevice(t, s)
+
+	// The intent is journaled before the hotplug
+	err := s.journalHotplug(persistapi.HotplugAdd, device, config.DeviceBlock, func() error {
+		intents, err := s.store.FromJournal(s.id)
+		assert.NoError(err)
+		assert.Len(intents, 1)
+		assert.Equal(persistapi.HotplugAdd, intents[0].Op)
+		assert.Equal(string(config.DeviceBlock), intents[0].DeviceType)
+		assert.Equal(device.DeviceID(), intents[0].Device.ID)
+		assert.False(intents[0].Done)
+		return nil
+	})
+	assert.NoError(err)
+
+	// and marked done after it
+	intents, err := s.store.FromJournal(s.id)
+	assert.NoError(err)
+	assert.Len(intents, 1)
+	assert.True(intents[0].Done)
+
+	// A failed hotplug leaves nothing to reconcile
+	hotplugErr := errors.New("hotplug failed")
+	err = s.journalHotplug(persistapi.HotplugRemove, device, config.DeviceBlock, func() error {
+		return hotplugErr
+	})
+	assert.Equal(hotplugErr, err)
+	intents, err = s.store.FromJournal(s.id)
+	assert.NoError(err)
+	assert.Len(intents, 1)
+
+	// Saving the sandbox state commits the hotplugs done
+	assert.NoError(s.Save())
+	intents, err = s.store.FromJournal(s.id)
+	assert.NoError(err)
+	assert.Empty(intents)
+	assert.Empty(s.hotplugIntents)
+
+	// Generic devices are not journaled
+	err = s.journalHotplug(persistapi.HotplugAdd, device, config.DeviceGeneric, func() error {
+		intents, err := s.store.FromJournal(s.id)
+		assert.NoError(err)
+		assert.Empty(intents)
+		return nil
+	})
+	assert.NoError(err)
+}
+
+func TestReconcileHotplugJournalAdd(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, plugged := range []bool{true, false} {
+		s := newHotplugJournalSandbox(t, "test-reconcile-hotplug-add")
+		s.hypervisor.(*mockHypervisor).devicePlugged = plugged
+
+		// The device was attached but the sandbox state never saved
+		device := newHotplugJournalDevice(t, s)
+		ds := device.Save()
+		ds.AttachCount = 1
+		assert.NoError(s.devManager.RemoveDevice(device.DeviceID()))
+		assert.NoError(s.store.ToJournal(s.id, []persistapi.HotplugIntent{
+			{Op: persistapi.HotplugAdd, DeviceType: string(config.DeviceBlock), Device: ds, Done: true},
+		}))
+
+		assert.NoError(s.reconcileHotplugJournal(context.Background()))
+
+		// The device is forgotten, unplugged if it was plugged
+		assert.Nil(s.devManager.GetDeviceByID(ds.ID))
+		intents, err := s.store.FromJournal(s.id)
+		assert.NoError(err)
+		assert.Empty(intents)
+	}
+}
+
+func TestReconcileHotplugJournalRemove(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, tc := range []struct {
+		state       types.StateString
+		plugged     bool
+		attachCount uint
+	}{
+		// Unplugged, the saved state is updated
+		{types.StateRunning, false, 0},
+		// Still plugged, the saved state is kept
+		{types.StateRunning, true, 1},
+		// The VM is gone, nothing to reconcile
+		{types.StateStopped, false, 1},
+	} {
+		s := newHotplugJournalSandbox(t, "test-reconcile-hotplug-remove")
+		s.state.State = tc.state
+		s.hypervisor.(*mockHypervisor).devicePlugged = tc.plugged
+
+		device := newHotplugJournalDevice(t, s)
+		ds := device.Save()
+		ds.AttachCount = 1
+		s.devManager.LoadDevices([]config.DeviceState{ds})
+		assert.NoError(s.store.ToJournal(s.id, []persistapi.HotplugIntent{
+			{Op: persistapi.HotplugRemove, DeviceType: string(config.DeviceBlock), Device: ds},
+		}))
+
+		assert.NoError(s.reconcileHotplugJournal(context.Background()))
+
+		assert.Equal(tc.attachCount, s.devManager.GetDeviceByID(ds.ID).GetAttachCount(), "state %s, plugged %v", tc.state, tc.plugged)
+		intents, err := s.store.FromJournal(s.id)
+		assert.NoError(err)
+		assert.Empty(intents)
+	}
+}
@@ -358,6 +358,14 @@ const (
 	// HybridVirtioVsockDev is a hybrid virtio-vsock device supported
 	// only on certain hypervisors, like firecracker.
 	HybridVirtioVsockDev
+
+	// SCSIPassthroughDev is a host SCSI device passed through to the
+	// guest SCSI bus.
+	SCSIPassthroughDev
+
+	// VirtioSerialPortDev is a virtio-serial port a host character device
+	// is relayed to.
+	VirtioSerialPortDev
 )
 
 type MemoryDevice struct {
@@ -540,6 +548,19 @@ type HypervisorConfig struct {
 	// VirtioFSCache cache mode for fs version cache
 	VirtioFSCache string
 
+	// VirtioFSExternalSocket is the vhost-user-fs socket of the daemon the
+	// operator runs for the virtio-fs-external shared file system, the
+	// {sandbox_id} string in it is replaced by the sandbox ID
+	VirtioFSExternalSocket string
+
+	// VirtioFSExternalRestartHook is run to (re)start the external
+	// vhost-user-fs daemon when its socket is not served
+	VirtioFSExternalRestartHook string
+
+	// VirtioFSExternalHealthCheck is run to check the external
+	// vhost-user-fs daemon, on top of the presence of its socket
+	VirtioFSExternalHealthCheck string
+
 	// File based memory backend root directory
 	FileBackedMemRootDir string
 
@@ -700,12 +721,59 @@ type HypervisorConfig struct {
 	// root port, switch or no port
 	ColdPlugVFIO config.PCIePort
 
+	// VFIOAutoBind binds the VFIO devices to vfio-pci when attaching them,
+	// and back to their host drivers when detaching them.
+	VFIOAutoBind bool
+
+	// HostSysfsRoot is the directory the host /sys and /dev are found
+	// under, e.g. the host root mount point for a runtime running in a
+	// container. The host root is used if empty.
+	HostSysfsRoot string
+
+	// SCSIPassthrough passes the SCSI generic devices, SCSI disks and
+	// multipath devices of the containers through to the guest SCSI bus.
+	SCSIPassthrough bool
+
+	// CharDevicePassthrough lists the host paths, as glob patterns, of the
+	// container character devices relayed to the guest through
+	// virtio-serial ports, e.g. /dev/ttyUSB*.
+	CharDevicePassthrough []string
+
+	// CharDeviceTransport is how the character devices are relayed to the
+	// guest, through virtio-serial ports or over vsock.
+	CharDeviceTransport string
+
+	// EnableTPM attaches a vTPM to the VM, backed by a swtpm process
+	// started for the sandbox.
+	EnableTPM bool
+
+	// SwtpmPath is the swtpm binary path.
+	SwtpmPath string
+
+	// TPMModel is the TPM interface the guest sees, tpm-crb or tpm-tis.
+	// It only applies to QEMU.
+	TPMModel string
+
+	// TPMStatePath is the host directory the TPM state of the sandboxes
+	// is persisted in, one sub-directory per TPM state identity.
+	TPMStatePath string
+
+	// TPMStateID is the identity the TPM state of the sandbox is kept
+	// under, e.g. its pod UID, so that the state outlives the sandbox. The
+	// sandbox ID is used if empty, and the state is removed with the
+	// sandbox then.
+	TPMStateID string
+
 	// PCIeRootPort is the number of root-port to create for the VM
 	PCIeRootPort uint32
 
 	// PCIeSwitchPort is the number of switch-port to create for the VM
 	PCIeSwitchPort uint32
 
+	// PCIeHotplugPorts is the number of root-port or switch-port reserved
+	// for the devices hot plugged to the VM, on top of the cold plugged ones
+	PCIeHotplugPorts uint32
+
 	// NumVCPUs specifies default number of vCPUs for the VM.
 	NumVCPUsF float32
 
@@ -903,6 +971,15 @@ func (conf *HypervisorConfig) CheckTemplateConfig() error {
 	return nil
 }
 
+// HostSysfs returns the provider of the host sysfs and devfs under the
+// configured root, the host root if none.
+func (conf *HypervisorConfig) HostSysfs() config.SysfsProvider {
+	if conf.HostSysfsRoot == "" {
+		return config.NewRootedSysfs("/")
+	}
+	return config.NewRootedSysfs(conf.HostSysfsRoot)
+}
+
 // AddKernelParam allows the addition of new kernel parameters to an existing
 // hypervisor configuration.
 func (conf *HypervisorConfig) AddKernelParam(p Param) error {
@@ -1307,6 +1384,7 @@ type Hypervisor interface {
 	AddDevice(ctx context.Context, devInfo interface{}, devType DeviceType) error
 	HotplugAddDevice(ctx context.Context, devInfo interface{}, devType DeviceType) (interface{}, error)
 	HotplugRemoveDevice(ctx context.Context, devInfo interface{}, devType DeviceType) (interface{}, error)
+	IsDevicePlugged(ctx context.Context, devInfo interface{}, devType DeviceType) (bool, error)
 	ResizeMemory(ctx context.Context, memMB uint32, memoryBlockSizeMB uint32, probe bool) (uint32, MemoryDevice, error)
 	ResizeVCPUs(ctx context.Context, vcpus uint32) (uint32, uint32, error)
 	GetTotalMemoryMB(ctx context.Context) uint32
@@ -1333,6 +1411,12 @@ type Hypervisor interface {
 
 	// check if hypervisor supports built-in rate limiter.
 	IsRateLimiterBuiltin() bool
+
+	// update the built-in rate limiter of a network endpoint.
+	UpdateNetRateLimiter(ctx context.Context, endpoint Endpoint, bandwidth NetworkBandwidth) error
+
+	// resize a plugged block device to size bytes and notify the guest.
+	ResizeBlockDevice(ctx context.Context, drive *config.BlockDrive, size uint64) error
 }
 
 // KernelParamFields is similar to strings.Fields(), but doesn't split
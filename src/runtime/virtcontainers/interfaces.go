@@ -69,9 +69,20 @@ type VCSandbox interface {
 	ListInterfaces(ctx context.Context) ([]*pbTypes.Interface, error)
 	UpdateRoutes(ctx context.Context, routes []*pbTypes.Route) ([]*pbTypes.Route, error)
 	ListRoutes(ctx context.Context) ([]*pbTypes.Route, error)
+	// ListNetworkEndpoints lists the interfaces, routes and ARP neighbors
+	// the sandbox network endpoints configure in the guest.
+	ListNetworkEndpoints(ctx context.Context) ([]*pbTypes.Interface, []*pbTypes.Route, []*pbTypes.ARPNeighbor, error)
+	UpdateInterfaceBandwidth(ctx context.Context, name string, bandwidth NetworkBandwidth) error
+	// ReloadDanConfig reconciles the sandbox network with its DAN config.
+	ReloadDanConfig(ctx context.Context) error
 
 	GetOOMEvent(ctx context.Context) (string, error)
+	// GetSandboxEvent returns the next event of the sandbox.
+	GetSandboxEvent(ctx context.Context) (SandboxEvent, error)
 	GetHypervisorPid() (int, error)
+	// PCIeTopology returns the map of the PCI(e) buses of the VM and of the
+	// devices plugged to them.
+	PCIeTopology(ctx context.Context) (PCIeTopology, error)
 	// RescanNetwork re-scans the network namespace for late-discovered endpoints.
 	RescanNetwork(ctx context.Context) error
 
@@ -76,6 +76,10 @@ const (
 	// an encryption key for a Storage struct.
 	encryptionKeyDriverOption = "encryption_key"
 
+	// encryptionKeyFileDriverOption is the driver option used to specify
+	// the guest file holding the key of an encrypted Storage struct.
+	encryptionKeyFileDriverOption = "encryption_key_file"
+
 	// Allocating an FSGroup that owns the pod's volumes
 	fsGid = "fsgid"
 
@@ -116,6 +120,9 @@ var (
 	kataBlkDevType                   = "blk"
 	kataBlkCCWDevType                = "blk-ccw"
 	kataSCSIDevType                  = "scsi"
+	kataSCSIGenericDevType           = "scsi-generic"
+	kataVirtioSerialDevType          = "virtio-serial"
+	kataVsockCharDevType             = "vsock-char"
 	kataNvdimmDevType                = "nvdimm"
 	kataVirtioFSDevType              = "virtio-fs"
 	kataOverlayDevType               = "overlayfs"
@@ -165,6 +172,8 @@ const (
 	grpcGuestDetailsRequest                   = "grpc.GuestDetailsRequest"
 	grpcMemHotplugByProbeRequest              = "grpc.MemHotplugByProbeRequest"
 	grpcCopyFileRequest                       = "grpc.CopyFileRequest"
+	grpcCloneDirectoryRequest                 = "grpc.CloneDirectoryRequest"
+	grpcRemovePathRequest                     = "grpc.RemovePathRequest"
 	grpcSetGuestDateTimeRequest               = "grpc.SetGuestDateTimeRequest"
 	grpcGetOOMEventRequest                    = "grpc.GetOOMEventRequest"
 	grpcGetMetricsRequest                     = "grpc.GetMetricsRequest"
@@ -283,6 +292,12 @@ var kataGuestSandboxStorageDir = func() string {
 	return filepath.Join(defaultKataGuestSandboxDir, "storage")
 }
 
+// kataGuestVolumeKeysDir is the guest directory of the keys of the encrypted
+// volumes, removed by the agent once the volumes are opened.
+var kataGuestVolumeKeysDir = func() string {
+	return filepath.Join(defaultKataGuestSandboxDir, "keys")
+}
+
 func ephemeralPath() string {
 	if rootless.IsRootless() {
 		return filepath.Join(kataGuestSandboxDir(), kataEphemeralDevType)
@@ -912,7 +927,7 @@ func setupStorages(ctx context.Context, sandbox *Sandbox) []*grpc.Storage {
 		// (resolv.conf, etc...) and potentially all container
 		// rootfs will reside.
 		sharedFS := sandbox.config.HypervisorConfig.SharedFS
-		if sharedFS == config.VirtioFS || sharedFS == config.VirtioFSNydus {
+		if IsVirtioFS(sharedFS) {
 			// If virtio-fs uses either of the two cache options 'auto, always',
 			// the guest directory can be mounted with option 'dax' allowing it to
 			// directly map contents from the host. Otherwise, the mount
@@ -1181,6 +1196,50 @@ func (k *kataAgent) appendBlockDevice(dev ContainerDevice, device api.Device, c
 	return kataDevice
 }
 
+func (k *kataAgent) appendSCSIPassthroughDevice(dev ContainerDevice, device api.Device, c *Container) *grpc.Device {
+	d, ok := device.GetDeviceInfo().(*config.SCSIPassthroughDrive)
+	if !ok || d == nil {
+		k.Logger().WithField("device", device).Error("malformed SCSI passthrough drive")
+		return nil
+	}
+
+	// A passed through SCSI disk shows up as a block device in the guest,
+	// a SCSI generic device as a SCSI generic character device.
+	kataDevice := &grpc.Device{
+		ContainerPath: dev.ContainerPath,
+		Type:          kataSCSIDevType,
+		Id:            d.SCSIAddr,
+	}
+	if d.Driver == config.SCSIGeneric {
+		kataDevice.Type = kataSCSIGenericDevType
+	}
+
+	return kataDevice
+}
+
+func (k *kataAgent) appendCharPassthroughDevice(dev ContainerDevice, device api.Device, c *Container) *grpc.Device {
+	d, ok := device.GetDeviceInfo().(*config.CharDrive)
+	if !ok || d == nil {
+		k.Logger().WithField("device", device).Error("malformed character device drive")
+		return nil
+	}
+
+	// The agent relays the vsock port to a pseudo terminal
+	if d.VsockPort != 0 {
+		return &grpc.Device{
+			ContainerPath: dev.ContainerPath,
+			Type:          kataVsockCharDevType,
+			Id:            strconv.FormatUint(uint64(d.VsockPort), 10),
+		}
+	}
+
+	return &grpc.Device{
+		ContainerPath: dev.ContainerPath,
+		Type:          kataVirtioSerialDevType,
+		Id:            d.PortName,
+	}
+}
+
 func (k *kataAgent) appendVhostUserBlkDevice(dev ContainerDevice, device api.Device, c *Container) *grpc.Device {
 	d, ok := device.GetDeviceInfo().(*config.VhostUserDeviceAttrs)
 	if !ok || d == nil {
@@ -1279,6 +1338,10 @@ func (k *kataAgent) appendDevices(deviceList []*grpc.Device, c *Container) []*gr
 			kataDevice = k.appendVhostUserBlkDevice(dev, device, c)
 		case config.DeviceVFIO:
 			kataDevice = k.appendVfioDevice(dev, device, c)
+		case config.DeviceSCSIPassthrough:
+			kataDevice = k.appendSCSIPassthroughDevice(dev, device, c)
+		case config.DeviceCharPassthrough:
+			kataDevice = k.appendCharPassthroughDevice(dev, device, c)
 		}
 
 		if kataDevice == nil || kataDevice.Type == "" {
@@ -1458,11 +1521,19 @@ func (k *kataAgent) createContainer(ctx context.Context, sandbox *Sandbox, c *Co
 
 	// Block based volumes will require some adjustments in the OCI spec, and creation of
 	// storage objects to pass to the agent.
-	layerStorages, volumeStorages, err := k.handleBlkOCIMounts(c, ociSpec)
+	layerStorages, volumeStorages, err := k.handleBlkOCIMounts(ctx, c, ociSpec)
 	if err != nil {
 		return nil, err
 	}
 
+	// The agent removes the volume keys once read, remove the ones it did
+	// not get to.
+	defer func() {
+		if err != nil {
+			k.removeVolumeKeys(ctx, volumeStorages)
+		}
+	}()
+
 	ctrStorages = append(ctrStorages, volumeStorages...)
 
 	// Layer storage objects are prepended to the list so that they come _before_ the
@@ -1698,6 +1769,9 @@ func handleBlockVolume(c *Container, device api.Device) (*grpc.Storage, error) {
 		vol.Source = fmt.Sprintf("/dev/pmem%s", blockDrive.NvdimmID)
 		vol.Fstype = blockDrive.Format
 		vol.Options = []string{"dax"}
+		if blockDrive.ReadOnly {
+			vol.Options = append(vol.Options, "ro")
+		}
 	case c.sandbox.config.HypervisorConfig.BlockDeviceDriver == config.VirtioBlockCCW:
 		vol.Driver = kataBlkCCWDevType
 		vol.Source = blockDrive.DevNo
@@ -1831,7 +1905,8 @@ func (k *kataAgent) handleDeviceBlockVolume(c *Container, m Mount, device api.De
 		}
 	}
 
-	if m.EncryptionKey != "" {
+	// The key references are resolved when the storage is sent to the agent
+	if m.EncryptionKey != "" && !volume.IsProvidedEncryptionKey(m.EncryptionKey) {
 		option := fmt.Sprintf("%s=%s", encryptionKeyDriverOption, m.EncryptionKey)
 		vol.DriverOptions = append(vol.DriverOptions, option)
 	}
@@ -1893,7 +1968,7 @@ func (k *kataAgent) createBlkStorageObject(c *Container, m Mount) (*grpc.Storage
 // handleBlkOCIMounts will create a unique destination mountpoint in the guest for each volume in the
 // given container and will update the OCI spec to utilize this mount point as the new source for the
 // container volume. The container mount structure is updated to store the guest destination mountpoint.
-func (k *kataAgent) handleBlkOCIMounts(c *Container, spec *specs.Spec) ([]*grpc.Storage, []*grpc.Storage, error) {
+func (k *kataAgent) handleBlkOCIMounts(ctx context.Context, c *Container, spec *specs.Spec) ([]*grpc.Storage, []*grpc.Storage, error) {
 
 	var volumeStorages []*grpc.Storage
 	var layerStorages []*grpc.Storage
@@ -1916,6 +1991,7 @@ func (k *kataAgent) handleBlkOCIMounts(c *Container, spec *specs.Spec) ([]*grpc.
 		// Create Storage structure
 		vol, err := k.createBlkStorageObject(c, m)
 		if vol == nil || err != nil {
+			k.removeVolumeKeys(ctx, volumeStorages)
 			return nil, nil, err
 		}
 
@@ -1942,7 +2018,8 @@ func (k *kataAgent) handleBlkOCIMounts(c *Container, spec *specs.Spec) ([]*grpc.
 				"new-source":      path,
 			}).Debug("Replacing OCI mount source")
 			spec.Mounts[idx].Source = path
-			if HasOption(spec.Mounts[idx].Options, vcAnnotations.IsFileBlockDevice) {
+			if HasOption(spec.Mounts[idx].Options, vcAnnotations.IsFileBlockDevice) ||
+				HasOption(spec.Mounts[idx].Options, vcAnnotations.IsFilePmemDevice) {
 				// The device is already mounted, just bind to path in container.
 				spec.Mounts[idx].Options = []string{"bind"}
 			}
@@ -1953,6 +2030,15 @@ func (k *kataAgent) handleBlkOCIMounts(c *Container, spec *specs.Spec) ([]*grpc.
 		vol.MountPoint = path
 		c.mounts[i].GuestDeviceMount = path
 
+		if volume.IsProvidedEncryptionKey(m.EncryptionKey) {
+			keyPath, err := k.sendVolumeKey(ctx, m.EncryptionKey, filename)
+			if err != nil {
+				k.removeVolumeKeys(ctx, volumeStorages)
+				return nil, nil, err
+			}
+			vol.DriverOptions = append(vol.DriverOptions, fmt.Sprintf("%s=%s", encryptionKeyFileDriverOption, keyPath))
+		}
+
 		volumeStorages = append(volumeStorages, vol)
 	}
 
@@ -2380,6 +2466,12 @@ func (k *kataAgent) installReqFunc(c *kataclient.AgentClient) {
 	k.reqHandlers[grpcCopyFileRequest] = func(ctx context.Context, req interface{}) (interface{}, error) {
 		return k.client.AgentServiceClient.CopyFile(ctx, req.(*grpc.CopyFileRequest))
 	}
+	k.reqHandlers[grpcCloneDirectoryRequest] = func(ctx context.Context, req interface{}) (interface{}, error) {
+		return k.client.AgentServiceClient.CloneDirectory(ctx, req.(*grpc.CloneDirectoryRequest))
+	}
+	k.reqHandlers[grpcRemovePathRequest] = func(ctx context.Context, req interface{}) (interface{}, error) {
+		return k.client.AgentServiceClient.RemovePath(ctx, req.(*grpc.RemovePathRequest))
+	}
 	k.reqHandlers[grpcSetGuestDateTimeRequest] = func(ctx context.Context, req interface{}) (interface{}, error) {
 		return k.client.AgentServiceClient.SetGuestDateTime(ctx, req.(*grpc.SetGuestDateTimeRequest))
 	}
@@ -2629,6 +2721,100 @@ func (k *kataAgent) copyFile(ctx context.Context, src, dst string) error {
 	return nil
 }
 
+func (k *kataAgent) cloneDir(ctx context.Context, src, dst, clone string) error {
+	var st unix.Stat_t
+
+	if err := unix.Lstat(src, &st); err != nil {
+		return fmt.Errorf("Could not get file %s information: %v", src, err)
+	}
+	if st.Mode&unix.S_IFMT != unix.S_IFDIR {
+		return fmt.Errorf("Could not clone %s: not a directory", src)
+	}
+
+	cloneReq := &grpc.CloneDirectoryRequest{
+		Path:     dst,
+		Source:   clone,
+		DirMode:  uint32(DirMode),
+		FileMode: st.Mode,
+		Uid:      int32(st.Uid),
+		Gid:      int32(st.Gid),
+	}
+
+	k.Logger().WithFields(logrus.Fields{
+		"source": src,
+		"dest":   dst,
+		"clone":  clone,
+	}).Debugf("Cloning directory in guest")
+
+	_, err := k.sendReq(ctx, cloneReq)
+	if err != nil && err.Error() == context.DeadlineExceeded.Error() {
+		return grpcStatus.Errorf(codes.DeadlineExceeded, "CloneDirectoryRequest timed out")
+	}
+	return err
+}
+
+func (k *kataAgent) removeFile(ctx context.Context, path string) error {
+	k.Logger().WithField("path", path).Debugf("Removing file in guest")
+
+	_, err := k.sendReq(ctx, &grpc.RemovePathRequest{Path: path})
+	if err != nil && err.Error() == context.DeadlineExceeded.Error() {
+		return grpcStatus.Errorf(codes.DeadlineExceeded, "RemovePathRequest timed out")
+	}
+	return err
+}
+
+// sendVolumeKey fetches the key material of the encrypted volume from its key
+// provider, and writes it to a guest key file rather than passing it in the
+// storage metadata. It returns the path of the guest key file.
+func (k *kataAgent) sendVolumeKey(ctx context.Context, encryptionKey, name string) (string, error) {
+	key, err := volume.GetEncryptionKey(ctx, encryptionKey)
+	if err != nil {
+		return "", err
+	}
+
+	keyPath := filepath.Join(kataGuestVolumeKeysDir(), name)
+	if err := k.writeKeyFile(ctx, keyPath, key); err != nil {
+		return "", fmt.Errorf("sending volume key: %w", err)
+	}
+
+	return keyPath, nil
+}
+
+// removeVolumeKeys removes the guest key files of the encrypted volumes, when
+// the storages are not set up.
+func (k *kataAgent) removeVolumeKeys(ctx context.Context, storages []*grpc.Storage) {
+	prefix := encryptionKeyFileDriverOption + "="
+	for _, s := range storages {
+		for _, opt := range s.DriverOptions {
+			if !strings.HasPrefix(opt, prefix) {
+				continue
+			}
+			keyPath := strings.TrimPrefix(opt, prefix)
+			if err := k.removeFile(ctx, keyPath); err != nil {
+				k.Logger().WithError(err).WithField("path", keyPath).Warn("Could not remove volume key")
+			}
+		}
+	}
+}
+
+func (k *kataAgent) writeKeyFile(ctx context.Context, dst string, key []byte) error {
+	cpReq := &grpc.CopyFileRequest{
+		Path:     dst,
+		DirMode:  uint32(sharedDirMode),
+		FileMode: unix.S_IFREG | 0400,
+		FileSize: int64(len(key)),
+		Data:     key,
+	}
+
+	k.Logger().WithField("dest", dst).Debug("Writing key file in guest")
+
+	_, err := k.sendReq(ctx, cpReq)
+	if err != nil && err.Error() == context.DeadlineExceeded.Error() {
+		return grpcStatus.Errorf(codes.DeadlineExceeded, "CopyFileRequest timed out")
+	}
+	return err
+}
+
 func (k *kataAgent) addSwap(ctx context.Context, PCIPath types.PciPath) error {
 	span, ctx := katatrace.Trace(ctx, k.Logger(), "addSwap", kataAgentTracingTags)
 	defer span.End()
@@ -261,6 +261,23 @@ func TestHandleDeviceBlockVolume(t *testing.T) {
 				Options: []string{"dax"},
 			},
 		},
+		{
+			inputDev: &drivers.BlockDevice{
+				BlockDrive: &config.BlockDrive{
+					Pmem:     true,
+					NvdimmID: testNvdimmID,
+					Format:   testBlkDriveFormat,
+					ReadOnly: true,
+				},
+			},
+			inputMount: Mount{},
+			resultVol: &pb.Storage{
+				Driver:  kataNvdimmDevType,
+				Source:  fmt.Sprintf("/dev/pmem%s", testNvdimmID),
+				Fstype:  testBlkDriveFormat,
+				Options: []string{"dax", "ro"},
+			},
+		},
 		{
 			BlockDeviceDriver: config.VirtioBlockCCW,
 			inputMount: Mount{
@@ -413,7 +430,7 @@ func TestHandleBlockVolume(t *testing.T) {
 	mounts = append(mounts, vMount, bMount, dMount)
 
 	tmpDir := "/vhost/user/dir"
-	dm := manager.NewDeviceManager(config.VirtioBlock, true, tmpDir, 0, devices)
+	dm := manager.NewDeviceManager(config.VirtioBlock, true, tmpDir, 0, devices, nil, nil)
 
 	sConfig := SandboxConfig{}
 	sConfig.HypervisorConfig.BlockDeviceDriver = config.VirtioBlock
@@ -471,7 +488,7 @@ func TestAppendDevicesEmptyContainerDeviceList(t *testing.T) {
 
 	c := &Container{
 		sandbox: &Sandbox{
-			devManager: manager.NewDeviceManager("virtio-scsi", false, "", 0, nil),
+			devManager: manager.NewDeviceManager("virtio-scsi", false, "", 0, nil, nil, nil),
 		},
 		devices: ctrDevices,
 	}
@@ -509,7 +526,7 @@ func TestAppendDevices(t *testing.T) {
 
 	c := &Container{
 		sandbox: &Sandbox{
-			devManager: manager.NewDeviceManager("virtio-blk", false, "", 0, ctrDevices),
+			devManager: manager.NewDeviceManager("virtio-blk", false, "", 0, ctrDevices, nil, nil),
 			config:     sandboxConfig,
 		},
 	}
@@ -563,7 +580,7 @@ func TestAppendVhostUserBlkDevices(t *testing.T) {
 	testVhostUserStorePath := "/test/vhost/user/store/path"
 	c := &Container{
 		sandbox: &Sandbox{
-			devManager: manager.NewDeviceManager("virtio-blk", true, testVhostUserStorePath, 0, ctrDevices),
+			devManager: manager.NewDeviceManager("virtio-blk", true, testVhostUserStorePath, 0, ctrDevices, nil, nil),
 			config:     sandboxConfig,
 		},
 	}
@@ -586,6 +603,122 @@ func TestAppendVhostUserBlkDevices(t *testing.T) {
 		updatedDevList, expected)
 }
 
+func TestAppendSCSIPassthroughDevices(t *testing.T) {
+	k := kataAgent{}
+
+	ctrDevices := []api.Device{
+		&drivers.SCSIPassthroughDevice{
+			GenericDevice: &drivers.GenericDevice{
+				ID: "test-scsi-generic",
+			},
+			Drive: &config.SCSIPassthroughDrive{
+				Driver:   config.SCSIGeneric,
+				SCSIAddr: "0:0",
+			},
+		},
+		&drivers.SCSIPassthroughDevice{
+			GenericDevice: &drivers.GenericDevice{
+				ID: "test-scsi-block",
+			},
+			Drive: &config.SCSIPassthroughDrive{
+				Driver:   config.SCSIBlock,
+				SCSIAddr: "0:1",
+			},
+		},
+	}
+
+	c := &Container{
+		sandbox: &Sandbox{
+			devManager: manager.NewDeviceManager(config.VirtioSCSI, false, "", 0, ctrDevices, nil, nil),
+			config: &SandboxConfig{
+				HypervisorConfig: HypervisorConfig{
+					BlockDeviceDriver: config.VirtioSCSI,
+				},
+			},
+		},
+	}
+	c.devices = append(c.devices,
+		ContainerDevice{
+			ID:            "test-scsi-generic",
+			ContainerPath: "/dev/sg0",
+		},
+		ContainerDevice{
+			ID:            "test-scsi-block",
+			ContainerPath: "/dev/mapper/mpatha",
+		},
+	)
+
+	expected := []*pb.Device{
+		{
+			Type:          kataSCSIGenericDevType,
+			ContainerPath: "/dev/sg0",
+			Id:            "0:0",
+		},
+		{
+			Type:          kataSCSIDevType,
+			ContainerPath: "/dev/mapper/mpatha",
+			Id:            "0:1",
+		},
+	}
+	updatedDevList := k.appendDevices([]*pb.Device{}, c)
+	assert.True(t, reflect.DeepEqual(updatedDevList, expected),
+		"Device lists didn't match: got %+v, expecting %+v",
+		updatedDevList, expected)
+}
+
+func TestAppendCharPassthroughDevices(t *testing.T) {
+	k := kataAgent{}
+
+	ctrDevices := []api.Device{
+		&drivers.CharPassthroughDevice{
+			GenericDevice: &drivers.GenericDevice{
+				ID: "test-char",
+			},
+			CharDrive: &config.CharDrive{
+				ID:       "char-test",
+				PortName: "char-test",
+			},
+		},
+	}
+
+	c := &Container{
+		sandbox: &Sandbox{
+			devManager: manager.NewDeviceManager(config.VirtioSCSI, false, "", 0, ctrDevices, nil, nil),
+			config:     &SandboxConfig{},
+		},
+	}
+	c.devices = append(c.devices, ContainerDevice{
+		ID:            "test-char",
+		ContainerPath: "/dev/ttyUSB0",
+	})
+
+	expected := []*pb.Device{
+		{
+			Type:          kataVirtioSerialDevType,
+			ContainerPath: "/dev/ttyUSB0",
+			Id:            "char-test",
+		},
+	}
+	updatedDevList := k.appendDevices([]*pb.Device{}, c)
+	assert.True(t, reflect.DeepEqual(updatedDevList, expected),
+		"Device lists didn't match: got %+v, expecting %+v",
+		updatedDevList, expected)
+
+	// Relayed over vsock
+	ctrDevices[0].(*drivers.CharPassthroughDevice).CharDrive.VsockPort = 65536
+	expected = []*pb.Device{
+		{
+			Type:          kataVsockCharDevType,
+			ContainerPath: "/dev/ttyUSB0",
+			Id:            "65536",
+		},
+	}
+	updatedDevList = k.appendDevices([]*pb.Device{}, c)
+	assert.True(t, reflect.DeepEqual(updatedDevList, expected),
+		"Device lists didn't match: got %+v, expecting %+v",
+		updatedDevList, expected)
+}
+
 func TestConstrainGRPCSpec(t *testing.T) {
 	assert := assert.New(t)
 	expectedCgroupPath := "system.slice:foo:bar"
@@ -1331,7 +1464,7 @@ func TestKataAgentCreateContainerVFIODevices(t *testing.T) {
 						ColdPlugVFIO: tt.coldPlugVFIO,
 					},
 				},
-				devManager: manager.NewDeviceManager("virtio-scsi", false, "", 0, nil),
+				devManager: manager.NewDeviceManager("virtio-scsi", false, "", 0, nil, nil, nil),
 				agent:      k,
 			}
 
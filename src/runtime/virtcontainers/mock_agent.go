@@ -122,6 +122,11 @@ func (n *mockAgent) listRoutes(ctx context.Context) ([]*pbTypes.Route, error) {
 	return nil, nil
 }
 
+// addARPNeighbors is the Noop agent ARP neighbors add implementation. It does nothing.
+func (n *mockAgent) addARPNeighbors(ctx context.Context, neighs []*pbTypes.ARPNeighbor) error {
+	return nil
+}
+
 // updateEphemeralMounts is the Noop agent updateEphemeralMounts implementation. It does nothing.
 func (n *mockAgent) updateEphemeralMounts(ctx context.Context, storages []*grpc.Storage) error {
 	return nil
@@ -226,6 +231,21 @@ func (n *mockAgent) copyFile(ctx context.Context, src, dst string) error {
 	return nil
 }
 
+// cloneDir is the Noop agent clone directory. It does nothing.
+func (n *mockAgent) cloneDir(ctx context.Context, src, dst, clone string) error {
+	return nil
+}
+
+// removeFile is the Noop agent remove file. It does nothing.
+func (n *mockAgent) removeFile(ctx context.Context, path string) error {
+	return nil
+}
+
+// writeKeyFile is the Noop agent key file writer. It does nothing.
+func (n *mockAgent) writeKeyFile(ctx context.Context, dst string, key []byte) error {
+	return nil
+}
+
 // addSwap is the Noop agent setup swap. It does nothing.
 func (n *mockAgent) addSwap(ctx context.Context, PCIPath types.PciPath) error {
 	return nil
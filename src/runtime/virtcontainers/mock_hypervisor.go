@@ -10,6 +10,7 @@ import (
 	"errors"
 	"os"
 
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
 	hv "github.com/kata-containers/kata-containers/src/runtime/pkg/hypervisors"
 	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/types"
 )
@@ -19,6 +20,8 @@ var MockHybridVSockPath = "/tmp/kata-mock-hybrid-vsock.socket"
 type mockHypervisor struct {
 	config  HypervisorConfig
 	mockPid int
+	// devicePlugged is what IsDevicePlugged reports
+	devicePlugged bool
 }
 
 func (m *mockHypervisor) Capabilities(ctx context.Context) types.Capabilities {
@@ -89,6 +92,10 @@ func (m *mockHypervisor) HotplugRemoveDevice(ctx context.Context, devInfo interf
 	return nil, nil
 }
 
+func (m *mockHypervisor) IsDevicePlugged(ctx context.Context, devInfo interface{}, devType DeviceType) (bool, error) {
+	return m.devicePlugged, nil
+}
+
 func (m *mockHypervisor) GetVMConsole(ctx context.Context, sandboxID string) (string, string, error) {
 	return "", "", nil
 }
@@ -155,3 +162,11 @@ func (m *mockHypervisor) GenerateSocket(id string) (interface{}, error) {
 func (m *mockHypervisor) IsRateLimiterBuiltin() bool {
 	return false
 }
+
+func (m *mockHypervisor) UpdateNetRateLimiter(ctx context.Context, endpoint Endpoint, bandwidth NetworkBandwidth) error {
+	return nil
+}
+
+func (m *mockHypervisor) ResizeBlockDevice(ctx context.Context, drive *config.BlockDrive, size uint64) error {
+	return nil
+}
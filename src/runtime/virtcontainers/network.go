@@ -13,6 +13,8 @@ import (
 	"net"
 	"os"
 	"runtime"
+	"strconv"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 	"github.com/vishvananda/netlink"
@@ -22,6 +24,7 @@ import (
 	"github.com/kata-containers/kata-containers/src/runtime/pkg/katautils/katatrace"
 	"github.com/kata-containers/kata-containers/src/runtime/pkg/uuid"
 	pbTypes "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/pkg/agent/protocols"
+	vcTypes "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/types"
 	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/utils"
 )
 
@@ -85,6 +88,18 @@ type NetworkInterfacePair struct {
 	TapInterface
 	VirtIface NetworkInterface
 	NetInterworkingModel
+	// NetworkPolicy, when set, restricts the traffic the VM can send
+	// through this pair.
+	NetworkPolicy *vcTypes.NetworkPolicy
+	// SharedClsact means that the clsact qdisc enforcing the network
+	// policy on the network interface was there before, and is left
+	// in place when the policy is removed.
+	SharedClsact bool
+	// NetworkPolicyPriority is the tc priority of the first network
+	// policy filter, the others following it.
+	NetworkPolicyPriority uint16
+	// Bandwidth holds the rate limits applied to this pair.
+	Bandwidth NetworkBandwidth
 }
 
 // NetlinkIface describes fully a network interface.
@@ -198,6 +213,115 @@ type NetworkConfig struct {
 	DisableNewNetwork bool
 	// if DAN config exists, use it to config network
 	DanConfigPath string
+	// NetworkPolicy restricts the egress traffic of every endpoint.
+	// A nil policy allows all traffic.
+	NetworkPolicy *vcTypes.NetworkPolicy
+	// Bandwidth limits the traffic of every endpoint, on top of the
+	// hypervisor wide rate limiters.
+	Bandwidth NetworkBandwidth
+	// InterfaceBandwidth limits the traffic of the endpoints created for
+	// the named interfaces, instead of Bandwidth.
+	InterfaceBandwidth map[string]NetworkBandwidth
+
+	// EndpointTypes maps interface names to the registered endpoint types
+	// to create for them.
+	EndpointTypes map[string]EndpointType
+
+	// UserModeNetworking connects the VM through a single user-mode
+	// networking endpoint, backed by an unprivileged passt process, instead
+	// of the interfaces of the network namespace. It is the only way to
+	// connect VMs running in the host network namespace.
+	UserModeNetworking bool
+	// PasstPath is the path to the passt binary.
+	PasstPath string
+	// ForwardedPorts are the host ports forwarded to the guest by the
+	// user-mode networking endpoint.
+	ForwardedPorts []PortForward
+}
+
+// PortForward forwards the traffic received on a host port to a guest port.
+type PortForward struct {
+	// Protocol is either "tcp" or "udp".
+	Protocol  string
+	HostPort  uint16
+	GuestPort uint16
+}
+
+// String returns the port forward in the [host_port:]guest_port/protocol
+// notation.
+func (p PortForward) String() string {
+	return fmt.Sprintf("%d:%d/%s", p.HostPort, p.GuestPort, p.Protocol)
+}
+
+// ParsePortForwards parses a comma separated list of port forwards, each in
+// the [host_port:]guest_port[/protocol] notation. The host port defaults to
+// the guest one, and the protocol to tcp.
+func ParsePortForwards(value string) ([]PortForward, error) {
+	var forwards []PortForward
+
+	for _, spec := range strings.Split(value, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		forward := PortForward{Protocol: "tcp"}
+		ports, protocol, found := strings.Cut(spec, "/")
+		if found {
+			forward.Protocol = protocol
+		}
+		if forward.Protocol != "tcp" && forward.Protocol != "udp" {
+			return nil, fmt.Errorf("invalid protocol %q in port forward %q", forward.Protocol, spec)
+		}
+
+		hostPort, guestPort, found := strings.Cut(ports, ":")
+		if !found {
+			guestPort = hostPort
+		}
+
+		port, err := strconv.ParseUint(hostPort, 10, 16)
+		if err != nil || port == 0 {
+			return nil, fmt.Errorf("invalid host port in port forward %q", spec)
+		}
+		forward.HostPort = uint16(port)
+
+		port, err = strconv.ParseUint(guestPort, 10, 16)
+		if err != nil || port == 0 {
+			return nil, fmt.Errorf("invalid guest port in port forward %q", spec)
+		}
+		forward.GuestPort = uint16(port)
+
+		forwards = append(forwards, forward)
+	}
+
+	return forwards, nil
+}
+
+// NetworkBandwidth defines the maximum rates, in bits per second, of the
+// traffic received (Ingress) and sent (Egress) by the VM through a network
+// endpoint. A zero rate means no limit.
+type NetworkBandwidth struct {
+	Ingress uint64
+	Egress  uint64
+}
+
+// limit returns the bandwidth allowed by both b and other.
+func (b NetworkBandwidth) limit(other NetworkBandwidth) NetworkBandwidth {
+	return NetworkBandwidth{
+		Ingress: minRate(b.Ingress, other.Ingress),
+		Egress:  minRate(b.Egress, other.Egress),
+	}
+}
+
+// minRate returns the lowest of two rates, zero meaning no limit.
+func minRate(a, b uint64) uint64 {
+	if a == 0 {
+		return b
+	}
+	if b == 0 {
+		return a
+	}
+	return min(a, b)
 }
 
 type Network interface {
@@ -232,6 +356,15 @@ type Network interface {
 
 	// GetEndpoints number of sandbox's network endpoints.
 	GetEndpointsNum() (int, error)
+
+	// UpdateEndpointBandwidth changes the rate limits of the endpoint
+	// created for the named interface.
+	UpdateEndpointBandwidth(context.Context, *Sandbox, string, NetworkBandwidth) error
+
+	// UpdateDanEndpoints reconciles the sandbox's network endpoints with
+	// its DAN config, and returns the endpoints it hot attached or updated
+	// along with the ones to remove.
+	UpdateDanEndpoints(context.Context, *Sandbox) ([]Endpoint, []Endpoint, error)
 }
 
 func generateVCNetworkStructures(ctx context.Context, endpoints []Endpoint) ([]*pbTypes.Interface, []*pbTypes.Route, []*pbTypes.ARPNeighbor, error) {
@@ -96,6 +96,14 @@ func (n *DarwinNetwork) GetEndpointsNum() (int, error) {
 	return 0, nil
 }
 
+func (n *DarwinNetwork) UpdateEndpointBandwidth(context.Context, *Sandbox, string, NetworkBandwidth) error {
+	return endpointNotSupported
+}
+
+func (n *DarwinNetwork) UpdateDanEndpoints(context.Context, *Sandbox) ([]Endpoint, []Endpoint, error) {
+	return nil, nil, endpointNotSupported
+}
+
 func validGuestRoute(route netlink.Route) bool {
 	return true
 }
@@ -107,3 +115,7 @@ func validGuestNeighbor(neigh netlink.Neigh, gatewaySet map[string]struct{}) boo
 func gatewaySetFromRoutes(routes []netlink.Route) map[string]struct{} {
 	return make(map[string]struct{})
 }
+
+func endpointHelperPids(endpoints []Endpoint) []int {
+	return nil
+}
@@ -9,10 +9,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"math/rand"
 	"net"
 	"os"
 	"os/exec"
+	"reflect"
 	"regexp"
 	"runtime"
 	"sort"
@@ -23,6 +25,7 @@ import (
 	"github.com/containernetworking/plugins/pkg/ns"
 	"github.com/sirupsen/logrus"
 	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
 	"github.com/vishvananda/netns"
 	otelTrace "go.opentelemetry.io/otel/trace"
 	"golang.org/x/sys/unix"
@@ -47,6 +50,15 @@ type LinuxNetwork struct {
 	interworkingModel NetInterworkingModel
 	netNSCreated      bool
 	danConfigPath     string
+	networkPolicy     *vctypes.NetworkPolicy
+	bandwidth         NetworkBandwidth
+	ifaceBandwidth    map[string]NetworkBandwidth
+	endpointTypes     map[string]EndpointType
+	// userModeNetworking replaces the network namespace interfaces with
+	// a single passt endpoint.
+	userModeNetworking bool
+	passtPath          string
+	forwardedPorts     []PortForward
 	// placeholderNetNS holds the path to a placeholder network namespace
 	// that we created but later abandoned in favour of the hypervisor's
 	// netns. If best-effort deletion in addAllEndpoints fails, teardown
@@ -80,6 +92,14 @@ func NewNetwork(configs ...*NetworkConfig) (Network, error) {
 		interworkingModel: config.InterworkingModel,
 		netNSCreated:      config.NetworkCreated,
 		danConfigPath:     config.DanConfigPath,
+		networkPolicy:     config.NetworkPolicy,
+		bandwidth:         config.Bandwidth,
+		ifaceBandwidth:    config.InterfaceBandwidth,
+		endpointTypes:     config.EndpointTypes,
+
+		userModeNetworking: config.UserModeNetworking,
+		passtPath:          config.PasstPath,
+		forwardedPorts:     config.ForwardedPorts,
 	}, nil
 }
 
@@ -106,8 +126,17 @@ func LoadNetwork(netInfo persistapi.NetworkInfo) Network {
 			ep = &TapEndpoint{}
 		case IPVlanEndpointType:
 			ep = &IPVlanEndpoint{}
+		case VdpaEndpointType:
+			ep = &VdpaEndpoint{}
+		case PasstEndpointType:
+			ep = &PasstEndpoint{}
 		default:
-			networkLogger().WithField("endpoint-type", e.Type).Error("unknown endpoint type")
+			pluginEp, err := loadPluginEndpoint(e)
+			if err != nil {
+				networkLogger().WithField("endpoint-type", e.Type).WithError(err).Error("unknown endpoint type")
+				continue
+			}
+			network.eps = append(network.eps, pluginEp)
 			continue
 		}
 		ep.load(e)
@@ -128,14 +157,50 @@ func (n *LinuxNetwork) addSingleEndpoint(ctx context.Context, s *Sandbox, netInf
 	// an appropriate EndPoint based on interface type
 	// This should be a switch
 
+	// Out-of-tree endpoint types take precedence, as their interfaces
+	// may look like physical ones.
+	endpointType, isPlugin := n.endpointTypes[netInfo.Iface.Name]
+	if !isPlugin {
+		endpointType, isPlugin = linkTypeEndpointTypes[netInfo.Iface.Type]
+	}
+
+	// Check if interface is backed by a vdpa device. Such interfaces are
+	// usually physical ones, which must not be unbound from their driver.
+	var vdpaDev *netlink.VDPADevConfig
+	var err error
+	if !isPlugin {
+		vdpaDev, err = findVdpaDevice(netInfo)
+		if err != nil {
+			// The vdpa netlink family is not registered until a vdpa
+			// bus driver is loaded.
+			networkLogger().WithError(err).WithField("interface", netInfo.Iface.Name).Debug("Could not list vdpa devices")
+		}
+	}
+
 	// Check if interface is a physical interface. Do not create
 	// tap interface/bridge if it is.
-	isPhysical, err := isPhysicalIface(netInfo.Iface.Name)
-	if err != nil {
-		return nil, err
+	isPhysical := false
+	if !isPlugin && vdpaDev == nil {
+		isPhysical, err = isPhysicalIface(netInfo.Iface.Name)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	if isPhysical {
+	if isPlugin {
+		idx, err := n.nextEndpointIndex()
+		if err != nil {
+			return nil, err
+		}
+		networkLogger().WithField("interface", netInfo.Iface.Name).WithField("endpoint-type", endpointType).Info("Plugin network interface found")
+		endpoint, err = newPluginEndpoint(endpointType, idx, netInfo, n.interworkingModel)
+		if err != nil {
+			return nil, err
+		}
+	} else if vdpaDev != nil {
+		networkLogger().WithField("interface", netInfo.Iface.Name).WithField("vdpa-device", vdpaDev.Name).Info("Vdpa network interface found")
+		endpoint, err = createVdpaEndpoint(netInfo, vdpaDev)
+	} else if isPhysical {
 		if s.config.HypervisorConfig.ColdPlugVFIO == config.NoPort {
 			// When `cold_plug_vfio` is set to "no-port", the PhysicalEndpoint's VFIO device cannot be attached to the guest VM.
 			// Fail early to prevent the VF interface from being unbound and rebound to the VFIO driver.
@@ -145,23 +210,9 @@ func (n *LinuxNetwork) addSingleEndpoint(ctx context.Context, s *Sandbox, netInf
 		endpoint, err = createPhysicalEndpoint(netInfo)
 	} else {
 		var socketPath string
-		idx := len(n.eps)
-
-		// Avoid endpoint naming conflicts
-		// When creating a new endpoint, we check existing endpoint names and automatically adjust the naming of the new endpoint to ensure uniqueness.
-		lastIdx := -1
-		if len(n.eps) > 0 {
-			lastEndpoint := n.eps[len(n.eps)-1]
-			re := regexp.MustCompile("[0-9]+")
-			matchStr := re.FindString(lastEndpoint.Name())
-			n, err := strconv.ParseInt(matchStr, 10, 64)
-			if err != nil {
-				return nil, err
-			}
-			lastIdx = int(n)
-		}
-		if idx <= lastIdx {
-			idx = lastIdx + 1
+		idx, err := n.nextEndpointIndex()
+		if err != nil {
+			return nil, err
 		}
 		// Check if this is a dummy interface which has a vhost-user socket associated with it
 		socketPath, err = vhostUserSocketPath(netInfo)
@@ -223,6 +274,17 @@ func (n *LinuxNetwork) addSingleEndpoint(ctx context.Context, s *Sandbox, netInf
 
 	endpoint.SetProperties(netInfo)
 
+	// The policy and the rate limits have to be known before attaching, as
+	// they are applied while connecting the endpoint to the VM.
+	if err := n.checkNetworkPolicy(endpoint); err != nil {
+		return nil, err
+	}
+	bandwidth := n.endpointBandwidth(endpoint.Name(), s.hypervisor.HypervisorConfig())
+	if netPair := endpoint.NetworkPair(); netPair != nil {
+		netPair.NetworkPolicy = n.networkPolicy
+		netPair.Bandwidth = bandwidth
+	}
+
 	networkLogger().WithField("endpoint-type", endpoint.Type()).WithField("hotplug", hotplug).Info("Attaching endpoint")
 	if hotplug {
 		if err := endpoint.HotAttach(ctx, s); err != nil {
@@ -235,17 +297,15 @@ func (n *LinuxNetwork) addSingleEndpoint(ctx context.Context, s *Sandbox, netInf
 	}
 
 	if !s.hypervisor.IsRateLimiterBuiltin() {
-		rxRateLimiterMaxRate := s.hypervisor.HypervisorConfig().RxRateLimiterMaxRate
-		if rxRateLimiterMaxRate > 0 {
+		if bandwidth.Ingress > 0 {
 			networkLogger().Info("Add Rx Rate Limiter")
-			if err := addRxRateLimiter(endpoint, rxRateLimiterMaxRate); err != nil {
+			if err := addRxRateLimiter(endpoint, bandwidth.Ingress); err != nil {
 				return nil, err
 			}
 		}
-		txRateLimiterMaxRate := s.hypervisor.HypervisorConfig().TxRateLimiterMaxRate
-		if txRateLimiterMaxRate > 0 {
+		if bandwidth.Egress > 0 {
 			networkLogger().Info("Add Tx Rate Limiter")
-			if err := addTxRateLimiter(endpoint, txRateLimiterMaxRate); err != nil {
+			if err := addTxRateLimiter(endpoint, bandwidth.Egress); err != nil {
 				return nil, err
 			}
 		}
@@ -256,6 +316,74 @@ func (n *LinuxNetwork) addSingleEndpoint(ctx context.Context, s *Sandbox, netInf
 	return endpoint, nil
 }
 
+// nextEndpointIndex returns the index of the next endpoint.
+func (n *LinuxNetwork) nextEndpointIndex() (int, error) {
+	idx := len(n.eps)
+
+	// Avoid endpoint naming conflicts
+	// When creating a new endpoint, we check existing endpoint names and automatically adjust the naming of the new endpoint to ensure uniqueness.
+	lastIdx := -1
+	if len(n.eps) > 0 {
+		lastEndpoint := n.eps[len(n.eps)-1]
+		re := regexp.MustCompile("[0-9]+")
+		matchStr := re.FindString(lastEndpoint.Name())
+		n, err := strconv.ParseInt(matchStr, 10, 64)
+		if err != nil {
+			return -1, err
+		}
+		lastIdx = int(n)
+	}
+	if idx <= lastIdx {
+		idx = lastIdx + 1
+	}
+
+	return idx, nil
+}
+
+// endpointBandwidth returns the rate limits of the endpoint created for the
+// named interface. The sandbox or interface specific limits can only lower
+// the hypervisor wide ones.
+func (n *LinuxNetwork) endpointBandwidth(name string, hConfig HypervisorConfig) NetworkBandwidth {
+	bandwidth := n.bandwidth
+	if ifaceBandwidth, ok := n.ifaceBandwidth[name]; ok {
+		bandwidth = ifaceBandwidth
+	}
+
+	return bandwidth.limit(NetworkBandwidth{
+		Ingress: hConfig.RxRateLimiterMaxRate,
+		Egress:  hConfig.TxRateLimiterMaxRate,
+	})
+}
+
+// checkNetworkPolicy returns an error if a network policy is configured
+// and cannot be enforced on "endpoint". The policy is enforced by tc filters
+// on the host side of the network pair, set up while connecting the pair to
+// the VM with the macvtap or tc-filter interworking models. Any other
+// endpoint would let the VM traffic through unfiltered.
+func (n *LinuxNetwork) checkNetworkPolicy(endpoint Endpoint) error {
+	if n.networkPolicy == nil {
+		return nil
+	}
+
+	switch endpoint.Type() {
+	case VethEndpointType, IPVlanEndpointType, MacvlanEndpointType:
+		netPair := endpoint.NetworkPair()
+		if netPair == nil {
+			break
+		}
+
+		model := netPair.NetInterworkingModel
+		if model == NetXConnectDefaultModel {
+			model = DefaultNetInterworkingModel
+		}
+		if model == NetXConnectMacVtapModel || model == NetXConnectTCFilterModel {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Network policy cannot be enforced on %s endpoint %s", endpoint.Type(), endpoint.Name())
+}
+
 func (n *LinuxNetwork) removeSingleEndpoint(ctx context.Context, s *Sandbox, endpoint Endpoint, hotplug bool) error {
 	idx := len(n.eps)
 	for i, val := range n.eps {
@@ -559,52 +687,200 @@ func convertDanDeviceToNetworkInfo(device *vctypes.DanDevice) (*NetworkInfo, err
 	return &netInfo, nil
 }
 
-// Load network config in DAN config
-// Create the endpoints for the interfaces in Dan.
-func (n *LinuxNetwork) addDanEndpoints() error {
-	if len(n.eps) > 0 {
-		// only load DAN config once
-		return nil
-	}
-
-	jsonData, err := os.ReadFile(n.danConfigPath)
+// loadDanEndpoints creates the endpoints for the interfaces described in
+// the DAN config file.
+func loadDanEndpoints(danConfigPath string) ([]Endpoint, error) {
+	jsonData, err := os.ReadFile(danConfigPath)
 	if err != nil {
-		return fmt.Errorf("fail to load DAN config file: %v", err)
+		return nil, fmt.Errorf("fail to load DAN config file: %v", err)
 	}
 
 	var config vctypes.DanConfig
 	err = json.Unmarshal([]byte(jsonData), &config)
 	if err != nil {
-		return fmt.Errorf("fail to unmarshal DAN config: %v", err)
+		return nil, fmt.Errorf("fail to unmarshal DAN config: %v", err)
 	}
 
+	var eps []Endpoint
 	for _, device := range config.Devices {
 		var endpoint Endpoint
 		networkLogger().WithField("interface", device.Name).Info("DAN interface found")
 
 		netInfo, err := convertDanDeviceToNetworkInfo(&device)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		switch device.Device.Type {
 		case vctypes.VfioDanDeviceType:
 			endpoint, err = createVfioEndpoint(device.Device.PciDeviceID, netInfo)
 			if err != nil {
-				return err
+				return nil, err
 			}
 		default:
-			return fmt.Errorf("unknown DAN device type: '%s'", device.Device.Type)
+			return nil, fmt.Errorf("unknown DAN device type: '%s'", device.Device.Type)
+		}
+
+		eps = append(eps, endpoint)
+	}
+
+	sort.Slice(eps, func(i, j int) bool {
+		return eps[i].Name() < eps[j].Name()
+	})
+
+	return eps, nil
+}
+
+// Load network config in DAN config
+// Create the endpoints for the interfaces in Dan.
+func (n *LinuxNetwork) addDanEndpoints() error {
+	if len(n.eps) > 0 {
+		// only load DAN config once
+		return nil
+	}
+
+	eps, err := loadDanEndpoints(n.danConfigPath)
+	if err != nil {
+		return err
+	}
+
+	for _, ep := range eps {
+		if err := n.checkNetworkPolicy(ep); err != nil {
+			return err
+		}
+	}
+
+	n.eps = eps
+
+	return nil
+}
+
+// addPasstEndpoint creates the user-mode networking endpoint, configured from
+// the network namespace, and attaches it to the VM.
+func (n *LinuxNetwork) addPasstEndpoint(ctx context.Context, s *Sandbox, hotplug bool) error {
+	if len(n.eps) > 0 {
+		// only one passt endpoint per sandbox
+		return nil
+	}
+
+	return doNetNS(n.netNSPath, func(_ ns.NetNS) error {
+		netInfo, err := passtNetworkInfo()
+		if err != nil {
+			return err
+		}
+
+		endpoint := createPasstEndpoint(netInfo, n.passtPath, n.forwardedPorts)
+		endpoint.SetProperties(netInfo)
+
+		if err := n.checkNetworkPolicy(endpoint); err != nil {
+			return err
+		}
+
+		networkLogger().WithField("interface", endpoint.Name()).WithField("hotplug", hotplug).Info("Attaching passt endpoint")
+		if hotplug {
+			err = endpoint.HotAttach(ctx, s)
+		} else {
+			err = endpoint.Attach(ctx, s)
+		}
+		if err != nil {
+			return err
 		}
 
 		n.eps = append(n.eps, endpoint)
+		return nil
+	})
+}
+
+// sameDanEndpoint returns true if both endpoints describe the same DAN
+// interface backed by the same host device.
+func sameDanEndpoint(a, b Endpoint) bool {
+	if a.Type() != b.Type() || a.Name() != b.Name() || a.HardwareAddr() != b.HardwareAddr() {
+		return false
+	}
+
+	vfioA, okA := a.(*VfioEndpoint)
+	vfioB, okB := b.(*VfioEndpoint)
+	if okA != okB {
+		return false
+	}
+
+	return !okA || vfioA.HostBDF == vfioB.HostBDF
+}
+
+// UpdateDanEndpoints reloads the DAN config and reconciles the endpoints of
+// a running sandbox with it: the endpoints of the new interfaces are hot
+// attached and the network configuration of the existing ones is updated.
+// It returns the endpoints that have been hot attached or whose network
+// configuration changed, and the endpoints of the interfaces removed from
+// the config, or of all of them when the config itself is removed. The
+// latter are left for the caller to remove.
+func (n *LinuxNetwork) UpdateDanEndpoints(ctx context.Context, s *Sandbox) ([]Endpoint, []Endpoint, error) {
+	span, ctx := n.trace(ctx, "UpdateDanEndpoints")
+	defer span.End()
+
+	if n.danConfigPath == "" {
+		return nil, nil, fmt.Errorf("sandbox network is not configured with a DAN config")
+	}
+
+	var eps []Endpoint
+	if _, err := os.Stat(n.danConfigPath); err == nil {
+		if eps, err = loadDanEndpoints(n.danConfigPath); err != nil {
+			return nil, nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+
+	for _, ep := range eps {
+		if err := n.checkNetworkPolicy(ep); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var changed, removed []Endpoint
+	for _, ep := range n.eps {
+		found := false
+		for _, newEp := range eps {
+			if sameDanEndpoint(ep, newEp) {
+				if !reflect.DeepEqual(ep.Properties(), newEp.Properties()) {
+					ep.SetProperties(newEp.Properties())
+					changed = append(changed, ep)
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			removed = append(removed, ep)
+		}
+	}
+
+	for _, newEp := range eps {
+		found := false
+		for _, ep := range n.eps {
+			if sameDanEndpoint(ep, newEp) {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+
+		networkLogger().WithField("interface", newEp.Name()).Info("Hot attaching DAN endpoint")
+		if err := newEp.HotAttach(ctx, s); err != nil {
+			return changed, removed, err
+		}
+
+		n.eps = append(n.eps, newEp)
+		changed = append(changed, newEp)
 	}
 
 	sort.Slice(n.eps, func(i, j int) bool {
 		return n.eps[i].Name() < n.eps[j].Name()
 	})
 
-	return nil
+	return changed, removed, nil
 }
 
 // Run runs a callback in the specified network namespace.
@@ -629,6 +905,10 @@ func (n *LinuxNetwork) AddEndpoints(ctx context.Context, s *Sandbox, endpointsIn
 			if err := n.addDanEndpoints(); err != nil {
 				return nil, err
 			}
+		} else if n.userModeNetworking {
+			if err := n.addPasstEndpoint(ctx, s, hotplug); err != nil {
+				return nil, err
+			}
 		} else {
 			if err := n.addAllEndpoints(ctx, s, hotplug); err != nil {
 				return nil, err
@@ -705,6 +985,62 @@ func (n *LinuxNetwork) RemoveEndpoints(ctx context.Context, s *Sandbox, endpoint
 	return nil
 }
 
+// UpdateEndpointBandwidth changes the rate limits of the endpoint created for
+// the named interface, either through the hypervisor built-in rate limiter or
+// through the tc based one.
+func (n *LinuxNetwork) UpdateEndpointBandwidth(ctx context.Context, s *Sandbox, name string, bandwidth NetworkBandwidth) error {
+	span, ctx := n.trace(ctx, "UpdateEndpointBandwidth")
+	defer span.End()
+
+	var endpoint Endpoint
+	for _, ep := range n.eps {
+		if ep.Name() == name {
+			endpoint = ep
+			break
+		}
+	}
+	if endpoint == nil {
+		return fmt.Errorf("Endpoint for interface %s not found", name)
+	}
+
+	// The hypervisor wide rate limiters can't be exceeded.
+	hConfig := s.hypervisor.HypervisorConfig()
+	bandwidth = bandwidth.limit(NetworkBandwidth{
+		Ingress: hConfig.RxRateLimiterMaxRate,
+		Egress:  hConfig.TxRateLimiterMaxRate,
+	})
+
+	networkLogger().WithFields(logrus.Fields{
+		"interface": name,
+		"ingress":   bandwidth.Ingress,
+		"egress":    bandwidth.Egress,
+	}).Info("Updating endpoint bandwidth")
+
+	// The tc rate limiters are removed once the native one enforces the
+	// bandwidth.
+	tcBandwidth := bandwidth
+	if s.hypervisor.IsRateLimiterBuiltin() {
+		if err := s.hypervisor.UpdateNetRateLimiter(ctx, endpoint, bandwidth); err != nil {
+			return err
+		}
+		tcBandwidth = NetworkBandwidth{}
+	}
+	if err := doNetNS(n.netNSPath, func(_ ns.NetNS) error {
+		if err := updateRxRateLimiter(endpoint, tcBandwidth.Ingress); err != nil {
+			return err
+		}
+		return updateTxRateLimiter(endpoint, tcBandwidth.Egress)
+	}); err != nil {
+		return err
+	}
+
+	if netPair := endpoint.NetworkPair(); netPair != nil {
+		netPair.Bandwidth = bandwidth
+	}
+
+	return nil
+}
+
 // Network getters
 func (n *LinuxNetwork) NetworkID() string {
 	return n.netNSPath
@@ -1059,6 +1395,26 @@ func tapNetworkPair(ctx context.Context, endpoint Endpoint, queues int, disableV
 		return fmt.Errorf("Could not enable veth %s: %s", netPair.VirtIface.Name, err)
 	}
 
+	// The macvtap transmits the VM traffic through its parent interface,
+	// so the policy is enforced on the egress of the latter.
+	if netPair.NetworkPolicy != nil {
+		created, err := addQdiscClsact(attrs.Index)
+		if err != nil {
+			return err
+		}
+		netPair.SharedClsact = !created
+
+		allow := &netlink.GenericAction{
+			ActionAttrs: netlink.ActionAttrs{
+				Action: netlink.TC_ACT_OK,
+			},
+		}
+		netPair.NetworkPolicyPriority, err = addNetworkPolicyFilters(attrs.Index, netlink.HANDLE_MIN_EGRESS, netPair.NetworkPolicy, allow)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Note: The underlying interfaces need to be up prior to fd creation.
 
 	netPair.VMFds, err = createMacvtapFds(tapLink.Attrs().Index, queues)
@@ -1142,6 +1498,20 @@ func setupTCFiltering(ctx context.Context, endpoint Endpoint, queues int, disabl
 		return err
 	}
 
+	// Only the traffic allowed by the network policy, if any, is
+	// redirected from the TAP to the network interface.
+	if netPair.NetworkPolicy != nil {
+		allow := &netlink.MirredAction{
+			ActionAttrs: netlink.ActionAttrs{
+				Action: netlink.TC_ACT_STOLEN,
+			},
+			MirredAction: netlink.TCA_EGRESS_REDIR,
+			Ifindex:      attrs.Index,
+		}
+		netPair.NetworkPolicyPriority, err = addNetworkPolicyFilters(tapAttrs.Index, netlink.MakeHandle(0xffff, 0), netPair.NetworkPolicy, allow)
+		return err
+	}
+
 	if err := addRedirectTCFilter(tapAttrs.Index, attrs.Index); err != nil {
 		return err
 	}
@@ -1252,65 +1622,393 @@ func removeQdiscIngress(link netlink.Link) error {
 	return nil
 }
 
-func untapNetworkPair(ctx context.Context, endpoint Endpoint) error {
-	span, _ := networkTrace(ctx, "untapNetworkPair", endpoint)
-	defer span.End()
-
-	netHandle, err := netlink.NewHandle()
+// addQdiscClsact creates a clsact qdisc for network interface with the specified
+// network index, unless the interface already has one, which is then shared.
+// Unlike the ingress qdisc, clsact also provides an egress hook for classifying
+// the packets sent through the interface. It returns whether the qdisc was
+// created.
+//
+// This is equivalent to calling `tc qdisc add dev eth0 clsact`
+func addQdiscClsact(index int) (bool, error) {
+	link, err := netlink.LinkByIndex(index)
 	if err != nil {
-		return err
+		return false, fmt.Errorf("Failed to get link for network index %d : %s", index, err)
 	}
-	defer netHandle.Close()
-
-	netPair := endpoint.NetworkPair()
 
-	tapLink, err := getLinkByName(netHandle, netPair.TAPIface.Name, &netlink.Macvtap{})
+	qdiscs, err := netlink.QdiscList(link)
 	if err != nil {
-		return fmt.Errorf("Could not get TAP interface %s: %s", netPair.TAPIface.Name, err)
-	}
-
-	if err := netHandle.LinkDel(tapLink); err != nil {
-		return fmt.Errorf("Could not remove TAP %s: %s", netPair.TAPIface.Name, err)
+		return false, fmt.Errorf("Failed to list qdiscs for network index %d : %s", index, err)
 	}
 
-	link, err := getLinkForEndpoint(endpoint, netHandle)
-	if err != nil {
-		return err
+	for _, qdisc := range qdiscs {
+		if qdisc.Type() == "clsact" {
+			return false, nil
+		}
 	}
 
-	hardAddr, err := net.ParseMAC(netPair.TAPIface.HardAddr)
-	if err != nil {
-		return err
-	}
-	if err := netHandle.LinkSetHardwareAddr(link, hardAddr); err != nil {
-		return fmt.Errorf("Could not set MAC address %s for veth interface %s: %s",
-			netPair.VirtIface.HardAddr, netPair.VirtIface.Name, err)
+	qdisc := &netlink.GenericQdisc{
+		QdiscAttrs: netlink.QdiscAttrs{
+			LinkIndex: index,
+			Handle:    netlink.MakeHandle(0xffff, 0),
+			Parent:    netlink.HANDLE_CLSACT,
+		},
+		QdiscType: "clsact",
 	}
 
-	if err := netHandle.LinkSetDown(link); err != nil {
-		return fmt.Errorf("Could not disable veth %s: %s", netPair.VirtIface.Name, err)
+	if err := netlink.QdiscAdd(qdisc); err != nil {
+		return false, fmt.Errorf("Failed to add clsact qdisc for network index %d : %s", index, err)
 	}
 
-	// Restore the IPs that were cleared
-	err = setIPs(link, netPair.VirtIface.Addrs)
-	return err
+	return true, nil
 }
 
-func removeTCFiltering(ctx context.Context, endpoint Endpoint) error {
-	span, _ := networkTrace(ctx, "removeTCFiltering", endpoint)
-	defer span.End()
+// removeQdiscClsact removes the clsact qdisc, and all the filters attached to
+// it, previously created on "link".
+func removeQdiscClsact(link netlink.Link) error {
+	if link == nil {
+		return nil
+	}
 
-	netHandle, err := netlink.NewHandle()
+	qdiscs, err := netlink.QdiscList(link)
 	if err != nil {
 		return err
 	}
-	defer netHandle.Close()
 
-	netPair := endpoint.NetworkPair()
+	for _, qdisc := range qdiscs {
+		if qdisc.Type() != "clsact" {
+			continue
+		}
 
-	tapLink, err := getLinkByName(netHandle, netPair.TAPIface.Name, &netlink.Tuntap{})
-	if err != nil {
-		return fmt.Errorf("Could not get TAP interface: %s", err)
+		if err := netlink.QdiscDel(qdisc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// networkPolicyNDTypes are the ICMPv6 types of the neighbor discovery, which
+// IPv6 needs as IPv4 needs ARP: router solicitation and advertisement,
+// neighbor solicitation and advertisement, and redirect.
+var networkPolicyNDTypes = []uint32{133, 134, 135, 136, 137}
+
+// networkPolicyFilters returns the tc filters letting only the traffic allowed
+// by "policy" get the "allow" action, in priority order starting at "priority".
+// ARP and the IPv6 neighbor discovery are always allowed, and a final
+// catch-all filter drops everything else.
+func networkPolicyFilters(policy *vctypes.NetworkPolicy, allow netlink.Action, priority uint16) ([]*netlink.U32, error) {
+	filters := []*netlink.U32{
+		{
+			FilterAttrs: netlink.FilterAttrs{Protocol: unix.ETH_P_ARP},
+			Actions:     []netlink.Action{allow},
+		},
+	}
+
+	for _, ndType := range networkPolicyNDTypes {
+		filters = append(filters, &netlink.U32{
+			FilterAttrs: netlink.FilterAttrs{Protocol: unix.ETH_P_IPV6},
+			Sel: &nl.TcU32Sel{
+				Flags: nl.TC_U32_TERMINAL,
+				Keys: []nl.TcU32Key{
+					// Next header
+					{Mask: 0x0000ff00, Val: unix.IPPROTO_ICMPV6 << 8, Off: 4},
+					// ICMPv6 type, right after the IPv6 header
+					{Mask: 0xff000000, Val: ndType << 24, Off: 40},
+				},
+			},
+			Actions: []netlink.Action{allow},
+		})
+	}
+
+	for _, rule := range policy.Egress {
+		protocol, sels, err := networkPolicySelectors(rule)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, sel := range sels {
+			filters = append(filters, &netlink.U32{
+				FilterAttrs: netlink.FilterAttrs{Protocol: protocol},
+				Sel:         sel,
+				Actions:     []netlink.Action{allow},
+			})
+		}
+	}
+
+	filters = append(filters, &netlink.U32{
+		FilterAttrs: netlink.FilterAttrs{Protocol: unix.ETH_P_ALL},
+		Actions: []netlink.Action{
+			&netlink.GenericAction{
+				ActionAttrs: netlink.ActionAttrs{
+					Action: netlink.TC_ACT_SHOT,
+				},
+			},
+		},
+	})
+
+	if int(priority)+len(filters)-1 > math.MaxUint16 {
+		return nil, fmt.Errorf("No room for %d network policy filters from priority %d", len(filters), priority)
+	}
+
+	for i, filter := range filters {
+		filter.Priority = priority + uint16(i)
+	}
+
+	return filters, nil
+}
+
+// freeFilterPriority returns the first of "count" consecutive tc priorities
+// which no filter of "parent" uses on the device with index "index", so that
+// the filters added there neither replace nor get interleaved with the ones
+// already attached to a shared qdisc.
+func freeFilterPriority(index int, parent uint32, count int) (uint16, error) {
+	link, err := netlink.LinkByIndex(index)
+	if err != nil {
+		return 0, fmt.Errorf("Could not get link for index %d: %s", index, err)
+	}
+
+	existing, err := netlink.FilterList(link, parent)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to list filters for index %d : %s", index, err)
+	}
+
+	used := make(map[uint16]bool, len(existing))
+	for _, filter := range existing {
+		used[filter.Attrs().Priority] = true
+	}
+
+	return firstFreePriority(used, count)
+}
+
+// firstFreePriority returns the first of "count" consecutive priorities,
+// starting at 1, none of which is in "used".
+func firstFreePriority(used map[uint16]bool, count int) (uint16, error) {
+	for base := 1; base+count-1 <= math.MaxUint16; base++ {
+		free := true
+		for prio := base; prio < base+count; prio++ {
+			if used[uint16(prio)] {
+				free = false
+				base = prio
+				break
+			}
+		}
+		if free {
+			return uint16(base), nil
+		}
+	}
+
+	return 0, fmt.Errorf("No %d consecutive free tc filter priorities", count)
+}
+
+// addNetworkPolicyFilters adds tc filters for device with index "index" so that
+// only the traffic allowed by "policy" gets the "allow" action. The filters
+// get consecutive priorities no other filter of "parent" uses, the first of
+// which is returned for removeNetworkPolicyFilters.
+//
+// This is equivalent to calling, with increasing free priorities:
+// `tc filter add dev eth0 parent <parent> protocol arp u32 match u32 0 0 action <allow>`
+// `tc filter add dev eth0 parent <parent> protocol ipv6 u32 match ip6 protocol 58 0xff match u8 <nd type> 0xff at 40 action <allow>`
+// `tc filter add dev eth0 parent <parent> protocol ip u32 match ip dst <cidr> match ip dport <port> 0xffff action <allow>`
+// `tc filter add dev eth0 parent <parent> protocol all u32 match u32 0 0 action drop`
+func addNetworkPolicyFilters(index int, parent uint32, policy *vctypes.NetworkPolicy, allow netlink.Action) (uint16, error) {
+	filters, err := networkPolicyFilters(policy, allow, 1)
+	if err != nil {
+		return 0, err
+	}
+
+	priority, err := freeFilterPriority(index, parent, len(filters))
+	if err != nil {
+		return 0, err
+	}
+
+	for i, filter := range filters {
+		filter.LinkIndex = index
+		filter.Parent = parent
+		filter.Priority = priority + uint16(i)
+
+		if err := netlink.FilterAdd(filter); err != nil {
+			return 0, fmt.Errorf("Failed to add network policy filter for index %d : %s", index, err)
+		}
+	}
+
+	return priority, nil
+}
+
+// removeNetworkPolicyFilters removes the tc filters added by
+// addNetworkPolicyFilters for "policy" from "priority" on "link", leaving the
+// qdisc they are attached to, and the other filters of the latter, in place.
+func removeNetworkPolicyFilters(link netlink.Link, parent uint32, policy *vctypes.NetworkPolicy, priority uint16) error {
+	if link == nil {
+		return nil
+	}
+
+	// The filters used to always start at priority 1.
+	if priority == 0 {
+		priority = 1
+	}
+
+	filters, err := networkPolicyFilters(policy, &netlink.GenericAction{}, priority)
+	if err != nil {
+		return err
+	}
+
+	for _, filter := range filters {
+		filter.LinkIndex = link.Attrs().Index
+		filter.Parent = parent
+
+		if err := netlink.FilterDel(filter); err != nil {
+			return fmt.Errorf("Failed to remove network policy filter for %s : %s", link.Attrs().Name, err)
+		}
+	}
+
+	return nil
+}
+
+// networkPolicySelectors translates a network policy rule into the u32
+// selectors matching it, one per destination port. Ports are looked up
+// right after the fixed size IP header, as tc does for `match ip dport`,
+// hence IPv4 packets carrying options are not matched by port rules.
+func networkPolicySelectors(rule vctypes.NetworkPolicyRule) (uint16, []*nl.TcU32Sel, error) {
+	_, dst, err := net.ParseCIDR(rule.CIDR)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var ipProto uint32
+	switch rule.Protocol {
+	case vctypes.NetworkPolicyProtocolTCP:
+		ipProto = unix.IPPROTO_TCP
+	case vctypes.NetworkPolicyProtocolUDP:
+		ipProto = unix.IPPROTO_UDP
+	}
+
+	var protocol uint16
+	var keys []nl.TcU32Key
+	var portOff int32
+
+	if ip4 := dst.IP.To4(); ip4 != nil {
+		protocol = unix.ETH_P_IP
+		mask := uint32(0)
+		if len(dst.Mask) == net.IPv4len {
+			mask = uint32(dst.Mask[0])<<24 | uint32(dst.Mask[1])<<16 | uint32(dst.Mask[2])<<8 | uint32(dst.Mask[3])
+		}
+		val := uint32(ip4[0])<<24 | uint32(ip4[1])<<16 | uint32(ip4[2])<<8 | uint32(ip4[3])
+		// Destination address
+		keys = append(keys, nl.TcU32Key{Mask: mask, Val: val & mask, Off: 16})
+		if ipProto != 0 {
+			// Header length of 5 words, i.e. no IP options
+			keys = append(keys, nl.TcU32Key{Mask: 0x0f000000, Val: 0x05000000, Off: 0})
+			// Protocol
+			keys = append(keys, nl.TcU32Key{Mask: 0x00ff0000, Val: ipProto << 16, Off: 8})
+		}
+		portOff = 20
+	} else {
+		protocol = unix.ETH_P_IPV6
+		// Destination address, one key per 32 bits word
+		for i := 0; i < net.IPv6len; i += 4 {
+			mask := uint32(dst.Mask[i])<<24 | uint32(dst.Mask[i+1])<<16 | uint32(dst.Mask[i+2])<<8 | uint32(dst.Mask[i+3])
+			if mask == 0 {
+				continue
+			}
+			val := uint32(dst.IP[i])<<24 | uint32(dst.IP[i+1])<<16 | uint32(dst.IP[i+2])<<8 | uint32(dst.IP[i+3])
+			keys = append(keys, nl.TcU32Key{Mask: mask, Val: val & mask, Off: int32(24 + i)})
+		}
+		if ipProto != 0 {
+			// Next header
+			keys = append(keys, nl.TcU32Key{Mask: 0x0000ff00, Val: ipProto << 8, Off: 4})
+		}
+		if len(keys) == 0 {
+			// match all
+			keys = append(keys, nl.TcU32Key{})
+		}
+		portOff = 40
+	}
+
+	if len(rule.Ports) == 0 {
+		return protocol, []*nl.TcU32Sel{{Flags: nl.TC_U32_TERMINAL, Keys: keys}}, nil
+	}
+
+	var sels []*nl.TcU32Sel
+	for _, port := range rule.Ports {
+		portKeys := make([]nl.TcU32Key, len(keys), len(keys)+1)
+		copy(portKeys, keys)
+		// Destination port
+		portKeys = append(portKeys, nl.TcU32Key{Mask: 0x0000ffff, Val: uint32(port), Off: portOff})
+		sels = append(sels, &nl.TcU32Sel{Flags: nl.TC_U32_TERMINAL, Keys: portKeys})
+	}
+
+	return protocol, sels, nil
+}
+
+func untapNetworkPair(ctx context.Context, endpoint Endpoint) error {
+	span, _ := networkTrace(ctx, "untapNetworkPair", endpoint)
+	defer span.End()
+
+	netHandle, err := netlink.NewHandle()
+	if err != nil {
+		return err
+	}
+	defer netHandle.Close()
+
+	netPair := endpoint.NetworkPair()
+
+	tapLink, err := getLinkByName(netHandle, netPair.TAPIface.Name, &netlink.Macvtap{})
+	if err != nil {
+		return fmt.Errorf("Could not get TAP interface %s: %s", netPair.TAPIface.Name, err)
+	}
+
+	if err := netHandle.LinkDel(tapLink); err != nil {
+		return fmt.Errorf("Could not remove TAP %s: %s", netPair.TAPIface.Name, err)
+	}
+
+	link, err := getLinkForEndpoint(endpoint, netHandle)
+	if err != nil {
+		return err
+	}
+
+	if netPair.NetworkPolicy != nil {
+		if netPair.SharedClsact {
+			err = removeNetworkPolicyFilters(link, netlink.HANDLE_MIN_EGRESS, netPair.NetworkPolicy, netPair.NetworkPolicyPriority)
+		} else {
+			err = removeQdiscClsact(link)
+		}
+		if err != nil {
+			return fmt.Errorf("Could not remove network policy from veth %s: %s", netPair.VirtIface.Name, err)
+		}
+	}
+
+	hardAddr, err := net.ParseMAC(netPair.TAPIface.HardAddr)
+	if err != nil {
+		return err
+	}
+	if err := netHandle.LinkSetHardwareAddr(link, hardAddr); err != nil {
+		return fmt.Errorf("Could not set MAC address %s for veth interface %s: %s",
+			netPair.VirtIface.HardAddr, netPair.VirtIface.Name, err)
+	}
+
+	if err := netHandle.LinkSetDown(link); err != nil {
+		return fmt.Errorf("Could not disable veth %s: %s", netPair.VirtIface.Name, err)
+	}
+
+	// Restore the IPs that were cleared
+	err = setIPs(link, netPair.VirtIface.Addrs)
+	return err
+}
+
+func removeTCFiltering(ctx context.Context, endpoint Endpoint) error {
+	span, _ := networkTrace(ctx, "removeTCFiltering", endpoint)
+	defer span.End()
+
+	netHandle, err := netlink.NewHandle()
+	if err != nil {
+		return err
+	}
+	defer netHandle.Close()
+
+	netPair := endpoint.NetworkPair()
+
+	tapLink, err := getLinkByName(netHandle, netPair.TAPIface.Name, &netlink.Tuntap{})
+	if err != nil {
+		return fmt.Errorf("Could not get TAP interface: %s", err)
 	}
 
 	if err := netHandle.LinkSetDown(tapLink); err != nil {
@@ -1540,7 +2238,7 @@ func addHTBQdisc(linkIndex int, maxRate uint64) error {
 // to tc filters for handling ingress traffic,
 // By redirecting interface ingress traffic to ifb and treat it as egress traffic there,
 // we could do network shaping to interface inbound traffic.
-func addIFBDevice() (int, error) {
+func addIFBDevice(ifbName string) (int, error) {
 	// Check whether host supports ifb
 	if ok, err := utils.SupportsIfb(); !ok {
 		return -1, err
@@ -1553,23 +2251,48 @@ func addIFBDevice() (int, error) {
 	defer netHandle.Close()
 
 	// There exists error when using netlink library to create ifb interface
-	cmd := exec.Command("ip", "link", "add", "dev", "ifb0", "type", "ifb")
+	cmd := exec.Command("ip", "link", "add", "dev", ifbName, "type", "ifb")
 	if output, err := cmd.CombinedOutput(); err != nil {
-		return -1, fmt.Errorf("Could not create link ifb0: %v, error %v", output, err)
+		return -1, fmt.Errorf("Could not create link %s: %v, error %v", ifbName, output, err)
 	}
 
-	ifbLink, err := netlink.LinkByName("ifb0")
+	ifbLink, err := netlink.LinkByName(ifbName)
 	if err != nil {
 		return -1, err
 	}
 
 	if err := netHandle.LinkSetUp(ifbLink); err != nil {
-		return -1, fmt.Errorf("Could not enable link ifb0 %v", err)
+		return -1, fmt.Errorf("Could not enable link %s %v", ifbName, err)
 	}
 
 	return ifbLink.Attrs().Index, nil
 }
 
+// legacyIFBName is the name of the single ifb used by the sandboxes created
+// before the ifb got named after the link it shapes the traffic of.
+const legacyIFBName = "ifb0"
+
+// ifbName returns the name of the ifb the ingress traffic of the link with
+// the given index is redirected to, so that each endpoint gets its own ifb.
+func ifbName(linkIndex int) string {
+	return fmt.Sprintf("ifb%d", linkIndex)
+}
+
+// ifbLinkByIndex returns the ifb the ingress traffic of the link with the
+// given index is redirected to.
+func ifbLinkByIndex(linkIndex int) (netlink.Link, error) {
+	ifbLink, err := netlink.LinkByName(ifbName(linkIndex))
+	if err == nil {
+		return ifbLink, nil
+	}
+
+	if legacyLink, legacyErr := netlink.LinkByName(legacyIFBName); legacyErr == nil {
+		return legacyLink, nil
+	}
+
+	return nil, err
+}
+
 // This is equivalent to calling:
 // tc filter add dev source parent ffff: protocol all u32 match u8 0 0 action mirred egress redirect dev ifb
 func addIFBRedirecting(sourceIndex int, ifbIndex int) error {
@@ -1605,6 +2328,9 @@ func addTxRateLimiter(endpoint Endpoint, maxRate uint64) error {
 			if err != nil {
 				return err
 			}
+			if err := endpoint.SetTxRateLimiter(); err != nil {
+				return err
+			}
 			return addHTBQdisc(link.Attrs().Index, maxRate)
 		case NetXConnectMacVtapModel, NetXConnectNoneModel:
 			linkName = netPair.TAPIface.Name
@@ -1622,12 +2348,12 @@ func addTxRateLimiter(endpoint Endpoint, maxRate uint64) error {
 		return err
 	}
 
-	ifbIndex, err := addIFBDevice()
+	link, err := netlink.LinkByName(linkName)
 	if err != nil {
 		return err
 	}
 
-	link, err := netlink.LinkByName(linkName)
+	ifbIndex, err := addIFBDevice(ifbName(link.Attrs().Index))
 	if err != nil {
 		return err
 	}
@@ -1639,6 +2365,181 @@ func addTxRateLimiter(endpoint Endpoint, maxRate uint64) error {
 	return addHTBQdisc(ifbIndex, maxRate)
 }
 
+// changeHTBQdisc changes the rate of the classes created by addHTBQdisc.
+func changeHTBQdisc(linkIndex int, maxRate uint64) error {
+	htbClassAttrs := netlink.HtbClassAttrs{
+		Rate: maxRate,
+		Ceil: maxRate,
+	}
+
+	classAttrs := netlink.ClassAttrs{
+		LinkIndex: linkIndex,
+		Parent:    netlink.MakeHandle(1, 0),
+		Handle:    netlink.MakeHandle(1, 1),
+	}
+	class := netlink.NewHtbClass(classAttrs, htbClassAttrs)
+	if err := netlink.ClassChange(class); err != nil {
+		return fmt.Errorf("Failed to change htb classid 1:1 : %v", err)
+	}
+
+	classAttrs = netlink.ClassAttrs{
+		LinkIndex: linkIndex,
+		Parent:    netlink.MakeHandle(1, 1),
+		Handle:    netlink.MakeHandle(1, 2),
+	}
+	class = netlink.NewHtbClass(classAttrs, htbClassAttrs)
+	if err := netlink.ClassChange(class); err != nil {
+		return fmt.Errorf("Failed to change htb class 1:2 : %v", err)
+	}
+
+	return nil
+}
+
+// hasHTBQdisc returns whether the link has the htb qdisc created by addHTBQdisc.
+func hasHTBQdisc(link netlink.Link) (bool, error) {
+	qdiscs, err := netlink.QdiscList(link)
+	if err != nil {
+		return false, err
+	}
+
+	for _, qdisc := range qdiscs {
+		if _, ok := qdisc.(*netlink.Htb); ok {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// rxRateLimiterLinkName returns the name of the link the rx rate limiter of
+// the endpoint shapes the traffic of.
+func rxRateLimiterLinkName(endpoint Endpoint) (string, error) {
+	switch ep := endpoint.(type) {
+	case *VethEndpoint, *IPVlanEndpoint, *TuntapEndpoint, *MacvlanEndpoint:
+		return endpoint.NetworkPair().TAPIface.Name, nil
+	case *MacvtapEndpoint, *TapEndpoint:
+		return endpoint.Name(), nil
+	default:
+		return "", fmt.Errorf("Unsupported endpointType %s for rx rate limiter", ep.Type())
+	}
+}
+
+// txRateLimiterLinkName returns the name of the link the tx rate limiter of
+// the endpoint applies to, and whether the traffic of that link is redirected
+// to an ifb, which is the case for all but the tcfilter inter-networking model.
+func txRateLimiterLinkName(endpoint Endpoint) (string, bool, error) {
+	switch ep := endpoint.(type) {
+	case *VethEndpoint, *IPVlanEndpoint, *TuntapEndpoint, *MacvlanEndpoint:
+		netPair := endpoint.NetworkPair()
+		switch netPair.NetInterworkingModel {
+		case NetXConnectTCFilterModel:
+			return netPair.VirtIface.Name, false, nil
+		case NetXConnectMacVtapModel, NetXConnectNoneModel:
+			return netPair.TAPIface.Name, true, nil
+		default:
+			return "", false, fmt.Errorf("Unsupported inter-networking model %v for tx rate limiter", netPair.NetInterworkingModel)
+		}
+	case *MacvtapEndpoint, *TapEndpoint:
+		return endpoint.Name(), true, nil
+	default:
+		return "", false, fmt.Errorf("Unsupported endpointType %s for tx rate limiter", ep.Type())
+	}
+}
+
+// updateRxRateLimiter changes the rate of the rx rate limiter of the endpoint,
+// adding the rate limiter if the endpoint doesn't have one yet, and removing
+// it if maxRate is zero.
+func updateRxRateLimiter(endpoint Endpoint, maxRate uint64) error {
+	if !endpoint.GetRxRateLimiter() {
+		if maxRate == 0 {
+			return nil
+		}
+		return addRxRateLimiter(endpoint, maxRate)
+	}
+
+	linkName, err := rxRateLimiterLinkName(endpoint)
+	if err != nil {
+		return err
+	}
+
+	link, err := netlink.LinkByName(linkName)
+	if err != nil {
+		return err
+	}
+
+	// The rate limiter may have been removed by an earlier update.
+	limited, err := hasHTBQdisc(link)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case maxRate == 0 && limited:
+		return removeHTBQdisc(linkName)
+	case maxRate == 0:
+		return nil
+	case limited:
+		return changeHTBQdisc(link.Attrs().Index, maxRate)
+	default:
+		return addHTBQdisc(link.Attrs().Index, maxRate)
+	}
+}
+
+// updateTxRateLimiter changes the rate of the tx rate limiter of the endpoint,
+// adding the rate limiter if the endpoint doesn't have one yet, and removing
+// it if maxRate is zero.
+func updateTxRateLimiter(endpoint Endpoint, maxRate uint64) error {
+	if !endpoint.GetTxRateLimiter() {
+		if maxRate == 0 {
+			return nil
+		}
+		return addTxRateLimiter(endpoint, maxRate)
+	}
+
+	linkName, redirected, err := txRateLimiterLinkName(endpoint)
+	if err != nil {
+		return err
+	}
+
+	link, err := netlink.LinkByName(linkName)
+	if err != nil {
+		return err
+	}
+
+	// The htb qdisc is on the virtual interface for the tcfilter
+	// inter-networking model, and on the ifb for the other ones.
+	htbLink := link
+	if redirected {
+		ifbLink, err := ifbLinkByIndex(link.Attrs().Index)
+		if err != nil {
+			// The rate limiter has been removed by an earlier update.
+			if maxRate == 0 {
+				return nil
+			}
+			return addTxRateLimiter(endpoint, maxRate)
+		}
+		htbLink = ifbLink
+	}
+
+	limited, err := hasHTBQdisc(htbLink)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case maxRate == 0 && redirected:
+		return removeIFBRedirecting(link)
+	case maxRate == 0 && limited:
+		return removeHTBQdisc(linkName)
+	case maxRate == 0:
+		return nil
+	case limited:
+		return changeHTBQdisc(htbLink.Attrs().Index, maxRate)
+	default:
+		return addHTBQdisc(htbLink.Attrs().Index, maxRate)
+	}
+}
+
 func removeHTBQdisc(linkName string) error {
 	link, err := netlink.LinkByName(linkName)
 	if err != nil {
@@ -1665,15 +2566,9 @@ func removeHTBQdisc(linkName string) error {
 }
 
 func removeRxRateLimiter(endpoint Endpoint, networkNSPath string) error {
-	var linkName string
-	switch ep := endpoint.(type) {
-	case *VethEndpoint, *IPVlanEndpoint, *TuntapEndpoint, *MacvlanEndpoint:
-		netPair := endpoint.NetworkPair()
-		linkName = netPair.TAPIface.Name
-	case *MacvtapEndpoint, *TapEndpoint:
-		linkName = endpoint.Name()
-	default:
-		return fmt.Errorf("Unsupported endpointType %s for removing rx rate limiter", ep.Type())
+	linkName, err := rxRateLimiterLinkName(endpoint)
+	if err != nil {
+		return err
 	}
 
 	if err := doNetNS(networkNSPath, func(_ ns.NetNS) error {
@@ -1685,64 +2580,57 @@ func removeRxRateLimiter(endpoint Endpoint, networkNSPath string) error {
 	return nil
 }
 
-func removeTxRateLimiter(endpoint Endpoint, networkNSPath string) error {
-	var linkName string
-	switch ep := endpoint.(type) {
-	case *VethEndpoint, *IPVlanEndpoint, *TuntapEndpoint, *MacvlanEndpoint:
-		netPair := endpoint.NetworkPair()
-		switch netPair.NetInterworkingModel {
-		case NetXConnectTCFilterModel:
-			linkName = netPair.VirtIface.Name
-			if err := doNetNS(networkNSPath, func(_ ns.NetNS) error {
-				return removeHTBQdisc(linkName)
-			}); err != nil {
-				return err
-			}
-			return nil
-		case NetXConnectMacVtapModel, NetXConnectNoneModel:
-			linkName = netPair.TAPIface.Name
-		}
-	case *MacvtapEndpoint, *TapEndpoint:
-		linkName = endpoint.Name()
-	default:
-		return fmt.Errorf("Unsupported endpointType %s for adding tx rate limiter", ep.Type())
+// removeIFBRedirecting undoes addIFBRedirecting and removes the ifb the
+// ingress traffic of link is redirected to, along with its htb qdisc.
+func removeIFBRedirecting(link netlink.Link) error {
+	if err := removeRedirectTCFilter(link); err != nil {
+		return err
 	}
 
-	if err := doNetNS(networkNSPath, func(_ ns.NetNS) error {
-		link, err := netlink.LinkByName(linkName)
-		if err != nil {
-			return fmt.Errorf("get link %s by name failed: %v", linkName, err)
-		}
+	if err := removeQdiscIngress(link); err != nil {
+		return err
+	}
 
-		if err := removeRedirectTCFilter(link); err != nil {
-			return err
-		}
+	netHandle, err := netlink.NewHandle()
+	if err != nil {
+		return err
+	}
+	defer netHandle.Close()
 
-		if err := removeQdiscIngress(link); err != nil {
-			return err
-		}
+	// remove ifb interface
+	ifbLink, err := ifbLinkByIndex(link.Attrs().Index)
+	if err != nil {
+		return fmt.Errorf("get ifb of link %s failed: %v", link.Attrs().Name, err)
+	}
 
-		netHandle, err := netlink.NewHandle()
-		if err != nil {
-			return err
-		}
-		defer netHandle.Close()
+	if err := netHandle.LinkSetDown(ifbLink); err != nil {
+		return fmt.Errorf("Could not disable ifb interface: %v", err)
+	}
 
-		// remove ifb interface
-		ifbLink, err := netlink.LinkByName("ifb0")
-		if err != nil {
-			return fmt.Errorf("get link %s by name failed: %v", linkName, err)
-		}
+	if err := netHandle.LinkDel(ifbLink); err != nil {
+		return fmt.Errorf("Could not remove ifb interface: %v", err)
+	}
+
+	return nil
+}
+
+func removeTxRateLimiter(endpoint Endpoint, networkNSPath string) error {
+	linkName, redirected, err := txRateLimiterLinkName(endpoint)
+	if err != nil {
+		return err
+	}
 
-		if err := netHandle.LinkSetDown(ifbLink); err != nil {
-			return fmt.Errorf("Could not disable ifb interface: %v", err)
+	if err := doNetNS(networkNSPath, func(_ ns.NetNS) error {
+		if !redirected {
+			return removeHTBQdisc(linkName)
 		}
 
-		if err := netHandle.LinkDel(ifbLink); err != nil {
-			return fmt.Errorf("Could not remove ifb interface: %v", err)
+		link, err := netlink.LinkByName(linkName)
+		if err != nil {
+			return fmt.Errorf("get link %s by name failed: %v", linkName, err)
 		}
 
-		return nil
+		return removeIFBRedirecting(link)
 	}); err != nil {
 		return err
 	}
@@ -8,8 +8,10 @@ package virtcontainers
 import (
 	"context"
 	"encoding/json"
+	"math"
 	"net"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -22,6 +24,7 @@ import (
 	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
 )
 
 func TestGenerateInterfacesAndRoutes(t *testing.T) {
@@ -265,6 +268,16 @@ func TestRxRateLimiter(t *testing.T) {
 	err = addRxRateLimiter(endpoint, maxRate)
 	assert.NoError(err)
 
+	// The rate limiter can be changed, removed and added back at runtime.
+	err = updateRxRateLimiter(endpoint, 2*maxRate)
+	assert.NoError(err)
+	err = updateRxRateLimiter(endpoint, 0)
+	assert.NoError(err)
+	err = updateRxRateLimiter(endpoint, 0)
+	assert.NoError(err)
+	err = updateRxRateLimiter(endpoint, maxRate)
+	assert.NoError(err)
+
 	currentNS, err := ns.GetCurrentNS()
 	assert.NoError(err)
 
@@ -314,6 +327,16 @@ func TestTxRateLimiter(t *testing.T) {
 	err = addTxRateLimiter(endpoint, maxRate)
 	assert.NoError(err)
 
+	// The rate limiter can be changed, removed and added back at runtime.
+	err = updateTxRateLimiter(endpoint, 2*maxRate)
+	assert.NoError(err)
+	err = updateTxRateLimiter(endpoint, 0)
+	assert.NoError(err)
+	err = updateTxRateLimiter(endpoint, 0)
+	assert.NoError(err)
+	err = updateTxRateLimiter(endpoint, maxRate)
+	assert.NoError(err)
+
 	currentNS, err := ns.GetCurrentNS()
 	assert.NoError(err)
 
@@ -328,6 +351,308 @@ func TestTxRateLimiter(t *testing.T) {
 	assert.NoError(err)
 }
 
+func TestIFBName(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("ifb3", ifbName(3))
+	assert.NotEqual(ifbName(3), ifbName(4))
+	assert.LessOrEqual(len(ifbName(1<<31-1)), unix.IFNAMSIZ-1)
+}
+
+// tcGenericActionSupported checks whether the kernel provides the generic tc
+// actions (act_gact) the network policy filters rely on.
+func tcGenericActionSupported(t *testing.T) bool {
+	veth := &netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: "gact0"}, PeerName: "gact1"}
+	if err := netlink.LinkAdd(veth); err != nil {
+		t.Fatalf("failed to add veth link: %v", err)
+	}
+	defer netlink.LinkDel(veth)
+
+	if err := addQdiscIngress(veth.Attrs().Index); err != nil {
+		t.Fatalf("failed to add ingress qdisc: %v", err)
+	}
+
+	filter := &netlink.U32{
+		FilterAttrs: netlink.FilterAttrs{
+			LinkIndex: veth.Attrs().Index,
+			Parent:    netlink.MakeHandle(0xffff, 0),
+			Protocol:  unix.ETH_P_ALL,
+		},
+		Actions: []netlink.Action{&netlink.GenericAction{}},
+	}
+
+	return netlink.FilterAdd(filter) == nil
+}
+
+func TestTcRedirectNetworkPolicy(t *testing.T) {
+	if tc.NotValid(ktu.NeedRoot()) {
+		t.Skip(testDisabledAsNonRoot)
+	}
+
+	if !tcGenericActionSupported(t) {
+		t.Skip("Test disabled as the kernel does not support generic tc actions")
+	}
+
+	assert := assert.New(t)
+
+	netHandle, err := netlink.NewHandle()
+	assert.NoError(err)
+	defer netHandle.Close()
+
+	// Create a test veth interface.
+	vethName := "foo"
+	veth := &netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: vethName, TxQLen: 200, MTU: 1400}, PeerName: "bar"}
+
+	err = netlink.LinkAdd(veth)
+	assert.NoError(err)
+
+	endpoint, err := createVethNetworkEndpoint(1, vethName, NetXConnectTCFilterModel)
+	assert.NoError(err)
+	endpoint.NetPair.NetworkPolicy = &vctypes.NetworkPolicy{
+		Egress: []vctypes.NetworkPolicyRule{
+			{CIDR: "10.0.0.0/8"},
+			{CIDR: "fd00::/64", Protocol: "tcp", Ports: []uint16{80, 443}},
+		},
+	}
+
+	link, err := netlink.LinkByName(vethName)
+	assert.NoError(err)
+
+	err = netHandle.LinkSetUp(link)
+	assert.NoError(err)
+
+	err = setupTCFiltering(context.Background(), endpoint, 1, true)
+	assert.NoError(err)
+
+	// arp, neighbor discovery, 10.0.0.0/8, fd00::/64 port 80 and 443, drop
+	tapLink, err := netlink.LinkByName(endpoint.NetPair.TAPIface.Name)
+	assert.NoError(err)
+	filters, err := netlink.FilterList(tapLink, netlink.MakeHandle(0xffff, 0))
+	assert.NoError(err)
+	assert.Len(filters, 10)
+
+	err = removeTCFiltering(context.Background(), endpoint)
+	assert.NoError(err)
+
+	// Remove the veth created for testing.
+	err = netHandle.LinkDel(link)
+	assert.NoError(err)
+}
+
+func TestMacvtapNetworkPolicy(t *testing.T) {
+	if tc.NotValid(ktu.NeedRoot()) {
+		t.Skip(testDisabledAsNonRoot)
+	}
+
+	if !tcGenericActionSupported(t) {
+		t.Skip("Test disabled as the kernel does not support generic tc actions")
+	}
+
+	assert := assert.New(t)
+
+	netHandle, err := netlink.NewHandle()
+	assert.NoError(err)
+	defer netHandle.Close()
+
+	// Create a test veth interface.
+	vethName := "foo"
+	veth := &netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: vethName, TxQLen: 200, MTU: 1400}, PeerName: "bar"}
+
+	err = netlink.LinkAdd(veth)
+	assert.NoError(err)
+
+	endpoint, err := createVethNetworkEndpoint(1, vethName, NetXConnectMacVtapModel)
+	assert.NoError(err)
+	endpoint.NetPair.NetworkPolicy = &vctypes.NetworkPolicy{
+		Egress: []vctypes.NetworkPolicyRule{
+			{CIDR: "0.0.0.0/0", Protocol: "udp", Ports: []uint16{53}},
+		},
+	}
+
+	link, err := netlink.LinkByName(vethName)
+	assert.NoError(err)
+
+	err = tapNetworkPair(context.Background(), endpoint, 1, true)
+	assert.NoError(err)
+
+	// arp, neighbor discovery, udp port 53, drop
+	filters, err := netlink.FilterList(link, netlink.HANDLE_MIN_EGRESS)
+	assert.NoError(err)
+	assert.Len(filters, 8)
+	assert.False(endpoint.NetPair.SharedClsact)
+	assert.Equal(uint16(1), endpoint.NetPair.NetworkPolicyPriority)
+
+	for _, f := range endpoint.NetPair.VMFds {
+		f.Close()
+	}
+
+	err = untapNetworkPair(context.Background(), endpoint)
+	assert.NoError(err)
+
+	qdiscs, err := netlink.QdiscList(link)
+	assert.NoError(err)
+	for _, qdisc := range qdiscs {
+		assert.NotEqual("clsact", qdisc.Type())
+	}
+
+	// A clsact qdisc already there is shared, and kept with its filters,
+	// the priorities of which are not reused.
+	created, err := addQdiscClsact(link.Attrs().Index)
+	assert.NoError(err)
+	assert.True(created)
+	assert.NoError(netlink.FilterAdd(&netlink.U32{
+		FilterAttrs: netlink.FilterAttrs{
+			LinkIndex: link.Attrs().Index,
+			Parent:    netlink.HANDLE_MIN_EGRESS,
+			Priority:  3,
+			Protocol:  unix.ETH_P_ALL,
+		},
+		Actions: []netlink.Action{&netlink.GenericAction{}},
+	}))
+
+	err = tapNetworkPair(context.Background(), endpoint, 1, true)
+	assert.NoError(err)
+	assert.True(endpoint.NetPair.SharedClsact)
+	assert.Equal(uint16(4), endpoint.NetPair.NetworkPolicyPriority)
+
+	for _, f := range endpoint.NetPair.VMFds {
+		f.Close()
+	}
+
+	err = untapNetworkPair(context.Background(), endpoint)
+	assert.NoError(err)
+
+	filters, err = netlink.FilterList(link, netlink.HANDLE_MIN_EGRESS)
+	assert.NoError(err)
+	assert.Len(filters, 1)
+
+	// Remove the veth created for testing.
+	err = netHandle.LinkDel(link)
+	assert.NoError(err)
+}
+
+func TestNetworkPolicyFilters(t *testing.T) {
+	assert := assert.New(t)
+
+	filters, err := networkPolicyFilters(&vctypes.NetworkPolicy{
+		Egress: []vctypes.NetworkPolicyRule{{CIDR: "10.0.0.0/8"}},
+	}, &netlink.GenericAction{}, 10)
+	assert.NoError(err)
+
+	// arp, neighbor discovery, 10.0.0.0/8, drop
+	assert.Len(filters, 8)
+	for i, filter := range filters {
+		assert.Equal(uint16(i+10), filter.Priority)
+	}
+	assert.Equal(uint16(unix.ETH_P_ARP), filters[0].Protocol)
+	for i, ndType := range networkPolicyNDTypes {
+		assert.Equal(uint16(unix.ETH_P_IPV6), filters[i+1].Protocol)
+		assert.Equal([]nl.TcU32Key{
+			{Mask: 0x0000ff00, Val: unix.IPPROTO_ICMPV6 << 8, Off: 4},
+			{Mask: 0xff000000, Val: ndType << 24, Off: 40},
+		}, filters[i+1].Sel.Keys)
+	}
+	assert.Equal(uint16(unix.ETH_P_IP), filters[6].Protocol)
+	assert.Equal(uint16(unix.ETH_P_ALL), filters[7].Protocol)
+
+	_, err = networkPolicyFilters(&vctypes.NetworkPolicy{
+		Egress: []vctypes.NetworkPolicyRule{{CIDR: "foo"}},
+	}, &netlink.GenericAction{}, 1)
+	assert.Error(err)
+
+	_, err = networkPolicyFilters(&vctypes.NetworkPolicy{}, &netlink.GenericAction{}, math.MaxUint16)
+	assert.Error(err)
+}
+
+func TestFirstFreePriority(t *testing.T) {
+	assert := assert.New(t)
+
+	prio, err := firstFreePriority(map[uint16]bool{}, 8)
+	assert.NoError(err)
+	assert.Equal(uint16(1), prio)
+
+	prio, err = firstFreePriority(map[uint16]bool{1: true, 5: true, 14: true}, 8)
+	assert.NoError(err)
+	assert.Equal(uint16(6), prio)
+
+	prio, err = firstFreePriority(map[uint16]bool{2: true, 4: true}, 1)
+	assert.NoError(err)
+	assert.Equal(uint16(1), prio)
+
+	_, err = firstFreePriority(map[uint16]bool{math.MaxUint16 / 2: true}, math.MaxUint16/2+2)
+	assert.Error(err)
+}
+
+func TestCheckNetworkPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	network := &LinuxNetwork{}
+	assert.NoError(network.checkNetworkPolicy(&PhysicalEndpoint{}))
+
+	network.networkPolicy = &vctypes.NetworkPolicy{}
+
+	for _, model := range []NetInterworkingModel{NetXConnectDefaultModel, NetXConnectMacVtapModel, NetXConnectTCFilterModel} {
+		endpoint, err := createVethNetworkEndpoint(1, "eth0", model)
+		assert.NoError(err)
+		assert.NoError(network.checkNetworkPolicy(endpoint))
+	}
+
+	endpoint, err := createVethNetworkEndpoint(1, "eth0", NetXConnectNoneModel)
+	assert.NoError(err)
+	assert.Error(network.checkNetworkPolicy(endpoint))
+
+	tuntap, err := createTuntapNetworkEndpoint(1, "tap0", nil, NetXConnectTCFilterModel)
+	assert.NoError(err)
+	assert.Error(network.checkNetworkPolicy(tuntap))
+
+	for _, endpoint := range []Endpoint{
+		&PhysicalEndpoint{},
+		&VfioEndpoint{},
+		&VhostUserEndpoint{},
+		&VdpaEndpoint{},
+		&PasstEndpoint{},
+		&pluginEndpoint{&testPluginEndpoint{netPair: &NetworkInterfacePair{NetInterworkingModel: NetXConnectTCFilterModel}}},
+	} {
+		assert.Error(network.checkNetworkPolicy(endpoint))
+	}
+}
+
+func TestNetworkPolicySelectors(t *testing.T) {
+	assert := assert.New(t)
+
+	protocol, sels, err := networkPolicySelectors(vctypes.NetworkPolicyRule{CIDR: "10.1.0.0/16"})
+	assert.NoError(err)
+	assert.Equal(uint16(unix.ETH_P_IP), protocol)
+	assert.Len(sels, 1)
+	assert.Equal([]nl.TcU32Key{{Mask: 0xffff0000, Val: 0x0a010000, Off: 16}}, sels[0].Keys)
+
+	protocol, sels, err = networkPolicySelectors(vctypes.NetworkPolicyRule{CIDR: "192.168.1.1/32", Protocol: "tcp", Ports: []uint16{80, 443}})
+	assert.NoError(err)
+	assert.Equal(uint16(unix.ETH_P_IP), protocol)
+	assert.Len(sels, 2)
+	assert.Len(sels[0].Keys, 4)
+	assert.Equal(nl.TcU32Key{Mask: 0x00ff0000, Val: unix.IPPROTO_TCP << 16, Off: 8}, sels[0].Keys[2])
+	assert.Equal(nl.TcU32Key{Mask: 0x0000ffff, Val: 80, Off: 20}, sels[0].Keys[3])
+	assert.Equal(nl.TcU32Key{Mask: 0x0000ffff, Val: 443, Off: 20}, sels[1].Keys[3])
+
+	protocol, sels, err = networkPolicySelectors(vctypes.NetworkPolicyRule{CIDR: "fd00:1::/32", Protocol: "udp", Ports: []uint16{53}})
+	assert.NoError(err)
+	assert.Equal(uint16(unix.ETH_P_IPV6), protocol)
+	assert.Len(sels, 1)
+	assert.Equal([]nl.TcU32Key{
+		{Mask: 0xffffffff, Val: 0xfd000001, Off: 24},
+		{Mask: 0x0000ff00, Val: unix.IPPROTO_UDP << 8, Off: 4},
+		{Mask: 0x0000ffff, Val: 53, Off: 40},
+	}, sels[0].Keys)
+
+	_, sels, err = networkPolicySelectors(vctypes.NetworkPolicyRule{CIDR: "::/0"})
+	assert.NoError(err)
+	assert.Equal([]nl.TcU32Key{{}}, sels[0].Keys)
+
+	_, _, err = networkPolicySelectors(vctypes.NetworkPolicyRule{CIDR: "foo"})
+	assert.Error(err)
+}
+
 func TestConvertDanDeviceToNetworkInfo(t *testing.T) {
 
 	jsonData, err := os.ReadFile("testdata/dan-config.json")
@@ -382,6 +707,98 @@ func TestAddEndpoints_Dan(t *testing.T) {
 	assert.Equal(t, ep.PciPath().String(), "")
 }
 
+func TestUpdateDanEndpoints(t *testing.T) {
+	assert := assert.New(t)
+
+	jsonData, err := os.ReadFile("testdata/dan-config.json")
+	assert.NoError(err)
+
+	danConfigPath := filepath.Join(t.TempDir(), "dan-config.json")
+	assert.NoError(os.WriteFile(danConfigPath, jsonData, 0600))
+
+	network := &LinuxNetwork{
+		netNSPath:         "net-123",
+		eps:               []Endpoint{},
+		interworkingModel: NetXConnectDefaultModel,
+		netNSCreated:      true,
+		danConfigPath:     danConfigPath,
+	}
+
+	ctx := context.TODO()
+	_, err = network.AddEndpoints(ctx, nil, nil, true)
+	assert.NoError(err)
+
+	// Only the routes of the existing interface change.
+	var config vctypes.DanConfig
+	assert.NoError(json.Unmarshal(jsonData, &config))
+	config.Devices[0].NetworkInfo.Routes = config.Devices[0].NetworkInfo.Routes[:1]
+	data, err := json.Marshal(config)
+	assert.NoError(err)
+	assert.NoError(os.WriteFile(danConfigPath, data, 0600))
+
+	changed, removed, err := network.UpdateDanEndpoints(ctx, &Sandbox{config: &SandboxConfig{}})
+	assert.NoError(err)
+	assert.Len(changed, 1)
+	assert.Empty(removed)
+	assert.Len(network.Endpoints(), 1)
+	assert.Len(network.Endpoints()[0].Properties().Routes, 1)
+
+	// Nothing changed since the last reload.
+	changed, removed, err = network.UpdateDanEndpoints(ctx, &Sandbox{config: &SandboxConfig{}})
+	assert.NoError(err)
+	assert.Empty(changed)
+	assert.Empty(removed)
+
+	// The VFIO device of a new interface can't be found on this host.
+	newDevice := config.Devices[0]
+	newDevice.Name = "net1"
+	newDevice.GuestMac = "0a:58:0a:0a:00:06"
+	newDevice.Device.PciDeviceID = "0000:ff:1f.7"
+	config.Devices = append(config.Devices, newDevice)
+	data, err = json.Marshal(config)
+	assert.NoError(err)
+	assert.NoError(os.WriteFile(danConfigPath, data, 0600))
+
+	_, _, err = network.UpdateDanEndpoints(ctx, &Sandbox{config: &SandboxConfig{}})
+	assert.Error(err)
+	assert.Len(network.Endpoints(), 1)
+
+	// The interfaces of a removed DAN config are left for the sandbox
+	// to remove.
+	assert.NoError(os.Remove(danConfigPath))
+	changed, removed, err = network.UpdateDanEndpoints(ctx, &Sandbox{config: &SandboxConfig{}})
+	assert.NoError(err)
+	assert.Empty(changed)
+	assert.Len(removed, 1)
+	assert.Equal("eth0", removed[0].Name())
+	assert.Len(network.Endpoints(), 1)
+
+	network.danConfigPath = ""
+	_, _, err = network.UpdateDanEndpoints(ctx, &Sandbox{config: &SandboxConfig{}})
+	assert.Error(err)
+}
+
+func TestSameDanEndpoint(t *testing.T) {
+	assert := assert.New(t)
+
+	netInfo := &NetworkInfo{}
+	netInfo.Iface.Name = "eth0"
+	netInfo.Iface.HardwareAddr, _ = net.ParseMAC("0a:58:0a:0a:00:05")
+
+	a, err := createVfioEndpoint("0000:85:02.5", netInfo)
+	assert.NoError(err)
+	b, err := createVfioEndpoint("0000:85:02.5", netInfo)
+	assert.NoError(err)
+	assert.True(sameDanEndpoint(a, b))
+
+	b.HostBDF = "0000:85:02.6"
+	assert.False(sameDanEndpoint(a, b))
+
+	b.HostBDF = a.HostBDF
+	b.Iface.HardAddr = "0a:58:0a:0a:00:06"
+	assert.False(sameDanEndpoint(a, b))
+}
+
 func TestValidGuestNeighbor(t *testing.T) {
 	assert := assert.New(t)
 
@@ -614,3 +1031,16 @@ func TestGatewaySetFromRoutes(t *testing.T) {
 		})
 	}
 }
+
+func TestEndpointBandwidth(t *testing.T) {
+	assert := assert.New(t)
+
+	n := &LinuxNetwork{
+		bandwidth:      NetworkBandwidth{Ingress: 1000, Egress: 1000},
+		ifaceBandwidth: map[string]NetworkBandwidth{"net1": {Ingress: 100}},
+	}
+	hConfig := HypervisorConfig{TxRateLimiterMaxRate: 500}
+
+	assert.Equal(NetworkBandwidth{Ingress: 1000, Egress: 500}, n.endpointBandwidth("eth0", hConfig))
+	assert.Equal(NetworkBandwidth{Ingress: 100, Egress: 500}, n.endpointBandwidth("net1", hConfig))
+}
@@ -72,3 +72,36 @@ func TestGenerateRandomPrivateMacAdd(t *testing.T) {
 
 	assert.NotEqual(addr1, addr2)
 }
+
+func TestNetworkBandwidthLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	operator := NetworkBandwidth{Ingress: 1000, Egress: 0}
+
+	assert.Equal(operator, operator.limit(NetworkBandwidth{}))
+	assert.Equal(NetworkBandwidth{Ingress: 500, Egress: 2000}, operator.limit(NetworkBandwidth{Ingress: 500, Egress: 2000}))
+	assert.Equal(NetworkBandwidth{Ingress: 1000, Egress: 2000}, operator.limit(NetworkBandwidth{Ingress: 5000, Egress: 2000}))
+}
+
+func TestParsePortForwards(t *testing.T) {
+	assert := assert.New(t)
+
+	forwards, err := ParsePortForwards("8080:80, 443,53/udp,5353:53/udp")
+	assert.NoError(err)
+	assert.Equal([]PortForward{
+		{Protocol: "tcp", HostPort: 8080, GuestPort: 80},
+		{Protocol: "tcp", HostPort: 443, GuestPort: 443},
+		{Protocol: "udp", HostPort: 53, GuestPort: 53},
+		{Protocol: "udp", HostPort: 5353, GuestPort: 53},
+	}, forwards)
+	assert.Equal("8080:80/tcp", forwards[0].String())
+
+	forwards, err = ParsePortForwards("")
+	assert.NoError(err)
+	assert.Empty(forwards)
+
+	for _, value := range []string{"80/sctp", "http", "0", "70000", "8080:", ":80"} {
+		_, err = ParsePortForwards(value)
+		assert.Error(err, value)
+	}
+}
@@ -0,0 +1,442 @@
+//go:build linux
+
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	persistapi "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/persist/api"
+	vcTypes "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/types"
+	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/utils"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// passtMTU is the MTU passt advertises to the guest by default.
+	passtMTU = 65520
+
+	passtSocketName = "passt.socket"
+)
+
+var (
+	passtTrace = getNetworkTrace(PasstEndpointType)
+
+	// passtSocketTimeout is how long passt has to listen on its socket
+	// once started.
+	passtSocketTimeout = 5 * time.Second
+
+	// startPasst starts a passt process and returns its PID.
+	startPasst = startPasstProcess
+
+	// isPasstProcess returns whether the process is the passt process
+	// listening on the socket.
+	isPasstProcess = func(pid int, socketPath string) bool {
+		return utils.ProcessHasArgs(pid, "--socket", socketPath)
+	}
+)
+
+// PasstEndpoint represents a user-mode networking interface, backed by a
+// passt process translating the guest traffic to host sockets. Unlike the
+// other endpoints, it does not need any network interface nor any privilege
+// on the host, which makes it usable from the host network namespace and by
+// rootless sandboxes.
+type PasstEndpoint struct {
+	// Path to the passt binary
+	PasstPath string
+	// Path to the unix socket passt listens on for the hypervisor
+	SocketPath string
+	// PID of the passt process
+	PID int
+	// Host ports forwarded to the guest
+	ForwardedPorts     []PortForward
+	HardAddr           string
+	IfaceName          string
+	EndpointProperties NetworkInfo
+	EndpointType       EndpointType
+	PCIPath            vcTypes.PciPath
+	CCWDevice          *vcTypes.CcwDevice
+}
+
+// Properties returns the properties of the interface.
+func (endpoint *PasstEndpoint) Properties() NetworkInfo {
+	return endpoint.EndpointProperties
+}
+
+// Name returns name of the interface.
+func (endpoint *PasstEndpoint) Name() string {
+	return endpoint.IfaceName
+}
+
+// HardwareAddr returns the mac address of the guest network interface
+func (endpoint *PasstEndpoint) HardwareAddr() string {
+	return endpoint.HardAddr
+}
+
+// Type indentifies the endpoint as a passt endpoint.
+func (endpoint *PasstEndpoint) Type() EndpointType {
+	return endpoint.EndpointType
+}
+
+// SetProperties sets the properties of the endpoint.
+func (endpoint *PasstEndpoint) SetProperties(properties NetworkInfo) {
+	endpoint.EndpointProperties = properties
+}
+
+// PciPath returns the PCI path of the endpoint.
+func (endpoint *PasstEndpoint) PciPath() vcTypes.PciPath {
+	return endpoint.PCIPath
+}
+
+// SetPciPath sets the PCI path of the endpoint.
+func (endpoint *PasstEndpoint) SetPciPath(pciPath vcTypes.PciPath) {
+	endpoint.PCIPath = pciPath
+}
+
+// CcwDevice returns the CCW device of the endpoint.
+func (endpoint *PasstEndpoint) CcwDevice() *vcTypes.CcwDevice {
+	return endpoint.CCWDevice
+}
+
+// SetCcwDevice sets the CCW device of the endpoint.
+func (endpoint *PasstEndpoint) SetCcwDevice(ccwDev vcTypes.CcwDevice) {
+	endpoint.CCWDevice = &ccwDev
+}
+
+// NetworkPair returns the network pair of the endpoint.
+func (endpoint *PasstEndpoint) NetworkPair() *NetworkInterfacePair {
+	return nil
+}
+
+// checkHypervisor checks that the sandbox hypervisor can connect to passt.
+func (endpoint *PasstEndpoint) checkHypervisor(s *Sandbox) error {
+	if s.config.HypervisorType != QemuHypervisor {
+		return fmt.Errorf("PasstEndpoint is not supported by hypervisor %s", s.config.HypervisorType)
+	}
+	return nil
+}
+
+// passtArgs returns the arguments passt is started with.
+func (endpoint *PasstEndpoint) passtArgs() []string {
+	args := []string{
+		// The runtime manages the process
+		"--foreground",
+		"--quiet",
+		"--socket", endpoint.SocketPath,
+		// Derive the guest addresses and routes from the same
+		// interface the guest one has been configured from.
+		"--interface", endpoint.IfaceName,
+		"--mtu", fmt.Sprint(passtMTU),
+	}
+
+	for _, port := range endpoint.ForwardedPorts {
+		flag := "--tcp-ports"
+		if port.Protocol == "udp" {
+			flag = "--udp-ports"
+		}
+		args = append(args, flag, fmt.Sprintf("%d:%d", port.HostPort, port.GuestPort))
+	}
+
+	return args
+}
+
+// startPasst starts passt in the current network namespace, and waits for
+// it to listen on its socket.
+func (endpoint *PasstEndpoint) startPasst(s *Sandbox) error {
+	dir := filepath.Join(s.config.HypervisorConfig.RunStorePath, s.id)
+	if err := os.MkdirAll(dir, DirMode); err != nil {
+		return err
+	}
+
+	socketPath, err := utils.BuildSocketPath(dir, passtSocketName)
+	if err != nil {
+		return err
+	}
+	endpoint.SocketPath = socketPath
+
+	args := endpoint.passtArgs()
+	networkLogger().WithField("path", endpoint.PasstPath).WithField("args", strings.Join(args, " ")).Info("Starting passt")
+
+	pid, err := startPasst(endpoint.PasstPath, args)
+	if err != nil {
+		return fmt.Errorf("could not start passt: %v", err)
+	}
+	endpoint.PID = pid
+
+	if err := waitForPasstSocket(socketPath); err != nil {
+		endpoint.stopPasst()
+		return err
+	}
+
+	return nil
+}
+
+// stopPasst stops the passt process, the guest network being unreachable
+// from then on.
+func (endpoint *PasstEndpoint) stopPasst() error {
+	if endpoint.PID == 0 {
+		return nil
+	}
+
+	// The PID may have been persisted by a previous shim, and recycled
+	// since passt quit.
+	if isPasstProcess(endpoint.PID, endpoint.SocketPath) {
+		if err := unix.Kill(endpoint.PID, unix.SIGTERM); err != nil && err != unix.ESRCH {
+			return fmt.Errorf("could not stop passt (pid %d): %v", endpoint.PID, err)
+		}
+	} else {
+		networkLogger().WithField("pid", endpoint.PID).Info("passt is not running anymore")
+	}
+	endpoint.PID = 0
+
+	if err := os.Remove(endpoint.SocketPath); err != nil && !os.IsNotExist(err) {
+		networkLogger().WithError(err).WithField("path", endpoint.SocketPath).Warn("Could not remove passt socket")
+	}
+
+	return nil
+}
+
+// Attach for passt endpoint starts passt and connects the hypervisor to it
+func (endpoint *PasstEndpoint) Attach(ctx context.Context, s *Sandbox) error {
+	span, ctx := passtTrace(ctx, "Attach", endpoint)
+	defer span.End()
+
+	if err := endpoint.checkHypervisor(s); err != nil {
+		return err
+	}
+
+	if err := endpoint.startPasst(s); err != nil {
+		return err
+	}
+
+	if err := s.hypervisor.AddDevice(ctx, endpoint, NetDev); err != nil {
+		endpoint.stopPasst()
+		return err
+	}
+	return nil
+}
+
+// Detach for passt endpoint stops passt
+func (endpoint *PasstEndpoint) Detach(ctx context.Context, netNsCreated bool, netNsPath string) error {
+	span, _ := passtTrace(ctx, "Detach", endpoint)
+	defer span.End()
+
+	return endpoint.stopPasst()
+}
+
+// HotAttach for passt endpoint starts passt and hot plugs the network device
+func (endpoint *PasstEndpoint) HotAttach(ctx context.Context, s *Sandbox) error {
+	span, ctx := passtTrace(ctx, "HotAttach", endpoint)
+	defer span.End()
+
+	if err := endpoint.checkHypervisor(s); err != nil {
+		return err
+	}
+
+	if err := endpoint.startPasst(s); err != nil {
+		return err
+	}
+
+	if _, err := s.hypervisor.HotplugAddDevice(ctx, endpoint, NetDev); err != nil {
+		networkLogger().WithError(err).Error("Error attach passt ep")
+		endpoint.stopPasst()
+		return err
+	}
+	return nil
+}
+
+// HotDetach for passt endpoint hot unplugs the network device and stops passt
+func (endpoint *PasstEndpoint) HotDetach(ctx context.Context, s *Sandbox, netNsCreated bool, netNsPath string) error {
+	span, ctx := passtTrace(ctx, "HotDetach", endpoint)
+	defer span.End()
+
+	if _, err := s.hypervisor.HotplugRemoveDevice(ctx, endpoint, NetDev); err != nil {
+		networkLogger().WithError(err).Error("Error detach passt ep")
+		return err
+	}
+
+	return endpoint.stopPasst()
+}
+
+// endpointHelperPids returns the PIDs of the host processes backing the
+// endpoints, that is of their passt processes.
+func endpointHelperPids(endpoints []Endpoint) []int {
+	var pids []int
+	for _, endpoint := range endpoints {
+		if passt, ok := endpoint.(*PasstEndpoint); ok && passt.PID != 0 {
+			pids = append(pids, passt.PID)
+		}
+	}
+	return pids
+}
+
+// createPasstEndpoint creates a passt endpoint, whose guest interface is
+// configured from netInfo.
+func createPasstEndpoint(netInfo NetworkInfo, passtPath string, forwardedPorts []PortForward) *PasstEndpoint {
+	return &PasstEndpoint{
+		PasstPath:      passtPath,
+		ForwardedPorts: forwardedPorts,
+		HardAddr:       netInfo.Iface.HardwareAddr.String(),
+		IfaceName:      netInfo.Iface.Name,
+		EndpointType:   PasstEndpointType,
+	}
+}
+
+// passtNetworkInfo returns the configuration of the guest interface of a
+// passt endpoint. Like passt does, it mirrors the addresses and the default
+// routes of the interface holding the default route of the current network
+// namespace.
+func passtNetworkInfo() (NetworkInfo, error) {
+	routes, err := netlink.RouteList(nil, netlink.FAMILY_ALL)
+	if err != nil {
+		return NetworkInfo{}, err
+	}
+
+	var link netlink.Link
+	var defaultRoutes []netlink.Route
+	for _, route := range routes {
+		if route.Gw == nil || !isDefaultRoute(route) {
+			continue
+		}
+
+		if link == nil {
+			if link, err = netlink.LinkByIndex(route.LinkIndex); err != nil {
+				return NetworkInfo{}, err
+			}
+		}
+
+		if route.LinkIndex == link.Attrs().Index {
+			defaultRoutes = append(defaultRoutes, route)
+		}
+	}
+
+	if link == nil {
+		return NetworkInfo{}, fmt.Errorf("no default route to configure the user-mode networking interface from")
+	}
+
+	addrs, err := netlink.AddrList(link, netlink.FAMILY_ALL)
+	if err != nil {
+		return NetworkInfo{}, err
+	}
+
+	var globalAddrs []netlink.Addr
+	for _, addr := range addrs {
+		if addr.Scope == unix.RT_SCOPE_UNIVERSE {
+			globalAddrs = append(globalAddrs, addr)
+		}
+	}
+
+	// The guest interface is a new one, as passt is not bridging the host
+	// interface.
+	hardAddr, err := generateRandomPrivateMacAddr()
+	if err != nil {
+		return NetworkInfo{}, err
+	}
+	hwAddr, err := net.ParseMAC(hardAddr)
+	if err != nil {
+		return NetworkInfo{}, err
+	}
+
+	return NetworkInfo{
+		Iface: NetlinkIface{
+			LinkAttrs: netlink.LinkAttrs{
+				Name:         link.Attrs().Name,
+				HardwareAddr: hwAddr,
+				MTU:          passtMTU,
+			},
+		},
+		Addrs:  globalAddrs,
+		Routes: defaultRoutes,
+	}, nil
+}
+
+// isDefaultRoute returns true if the route matches any destination.
+func isDefaultRoute(route netlink.Route) bool {
+	if route.Dst == nil {
+		return true
+	}
+	ones, _ := route.Dst.Mask.Size()
+	return ones == 0
+}
+
+func waitForPasstSocket(socketPath string) error {
+	deadline := time.Now().Add(passtSocketTimeout)
+	for {
+		if _, err := os.Stat(socketPath); err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("passt did not create its socket %s after %v", socketPath, passtSocketTimeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func startPasstProcess(path string, args []string) (int, error) {
+	cmd := exec.Command(path, args...)
+	if err := utils.StartCmd(cmd); err != nil {
+		return 0, err
+	}
+
+	go func() {
+		cmd.Wait()
+		networkLogger().WithField("pid", cmd.Process.Pid).Info("passt quits")
+	}()
+
+	return cmd.Process.Pid, nil
+}
+
+func (endpoint *PasstEndpoint) save() persistapi.NetworkEndpoint {
+	return persistapi.NetworkEndpoint{
+		Type: string(endpoint.Type()),
+		Passt: &persistapi.PasstEndpoint{
+			IfaceName:  endpoint.IfaceName,
+			HardAddr:   endpoint.HardAddr,
+			SocketPath: endpoint.SocketPath,
+			PID:        endpoint.PID,
+			PCIPath:    endpoint.PCIPath,
+		},
+	}
+}
+
+func (endpoint *PasstEndpoint) load(s persistapi.NetworkEndpoint) {
+	endpoint.EndpointType = PasstEndpointType
+
+	if s.Passt != nil {
+		endpoint.IfaceName = s.Passt.IfaceName
+		endpoint.HardAddr = s.Passt.HardAddr
+		endpoint.SocketPath = s.Passt.SocketPath
+		endpoint.PID = s.Passt.PID
+		endpoint.PCIPath = s.Passt.PCIPath
+	}
+}
+
+// unsupported
+func (endpoint *PasstEndpoint) GetRxRateLimiter() bool {
+	return false
+}
+
+func (endpoint *PasstEndpoint) SetRxRateLimiter() error {
+	return fmt.Errorf("rx rate limiter is unsupported for passt endpoint")
+}
+
+// unsupported
+func (endpoint *PasstEndpoint) GetTxRateLimiter() bool {
+	return false
+}
+
+func (endpoint *PasstEndpoint) SetTxRateLimiter() error {
+	return fmt.Errorf("tx rate limiter is unsupported for passt endpoint")
+}
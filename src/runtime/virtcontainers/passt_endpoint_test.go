@@ -0,0 +1,281 @@
+//go:build linux
+
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/containernetworking/plugins/pkg/ns"
+	"github.com/containernetworking/plugins/pkg/testutils"
+	ktu "github.com/kata-containers/kata-containers/src/runtime/pkg/katatestutils"
+	persistapi "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/persist/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+)
+
+// setupPasstFixture replaces passt with a process that only creates the
+// socket passt would listen on, and runs with the passt arguments.
+func setupPasstFixture(t *testing.T, createSocket bool) {
+	savedStartPasst, savedTimeout := startPasst, passtSocketTimeout
+
+	startPasst = func(path string, args []string) (int, error) {
+		for i, arg := range args {
+			if arg == "--socket" && createSocket {
+				if err := os.WriteFile(args[i+1], nil, 0600); err != nil {
+					return 0, err
+				}
+			}
+		}
+
+		cmd := exec.Command("sh", append([]string{"-c", "sleep 60; :", "passt"}, args...)...)
+		if err := cmd.Start(); err != nil {
+			return 0, err
+		}
+		go cmd.Wait()
+
+		return cmd.Process.Pid, nil
+	}
+	passtSocketTimeout = 100 * time.Millisecond
+
+	t.Cleanup(func() {
+		startPasst, passtSocketTimeout = savedStartPasst, savedTimeout
+	})
+}
+
+func TestPasstEndpointArgs(t *testing.T) {
+	assert := assert.New(t)
+
+	ep := &PasstEndpoint{
+		SocketPath: "/run/vc/sbs/foo/passt.socket",
+		IfaceName:  "eth0",
+		ForwardedPorts: []PortForward{
+			{Protocol: "tcp", HostPort: 8080, GuestPort: 80},
+			{Protocol: "udp", HostPort: 53, GuestPort: 53},
+		},
+	}
+
+	assert.Equal([]string{
+		"--foreground",
+		"--quiet",
+		"--socket", "/run/vc/sbs/foo/passt.socket",
+		"--interface", "eth0",
+		"--mtu", "65520",
+		"--tcp-ports", "8080:80",
+		"--udp-ports", "53:53",
+	}, ep.passtArgs())
+}
+
+func TestPasstEndpointAttach(t *testing.T) {
+	assert := assert.New(t)
+
+	setupPasstFixture(t, true)
+
+	ep := &PasstEndpoint{
+		PasstPath:    "/usr/bin/passt",
+		HardAddr:     "02:00:ca:fe:00:01",
+		IfaceName:    "eth0",
+		EndpointType: PasstEndpointType,
+	}
+
+	runStorePath := t.TempDir()
+	s := &Sandbox{
+		id: "passt-sandbox",
+		config: &SandboxConfig{
+			HypervisorType: QemuHypervisor,
+			HypervisorConfig: HypervisorConfig{
+				RunStorePath: runStorePath,
+			},
+		},
+		hypervisor: &mockHypervisor{},
+	}
+
+	assert.NoError(ep.Attach(context.Background(), s))
+	assert.NotZero(ep.PID)
+	assert.Equal(filepath.Join(runStorePath, "passt-sandbox", passtSocketName), ep.SocketPath)
+	assert.FileExists(ep.SocketPath)
+
+	assert.NoError(ep.Detach(context.Background(), true, ""))
+	assert.Zero(ep.PID)
+	assert.NoFileExists(ep.SocketPath)
+
+	assert.NoError(ep.HotAttach(context.Background(), s))
+	assert.NotZero(ep.PID)
+	assert.NoError(ep.HotDetach(context.Background(), s, true, ""))
+	assert.Zero(ep.PID)
+
+	s.config.HypervisorType = ClhHypervisor
+	assert.Error(ep.Attach(context.Background(), s))
+	assert.Error(ep.HotAttach(context.Background(), s))
+
+	assert.False(ep.GetRxRateLimiter())
+	assert.Error(ep.SetRxRateLimiter())
+	assert.False(ep.GetTxRateLimiter())
+	assert.Error(ep.SetTxRateLimiter())
+}
+
+func TestPasstEndpointStopRecycledPID(t *testing.T) {
+	assert := assert.New(t)
+
+	// The persisted PID is now the one of another process
+	cmd := exec.Command("sleep", "60")
+	assert.NoError(cmd.Start())
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	ep := &PasstEndpoint{
+		SocketPath:   filepath.Join(t.TempDir(), passtSocketName),
+		PID:          cmd.Process.Pid,
+		EndpointType: PasstEndpointType,
+	}
+
+	assert.NoError(ep.stopPasst())
+	assert.Zero(ep.PID)
+	assert.NoError(cmd.Process.Signal(syscall.Signal(0)))
+}
+
+func TestPasstEndpointAttachNoSocket(t *testing.T) {
+	assert := assert.New(t)
+
+	setupPasstFixture(t, false)
+
+	ep := &PasstEndpoint{
+		IfaceName:    "eth0",
+		EndpointType: PasstEndpointType,
+	}
+
+	s := &Sandbox{
+		id: "passt-sandbox",
+		config: &SandboxConfig{
+			HypervisorType: QemuHypervisor,
+			HypervisorConfig: HypervisorConfig{
+				RunStorePath: t.TempDir(),
+			},
+		},
+		hypervisor: &mockHypervisor{},
+	}
+
+	// passt is stopped if it does not listen on its socket
+	assert.Error(ep.Attach(context.Background(), s))
+	assert.Zero(ep.PID)
+}
+
+func TestPasstEndpointSaveLoad(t *testing.T) {
+	assert := assert.New(t)
+
+	ep := &PasstEndpoint{
+		SocketPath:   "/run/vc/sbs/foo/passt.socket",
+		PID:          1234,
+		HardAddr:     "02:00:ca:fe:00:01",
+		IfaceName:    "eth0",
+		EndpointType: PasstEndpointType,
+	}
+
+	saved := ep.save()
+	assert.Equal(string(PasstEndpointType), saved.Type)
+	assert.NotNil(saved.Passt)
+
+	loaded := &PasstEndpoint{}
+	loaded.load(saved)
+	assert.Equal(ep, loaded)
+}
+
+func TestAddPasstEndpoint(t *testing.T) {
+	if tc.NotValid(ktu.NeedRoot()) {
+		t.Skip(testDisabledAsNonRoot)
+	}
+
+	assert := assert.New(t)
+
+	setupPasstFixture(t, true)
+
+	n, err := testutils.NewNS()
+	assert.NoError(err)
+	defer n.Close()
+
+	netnsHandle, err := netns.GetFromPath(n.Path())
+	assert.NoError(err)
+	defer netnsHandle.Close()
+
+	netlinkHandle, err := netlink.NewHandleAt(netnsHandle)
+	assert.NoError(err)
+	defer netlinkHandle.Close()
+
+	network := &LinuxNetwork{
+		netNSPath:          n.Path(),
+		eps:                []Endpoint{},
+		userModeNetworking: true,
+		passtPath:          "/usr/bin/passt",
+		forwardedPorts:     []PortForward{{Protocol: "tcp", HostPort: 8080, GuestPort: 80}},
+	}
+	s := &Sandbox{
+		id: "passt-sandbox",
+		config: &SandboxConfig{
+			HypervisorType: QemuHypervisor,
+			HypervisorConfig: HypervisorConfig{
+				RunStorePath: t.TempDir(),
+			},
+		},
+		hypervisor: &mockHypervisor{},
+	}
+
+	// No default route to configure the guest from
+	_, err = network.AddEndpoints(context.Background(), s, nil, false)
+	assert.Error(err)
+
+	assert.NoError(netlinkHandle.LinkAdd(&netlink.Bridge{LinkAttrs: netlink.LinkAttrs{Name: "uplink0", TxQLen: -1}}))
+	link, err := netlinkHandle.LinkByName("uplink0")
+	assert.NoError(err)
+	assert.NoError(netlinkHandle.LinkSetUp(link))
+	addr, err := netlink.ParseAddr("192.168.10.2/24")
+	assert.NoError(err)
+	assert.NoError(netlinkHandle.AddrAdd(link, addr))
+	assert.NoError(netlinkHandle.RouteAdd(&netlink.Route{
+		LinkIndex: link.Attrs().Index,
+		Gw:        net.ParseIP("192.168.10.1"),
+	}))
+
+	eps, err := network.AddEndpoints(context.Background(), s, nil, false)
+	assert.NoError(err)
+	assert.Len(eps, 1)
+
+	ep := eps[0].(*PasstEndpoint)
+	assert.Equal("uplink0", ep.Name())
+	assert.NotEqual(link.Attrs().HardwareAddr.String(), ep.HardwareAddr())
+	assert.Equal(passtMTU, ep.Properties().Iface.MTU)
+	assert.Len(ep.Properties().Addrs, 1)
+	assert.Equal("192.168.10.2/24", ep.Properties().Addrs[0].IPNet.String())
+	assert.Len(ep.Properties().Routes, 1)
+	assert.Equal("192.168.10.1", ep.Properties().Routes[0].Gw.String())
+	assert.Equal(network.forwardedPorts, ep.ForwardedPorts)
+
+	// Only one passt endpoint per sandbox
+	eps, err = network.AddEndpoints(context.Background(), s, nil, false)
+	assert.NoError(err)
+	assert.Len(eps, 1)
+
+	loaded := LoadNetwork(persistapi.NetworkInfo{
+		Endpoints: []persistapi.NetworkEndpoint{ep.save()},
+	})
+	assert.Len(loaded.Endpoints(), 1)
+	assert.Equal(ep.PID, loaded.Endpoints()[0].(*PasstEndpoint).PID)
+
+	assert.NoError(doNetNS(n.Path(), func(_ ns.NetNS) error {
+		return network.RemoveEndpoints(context.Background(), s, nil, false)
+	}))
+	assert.Empty(network.Endpoints())
+}
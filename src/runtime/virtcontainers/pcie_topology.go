@@ -0,0 +1,240 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
+	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/types"
+)
+
+// ErrPCIePortCapacity means that the devices do not fit on the PCIe ports of
+// the VM.
+var ErrPCIePortCapacity = errors.New("not enough PCIe ports")
+
+// PCIeTopology is the map of the PCI(e) buses of the VM and of the devices
+// plugged to them.
+type PCIeTopology struct {
+	// Bridges are the PCI bridges of the VM
+	Bridges []PCIeBus `json:"bridges,omitempty"`
+	// RootPorts are the PCIe root ports of the VM, free or not
+	RootPorts []PCIeBus `json:"root_ports,omitempty"`
+	// SwitchPorts are the downstream ports of the PCIe switch of the VM,
+	// free or not
+	SwitchPorts []PCIeBus `json:"switch_ports,omitempty"`
+}
+
+// PCIeBus is a PCI bridge or a PCIe port, and the devices plugged to it.
+type PCIeBus struct {
+	// ID is the ID of the bus in the hypervisor
+	ID string `json:"id"`
+	// Capacity is the number of devices the bus takes
+	Capacity uint32 `json:"capacity"`
+	// Devices are the devices plugged to the bus
+	Devices []PCIeSlot `json:"devices,omitempty"`
+}
+
+// PCIeSlot is a device plugged to a PCIe bus.
+type PCIeSlot struct {
+	// Device is the ID of the device in the hypervisor
+	Device string `json:"device"`
+	// BDF is the address of the device on the host, for VFIO devices
+	BDF string `json:"bdf,omitempty"`
+	// PciPath is the path of the device in the guest, once known
+	PciPath string `json:"pci_path,omitempty"`
+}
+
+// pciePortConfig returns the configuration option and the maximum of the
+// number of ports of the given type.
+func pciePortConfig(port config.PCIePort) (string, uint32) {
+	if port == config.SwitchPort {
+		return "pcie_switch_port", config.MaxPCIeSwitchPort
+	}
+	return "pcie_root_port", config.MaxPCIeRootPort
+}
+
+// PlanPCIePorts returns the number of ports of the given type to create for
+// the devices plugged to them when the VM boots. Each device gets a port, and
+// pcie_hotplug_ports more are reserved for the hotplugs when the devices are
+// hot plugged to this type of port. The ports configured are the minimum to
+// create: the ones left free are used by the hotplugs too.
+func PlanPCIePorts(hypervisorConfig *HypervisorConfig, port config.PCIePort, devices uint32) (uint32, error) {
+	var configured uint32
+
+	switch port {
+	case config.RootPort:
+		configured = hypervisorConfig.PCIeRootPort
+	case config.SwitchPort:
+		configured = hypervisorConfig.PCIeSwitchPort
+	default:
+		return 0, nil
+	}
+
+	var reserved uint32
+	if hypervisorConfig.HotPlugVFIO == port {
+		reserved = hypervisorConfig.PCIeHotplugPorts
+	}
+
+	ports := devices + reserved
+	if ports < configured {
+		ports = configured
+	}
+
+	option, maxPorts := pciePortConfig(port)
+	if ports > maxPorts {
+		return 0, fmt.Errorf("%w: %d devices plugged at boot, pcie_hotplug_ports = %d and %s = %d need %d %s ports, at most %d are supported: lower %s or pcie_hotplug_ports, or plug fewer devices",
+			ErrPCIePortCapacity, devices, reserved, option, configured, ports, port, maxPorts, option)
+	}
+
+	return ports, nil
+}
+
+// checkPCIePortBus checks that the bus a device is assigned to is one of the
+// ports of the given type the VM was created with. No ports means that they
+// were not planned, as for the VMs started by older runtimes, and any bus is
+// accepted.
+func checkPCIePortBus(port config.PCIePort, bus string, ports uint32) error {
+	if ports == 0 {
+		return nil
+	}
+
+	index, err := strconv.ParseUint(strings.TrimPrefix(bus, config.PCIePortPrefixMapping[port].String()), 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid %s bus %q: %v", port, bus, err)
+	}
+
+	if uint32(index) < ports {
+		return nil
+	}
+
+	option, maxPorts := pciePortConfig(port)
+	return fmt.Errorf("%w: all the %d %s ports of the VM are in use, raise %s or pcie_hotplug_ports in the configuration (at most %d ports) to plug more devices",
+		ErrPCIePortCapacity, ports, port, option, maxPorts)
+}
+
+// PCIeTopology returns the map of the PCI(e) buses of the VM and of the
+// devices plugged to them.
+func (s *Sandbox) PCIeTopology(ctx context.Context) (PCIeTopology, error) {
+	var topology PCIeTopology
+
+	state := s.hypervisor.Save()
+
+	for _, bridge := range state.Bridges {
+		bus := PCIeBus{
+			ID:       bridge.ID,
+			Capacity: types.NewBridge(types.Type(bridge.Type), bridge.ID, nil, bridge.Addr).MaxCapacity,
+		}
+
+		slots := make([]uint32, 0, len(bridge.DeviceAddr))
+		for slot := range bridge.DeviceAddr {
+			slots = append(slots, slot)
+		}
+		sort.Slice(slots, func(i, j int) bool { return slots[i] < slots[j] })
+
+		for _, slot := range slots {
+			pciSlot := PCIeSlot{Device: bridge.DeviceAddr[slot]}
+			if path, err := pciPathOnBridge(bridge.Addr, slot); err == nil {
+				pciSlot.PciPath = path.String()
+			}
+			bus.Devices = append(bus.Devices, pciSlot)
+		}
+
+		topology.Bridges = append(topology.Bridges, bus)
+	}
+
+	guestPciPaths := s.vfioGuestPciPaths()
+	topology.RootPorts = pciePorts(config.RootPort, state.PCIeRootPort, guestPciPaths)
+	topology.SwitchPorts = pciePorts(config.SwitchPort, state.PCIeSwitchPort, guestPciPaths)
+
+	return topology, nil
+}
+
+// pciPathOnBridge returns the PCI path of the device in the slot of the bridge
+// in the slot addr of the root bus.
+func pciPathOnBridge(addr int, slot uint32) (types.PciPath, error) {
+	bridgeSlot, err := types.PciSlotFromInt(addr)
+	if err != nil {
+		return types.PciPath{}, err
+	}
+
+	devSlot, err := types.PciSlotFromInt(int(slot))
+	if err != nil {
+		return types.PciPath{}, err
+	}
+
+	return types.PciPathFromSlots(bridgeSlot, devSlot)
+}
+
+// pciePorts lists the ports of the given type, with the devices plugged to
+// them.
+func pciePorts(port config.PCIePort, number uint32, guestPciPaths map[string]string) []PCIeBus {
+	prefix := config.PCIePortPrefixMapping[port].String()
+
+	ports := make([]PCIeBus, 0, number)
+	for i := uint32(0); i < number; i++ {
+		ports = append(ports, PCIeBus{
+			ID:       fmt.Sprintf("%s%d", prefix, i),
+			Capacity: 1,
+		})
+	}
+
+	for i, dev := range config.PCIeDevicesPerPort[port] {
+		// The devices which are not VFIO ones are plugged to the
+		// next free port, and have no bus set.
+		bus := dev.Bus
+		if bus == "" {
+			bus = fmt.Sprintf("%s%d", prefix, i)
+		}
+
+		for p := range ports {
+			if ports[p].ID == bus {
+				ports[p].Devices = append(ports[p].Devices, PCIeSlot{
+					Device:  dev.ID,
+					BDF:     dev.BDF,
+					PciPath: guestPciPaths[dev.ID],
+				})
+				break
+			}
+		}
+	}
+
+	return ports
+}
+
+// vfioGuestPciPaths returns the PCI path in the guest of the VFIO devices
+// attached to the sandbox, by device ID.
+func (s *Sandbox) vfioGuestPciPaths() map[string]string {
+	paths := make(map[string]string)
+	if s.devManager == nil {
+		return paths
+	}
+
+	for _, device := range s.devManager.GetAllDevices() {
+		if device.DeviceType() != config.DeviceVFIO {
+			continue
+		}
+
+		vfioDevices, ok := device.GetDeviceInfo().([]*config.VFIODev)
+		if !ok {
+			continue
+		}
+
+		for _, dev := range vfioDevices {
+			if dev.GuestPciPath.IsNil() {
+				continue
+			}
+			paths[dev.ID] = dev.GuestPciPath.String()
+		}
+	}
+
+	return paths
+}
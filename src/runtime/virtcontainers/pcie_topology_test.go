@@ -0,0 +1,109 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
+	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanPCIePorts(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, tc := range []struct {
+		hypervisorConfig HypervisorConfig
+		port             config.PCIePort
+		devices          uint32
+		ports            uint32
+		fits             bool
+	}{
+		// The ports configured are the minimum for cold plug
+		{HypervisorConfig{ColdPlugVFIO: config.RootPort, PCIeRootPort: 4}, config.RootPort, 2, 4, true},
+		{HypervisorConfig{ColdPlugVFIO: config.RootPort, PCIeRootPort: 4}, config.RootPort, 6, 6, true},
+		// and for hotplug, the ports left free are hot plugged to
+		{HypervisorConfig{ColdPlugVFIO: config.RootPort, HotPlugVFIO: config.RootPort, PCIeRootPort: 4}, config.RootPort, 2, 4, true},
+		{HypervisorConfig{HotPlugVFIO: config.SwitchPort, PCIeSwitchPort: 3}, config.SwitchPort, 0, 3, true},
+		{HypervisorConfig{ColdPlugVFIO: config.RootPort, HotPlugVFIO: config.RootPort, PCIeRootPort: config.MaxPCIeRootPort}, config.RootPort, 1, config.MaxPCIeRootPort, true},
+		{HypervisorConfig{ColdPlugVFIO: config.RootPort, HotPlugVFIO: config.RootPort}, config.RootPort, config.MaxPCIeRootPort + 1, 0, false},
+		// Ports are reserved for the hotplugs on top of the cold plugged devices
+		{HypervisorConfig{ColdPlugVFIO: config.RootPort, HotPlugVFIO: config.RootPort, PCIeRootPort: 4, PCIeHotplugPorts: 3}, config.RootPort, 2, 5, true},
+		{HypervisorConfig{ColdPlugVFIO: config.RootPort, HotPlugVFIO: config.RootPort, PCIeRootPort: 4, PCIeHotplugPorts: 3}, config.RootPort, 0, 4, true},
+		{HypervisorConfig{ColdPlugVFIO: config.RootPort, HotPlugVFIO: config.RootPort, PCIeHotplugPorts: 2}, config.RootPort, config.MaxPCIeRootPort - 1, 0, false},
+		// but not when the devices are hot plugged to other ports
+		{HypervisorConfig{ColdPlugVFIO: config.RootPort, HotPlugVFIO: config.BridgePort, PCIeHotplugPorts: 3}, config.RootPort, 2, 2, true},
+		{HypervisorConfig{ColdPlugVFIO: config.SwitchPort}, config.SwitchPort, config.MaxPCIeSwitchPort + 1, 0, false},
+		{HypervisorConfig{ColdPlugVFIO: config.BridgePort}, config.BridgePort, 40, 0, true},
+	} {
+		ports, err := PlanPCIePorts(&tc.hypervisorConfig, tc.port, tc.devices)
+		if tc.fits {
+			assert.NoError(err, "%+v", tc)
+			assert.Equal(tc.ports, ports, "%+v", tc)
+		} else {
+			assert.ErrorIs(err, ErrPCIePortCapacity, "%+v", tc)
+		}
+	}
+}
+
+func TestCheckPCIePortBus(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.NoError(checkPCIePortBus(config.RootPort, "rp1", 2))
+	assert.NoError(checkPCIePortBus(config.SwitchPort, "swdp0", 1))
+
+	err := checkPCIePortBus(config.RootPort, "rp2", 2)
+	assert.ErrorIs(err, ErrPCIePortCapacity)
+	assert.Contains(err.Error(), "pcie_root_port")
+
+	err = checkPCIePortBus(config.SwitchPort, "swdp1", 1)
+	assert.ErrorIs(err, ErrPCIePortCapacity)
+	assert.Contains(err.Error(), "pcie_switch_port")
+
+	// The ports of a VM persisted without them were not planned
+	assert.NoError(checkPCIePortBus(config.RootPort, "rp3", 0))
+
+	assert.Error(checkPCIePortBus(config.RootPort, "bp0", 2))
+}
+
+func TestSandboxPCIeTopology(t *testing.T) {
+	assert := assert.New(t)
+
+	previous := config.PCIeDevicesPerPort
+	t.Cleanup(func() { config.PCIeDevicesPerPort = previous })
+	config.PCIeDevicesPerPort = map[config.PCIePort][]config.VFIODev{
+		config.RootPort: {
+			{ID: "vfio-gpu", BDF: "0000:04:00.0", Bus: "rp0"},
+			{ID: "virtio-net"},
+		},
+	}
+
+	bridge := types.NewBridge(types.PCI, "pci-bridge-0", map[uint32]string{2: "virtio-blk", 1: "virtio-scsi"}, 2)
+	q := &qemu{
+		arch:  &qemuArchBase{Bridges: []types.Bridge{bridge}},
+		state: QemuState{PCIeRootPort: 3},
+	}
+	s := &Sandbox{hypervisor: q}
+
+	topology, err := s.PCIeTopology(context.Background())
+	assert.NoError(err)
+
+	assert.Len(topology.Bridges, 1)
+	assert.Equal(uint32(types.PCIBridgeMaxCapacity), topology.Bridges[0].Capacity)
+	assert.Equal([]PCIeSlot{
+		{Device: "virtio-scsi", PciPath: "02/01"},
+		{Device: "virtio-blk", PciPath: "02/02"},
+	}, topology.Bridges[0].Devices)
+
+	assert.Equal([]PCIeBus{
+		{ID: "rp0", Capacity: 1, Devices: []PCIeSlot{{Device: "vfio-gpu", BDF: "0000:04:00.0"}}},
+		{ID: "rp1", Capacity: 1, Devices: []PCIeSlot{{Device: "virtio-net"}}},
+		{ID: "rp2", Capacity: 1},
+	}, topology.RootPorts)
+	assert.Empty(topology.SwitchPorts)
+}
@@ -7,9 +7,12 @@ package virtcontainers
 
 import (
 	"errors"
+	"fmt"
+	"syscall"
 
 	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/api"
 	devconfig "github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
+	deviceManager "github.com/kata-containers/kata-containers/src/runtime/pkg/device/manager"
 	hv "github.com/kata-containers/kata-containers/src/runtime/pkg/hypervisors"
 	exp "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/experimental"
 	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/persist"
@@ -91,6 +94,7 @@ func (s *Sandbox) dumpDevices(ss *persistapi.SandboxState, cs map[string]persist
 				UID:           dev.UID,
 				GID:           dev.GID,
 				Shared:        dev.Shared,
+				ErofsLayer:    dev.ErofsLayer,
 			})
 		}
 
@@ -164,14 +168,26 @@ func (s *Sandbox) dumpAgent(ss *persistapi.SandboxState) {
 	}
 }
 
-func (s *Sandbox) dumpNetwork(ss *persistapi.SandboxState) {
+func (s *Sandbox) dumpNetwork(ss *persistapi.SandboxState) error {
 	ss.Network = persistapi.NetworkInfo{
 		NetworkID:      s.network.NetworkID(),
 		NetworkCreated: s.network.NetworkCreated(),
 	}
 	for _, e := range s.network.Endpoints() {
+		// The plugins serialize the state of their endpoints, do not
+		// persist an endpoint that could not be restored.
+		if p, ok := e.(*pluginEndpoint); ok {
+			state, err := p.saveState()
+			if err != nil {
+				return err
+			}
+			ss.Network.Endpoints = append(ss.Network.Endpoints, state)
+			continue
+		}
 		ss.Network.Endpoints = append(ss.Network.Endpoints, e.save())
 	}
+
+	return nil
 }
 
 func (s *Sandbox) dumpConfig(ss *persistapi.SandboxState) {
@@ -179,10 +195,11 @@ func (s *Sandbox) dumpConfig(ss *persistapi.SandboxState) {
 	ss.Config = persistapi.SandboxConfig{
 		HypervisorType: string(sconfig.HypervisorType),
 		NetworkConfig: persistapi.NetworkConfig{
-			NetworkID:         sconfig.NetworkConfig.NetworkID,
-			NetworkCreated:    sconfig.NetworkConfig.NetworkCreated,
-			DisableNewNetwork: sconfig.NetworkConfig.DisableNewNetwork,
-			InterworkingModel: int(sconfig.NetworkConfig.InterworkingModel),
+			NetworkID:          sconfig.NetworkConfig.NetworkID,
+			NetworkCreated:     sconfig.NetworkConfig.NetworkCreated,
+			DisableNewNetwork:  sconfig.NetworkConfig.DisableNewNetwork,
+			InterworkingModel:  int(sconfig.NetworkConfig.InterworkingModel),
+			UserModeNetworking: sconfig.NetworkConfig.UserModeNetworking,
 		},
 
 		ShmSize:             sconfig.ShmSize,
@@ -192,6 +209,7 @@ func (s *Sandbox) dumpConfig(ss *persistapi.SandboxState) {
 		DisableGuestSeccomp: sconfig.DisableGuestSeccomp,
 		EnableVCPUsPinning:  sconfig.EnableVCPUsPinning,
 		GuestSeLinuxLabel:   sconfig.GuestSeLinuxLabel,
+		ErofsLayerCacheDir:  sconfig.ErofsLayerCacheDir,
 	}
 
 	ss.Config.SandboxBindMounts = append(ss.Config.SandboxBindMounts, sconfig.SandboxBindMounts...)
@@ -230,6 +248,9 @@ func (s *Sandbox) dumpConfig(ss *persistapi.SandboxState) {
 		VirtioFSDaemon:                sconfig.HypervisorConfig.VirtioFSDaemon,
 		VirtioFSDaemonList:            sconfig.HypervisorConfig.VirtioFSDaemonList,
 		VirtioFSCache:                 sconfig.HypervisorConfig.VirtioFSCache,
+		VirtioFSExternalSocket:        sconfig.HypervisorConfig.VirtioFSExternalSocket,
+		VirtioFSExternalRestartHook:   sconfig.HypervisorConfig.VirtioFSExternalRestartHook,
+		VirtioFSExternalHealthCheck:   sconfig.HypervisorConfig.VirtioFSExternalHealthCheck,
 		VirtioFSExtraArgs:             sconfig.HypervisorConfig.VirtioFSExtraArgs[:],
 		BlockDeviceCacheSet:           sconfig.HypervisorConfig.BlockDeviceCacheSet,
 		BlockDeviceCacheDirect:        sconfig.HypervisorConfig.BlockDeviceCacheDirect,
@@ -250,6 +271,12 @@ func (s *Sandbox) dumpConfig(ss *persistapi.SandboxState) {
 		BootFromTemplate:              sconfig.HypervisorConfig.BootFromTemplate,
 		DisableVhostNet:               sconfig.HypervisorConfig.DisableVhostNet,
 		EnableVhostUserStore:          sconfig.HypervisorConfig.EnableVhostUserStore,
+		EnableTPM:                     sconfig.HypervisorConfig.EnableTPM,
+		SwtpmPath:                     sconfig.HypervisorConfig.SwtpmPath,
+		TPMModel:                      sconfig.HypervisorConfig.TPMModel,
+		TPMStatePath:                  sconfig.HypervisorConfig.TPMStatePath,
+		TPMStateID:                    sconfig.HypervisorConfig.TPMStateID,
+		HostSysfsRoot:                 sconfig.HypervisorConfig.HostSysfsRoot,
 		SeccompSandbox:                sconfig.HypervisorConfig.SeccompSandbox,
 		VhostUserStorePath:            sconfig.HypervisorConfig.VhostUserStorePath,
 		VhostUserStorePathList:        sconfig.HypervisorConfig.VhostUserStorePathList,
@@ -288,14 +315,17 @@ func (s *Sandbox) Save() error {
 	s.dumpProcess(cs)
 	s.dumpMounts(cs)
 	s.dumpAgent(&ss)
-	s.dumpNetwork(&ss)
+	if err := s.dumpNetwork(&ss); err != nil {
+		return err
+	}
 	s.dumpConfig(&ss)
 
 	if err := s.store.ToDisk(ss, cs); err != nil {
 		return err
 	}
 
-	return nil
+	// The hotplugs done so far are now part of the sandbox state.
+	return s.pruneHotplugJournal()
 }
 
 func (s *Sandbox) loadState(ss persistapi.SandboxState) {
@@ -341,6 +371,7 @@ func (c *Container) loadContDevices(cs persistapi.ContainerState) {
 			UID:           dev.UID,
 			GID:           dev.GID,
 			Shared:        dev.Shared,
+			ErofsLayer:    dev.ErofsLayer,
 		})
 	}
 }
@@ -405,6 +436,32 @@ func (c *Container) Restore() error {
 	return nil
 }
 
+// restoreVFIOHostDrivers binds the VFIO devices of a sandbox back to their
+// host drivers from its persisted state, when the sandbox cannot be fetched or
+// deleted after a runtime crash. The devices are only released once the VM is
+// gone.
+func restoreVFIOHostDrivers(id string) error {
+	store, err := persist.GetDriver()
+	if err != nil || store == nil {
+		return errors.New("failed to get fs persist driver")
+	}
+
+	ss, _, err := store.FromDisk(id)
+	if err != nil {
+		return err
+	}
+
+	if pid := ss.HypervisorState.Pid; pid > 0 && syscall.Kill(pid, syscall.Signal(0)) == nil {
+		return fmt.Errorf("hypervisor process %d is still running", pid)
+	}
+
+	sysfs := devconfig.NewRootedSysfs("/")
+	if ss.Config.HypervisorConfig.HostSysfsRoot != "" {
+		sysfs = devconfig.NewRootedSysfs(ss.Config.HypervisorConfig.HostSysfsRoot)
+	}
+	return deviceManager.RestoreHostDriversFromState(sysfs, ss.Devices)
+}
+
 func loadSandboxConfig(id string) (*SandboxConfig, error) {
 	store, err := persist.GetDriver()
 	if err != nil || store == nil {
@@ -421,10 +478,11 @@ func loadSandboxConfig(id string) (*SandboxConfig, error) {
 		ID:             id,
 		HypervisorType: HypervisorType(savedConf.HypervisorType),
 		NetworkConfig: NetworkConfig{
-			NetworkID:         savedConf.NetworkConfig.NetworkID,
-			NetworkCreated:    savedConf.NetworkConfig.NetworkCreated,
-			DisableNewNetwork: savedConf.NetworkConfig.DisableNewNetwork,
-			InterworkingModel: NetInterworkingModel(savedConf.NetworkConfig.InterworkingModel),
+			NetworkID:          savedConf.NetworkConfig.NetworkID,
+			NetworkCreated:     savedConf.NetworkConfig.NetworkCreated,
+			DisableNewNetwork:  savedConf.NetworkConfig.DisableNewNetwork,
+			InterworkingModel:  NetInterworkingModel(savedConf.NetworkConfig.InterworkingModel),
+			UserModeNetworking: savedConf.NetworkConfig.UserModeNetworking,
 		},
 
 		ShmSize:             savedConf.ShmSize,
@@ -434,6 +492,7 @@ func loadSandboxConfig(id string) (*SandboxConfig, error) {
 		DisableGuestSeccomp: savedConf.DisableGuestSeccomp,
 		EnableVCPUsPinning:  savedConf.EnableVCPUsPinning,
 		GuestSeLinuxLabel:   savedConf.GuestSeLinuxLabel,
+		ErofsLayerCacheDir:  savedConf.ErofsLayerCacheDir,
 	}
 	sconfig.SandboxBindMounts = append(sconfig.SandboxBindMounts, savedConf.SandboxBindMounts...)
 
@@ -472,6 +531,9 @@ func loadSandboxConfig(id string) (*SandboxConfig, error) {
 		VirtioFSDaemon:                hconf.VirtioFSDaemon,
 		VirtioFSDaemonList:            hconf.VirtioFSDaemonList,
 		VirtioFSCache:                 hconf.VirtioFSCache,
+		VirtioFSExternalSocket:        hconf.VirtioFSExternalSocket,
+		VirtioFSExternalRestartHook:   hconf.VirtioFSExternalRestartHook,
+		VirtioFSExternalHealthCheck:   hconf.VirtioFSExternalHealthCheck,
 		VirtioFSExtraArgs:             hconf.VirtioFSExtraArgs[:],
 		BlockDeviceCacheSet:           hconf.BlockDeviceCacheSet,
 		BlockDeviceCacheDirect:        hconf.BlockDeviceCacheDirect,
@@ -496,6 +558,12 @@ func loadSandboxConfig(id string) (*SandboxConfig, error) {
 		BootFromTemplate:              hconf.BootFromTemplate,
 		DisableVhostNet:               hconf.DisableVhostNet,
 		EnableVhostUserStore:          hconf.EnableVhostUserStore,
+		EnableTPM:                     hconf.EnableTPM,
+		SwtpmPath:                     hconf.SwtpmPath,
+		TPMModel:                      hconf.TPMModel,
+		TPMStatePath:                  hconf.TPMStatePath,
+		TPMStateID:                    hconf.TPMStateID,
+		HostSysfsRoot:                 hconf.HostSysfsRoot,
 		VhostUserStorePath:            hconf.VhostUserStorePath,
 		VhostUserStorePathList:        hconf.VhostUserStorePathList,
 		GuestHookPath:                 hconf.GuestHookPath,
@@ -70,6 +70,18 @@ type HypervisorConfig struct {
 	// VirtioFSCache cache mode for fs version cache
 	VirtioFSCache string
 
+	// VirtioFSExternalSocket is the vhost-user-fs socket of the daemon the
+	// operator runs for the virtio-fs-external shared file system
+	VirtioFSExternalSocket string
+
+	// VirtioFSExternalRestartHook is run to (re)start the external
+	// vhost-user-fs daemon
+	VirtioFSExternalRestartHook string
+
+	// VirtioFSExternalHealthCheck is run to check the external
+	// vhost-user-fs daemon
+	VirtioFSExternalHealthCheck string
+
 	// File based memory backend root directory
 	FileBackedMemRootDir string
 
@@ -221,6 +233,24 @@ type HypervisorConfig struct {
 
 	// EnableVhostUserStore is used to indicate if host supports vhost-user-blk/scsi
 	EnableVhostUserStore bool
+
+	// EnableTPM attaches a vTPM to the VM, backed by swtpm
+	EnableTPM bool
+
+	// SwtpmPath is the swtpm binary path
+	SwtpmPath string
+
+	// TPMModel is the TPM interface the guest sees
+	TPMModel string
+
+	// TPMStatePath is the host directory the TPM states are kept in
+	TPMStatePath string
+
+	// TPMStateID is the identity the TPM state of the sandbox is kept under
+	TPMStateID string
+
+	// HostSysfsRoot is the directory the host /sys and /dev are found under
+	HostSysfsRoot string
 }
 
 // KataAgentConfig is a structure storing information needed
@@ -238,10 +268,11 @@ type ShimConfig struct {
 
 // NetworkConfig is the network configuration related to a network.
 type NetworkConfig struct {
-	NetworkID         string
-	NetworkCreated    bool
-	DisableNewNetwork bool
-	InterworkingModel int
+	NetworkID          string
+	NetworkCreated     bool
+	DisableNewNetwork  bool
+	InterworkingModel  int
+	UserModeNetworking bool
 }
 
 type ContainerConfig struct {
@@ -303,4 +334,7 @@ type SandboxConfig struct {
 
 	// EnableVCPUsPinning controls whether each vCPU thread should be scheduled to a fixed CPU
 	EnableVCPUsPinning bool
+
+	// ErofsLayerCacheDir is the directory of the node-level erofs layer cache
+	ErofsLayerCacheDir string
 }
@@ -34,6 +34,10 @@ type DeviceMap struct {
 
 	// Shared indicates whether the device is shared across containers
 	Shared bool
+
+	// ErofsLayer indicates whether the device is a layer blob of the erofs
+	// layer cache
+	ErofsLayer bool
 }
 
 // Mount describes a container mount.
@@ -14,6 +14,11 @@ type PersistDriver interface {
 	FromDisk(sid string) (SandboxState, map[string]ContainerState, error)
 	// Destroy will remove everything from storage
 	Destroy(sid string) error
+	// ToJournal records the hotplug intents of sandbox with `sid`. Unlike
+	// ToDisk, it can be called while the sandbox state is being changed.
+	ToJournal(sid string, intents []HotplugIntent) error
+	// FromJournal returns the hotplug intents recorded for sandbox with `sid`.
+	FromJournal(sid string) ([]HotplugIntent, error)
 	// Lock locks the persist driver, "exclusive" decides whether the lock is exclusive or shared.
 	// It returns Unlock Function and errors
 	Lock(sid string, exclusive bool) (func() error, error)
@@ -25,4 +30,13 @@ type PersistDriver interface {
 	// RunVMStoragePath is the vm directory.
 	// It will contain all guest vm sockets and shared mountpoints.
 	RunVMStoragePath() string
+
+	// GlobalWrite writes data to a path relative to the storage root,
+	// which is shared by all the sandboxes of the node.
+	GlobalWrite(relativePath string, data []byte) error
+	// GlobalRead reads data from a path relative to the storage root.
+	GlobalRead(relativePath string) ([]byte, error)
+	// GlobalLock locks a path relative to the storage root across all the
+	// sandboxes of the node. It returns Unlock Function and errors
+	GlobalLock(relativePath string, exclusive bool) (func() error, error)
 }
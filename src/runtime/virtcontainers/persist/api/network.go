@@ -36,8 +36,13 @@ type TuntapInterface struct {
 // NetworkInterfacePair defines a pair between VM and virtual network interfaces.
 type NetworkInterfacePair struct {
 	TapInterface
-	VirtIface            NetworkInterface
-	NetInterworkingModel int
+	VirtIface             NetworkInterface
+	NetInterworkingModel  int
+	NetworkPolicy         *vcTypes.NetworkPolicy `json:",omitempty"`
+	SharedClsact          bool                   `json:",omitempty"`
+	NetworkPolicyPriority uint16                 `json:",omitempty"`
+	RxRateLimiterMaxRate  uint64                 `json:",omitempty"`
+	TxRateLimiterMaxRate  uint64                 `json:",omitempty"`
 }
 
 type PhysicalEndpoint struct {
@@ -79,8 +84,32 @@ type VhostUserEndpoint struct {
 	PCIPath   vcTypes.PciPath
 }
 
+// PluginEndpoint holds the state of an out-of-tree endpoint, serialized
+// by the endpoint itself.
+type PluginEndpoint struct {
+	State []byte
+}
+
 type VfioEndpoint struct {
 	IfaceName string
+	HostBDF   string `json:",omitempty"`
+}
+
+type VdpaEndpoint struct {
+	IfaceName     string
+	HardAddr      string
+	VdpaDevName   string
+	VhostVdpaPath string
+	NumQueues     uint32
+	PCIPath       vcTypes.PciPath
+}
+
+type PasstEndpoint struct {
+	IfaceName  string
+	HardAddr   string
+	SocketPath string
+	PID        int
+	PCIPath    vcTypes.PciPath
 }
 
 // NetworkEndpoint contains network interface information
@@ -95,6 +124,9 @@ type NetworkEndpoint struct {
 	IPVlan    *IPVlanEndpoint    `json:",omitempty"`
 	Tuntap    *TuntapEndpoint    `json:",omitempty"`
 	Vfio      *VfioEndpoint      `json:",omitempty"`
+	Vdpa      *VdpaEndpoint      `json:",omitempty"`
+	Passt     *PasstEndpoint     `json:",omitempty"`
+	Plugin    *PluginEndpoint    `json:",omitempty"`
 
 	Type string
 }
@@ -19,6 +19,33 @@ type AgentState struct {
 	URL string
 }
 
+// HotplugOp is the operation of a hotplug intent
+type HotplugOp string
+
+const (
+	// HotplugAdd is a device hot plug
+	HotplugAdd HotplugOp = "add"
+
+	// HotplugRemove is a device hot unplug
+	HotplugRemove HotplugOp = "remove"
+)
+
+// HotplugIntent records a device hot plug or hot unplug, from before the
+// hypervisor is asked for it until the sandbox state is saved with it.
+type HotplugIntent struct {
+	// Op is the hotplug operation
+	Op HotplugOp
+
+	// DeviceType is the type the device is hot plugged as
+	DeviceType string
+
+	// Device is the device state when the operation started
+	Device dev.DeviceState
+
+	// Done is set once the hypervisor completed the operation
+	Done bool
+}
+
 // SandboxState contains state information of sandbox
 // nolint: maligned
 type SandboxState struct {
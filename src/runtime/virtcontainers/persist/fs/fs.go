@@ -23,6 +23,9 @@ import (
 // persistFile is the file name for JSON sandbox/container configuration
 const persistFile = "persist.json"
 
+// journalFile is the file name for the JSON hotplug intents of a sandbox
+const journalFile = "hotplug-journal.json"
+
 // dirMode is the permission bits used for creating a directory
 const dirMode = os.FileMode(0700) | os.ModeDir
 
@@ -241,6 +244,85 @@ func (fs *FS) Destroy(sandboxID string) error {
 	return nil
 }
 
+// ToJournal writes the hotplug intents of the sandbox, the journal is
+// replaced atomically so that a crash leaves either the former or the new one.
+func (fs *FS) ToJournal(sandboxID string, intents []persistapi.HotplugIntent) error {
+	if sandboxID == "" {
+		return fmt.Errorf("sandbox container id required")
+	}
+
+	sandboxDir, err := fs.sandboxDir(sandboxID)
+	if err != nil {
+		return err
+	}
+
+	journal := filepath.Join(sandboxDir, journalFile)
+	if len(intents) == 0 {
+		if err := os.Remove(journal); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	if err := utils.MkdirAllWithInheritedOwner(sandboxDir, dirMode); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(intents)
+	if err != nil {
+		return err
+	}
+
+	tmp := journal + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fileMode)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, journal)
+}
+
+// FromJournal reads the hotplug intents of the sandbox
+func (fs *FS) FromJournal(sandboxID string) ([]persistapi.HotplugIntent, error) {
+	if sandboxID == "" {
+		return nil, fmt.Errorf("restore requires sandbox id")
+	}
+
+	sandboxDir, err := fs.sandboxDir(sandboxID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(sandboxDir, journalFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var intents []persistapi.HotplugIntent
+	if err := json.Unmarshal(data, &intents); err != nil {
+		return nil, err
+	}
+
+	return intents, nil
+}
+
 func (fs *FS) Lock(sandboxID string, exclusive bool) (func() error, error) {
 	if sandboxID == "" {
 		return nil, fmt.Errorf("sandbox container id required")
@@ -298,18 +380,32 @@ func (fs *FS) GlobalWrite(relativePath string, data []byte) error {
 		return err
 	}
 
-	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, fileMode)
+	// The data is written to a temporary file renamed over the file, so that
+	// readers never see it truncated or partially written.
+	f, err := os.CreateTemp(dir, filepath.Base(path)+".*")
 	if err != nil {
 		fs.Logger().WithError(err).WithField("file", path).Error("failed to open file for writing")
 		return err
 	}
-	defer f.Close()
+	tmp := f.Name()
+	defer os.Remove(tmp)
+
+	if err := f.Chmod(fileMode); err != nil {
+		f.Close()
+		return err
+	}
 
 	if _, err := f.Write(data); err != nil {
+		f.Close()
 		fs.Logger().WithError(err).WithField("file", path).Error("failed to write file")
 		return err
 	}
-	return nil
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
 }
 
 func (fs *FS) GlobalRead(relativePath string) ([]byte, error) {
@@ -321,7 +417,9 @@ func (fs *FS) GlobalRead(relativePath string) ([]byte, error) {
 
 	f, err := os.Open(path)
 	if err != nil {
-		fs.Logger().WithError(err).WithField("file", path).Error("failed to open file for reading")
+		if !os.IsNotExist(err) {
+			fs.Logger().WithError(err).WithField("file", path).Error("failed to open file for reading")
+		}
 		return nil, err
 	}
 	defer f.Close()
@@ -334,6 +432,39 @@ func (fs *FS) GlobalRead(relativePath string) ([]byte, error) {
 	return data, nil
 }
 
+func (fs *FS) GlobalLock(relativePath string, exclusive bool) (func() error, error) {
+	path := filepath.Join(fs.storageRootPath, relativePath)
+	path, err := filepath.Abs(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find abs path for %q: %v", relativePath, err)
+	}
+
+	if err := utils.MkdirAllWithInheritedOwner(filepath.Dir(path), dirMode); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_RDONLY|os.O_CREATE, fileMode)
+	if err != nil {
+		return nil, err
+	}
+
+	lockType := syscall.LOCK_SH
+	if exclusive {
+		lockType = syscall.LOCK_EX
+	}
+
+	if err := syscall.Flock(int(f.Fd()), lockType); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	unlockFunc := func() error {
+		defer f.Close()
+		return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	}
+	return unlockFunc, nil
+}
+
 func (fs *FS) RunStoragePath() string {
 	return filepath.Join(fs.storageRootPath, sandboxPathSuffix)
 }
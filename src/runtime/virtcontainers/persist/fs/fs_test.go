@@ -8,8 +8,10 @@ package fs
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
 	persistapi "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/persist/api"
 	"github.com/stretchr/testify/assert"
 )
@@ -149,6 +151,58 @@ func TestFsDriver(t *testing.T) {
 	assert.True(t, os.IsNotExist(err))
 }
 
+func TestFsJournal(t *testing.T) {
+	assert := assert.New(t)
+	fs, err := getFsDriver(t)
+	assert.NoError(err)
+
+	sid := "test-fs-journal"
+
+	// No journal
+	intents, err := fs.FromJournal(sid)
+	assert.NoError(err)
+	assert.Empty(intents)
+
+	expected := []persistapi.HotplugIntent{
+		{
+			Op:         persistapi.HotplugAdd,
+			DeviceType: "block",
+			Device:     config.DeviceState{ID: "dev-1", AttachCount: 1},
+		},
+		{
+			Op:         persistapi.HotplugRemove,
+			DeviceType: "vfio",
+			Device:     config.DeviceState{ID: "dev-2"},
+			Done:       true,
+		},
+	}
+	assert.NoError(fs.ToJournal(sid, expected))
+
+	intents, err = fs.FromJournal(sid)
+	assert.NoError(err)
+	assert.Equal(expected, intents)
+
+	sandboxDir, err := fs.sandboxDir(sid)
+	assert.NoError(err)
+	assert.NoFileExists(filepath.Join(sandboxDir, journalFile+".tmp"))
+
+	// The sandbox state does not see the journal
+	fs.sandboxState.SandboxContainer = sid
+	assert.NoError(fs.ToDisk(*fs.sandboxState, nil))
+	_, cs, err := fs.FromDisk(sid)
+	assert.NoError(err)
+	assert.Empty(cs)
+
+	// An empty journal is removed
+	assert.NoError(fs.ToJournal(sid, nil))
+	assert.NoFileExists(filepath.Join(sandboxDir, journalFile))
+	assert.NoError(fs.ToJournal(sid, nil))
+
+	_, err = fs.FromJournal("")
+	assert.Error(err)
+	assert.Error(fs.ToJournal("", expected))
+}
+
 func TestGlobalReadWrite(t *testing.T) {
 	relPath := "test/123/aaa.json"
 	data := "hello this is testing global read write"
@@ -167,4 +221,30 @@ func TestGlobalReadWrite(t *testing.T) {
 	out, err = fs.GlobalRead("nonexist")
 	assert.NotNil(t, err)
 	assert.Nil(t, out)
+
+	// Overwriting with shorter data
+	err = fs.GlobalWrite(relPath, []byte("hello"))
+	assert.Nil(t, err)
+	out, err = fs.GlobalRead(relPath)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", string(out))
+
+	// No temporary file is left behind
+	entries, err := os.ReadDir(filepath.Dir(filepath.Join(fs.storageRootPath, relPath)))
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestGlobalLock(t *testing.T) {
+	fs, err := getFsDriver(t)
+	assert.Nil(t, err)
+
+	unlock, err := fs.GlobalLock("test/lock", true)
+	assert.Nil(t, err)
+	assert.FileExists(t, filepath.Join(fs.storageRootPath, "test/lock"))
+	assert.Nil(t, unlock())
+
+	unlock, err = fs.GlobalLock("test/lock", false)
+	assert.Nil(t, err)
+	assert.Nil(t, unlock())
 }
@@ -8,11 +8,14 @@ package virtcontainers
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
 	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/manager"
+	hv "github.com/kata-containers/kata-containers/src/runtime/pkg/hypervisors"
 	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/persist"
+	persistapi "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/persist/api"
 	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/types"
 	"github.com/stretchr/testify/assert"
 )
@@ -32,7 +35,7 @@ func TestSandboxRestore(t *testing.T) {
 	sandbox := Sandbox{
 		id:         "test-exp",
 		containers: container,
-		devManager: manager.NewDeviceManager(config.VirtioSCSI, false, "", 0, nil),
+		devManager: manager.NewDeviceManager(config.VirtioSCSI, false, "", 0, nil, nil, nil),
 		hypervisor: &mockHypervisor{},
 		network:    network,
 		ctx:        context.Background(),
@@ -81,3 +84,40 @@ func TestSandboxRestore(t *testing.T) {
 	assert.Equal(len(sandbox.state.BlockIndexMap), 1)
 	assert.Equal(sandbox.state.BlockIndexMap[2], struct{}{})
 }
+
+func TestRestoreVFIOHostDrivers(t *testing.T) {
+	assert := assert.New(t)
+
+	root := t.TempDir()
+	deviceDir := filepath.Join(root, "sys/bus/pci/devices/0000:01:00.0")
+	assert.NoError(os.MkdirAll(deviceDir, 0755))
+	assert.NoError(os.WriteFile(filepath.Join(deviceDir, "driver_override"), []byte("vfio-pci"), 0640))
+	assert.NoError(os.WriteFile(filepath.Join(root, "sys/bus/pci/drivers_probe"), nil, 0640))
+
+	store, err := persist.GetDriver()
+	assert.NoError(err)
+
+	ss := persistapi.SandboxState{
+		SandboxContainer: "test-vfio-restore",
+		HypervisorState:  hv.HypervisorState{Pid: os.Getpid()},
+		Devices: []config.DeviceState{
+			{ID: "vfio", Type: string(config.DeviceVFIO), VFIOHostDrivers: map[string]string{"0000:01:00.0": "i40e"}},
+		},
+	}
+	ss.Config.HypervisorConfig.HostSysfsRoot = root
+	assert.NoError(store.ToDisk(ss, nil))
+	defer store.Destroy("test-vfio-restore")
+
+	// The devices are kept while the VM may use them
+	assert.Error(restoreVFIOHostDrivers("test-vfio-restore"))
+
+	ss.HypervisorState.Pid = 0
+	assert.NoError(store.ToDisk(ss, nil))
+	assert.NoError(restoreVFIOHostDrivers("test-vfio-restore"))
+
+	data, err := os.ReadFile(filepath.Join(deviceDir, "driver_override"))
+	assert.NoError(err)
+	assert.Equal("i40e", string(data))
+
+	assert.Error(restoreVFIOHostDrivers("test-vfio-unknown"))
+}
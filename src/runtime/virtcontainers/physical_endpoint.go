@@ -170,7 +170,7 @@ func (endpoint *PhysicalEndpoint) HotDetach(ctx context.Context, s *Sandbox, net
 	var vfioPath string
 	var err error
 
-	if vfioPath, err = drivers.GetVFIODevPath(endpoint.BDF); err != nil {
+	if vfioPath, err = drivers.GetVFIODevPath(config.NewRootedSysfs("/"), endpoint.BDF); err != nil {
 		return err
 	}
 
@@ -280,12 +280,14 @@ func createPhysicalEndpoint(netInfo NetworkInfo) (*PhysicalEndpoint, error) {
 	return physicalEndpoint, nil
 }
 
+// Physical interfaces are discovered from the host network namespace,
+// so they are always rebound through the host sysfs.
 func bindNICToVFIO(endpoint *PhysicalEndpoint) (string, error) {
-	return drivers.BindDevicetoVFIO(endpoint.BDF, endpoint.Driver)
+	return drivers.BindDevicetoVFIO(config.NewRootedSysfs("/"), endpoint.BDF, endpoint.Driver)
 }
 
 func bindNICToHost(endpoint *PhysicalEndpoint) error {
-	return drivers.BindDevicetoHost(endpoint.BDF, endpoint.Driver)
+	return drivers.BindDevicetoHost(config.NewRootedSysfs("/"), endpoint.BDF, endpoint.Driver)
 }
 
 func (endpoint *PhysicalEndpoint) save() persistapi.NetworkEndpoint {
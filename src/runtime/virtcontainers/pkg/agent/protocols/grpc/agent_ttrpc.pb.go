@@ -52,6 +52,8 @@ type AgentServiceService interface {
 	GetVolumeStats(context.Context, *VolumeStatsRequest) (*VolumeStatsResponse, error)
 	ResizeVolume(context.Context, *ResizeVolumeRequest) (*emptypb.Empty, error)
 	SetPolicy(context.Context, *SetPolicyRequest) (*emptypb.Empty, error)
+	CloneDirectory(context.Context, *CloneDirectoryRequest) (*emptypb.Empty, error)
+	RemovePath(context.Context, *RemovePathRequest) (*emptypb.Empty, error)
 }
 
 func RegisterAgentServiceService(srv *ttrpc.Server, svc AgentServiceService) {
@@ -351,6 +353,20 @@ func RegisterAgentServiceService(srv *ttrpc.Server, svc AgentServiceService) {
 				}
 				return svc.SetPolicy(ctx, &req)
 			},
+			"CloneDirectory": func(ctx context.Context, unmarshal func(interface{}) error) (interface{}, error) {
+				var req CloneDirectoryRequest
+				if err := unmarshal(&req); err != nil {
+					return nil, err
+				}
+				return svc.CloneDirectory(ctx, &req)
+			},
+			"RemovePath": func(ctx context.Context, unmarshal func(interface{}) error) (interface{}, error) {
+				var req RemovePathRequest
+				if err := unmarshal(&req); err != nil {
+					return nil, err
+				}
+				return svc.RemovePath(ctx, &req)
+			},
 		},
 	})
 }
@@ -700,3 +716,19 @@ func (c *agentserviceClient) SetPolicy(ctx context.Context, req *SetPolicyReques
 	}
 	return &resp, nil
 }
+
+func (c *agentserviceClient) CloneDirectory(ctx context.Context, req *CloneDirectoryRequest) (*emptypb.Empty, error) {
+	var resp emptypb.Empty
+	if err := c.client.Call(ctx, "grpc.AgentService", "CloneDirectory", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *agentserviceClient) RemovePath(ctx context.Context, req *RemovePathRequest) (*emptypb.Empty, error) {
+	var resp emptypb.Empty
+	if err := c.client.Call(ctx, "grpc.AgentService", "RemovePath", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
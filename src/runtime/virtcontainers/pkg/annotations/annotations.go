@@ -182,6 +182,9 @@ const (
 	// Enable Hypervisor Devices IOMMU_PLATFORM
 	IOMMUPlatform = kataAnnotHypervisorPrefix + "enable_iommu_platform"
 
+	// EnableTPM is a sandbox annotation to specify if the VM should have a vTPM, backed by swtpm
+	EnableTPM = kataAnnotHypervisorPrefix + "enable_tpm"
+
 	// FileBackedMemRootDir is a sandbox annotation to soecify file based memory backend root directory
 	FileBackedMemRootDir = kataAnnotHypervisorPrefix + "file_mem_backend"
 
@@ -311,6 +314,32 @@ const (
 
 	// ForceGuestPull is a sandbox annotation that sets experimental_force_guest_pull.
 	ForceGuestPull = kataAnnotRuntimePrefix + "experimental_force_guest_pull"
+
+	// NetworkPolicy is a sandbox annotation holding a JSON encoded network policy
+	// restricting the egress traffic of the sandbox. It cannot override the policy
+	// set by the runtime.network_policy parameter in the global configuration.toml
+	NetworkPolicy = kataAnnotRuntimePrefix + "network_policy"
+
+	// NetworkBandwidth is a sandbox annotation holding a JSON encoded map of
+	// per-interface bandwidth limits, e.g. {"net1": {"ingress": "10M", "egress": "1G"}}.
+	// It takes precedence over the kubernetes.io bandwidth annotations for the
+	// listed interfaces.
+	NetworkBandwidth = kataAnnotRuntimePrefix + "network_bandwidth"
+
+	// NetworkEndpointTypes is a sandbox annotation holding a JSON encoded map
+	// of interface names to the registered endpoint types to create for them,
+	// e.g. {"net1": "my-endpoint"}.
+	NetworkEndpointTypes = kataAnnotRuntimePrefix + "network_endpoint_types"
+
+	// UserModeNetworking is a sandbox annotation to connect the VM through
+	// passt user-mode networking, instead of the network namespace interfaces.
+	UserModeNetworking = kataAnnotRuntimePrefix + "user_mode_networking"
+
+	// NetworkForwardedPorts is a sandbox annotation holding the comma
+	// separated host ports forwarded to the guest with user-mode networking,
+	// each one in the [host_port:]guest_port[/protocol] notation,
+	// e.g. "8080:80,53/udp".
+	NetworkForwardedPorts = kataAnnotRuntimePrefix + "network_forwarded_ports"
 )
 
 // Agent related annotations
@@ -370,6 +399,10 @@ const (
 	// IsFileBlockDevice indicates that the annotated filesystem is mounted on a block device
 	// backed by a host file.
 	IsFileBlockDevice = kataAnnotFsOptPrefix + "block_device=file"
+
+	// IsFilePmemDevice indicates that the annotated filesystem is on a host file with
+	// the PFN signature, to be attached as a NVDIMM and mounted with DAX.
+	IsFilePmemDevice = kataAnnotFsOptPrefix + "block_device=pmem"
 )
 
 const (
@@ -385,4 +418,10 @@ const (
 	// Supported suffixes are: Ki | Mi | Gi | Ti | Pi | Ei . For example: 4Mi
 	// For more information about supported suffixes see https://physics.nist.gov/cuu/Units/binary.html
 	SGXEPC = "sgx.intel.com/epc"
+
+	// IngressBandwidth and EgressBandwidth are the bandwidth plugin annotations,
+	// limiting the traffic received and sent by the pod, in bits per second.
+	// They accept resource quantities, e.g. 10M or 1G.
+	IngressBandwidth = "kubernetes.io/ingress-bandwidth"
+	EgressBandwidth  = "kubernetes.io/egress-bandwidth"
 )
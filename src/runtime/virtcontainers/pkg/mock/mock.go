@@ -257,6 +257,14 @@ func (p *HybridVSockTTRPCMockImp) SetIPTables(ctx context.Context, req *pb.SetIP
 	return &pb.SetIPTablesResponse{}, nil
 }
 
+func (p *HybridVSockTTRPCMockImp) CloneDirectory(ctx context.Context, req *pb.CloneDirectoryRequest) (*gpb.Empty, error) {
+	return emptyResp, nil
+}
+
+func (p *HybridVSockTTRPCMockImp) RemovePath(ctx context.Context, req *pb.RemovePathRequest) (*gpb.Empty, error) {
+	return emptyResp, nil
+}
+
 func (p *HybridVSockTTRPCMockImp) SetPolicy(ctx context.Context, req *pb.SetPolicyRequest) (*gpb.Empty, error) {
 	return &gpb.Empty{}, nil
 }
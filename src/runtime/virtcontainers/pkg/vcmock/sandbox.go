@@ -203,6 +203,9 @@ func (s *Sandbox) RemoveInterface(ctx context.Context, inf *pbTypes.Interface) (
 
 // ListInterfaces implements the VCSandbox function of the same name.
 func (s *Sandbox) ListInterfaces(ctx context.Context) ([]*pbTypes.Interface, error) {
+	if s.ListInterfacesFunc != nil {
+		return s.ListInterfacesFunc()
+	}
 	return nil, nil
 }
 
@@ -213,13 +216,49 @@ func (s *Sandbox) UpdateRoutes(ctx context.Context, routes []*pbTypes.Route) ([]
 
 // ListRoutes implements the VCSandbox function of the same name.
 func (s *Sandbox) ListRoutes(ctx context.Context) ([]*pbTypes.Route, error) {
+	if s.ListRoutesFunc != nil {
+		return s.ListRoutesFunc()
+	}
 	return nil, nil
 }
 
+// ListNetworkEndpoints implements the VCSandbox function of the same name.
+func (s *Sandbox) ListNetworkEndpoints(ctx context.Context) ([]*pbTypes.Interface, []*pbTypes.Route, []*pbTypes.ARPNeighbor, error) {
+	if s.ListNetworkEndpointsFunc != nil {
+		return s.ListNetworkEndpointsFunc()
+	}
+	return nil, nil, nil, nil
+}
+
+// UpdateInterfaceBandwidth implements the VCSandbox function of the same name.
+func (s *Sandbox) UpdateInterfaceBandwidth(ctx context.Context, name string, bandwidth vc.NetworkBandwidth) error {
+	if s.UpdateInterfaceBandwidthFunc != nil {
+		return s.UpdateInterfaceBandwidthFunc(name, bandwidth)
+	}
+	return nil
+}
+
+// ReloadDanConfig implements the VCSandbox function of the same name.
+func (s *Sandbox) ReloadDanConfig(ctx context.Context) error {
+	if s.ReloadDanConfigFunc != nil {
+		return s.ReloadDanConfigFunc()
+	}
+	return nil
+}
+
 func (s *Sandbox) GetOOMEvent(ctx context.Context) (string, error) {
 	return "", nil
 }
 
+// GetSandboxEvent implements the VCSandbox function of the same name.
+func (s *Sandbox) GetSandboxEvent(ctx context.Context) (vc.SandboxEvent, error) {
+	if s.GetSandboxEventFunc != nil {
+		return s.GetSandboxEventFunc()
+	}
+	<-ctx.Done()
+	return vc.SandboxEvent{}, ctx.Err()
+}
+
 // UpdateRuntimeMetrics implements the VCSandbox function of the same name.
 func (s *Sandbox) UpdateRuntimeMetrics() error {
 	if s.UpdateRuntimeMetricsFunc != nil {
@@ -259,6 +298,14 @@ func (s *Sandbox) RescanNetwork(ctx context.Context) error {
 	return nil
 }
 
+// PCIeTopology implements the VCSandbox function of the same name.
+func (s *Sandbox) PCIeTopology(ctx context.Context) (vc.PCIeTopology, error) {
+	if s.PCIeTopologyFunc != nil {
+		return s.PCIeTopologyFunc()
+	}
+	return vc.PCIeTopology{}, nil
+}
+
 func (s *Sandbox) GuestVolumeStats(ctx context.Context, path string) ([]byte, error) {
 	return nil, nil
 }
@@ -68,6 +68,13 @@ type Sandbox struct {
 	GetAgentMetricsFunc      func() (string, error)
 	StatsFunc                func() (vc.SandboxStats, error)
 	GetAgentURLFunc          func() (string, error)
+
+	// functions for the network and device management mocks
+	ListNetworkEndpointsFunc     func() ([]*pbTypes.Interface, []*pbTypes.Route, []*pbTypes.ARPNeighbor, error)
+	UpdateInterfaceBandwidthFunc func(name string, bandwidth vc.NetworkBandwidth) error
+	ReloadDanConfigFunc          func() error
+	PCIeTopologyFunc             func() (vc.PCIeTopology, error)
+	GetSandboxEventFunc          func() (vc.SandboxEvent, error)
 }
 
 // Container is a fake Container type used for testing
@@ -82,6 +82,8 @@ type QemuState struct {
 	HotpluggedVCPUs   []hv.CPUDevice
 	HotpluggedMemory  int
 	VirtiofsDaemonPid int
+	SwtpmPid          int
+	PasstPid          int
 	HotplugVFIO       config.PCIePort
 	ColdPlugVFIO      config.PCIePort
 	PCIeRootPort      uint32
@@ -95,6 +97,8 @@ type qemu struct {
 
 	virtiofsDaemon VirtiofsDaemon
 
+	swtpm *swtpm
+
 	ctx context.Context
 
 	// fds is a list of file descriptors inherited by QEMU process
@@ -118,6 +122,13 @@ type qemu struct {
 
 	stopped int32
 
+	// virtiofsDaemonStopping is set when the virtio-fs daemon is stopped
+	// on purpose, so that its exit is not reported.
+	virtiofsDaemonStopping int32
+
+	// virtiofsDaemonQuitHandler is called when the virtio-fs daemon quits.
+	virtiofsDaemonQuitHandler func()
+
 	mu sync.Mutex
 }
 
@@ -135,6 +146,7 @@ const (
 	qmpExecCatCmd = "exec:cat"
 
 	scsiControllerID         = "scsi0"
+	charDeviceSerialID       = "charserial0"
 	rngID                    = "rng0"
 	fallbackFileBackedMemDir = "/dev/shm"
 	balloonID                = "balloon0"
@@ -466,6 +478,20 @@ func (q *qemu) buildDevices(ctx context.Context, kernelPath string) ([]govmmQemu
 		}
 	}
 
+	if len(q.config.CharDevicePassthrough) > 0 && q.config.CharDeviceTransport != config.CharDeviceVsock {
+		devices, err = q.arch.appendCharDeviceSerial(ctx, devices)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	if q.config.EnableTPM {
+		devices, err = q.appendTPM(devices)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
 	return devices, ioThread, kernel, nil
 }
 
@@ -537,39 +563,26 @@ func (q *qemu) createVirtiofsDaemon(sharedPath string) (VirtiofsDaemon, error) {
 		return nil, err
 	}
 
-	if q.config.SharedFS == config.VirtioFSNydus {
-		apiSockPath, err := q.nydusdAPISocketPath(q.id)
-		if err != nil {
-			return nil, err
-		}
-		nd := &nydusd{
-			path:        q.config.VirtioFSDaemon,
-			sockPath:    virtiofsdSocketPath,
-			apiSockPath: apiSockPath,
-			sourcePath:  sharedPath,
-			debug:       q.config.Debug,
-			extraArgs:   q.config.VirtioFSExtraArgs,
-			startFn:     startInShimNS,
-		}
-		nd.setupShareDirFn = nd.setupPassthroughFS
-		return nd, nil
+	apiSockPath, err := q.nydusdAPISocketPath(q.id)
+	if err != nil {
+		return nil, err
 	}
 
 	// Set the xattr option for virtiofsd daemon to enable extended attributes
-	// in virtiofs if SELinux on the guest side is enabled.
-	if !q.config.DisableGuestSeLinux {
+	// in virtiofs if SELinux on the guest side is enabled. The other daemons
+	// are not virtiofsd, or not started by the runtime.
+	if q.config.SharedFS == config.VirtioFS && !q.config.DisableGuestSeLinux {
 		q.Logger().Info("Set the xattr option for virtiofsd")
 		q.config.VirtioFSExtraArgs = append(q.config.VirtioFSExtraArgs, "--xattr")
 	}
 
-	// default use virtiofsd
-	return &virtiofsd{
-		path:       q.config.VirtioFSDaemon,
-		sourcePath: sharedPath,
-		socketPath: virtiofsdSocketPath,
-		extraArgs:  q.config.VirtioFSExtraArgs,
-		cache:      q.config.VirtioFSCache,
-	}, nil
+	return newVirtiofsDaemon(VirtiofsDaemonParams{
+		Config:        &q.config,
+		SandboxID:     q.id,
+		SocketPath:    virtiofsdSocketPath,
+		APISocketPath: apiSockPath,
+		SharedPath:    sharedPath,
+	})
 }
 
 // CreateVM is the Hypervisor VM creation implementation for govmmQemu.
@@ -620,7 +633,7 @@ func (q *qemu) CreateVM(ctx context.Context, id string, network Network, hypervi
 	// builds the first VM with file-backed memory and shared=on and the
 	// subsequent ones with shared=off. virtio-fs always requires shared=on for
 	// memory.
-	if q.config.SharedFS == config.VirtioFS || q.config.SharedFS == config.VirtioFSNydus ||
+	if IsVirtioFS(q.config.SharedFS) ||
 		q.config.FileBackedMemRootDir != "" {
 		if !q.config.BootToBeTemplate && !q.config.BootFromTemplate {
 			q.setupFileBackedMem(&knobs, &memory)
@@ -772,6 +785,31 @@ func (q *qemu) CreateVM(ctx context.Context, id string, network Network, hypervi
 	return err
 }
 
+// appendTPM appends the vTPM, backed by the swtpm of the sandbox.
+func (q *qemu) appendTPM(devices []govmmQemu.Device) ([]govmmQemu.Device, error) {
+	tpm, err := newSwtpm(&q.config, q.id, q.state.SwtpmPid)
+	if err != nil {
+		return nil, err
+	}
+	q.swtpm = tpm
+
+	model := govmmQemu.DeviceDriver(q.config.TPMModel)
+	if model == "" {
+		model = govmmQemu.TPMCRB
+	}
+
+	dev := govmmQemu.TPMDevice{
+		ID:         "tpm0",
+		Driver:     model,
+		SocketPath: tpm.socketPath,
+	}
+	if !dev.Valid() {
+		return nil, fmt.Errorf("invalid TPM device %+v", dev)
+	}
+
+	return append(devices, dev), nil
+}
+
 func (q *qemu) checkBpfEnabled() {
 	if q.config.SeccompSandbox != "" {
 		out, err := os.ReadFile("/proc/sys/net/core/bpf_jit_enable")
@@ -828,9 +866,10 @@ func (q *qemu) createPCIeTopology(qemuConfig *govmmQemu.Config, hypervisorConfig
 		}
 		numOfPluggablePorts += uint32(virtPcieRootPortNum)
 	}
+	sysfs := hypervisorConfig.HostSysfs()
 	for _, dev := range hypervisorConfig.VFIODevices {
 		var err error
-		dev.HostPath, err = config.GetHostPath(dev, false, "")
+		dev.HostPath, err = config.GetHostPath(sysfs, dev, false, "")
 		if err != nil {
 			return fmt.Errorf("Cannot get host path for device: %v err: %v", dev, err)
 		}
@@ -841,7 +880,7 @@ func (q *qemu) createPCIeTopology(qemuConfig *govmmQemu.Config, hypervisorConfig
 		// /dev/vfio/devices/vfio0
 		// (1) Check if we have the new IOMMUFD or old container based VFIO
 		if strings.HasPrefix(dev.HostPath, pkgDevice.IommufdDevPath) {
-			vfioDevices, err = drivers.GetDeviceFromVFIODev(dev)
+			vfioDevices, err = drivers.GetDeviceFromVFIODev(sysfs, dev)
 			if err != nil {
 				return fmt.Errorf("Cannot get VFIO device from IOMMUFD with device: %v err: %v", dev, err)
 			}
@@ -850,14 +889,14 @@ func (q *qemu) createPCIeTopology(qemuConfig *govmmQemu.Config, hypervisorConfig
 				return fmt.Errorf("ConfidentialGuest needs IOMMUFD - cannot use %s", dev.HostPath)
 			}
 
-			vfioDevices, err = drivers.GetAllVFIODevicesFromIOMMUGroup(dev)
+			vfioDevices, err = drivers.GetAllVFIODevicesFromIOMMUGroup(sysfs, dev)
 			if err != nil {
 				return fmt.Errorf("Cannot get all VFIO devices from IOMMU group with device: %v err: %v", dev, err)
 			}
 		}
 
 		for _, vfioDevice := range vfioDevices {
-			if drivers.IsPCIeDevice(vfioDevice.BDF) {
+			if drivers.IsPCIeDevice(sysfs, vfioDevice.BDF) {
 				numOfPluggablePorts = numOfPluggablePorts + 1
 			}
 		}
@@ -865,37 +904,32 @@ func (q *qemu) createPCIeTopology(qemuConfig *govmmQemu.Config, hypervisorConfig
 	vfioOnRootPort := (q.state.HotPlugVFIO == config.RootPort || q.state.ColdPlugVFIO == config.RootPort)
 	vfioOnSwitchPort := (q.state.HotPlugVFIO == config.SwitchPort || q.state.ColdPlugVFIO == config.SwitchPort)
 
-	// If the devices are not advertised via CRI or cold-plugged we need to
-	// get the number of pluggable root/switch ports from the config
-	numPCIeRootPorts := hypervisorConfig.PCIeRootPort
-	numPCIeSwitchPorts := hypervisorConfig.PCIeSwitchPort
-
-	// If number of PCIe root ports > 16 then bail out otherwise we may
-	// use up all slots or IO memory on the root bus and vfio-XXX-pci devices
-	// cannot be added which are crucial for Kata max slots on root bus is 32
-	// max slots on the complete pci(e) topology is 256 in QEMU
+	// The ports are planned for the devices plugged when the VM boots and
+	// the hotplugs expected, with at most 16 root or switch ports otherwise
+	// we may use up all slots or IO memory on the root bus and vfio-XXX-pci
+	// devices cannot be added which are crucial for Kata max slots on root
+	// bus is 32 max slots on the complete pci(e) topology is 256 in QEMU
 	if vfioOnRootPort {
-		if numOfPluggablePorts < numPCIeRootPorts {
-			numOfPluggablePorts = numPCIeRootPorts
-		}
-		if numOfPluggablePorts > maxPCIeRootPort {
-			return fmt.Errorf("Number of PCIe Root Ports exceeed allowed max of %d", maxPCIeRootPort)
+		ports, err := PlanPCIePorts(hypervisorConfig, config.RootPort, numOfPluggablePorts)
+		if err != nil {
+			return err
 		}
-		qemuConfig.Devices = q.arch.appendPCIeRootPortDevice(qemuConfig.Devices, numOfPluggablePorts)
+		q.state.PCIeRootPort = ports
+		qemuConfig.Devices = q.arch.appendPCIeRootPortDevice(qemuConfig.Devices, ports)
 		return nil
 	}
 	if vfioOnSwitchPort {
-		if numOfPluggablePorts < numPCIeSwitchPorts {
-			numOfPluggablePorts = numPCIeSwitchPorts
-		}
-		if numOfPluggablePorts > maxPCIeSwitchPort {
-			return fmt.Errorf("Number of PCIe Switch Ports exceeed allowed max of %d", maxPCIeSwitchPort)
+		ports, err := PlanPCIePorts(hypervisorConfig, config.SwitchPort, numOfPluggablePorts)
+		if err != nil {
+			return err
 		}
-		qemuConfig.Devices = q.arch.appendPCIeSwitchPortDevice(qemuConfig.Devices, numOfPluggablePorts)
+		q.state.PCIeSwitchPort = ports
+		qemuConfig.Devices = q.arch.appendPCIeSwitchPortDevice(qemuConfig.Devices, ports)
 		return nil
 	}
 	// If both Root Port and Switch Port are not enabled, check if QemuVirt need add pcie root port.
 	if machineType == QemuVirt {
+		q.state.PCIeRootPort = numOfPluggablePorts
 		qemuConfig.Devices = q.arch.appendPCIeRootPortDevice(qemuConfig.Devices, numOfPluggablePorts)
 	}
 	return nil
@@ -910,8 +944,10 @@ func (q *qemu) nydusdAPISocketPath(id string) (string, error) {
 }
 
 func (q *qemu) setupVirtiofsDaemon(ctx context.Context) (err error) {
+	atomic.StoreInt32(&q.virtiofsDaemonStopping, 0)
+
 	pid, err := q.virtiofsDaemon.Start(ctx, func() {
-		q.StopVM(ctx, false)
+		q.onVirtiofsDaemonQuit(ctx)
 	})
 	if err != nil {
 		return err
@@ -921,7 +957,27 @@ func (q *qemu) setupVirtiofsDaemon(ctx context.Context) (err error) {
 	return nil
 }
 
+func (q *qemu) setVirtiofsDaemonQuitHandler(handler func()) {
+	q.virtiofsDaemonQuitHandler = handler
+}
+
+// onVirtiofsDaemonQuit stops the VM, as the vhost-user-fs device can't be
+// reconnected to another virtio-fs daemon, and reports the daemon exit.
+func (q *qemu) onVirtiofsDaemonQuit(ctx context.Context) {
+	if atomic.LoadInt32(&q.virtiofsDaemonStopping) != 0 {
+		return
+	}
+
+	q.StopVM(ctx, false)
+
+	if q.virtiofsDaemonQuitHandler != nil {
+		q.virtiofsDaemonQuitHandler()
+	}
+}
+
 func (q *qemu) stopVirtiofsDaemon(ctx context.Context) (err error) {
+	atomic.StoreInt32(&q.virtiofsDaemonStopping, 1)
+
 	if q.state.VirtiofsDaemonPid == 0 {
 		q.Logger().Warn("The virtiofsd had stopped")
 		return nil
@@ -935,6 +991,35 @@ func (q *qemu) stopVirtiofsDaemon(ctx context.Context) (err error) {
 	return nil
 }
 
+func (q *qemu) setupSwtpm(ctx context.Context) error {
+	if q.swtpm == nil {
+		return errors.New("Missing swtpm configuration")
+	}
+
+	pid, err := q.swtpm.Start(ctx, func() {
+		q.StopVM(ctx, false)
+	})
+	if err != nil {
+		return err
+	}
+	q.state.SwtpmPid = pid
+
+	return nil
+}
+
+func (q *qemu) stopSwtpm(ctx context.Context) error {
+	if q.state.SwtpmPid == 0 || q.swtpm == nil {
+		q.Logger().Warn("The swtpm had stopped")
+		return nil
+	}
+
+	if err := q.swtpm.Stop(ctx); err != nil {
+		return err
+	}
+	q.state.SwtpmPid = 0
+	return nil
+}
+
 func (q *qemu) getMemArgs() (bool, string, string, error) {
 	share := false
 	target := ""
@@ -953,7 +1038,7 @@ func (q *qemu) getMemArgs() (bool, string, string, error) {
 			return share, target, "", fmt.Errorf("Vhost-user-blk/scsi requires hugepage memory")
 		}
 
-		if q.config.SharedFS == config.VirtioFS || q.config.SharedFS == config.VirtioFSNydus ||
+		if IsVirtioFS(q.config.SharedFS) ||
 			q.config.FileBackedMemRootDir != "" {
 			target = q.qemuConfig.Memory.Path
 			memoryBack = "memory-backend-file"
@@ -1173,7 +1258,7 @@ func (q *qemu) StartVM(ctx context.Context, timeout int) error {
 		}
 		defer selinux.SetExecLabel("")
 	}
-	if q.config.SharedFS == config.VirtioFS || q.config.SharedFS == config.VirtioFSNydus {
+	if IsVirtioFS(q.config.SharedFS) {
 		err = q.setupVirtiofsDaemon(ctx)
 		if err != nil {
 			return err
@@ -1188,6 +1273,20 @@ func (q *qemu) StartVM(ctx context.Context, timeout int) error {
 
 	}
 
+	if q.config.EnableTPM {
+		err = q.setupSwtpm(ctx)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err != nil {
+				if shutdownErr := q.stopSwtpm(ctx); shutdownErr != nil {
+					q.Logger().WithError(shutdownErr).Warn("failed to stop swtpm")
+				}
+			}
+		}()
+	}
+
 	qemuCmd, reader, err := govmmQemu.LaunchQemu(q.qemuConfig, newQMPLogger())
 	if err != nil {
 		q.Logger().WithError(err).Error("failed to launch qemu")
@@ -1330,12 +1429,18 @@ func (q *qemu) StopVM(ctx context.Context, waitOnly bool) (err error) {
 		}
 	}
 
-	if q.config.SharedFS == config.VirtioFS || q.config.SharedFS == config.VirtioFSNydus {
+	if IsVirtioFS(q.config.SharedFS) {
 		if err := q.stopVirtiofsDaemon(ctx); err != nil {
 			return err
 		}
 	}
 
+	if q.config.EnableTPM {
+		if err := q.stopSwtpm(ctx); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -1616,7 +1721,7 @@ func (q *qemu) hotplugAddBlockDevice(ctx context.Context, drive *config.BlockDri
 			return err
 		}
 
-		if err = q.qmpMonitorCh.qmp.ExecuteNVDIMMDeviceAdd(q.qmpMonitorCh.ctx, drive.ID, drive.File, blocksize, &drive.Pmem); err != nil {
+		if err = q.qmpMonitorCh.qmp.ExecuteNVDIMMDeviceAddWithReadOnly(q.qmpMonitorCh.ctx, drive.ID, drive.File, blocksize, &drive.Pmem, drive.ReadOnly); err != nil {
 			q.Logger().WithError(err).Errorf("Failed to add NVDIMM device %s", drive.File)
 			return err
 		}
@@ -1754,6 +1859,9 @@ func (q *qemu) hotplugAddVhostUserBlkDevice(ctx context.Context, vAttr *config.V
 		addr := "00"
 
 		bridgeID := fmt.Sprintf("%s%d", config.PCIeRootPortPrefix, len(config.PCIeDevicesPerPort[config.RootPort]))
+		if err = checkPCIePortBus(config.RootPort, bridgeID, q.state.PCIeRootPort); err != nil {
+			return err
+		}
 		dev := config.VFIODev{ID: devID}
 		config.PCIeDevicesPerPort[config.RootPort] = append(config.PCIeDevicesPerPort[config.RootPort], dev)
 
@@ -1829,6 +1937,96 @@ func (q *qemu) hotplugBlockDevice(ctx context.Context, drive *config.BlockDrive,
 	return q.qmpMonitorCh.qmp.ExecuteBlockdevDel(q.qmpMonitorCh.ctx, drive.ID)
 }
 
+// hotplugSCSIPassthroughDevice plugs a host SCSI device on the SCSI bus
+// of the block devices, with a scsi-generic or scsi-block device issuing the
+// guest SCSI commands to the host device.
+func (q *qemu) hotplugSCSIPassthroughDevice(ctx context.Context, drive *config.SCSIPassthroughDrive, op Operation) (err error) {
+	if err := q.qmpSetup(); err != nil {
+		return err
+	}
+
+	devID := "virtio-" + drive.ID
+
+	if op == RemoveDevice {
+		if err := q.qmpMonitorCh.qmp.ExecuteDeviceDel(q.qmpMonitorCh.ctx, devID); err != nil {
+			return err
+		}
+
+		return q.qmpMonitorCh.qmp.ExecuteBlockdevDel(q.qmpMonitorCh.ctx, drive.ID)
+	}
+
+	if q.config.BlockDeviceDriver != config.VirtioSCSI {
+		return fmt.Errorf("SCSI passthrough requires the %s block device driver, not %s", config.VirtioSCSI, q.config.BlockDeviceDriver)
+	}
+
+	qblkDevice := govmmQemu.BlockDevice{
+		ID:       drive.ID,
+		File:     drive.File,
+		ReadOnly: drive.ReadOnly,
+		AIO:      govmmQemu.BlockDeviceAIO(q.config.BlockDeviceAIO),
+	}
+
+	if err = q.qmpMonitorCh.qmp.ExecuteBlockdevAdd(q.qmpMonitorCh.ctx, &qblkDevice); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			q.qmpMonitorCh.qmp.ExecuteBlockdevDel(q.qmpMonitorCh.ctx, drive.ID)
+		}
+	}()
+
+	// Bus exposed by the SCSI Controller
+	bus := scsiControllerID + ".0"
+
+	scsiID, lun, err := utils.GetSCSIIdLun(drive.Index)
+	if err != nil {
+		return err
+	}
+
+	return q.qmpMonitorCh.qmp.ExecuteSCSIDeviceAdd(q.qmpMonitorCh.ctx, drive.ID, devID, drive.Driver, bus, romFile, scsiID, lun, true, defaultDisableModern)
+}
+
+// hotplugVirtioSerialPort plugs a virtio-serial port backed by a socket the
+// host character device is relayed to, and sets the socket of the drive.
+func (q *qemu) hotplugVirtioSerialPort(ctx context.Context, drive *config.CharDrive, op Operation) (err error) {
+	if err := q.qmpSetup(); err != nil {
+		return err
+	}
+
+	devID := "virtio-" + drive.ID
+
+	if op == RemoveDevice {
+		if err := q.qmpMonitorCh.qmp.ExecuteDeviceDel(q.qmpMonitorCh.ctx, devID); err != nil {
+			return err
+		}
+
+		return q.qmpMonitorCh.qmp.ExecuteChardevDel(q.qmpMonitorCh.ctx, drive.ID)
+	}
+
+	if len(q.config.CharDevicePassthrough) == 0 || q.config.CharDeviceTransport == config.CharDeviceVsock {
+		return fmt.Errorf("no virtio-serial controller for character devices, char_device_passthrough is not set or char_device_transport is not %s", config.CharDeviceVirtioSerial)
+	}
+
+	drive.SocketPath, err = utils.BuildSocketPath(q.config.RunStorePath, q.id, drive.ID+".sock")
+	if err != nil {
+		return err
+	}
+
+	// The runtime connects to the socket to relay the host character device
+	if err = q.qmpMonitorCh.qmp.ExecuteCharDevUnixSocketAdd(q.qmpMonitorCh.ctx, drive.ID, drive.SocketPath, false, true, 0); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			q.qmpMonitorCh.qmp.ExecuteChardevDel(q.qmpMonitorCh.ctx, drive.ID)
+		}
+	}()
+
+	return q.qmpMonitorCh.qmp.ExecuteVirtSerialPortAddWithBus(q.qmpMonitorCh.ctx, devID, drive.PortName, drive.ID, charDeviceSerialID+".0")
+}
+
 func (q *qemu) hotplugVhostUserDevice(ctx context.Context, vAttr *config.VhostUserDeviceAttrs, op Operation) error {
 	if err := q.qmpSetup(); err != nil {
 		return err
@@ -1928,8 +2126,18 @@ func (q *qemu) hotplugVFIODevice(ctx context.Context, device *config.VFIODev, op
 		// a large PCI BAR which is a currently a limitation with PCI bridges.
 		switch q.state.HotPlugVFIO {
 		case config.RootPort:
+			if device.IsPCIe {
+				if err = checkPCIePortBus(config.RootPort, device.Bus, q.state.PCIeRootPort); err != nil {
+					return err
+				}
+			}
 			err = q.hotplugVFIODeviceRootPort(ctx, device)
 		case config.SwitchPort:
+			if device.IsPCIe {
+				if err = checkPCIePortBus(config.SwitchPort, device.Bus, q.state.PCIeSwitchPort); err != nil {
+					return err
+				}
+			}
 			err = q.hotplugVFIODeviceSwitchPort(ctx, device)
 		case config.BridgePort:
 			err = q.hotplugVFIODeviceBridgePort(ctx, device)
@@ -1984,26 +2192,76 @@ func (q *qemu) hotAddNetDevice(name, hardAddr string, VMFds, VhostFds []*os.File
 	return q.qmpMonitorCh.qmp.ExecuteNetdevAddByFds(q.qmpMonitorCh.ctx, "tap", name, VMFdNames, VhostFdNames)
 }
 
-func (q *qemu) hotplugNetDevice(ctx context.Context, endpoint Endpoint, op Operation) (err error) {
-	if err = q.qmpSetup(); err != nil {
-		return err
+// setPasstPid tracks the passt process the endpoint connects the VM to, if
+// any, which serves the VM like virtiofsd does.
+func (q *qemu) setPasstPid(endpoint Endpoint, op Operation) {
+	passt, ok := endpoint.(*PasstEndpoint)
+	if !ok {
+		return
 	}
-	var tap TapInterface
 
+	if op == AddDevice {
+		q.state.PasstPid = passt.PID
+	} else {
+		q.state.PasstPid = 0
+	}
+}
+
+// hotplugNetTap returns the tap identifying the netdev of the endpoint, and
+// the vhost-vdpa device or the passt socket the netdev connects to, if any.
+func hotplugNetTap(endpoint Endpoint) (tap TapInterface, vhostVdpaPath string, passtSocketPath string, err error) {
 	switch endpoint.Type() {
 	case VethEndpointType, IPVlanEndpointType, MacvlanEndpointType, TuntapEndpointType:
 		tap = endpoint.NetworkPair().TapInterface
 	case TapEndpointType:
 		drive := endpoint.(*TapEndpoint)
 		tap = drive.TapInterface
+	case VdpaEndpointType:
+		// There is no tap, the vdpa device name identifies the netdev.
+		vdpa := endpoint.(*VdpaEndpoint)
+		tap = TapInterface{ID: vdpa.VdpaDevName, Name: vdpa.VdpaDevName}
+		vhostVdpaPath = vdpa.VhostVdpaPath
+	case PasstEndpointType:
+		// There is no tap either, the netdev connects to the passt socket.
+		passt := endpoint.(*PasstEndpoint)
+		tap = TapInterface{ID: "passt-" + passt.IfaceName, Name: "passt-" + passt.IfaceName}
+		passtSocketPath = passt.SocketPath
 	default:
-		return fmt.Errorf("this endpoint is not supported")
+		// The plugin endpoints are backed by the tap of their network pair.
+		if _, ok := endpoint.(*pluginEndpoint); !ok || endpoint.NetworkPair() == nil {
+			return TapInterface{}, "", "", fmt.Errorf("this endpoint is not supported")
+		}
+		tap = endpoint.NetworkPair().TapInterface
+	}
+
+	return tap, vhostVdpaPath, passtSocketPath, nil
+}
+
+func (q *qemu) hotplugNetDevice(ctx context.Context, endpoint Endpoint, op Operation) (err error) {
+	if err = q.qmpSetup(); err != nil {
+		return err
+	}
+	tap, vhostVdpaPath, passtSocketPath, err := hotplugNetTap(endpoint)
+	if err != nil {
+		return err
 	}
 
 	devID := "virtio-" + tap.ID
 	machineType := q.HypervisorConfig().HypervisorMachineType
+	queues := int(q.config.NumVCPUs())
+	if vdpa, ok := endpoint.(*VdpaEndpoint); ok {
+		// The queues of a vdpa device are the ones of the hardware.
+		queues = int(vdpa.NumQueues / 2)
+	}
 	if op == AddDevice {
-		if err = q.hotAddNetDevice(tap.Name, endpoint.HardwareAddr(), tap.VMFds, tap.VhostFds); err != nil {
+		if vhostVdpaPath != "" {
+			err = q.qmpMonitorCh.qmp.ExecuteNetdevVhostVdpaAdd(q.qmpMonitorCh.ctx, tap.Name, vhostVdpaPath, queues)
+		} else if passtSocketPath != "" {
+			err = q.qmpMonitorCh.qmp.ExecuteNetdevStreamAdd(q.qmpMonitorCh.ctx, tap.Name, passtSocketPath)
+		} else {
+			err = q.hotAddNetDevice(tap.Name, endpoint.HardwareAddr(), tap.VMFds, tap.VhostFds)
+		}
+		if err != nil {
 			return err
 		}
 
@@ -2017,10 +2275,13 @@ func (q *qemu) hotplugNetDevice(ctx context.Context, endpoint Endpoint, op Opera
 		if machineType == QemuVirt {
 			addr := "00"
 			bridgeID := fmt.Sprintf("%s%d", config.PCIeRootPortPrefix, len(config.PCIeDevicesPerPort[config.RootPort]))
+			if err = checkPCIePortBus(config.RootPort, bridgeID, q.state.PCIeRootPort); err != nil {
+				return err
+			}
 			dev := config.VFIODev{ID: devID}
 			config.PCIeDevicesPerPort[config.RootPort] = append(config.PCIeDevicesPerPort[config.RootPort], dev)
 
-			return q.qmpMonitorCh.qmp.ExecuteNetPCIDeviceAdd(q.qmpMonitorCh.ctx, tap.Name, devID, endpoint.HardwareAddr(), addr, bridgeID, romFile, int(q.config.NumVCPUs()), defaultDisableModern)
+			return q.qmpMonitorCh.qmp.ExecuteNetPCIDeviceAdd(q.qmpMonitorCh.ctx, tap.Name, devID, endpoint.HardwareAddr(), addr, bridgeID, romFile, queues, defaultDisableModern)
 		}
 
 		addr, bridge, err := q.arch.addDeviceToBridge(ctx, tap.ID, types.PCI)
@@ -2043,9 +2304,9 @@ func (q *qemu) hotplugNetDevice(ctx context.Context, endpoint Endpoint, op Opera
 		}
 		if machine.Type == QemuCCWVirtio {
 			devNoHotplug := fmt.Sprintf("fe.%x.%v", bridge.Addr, addr)
-			return q.qmpMonitorCh.qmp.ExecuteNetCCWDeviceAdd(q.qmpMonitorCh.ctx, tap.Name, devID, endpoint.HardwareAddr(), devNoHotplug, int(q.config.NumVCPUs()))
+			return q.qmpMonitorCh.qmp.ExecuteNetCCWDeviceAdd(q.qmpMonitorCh.ctx, tap.Name, devID, endpoint.HardwareAddr(), devNoHotplug, queues)
 		}
-		return q.qmpMonitorCh.qmp.ExecuteNetPCIDeviceAdd(q.qmpMonitorCh.ctx, tap.Name, devID, endpoint.HardwareAddr(), addr, bridge.ID, romFile, int(q.config.NumVCPUs()), defaultDisableModern)
+		return q.qmpMonitorCh.qmp.ExecuteNetPCIDeviceAdd(q.qmpMonitorCh.ctx, tap.Name, devID, endpoint.HardwareAddr(), addr, bridge.ID, romFile, queues, defaultDisableModern)
 	}
 
 	if err := q.arch.removeDeviceFromBridge(tap.ID); err != nil {
@@ -2075,10 +2336,20 @@ func (q *qemu) hotplugDevice(ctx context.Context, devInfo interface{}, devType D
 		return q.hotplugMemory(memdev, op)
 	case NetDev:
 		device := devInfo.(Endpoint)
-		return nil, q.hotplugNetDevice(ctx, device, op)
+		if err := q.hotplugNetDevice(ctx, device, op); err != nil {
+			return nil, err
+		}
+		q.setPasstPid(device, op)
+		return nil, nil
 	case VhostuserDev:
 		vAttr := devInfo.(*config.VhostUserDeviceAttrs)
 		return nil, q.hotplugVhostUserDevice(ctx, vAttr, op)
+	case SCSIPassthroughDev:
+		drive := devInfo.(*config.SCSIPassthroughDrive)
+		return nil, q.hotplugSCSIPassthroughDevice(ctx, drive, op)
+	case VirtioSerialPortDev:
+		drive := devInfo.(*config.CharDrive)
+		return nil, q.hotplugVirtioSerialPort(ctx, drive, op)
 	default:
 		return nil, fmt.Errorf("cannot hotplug device: unsupported device type '%v'", devType)
 	}
@@ -2110,6 +2381,85 @@ func (q *qemu) HotplugRemoveDevice(ctx context.Context, devInfo interface{}, dev
 	return data, nil
 }
 
+// IsDevicePlugged reports whether the device is plugged in the VM, from the
+// PCI devices, the block devices and the serial ports QEMU knows about.
+func (q *qemu) IsDevicePlugged(ctx context.Context, devInfo interface{}, devType DeviceType) (bool, error) {
+	span, ctx := katatrace.Trace(ctx, q.Logger(), "IsDevicePlugged", qemuTracingTags)
+	katatrace.AddTags(span, "sandbox_id", q.id, "device", devInfo)
+	defer span.End()
+
+	var devID string
+	switch devType {
+	case BlockDev:
+		devID = "virtio-" + devInfo.(*config.BlockDrive).ID
+	case SCSIPassthroughDev:
+		devID = "virtio-" + devInfo.(*config.SCSIPassthroughDrive).ID
+	case VhostuserDev:
+		devID = "virtio-" + devInfo.(*config.VhostUserDeviceAttrs).DevID
+	case VfioDev:
+		devID = devInfo.(*config.VFIODev).ID
+	case VirtioSerialPortDev:
+		devID = "virtio-" + devInfo.(*config.CharDrive).ID
+	default:
+		return false, fmt.Errorf("cannot query device: unsupported device type '%v'", devType)
+	}
+
+	if err := q.qmpSetup(); err != nil {
+		return false, err
+	}
+
+	// The serial ports are neither on a PCI bus nor block devices, they are
+	// only known as devices with an ID.
+	if devType == VirtioSerialPortDev {
+		peripherals, err := q.qmpMonitorCh.qmp.ExecuteQomList(q.qmpMonitorCh.ctx, "/machine/peripheral")
+		if err != nil {
+			return false, err
+		}
+		for _, peripheral := range peripherals {
+			if peripheral.Name == devID {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	pciInfo, err := q.qmpMonitorCh.qmp.ExecuteQueryPCI(q.qmpMonitorCh.ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, bus := range pciInfo {
+		if hasPCIDevice(bus.Devices, devID) {
+			return true, nil
+		}
+	}
+
+	blockInfo, err := q.qmpMonitorCh.qmp.ExecuteQueryBlock(q.qmpMonitorCh.ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, block := range blockInfo {
+		if block.Qdev == devID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// hasPCIDevice looks for the device devID on a PCI bus and on the buses
+// behind its bridges.
+func hasPCIDevice(devices []govmmQemu.PCIDeviceInfo, devID string) bool {
+	for _, dev := range devices {
+		if dev.QdevID == devID {
+			return true
+		}
+		if dev.PCIBridge != nil && hasPCIDevice(dev.PCIBridge.Devices, devID) {
+			return true
+		}
+	}
+	return false
+}
+
 func (q *qemu) hotplugCPUs(vcpus uint32, op Operation) (uint32, error) {
 	if vcpus == 0 {
 		q.Logger().Warnf("cannot hotplug 0 vCPUs")
@@ -2349,7 +2699,7 @@ func (q *qemu) AddDevice(ctx context.Context, devInfo interface{}, devType Devic
 
 	switch v := devInfo.(type) {
 	case types.Volume:
-		if q.config.SharedFS == config.VirtioFS || q.config.SharedFS == config.VirtioFSNydus {
+		if IsVirtioFS(q.config.SharedFS) {
 			q.Logger().WithField("volume-type", "virtio-fs").Info("adding volume")
 
 			var randBytes []byte
@@ -2387,6 +2737,7 @@ func (q *qemu) AddDevice(ctx context.Context, devInfo interface{}, devType Devic
 		q.qemuConfig.Devices, err = q.arch.appendVSock(ctx, q.qemuConfig.Devices, v)
 	case Endpoint:
 		q.qemuConfig.Devices, err = q.arch.appendNetwork(ctx, q.qemuConfig.Devices, v)
+		q.setPasstPid(v, AddDevice)
 	case config.BlockDrive:
 		q.qemuConfig.Devices, err = q.arch.appendBlockDevice(ctx, q.qemuConfig.Devices, v)
 	case config.VhostUserDeviceAttrs:
@@ -2859,6 +3210,12 @@ func (q *qemu) GetPids() []int {
 	if q.state.VirtiofsDaemonPid != 0 {
 		pids = append(pids, q.state.VirtiofsDaemonPid)
 	}
+	if q.state.SwtpmPid != 0 {
+		pids = append(pids, q.state.SwtpmPid)
+	}
+	if q.state.PasstPid != 0 {
+		pids = append(pids, q.state.PasstPid)
+	}
 
 	return pids
 }
@@ -2934,9 +3291,15 @@ func (q *qemu) Save() (s hv.HypervisorState) {
 		s.Pid = pids[0]
 	}
 	s.VirtiofsDaemonPid = q.state.VirtiofsDaemonPid
+	s.SwtpmPid = q.state.SwtpmPid
+	s.PasstPid = q.state.PasstPid
 	s.Type = string(QemuHypervisor)
 	s.UUID = q.state.UUID
 	s.HotpluggedMemory = q.state.HotpluggedMemory
+	s.HotPlugVFIO = q.state.HotPlugVFIO
+	s.ColdPlugVFIO = q.state.ColdPlugVFIO
+	s.PCIeRootPort = q.state.PCIeRootPort
+	s.PCIeSwitchPort = q.state.PCIeSwitchPort
 
 	for _, bridge := range q.arch.getBridges() {
 		s.Bridges = append(s.Bridges, hv.Bridge{
@@ -2959,6 +3322,12 @@ func (q *qemu) Load(s hv.HypervisorState) {
 	q.state.UUID = s.UUID
 	q.state.HotpluggedMemory = s.HotpluggedMemory
 	q.state.VirtiofsDaemonPid = s.VirtiofsDaemonPid
+	q.state.SwtpmPid = s.SwtpmPid
+	q.state.PasstPid = s.PasstPid
+	q.state.HotPlugVFIO = s.HotPlugVFIO
+	q.state.ColdPlugVFIO = s.ColdPlugVFIO
+	q.state.PCIeRootPort = s.PCIeRootPort
+	q.state.PCIeSwitchPort = s.PCIeSwitchPort
 
 	for _, bridge := range s.Bridges {
 		q.state.Bridges = append(q.state.Bridges, types.NewBridge(types.Type(bridge.Type), bridge.ID, bridge.DeviceAddr, bridge.Addr))
@@ -3002,3 +3371,24 @@ func (q *qemu) GenerateSocket(id string) (interface{}, error) {
 func (q *qemu) IsRateLimiterBuiltin() bool {
 	return false
 }
+
+func (q *qemu) UpdateNetRateLimiter(ctx context.Context, endpoint Endpoint, bandwidth NetworkBandwidth) error {
+	return fmt.Errorf("QEMU does not support built-in network rate limiters")
+}
+
+// ResizeBlockDevice resizes the block node of a hotplugged drive, QEMU then
+// notifies the guest of the new capacity.
+func (q *qemu) ResizeBlockDevice(ctx context.Context, drive *config.BlockDrive, size uint64) error {
+	span, _ := katatrace.Trace(ctx, q.Logger(), "ResizeBlockDevice", qemuTracingTags, map[string]string{"sandbox_id": q.id})
+	defer span.End()
+
+	if drive.NvdimmID != "" {
+		return fmt.Errorf("cannot resize the NVDIMM backed drive %s", drive.ID)
+	}
+
+	if err := q.qmpSetup(); err != nil {
+		return err
+	}
+
+	return q.qmpMonitorCh.qmp.ExecuteBlockResize(q.qmpMonitorCh.ctx, drive.ID, size)
+}
@@ -88,6 +88,10 @@ type qemuArch interface {
 	// appendSCSIController appens a SCSI controller to devices
 	appendSCSIController(context context.Context, devices []govmmQemu.Device, enableIOThreads bool) ([]govmmQemu.Device, *govmmQemu.IOThread, error)
 
+	// appendCharDeviceSerial appends the virtio-serial controller the host
+	// character devices are relayed to
+	appendCharDeviceSerial(ctx context.Context, devices []govmmQemu.Device) ([]govmmQemu.Device, error)
+
 	// appendBridges appends bridges to devices
 	appendBridges(devices []govmmQemu.Device) []govmmQemu.Device
 
@@ -205,8 +209,7 @@ const (
 	defaultBridgeBus          = "pcie.0"
 	defaultPCBridgeBus        = "pci.0"
 	maxDevIDSize              = 31
-	maxPCIeRootPort           = 16 // Limitation from QEMU
-	maxPCIeSwitchPort         = 16 // Limitation from QEMU
+	maxCharDevicePorts        = 31 // Limitation from QEMU, port 0 is reserved
 )
 
 // This is the PCI start address assigned to the first bridge that
@@ -401,6 +404,19 @@ func (q *qemuArchBase) appendConsole(_ context.Context, devices []govmmQemu.Devi
 	return devices, nil
 }
 
+func genericCharDeviceSerial(nestedRun bool) govmmQemu.SerialDevice {
+	return govmmQemu.SerialDevice{
+		Driver:        govmmQemu.VirtioSerial,
+		ID:            charDeviceSerialID,
+		DisableModern: nestedRun,
+		MaxPorts:      maxCharDevicePorts,
+	}
+}
+
+func (q *qemuArchBase) appendCharDeviceSerial(_ context.Context, devices []govmmQemu.Device) ([]govmmQemu.Device, error) {
+	return append(devices, genericCharDeviceSerial(q.nestedRun)), nil
+}
+
 func genericImage(path string) (config.BlockDrive, error) {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return config.BlockDrive{}, err
@@ -627,6 +643,44 @@ func genericNetwork(endpoint Endpoint, vhost, nestedRun bool, index int) (govmmQ
 			FDs:           netPair.VMFds,
 			VhostFDs:      netPair.VhostFds,
 		}
+	case *VdpaEndpoint:
+		d = govmmQemu.NetDevice{
+			Type:          govmmQemu.VHOSTVDPA,
+			Driver:        govmmQemu.VirtioNet,
+			ID:            fmt.Sprintf("network-%d", index),
+			VhostDev:      ep.VhostVdpaPath,
+			Queues:        int(ep.NumQueues / 2),
+			MACAddress:    ep.HardwareAddr(),
+			DisableModern: nestedRun,
+		}
+	case *PasstEndpoint:
+		d = govmmQemu.NetDevice{
+			Type:          govmmQemu.STREAM,
+			Driver:        govmmQemu.VirtioNet,
+			ID:            fmt.Sprintf("network-%d", index),
+			SocketPath:    ep.SocketPath,
+			MACAddress:    ep.HardwareAddr(),
+			DisableModern: nestedRun,
+		}
+	case *pluginEndpoint:
+		// The plugin endpoints are backed by the tap of their network pair.
+		netPair := ep.NetworkPair()
+		if netPair == nil {
+			return govmmQemu.NetDevice{}, fmt.Errorf("Endpoint of type %s has no network pair", ep.Type())
+		}
+		d = govmmQemu.NetDevice{
+			Type:          networkModelToQemuType(netPair.NetInterworkingModel),
+			Driver:        govmmQemu.VirtioNet,
+			ID:            fmt.Sprintf("network-%d", index),
+			IFName:        netPair.TAPIface.Name,
+			MACAddress:    netPair.TAPIface.HardAddr,
+			DownScript:    "no",
+			Script:        "no",
+			VHost:         vhost,
+			DisableModern: nestedRun,
+			FDs:           netPair.VMFds,
+			VhostFDs:      netPair.VhostFds,
+		}
 	default:
 		return govmmQemu.NetDevice{}, fmt.Errorf("Unknown type for endpoint")
 	}
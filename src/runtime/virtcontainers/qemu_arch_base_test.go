@@ -553,6 +553,24 @@ func TestQemuArchBaseAppendSCSIController(t *testing.T) {
 	assert.NoError(err)
 }
 
+func TestQemuArchBaseAppendCharDeviceSerial(t *testing.T) {
+	var devices []govmmQemu.Device
+	assert := assert.New(t)
+	qemuArchBase := newQemuArchBase()
+
+	expectedOut := []govmmQemu.Device{
+		govmmQemu.SerialDevice{
+			Driver:   govmmQemu.VirtioSerial,
+			ID:       charDeviceSerialID,
+			MaxPorts: maxCharDevicePorts,
+		},
+	}
+
+	devices, err := qemuArchBase.appendCharDeviceSerial(context.Background(), devices)
+	assert.NoError(err)
+	assert.Equal(expectedOut, devices)
+}
+
 func TestQemuArchBaseAppendNetwork(t *testing.T) {
 	var devices []govmmQemu.Device
 	var err error
@@ -588,6 +606,21 @@ func TestQemuArchBaseAppendNetwork(t *testing.T) {
 		},
 	}
 
+	vdpaEp := &VdpaEndpoint{
+		VdpaDevName:   "vdpa0",
+		VhostVdpaPath: "/dev/vhost-vdpa-0",
+		NumQueues:     8,
+		HardAddr:      macAddr.String(),
+		EndpointType:  VdpaEndpointType,
+	}
+
+	passtEp := &PasstEndpoint{
+		SocketPath:   "/run/vc/sbs/passt.socket",
+		HardAddr:     macAddr.String(),
+		IfaceName:    "eth0",
+		EndpointType: PasstEndpointType,
+	}
+
 	expectedOut := []govmmQemu.Device{
 		govmmQemu.NetDevice{
 			Type:       networkModelToQemuType(macvlanEp.NetPair.NetInterworkingModel),
@@ -611,12 +644,31 @@ func TestQemuArchBaseAppendNetwork(t *testing.T) {
 			FDs:        macvtapEp.VMFds,
 			VhostFDs:   macvtapEp.VhostFds,
 		},
+		govmmQemu.NetDevice{
+			Type:       govmmQemu.VHOSTVDPA,
+			Driver:     govmmQemu.VirtioNet,
+			ID:         fmt.Sprintf("network-%d", 2),
+			VhostDev:   vdpaEp.VhostVdpaPath,
+			Queues:     4,
+			MACAddress: vdpaEp.HardwareAddr(),
+		},
+		govmmQemu.NetDevice{
+			Type:       govmmQemu.STREAM,
+			Driver:     govmmQemu.VirtioNet,
+			ID:         fmt.Sprintf("network-%d", 3),
+			SocketPath: passtEp.SocketPath,
+			MACAddress: passtEp.HardwareAddr(),
+		},
 	}
 
 	devices, err = qemuArchBase.appendNetwork(context.Background(), devices, macvlanEp)
 	assert.NoError(err)
 	devices, err = qemuArchBase.appendNetwork(context.Background(), devices, macvtapEp)
 	assert.NoError(err)
+	devices, err = qemuArchBase.appendNetwork(context.Background(), devices, vdpaEp)
+	assert.NoError(err)
+	devices, err = qemuArchBase.appendNetwork(context.Background(), devices, passtEp)
+	assert.NoError(err)
 	assert.Equal(expectedOut, devices)
 }
 
@@ -276,6 +276,22 @@ func (q *qemuS390x) appendSCSIController(ctx context.Context, devices []govmmQem
 	return devices, t, nil
 }
 
+func (q *qemuS390x) appendCharDeviceSerial(ctx context.Context, devices []govmmQemu.Device) ([]govmmQemu.Device, error) {
+	d := genericCharDeviceSerial(q.nestedRun)
+	d.Driver = virtioSerialCCW
+	addr, b, err := q.addDeviceToBridge(ctx, d.ID, types.CCW)
+	if err != nil {
+		return devices, fmt.Errorf("Failed to append character device serial %v", err)
+	}
+	d.DevNo, err = b.AddressFormatCCW(addr)
+	if err != nil {
+		return devices, fmt.Errorf("Failed to append character device serial %v", err)
+	}
+
+	devices = append(devices, d)
+	return devices, nil
+}
+
 func (q *qemuS390x) appendVSock(ctx context.Context, devices []govmmQemu.Device, vsock types.VSock) ([]govmmQemu.Device, error) {
 	var devno string
 	id := fmt.Sprintf("vsock-%d", vsock.ContextID)
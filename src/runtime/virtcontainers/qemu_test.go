@@ -19,6 +19,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
 
 	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
@@ -738,6 +739,56 @@ func TestQemuGetpids(t *testing.T) {
 	assert.True(len(pids) == 2)
 	assert.True(pids[0] == 100)
 	assert.True(pids[1] == 200)
+
+	q.state.SwtpmPid = 300
+	pids = q.GetPids()
+	assert.True(len(pids) == 3)
+	assert.True(pids[2] == 300)
+
+	// The passt process of the network is tracked with its endpoint
+	passt := &PasstEndpoint{PID: 400, EndpointType: PasstEndpointType}
+	q.setPasstPid(&TapEndpoint{}, AddDevice)
+	assert.Len(q.GetPids(), 3)
+	q.setPasstPid(passt, AddDevice)
+	pids = q.GetPids()
+	assert.True(len(pids) == 4)
+	assert.True(pids[3] == 400)
+	q.setPasstPid(passt, RemoveDevice)
+	assert.Len(q.GetPids(), 3)
+}
+
+func TestQemuAppendTPM(t *testing.T) {
+	assert := assert.New(t)
+
+	qemuConfig := newQemuConfig()
+	qemuConfig.EnableTPM = true
+	qemuConfig.SwtpmPath = "/usr/bin/swtpm"
+	qemuConfig.TPMStatePath = t.TempDir()
+	qemuConfig.VMStorePath = t.TempDir()
+
+	q := &qemu{
+		id:     "tpm-sandbox",
+		config: qemuConfig,
+	}
+
+	devices, err := q.appendTPM(nil)
+	assert.NoError(err)
+	assert.Len(devices, 1)
+	assert.Equal(govmmQemu.TPMDevice{
+		ID:         "tpm0",
+		Driver:     govmmQemu.TPMCRB,
+		SocketPath: filepath.Join(qemuConfig.VMStorePath, q.id, swtpmSocket),
+	}, devices[0])
+	assert.Equal(filepath.Join(qemuConfig.TPMStatePath, q.id), q.swtpm.statePath)
+
+	q.config.TPMModel = string(govmmQemu.TPMTIS)
+	devices, err = q.appendTPM(nil)
+	assert.NoError(err)
+	assert.Equal(govmmQemu.TPMTIS, devices[0].(govmmQemu.TPMDevice).Driver)
+
+	q.config.TPMModel = "tpm-spapr"
+	_, err = q.appendTPM(nil)
+	assert.Error(err)
 }
 
 func TestQemuSetConfig(t *testing.T) {
@@ -778,6 +829,31 @@ func TestQemuStartSandbox(t *testing.T) {
 	assert.Error(err)
 }
 
+func TestQemuVirtiofsDaemonQuit(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	q := &qemu{
+		config:         HypervisorConfig{SharedFS: config.VirtioFS},
+		virtiofsDaemon: &virtiofsdMock{},
+	}
+	atomic.StoreInt32(&q.stopped, 1)
+
+	quits := 0
+	q.setVirtiofsDaemonQuitHandler(func() {
+		quits++
+	})
+
+	// The daemon exit is reported
+	q.onVirtiofsDaemonQuit(ctx)
+	assert.Equal(1, quits)
+
+	// but not when it is stopped along with the VM
+	atomic.StoreInt32(&q.virtiofsDaemonStopping, 1)
+	q.onVirtiofsDaemonQuit(ctx)
+	assert.Equal(1, quits)
+}
+
 func TestPrepareInitdataImage(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -1200,3 +1276,24 @@ func TestResizeMemoryVirtioMemNegativeSize(t *testing.T) {
 	// State should remain unchanged
 	assert.Equal(100, q.state.HotpluggedMemory)
 }
+
+func TestHasPCIDevice(t *testing.T) {
+	assert := assert.New(t)
+
+	devices := []govmmQemu.PCIDeviceInfo{
+		{Slot: 1, QdevID: "virtio-drive-0"},
+		{
+			Slot: 2,
+			PCIBridge: &govmmQemu.PCIBridgeInfo{
+				Devices: []govmmQemu.PCIDeviceInfo{
+					{Slot: 0, QdevID: "vfio-0"},
+				},
+			},
+		},
+	}
+
+	assert.True(hasPCIDevice(devices, "virtio-drive-0"))
+	assert.True(hasPCIDevice(devices, "vfio-0"))
+	assert.False(hasPCIDevice(devices, "virtio-drive-1"))
+	assert.False(hasPCIDevice(nil, "vfio-0"))
+}
@@ -13,6 +13,7 @@ import (
 
 	cri "github.com/containerd/containerd/pkg/cri/annotations"
 	"github.com/containerd/ttrpc"
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
 	persistapi "github.com/kata-containers/kata-containers/src/runtime/pkg/hypervisors"
 	pb "github.com/kata-containers/kata-containers/src/runtime/protocols/hypervisor"
 	hypannotations "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/pkg/annotations"
@@ -215,6 +216,10 @@ func (rh *remoteHypervisor) HotplugRemoveDevice(ctx context.Context, devInfo int
 	return nil, notImplemented("HotplugRemoveDevice")
 }
 
+func (rh *remoteHypervisor) IsDevicePlugged(ctx context.Context, devInfo interface{}, devType DeviceType) (bool, error) {
+	return false, notImplemented("IsDevicePlugged")
+}
+
 func (rh *remoteHypervisor) ResizeMemory(ctx context.Context, memMB uint32, memoryBlockSizeMB uint32, probe bool) (uint32, MemoryDevice, error) {
 	return memMB, MemoryDevice{}, nil
 }
@@ -296,3 +301,11 @@ func (rh *remoteHypervisor) Load(persistapi.HypervisorState) {
 func (rh *remoteHypervisor) IsRateLimiterBuiltin() bool {
 	return false
 }
+
+func (rh *remoteHypervisor) UpdateNetRateLimiter(ctx context.Context, endpoint Endpoint, bandwidth NetworkBandwidth) error {
+	return notImplemented("UpdateNetRateLimiter")
+}
+
+func (rh *remoteHypervisor) ResizeBlockDevice(ctx context.Context, drive *config.BlockDrive, size uint64) error {
+	return notImplemented("ResizeBlockDevice")
+}
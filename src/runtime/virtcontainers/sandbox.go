@@ -201,6 +201,14 @@ type SandboxConfig struct {
 	// /var/lib/k0s/kubelet for k0s). If empty, the runtime uses the default
 	// /var/lib/kubelet for matching ConfigMap/Secret volume paths.
 	KubeletRootDir string
+
+	// ErofsLayerCache attaches the identical erofs layers of the containers
+	// once, looking them up by digest in the node-level layer cache.
+	ErofsLayerCache bool
+
+	// ErofsLayerCacheDir is the directory of the node-level erofs layer
+	// cache. If empty, the runtime uses /run/kata-containers/erofs-layers.
+	ErofsLayerCacheDir string
 }
 
 // valid checks that the sandbox configuration is valid.
@@ -247,6 +255,9 @@ type Sandbox struct {
 	overheadController resCtrl.ResourceController
 
 	containers map[string]*Container
+	// containersLock guards the additions and removals of containers
+	// against the readers out of the sandbox API calls.
+	containersLock sync.RWMutex
 
 	id string
 
@@ -269,6 +280,19 @@ type Sandbox struct {
 	// multiple times for hot-plugged network device when Sandbox has multiple
 	// containers.
 	hotplugNetworkConfigApplied bool
+
+	// charDeviceRelays relays the host character devices to their
+	// virtio-serial ports, indexed by character device ID.
+	charDeviceRelays     map[string]*charDeviceRelay
+	charDeviceRelaysLock sync.Mutex
+
+	// hotplugIntents are the device hotplugs recorded in the hotplug
+	// journal, until the sandbox state reflecting them is saved.
+	hotplugIntents     []*persistapi.HotplugIntent
+	hotplugIntentsLock sync.Mutex
+
+	// events are the sandbox events not read yet.
+	events chan SandboxEvent
 }
 
 // ID returns the sandbox identifier string.
@@ -434,6 +458,7 @@ func (s *Sandbox) Release(ctx context.Context) error {
 		s.monitor.stop()
 	}
 	s.fsShare.StopFileEventWatcher(ctx)
+	s.stopCharDeviceRelay("")
 	s.hypervisor.Disconnect(ctx)
 	return s.agent.disconnect(ctx)
 }
@@ -693,8 +718,11 @@ func newSandbox(ctx context.Context, sandboxConfig SandboxConfig, factory Factor
 		swapDeviceNum:   0,
 		swapSizeBytes:   0,
 		swapDevices:     []*config.BlockDrive{},
+		events:          make(chan SandboxEvent, sandboxEventsSize),
 	}
 
+	s.watchVirtiofsDaemon()
+
 	fsShare, err := NewFilesystemShare(s)
 	if err != nil {
 		return nil, err
@@ -707,6 +735,9 @@ func newSandbox(ctx context.Context, sandboxConfig SandboxConfig, factory Factor
 	defer func() {
 		if retErr != nil {
 			s.Logger().WithError(retErr).Error("Create new sandbox failed")
+			if err := s.deviceLeases().ReleaseAll(); err != nil {
+				s.Logger().WithError(err).Error("failed to release device leases")
+			}
 			s.store.Destroy(s.id)
 		}
 	}()
@@ -721,7 +752,8 @@ func newSandbox(ctx context.Context, sandboxConfig SandboxConfig, factory Factor
 
 	s.devManager = deviceManager.NewDeviceManager(sandboxConfig.HypervisorConfig.BlockDeviceDriver,
 		sandboxConfig.HypervisorConfig.EnableVhostUserStore,
-		sandboxConfig.HypervisorConfig.VhostUserStorePath, sandboxConfig.HypervisorConfig.VhostUserDeviceReconnect, nil)
+		sandboxConfig.HypervisorConfig.VhostUserStorePath, sandboxConfig.HypervisorConfig.VhostUserDeviceReconnect, nil, s.deviceLeases(),
+		sandboxConfig.HypervisorConfig.HostSysfs())
 
 	// Create the sandbox resource controllers.
 	if err := s.createResourceController(); err != nil {
@@ -830,6 +862,10 @@ func (s *Sandbox) coldOrHotPlugVFIO(sandboxConfig *SandboxConfig) (bool, error)
 				continue
 			}
 			isVFIODevice := deviceManager.IsVFIODevice(device.ContainerPath)
+			if isVFIODevice {
+				device.BindVFIO = sandboxConfig.HypervisorConfig.VFIOAutoBind
+				sandboxConfig.Containers[cnt].DeviceInfos[dev].BindVFIO = sandboxConfig.HypervisorConfig.VFIOAutoBind
+			}
 			if hotPlugVFIO && isVFIODevice {
 				device.ColdPlug = false
 				device.Port = sandboxConfig.HypervisorConfig.HotPlugVFIO
@@ -1044,7 +1080,9 @@ func (s *Sandbox) removeContainer(containerID string) error {
 			containerID, s.id)
 	}
 
+	s.containersLock.Lock()
 	delete(s.containers, containerID)
+	s.containersLock.Unlock()
 
 	return nil
 }
@@ -1078,6 +1116,37 @@ func (s *Sandbox) Delete(ctx context.Context) error {
 		s.Logger().WithError(err).Error("failed to Cleanup hypervisor")
 	}
 
+	s.stopCharDeviceRelay("")
+
+	// The TPM state outlives the sandbox, unless it is only identified by
+	// the sandbox. The states of the pods are removed once stale, the pods
+	// being gone.
+	if s.config.HypervisorConfig.EnableTPM {
+		if s.config.HypervisorConfig.TPMStateID == "" {
+			if err := os.RemoveAll(swtpmStatePath(&s.config.HypervisorConfig, s.id)); err != nil {
+				s.Logger().WithError(err).Error("failed to remove the TPM state")
+			}
+		}
+		if err := removeStaleSwtpmStates(s.config.HypervisorConfig.TPMStatePath, swtpmStateRetention); err != nil {
+			s.Logger().WithError(err).Error("failed to remove the stale TPM states")
+		}
+	}
+
+	// The VM is stopped, the VFIO devices can go back to the host
+	if s.devManager != nil {
+		if err := s.devManager.RestoreHostDrivers(); err != nil {
+			s.Logger().WithError(err).Error("failed to bind VFIO devices back to their host drivers")
+		}
+	}
+
+	if err := s.deviceLeases().ReleaseAll(); err != nil {
+		s.Logger().WithError(err).Error("failed to release device leases")
+	}
+
+	if err := s.erofsLayers().releaseSandbox(s.id); err != nil {
+		s.Logger().WithError(err).Error("failed to release erofs layers")
+	}
+
 	if err := s.fsShare.Cleanup(ctx); err != nil {
 		s.Logger().WithError(err).Error("failed to cleanup share files")
 	}
@@ -1089,6 +1158,28 @@ func (s *Sandbox) Delete(ctx context.Context) error {
 	return s.store.Destroy(s.id)
 }
 
+// deviceLeases returns the registry claiming the sandbox devices across the
+// sandboxes of the node.
+func (s *Sandbox) deviceLeases() *deviceManager.DeviceLeases {
+	return deviceManager.NewDeviceLeases(s.store, s.id, func(sandboxID string) bool {
+		_, err := os.Stat(filepath.Join(s.store.RunStoragePath(), sandboxID))
+		return !os.IsNotExist(err)
+	})
+}
+
+// erofsLayers returns the node-level cache of the erofs layers.
+func (s *Sandbox) erofsLayers() *erofsLayerCache {
+	dir := s.config.ErofsLayerCacheDir
+	if dir == "" {
+		dir = defaultErofsLayerCacheDir
+	}
+
+	return newErofsLayerCache(dir, func(sandboxID string) bool {
+		_, err := os.Stat(filepath.Join(s.store.RunStoragePath(), sandboxID))
+		return !os.IsNotExist(err)
+	})
+}
+
 // cleanupEphemeralDisks removes ephemeral disk images and their mount info.
 func (s *Sandbox) cleanupEphemeralDisks() error {
 	if s.config.EmptyDirMode != EmptyDirModeVirtioBlkEncrypted {
@@ -1108,8 +1199,11 @@ func (s *Sandbox) cleanupEphemeralDisks() error {
 }
 
 func (s *Sandbox) createNetwork(ctx context.Context) error {
-	if s.config.NetworkConfig.DisableNewNetwork ||
-		s.config.NetworkConfig.NetworkID == "" {
+	// User-mode networking does not depend on the network namespace
+	// interfaces, and can connect VMs running in the host one.
+	if !s.config.NetworkConfig.UserModeNetworking &&
+		(s.config.NetworkConfig.DisableNewNetwork ||
+			s.config.NetworkConfig.NetworkID == "") {
 		return nil
 	}
 
@@ -1275,6 +1369,69 @@ func (s *Sandbox) ListRoutes(ctx context.Context) ([]*pbTypes.Route, error) {
 	return s.agent.listRoutes(ctx)
 }
 
+// ListNetworkEndpoints lists the interfaces, routes and ARP neighbors the
+// sandbox network endpoints configure in the guest, as discovered from the
+// host network namespace.
+func (s *Sandbox) ListNetworkEndpoints(ctx context.Context) ([]*pbTypes.Interface, []*pbTypes.Route, []*pbTypes.ARPNeighbor, error) {
+	return generateVCNetworkStructures(ctx, s.network.Endpoints())
+}
+
+// ReloadDanConfig reconciles the network of a running sandbox with its DAN
+// config: the VFIO devices of the new interfaces are hot plugged, the
+// network of the new and changed interfaces is configured in the guest,
+// while the VFIO devices of the removed interfaces are hot unplugged.
+func (s *Sandbox) ReloadDanConfig(ctx context.Context) error {
+	changed, removed, err := s.network.UpdateDanEndpoints(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	if len(removed) > 0 {
+		s.Logger().WithField("endpoints", len(removed)).Info("Hot detaching DAN endpoints")
+		if err := s.network.RemoveEndpoints(ctx, s, removed, true); err != nil {
+			return err
+		}
+	}
+
+	interfaces, _, neighs, err := generateVCNetworkStructures(ctx, changed)
+	if err != nil {
+		return err
+	}
+
+	for _, ifc := range interfaces {
+		if _, err := s.agent.updateInterface(ctx, ifc); err != nil {
+			return fmt.Errorf("updating interface %s in guest: %w", ifc.Name, err)
+		}
+	}
+
+	// The guest route table is replaced as a whole, so the routes of all
+	// the endpoints have to be sent.
+	_, routes, _, err := generateVCNetworkStructures(ctx, s.network.Endpoints())
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.agent.updateRoutes(ctx, routes); err != nil {
+		return fmt.Errorf("updating routes in guest: %w", err)
+	}
+
+	if err := s.agent.addARPNeighbors(ctx, neighs); err != nil {
+		return fmt.Errorf("adding ARP neighbors in guest: %w", err)
+	}
+
+	return s.Save()
+}
+
+// UpdateInterfaceBandwidth changes the bandwidth limits of the network
+// endpoint named name. A zero rate removes the corresponding limit.
+func (s *Sandbox) UpdateInterfaceBandwidth(ctx context.Context, name string, bandwidth NetworkBandwidth) error {
+	if err := s.network.UpdateEndpointBandwidth(ctx, s, name, bandwidth); err != nil {
+		return err
+	}
+
+	return s.Save()
+}
+
 const (
 	// unix socket type of console
 	consoleProtoUnix = "unix"
@@ -1533,7 +1690,7 @@ func (s *Sandbox) startVM(ctx context.Context, prestartHookFunc func(context.Con
 		}
 		// If we want the network, scan the netns again to update the network
 		// configuration after the prestart hooks have run.
-		if !s.config.NetworkConfig.DisableNewNetwork {
+		if !s.config.NetworkConfig.DisableNewNetwork || s.config.NetworkConfig.UserModeNetworking {
 			if _, err := s.network.AddEndpoints(ctx, s, nil, false); err != nil {
 				return err
 			}
@@ -1572,7 +1729,7 @@ func (s *Sandbox) startVM(ctx context.Context, prestartHookFunc func(context.Con
 	// 3. In case of vm factory, scan the netns to hotplug interfaces after vm is started.
 	// 4. In case of prestartHookFunc, network config might have been changed. We need to
 	//    rescan and handle the change.
-	if !s.config.NetworkConfig.DisableNewNetwork &&
+	if (!s.config.NetworkConfig.DisableNewNetwork || s.config.NetworkConfig.UserModeNetworking) &&
 		caps.IsNetworkDeviceHotplugSupported() &&
 		(s.factory != nil || prestartHookFunc != nil) {
 		if _, err := s.network.AddEndpoints(ctx, s, nil, true); err != nil {
@@ -1630,7 +1787,9 @@ func (s *Sandbox) addContainer(c *Container) error {
 	if _, ok := s.containers[c.id]; ok {
 		return fmt.Errorf("Duplicated container: %s", c.id)
 	}
+	s.containersLock.Lock()
 	s.containers[c.id] = c
+	s.containersLock.Unlock()
 
 	return nil
 }
@@ -2158,6 +2317,13 @@ func (s *Sandbox) HotplugAddDevice(ctx context.Context, device api.Device, devTy
 		}
 	}
 
+	return s.journalHotplug(persistapi.HotplugAdd, device, devType, func() error {
+		return s.hotplugAddDevice(ctx, device, devType)
+	})
+}
+
+// hotplugAddDevice hot plugs the device in the VM.
+func (s *Sandbox) hotplugAddDevice(ctx context.Context, device api.Device, devType config.DeviceType) error {
 	switch devType {
 	case config.DeviceVFIO:
 		vfioDevices, ok := device.GetDeviceInfo().([]*config.VFIODev)
@@ -2185,6 +2351,41 @@ func (s *Sandbox) HotplugAddDevice(ctx context.Context, device api.Device, devTy
 		}
 		_, err := s.hypervisor.HotplugAddDevice(ctx, blockDevice.BlockDrive, BlockDev)
 		return err
+	case config.DeviceSCSIPassthrough:
+		scsiDevice, ok := device.(*drivers.SCSIPassthroughDevice)
+		if !ok {
+			return fmt.Errorf("device type mismatch, expect device type to be %s", devType)
+		}
+		_, err := s.hypervisor.HotplugAddDevice(ctx, scsiDevice.Drive, SCSIPassthroughDev)
+		return err
+	case config.DeviceCharPassthrough:
+		charDevice, ok := device.(*drivers.CharPassthroughDevice)
+		if !ok {
+			return fmt.Errorf("device type mismatch, expect device type to be %s", devType)
+		}
+		// The character devices are relayed to virtio-serial ports or
+		// over the vhost-vsock device of QEMU.
+		if s.config.HypervisorType != QemuHypervisor {
+			return fmt.Errorf("character device passthrough is not supported by hypervisor %s, only by %s", s.config.HypervisorType, QemuHypervisor)
+		}
+		if s.config.HypervisorConfig.CharDeviceTransport == config.CharDeviceVsock {
+			contextID, err := s.guestVsockContextID()
+			if err != nil {
+				return err
+			}
+			charDevice.CharDrive.VsockContextID = contextID
+			return s.startCharDeviceRelay(charDevice.CharDrive)
+		}
+		if _, err := s.hypervisor.HotplugAddDevice(ctx, charDevice.CharDrive, VirtioSerialPortDev); err != nil {
+			return err
+		}
+		if err := s.startCharDeviceRelay(charDevice.CharDrive); err != nil {
+			if _, rmErr := s.hypervisor.HotplugRemoveDevice(ctx, charDevice.CharDrive, VirtioSerialPortDev); rmErr != nil {
+				s.Logger().WithError(rmErr).Warn("failed to remove virtio-serial port")
+			}
+			return err
+		}
+		return nil
 	case config.VhostUserBlk:
 		vhostUserBlkDevice, ok := device.(*drivers.VhostUserBlkDevice)
 
@@ -2212,6 +2413,13 @@ func (s *Sandbox) HotplugRemoveDevice(ctx context.Context, device api.Device, de
 		}
 	}()
 
+	return s.journalHotplug(persistapi.HotplugRemove, device, devType, func() error {
+		return s.hotplugRemoveDevice(ctx, device, devType)
+	})
+}
+
+// hotplugRemoveDevice hot unplugs the device from the VM.
+func (s *Sandbox) hotplugRemoveDevice(ctx context.Context, device api.Device, devType config.DeviceType) error {
 	switch devType {
 	case config.DeviceVFIO:
 		vfioDevices, ok := device.GetDeviceInfo().([]*config.VFIODev)
@@ -2244,6 +2452,24 @@ func (s *Sandbox) HotplugRemoveDevice(ctx context.Context, device api.Device, de
 		}
 		_, err := s.hypervisor.HotplugRemoveDevice(ctx, blockDrive, BlockDev)
 		return err
+	case config.DeviceSCSIPassthrough:
+		drive, ok := device.GetDeviceInfo().(*config.SCSIPassthroughDrive)
+		if !ok {
+			return fmt.Errorf("device type mismatch, expect device type to be %s", devType)
+		}
+		_, err := s.hypervisor.HotplugRemoveDevice(ctx, drive, SCSIPassthroughDev)
+		return err
+	case config.DeviceCharPassthrough:
+		drive, ok := device.GetDeviceInfo().(*config.CharDrive)
+		if !ok {
+			return fmt.Errorf("device type mismatch, expect device type to be %s", devType)
+		}
+		s.stopCharDeviceRelay(drive.ID)
+		if drive.VsockPort != 0 {
+			return nil
+		}
+		_, err := s.hypervisor.HotplugRemoveDevice(ctx, drive, VirtioSerialPortDev)
+		return err
 	case config.VhostUserBlk:
 		vhostUserDeviceAttrs, ok := device.GetDeviceInfo().(*config.VhostUserDeviceAttrs)
 		if !ok {
@@ -2743,6 +2969,16 @@ func (s *Sandbox) setupResourceController() error {
 		return fmt.Errorf("Could not add runtime PID %d to the sandbox %s resource controller: %v", runtimePid, s.sandboxController, err)
 	}
 
+	// The endpoint processes started with the network, before the runtime
+	// joined the controller, are moved along.
+	if s.network != nil {
+		for _, pid := range endpointHelperPids(s.network.Endpoints()) {
+			if err := vmmController.AddProcess(pid); err != nil {
+				return fmt.Errorf("Could not add network PID %d to the sandbox %s resource controller: %v", pid, s.sandboxController, err)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -2805,32 +3041,68 @@ func (s *Sandbox) GuestVolumeStats(ctx context.Context, volumePath string) ([]by
 	return s.agent.getGuestVolumeStats(ctx, guestMountPath)
 }
 
-// ResizeGuestVolume resizes a volume in the guest.
+// ResizeGuestVolume resizes a volume in the guest. The backing file or device
+// of the volume must already have been grown to size bytes on the host.
 func (s *Sandbox) ResizeGuestVolume(ctx context.Context, volumePath string, size uint64) error {
-	// TODO: https://github.com/kata-containers/kata-containers/issues/3694.
-	guestMountPath, err := s.guestMountPath(volumePath)
+	m, err := s.guestMount(volumePath)
 	if err != nil {
 		return err
 	}
-	return s.agent.resizeGuestVolume(ctx, guestMountPath, size)
+
+	if err := s.resizeVolumeDevice(ctx, m.BlockDeviceID, size); err != nil {
+		return err
+	}
+
+	return s.agent.resizeGuestVolume(ctx, m.GuestDeviceMount, size)
+}
+
+// resizeVolumeDevice makes the hypervisor notify the guest of the new size of
+// the block device backing a volume. vhost-user devices are notified by their
+// backend, e.g. SPDK, when the bdev is resized.
+func (s *Sandbox) resizeVolumeDevice(ctx context.Context, deviceID string, size uint64) error {
+	if deviceID == "" {
+		return fmt.Errorf("volume has no block device to resize")
+	}
+
+	device := s.devManager.GetDeviceByID(deviceID)
+	if device == nil {
+		return fmt.Errorf("failed to find device by id %s", deviceID)
+	}
+
+	switch drive := device.GetDeviceInfo().(type) {
+	case *config.BlockDrive:
+		return s.hypervisor.ResizeBlockDevice(ctx, drive, size)
+	case *config.VhostUserDeviceAttrs:
+		return nil
+	default:
+		return fmt.Errorf("cannot resize device %s of type %s", deviceID, device.DeviceType())
+	}
 }
 
 func (s *Sandbox) guestMountPath(volumePath string) (string, error) {
+	m, err := s.guestMount(volumePath)
+	if err != nil {
+		return "", err
+	}
+	return m.GuestDeviceMount, nil
+}
+
+func (s *Sandbox) guestMount(volumePath string) (Mount, error) {
 	// verify the device even exists
 	if _, err := os.Stat(volumePath); err != nil {
 		s.Logger().WithError(err).WithField("volume", volumePath).Error("Cannot get stats for volume that doesn't exist")
-		return "", err
+		return Mount{}, err
 	}
 
 	// verify that we have a mount in this sandbox who's source maps to this
 	for _, c := range s.containers {
 		for _, m := range c.mounts {
 			if volumePath == m.Source {
-				return m.GuestDeviceMount, nil
+				return m, nil
 			}
 		}
 	}
-	return "", fmt.Errorf("mount %s not found in sandbox", volumePath)
+	return Mount{}, fmt.Errorf("mount %s not found in sandbox", volumePath)
 }
 
 // getSandboxCPUSet returns the union of each of the sandbox's containers' CPU sets'
@@ -2891,6 +3163,12 @@ func fetchSandbox(ctx context.Context, sandboxID string) (sandbox *Sandbox, err
 		return nil, err
 	}
 
+	// The runtime may have stopped in the middle of a device hotplug. The
+	// sandbox is fetched to be cleaned up, which must not fail on it.
+	if err := sandbox.reconcileHotplugJournal(ctx); err != nil {
+		sandbox.Logger().WithError(err).Warn("Could not reconcile the device hotplug journal")
+	}
+
 	return sandbox, nil
 }
 
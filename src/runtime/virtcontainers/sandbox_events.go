@@ -0,0 +1,105 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"sort"
+)
+
+// SandboxEventType is the type of a SandboxEvent.
+type SandboxEventType string
+
+const (
+	// RootfsUnhealthyEvent means that the rootfs of the containers is not
+	// reliable anymore: the virtio-fs daemon sharing it quit, and the
+	// sandbox is stopped.
+	RootfsUnhealthyEvent SandboxEventType = "rootfs-unhealthy"
+)
+
+// sandboxEventsSize is the number of sandbox events kept until they are read,
+// the events sent once it is reached are dropped.
+const sandboxEventsSize = 16
+
+// SandboxEvent is an event of the sandbox, for its containers.
+type SandboxEvent struct {
+	// Type is the type of the event
+	Type SandboxEventType
+	// ContainerIDs are the IDs of the containers affected by the event
+	ContainerIDs []string
+	// Message describes the event
+	Message string
+}
+
+// virtiofsDaemonWatcher is implemented by the hypervisors which notify when
+// their virtio-fs daemon quits.
+type virtiofsDaemonWatcher interface {
+	// setVirtiofsDaemonQuitHandler sets the function called when the
+	// virtio-fs daemon quits
+	setVirtiofsDaemonQuitHandler(handler func())
+}
+
+// GetSandboxEvent returns the next event of the sandbox, waiting for it until
+// the context is done.
+func (s *Sandbox) GetSandboxEvent(ctx context.Context) (SandboxEvent, error) {
+	select {
+	case event := <-s.events:
+		return event, nil
+	case <-ctx.Done():
+		return SandboxEvent{}, ctx.Err()
+	}
+}
+
+// sendEvent queues an event of the sandbox, without waiting for it to be
+// read.
+func (s *Sandbox) sendEvent(event SandboxEvent) {
+	select {
+	case s.events <- event:
+	default:
+		s.Logger().WithField("event", event.Type).Warn("sandbox events are not read, dropping event")
+	}
+}
+
+// watchVirtiofsDaemon has the sandbox notified when the virtio-fs daemon of
+// the hypervisor quits.
+func (s *Sandbox) watchVirtiofsDaemon() {
+	if watcher, ok := s.hypervisor.(virtiofsDaemonWatcher); ok {
+		watcher.setVirtiofsDaemonQuitHandler(s.virtiofsDaemonQuit)
+	}
+}
+
+// virtiofsDaemonQuit reports the rootfs of the containers shared through the
+// virtio-fs daemon which quit as unhealthy.
+func (s *Sandbox) virtiofsDaemonQuit() {
+	message := "virtio-fs daemon quit, sandbox stopped"
+	virtiofsdQuits.Inc()
+
+	s.Logger().Warn(message)
+
+	s.sendEvent(SandboxEvent{
+		Type:         RootfsUnhealthyEvent,
+		ContainerIDs: s.sharedRootfsContainers(),
+		Message:      message,
+	})
+}
+
+// sharedRootfsContainers returns the IDs of the containers whose rootfs is
+// shared with the guest, rather than plugged as a block device.
+func (s *Sandbox) sharedRootfsContainers() []string {
+	// The daemon quits while the containers may be added or removed.
+	s.containersLock.RLock()
+	defer s.containersLock.RUnlock()
+
+	var ids []string
+	for id, c := range s.containers {
+		if c.state.BlockDeviceID == "" {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	return ids
+}
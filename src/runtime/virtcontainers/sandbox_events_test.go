@@ -0,0 +1,76 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSandboxVirtiofsDaemonQuit(t *testing.T) {
+	assert := assert.New(t)
+
+	q := &qemu{}
+	s := &Sandbox{
+		id:         "sandbox",
+		hypervisor: q,
+		containers: map[string]*Container{
+			"shared": {},
+			"block":  {state: types.ContainerState{BlockDeviceID: "drive"}},
+		},
+		events: make(chan SandboxEvent, 1),
+	}
+
+	s.watchVirtiofsDaemon()
+	assert.NotNil(q.virtiofsDaemonQuitHandler)
+
+	// The containers whose rootfs is shared are reported unhealthy
+	q.virtiofsDaemonQuitHandler()
+	event, err := s.GetSandboxEvent(context.Background())
+	assert.NoError(err)
+	assert.Equal(RootfsUnhealthyEvent, event.Type)
+	assert.Equal([]string{"shared"}, event.ContainerIDs)
+
+	// and the events which are not read are dropped
+	q.virtiofsDaemonQuitHandler()
+	q.virtiofsDaemonQuitHandler()
+	event, err = s.GetSandboxEvent(context.Background())
+	assert.NoError(err)
+	assert.Contains(event.Message, "sandbox stopped")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = s.GetSandboxEvent(ctx)
+	assert.ErrorIs(err, context.DeadlineExceeded)
+}
+
+func TestSandboxSharedRootfsContainersConcurrent(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &Sandbox{
+		id:         "sandbox",
+		containers: map[string]*Container{"shared": {id: "shared"}},
+	}
+
+	// The virtio-fs daemon may quit while containers are added and removed.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			assert.NoError(s.addContainer(&Container{id: "other"}))
+			assert.NoError(s.removeContainer("other"))
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		assert.Contains(s.sharedRootfsContainers(), "shared")
+	}
+	<-done
+}
@@ -74,6 +74,13 @@ var (
 		[]string{"action"},
 	)
 
+	configVolumeSyncLag = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespaceKatashim,
+		Name:      "config_volume_sync_lag_seconds",
+		Help:      "Delay between a config volume update and its sync to the guest.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 10),
+	})
+
 	// virtiofsd
 	virtiofsdThreads = prometheus.NewGauge(prometheus.GaugeOpts{
 		Namespace: namespaceVirtiofsd,
@@ -110,6 +117,12 @@ var (
 		Name:      "fds",
 		Help:      "Open FDs for virtiofsd.",
 	})
+
+	virtiofsdQuits = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespaceVirtiofsd,
+		Name:      "quits_total",
+		Help:      "Virtiofsd exits while the sandbox was running.",
+	})
 )
 
 func RegisterMetrics() {
@@ -122,12 +135,14 @@ func RegisterMetrics() {
 	prometheus.MustRegister(hypervisorOpenFDs)
 	// agent
 	prometheus.MustRegister(agentRPCDurationsHistogram)
+	prometheus.MustRegister(configVolumeSyncLag)
 	// virtiofsd
 	prometheus.MustRegister(virtiofsdThreads)
 	prometheus.MustRegister(virtiofsdProcStatus)
 	prometheus.MustRegister(virtiofsdProcStat)
 	prometheus.MustRegister(virtiofsdIOStat)
 	prometheus.MustRegister(virtiofsdOpenFDs)
+	prometheus.MustRegister(virtiofsdQuits)
 }
 
 // UpdateRuntimeMetrics update shim/hypervisor's metrics
@@ -605,7 +605,7 @@ func TestSandboxAttachDevicesVFIO(t *testing.T) {
 		config.SysIOMMUGroupPath = savedIOMMUPath
 	}()
 
-	dm := manager.NewDeviceManager(config.VirtioSCSI, false, "", 0, nil)
+	dm := manager.NewDeviceManager(config.VirtioSCSI, false, "", 0, nil, nil, nil)
 	path := filepath.Join(vfioPath, testFDIOGroup)
 	deviceInfo := config.DeviceInfo{
 		HostPath:      path,
@@ -1154,7 +1154,7 @@ func TestAttachBlockDevice(t *testing.T) {
 		DevType:       "b",
 	}
 
-	dm := manager.NewDeviceManager(config.VirtioBlock, false, "", 0, nil)
+	dm := manager.NewDeviceManager(config.VirtioBlock, false, "", 0, nil, nil, nil)
 	device, err := dm.NewDevice(deviceInfo)
 	assert.Nil(t, err)
 	_, ok := device.(*drivers.BlockDevice)
@@ -1199,6 +1199,43 @@ func TestAttachBlockDevice(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestSandboxResizeVolumeDevice(t *testing.T) {
+	assert := assert.New(t)
+
+	dm := manager.NewDeviceManager(config.VirtioBlock, false, "", 0, nil, nil, nil)
+	sandbox := &Sandbox{
+		id:         testSandboxID,
+		hypervisor: &mockHypervisor{},
+		devManager: dm,
+		config: &SandboxConfig{
+			HypervisorConfig: HypervisorConfig{BlockDeviceDriver: config.VirtioBlock},
+		},
+		ctx:   context.Background(),
+		state: types.SandboxState{BlockIndexMap: make(map[int]struct{})},
+	}
+
+	// The volume has no block device
+	err := sandbox.resizeVolumeDevice(context.Background(), "", 1<<30)
+	assert.Error(err)
+
+	err = sandbox.resizeVolumeDevice(context.Background(), "unknown", 1<<30)
+	assert.Error(err)
+
+	device, err := dm.NewDevice(config.DeviceInfo{
+		HostPath:      "/dev/hda",
+		ContainerPath: "/dev/hda",
+		DevType:       "b",
+	})
+	assert.NoError(err)
+
+	err = device.Attach(context.Background(), sandbox)
+	assert.NoError(err)
+	defer device.Detach(context.Background(), sandbox)
+
+	err = sandbox.resizeVolumeDevice(context.Background(), device.DeviceID(), 1<<30)
+	assert.NoError(err)
+}
+
 func TestPreAddDevice(t *testing.T) {
 	hypervisor := &mockHypervisor{}
 
@@ -1210,7 +1247,7 @@ func TestPreAddDevice(t *testing.T) {
 		HypervisorConfig: hConfig,
 	}
 
-	dm := manager.NewDeviceManager(config.VirtioBlock, false, "", 0, nil)
+	dm := manager.NewDeviceManager(config.VirtioBlock, false, "", 0, nil, nil, nil)
 	// create a sandbox first
 	sandbox := &Sandbox{
 		id:         testSandboxID,
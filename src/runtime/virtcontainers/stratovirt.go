@@ -553,7 +553,7 @@ func (s *stratovirt) appendNetwork(ctx context.Context, devices []VirtioDev, end
 }
 
 func (s *stratovirt) appendVirtioFs(ctx context.Context, devices []VirtioDev, volume types.Volume) []VirtioDev {
-	if s.config.SharedFS != config.VirtioFS && s.config.SharedFS != config.VirtioFSNydus {
+	if !IsVirtioFS(s.config.SharedFS) {
 		return devices
 	}
 	name := "virtio_fs"
@@ -774,7 +774,7 @@ func (s *stratovirt) createParams(params *[]string) {
 	*params = append(*params, "-D")
 	*params = append(*params, "-disable-seccomp")
 
-	if s.config.SharedFS == config.VirtioFS || s.config.SharedFS == config.VirtioFSNydus {
+	if IsVirtioFS(s.config.SharedFS) {
 		*params = append(*params, "-machine", fmt.Sprintf("type=%s,dump-guest-core=off,mem-share=on", s.svConfig.machineType))
 	} else {
 		*params = append(*params, "-machine", fmt.Sprintf("type=%s,dump-guest-core=off", s.svConfig.machineType))
@@ -929,32 +929,18 @@ func (s *stratovirt) createVirtiofsDaemon(sharedPath string) (VirtiofsDaemon, er
 		return nil, err
 	}
 
-	if s.config.SharedFS == config.VirtioFSNydus {
-		apiSockPath, err := s.nydusdSocketPath(s.id)
-		if err != nil {
-			return nil, err
-		}
-		nd := &nydusd{
-			path:        s.config.VirtioFSDaemon,
-			sockPath:    virtiofsdSocketPath,
-			apiSockPath: apiSockPath,
-			sourcePath:  sharedPath,
-			debug:       s.config.Debug,
-			extraArgs:   s.config.VirtioFSExtraArgs,
-			startFn:     startInShimNS,
-		}
-		nd.setupShareDirFn = nd.setupPassthroughFS
-		return nd, nil
+	apiSockPath, err := s.nydusdSocketPath(s.id)
+	if err != nil {
+		return nil, err
 	}
 
-	// default use virtiofsd
-	return &virtiofsd{
-		path:       s.config.VirtioFSDaemon,
-		sourcePath: sharedPath,
-		socketPath: virtiofsdSocketPath,
-		extraArgs:  s.config.VirtioFSExtraArgs,
-		cache:      s.config.VirtioFSCache,
-	}, nil
+	return newVirtiofsDaemon(VirtiofsDaemonParams{
+		Config:        &s.config,
+		SandboxID:     s.id,
+		SocketPath:    virtiofsdSocketPath,
+		APISocketPath: apiSockPath,
+		SharedPath:    sharedPath,
+	})
 }
 
 func (s *stratovirt) CreateVM(ctx context.Context, id string, network Network, hypervisorConfig *HypervisorConfig) error {
@@ -1098,7 +1084,7 @@ func (s *stratovirt) StopVM(ctx context.Context, waitOnly bool) (err error) {
 		}
 	}
 
-	if s.config.SharedFS == config.VirtioFS || s.config.SharedFS == config.VirtioFSNydus {
+	if IsVirtioFS(s.config.SharedFS) {
 		if err := s.stopVirtiofsDaemon(ctx); err != nil {
 			return err
 		}
@@ -1167,6 +1153,10 @@ func (s *stratovirt) HotplugRemoveDevice(ctx context.Context, devInfo interface{
 	}
 }
 
+func (s *stratovirt) IsDevicePlugged(ctx context.Context, devInfo interface{}, devType DeviceType) (bool, error) {
+	return false, fmt.Errorf("Query device: unsupported device type '%v'", devType)
+}
+
 func (s *stratovirt) ResizeMemory(ctx context.Context, reqMemMB uint32, memoryBlockSizeMB uint32, probe bool) (uint32, MemoryDevice, error) {
 	return 0, MemoryDevice{}, nil
 }
@@ -1309,3 +1299,11 @@ func (s *stratovirt) GenerateSocket(id string) (interface{}, error) {
 func (s *stratovirt) IsRateLimiterBuiltin() bool {
 	return false
 }
+
+func (s *stratovirt) UpdateNetRateLimiter(ctx context.Context, endpoint Endpoint, bandwidth NetworkBandwidth) error {
+	return fmt.Errorf("StratoVirt does not support built-in network rate limiters")
+}
+
+func (s *stratovirt) ResizeBlockDevice(ctx context.Context, drive *config.BlockDrive, size uint64) error {
+	return fmt.Errorf("StratoVirt does not support resizing block devices")
+}
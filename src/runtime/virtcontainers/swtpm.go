@@ -0,0 +1,351 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/katautils/katatrace"
+	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// swtpmTracingTags defines tags for the trace span
+var swtpmTracingTags = map[string]string{
+	"source":    "runtime",
+	"package":   "virtcontainers",
+	"subsystem": "swtpm",
+}
+
+var (
+	errSwtpmPathEmpty       = errors.New("swtpm path is empty")
+	errSwtpmSocketPathEmpty = errors.New("swtpm socket path is empty")
+	errSwtpmStatePathEmpty  = errors.New("swtpm state path is empty")
+)
+
+const (
+	// swtpmSocket is the name of the swtpm control socket, in the VM
+	// store directory.
+	swtpmSocket = "swtpm.sock"
+
+	// swtpmStateDirMode is the mode of the directory the TPM state is
+	// kept in, it holds the TPM secrets.
+	swtpmStateDirMode = os.FileMode(0700)
+
+	// swtpmStateLock is the file, in the TPM state directory, locked as
+	// long as swtpm uses the state, and whose modification time is the
+	// last time it was used.
+	swtpmStateLock = "kata.lock"
+)
+
+// swtpmStateRetention is how long the TPM state of a pod is kept once no
+// sandbox uses it anymore, for the pod sandbox to be recreated.
+var swtpmStateRetention = 7 * 24 * time.Hour
+
+// swtpm is the TPM 2.0 emulator backing the vTPM of a sandbox. The TPM state
+// is kept on the host, outside of the VM store, under the TPM state identity
+// of the sandbox, so that the keys sealed by the guest survive the sandbox
+// when the identity outlives it, e.g. a pod whose sandbox is recreated.
+// swtpm exits with the VM, and the VM is stopped if swtpm quits.
+type swtpm struct {
+	// path to the swtpm binary
+	path string
+	// socketPath is the control socket the hypervisor connects to
+	socketPath string
+	// statePath is the directory the TPM state is persisted in
+	statePath string
+	// PID process ID of swtpm process
+	PID int
+}
+
+// newSwtpm returns the swtpm backing the vTPM of the sandbox id, pid is the
+// one of the swtpm already running for the sandbox, if any.
+func newSwtpm(config *HypervisorConfig, id string, pid int) (*swtpm, error) {
+	socketPath, err := utils.BuildSocketPath(config.VMStorePath, id, swtpmSocket)
+	if err != nil {
+		return nil, err
+	}
+
+	return &swtpm{
+		path:       config.SwtpmPath,
+		socketPath: socketPath,
+		statePath:  swtpmStatePath(config, id),
+		PID:        pid,
+	}, nil
+}
+
+// swtpmStatePath returns the directory the TPM state of the sandbox id is
+// persisted in.
+func swtpmStatePath(config *HypervisorConfig, id string) string {
+	if config.TPMStateID != "" {
+		return filepath.Join(config.TPMStatePath, config.TPMStateID)
+	}
+	return filepath.Join(config.TPMStatePath, id)
+}
+
+// getSocketFD opens the control socket on behalf of swtpm, so that it is
+// ready for the hypervisor as soon as swtpm is started.
+func (s *swtpm) getSocketFD() (*os.File, error) {
+	if _, err := os.Stat(filepath.Dir(s.socketPath)); err != nil {
+		return nil, errors.Errorf("Socket directory does not exist %s", filepath.Dir(s.socketPath))
+	}
+
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: s.socketPath, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+
+	// The hypervisor may run as non-root while swtpm runs as root.
+	if err := utils.ChownToParent(s.socketPath); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	// no longer needed since fd is a dup
+	defer listener.Close()
+
+	listener.SetUnlinkOnClose(false)
+
+	return listener.File()
+}
+
+// Start the swtpm process, return its pid.
+func (s *swtpm) Start(ctx context.Context, onQuit onQuitFunc) (int, error) {
+	span, _ := katatrace.Trace(ctx, s.Logger(), "Start", swtpmTracingTags)
+	defer span.End()
+
+	if err := s.valid(); err != nil {
+		return 0, err
+	}
+
+	if err := os.MkdirAll(s.statePath, swtpmStateDirMode); err != nil {
+		return 0, fmt.Errorf("failed to create swtpm state directory %s: %w", s.statePath, err)
+	}
+
+	cmd := exec.Command(s.path)
+
+	socketFD, err := s.getSocketFD()
+	if err != nil {
+		return 0, err
+	}
+	defer socketFD.Close()
+
+	cmd.ExtraFiles = append(cmd.ExtraFiles, socketFD)
+
+	// Extra FDs for swtpm start from 3
+	socketFdNumber := 2 + uint(len(cmd.ExtraFiles))
+	args := s.args(socketFdNumber)
+	cmd.Args = append(cmd.Args, args...)
+
+	// swtpm inherits the lock of the state, which is then held as long as
+	// it runs, whatever happens to the runtime.
+	lock, err := s.lockState()
+	if err != nil {
+		return 0, err
+	}
+	defer lock.Close()
+
+	cmd.ExtraFiles = append(cmd.ExtraFiles, lock)
+
+	s.Logger().WithField("path", s.path).WithField("args", strings.Join(args, " ")).Info()
+
+	if err = utils.StartCmd(cmd); err != nil {
+		return 0, err
+	}
+
+	go func() {
+		cmd.Process.Wait()
+		s.Logger().Info("swtpm quits")
+		if onQuit != nil {
+			onQuit()
+		}
+	}()
+
+	s.PID = cmd.Process.Pid
+
+	return cmd.Process.Pid, nil
+}
+
+// Stop the swtpm process. The TPM state is kept.
+func (s *swtpm) Stop(ctx context.Context) error {
+	span, _ := katatrace.Trace(ctx, s.Logger(), "Stop", swtpmTracingTags)
+	defer span.End()
+
+	if s.PID == 0 {
+		s.Logger().WithField("invalid-swtpm-pid", s.PID).Warn("cannot kill swtpm")
+		return nil
+	}
+
+	// The PID may have been persisted by a previous runtime, and recycled
+	// since swtpm quit.
+	if utils.ProcessHasArgs(s.PID, "--tpmstate", s.tpmStateArg()) {
+		if err := syscall.Kill(s.PID, syscall.SIGKILL); err != nil && err != syscall.ESRCH {
+			s.Logger().WithError(err).WithField("pid", s.PID).Warn("kill swtpm failed")
+			return nil
+		}
+	} else {
+		s.Logger().WithField("pid", s.PID).Info("swtpm is not running anymore")
+	}
+	s.PID = 0
+
+	// Record when the state was last used
+	now := time.Now()
+	if err := os.Chtimes(filepath.Join(s.statePath, swtpmStateLock), now, now); err != nil && !os.IsNotExist(err) {
+		s.Logger().WithError(err).WithField("path", s.statePath).Warn("updating the TPM state use time failed")
+	}
+
+	if err := os.Remove(s.socketPath); err != nil && !os.IsNotExist(err) {
+		s.Logger().WithError(err).WithField("path", s.socketPath).Warn("removing swtpm socket failed")
+	}
+	return nil
+}
+
+func (s *swtpm) args(FdSocketNumber uint) []string {
+	return []string{
+		"socket",
+		"--tpm2",
+		// TPM state, kept under the TPM state identity
+		"--tpmstate", s.tpmStateArg(),
+		// control channel the hypervisor connects to
+		"--ctrl", fmt.Sprintf("type=unixio,fd=%d", FdSocketNumber),
+		// exit once the hypervisor disconnects
+		"--terminate",
+	}
+}
+
+func (s *swtpm) tpmStateArg() string {
+	return "dir=" + s.statePath + ",mode=0600"
+}
+
+// lockState returns the lock of the TPM state, shared so that the sandboxes
+// of a pod recreated in a row do not fail on each other.
+func (s *swtpm) lockState() (*os.File, error) {
+	lockPath := filepath.Join(s.statePath, swtpmStateLock)
+
+	for {
+		if err := os.MkdirAll(s.statePath, swtpmStateDirMode); err != nil {
+			return nil, err
+		}
+
+		lock, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0600)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_SH); err != nil {
+			lock.Close()
+			return nil, fmt.Errorf("failed to lock the TPM state %s: %w", s.statePath, err)
+		}
+
+		// The state may have been removed as stale while locking it
+		locked, err := lock.Stat()
+		if err != nil {
+			lock.Close()
+			return nil, err
+		}
+		if current, err := os.Stat(lockPath); err != nil || !os.SameFile(locked, current) {
+			lock.Close()
+			continue
+		}
+
+		now := time.Now()
+		if err := os.Chtimes(lockPath, now, now); err != nil {
+			lock.Close()
+			return nil, err
+		}
+
+		return lock, nil
+	}
+}
+
+// removeStaleSwtpmStates removes the TPM states under statePath which no
+// swtpm uses, and which were last used more than retention ago, e.g. the
+// states of the deleted pods.
+func removeStaleSwtpmStates(statePath string, retention time.Duration) error {
+	entries, err := os.ReadDir(statePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		dir := filepath.Join(statePath, entry.Name())
+		if err := removeStaleSwtpmState(dir, retention); err != nil {
+			hvLogger.WithField("subsystem", "swtpm").WithError(err).WithField("path", dir).Warn("failed to remove the TPM state")
+		}
+	}
+
+	return nil
+}
+
+func removeStaleSwtpmState(dir string, retention time.Duration) error {
+	lock, err := os.OpenFile(filepath.Join(dir, swtpmStateLock), os.O_RDONLY, 0)
+	if os.IsNotExist(err) {
+		// The state is being created, or was never used
+		fi, err := os.Stat(dir)
+		if err != nil || time.Since(fi.ModTime()) < retention {
+			return err
+		}
+		return os.RemoveAll(dir)
+	}
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		if err == syscall.EWOULDBLOCK {
+			// swtpm uses the state
+			return nil
+		}
+		return err
+	}
+
+	fi, err := lock.Stat()
+	if err != nil {
+		return err
+	}
+	if time.Since(fi.ModTime()) < retention {
+		return nil
+	}
+
+	return os.RemoveAll(dir)
+}
+
+func (s *swtpm) valid() error {
+	if s.path == "" {
+		return errSwtpmPathEmpty
+	}
+
+	if s.socketPath == "" {
+		return errSwtpmSocketPathEmpty
+	}
+
+	if s.statePath == "" {
+		return errSwtpmStatePathEmpty
+	}
+
+	return nil
+}
+
+func (s *swtpm) Logger() *log.Entry {
+	return hvLogger.WithField("subsystem", "swtpm")
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSwtpmStart(t *testing.T) {
+	assert := assert.New(t)
+
+	stateDir := t.TempDir()
+	socketDir := t.TempDir()
+
+	validConfig := swtpm{
+		path:       "/usr/bin/swtpm-path",
+		socketPath: filepath.Join(socketDir, swtpmSocket),
+		statePath:  filepath.Join(stateDir, "sandbox"),
+	}
+	noSocketDir := validConfig
+	noSocketDir.socketPath = "/tmp/path/to/swtpm/swtpm.sock"
+
+	// nolint: govet
+	tests := []struct {
+		name    string
+		swtpm   swtpm
+		wantErr bool
+	}{
+		{"empty config", swtpm{}, true},
+		{"socket directory does not exist", noSocketDir, true},
+		{"valid config", validConfig, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.swtpm
+			_, err := s.Start(context.Background(), nil)
+			if tt.wantErr {
+				assert.Error(err)
+				return
+			}
+			assert.NoError(err)
+			assert.NotZero(s.PID)
+
+			// The state directory only is accessible to swtpm
+			fi, err := os.Stat(s.statePath)
+			assert.NoError(err)
+			assert.Equal(swtpmStateDirMode, fi.Mode().Perm())
+			assert.FileExists(s.socketPath)
+		})
+	}
+}
+
+func TestSwtpmArgs(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &swtpm{
+		path:      "/usr/bin/swtpm",
+		statePath: "/var/lib/kata-containers/swtpm/sandbox",
+	}
+
+	expected := "socket --tpm2 --tpmstate dir=/var/lib/kata-containers/swtpm/sandbox,mode=0600 --ctrl type=unixio,fd=3 --terminate"
+	assert.Equal(expected, strings.Join(s.args(3), " "))
+}
+
+func TestSwtpmStop(t *testing.T) {
+	assert := assert.New(t)
+
+	socketPath := filepath.Join(t.TempDir(), swtpmSocket)
+	assert.NoError(os.WriteFile(socketPath, nil, 0600))
+
+	// Not started
+	s := &swtpm{socketPath: socketPath}
+	assert.NoError(s.Stop(context.Background()))
+	assert.FileExists(socketPath)
+
+	// Already gone
+	s.PID = 999999999
+	assert.NoError(s.Stop(context.Background()))
+	assert.Zero(s.PID)
+	assert.NoFileExists(socketPath)
+
+	// The PID was recycled by another process, which is left alone
+	cmd := exec.Command("sleep", "60")
+	assert.NoError(cmd.Start())
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	s.PID = cmd.Process.Pid
+	assert.NoError(s.Stop(context.Background()))
+	assert.Zero(s.PID)
+	assert.NoError(cmd.Process.Signal(syscall.Signal(0)))
+}
+
+func TestRemoveStaleSwtpmStates(t *testing.T) {
+	assert := assert.New(t)
+
+	statePath := t.TempDir()
+	old := time.Now().Add(-2 * time.Hour)
+
+	// A state in use, a recently used one, a stale one and a never used one
+	var states []*swtpm
+	for _, id := range []string{"used", "recent", "stale", "unused"} {
+		s := &swtpm{statePath: filepath.Join(statePath, id)}
+		states = append(states, s)
+		if id == "unused" {
+			assert.NoError(os.MkdirAll(s.statePath, swtpmStateDirMode))
+			assert.NoError(os.Chtimes(s.statePath, old, old))
+			continue
+		}
+
+		lock, err := s.lockState()
+		assert.NoError(err)
+		if id == "used" {
+			assert.NoError(os.Chtimes(lock.Name(), old, old))
+			defer lock.Close()
+			continue
+		}
+		lock.Close()
+		if id == "stale" {
+			assert.NoError(os.Chtimes(lock.Name(), old, old))
+		}
+	}
+
+	assert.NoError(removeStaleSwtpmStates(statePath, time.Hour))
+	assert.DirExists(states[0].statePath)
+	assert.DirExists(states[1].statePath)
+	assert.NoDirExists(states[2].statePath)
+	assert.NoDirExists(states[3].statePath)
+
+	// The state removed while being locked is created again
+	lock, err := states[2].lockState()
+	assert.NoError(err)
+	lock.Close()
+	assert.FileExists(filepath.Join(states[2].statePath, swtpmStateLock))
+
+	assert.NoError(removeStaleSwtpmStates(filepath.Join(statePath, "missing"), time.Hour))
+}
+
+func TestNewSwtpm(t *testing.T) {
+	assert := assert.New(t)
+
+	config := &HypervisorConfig{
+		SwtpmPath:    "/usr/bin/swtpm",
+		TPMStatePath: "/var/lib/kata-containers/swtpm",
+		VMStorePath:  "/run/vc/vm",
+	}
+
+	s, err := newSwtpm(config, "sandbox", 1234)
+	assert.NoError(err)
+	assert.Equal(&swtpm{
+		path:       "/usr/bin/swtpm",
+		socketPath: "/run/vc/vm/sandbox/swtpm.sock",
+		statePath:  "/var/lib/kata-containers/swtpm/sandbox",
+		PID:        1234,
+	}, s)
+
+	// The state is kept under the TPM state identity when set
+	config.TPMStateID = "pod-uid"
+	s, err = newSwtpm(config, "sandbox", 0)
+	assert.NoError(err)
+	assert.Equal("/var/lib/kata-containers/swtpm/pod-uid", s.statePath)
+	config.TPMStateID = ""
+
+	// The socket path must fit in sun_path
+	_, err = newSwtpm(config, strings.Repeat("x", 128), 0)
+	assert.Error(err)
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+)
+
+const (
+	// NetworkPolicyProtocolTCP restricts a rule to TCP traffic.
+	NetworkPolicyProtocolTCP = "tcp"
+
+	// NetworkPolicyProtocolUDP restricts a rule to UDP traffic.
+	NetworkPolicyProtocolUDP = "udp"
+)
+
+// NetworkPolicy describes the traffic a sandbox is allowed to send out of
+// its network endpoints. It is enforced on the host side of each endpoint,
+// so a compromised guest kernel cannot bypass it.
+//
+// An empty Egress list denies all IP traffic. ARP and the IPv6 neighbor
+// discovery are always allowed so that the guest can still resolve its
+// gateway.
+type NetworkPolicy struct {
+	Egress []NetworkPolicyRule `json:"egress"`
+}
+
+// NetworkPolicyRule allows the traffic matching a destination CIDR and,
+// optionally, a transport protocol and a list of destination ports.
+type NetworkPolicyRule struct {
+	CIDR     string   `json:"cidr"`
+	Protocol string   `json:"protocol,omitempty"`
+	Ports    []uint16 `json:"ports,omitempty"`
+}
+
+// ParseNetworkPolicy decodes and validates a JSON encoded network policy.
+func ParseNetworkPolicy(data []byte) (*NetworkPolicy, error) {
+	var policy NetworkPolicy
+
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("invalid network policy: %v", err)
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// LoadNetworkPolicy reads and validates the network policy stored at path.
+func LoadNetworkPolicy(path string) (*NetworkPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseNetworkPolicy(data)
+}
+
+// Validate checks that every rule of the policy can be enforced.
+func (p *NetworkPolicy) Validate() error {
+	for i, rule := range p.Egress {
+		if _, _, err := net.ParseCIDR(rule.CIDR); err != nil {
+			return fmt.Errorf("network policy rule %d: invalid CIDR %q", i, rule.CIDR)
+		}
+
+		switch rule.Protocol {
+		case "":
+			if len(rule.Ports) > 0 {
+				return fmt.Errorf("network policy rule %d: ports require a protocol", i)
+			}
+		case NetworkPolicyProtocolTCP, NetworkPolicyProtocolUDP:
+		default:
+			return fmt.Errorf("network policy rule %d: unsupported protocol %q", i, rule.Protocol)
+		}
+
+		for _, port := range rule.Ports {
+			if port == 0 {
+				return fmt.Errorf("network policy rule %d: invalid port 0", i)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNetworkPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	policy, err := ParseNetworkPolicy([]byte(`{"egress": [
+		{"cidr": "10.0.0.0/8"},
+		{"cidr": "192.168.1.1/32", "protocol": "tcp", "ports": [80, 443]},
+		{"cidr": "fd00::/64", "protocol": "udp", "ports": [53]}
+	]}`))
+	assert.NoError(err)
+	assert.Len(policy.Egress, 3)
+	assert.Equal([]uint16{80, 443}, policy.Egress[1].Ports)
+
+	policy, err = ParseNetworkPolicy([]byte(`{"egress": []}`))
+	assert.NoError(err)
+	assert.Empty(policy.Egress)
+
+	_, err = ParseNetworkPolicy([]byte(`not json`))
+	assert.Error(err)
+}
+
+func TestNetworkPolicyValidation(t *testing.T) {
+	assert := assert.New(t)
+
+	invalid := []NetworkPolicyRule{
+		{CIDR: "10.0.0.1"},
+		{CIDR: "10.0.0.0/8", Ports: []uint16{80}},
+		{CIDR: "10.0.0.0/8", Protocol: "icmp"},
+		{CIDR: "10.0.0.0/8", Protocol: "tcp", Ports: []uint16{0}},
+	}
+
+	for _, rule := range invalid {
+		policy := NetworkPolicy{Egress: []NetworkPolicyRule{rule}}
+		assert.Error(policy.Validate(), "rule %+v", rule)
+	}
+}
+
+func TestLoadNetworkPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "policy.json")
+	_, err := LoadNetworkPolicy(path)
+	assert.Error(err)
+
+	err = os.WriteFile(path, []byte(`{"egress": [{"cidr": "0.0.0.0/0", "protocol": "udp", "ports": [53]}]}`), 0600)
+	assert.NoError(err)
+
+	policy, err := LoadNetworkPolicy(path)
+	assert.NoError(err)
+	assert.Len(policy.Egress, 1)
+}
@@ -12,6 +12,7 @@ import (
 	"io"
 	"math/big"
 	"os"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
@@ -143,6 +144,29 @@ func GetDevicePathAndFsTypeOptions(mountPoint string) (devicePath, fsType string
 	}
 }
 
+// ProcessHasArgs returns whether the process runs with the consecutive
+// arguments. It guards the signals sent to a persisted PID, which may have
+// been recycled by another process since, e.g. after a shim restart.
+func ProcessHasArgs(pid int, args ...string) bool {
+	if pid <= 0 {
+		return false
+	}
+
+	cmdline, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err != nil {
+		return false
+	}
+
+	procArgs := strings.Split(strings.TrimSuffix(string(cmdline), "\x00"), "\x00")
+	for i := 0; i+len(args) <= len(procArgs); i++ {
+		if slices.Equal(procArgs[i:i+len(args)], args) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func waitProcessUsingPidfd(pid int, timeoutSecs uint, logger *logrus.Entry) (bool, error) {
 	pidfd, err := unix.PidfdOpen(pid, 0)
 
@@ -63,3 +63,21 @@ func TestGetDevicePathAndFsTypeOptionsSuccessful(t *testing.T) {
 	assert.Equal(fstype, fstypeOut)
 	assert.Equal(fsOptions, optsOut)
 }
+
+func TestProcessHasArgs(t *testing.T) {
+	assert := assert.New(t)
+
+	cmd := exec.Command("sleep", "--", "60")
+	assert.NoError(cmd.Start())
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	pid := cmd.Process.Pid
+	assert.True(ProcessHasArgs(pid, "--", "60"))
+	assert.True(ProcessHasArgs(pid, "sleep"))
+	assert.False(ProcessHasArgs(pid, "--", "6"))
+	assert.False(ProcessHasArgs(pid, "60", "--"))
+	assert.False(ProcessHasArgs(0, "sleep"))
+}
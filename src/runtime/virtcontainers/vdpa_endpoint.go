@@ -0,0 +1,339 @@
+//go:build linux
+
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	persistapi "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/persist/api"
+	vcTypes "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/types"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// The vdpa generic netlink family is only available from the initial
+	// network namespace.
+	hostNetNSPath = "/proc/1/ns/net"
+
+	vhostVdpaPrefix = "vhost-vdpa-"
+)
+
+var (
+	vdpaTrace = getNetworkTrace(VdpaEndpointType)
+
+	// vdpaSysfsDevicesPath is where the vdpa devices are listed, each
+	// device directory holding the vhost-vdpa character device the
+	// device is bound to, if any.
+	vdpaSysfsDevicesPath = "/sys/bus/vdpa/devices"
+
+	vdpaHandle vdpaNetlinkHandle = hostVdpaNetlinkHandle{}
+)
+
+// vdpaNetlinkHandle lists the vdpa devices of the host.
+type vdpaNetlinkHandle interface {
+	VDPAGetDevConfigList() ([]*netlink.VDPADevConfig, error)
+}
+
+type hostVdpaNetlinkHandle struct{}
+
+// VDPAGetDevConfigList lists the vdpa devices from the initial network
+// namespace, as the endpoints are created from the sandbox one.
+func (hostVdpaNetlinkHandle) VDPAGetDevConfigList() ([]*netlink.VDPADevConfig, error) {
+	hostNS, err := netns.GetFromPath(hostNetNSPath)
+	if err != nil {
+		return nil, err
+	}
+	defer hostNS.Close()
+
+	h, err := netlink.NewHandleAt(hostNS, unix.NETLINK_GENERIC)
+	if err != nil {
+		return nil, err
+	}
+	defer h.Close()
+
+	return h.VDPAGetDevConfigList()
+}
+
+// VdpaEndpoint represents a network interface backed by a vdpa device bound
+// to the vhost-vdpa driver. The guest gets a virtio-net device whose datapath
+// is offloaded to the hardware, without the whole VF being passed through.
+type VdpaEndpoint struct {
+	// Name of the vdpa device, e.g. vdpa0
+	VdpaDevName string
+	// Path to the vhost-vdpa character device, e.g. /dev/vhost-vdpa-0
+	VhostVdpaPath string
+	// Number of virtqueues of the device
+	NumQueues uint32
+	// MAC address of the interface
+	HardAddr           string
+	IfaceName          string
+	EndpointProperties NetworkInfo
+	EndpointType       EndpointType
+	PCIPath            vcTypes.PciPath
+	CCWDevice          *vcTypes.CcwDevice
+}
+
+// Properties returns the properties of the interface.
+func (endpoint *VdpaEndpoint) Properties() NetworkInfo {
+	return endpoint.EndpointProperties
+}
+
+// Name returns name of the interface.
+func (endpoint *VdpaEndpoint) Name() string {
+	return endpoint.IfaceName
+}
+
+// HardwareAddr returns the mac address of the vdpa network interface
+func (endpoint *VdpaEndpoint) HardwareAddr() string {
+	return endpoint.HardAddr
+}
+
+// Type indentifies the endpoint as a vdpa endpoint.
+func (endpoint *VdpaEndpoint) Type() EndpointType {
+	return endpoint.EndpointType
+}
+
+// SetProperties sets the properties of the endpoint.
+func (endpoint *VdpaEndpoint) SetProperties(properties NetworkInfo) {
+	endpoint.EndpointProperties = properties
+}
+
+// PciPath returns the PCI path of the endpoint.
+func (endpoint *VdpaEndpoint) PciPath() vcTypes.PciPath {
+	return endpoint.PCIPath
+}
+
+// SetPciPath sets the PCI path of the endpoint.
+func (endpoint *VdpaEndpoint) SetPciPath(pciPath vcTypes.PciPath) {
+	endpoint.PCIPath = pciPath
+}
+
+// CcwDevice returns the CCW device of the endpoint.
+func (endpoint *VdpaEndpoint) CcwDevice() *vcTypes.CcwDevice {
+	return endpoint.CCWDevice
+}
+
+// SetCcwDevice sets the CCW device of the endpoint.
+func (endpoint *VdpaEndpoint) SetCcwDevice(ccwDev vcTypes.CcwDevice) {
+	endpoint.CCWDevice = &ccwDev
+}
+
+// NetworkPair returns the network pair of the endpoint.
+func (endpoint *VdpaEndpoint) NetworkPair() *NetworkInterfacePair {
+	return nil
+}
+
+// checkHypervisor checks that the sandbox hypervisor can handle vdpa devices.
+func (endpoint *VdpaEndpoint) checkHypervisor(s *Sandbox) error {
+	switch s.config.HypervisorType {
+	case QemuHypervisor, ClhHypervisor:
+		return nil
+	default:
+		return fmt.Errorf("VdpaEndpoint is not supported by hypervisor %s", s.config.HypervisorType)
+	}
+}
+
+// allowVhostVdpaDevice gives the hypervisor access to the vhost-vdpa device.
+func (endpoint *VdpaEndpoint) allowVhostVdpaDevice(s *Sandbox) {
+	if s.sandboxController == nil {
+		return
+	}
+
+	if err := s.sandboxController.AddDevice(endpoint.VhostVdpaPath); err != nil {
+		networkLogger().WithError(err).WithField("device", endpoint.VhostVdpaPath).
+			Warnf("Could not add device to the %s controller", s.sandboxController)
+	}
+}
+
+// Attach for vdpa endpoint passes the vhost-vdpa device to the hypervisor
+func (endpoint *VdpaEndpoint) Attach(ctx context.Context, s *Sandbox) error {
+	span, ctx := vdpaTrace(ctx, "Attach", endpoint)
+	defer span.End()
+
+	if err := endpoint.checkHypervisor(s); err != nil {
+		return err
+	}
+
+	endpoint.allowVhostVdpaDevice(s)
+
+	return s.hypervisor.AddDevice(ctx, endpoint, NetDev)
+}
+
+// Detach for vdpa endpoint is a no-op, the vdpa device is left untouched
+func (endpoint *VdpaEndpoint) Detach(ctx context.Context, netNsCreated bool, netNsPath string) error {
+	return nil
+}
+
+// HotAttach for vdpa endpoint hot plugs the vhost-vdpa device
+func (endpoint *VdpaEndpoint) HotAttach(ctx context.Context, s *Sandbox) error {
+	span, ctx := vdpaTrace(ctx, "HotAttach", endpoint)
+	defer span.End()
+
+	if err := endpoint.checkHypervisor(s); err != nil {
+		return err
+	}
+
+	endpoint.allowVhostVdpaDevice(s)
+
+	if _, err := s.hypervisor.HotplugAddDevice(ctx, endpoint, NetDev); err != nil {
+		networkLogger().WithError(err).Error("Error attach vdpa ep")
+		return err
+	}
+	return nil
+}
+
+// HotDetach for vdpa endpoint hot unplugs the vhost-vdpa device
+func (endpoint *VdpaEndpoint) HotDetach(ctx context.Context, s *Sandbox, netNsCreated bool, netNsPath string) error {
+	span, ctx := vdpaTrace(ctx, "HotDetach", endpoint)
+	defer span.End()
+
+	if _, err := s.hypervisor.HotplugRemoveDevice(ctx, endpoint, NetDev); err != nil {
+		networkLogger().WithError(err).Error("Error detach vdpa ep")
+		return err
+	}
+
+	if s.sandboxController != nil {
+		if err := s.sandboxController.RemoveDevice(endpoint.VhostVdpaPath); err != nil {
+			networkLogger().WithError(err).WithField("device", endpoint.VhostVdpaPath).
+				Warnf("Could not remove device from the %s controller", s.sandboxController)
+		}
+	}
+	return nil
+}
+
+// createVdpaEndpoint creates a vdpa endpoint
+func createVdpaEndpoint(netInfo NetworkInfo, vdpaDev *netlink.VDPADevConfig) (*VdpaEndpoint, error) {
+	vhostVdpaPath, err := vhostVdpaDevicePath(vdpaDev.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// A receive and a transmit queue per queue pair
+	queuePairs := uint32(vdpaDev.Net.Cfg.MaxVQP)
+	if queuePairs == 0 {
+		queuePairs = 1
+	}
+
+	return &VdpaEndpoint{
+		VdpaDevName:   vdpaDev.Name,
+		VhostVdpaPath: vhostVdpaPath,
+		NumQueues:     2 * queuePairs,
+		HardAddr:      netInfo.Iface.HardwareAddr.String(),
+		IfaceName:     netInfo.Iface.Name,
+		EndpointType:  VdpaEndpointType,
+	}, nil
+}
+
+// findVdpaDevice returns the vdpa device backing a network interface, if any.
+// A vdpa device named after the interface backs it, otherwise the vdpa
+// devices are matched by the MAC address they have been created with. Only
+// the devices bound to the vhost_vdpa driver can back an interface, the other
+// ones are skipped: the netdev of a device bound to virtio_vdpa has the MAC
+// address of the device, and is a regular interface.
+func findVdpaDevice(netInfo NetworkInfo) (*netlink.VDPADevConfig, error) {
+	if netInfo.Iface.Name == "lo" {
+		return nil, nil
+	}
+
+	devices, err := vdpaHandle.VDPAGetDevConfigList()
+	if err != nil {
+		return nil, err
+	}
+
+	var usable []*netlink.VDPADevConfig
+	for _, dev := range devices {
+		if _, err := vhostVdpaDevicePath(dev.Name); err != nil {
+			networkLogger().WithError(err).WithField("vdpa-device", dev.Name).Debug("Skipping vdpa device")
+			continue
+		}
+		if dev.Name == netInfo.Iface.Name {
+			return dev, nil
+		}
+		usable = append(usable, dev)
+	}
+
+	if len(netInfo.Iface.HardwareAddr) == 0 {
+		return nil, nil
+	}
+
+	for _, dev := range usable {
+		if dev.Net.Cfg.MACAddr.String() == netInfo.Iface.HardwareAddr.String() {
+			return dev, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// vhostVdpaDevicePath returns the path to the vhost-vdpa character device of
+// a vdpa device.
+func vhostVdpaDevicePath(vdpaDevName string) (string, error) {
+	entries, err := os.ReadDir(filepath.Join(vdpaSysfsDevicesPath, vdpaDevName))
+	if err != nil {
+		return "", err
+	}
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), vhostVdpaPrefix) {
+			return filepath.Join("/dev", entry.Name()), nil
+		}
+	}
+
+	return "", fmt.Errorf("vdpa device %s is not bound to the vhost_vdpa driver", vdpaDevName)
+}
+
+func (endpoint *VdpaEndpoint) save() persistapi.NetworkEndpoint {
+	return persistapi.NetworkEndpoint{
+		Type: string(endpoint.Type()),
+		Vdpa: &persistapi.VdpaEndpoint{
+			IfaceName:     endpoint.IfaceName,
+			HardAddr:      endpoint.HardAddr,
+			VdpaDevName:   endpoint.VdpaDevName,
+			VhostVdpaPath: endpoint.VhostVdpaPath,
+			NumQueues:     endpoint.NumQueues,
+			PCIPath:       endpoint.PCIPath,
+		},
+	}
+}
+
+func (endpoint *VdpaEndpoint) load(s persistapi.NetworkEndpoint) {
+	endpoint.EndpointType = VdpaEndpointType
+
+	if s.Vdpa != nil {
+		endpoint.IfaceName = s.Vdpa.IfaceName
+		endpoint.HardAddr = s.Vdpa.HardAddr
+		endpoint.VdpaDevName = s.Vdpa.VdpaDevName
+		endpoint.VhostVdpaPath = s.Vdpa.VhostVdpaPath
+		endpoint.NumQueues = s.Vdpa.NumQueues
+		endpoint.PCIPath = s.Vdpa.PCIPath
+	}
+}
+
+// unsupported
+func (endpoint *VdpaEndpoint) GetRxRateLimiter() bool {
+	return false
+}
+
+func (endpoint *VdpaEndpoint) SetRxRateLimiter() error {
+	return fmt.Errorf("rx rate limiter is unsupported for vdpa endpoint")
+}
+
+// unsupported
+func (endpoint *VdpaEndpoint) GetTxRateLimiter() bool {
+	return false
+}
+
+func (endpoint *VdpaEndpoint) SetTxRateLimiter() error {
+	return fmt.Errorf("tx rate limiter is unsupported for vdpa endpoint")
+}
@@ -0,0 +1,232 @@
+//go:build linux
+
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	persistapi "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/persist/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/vishvananda/netlink"
+)
+
+type vdpaNetlinkFixture struct {
+	devices []*netlink.VDPADevConfig
+	err     error
+}
+
+func (f vdpaNetlinkFixture) VDPAGetDevConfigList() ([]*netlink.VDPADevConfig, error) {
+	return f.devices, f.err
+}
+
+func newVdpaDevConfig(name, mac string, maxVQP uint16) *netlink.VDPADevConfig {
+	hwAddr, _ := net.ParseMAC(mac)
+
+	dev := &netlink.VDPADevConfig{}
+	dev.Name = name
+	dev.Net.Cfg.MACAddr = hwAddr
+	dev.Net.Cfg.MaxVQP = maxVQP
+
+	return dev
+}
+
+// setupVdpaFixtures replaces the host vdpa devices with the given ones, the
+// devices with a vhost-vdpa index being bound to the vhost_vdpa driver.
+func setupVdpaFixtures(t *testing.T, devices []*netlink.VDPADevConfig, vhostVdpaIndexes map[string]int) {
+	sysfsPath := t.TempDir()
+	for _, dev := range devices {
+		devPath := filepath.Join(sysfsPath, dev.Name)
+		assert.NoError(t, os.MkdirAll(devPath, 0755))
+		if idx, ok := vhostVdpaIndexes[dev.Name]; ok {
+			assert.NoError(t, os.Mkdir(filepath.Join(devPath, fmt.Sprintf("vhost-vdpa-%d", idx)), 0755))
+		}
+	}
+
+	savedHandle, savedSysfsPath := vdpaHandle, vdpaSysfsDevicesPath
+	vdpaHandle = vdpaNetlinkFixture{devices: devices}
+	vdpaSysfsDevicesPath = sysfsPath
+
+	t.Cleanup(func() {
+		vdpaHandle, vdpaSysfsDevicesPath = savedHandle, savedSysfsPath
+	})
+}
+
+func TestFindVdpaDevice(t *testing.T) {
+	assert := assert.New(t)
+
+	// vdpa0 is bound to virtio_vdpa, the others to vhost_vdpa
+	setupVdpaFixtures(t, []*netlink.VDPADevConfig{
+		newVdpaDevConfig("vdpa0", "02:00:ca:fe:00:02", 1),
+		newVdpaDevConfig("vdpa1", "02:00:ca:fe:00:02", 4),
+		newVdpaDevConfig("vdpa2", "02:00:ca:fe:00:04", 1),
+		newVdpaDevConfig("vdpa3", "02:00:ca:fe:00:01", 1),
+	}, map[string]int{"vdpa1": 1, "vdpa2": 2})
+
+	netInfo := NetworkInfo{}
+	netInfo.Iface.Name = "eth0"
+	netInfo.Iface.HardwareAddr, _ = net.ParseMAC("02:00:ca:fe:00:02")
+
+	dev, err := findVdpaDevice(netInfo)
+	assert.NoError(err)
+	assert.NotNil(dev)
+	assert.Equal("vdpa1", dev.Name)
+
+	netInfo.Iface.HardwareAddr, _ = net.ParseMAC("02:00:ca:fe:00:03")
+	dev, err = findVdpaDevice(netInfo)
+	assert.NoError(err)
+	assert.Nil(dev)
+
+	// The devices not bound to vhost_vdpa are skipped
+	netInfo.Iface.HardwareAddr, _ = net.ParseMAC("02:00:ca:fe:00:01")
+	dev, err = findVdpaDevice(netInfo)
+	assert.NoError(err)
+	assert.Nil(dev)
+
+	// A device named after the interface backs it, whatever its MAC address
+	netInfo.Iface.Name = "vdpa2"
+	netInfo.Iface.HardwareAddr, _ = net.ParseMAC("02:00:ca:fe:00:02")
+	dev, err = findVdpaDevice(netInfo)
+	assert.NoError(err)
+	assert.NotNil(dev)
+	assert.Equal("vdpa2", dev.Name)
+
+	netInfo.Iface.Name = "vdpa3"
+	dev, err = findVdpaDevice(netInfo)
+	assert.NoError(err)
+	assert.NotNil(dev)
+	assert.Equal("vdpa1", dev.Name)
+
+	// The loopback interface is never backed by a vdpa device
+	netInfo.Iface.Name = "lo"
+	netInfo.Iface.HardwareAddr = nil
+	dev, err = findVdpaDevice(netInfo)
+	assert.NoError(err)
+	assert.Nil(dev)
+
+	vdpaHandle = vdpaNetlinkFixture{err: fmt.Errorf("vdpa family not found")}
+	netInfo.Iface.Name = "eth0"
+	netInfo.Iface.HardwareAddr, _ = net.ParseMAC("02:00:ca:fe:00:02")
+	_, err = findVdpaDevice(netInfo)
+	assert.Error(err)
+}
+
+func TestCreateVdpaEndpoint(t *testing.T) {
+	assert := assert.New(t)
+
+	devices := []*netlink.VDPADevConfig{
+		newVdpaDevConfig("vdpa0", "02:00:ca:fe:00:01", 0),
+		newVdpaDevConfig("vdpa1", "02:00:ca:fe:00:02", 4),
+	}
+	setupVdpaFixtures(t, devices, map[string]int{"vdpa1": 3})
+
+	netInfo := NetworkInfo{}
+	netInfo.Iface.Name = "eth0"
+	netInfo.Iface.HardwareAddr, _ = net.ParseMAC("02:00:ca:fe:00:02")
+
+	ep, err := createVdpaEndpoint(netInfo, devices[1])
+	assert.NoError(err)
+	assert.Equal(VdpaEndpointType, ep.Type())
+	assert.Equal("eth0", ep.Name())
+	assert.Equal("02:00:ca:fe:00:02", ep.HardwareAddr())
+	assert.Equal("vdpa1", ep.VdpaDevName)
+	assert.Equal("/dev/vhost-vdpa-3", ep.VhostVdpaPath)
+	assert.Equal(uint32(8), ep.NumQueues)
+	assert.Nil(ep.NetworkPair())
+
+	// Not bound to vhost_vdpa
+	_, err = createVdpaEndpoint(netInfo, devices[0])
+	assert.Error(err)
+}
+
+func TestVdpaEndpointSaveLoad(t *testing.T) {
+	assert := assert.New(t)
+
+	ep := &VdpaEndpoint{
+		VdpaDevName:   "vdpa0",
+		VhostVdpaPath: "/dev/vhost-vdpa-0",
+		NumQueues:     2,
+		HardAddr:      "02:00:ca:fe:00:01",
+		IfaceName:     "eth0",
+		EndpointType:  VdpaEndpointType,
+	}
+
+	saved := ep.save()
+	assert.Equal(string(VdpaEndpointType), saved.Type)
+	assert.NotNil(saved.Vdpa)
+
+	loaded := &VdpaEndpoint{}
+	loaded.load(saved)
+	assert.Equal(ep, loaded)
+}
+
+func TestVdpaEndpointAttach(t *testing.T) {
+	assert := assert.New(t)
+
+	ep := &VdpaEndpoint{
+		VdpaDevName:   "vdpa0",
+		VhostVdpaPath: "/dev/vhost-vdpa-0",
+		EndpointType:  VdpaEndpointType,
+	}
+
+	s := &Sandbox{
+		config:     &SandboxConfig{HypervisorType: QemuHypervisor},
+		hypervisor: &mockHypervisor{},
+	}
+
+	assert.NoError(ep.Attach(context.Background(), s))
+	assert.NoError(ep.HotAttach(context.Background(), s))
+	assert.NoError(ep.HotDetach(context.Background(), s, true, ""))
+	assert.NoError(ep.Detach(context.Background(), true, ""))
+
+	s.config.HypervisorType = FirecrackerHypervisor
+	assert.Error(ep.Attach(context.Background(), s))
+	assert.Error(ep.HotAttach(context.Background(), s))
+
+	assert.False(ep.GetRxRateLimiter())
+	assert.Error(ep.SetRxRateLimiter())
+	assert.False(ep.GetTxRateLimiter())
+	assert.Error(ep.SetTxRateLimiter())
+}
+
+func TestAddVdpaEndpoint(t *testing.T) {
+	assert := assert.New(t)
+
+	setupVdpaFixtures(t, []*netlink.VDPADevConfig{
+		newVdpaDevConfig("vdpa0", "02:00:ca:fe:00:01", 1),
+	}, map[string]int{"vdpa0": 0})
+
+	network := &LinuxNetwork{
+		eps:               []Endpoint{},
+		interworkingModel: NetXConnectTCFilterModel,
+	}
+	s := &Sandbox{
+		config:     &SandboxConfig{HypervisorType: QemuHypervisor},
+		hypervisor: &mockHypervisor{},
+	}
+
+	netInfo := NetworkInfo{}
+	netInfo.Iface.Name = "eth0"
+	netInfo.Iface.Type = "device"
+	netInfo.Iface.HardwareAddr, _ = net.ParseMAC("02:00:ca:fe:00:01")
+
+	ep, err := network.addSingleEndpoint(context.Background(), s, netInfo, true)
+	assert.NoError(err)
+	assert.Equal(VdpaEndpointType, ep.Type())
+	assert.Equal("/dev/vhost-vdpa-0", ep.(*VdpaEndpoint).VhostVdpaPath)
+
+	loaded := LoadNetwork(persistapi.NetworkInfo{
+		Endpoints: []persistapi.NetworkEndpoint{ep.save()},
+	})
+	assert.Len(loaded.Endpoints(), 1)
+	assert.Equal(ep.(*VdpaEndpoint).VdpaDevName, loaded.Endpoints()[0].(*VdpaEndpoint).VdpaDevName)
+}
@@ -9,6 +9,9 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/drivers"
+	resCtrl "github.com/kata-containers/kata-containers/src/runtime/pkg/resourcecontrol"
 	persistapi "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/persist/api"
 	vcTypes "github.com/kata-containers/kata-containers/src/runtime/virtcontainers/types"
 )
@@ -85,18 +88,76 @@ func (endpoint *VfioEndpoint) Detach(ctx context.Context, netNsCreated bool, net
 	return fmt.Errorf("detach is unsupported for VFIO endpoint")
 }
 
-func (endpoint *VfioEndpoint) HotAttach(context.Context, *Sandbox) error {
-	return fmt.Errorf("VfioEndpoint does not support Hot attach")
+// vfioDeviceInfo returns the description of the VFIO group of the endpoint.
+// The device is expected to be bound to vfio-pci already.
+func (endpoint *VfioEndpoint) vfioDeviceInfo(sysfs config.SysfsProvider) (config.DeviceInfo, error) {
+	vfioPath, err := drivers.GetVFIODevPath(sysfs, endpoint.HostBDF)
+	if err != nil {
+		return config.DeviceInfo{}, err
+	}
+
+	c, err := resCtrl.DeviceToCgroupDeviceRule(vfioPath)
+	if err != nil {
+		return config.DeviceInfo{}, err
+	}
+
+	return config.DeviceInfo{
+		ContainerPath: vfioPath,
+		DevType:       string(c.Type),
+		Major:         c.Major,
+		Minor:         c.Minor,
+		ColdPlug:      false,
+	}, nil
+}
+
+// HotAttach for VFIO endpoint hot plugs the VFIO device into the VM, so
+// that its network can be configured once the guest kernel claimed it.
+func (endpoint *VfioEndpoint) HotAttach(ctx context.Context, s *Sandbox) error {
+	d, err := endpoint.vfioDeviceInfo(s.config.HypervisorConfig.HostSysfs())
+	if err != nil {
+		return err
+	}
+	d.Port = s.config.HypervisorConfig.HotPlugVFIO
+
+	if _, err := s.AddDevice(ctx, d); err != nil {
+		return err
+	}
+
+	pciPath := s.GetVfioDeviceGuestPciPath(endpoint.HostBDF)
+	if pciPath.IsNil() {
+		return fmt.Errorf("PCI path for VFIO interface '%s' not found", endpoint.Name())
+	}
+	endpoint.SetPciPath(pciPath)
+
+	return nil
 }
 
+// HotDetach for VFIO endpoint hot unplugs the VFIO device from the VM.
 func (endpoint *VfioEndpoint) HotDetach(ctx context.Context, s *Sandbox, netNsCreated bool, netNsPath string) error {
-	return fmt.Errorf("VfioEndpoint does not support Hot detach")
+	d, err := endpoint.vfioDeviceInfo(s.config.HypervisorConfig.HostSysfs())
+	if err != nil {
+		return err
+	}
+
+	device := s.devManager.FindDevice(&d)
+	if device == nil {
+		return fmt.Errorf("VFIO device for interface '%s' not found", endpoint.Name())
+	}
+
+	if err := s.devManager.DetachDevice(ctx, device.DeviceID(), s); err != nil {
+		return err
+	}
+
+	return s.devManager.RemoveDevice(device.DeviceID())
 }
 
 func (endpoint *VfioEndpoint) save() persistapi.NetworkEndpoint {
 	return persistapi.NetworkEndpoint{
 		Type: string(endpoint.Type()),
-		Vfio: &persistapi.VfioEndpoint{},
+		Vfio: &persistapi.VfioEndpoint{
+			IfaceName: endpoint.Iface.Name,
+			HostBDF:   endpoint.HostBDF,
+		},
 	}
 }
 
@@ -105,6 +166,7 @@ func (endpoint *VfioEndpoint) load(s persistapi.NetworkEndpoint) {
 
 	if s.Vfio != nil {
 		endpoint.Iface.Name = s.Vfio.IfaceName
+		endpoint.HostBDF = s.Vfio.HostBDF
 	}
 }
 
@@ -0,0 +1,242 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/katautils/katatrace"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// vhostUserFSExternalTracingTags defines tags for the trace span
+var vhostUserFSExternalTracingTags = map[string]string{
+	"source":    "runtime",
+	"package":   "virtcontainers",
+	"subsystem": "vhost-user-fs-external",
+}
+
+var errVhostUserFSExternalSocketEmpty = errors.New("external vhost-user-fs socket path is empty")
+
+const (
+	// vhostUserFSExternalSandboxID is replaced by the sandbox ID in the
+	// external vhost-user-fs socket path.
+	vhostUserFSExternalSandboxID = "{sandbox_id}"
+
+	// vhostUserFSExternalTimeout is how long the external daemon is given
+	// to serve its socket once the restart hook is run.
+	vhostUserFSExternalTimeout = 10 * time.Second
+
+	// vhostUserFSExternalCheckInterval is how often the external daemon
+	// is checked while the sandbox runs.
+	vhostUserFSExternalCheckInterval = 5 * time.Second
+
+	// vhostUserFSExternalHealthCheckTimeout is how long the health check
+	// command is given to check the external daemon.
+	vhostUserFSExternalHealthCheckTimeout = 3 * time.Second
+)
+
+// vhostUserFSExternal is a virtio-fs backend served by a vhost-user-fs daemon
+// the operator runs. The runtime neither starts nor stops the daemon: its
+// socket is used as-is, linked to the socket path the hypervisor connects
+// to. The daemon is checked at start and while the sandbox runs: when its
+// socket is not served, the restart hook is run to (re)start the daemon.
+type vhostUserFSExternal struct {
+	// sandboxID is the ID of the sandbox the daemon serves
+	sandboxID string
+	// externalSocketPath is the socket the daemon serves
+	externalSocketPath string
+	// socketPath is where the hypervisor connects to
+	socketPath string
+	// sourcePath is the directory the daemon is expected to share
+	sourcePath string
+	// restartHook is run to (re)start the daemon, if set
+	restartHook string
+	// healthCheck is run to check the daemon, if set
+	healthCheck string
+	// timeout is how long the daemon is given to serve its socket once
+	// the restart hook is run
+	timeout time.Duration
+	// checkInterval is how often the daemon is checked while the sandbox
+	// runs
+	checkInterval time.Duration
+	// stopMonitor stops checking the daemon
+	stopMonitor chan struct{}
+}
+
+func newExternalVhostUserFS(params VirtiofsDaemonParams) (VirtiofsDaemon, error) {
+	return &vhostUserFSExternal{
+		sandboxID:          params.SandboxID,
+		externalSocketPath: strings.ReplaceAll(params.Config.VirtioFSExternalSocket, vhostUserFSExternalSandboxID, params.SandboxID),
+		socketPath:         params.SocketPath,
+		sourcePath:         params.SharedPath,
+		restartHook:        params.Config.VirtioFSExternalRestartHook,
+		healthCheck:        params.Config.VirtioFSExternalHealthCheck,
+		timeout:            vhostUserFSExternalTimeout,
+		checkInterval:      vhostUserFSExternalCheckInterval,
+	}, nil
+}
+
+// Start checks that the daemon serves its socket, restarting it through the
+// restart hook if not, and links the socket for the hypervisor. The daemon
+// is not a child of the runtime, hence no pid is returned: it is checked
+// until Stop instead, and onQuit is called once it is gone and cannot be
+// restarted.
+func (v *vhostUserFSExternal) Start(ctx context.Context, onQuit onQuitFunc) (int, error) {
+	span, _ := katatrace.Trace(ctx, v.Logger(), "Start", vhostUserFSExternalTracingTags)
+	defer span.End()
+
+	if v.externalSocketPath == "" {
+		return 0, errVhostUserFSExternalSocketEmpty
+	}
+
+	if err := v.check(); err != nil {
+		if v.restartHook == "" {
+			return 0, err
+		}
+
+		v.Logger().WithError(err).Warn("external vhost-user-fs daemon not ready, running the restart hook")
+		if err := v.restart(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := os.Remove(v.socketPath); err != nil && !os.IsNotExist(err) {
+		return 0, err
+	}
+
+	if err := os.Symlink(v.externalSocketPath, v.socketPath); err != nil {
+		return 0, fmt.Errorf("failed to link external vhost-user-fs socket %s: %w", v.externalSocketPath, err)
+	}
+
+	v.Logger().WithField("socket", v.externalSocketPath).Info("using external vhost-user-fs daemon")
+
+	v.stopMonitor = make(chan struct{})
+	go v.monitor(v.stopMonitor, onQuit)
+
+	return 0, nil
+}
+
+// Stop stops checking the daemon and unlinks its socket, the daemon is left
+// running.
+func (v *vhostUserFSExternal) Stop(ctx context.Context) error {
+	if v.stopMonitor != nil {
+		close(v.stopMonitor)
+		v.stopMonitor = nil
+	}
+
+	if err := os.Remove(v.socketPath); err != nil && !os.IsNotExist(err) {
+		v.Logger().WithError(err).WithField("path", v.socketPath).Warn("removing external vhost-user-fs socket link failed")
+	}
+	return nil
+}
+
+func (v *vhostUserFSExternal) Mount(opt MountOption) error {
+	return errUnimplemented
+}
+
+func (v *vhostUserFSExternal) Umount(mountpoint string) error {
+	return errUnimplemented
+}
+
+// check is the health check of the daemon: its socket must exist. The socket
+// is not connected to, a vhost-user daemon serves a single front-end and may
+// exit once it disconnects. The health check command, if set, then tells
+// whether the daemon behind the socket is alive.
+func (v *vhostUserFSExternal) check() error {
+	info, err := os.Stat(v.externalSocketPath)
+	if err != nil {
+		return fmt.Errorf("external vhost-user-fs socket %s is not served: %w", v.externalSocketPath, err)
+	}
+
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("external vhost-user-fs socket %s is not a socket", v.externalSocketPath)
+	}
+
+	if v.healthCheck == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), vhostUserFSExternalHealthCheckTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, v.healthCheck, v.sandboxID, v.externalSocketPath, v.sourcePath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("external vhost-user-fs health check %s failed: %w: %s", v.healthCheck, err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// monitor checks the daemon until stopCh is closed, restarting it through
+// the restart hook when it stops serving its socket. onQuit is called when
+// the daemon is gone and cannot be restarted.
+func (v *vhostUserFSExternal) monitor(stopCh chan struct{}, onQuit onQuitFunc) {
+	ticker := time.NewTicker(v.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+
+		err := v.check()
+		if err == nil {
+			continue
+		}
+
+		if v.restartHook != "" {
+			v.Logger().WithError(err).Warn("external vhost-user-fs daemon stopped, running the restart hook")
+			if err = v.restart(context.Background()); err == nil {
+				continue
+			}
+		}
+
+		v.Logger().WithError(err).Error("external vhost-user-fs daemon is gone")
+		if onQuit != nil {
+			onQuit()
+		}
+		return
+	}
+}
+
+// restart runs the restart hook with the sandbox ID, the daemon socket and
+// the shared directory as arguments, and waits for the daemon to serve its
+// socket.
+func (v *vhostUserFSExternal) restart(ctx context.Context) error {
+	hookCtx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(hookCtx, v.restartHook, v.sandboxID, v.externalSocketPath, v.sourcePath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("external vhost-user-fs restart hook %s failed: %w: %s", v.restartHook, err, strings.TrimSpace(string(output)))
+	}
+
+	var err error
+	for {
+		if err = v.check(); err == nil {
+			return nil
+		}
+
+		select {
+		case <-hookCtx.Done():
+			return err
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+func (v *vhostUserFSExternal) Logger() *log.Entry {
+	return hvLogger.WithField("subsystem", "vhost-user-fs-external")
+}
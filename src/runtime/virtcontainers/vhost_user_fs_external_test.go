@@ -0,0 +1,187 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVhostUserFSExternalStart(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	served := filepath.Join(dir, "served.sock")
+	listener, err := net.Listen("unix", served)
+	assert.NoError(err)
+	defer listener.Close()
+
+	external := filepath.Join(dir, "external.sock")
+	v := &vhostUserFSExternal{
+		sandboxID:          "sandbox",
+		externalSocketPath: external,
+		socketPath:         filepath.Join(dir, "vhost-fs.sock"),
+		sourcePath:         dir,
+		timeout:            time.Second,
+		checkInterval:      time.Second,
+	}
+
+	// The socket is not served, and there is no hook to restart the daemon
+	_, err = v.Start(context.Background(), nil)
+	assert.Error(err)
+
+	// The hook fails
+	v.restartHook = "/bin/false"
+	_, err = v.Start(context.Background(), nil)
+	assert.Error(err)
+
+	// The hook restarts the daemon serving the socket
+	hook := filepath.Join(dir, "restart-hook")
+	assert.NoError(os.WriteFile(hook, []byte("#!/bin/sh\nln -s "+served+" \"$2\"\n"), 0755))
+	v.restartHook = hook
+	pid, err := v.Start(context.Background(), nil)
+	assert.NoError(err)
+	assert.Zero(pid)
+
+	// The hypervisor connects to the daemon socket
+	target, err := os.Readlink(v.socketPath)
+	assert.NoError(err)
+	assert.Equal(external, target)
+
+	// The daemon is left running
+	assert.NoError(v.Stop(context.Background()))
+	assert.NoFileExists(v.socketPath)
+	assert.NoError(v.check())
+
+	// A file is not a socket
+	assert.NoError(os.Remove(external))
+	assert.NoError(os.WriteFile(external, nil, 0644))
+	assert.Error(v.check())
+
+	// The socket of a daemon that is gone is left to the health check
+	stale := filepath.Join(dir, "stale.sock")
+	staleListener, err := net.Listen("unix", stale)
+	assert.NoError(err)
+	staleListener.(*net.UnixListener).SetUnlinkOnClose(false)
+	assert.NoError(staleListener.Close())
+	assert.FileExists(stale)
+
+	assert.NoError(os.Remove(external))
+	assert.NoError(os.Symlink(stale, external))
+	assert.NoError(v.check())
+
+	v.healthCheck = "/bin/false"
+	assert.Error(v.check())
+	v.healthCheck = "/bin/true"
+	assert.NoError(v.check())
+}
+
+func TestVhostUserFSExternalSingleConnection(t *testing.T) {
+	assert := assert.New(t)
+
+	// The daemon serves a single front-end and exits once it disconnects
+	dir := t.TempDir()
+	external := filepath.Join(dir, "external.sock")
+	listener, err := net.Listen("unix", external)
+	assert.NoError(err)
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if err == nil {
+			accepted <- conn
+		}
+		close(accepted)
+	}()
+
+	v := &vhostUserFSExternal{
+		sandboxID:          "sandbox",
+		externalSocketPath: external,
+		socketPath:         filepath.Join(dir, "vhost-fs.sock"),
+		sourcePath:         dir,
+		healthCheck:        "/bin/true",
+		timeout:            time.Second,
+		checkInterval:      10 * time.Millisecond,
+	}
+
+	quit := make(chan struct{})
+	_, err = v.Start(context.Background(), func() { close(quit) })
+	assert.NoError(err)
+	defer v.Stop(context.Background())
+
+	// The checks do not connect to the daemon
+	select {
+	case <-accepted:
+		assert.Fail("the check connected to the daemon")
+	case <-quit:
+		assert.Fail("onQuit called")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Its connection is left to the hypervisor
+	conn, err := net.Dial("unix", v.socketPath)
+	assert.NoError(err)
+	defer conn.Close()
+
+	daemonConn := <-accepted
+	assert.NotNil(daemonConn)
+	if daemonConn != nil {
+		daemonConn.Close()
+	}
+}
+
+func TestVhostUserFSExternalMonitor(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	served := filepath.Join(dir, "served.sock")
+	listener, err := net.Listen("unix", served)
+	assert.NoError(err)
+	defer listener.Close()
+
+	external := filepath.Join(dir, "external.sock")
+	assert.NoError(os.Symlink(served, external))
+
+	broken := filepath.Join(dir, "broken")
+	hook := filepath.Join(dir, "restart-hook")
+	assert.NoError(os.WriteFile(hook, []byte("#!/bin/sh\n[ -e "+broken+" ] && exit 1\nln -s "+served+" \"$2\"\n"), 0755))
+
+	v := &vhostUserFSExternal{
+		sandboxID:          "sandbox",
+		externalSocketPath: external,
+		socketPath:         filepath.Join(dir, "vhost-fs.sock"),
+		sourcePath:         dir,
+		restartHook:        hook,
+		timeout:            time.Second,
+		checkInterval:      10 * time.Millisecond,
+	}
+
+	quit := make(chan struct{})
+	_, err = v.Start(context.Background(), func() { close(quit) })
+	assert.NoError(err)
+
+	// The daemon stops serving its socket, and is restarted by the hook
+	assert.NoError(os.Remove(external))
+	assert.Eventually(func() bool { return v.check() == nil }, 5*time.Second, 10*time.Millisecond)
+
+	// The daemon cannot be restarted anymore
+	assert.NoError(os.WriteFile(broken, nil, 0644))
+	assert.NoError(os.Remove(external))
+	select {
+	case <-quit:
+	case <-time.After(5 * time.Second):
+		assert.Fail("onQuit not called")
+	}
+
+	assert.NoError(v.Stop(context.Background()))
+}
@@ -12,6 +12,7 @@ package virtcontainers
 import (
 	"context"
 
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
 	hv "github.com/kata-containers/kata-containers/src/runtime/pkg/hypervisors"
 	"github.com/kata-containers/kata-containers/src/runtime/virtcontainers/types"
 	"github.com/pkg/errors"
@@ -58,6 +59,10 @@ func (vfw *virtFramework) HotplugRemoveDevice(ctx context.Context, devInfo inter
 	return nil, nil
 }
 
+func (vfw *virtFramework) IsDevicePlugged(ctx context.Context, devInfo interface{}, devType DeviceType) (bool, error) {
+	return false, nil
+}
+
 func (vfw *virtFramework) ResizeMemory(ctx context.Context, memMB uint32, memoryBlockSizeMB uint32, probe bool) (uint32, MemoryDevice, error) {
 	return 0, MemoryDevice{}, nil
 }
@@ -135,3 +140,11 @@ func (vfw *virtFramework) GenerateSocket(id string) (interface{}, error) {
 func (vfw *virtFramework) IsRateLimiterBuiltin() bool {
 	return false
 }
+
+func (vfw *virtFramework) UpdateNetRateLimiter(ctx context.Context, endpoint Endpoint, bandwidth NetworkBandwidth) error {
+	return errors.New("virtFramework does not support built-in network rate limiters")
+}
+
+func (vfw *virtFramework) ResizeBlockDevice(ctx context.Context, drive *config.BlockDrive, size uint64) error {
+	return errors.New("virtFramework does not support resizing block devices")
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
+)
+
+// VirtiofsDaemonParams are the parameters a VirtiofsDaemon is created with.
+type VirtiofsDaemonParams struct {
+	// Config is the hypervisor configuration of the sandbox
+	Config *HypervisorConfig
+	// SandboxID is the ID of the sandbox the daemon serves
+	SandboxID string
+	// SocketPath is the vhost-user-fs socket the hypervisor connects to
+	SocketPath string
+	// APISocketPath is the API socket of the daemon, for the daemons
+	// which have one
+	APISocketPath string
+	// SharedPath is the directory the daemon shares with the guest
+	SharedPath string
+}
+
+// VirtiofsDaemonFactory creates the VirtiofsDaemon of a sandbox.
+type VirtiofsDaemonFactory func(params VirtiofsDaemonParams) (VirtiofsDaemon, error)
+
+var (
+	virtiofsDaemonsLock sync.RWMutex
+
+	// virtiofsDaemons are the VirtiofsDaemon implementations, by the
+	// shared_fs value selecting them
+	virtiofsDaemons = map[string]VirtiofsDaemonFactory{
+		config.VirtioFS:         newVirtiofsd,
+		config.VirtioFSNydus:    newNydusd,
+		config.VirtioFSExternal: newExternalVhostUserFS,
+	}
+)
+
+// RegisterVirtiofsDaemon registers the VirtiofsDaemon implementation selected
+// by the sharedFS value of the shared_fs option, replacing the one already
+// registered for it if any.
+func RegisterVirtiofsDaemon(sharedFS string, factory VirtiofsDaemonFactory) {
+	virtiofsDaemonsLock.Lock()
+	defer virtiofsDaemonsLock.Unlock()
+
+	virtiofsDaemons[sharedFS] = factory
+}
+
+// IsVirtioFS returns whether the shared file system is a virtio-fs one,
+// served by a registered VirtiofsDaemon.
+func IsVirtioFS(sharedFS string) bool {
+	virtiofsDaemonsLock.RLock()
+	defer virtiofsDaemonsLock.RUnlock()
+
+	_, ok := virtiofsDaemons[sharedFS]
+	return ok
+}
+
+// VirtioFSBackends returns the shared_fs values of the registered
+// VirtiofsDaemon implementations.
+func VirtioFSBackends() []string {
+	virtiofsDaemonsLock.RLock()
+	defer virtiofsDaemonsLock.RUnlock()
+
+	backends := make([]string, 0, len(virtiofsDaemons))
+	for sharedFS := range virtiofsDaemons {
+		backends = append(backends, sharedFS)
+	}
+	sort.Strings(backends)
+
+	return backends
+}
+
+// newVirtiofsDaemon creates the VirtiofsDaemon selected by the shared file
+// system of the configuration, virtiofsd by default.
+func newVirtiofsDaemon(params VirtiofsDaemonParams) (VirtiofsDaemon, error) {
+	virtiofsDaemonsLock.RLock()
+	factory, ok := virtiofsDaemons[params.Config.SharedFS]
+	virtiofsDaemonsLock.RUnlock()
+
+	if !ok {
+		factory = newVirtiofsd
+	}
+
+	return factory(params)
+}
+
+func newVirtiofsd(params VirtiofsDaemonParams) (VirtiofsDaemon, error) {
+	return &virtiofsd{
+		path:       params.Config.VirtioFSDaemon,
+		sourcePath: params.SharedPath,
+		socketPath: params.SocketPath,
+		extraArgs:  params.Config.VirtioFSExtraArgs,
+		cache:      params.Config.VirtioFSCache,
+	}, nil
+}
+
+func newNydusd(params VirtiofsDaemonParams) (VirtiofsDaemon, error) {
+	nd := &nydusd{
+		path:        params.Config.VirtioFSDaemon,
+		sockPath:    params.SocketPath,
+		apiSockPath: params.APISocketPath,
+		sourcePath:  params.SharedPath,
+		debug:       params.Config.Debug,
+		extraArgs:   params.Config.VirtioFSExtraArgs,
+		startFn:     startInShimNS,
+	}
+	nd.setupShareDirFn = nd.setupPassthroughFS
+	return nd, nil
+}
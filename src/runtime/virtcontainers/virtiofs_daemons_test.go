@@ -0,0 +1,74 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"testing"
+
+	"github.com/kata-containers/kata-containers/src/runtime/pkg/device/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewVirtiofsDaemon(t *testing.T) {
+	assert := assert.New(t)
+
+	params := VirtiofsDaemonParams{
+		Config: &HypervisorConfig{
+			VirtioFSDaemon:         "/usr/libexec/virtiofsd",
+			VirtioFSExternalSocket: "/run/vhost-user-fs/{sandbox_id}.sock",
+		},
+		SandboxID:     "sandbox",
+		SocketPath:    "/run/vc/vm/sandbox/vhost-fs.sock",
+		APISocketPath: "/run/vc/vm/sandbox/nydusd-api.sock",
+		SharedPath:    "/run/kata-containers/shared/sandboxes/sandbox/shared",
+	}
+
+	for sharedFS, expected := range map[string]VirtiofsDaemon{
+		config.VirtioFS:      &virtiofsd{},
+		config.VirtioFSNydus: &nydusd{},
+		// virtiofsd is the default
+		config.Virtio9P:         &virtiofsd{},
+		config.VirtioFSExternal: &vhostUserFSExternal{},
+	} {
+		params.Config.SharedFS = sharedFS
+		daemon, err := newVirtiofsDaemon(params)
+		assert.NoError(err)
+		assert.IsType(expected, daemon, sharedFS)
+	}
+
+	params.Config.SharedFS = config.VirtioFSExternal
+	daemon, err := newVirtiofsDaemon(params)
+	assert.NoError(err)
+	assert.Equal("/run/vhost-user-fs/sandbox.sock", daemon.(*vhostUserFSExternal).externalSocketPath)
+	assert.Equal(params.SocketPath, daemon.(*vhostUserFSExternal).socketPath)
+}
+
+func TestRegisterVirtiofsDaemon(t *testing.T) {
+	assert := assert.New(t)
+
+	const sharedFS = "virtio-fs-test"
+
+	assert.False(IsVirtioFS(sharedFS))
+	assert.False(IsVirtioFS(config.Virtio9P))
+	assert.False(IsVirtioFS(config.NoSharedFS))
+	assert.True(IsVirtioFS(config.VirtioFSExternal))
+
+	RegisterVirtiofsDaemon(sharedFS, func(params VirtiofsDaemonParams) (VirtiofsDaemon, error) {
+		return &virtiofsdMock{}, nil
+	})
+	t.Cleanup(func() {
+		virtiofsDaemonsLock.Lock()
+		delete(virtiofsDaemons, sharedFS)
+		virtiofsDaemonsLock.Unlock()
+	})
+
+	assert.True(IsVirtioFS(sharedFS))
+	assert.Contains(VirtioFSBackends(), sharedFS)
+
+	daemon, err := newVirtiofsDaemon(VirtiofsDaemonParams{Config: &HypervisorConfig{SharedFS: sharedFS}})
+	assert.NoError(err)
+	assert.IsType(&virtiofsdMock{}, daemon)
+}
@@ -46,6 +46,7 @@ func main() {
 		"timeout for SPDK JSON-RPC requests")
 	flag.StringVar(&cfg.SpdkRawPath, "spdk-rawpath", "", "path for spdk rawdisk backing files")
 	flag.StringVar(&cfg.SpdkVhostPath, "spdk-vhostpath", "", "path for spdk vhost controller sockets")
+	flag.StringVar(&cfg.SpdkLvstore, "spdk-lvstore", "", "spdk lvol store to create the spdk volumes in, required to snapshot them")
 
 	showVersion := flag.Bool("version", false, "Show version.")
 
@@ -11,8 +11,10 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/pborman/uuid"
 	"google.golang.org/grpc/codes"
@@ -86,6 +88,10 @@ func (dv *directVolume) CreateVolume(ctx context.Context, req *csi.CreateVolumeR
 				if volumeSource.GetVolume() != nil && exVol.ParentVolID != volumeSource.GetVolume().GetVolumeId() {
 					return nil, status.Error(codes.AlreadyExists, "existing volume source volume id not matching")
 				}
+			case *csi.VolumeContentSource_Snapshot:
+				if volumeSource.GetSnapshot() != nil && exVol.ParentSnapID != volumeSource.GetSnapshot().GetSnapshotId() {
+					return nil, status.Error(codes.AlreadyExists, "existing volume source snapshot id not matching")
+				}
 			default:
 				return nil, status.Errorf(codes.InvalidArgument, "%v not a proper volume source", volumeSource)
 			}
@@ -105,17 +111,45 @@ func (dv *directVolume) CreateVolume(ctx context.Context, req *csi.CreateVolumeR
 	volumeID := uuid.NewUUID().String()
 	kind := volumeCtx[storageKind]
 
+	isSpdkVolume := volumeCtx[utils.KataContainersDirectVolumeType] == utils.SpdkVolumeTypeName
+	isSpdkLvolVolume := isSpdkVolume && dv.config.SpdkLvstore != ""
+
+	// The data of the snapshot is restored to the raw disk before the
+	// volume is created, so that the raw disk is used as is. The lvol of
+	// an SPDK volume is cloned from the snapshot instead.
+	var snapshotDataPath, lvolSnapshotID string
+	if snapshot := contentSrc.GetSnapshot(); snapshot != nil && isSpdkLvolVolume {
+		lvolSnapshotID = snapshot.GetSnapshotId()
+	} else if snapshot != nil {
+		if isSpdkVolume {
+			snapshotDataPath = spdkBackingFilePath(req.GetName())
+		} else {
+			snapshotDataPath = utils.GetDirectBlockDevicePath(dv.config.StoragePath, volumeID, capacity)
+		}
+		if err := dv.loadFromSnapshot(capacity, snapshot.GetSnapshotId(), snapshotDataPath); err != nil {
+			klog.V(4).Infof("VolumeSource error: %v", err)
+			return nil, err
+		}
+	}
+
 	var vol *state.Volume
 	var err error
-	if volumeCtx[utils.KataContainersDirectVolumeType] == utils.SpdkVolumeTypeName {
+	if isSpdkLvolVolume {
+		vol, err = dv.createSPDKLvolVolume(volumeID, req.GetName(), capacity, kind, lvolSnapshotID)
+		if err != nil {
+			return nil, err
+		}
+	} else if isSpdkVolume {
 		vol, err = dv.createSPDKVolume(volumeID, req.GetName(), capacity, kind)
 		if err != nil {
+			removeSnapshotData(snapshotDataPath)
 			return nil, err
 		}
 	} else {
 		vol, err = dv.createVolume(volumeID, req.GetName(), capacity, kind)
 		if err != nil {
-			klog.Errorf("created volume %s at path %s failed with error: %v", vol.VolID, vol.VolPath, err.Error())
+			klog.Errorf("created volume %s failed with error: %v", volumeID, err.Error())
+			removeSnapshotData(snapshotDataPath)
 			return nil, err
 		}
 		klog.Infof("created volume %s at path %s", vol.VolID, vol.VolPath)
@@ -130,6 +164,9 @@ func (dv *directVolume) CreateVolume(ctx context.Context, req *csi.CreateVolumeR
 				err = dv.loadFromVolume(capacity, srcVolume.GetVolumeId(), path)
 				vol.ParentVolID = srcVolume.GetVolumeId()
 			}
+		case *csi.VolumeContentSource_Snapshot:
+			vol.ParentSnapID = volumeSource.GetSnapshot().GetSnapshotId()
+			err = dv.state.UpdateVolume(*vol)
 		default:
 			err = status.Errorf(codes.InvalidArgument, "%v not a proper volume source", volumeSource)
 		}
@@ -188,7 +225,12 @@ func (dv *directVolume) DeleteVolume(ctx context.Context, req *csi.DeleteVolumeR
 		bdevName := vol.Metadata["bdevName"]
 		backingFile := vol.Metadata["backingFile"]
 
-		if bdevName != "" {
+		if bdevName != "" && isSPDKLvolVolume(vol) {
+			if err := spdkDeleteLvol(bdevName); err != nil {
+				klog.Errorf("Failed to delete SPDK lvol %s: %v", bdevName, err)
+				return nil, status.Errorf(codes.Internal, "Failed to delete SPDK lvol: %v", err)
+			}
+		} else if bdevName != "" {
 			_, err := spdkrpc.Call("bdev_aio_delete", map[string]any{"name": bdevName})
 			if err != nil {
 				if se, ok := err.(*spdkrpc.SpdkError); ok && se.Code == spdkrpc.SpdkErrNoDevice {
@@ -297,6 +339,12 @@ func (dv *directVolume) validateControllerServiceRequest(c csi.ControllerService
 func (dv *directVolume) getControllerServiceCapabilities() []*csi.ControllerServiceCapability {
 	cl := []csi.ControllerServiceCapability_RPC_Type{
 		csi.ControllerServiceCapability_RPC_CREATE_DELETE_VOLUME,
+		csi.ControllerServiceCapability_RPC_CREATE_DELETE_SNAPSHOT,
+		csi.ControllerServiceCapability_RPC_LIST_SNAPSHOTS,
+		csi.ControllerServiceCapability_RPC_EXPAND_VOLUME,
+		csi.ControllerServiceCapability_RPC_LIST_VOLUMES,
+		csi.ControllerServiceCapability_RPC_GET_VOLUME,
+		csi.ControllerServiceCapability_RPC_VOLUME_CONDITION,
 	}
 
 	var csc []*csi.ControllerServiceCapability
@@ -319,11 +367,108 @@ func (dv *directVolume) ControllerModifyVolume(context.Context, *csi.ControllerM
 }
 
 func (dv *directVolume) ListVolumes(ctx context.Context, req *csi.ListVolumesRequest) (*csi.ListVolumesResponse, error) {
-	return &csi.ListVolumesResponse{}, nil
+	if err := dv.validateControllerServiceRequest(csi.ControllerServiceCapability_RPC_LIST_VOLUMES); err != nil {
+		klog.V(3).Infof("invalid list volumes req: %v", req)
+		return nil, err
+	}
+
+	dv.mutex.Lock()
+	volumes := dv.state.GetVolumes()
+	start, end, next, err := paginate(len(volumes), req.GetStartingToken(), req.GetMaxEntries())
+	if err != nil {
+		dv.mutex.Unlock()
+		return nil, err
+	}
+
+	var entries []*csi.ListVolumesResponse_Entry
+	var health []volumeHealth
+	for _, vol := range volumes[start:end] {
+		entries = append(entries, &csi.ListVolumesResponse_Entry{
+			Volume: dv.csiVolume(vol),
+			Status: &csi.ListVolumesResponse_VolumeStatus{
+				PublishedNodeIds: publishedNodeIDs(vol),
+			},
+		})
+		health = append(health, dv.volumeHealth(vol))
+	}
+	dv.mutex.Unlock()
+
+	// The conditions are checked without holding the driver lock, the
+	// SPDK RPCs can take a while.
+	for i, entry := range entries {
+		entry.Status.VolumeCondition = health[i].condition()
+	}
+
+	return &csi.ListVolumesResponse{
+		Entries:   entries,
+		NextToken: next,
+	}, nil
 }
 
 func (dv *directVolume) ControllerGetVolume(ctx context.Context, req *csi.ControllerGetVolumeRequest) (*csi.ControllerGetVolumeResponse, error) {
-	return &csi.ControllerGetVolumeResponse{}, nil
+	if err := dv.validateControllerServiceRequest(csi.ControllerServiceCapability_RPC_GET_VOLUME); err != nil {
+		klog.V(3).Infof("invalid get volume req: %v", req)
+		return nil, err
+	}
+
+	if len(req.GetVolumeId()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Volume ID missing in request")
+	}
+
+	dv.mutex.Lock()
+	vol, err := dv.state.GetVolumeByID(req.GetVolumeId())
+	if err != nil {
+		dv.mutex.Unlock()
+		return nil, err
+	}
+	volume := dv.csiVolume(vol)
+	health := dv.volumeHealth(vol)
+	dv.mutex.Unlock()
+
+	return &csi.ControllerGetVolumeResponse{
+		Volume: volume,
+		Status: &csi.ControllerGetVolumeResponse_VolumeStatus{
+			PublishedNodeIds: publishedNodeIDs(vol),
+			VolumeCondition:  health.condition(),
+		},
+	}, nil
+}
+
+func (dv *directVolume) csiVolume(vol state.Volume) *csi.Volume {
+	volume := &csi.Volume{
+		VolumeId:      vol.VolID,
+		CapacityBytes: vol.VolSize,
+	}
+
+	if vol.ParentSnapID != "" {
+		volume.ContentSource = &csi.VolumeContentSource{
+			Type: &csi.VolumeContentSource_Snapshot{
+				Snapshot: &csi.VolumeContentSource_SnapshotSource{SnapshotId: vol.ParentSnapID},
+			},
+		}
+	} else if vol.ParentVolID != "" {
+		volume.ContentSource = &csi.VolumeContentSource{
+			Type: &csi.VolumeContentSource_Volume{
+				Volume: &csi.VolumeContentSource_VolumeSource{VolumeId: vol.ParentVolID},
+			},
+		}
+	}
+
+	if dv.config.EnableTopology {
+		volume.AccessibleTopology = []*csi.Topology{
+			{Segments: map[string]string{TopologyKeyNode: dv.config.NodeID}},
+		}
+	}
+
+	return volume
+}
+
+// publishedNodeIDs returns the node on which the volume is published, if any.
+func publishedNodeIDs(vol state.Volume) []string {
+	if vol.Published.Empty() || vol.NodeID == "" {
+		return nil
+	}
+	return []string{vol.NodeID}
 }
 
 func (dv *directVolume) ControllerPublishVolume(ctx context.Context, req *csi.ControllerPublishVolumeRequest) (*csi.ControllerPublishVolumeResponse, error) {
@@ -337,21 +482,180 @@ func (dv *directVolume) ControllerUnpublishVolume(ctx context.Context, req *csi.
 }
 
 func (dv *directVolume) CreateSnapshot(ctx context.Context, req *csi.CreateSnapshotRequest) (*csi.CreateSnapshotResponse, error) {
+	if err := dv.validateControllerServiceRequest(csi.ControllerServiceCapability_RPC_CREATE_DELETE_SNAPSHOT); err != nil {
+		klog.V(3).Infof("invalid create snapshot req: %v", req)
+		return nil, err
+	}
+
+	if len(req.GetName()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Name missing in request")
+	}
+	if len(req.GetSourceVolumeId()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "SourceVolumeId missing in request")
+	}
+
+	dv.mutex.Lock()
+	defer dv.mutex.Unlock()
+
+	// Need to check for already existing snapshot name, and if found
+	// check that it is a snapshot of the same volume
+	if exSnap, err := dv.state.GetSnapshotByName(req.GetName()); err == nil {
+		if exSnap.VolID != req.GetSourceVolumeId() {
+			return nil, status.Errorf(codes.AlreadyExists, "snapshot with the same name: %s but with different SourceVolumeId already exist", req.GetName())
+		}
 
-	return &csi.CreateSnapshotResponse{}, nil
+		return &csi.CreateSnapshotResponse{Snapshot: csiSnapshot(exSnap)}, nil
+	}
+
+	vol, err := dv.state.GetVolumeByID(req.GetSourceVolumeId())
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := dv.createSnapshot(uuid.NewUUID().String(), req.GetName(), vol)
+	if err != nil {
+		return nil, err
+	}
+	klog.Infof("created snapshot %s of volume %s", snapshot.ID, vol.VolID)
+
+	return &csi.CreateSnapshotResponse{Snapshot: csiSnapshot(*snapshot)}, nil
 }
 
 func (dv *directVolume) DeleteSnapshot(ctx context.Context, req *csi.DeleteSnapshotRequest) (*csi.DeleteSnapshotResponse, error) {
+	if err := dv.validateControllerServiceRequest(csi.ControllerServiceCapability_RPC_CREATE_DELETE_SNAPSHOT); err != nil {
+		klog.V(3).Infof("invalid delete snapshot req: %v", req)
+		return nil, err
+	}
+
+	if len(req.GetSnapshotId()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Snapshot ID missing in request")
+	}
+
+	dv.mutex.Lock()
+	defer dv.mutex.Unlock()
+
+	snapshotID := req.GetSnapshotId()
+	if err := dv.deleteSnapshot(snapshotID); err != nil {
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to delete snapshot %v: %v", snapshotID, err))
+	}
+	klog.Infof("snapshot %v successfully deleted", snapshotID)
 
 	return &csi.DeleteSnapshotResponse{}, nil
 }
 
 func (dv *directVolume) ListSnapshots(ctx context.Context, req *csi.ListSnapshotsRequest) (*csi.ListSnapshotsResponse, error) {
+	if err := dv.validateControllerServiceRequest(csi.ControllerServiceCapability_RPC_LIST_SNAPSHOTS); err != nil {
+		klog.V(3).Infof("invalid list snapshot req: %v", req)
+		return nil, err
+	}
+
+	dv.mutex.Lock()
+	defer dv.mutex.Unlock()
+
+	var snapshots []state.Snapshot
+	for _, snapshot := range dv.state.GetSnapshots() {
+		if req.GetSnapshotId() != "" && snapshot.ID != req.GetSnapshotId() {
+			continue
+		}
+		if req.GetSourceVolumeId() != "" && snapshot.VolID != req.GetSourceVolumeId() {
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	start, end, next, err := paginate(len(snapshots), req.GetStartingToken(), req.GetMaxEntries())
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*csi.ListSnapshotsResponse_Entry
+	for _, snapshot := range snapshots[start:end] {
+		entries = append(entries, &csi.ListSnapshotsResponse_Entry{Snapshot: csiSnapshot(snapshot)})
+	}
+
+	return &csi.ListSnapshotsResponse{
+		Entries:   entries,
+		NextToken: next,
+	}, nil
+}
+
+func csiSnapshot(snapshot state.Snapshot) *csi.Snapshot {
+	return &csi.Snapshot{
+		SnapshotId:     snapshot.ID,
+		SourceVolumeId: snapshot.VolID,
+		SizeBytes:      snapshot.SizeBytes,
+		CreationTime: &timestamp.Timestamp{
+			Seconds: snapshot.CreationTime.Unix(),
+			Nanos:   int32(snapshot.CreationTime.Nanosecond()),
+		},
+		ReadyToUse: snapshot.ReadyToUse,
+	}
+}
+
+// paginate returns the range of the entries to list for the starting token
+// and the maximum number of entries, and the token of the next entries.
+func paginate(count int, startingToken string, maxEntries int32) (int, int, string, error) {
+	start := 0
+	if startingToken != "" {
+		var err error
+		if start, err = strconv.Atoi(startingToken); err != nil || start < 0 || start > count {
+			return 0, 0, "", status.Errorf(codes.Aborted, "invalid starting token %q", startingToken)
+		}
+	}
 
-	return &csi.ListSnapshotsResponse{}, nil
+	end := count
+	if maxEntries > 0 && start+int(maxEntries) < count {
+		end = start + int(maxEntries)
+	}
+
+	next := ""
+	if end < count {
+		next = strconv.Itoa(end)
+	}
+	return start, end, next, nil
 }
 
 func (dv *directVolume) ControllerExpandVolume(ctx context.Context, req *csi.ControllerExpandVolumeRequest) (*csi.ControllerExpandVolumeResponse, error) {
+	if err := dv.validateControllerServiceRequest(csi.ControllerServiceCapability_RPC_EXPAND_VOLUME); err != nil {
+		klog.V(3).Infof("invalid expand volume req: %v", req)
+		return nil, err
+	}
 
-	return &csi.ControllerExpandVolumeResponse{}, nil
+	volID := req.GetVolumeId()
+	if len(volID) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Volume ID missing in request")
+	}
+	capRange := req.GetCapacityRange()
+	if capRange == nil {
+		return nil, status.Error(codes.InvalidArgument, "Capacity range not provided")
+	}
+
+	capacity := capRange.GetRequiredBytes()
+	if limit := capRange.GetLimitBytes(); limit > 0 && capacity > limit {
+		return nil, status.Errorf(codes.InvalidArgument, "Requested capacity %d exceeds the limit %d", capacity, limit)
+	}
+	if capacity > dv.config.MaxVolumeSize {
+		return nil, status.Errorf(codes.OutOfRange, "Requested capacity %d exceeds maximum allowed %d", capacity, dv.config.MaxVolumeSize)
+	}
+
+	dv.mutex.Lock()
+	defer dv.mutex.Unlock()
+
+	vol, err := dv.state.GetVolumeByID(volID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The volumes are never shrunk
+	if vol.VolSize < capacity {
+		if err := dv.expandVolume(&vol, capacity); err != nil {
+			return nil, err
+		}
+		klog.Infof("volume %s expanded to %d bytes", volID, capacity)
+	}
+
+	return &csi.ControllerExpandVolumeResponse{
+		CapacityBytes:         vol.VolSize,
+		NodeExpansionRequired: true,
+	}, nil
 }
@@ -9,10 +9,13 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	csi "github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"kata-containers/csi-kata-directvolume/pkg/spdkrpc"
 	"kata-containers/csi-kata-directvolume/pkg/utils"
@@ -109,6 +112,13 @@ func TestSPDKVolume(t *testing.T) {
 	}
 	require.True(t, foundCreate, "should call bdev_aio_create")
 
+	// An SPDK volume not backed by an lvol is not snapshotted
+	_, err = dv.CreateSnapshot(context.TODO(), &csi.CreateSnapshotRequest{
+		Name:           "snap-spdk",
+		SourceVolumeId: volID,
+	})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
 	_, err = dv.DeleteVolume(context.TODO(), &csi.DeleteVolumeRequest{VolumeId: volID})
 	require.NoError(t, err)
 
@@ -127,3 +137,498 @@ func TestSPDKVolume(t *testing.T) {
 	_, err = dv.DeleteVolume(context.TODO(), &csi.DeleteVolumeRequest{VolumeId: volID})
 	require.NoError(t, err)
 }
+
+func TestSnapshots(t *testing.T) {
+	tmp := t.TempDir()
+
+	oldDir := utils.SpdkRawDiskDir
+	utils.SpdkRawDiskDir = filepath.Join(tmp, "rawdisks")
+	defer func() { utils.SpdkRawDiskDir = oldDir }()
+
+	oldCall := spdkrpc.Call
+	fs := newFakeSpdk()
+	spdkrpc.Call = fs.fn
+	defer func() { spdkrpc.Call = oldCall }()
+
+	cfg := Config{
+		DriverName:    "directvolume.csi.katacontainers.io",
+		Endpoint:      "unix:///tmp/fake.sock",
+		NodeID:        "node-test",
+		StoragePath:   filepath.Join(tmp, "stor"),
+		StateDir:      filepath.Join(tmp, "st"),
+		MaxVolumeSize: 1 << 40,
+		SpdkRawPath:   filepath.Join(tmp, "spdk-raw"),
+		SpdkVhostPath: filepath.Join(tmp, "spdk-vhost"),
+	}
+	dv, err := NewDirectVolumeDriver(cfg)
+	require.NoError(t, err)
+
+	createVolume := func(name, volType string, size int64, snapshotID string) *csi.Volume {
+		req := &csi.CreateVolumeRequest{
+			Name:          name,
+			CapacityRange: &csi.CapacityRange{RequiredBytes: size},
+			Parameters: map[string]string{
+				utils.KataContainersDirectVolumeType: volType,
+			},
+			VolumeCapabilities: []*csi.VolumeCapability{mountCap()},
+		}
+		if snapshotID != "" {
+			req.VolumeContentSource = &csi.VolumeContentSource{
+				Type: &csi.VolumeContentSource_Snapshot{
+					Snapshot: &csi.VolumeContentSource_SnapshotSource{SnapshotId: snapshotID},
+				},
+			}
+		}
+		resp, err := dv.CreateVolume(context.TODO(), req)
+		require.NoError(t, err)
+		return resp.GetVolume()
+	}
+
+	// A staged direct volume with data
+	vol := createVolume("vol", utils.DirectVolumeTypeName, 2<<20, "")
+	rawDisk := utils.GetDirectBlockDevicePath(cfg.StoragePath, vol.GetVolumeId(), 2<<20)
+	require.NoError(t, os.MkdirAll(filepath.Dir(rawDisk), 0o750))
+	require.NoError(t, os.WriteFile(rawDisk, []byte("seeded database"), 0o640))
+	require.NoError(t, os.Truncate(rawDisk, 2<<20))
+
+	snapResp, err := dv.CreateSnapshot(context.TODO(), &csi.CreateSnapshotRequest{
+		Name:           "snap",
+		SourceVolumeId: vol.GetVolumeId(),
+	})
+	require.NoError(t, err)
+	snap := snapResp.GetSnapshot()
+	require.Equal(t, vol.GetVolumeId(), snap.GetSourceVolumeId())
+	require.EqualValues(t, 2<<20, snap.GetSizeBytes())
+	require.True(t, snap.GetReadyToUse())
+
+	// The snapshot is independent from its volume
+	require.NoError(t, os.WriteFile(rawDisk, []byte("updated database"), 0o640))
+
+	// Creating the snapshot again is idempotent
+	again, err := dv.CreateSnapshot(context.TODO(), &csi.CreateSnapshotRequest{
+		Name:           "snap",
+		SourceVolumeId: vol.GetVolumeId(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, snap.GetSnapshotId(), again.GetSnapshot().GetSnapshotId())
+
+	_, err = dv.CreateSnapshot(context.TODO(), &csi.CreateSnapshotRequest{
+		Name:           "snap",
+		SourceVolumeId: "other",
+	})
+	require.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	// A snapshot of a volume never staged has no data
+	emptyVol := createVolume("empty", utils.DirectVolumeTypeName, 1<<20, "")
+	emptyResp, err := dv.CreateSnapshot(context.TODO(), &csi.CreateSnapshotRequest{
+		Name:           "empty-snap",
+		SourceVolumeId: emptyVol.GetVolumeId(),
+	})
+	require.NoError(t, err)
+
+	list, err := dv.ListSnapshots(context.TODO(), &csi.ListSnapshotsRequest{MaxEntries: 1})
+	require.NoError(t, err)
+	require.Len(t, list.GetEntries(), 1)
+	require.Equal(t, snap.GetSnapshotId(), list.GetEntries()[0].GetSnapshot().GetSnapshotId())
+	require.Equal(t, "1", list.GetNextToken())
+
+	list, err = dv.ListSnapshots(context.TODO(), &csi.ListSnapshotsRequest{StartingToken: list.GetNextToken()})
+	require.NoError(t, err)
+	require.Len(t, list.GetEntries(), 1)
+	require.Equal(t, emptyResp.GetSnapshot().GetSnapshotId(), list.GetEntries()[0].GetSnapshot().GetSnapshotId())
+	require.Empty(t, list.GetNextToken())
+
+	list, err = dv.ListSnapshots(context.TODO(), &csi.ListSnapshotsRequest{SourceVolumeId: emptyVol.GetVolumeId()})
+	require.NoError(t, err)
+	require.Len(t, list.GetEntries(), 1)
+
+	_, err = dv.ListSnapshots(context.TODO(), &csi.ListSnapshotsRequest{StartingToken: "10"})
+	require.Equal(t, codes.Aborted, status.Code(err))
+
+	// A direct volume is restored with the data of the snapshot, grown
+	// to the size of the volume
+	clone := createVolume("clone", utils.DirectVolumeTypeName, 4<<20, snap.GetSnapshotId())
+	cloneDisk := utils.GetDirectBlockDevicePath(cfg.StoragePath, clone.GetVolumeId(), 4<<20)
+	data, err := os.ReadFile(cloneDisk)
+	require.NoError(t, err)
+	require.Len(t, data, 4<<20)
+	require.True(t, strings.HasPrefix(string(data), "seeded database"))
+
+	cloneVol, err := dv.state.GetVolumeByID(clone.GetVolumeId())
+	require.NoError(t, err)
+	require.Equal(t, snap.GetSnapshotId(), cloneVol.ParentSnapID)
+
+	// and so is a SPDK volume, before its bdev is created
+	spdkClone := createVolume("spdk-clone", utils.SpdkVolumeTypeName, 2<<20, snap.GetSnapshotId())
+	data, err = os.ReadFile(filepath.Join(utils.SpdkRawDiskDir, "spdk-clone.raw"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(data), "seeded database"))
+	require.Equal(t, "bdev_aio_create", fs.calls[len(fs.calls)-1].method)
+
+	// A published direct volume is only snapshotted with a reflink
+	published, err := dv.state.GetVolumeByID(vol.GetVolumeId())
+	require.NoError(t, err)
+	published.Published.Add(filepath.Join(tmp, "target"))
+	require.NoError(t, dv.state.UpdateVolume(published))
+
+	_, err = dv.CreateSnapshot(context.TODO(), &csi.CreateSnapshotRequest{
+		Name:           "published-snap",
+		SourceVolumeId: vol.GetVolumeId(),
+	})
+	if err != nil {
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+		_, err = dv.state.GetSnapshotByName("published-snap")
+		require.Equal(t, codes.NotFound, status.Code(err))
+	}
+
+	// A volume is not restored from a smaller snapshot
+	_, err = dv.CreateVolume(context.TODO(), &csi.CreateVolumeRequest{
+		Name:               "too-small",
+		CapacityRange:      &csi.CapacityRange{RequiredBytes: 1 << 20},
+		VolumeCapabilities: []*csi.VolumeCapability{mountCap()},
+		VolumeContentSource: &csi.VolumeContentSource{
+			Type: &csi.VolumeContentSource_Snapshot{
+				Snapshot: &csi.VolumeContentSource_SnapshotSource{SnapshotId: snap.GetSnapshotId()},
+			},
+		},
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Deleting the snapshot removes its data, and is idempotent
+	snapshot, err := dv.state.GetSnapshotByID(snap.GetSnapshotId())
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = dv.DeleteSnapshot(context.TODO(), &csi.DeleteSnapshotRequest{SnapshotId: snap.GetSnapshotId()})
+		require.NoError(t, err)
+	}
+	_, err = os.Stat(snapshot.Path)
+	require.True(t, os.IsNotExist(err))
+
+	_, err = dv.DeleteVolume(context.TODO(), &csi.DeleteVolumeRequest{VolumeId: spdkClone.GetVolumeId()})
+	require.NoError(t, err)
+	_, err = dv.DeleteVolume(context.TODO(), &csi.DeleteVolumeRequest{VolumeId: clone.GetVolumeId()})
+	require.NoError(t, err)
+	_, err = os.Stat(cloneDisk)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestSPDKLvolSnapshots(t *testing.T) {
+	tmp := t.TempDir()
+
+	oldCall := spdkrpc.Call
+	fs := newFakeSpdk()
+	spdkrpc.Call = fs.fn
+	defer func() { spdkrpc.Call = oldCall }()
+
+	cfg := Config{
+		DriverName:    "directvolume.csi.katacontainers.io",
+		Endpoint:      "unix:///tmp/fake.sock",
+		NodeID:        "node-test",
+		StoragePath:   filepath.Join(tmp, "stor"),
+		StateDir:      filepath.Join(tmp, "st"),
+		MaxVolumeSize: 1 << 40,
+		SpdkRawPath:   filepath.Join(tmp, "spdk-raw"),
+		SpdkVhostPath: filepath.Join(tmp, "spdk-vhost"),
+		SpdkLvstore:   "lvs0",
+	}
+	dv, err := NewDirectVolumeDriver(cfg)
+	require.NoError(t, err)
+
+	lastCall := func(method string) call {
+		for i := len(fs.calls) - 1; i >= 0; i-- {
+			if fs.calls[i].method == method {
+				return fs.calls[i]
+			}
+		}
+		require.Failf(t, "SPDK method not called", method)
+		return call{}
+	}
+	createVolume := func(name, volType string, size int64, snapshotID string) (*csi.CreateVolumeResponse, error) {
+		req := &csi.CreateVolumeRequest{
+			Name:          name,
+			CapacityRange: &csi.CapacityRange{RequiredBytes: size},
+			Parameters: map[string]string{
+				utils.KataContainersDirectVolumeType: volType,
+			},
+			VolumeCapabilities: []*csi.VolumeCapability{mountCap()},
+		}
+		if snapshotID != "" {
+			req.VolumeContentSource = &csi.VolumeContentSource{
+				Type: &csi.VolumeContentSource_Snapshot{
+					Snapshot: &csi.VolumeContentSource_SnapshotSource{SnapshotId: snapshotID},
+				},
+			}
+		}
+		return dv.CreateVolume(context.TODO(), req)
+	}
+
+	// The SPDK volume is a thin provisioned lvol of the lvol store
+	fs.ret["bdev_lvol_create"] = "lvs0/vol"
+	resp, err := createVolume("vol", utils.SpdkVolumeTypeName, 3<<19, "")
+	require.NoError(t, err)
+	volID := resp.GetVolume().GetVolumeId()
+
+	create := lastCall("bdev_lvol_create")
+	require.Equal(t, "lvs0", create.params["lvs_name"])
+	require.Equal(t, "vol", create.params["lvol_name"])
+	require.EqualValues(t, 2, create.params["size_in_mib"])
+	require.Equal(t, true, create.params["thin_provision"])
+
+	vol, err := dv.state.GetVolumeByID(volID)
+	require.NoError(t, err)
+	require.Equal(t, "lvs0/vol", vol.Metadata["bdevName"])
+	require.Empty(t, dv.getVolumeDataPath(vol))
+	require.False(t, dv.volumeHealth(vol).condition().GetAbnormal())
+
+	// and is snapshotted by SPDK, even while it is published
+	vol.Published.Add(filepath.Join(tmp, "target"))
+	require.NoError(t, dv.state.UpdateVolume(vol))
+
+	fs.ret["bdev_lvol_snapshot"] = "lvs0/snap"
+	snapResp, err := dv.CreateSnapshot(context.TODO(), &csi.CreateSnapshotRequest{
+		Name:           "snap",
+		SourceVolumeId: volID,
+	})
+	require.NoError(t, err)
+	snap := snapResp.GetSnapshot()
+	require.True(t, snap.GetReadyToUse())
+	require.Equal(t, "lvs0/vol", lastCall("bdev_lvol_snapshot").params["lvol_name"])
+
+	snapshot, err := dv.state.GetSnapshotByID(snap.GetSnapshotId())
+	require.NoError(t, err)
+	require.Equal(t, "lvs0/snap", snapshot.BdevName)
+	require.Empty(t, snapshot.Path)
+
+	// A volume restored from the snapshot is a clone of it, grown to the
+	// size of the volume
+	fs.ret["bdev_lvol_clone"] = "lvs0/clone"
+	cloneResp, err := createVolume("clone", utils.SpdkVolumeTypeName, 4<<20, snap.GetSnapshotId())
+	require.NoError(t, err)
+
+	clone := lastCall("bdev_lvol_clone")
+	require.Equal(t, "lvs0/snap", clone.params["snapshot_name"])
+	require.Equal(t, "clone", clone.params["clone_name"])
+	resize := lastCall("bdev_lvol_resize")
+	require.Equal(t, "lvs0/clone", resize.params["name"])
+	require.EqualValues(t, 4, resize.params["size_in_mib"])
+
+	cloneVol, err := dv.state.GetVolumeByID(cloneResp.GetVolume().GetVolumeId())
+	require.NoError(t, err)
+	require.Equal(t, snap.GetSnapshotId(), cloneVol.ParentSnapID)
+
+	// The lvol snapshot is not restored to a direct volume
+	_, err = createVolume("direct", utils.DirectVolumeTypeName, 4<<20, snap.GetSnapshotId())
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// The lvol is resized by SPDK when the volume is expanded
+	_, err = dv.ControllerExpandVolume(context.TODO(), &csi.ControllerExpandVolumeRequest{
+		VolumeId:      volID,
+		CapacityRange: &csi.CapacityRange{RequiredBytes: 8 << 20},
+	})
+	require.NoError(t, err)
+	resize = lastCall("bdev_lvol_resize")
+	require.Equal(t, "lvs0/vol", resize.params["name"])
+	require.EqualValues(t, 8, resize.params["size_in_mib"])
+
+	// Deleting the snapshot and the volumes deletes their lvols
+	_, err = dv.DeleteSnapshot(context.TODO(), &csi.DeleteSnapshotRequest{SnapshotId: snap.GetSnapshotId()})
+	require.NoError(t, err)
+	require.Equal(t, "lvs0/snap", lastCall("bdev_lvol_delete").params["name"])
+
+	_, err = dv.DeleteVolume(context.TODO(), &csi.DeleteVolumeRequest{VolumeId: cloneVol.VolID})
+	require.NoError(t, err)
+	require.Equal(t, "lvs0/clone", lastCall("bdev_lvol_delete").params["name"])
+
+	fs.err["bdev_lvol_delete"] = &spdkrpc.SpdkError{Code: spdkrpc.SpdkErrNoDevice, Message: "No such device"}
+	_, err = dv.DeleteVolume(context.TODO(), &csi.DeleteVolumeRequest{VolumeId: volID})
+	require.NoError(t, err)
+	_, err = dv.state.GetVolumeByID(volID)
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestExpandVolume(t *testing.T) {
+	tmp := t.TempDir()
+
+	oldDir := utils.SpdkRawDiskDir
+	utils.SpdkRawDiskDir = filepath.Join(tmp, "rawdisks")
+	defer func() { utils.SpdkRawDiskDir = oldDir }()
+
+	oldCall := spdkrpc.Call
+	fs := newFakeSpdk()
+	spdkrpc.Call = fs.fn
+	defer func() { spdkrpc.Call = oldCall }()
+
+	cfg := Config{
+		DriverName:    "directvolume.csi.katacontainers.io",
+		Endpoint:      "unix:///tmp/fake.sock",
+		NodeID:        "node-test",
+		StoragePath:   filepath.Join(tmp, "stor"),
+		StateDir:      filepath.Join(tmp, "st"),
+		MaxVolumeSize: 16 << 20,
+		SpdkRawPath:   filepath.Join(tmp, "spdk-raw"),
+		SpdkVhostPath: filepath.Join(tmp, "spdk-vhost"),
+	}
+	dv, err := NewDirectVolumeDriver(cfg)
+	require.NoError(t, err)
+
+	createVolume := func(name, volType string, size int64) string {
+		resp, err := dv.CreateVolume(context.TODO(), &csi.CreateVolumeRequest{
+			Name:          name,
+			CapacityRange: &csi.CapacityRange{RequiredBytes: size},
+			Parameters: map[string]string{
+				utils.KataContainersDirectVolumeType: volType,
+			},
+			VolumeCapabilities: []*csi.VolumeCapability{mountCap()},
+		})
+		require.NoError(t, err)
+		return resp.GetVolume().GetVolumeId()
+	}
+	expandVolume := func(volID string, size int64) (*csi.ControllerExpandVolumeResponse, error) {
+		return dv.ControllerExpandVolume(context.TODO(), &csi.ControllerExpandVolumeRequest{
+			VolumeId:      volID,
+			CapacityRange: &csi.CapacityRange{RequiredBytes: size},
+		})
+	}
+
+	// The raw disk of a staged direct volume is grown in place
+	volID := createVolume("vol", utils.DirectVolumeTypeName, 2<<20)
+	rawDisk := utils.GetDirectBlockDevicePath(cfg.StoragePath, volID, 2<<20)
+	require.NoError(t, os.MkdirAll(filepath.Dir(rawDisk), 0o750))
+	require.NoError(t, os.WriteFile(rawDisk, nil, 0o640))
+	require.NoError(t, os.Truncate(rawDisk, 2<<20))
+
+	resp, err := expandVolume(volID, 4<<20)
+	require.NoError(t, err)
+	require.EqualValues(t, 4<<20, resp.GetCapacityBytes())
+	require.True(t, resp.GetNodeExpansionRequired())
+
+	info, err := os.Stat(rawDisk)
+	require.NoError(t, err)
+	require.EqualValues(t, 4<<20, info.Size())
+
+	vol, err := dv.state.GetVolumeByID(volID)
+	require.NoError(t, err)
+	require.EqualValues(t, 4<<20, vol.VolSize)
+	require.Equal(t, rawDisk, vol.Metadata["devicePath"])
+	require.Equal(t, rawDisk, dv.getVolumeDataPath(vol))
+
+	// The volumes are never shrunk
+	resp, err = expandVolume(volID, 1<<20)
+	require.NoError(t, err)
+	require.EqualValues(t, 4<<20, resp.GetCapacityBytes())
+
+	_, err = expandVolume(volID, 32<<20)
+	require.Equal(t, codes.OutOfRange, status.Code(err))
+
+	_, err = expandVolume("missing", 4<<20)
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	// The SPDK bdev is rescanned once its backing file is grown
+	spdkVolID := createVolume("vol-spdk", utils.SpdkVolumeTypeName, 2<<20)
+	_, err = expandVolume(spdkVolID, 8<<20)
+	require.NoError(t, err)
+
+	info, err = os.Stat(filepath.Join(utils.SpdkRawDiskDir, "vol-spdk.raw"))
+	require.NoError(t, err)
+	require.EqualValues(t, 8<<20, info.Size())
+
+	last := fs.calls[len(fs.calls)-1]
+	require.Equal(t, "bdev_aio_rescan", last.method)
+	require.Equal(t, "bdev-vol-spdk", last.params["name"])
+}
+
+func TestListVolumes(t *testing.T) {
+	tmp := t.TempDir()
+
+	oldDir := utils.SpdkRawDiskDir
+	utils.SpdkRawDiskDir = filepath.Join(tmp, "rawdisks")
+	defer func() { utils.SpdkRawDiskDir = oldDir }()
+
+	oldCall := spdkrpc.Call
+	fs := newFakeSpdk()
+	spdkrpc.Call = fs.fn
+	defer func() { spdkrpc.Call = oldCall }()
+
+	cfg := Config{
+		DriverName:     "directvolume.csi.katacontainers.io",
+		Endpoint:       "unix:///tmp/fake.sock",
+		NodeID:         "node-test",
+		StoragePath:    filepath.Join(tmp, "stor"),
+		StateDir:       filepath.Join(tmp, "st"),
+		MaxVolumeSize:  1 << 40,
+		EnableTopology: true,
+		SpdkRawPath:    filepath.Join(tmp, "spdk-raw"),
+		SpdkVhostPath:  filepath.Join(tmp, "spdk-vhost"),
+	}
+	dv, err := NewDirectVolumeDriver(cfg)
+	require.NoError(t, err)
+
+	var volIDs []string
+	for _, volType := range []string{utils.DirectVolumeTypeName, utils.SpdkVolumeTypeName} {
+		resp, err := dv.CreateVolume(context.TODO(), &csi.CreateVolumeRequest{
+			Name:          "vol-" + volType,
+			CapacityRange: &csi.CapacityRange{RequiredBytes: 1 << 20},
+			Parameters: map[string]string{
+				utils.KataContainersDirectVolumeType: volType,
+			},
+			VolumeCapabilities: []*csi.VolumeCapability{mountCap()},
+		})
+		require.NoError(t, err)
+		volIDs = append(volIDs, resp.GetVolume().GetVolumeId())
+	}
+
+	list, err := dv.ListVolumes(context.TODO(), &csi.ListVolumesRequest{MaxEntries: 1})
+	require.NoError(t, err)
+	require.Len(t, list.GetEntries(), 1)
+	require.Equal(t, "1", list.GetNextToken())
+
+	entry := list.GetEntries()[0]
+	require.Equal(t, volIDs[0], entry.GetVolume().GetVolumeId())
+	require.EqualValues(t, 1<<20, entry.GetVolume().GetCapacityBytes())
+	require.Equal(t, "node-test", entry.GetVolume().GetAccessibleTopology()[0].GetSegments()[TopologyKeyNode])
+	require.Empty(t, entry.GetStatus().GetPublishedNodeIds())
+	// A direct volume which is not staged has no raw disk yet
+	require.False(t, entry.GetStatus().GetVolumeCondition().GetAbnormal())
+
+	list, err = dv.ListVolumes(context.TODO(), &csi.ListVolumesRequest{StartingToken: list.GetNextToken()})
+	require.NoError(t, err)
+	require.Len(t, list.GetEntries(), 1)
+	require.Equal(t, volIDs[1], list.GetEntries()[0].GetVolume().GetVolumeId())
+	require.False(t, list.GetEntries()[0].GetStatus().GetVolumeCondition().GetAbnormal())
+	require.Empty(t, list.GetNextToken())
+
+	_, err = dv.ListVolumes(context.TODO(), &csi.ListVolumesRequest{StartingToken: "3"})
+	require.Equal(t, codes.Aborted, status.Code(err))
+
+	// A published volume whose raw disk is missing is abnormal
+	vol, err := dv.state.GetVolumeByID(volIDs[0])
+	require.NoError(t, err)
+	vol.NodeID = "node-test"
+	vol.Staged.Add(filepath.Join(tmp, "stage"))
+	vol.Published.Add(filepath.Join(tmp, "target"))
+	require.NoError(t, dv.state.UpdateVolume(vol))
+
+	getResp, err := dv.ControllerGetVolume(context.TODO(), &csi.ControllerGetVolumeRequest{VolumeId: volIDs[0]})
+	require.NoError(t, err)
+	require.Equal(t, []string{"node-test"}, getResp.GetStatus().GetPublishedNodeIds())
+	require.True(t, getResp.GetStatus().GetVolumeCondition().GetAbnormal())
+
+	rawDisk := utils.GetDirectBlockDevicePath(cfg.StoragePath, volIDs[0], 1<<20)
+	require.NoError(t, os.MkdirAll(filepath.Dir(rawDisk), 0o750))
+	require.NoError(t, os.WriteFile(rawDisk, nil, 0o640))
+	require.NoError(t, os.Truncate(rawDisk, 1<<20))
+
+	getResp, err = dv.ControllerGetVolume(context.TODO(), &csi.ControllerGetVolumeRequest{VolumeId: volIDs[0]})
+	require.NoError(t, err)
+	require.False(t, getResp.GetStatus().GetVolumeCondition().GetAbnormal())
+
+	// and so is a SPDK volume whose bdev is missing
+	fs.err["bdev_get_bdevs"] = &spdkrpc.SpdkError{Code: spdkrpc.SpdkErrNoDevice, Message: "No such device"}
+	getResp, err = dv.ControllerGetVolume(context.TODO(), &csi.ControllerGetVolumeRequest{VolumeId: volIDs[1]})
+	require.NoError(t, err)
+	require.True(t, getResp.GetStatus().GetVolumeCondition().GetAbnormal())
+	require.Contains(t, getResp.GetStatus().GetVolumeCondition().GetMessage(), "bdev-vol-spdkvol")
+
+	_, err = dv.ControllerGetVolume(context.TODO(), &csi.ControllerGetVolumeRequest{VolumeId: "missing"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -20,6 +20,7 @@ import (
 	"kata-containers/csi-kata-directvolume/pkg/state"
 	"kata-containers/csi-kata-directvolume/pkg/utils"
 
+	"github.com/container-storage-interface/spec/lib/go/csi"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -60,6 +61,10 @@ type Config struct {
 	SpdkRPCTimeout time.Duration
 	SpdkRawPath    string
 	SpdkVhostPath  string
+	// SpdkLvstore is the SPDK lvol store the SPDK volumes are created
+	// in, the volumes are AIO bdevs of raw files otherwise. Only the
+	// volumes backed by lvols can be snapshotted.
+	SpdkLvstore string
 }
 
 func NewDirectVolumeDriver(cfg Config) (*directVolume, error) {
@@ -134,6 +139,47 @@ func (dv *directVolume) getVolumePath(volID string) string {
 	return filepath.Join(dv.config.StateDir, volID)
 }
 
+// getSnapshotPath returns the canonical path for the data of a snapshot
+func (dv *directVolume) getSnapshotPath(snapshotID string) string {
+	return filepath.Join(dv.config.StateDir, "snapshots", snapshotID+".raw")
+}
+
+// spdkBackingFilePath returns the path of the backing file of the SPDK bdev of
+// a volume.
+func spdkBackingFilePath(volName string) string {
+	return filepath.Join(utils.SpdkRawDiskDir, fmt.Sprintf("%s.raw", volName))
+}
+
+// isSPDKLvolVolume returns whether the volume is an SPDK volume backed by an
+// lvol.
+func isSPDKLvolVolume(vol state.Volume) bool {
+	return vol.Metadata["type"] == utils.SpdkVolumeTypeName && vol.Metadata["lvstore"] != ""
+}
+
+// spdkSizeInMiB returns the size of an lvol holding size bytes.
+func spdkSizeInMiB(size int64) int64 {
+	return (size + utils.MiB - 1) / utils.MiB
+}
+
+// getVolumeDataPath returns the raw disk holding the data of the volume. The
+// SPDK volumes backed by an lvol have none.
+func (dv *directVolume) getVolumeDataPath(vol state.Volume) string {
+	if isSPDKLvolVolume(vol) {
+		return ""
+	}
+	if vol.Metadata["type"] == utils.SpdkVolumeTypeName {
+		return vol.Metadata["backingFile"]
+	}
+	if devicePath, ok := dv.config.VolumeDevices[vol.VolID]; ok {
+		return devicePath
+	}
+	// The raw disk of an expanded volume keeps the name it was created with.
+	if devicePath := vol.Metadata["devicePath"]; devicePath != "" {
+		return devicePath
+	}
+	return utils.GetDirectBlockDevicePath(dv.config.StoragePath, vol.VolID, vol.VolSize)
+}
+
 // createVolume allocates capacity, creates the directory for the direct volume, and
 // adds the volume to the list.
 // It returns the volume path or err if one occurs. That error is suitable as result of a gRPC call.
@@ -201,6 +247,11 @@ func (dv *directVolume) deleteVolume(volID string) error {
 	if err := os.RemoveAll(path); err != nil && !os.IsNotExist(err) {
 		return err
 	}
+	// The raw disk of a volume restored from a snapshot exists before the
+	// volume is staged.
+	if err := os.RemoveAll(filepath.Join(dv.config.StoragePath, volID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
 	if err := dv.state.DeleteVolume(volID); err != nil {
 		return err
 	}
@@ -212,7 +263,7 @@ func (dv *directVolume) deleteVolume(volID string) error {
 // createSPDKVolume allocates backing file, creates the SPDK bdev, and adds the volume to the list.
 // It returns the created volume or an error if one occurs.
 func (dv *directVolume) createSPDKVolume(volumeID, volName string, capacity int64, kind string) (*state.Volume, error) {
-	backingFile := filepath.Join(utils.SpdkRawDiskDir, fmt.Sprintf("%s.raw", volName))
+	backingFile := spdkBackingFilePath(volName)
 
 	if err := os.MkdirAll(utils.SpdkRawDiskDir, 0750); err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create dir %s: %v", utils.SpdkRawDiskDir, err)
@@ -267,6 +318,219 @@ func (dv *directVolume) createSPDKVolume(volumeID, volName string, capacity int6
 	return vol, nil
 }
 
+// createSPDKLvolVolume creates the lvol of an SPDK volume in the lvol store,
+// cloned from the lvol snapshot snapshotID if any, and adds the volume to the
+// list.
+// It returns the created volume or an error suitable as result of a gRPC call.
+func (dv *directVolume) createSPDKLvolVolume(volumeID, volName string, capacity int64, kind, snapshotID string) (*state.Volume, error) {
+	var bdevName string
+	if snapshotID == "" {
+		params := map[string]any{
+			"lvs_name":       dv.config.SpdkLvstore,
+			"lvol_name":      volName,
+			"size_in_mib":    spdkSizeInMiB(capacity),
+			"thin_provision": true,
+		}
+		result, err := spdkrpc.Call("bdev_lvol_create", params)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "bdev_lvol_create failed: %v", err)
+		}
+		bdevName, _ = result.(string)
+	} else {
+		snapshot, err := dv.state.GetSnapshotByID(snapshotID)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot.BdevName == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "snapshot %v is not an SPDK lvol snapshot and cannot be restored to an lvol", snapshotID)
+		}
+		if snapshot.SizeBytes > capacity {
+			return nil, status.Errorf(codes.InvalidArgument, "snapshot %v size %v is greater than requested volume size %v", snapshotID, snapshot.SizeBytes, capacity)
+		}
+
+		result, err := spdkrpc.Call("bdev_lvol_clone", map[string]any{"snapshot_name": snapshot.BdevName, "clone_name": volName})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "bdev_lvol_clone of %s failed: %v", snapshot.BdevName, err)
+		}
+		bdevName, _ = result.(string)
+
+		if bdevName != "" && capacity > snapshot.SizeBytes {
+			if _, err := spdkrpc.Call("bdev_lvol_resize", map[string]any{"name": bdevName, "size_in_mib": spdkSizeInMiB(capacity)}); err != nil {
+				if delErr := spdkDeleteLvol(bdevName); delErr != nil {
+					klog.Warningf("[SPDK] failed to delete lvol %s: %v", bdevName, delErr)
+				}
+				return nil, status.Errorf(codes.Internal, "bdev_lvol_resize of %s failed: %v", bdevName, err)
+			}
+		}
+	}
+	if bdevName == "" {
+		return nil, status.Errorf(codes.Internal, "SPDK created no lvol for volume %s", volumeID)
+	}
+
+	vol := &state.Volume{
+		VolID:   volumeID,
+		VolName: volName,
+		VolSize: capacity,
+		Kind:    kind,
+		Metadata: map[string]string{
+			"type":     utils.SpdkVolumeTypeName,
+			"bdevName": bdevName,
+			"lvstore":  dv.config.SpdkLvstore,
+		},
+	}
+	if err := dv.state.UpdateVolume(*vol); err != nil {
+		if delErr := spdkDeleteLvol(bdevName); delErr != nil {
+			klog.Warningf("[SPDK] failed to delete lvol %s: %v", bdevName, delErr)
+		}
+		return nil, err
+	}
+
+	klog.Infof("[SPDK] volume %s registered lvol=%s lvstore=%s", volumeID, bdevName, dv.config.SpdkLvstore)
+	return vol, nil
+}
+
+// spdkDeleteLvol deletes an SPDK lvol or lvol snapshot, it is not an error
+// when it does not exist.
+func spdkDeleteLvol(bdevName string) error {
+	if _, err := spdkrpc.Call("bdev_lvol_delete", map[string]any{"name": bdevName}); err != nil {
+		if se, ok := err.(*spdkrpc.SpdkError); ok && se.Code == spdkrpc.SpdkErrNoDevice {
+			klog.Infof("[SPDK] lvol %s already absent, skip", bdevName)
+			return nil
+		}
+		return fmt.Errorf("bdev_lvol_delete(%s) failed: %w", bdevName, err)
+	}
+
+	klog.Infof("[SPDK] lvol %s deleted", bdevName)
+	return nil
+}
+
+// withSPDKNbdDevice runs fn with the SPDK bdev exported as an NBD device on the
+// host, the lvols have no host file to format or resize.
+func withSPDKNbdDevice(bdevName string, fn func(devicePath string) error) error {
+	result, err := spdkrpc.Call("nbd_start_disk", map[string]any{"bdev_name": bdevName})
+	if err != nil {
+		return fmt.Errorf("nbd_start_disk(%s) failed: %w", bdevName, err)
+	}
+	devicePath, _ := result.(string)
+	if devicePath == "" {
+		return fmt.Errorf("nbd_start_disk(%s) returned no NBD device: %v", bdevName, result)
+	}
+	defer func() {
+		if _, err := spdkrpc.Call("nbd_stop_disk", map[string]any{"nbd_device": devicePath}); err != nil {
+			klog.Warningf("[SPDK] nbd_stop_disk(%s) failed: %v", devicePath, err)
+		}
+	}()
+
+	return fn(devicePath)
+}
+
+// expandVolume grows the raw disk of the volume, the backing file of its
+// SPDK bdev or its SPDK lvol, to the capacity, and records the new size of the volume. A direct
+// volume which was never staged has no raw disk yet, it is created with the
+// new size when the volume is staged.
+// It returns an error suitable as result of a gRPC call.
+func (dv *directVolume) expandVolume(vol *state.Volume, capacity int64) error {
+	if dv.config.Capacity.Enabled() {
+		used := dv.sumVolumeSizes(vol.Kind)
+		available := dv.config.Capacity[vol.Kind]
+		if used-vol.VolSize+capacity > available.Value() {
+			return status.Errorf(codes.ResourceExhausted, "requested capacity %d exceeds remaining capacity for %q, %s out of %s already used",
+				capacity, vol.Kind, resource.NewQuantity(used, resource.BinarySI).String(), available.String())
+		}
+	}
+
+	if isSPDKLvolVolume(*vol) {
+		bdevName := vol.Metadata["bdevName"]
+		if _, err := spdkrpc.Call("bdev_lvol_resize", map[string]any{"name": bdevName, "size_in_mib": spdkSizeInMiB(capacity)}); err != nil {
+			return status.Errorf(codes.Internal, "bdev_lvol_resize of %s failed: %v", bdevName, err)
+		}
+		klog.Infof("[SPDK] lvol %s resized for volume %s", bdevName, vol.VolID)
+
+		vol.VolSize = capacity
+		return dv.state.UpdateVolume(*vol)
+	}
+
+	isSpdkVolume := vol.Metadata["type"] == utils.SpdkVolumeTypeName
+	dataPath := dv.getVolumeDataPath(*vol)
+	if _, err := os.Stat(dataPath); err == nil {
+		if err := os.Truncate(dataPath, capacity); err != nil {
+			return status.Errorf(codes.Internal, "failed to grow raw disk %s: %v", dataPath, err)
+		}
+		if !isSpdkVolume {
+			if vol.Metadata == nil {
+				vol.Metadata = map[string]string{}
+			}
+			vol.Metadata["devicePath"] = dataPath
+		}
+	} else if !os.IsNotExist(err) {
+		return status.Errorf(codes.Internal, "stat raw disk %s failed: %v", dataPath, err)
+	}
+
+	if isSpdkVolume {
+		bdevName := vol.Metadata["bdevName"]
+		if _, err := spdkrpc.Call("bdev_aio_rescan", map[string]any{"name": bdevName}); err != nil {
+			return status.Errorf(codes.Internal, "bdev_aio_rescan of %s failed: %v", bdevName, err)
+		}
+		klog.Infof("[SPDK] bdev %s rescanned for volume %s", bdevName, vol.VolID)
+	}
+
+	vol.VolSize = capacity
+	return dv.state.UpdateVolume(*vol)
+}
+
+// volumeHealth is what the condition of a volume is checked from. It is
+// copied from the driver state, so that the condition can be checked
+// without holding the driver lock.
+type volumeHealth struct {
+	dataPath string
+	// bdevName is the SPDK bdev of the volume, if any.
+	bdevName string
+	isSpdk   bool
+	staged   bool
+	size     int64
+}
+
+// volumeHealth returns what the condition of the volume is checked from. The
+// driver lock must be held.
+func (dv *directVolume) volumeHealth(vol state.Volume) volumeHealth {
+	return volumeHealth{
+		dataPath: dv.getVolumeDataPath(vol),
+		bdevName: vol.Metadata["bdevName"],
+		isSpdk:   vol.Metadata["type"] == utils.SpdkVolumeTypeName,
+		staged:   !vol.Staged.Empty(),
+		size:     vol.VolSize,
+	}
+}
+
+// condition checks the raw disk of the volume, or the backing file and the
+// bdev of its SPDK volume, on the host. A direct volume which is not staged
+// has no raw disk yet.
+func (h volumeHealth) condition() *csi.VolumeCondition {
+	abnormal := func(format string, args ...any) *csi.VolumeCondition {
+		return &csi.VolumeCondition{Abnormal: true, Message: fmt.Sprintf(format, args...)}
+	}
+
+	// The SPDK lvols have no raw disk on the host.
+	if h.dataPath != "" {
+		info, err := os.Stat(h.dataPath)
+		if err != nil {
+			if !os.IsNotExist(err) || h.isSpdk || h.staged {
+				return abnormal("raw disk %s of the volume is not available: %v", h.dataPath, err)
+			}
+		} else if info.Size() < h.size {
+			return abnormal("raw disk %s of the volume is smaller than the volume: %d < %d bytes", h.dataPath, info.Size(), h.size)
+		}
+	}
+
+	if h.isSpdk {
+		if _, err := spdkrpc.Call("bdev_get_bdevs", map[string]any{"name": h.bdevName}); err != nil {
+			return abnormal("SPDK bdev %s of the volume is not available: %v", h.bdevName, err)
+		}
+	}
+
+	return &csi.VolumeCondition{Abnormal: false, Message: "OK"}
+}
+
 func (dv *directVolume) sumVolumeSizes(kind string) (sum int64) {
 	for _, volume := range dv.state.GetVolumes() {
 		if volume.Kind == kind {
@@ -309,3 +573,134 @@ func loadFromPersitStorage(directVolume state.Volume, destPath string) error {
 	}
 	return nil
 }
+
+// createSnapshot snapshots the lvol of an SPDK volume, or copies the raw disk
+// of a direct volume, and adds the snapshot to the list. A direct volume
+// which was never staged has no raw disk yet, its snapshot has no data.
+// It returns the created snapshot or an error suitable as result of a gRPC call.
+func (dv *directVolume) createSnapshot(snapshotID, name string, vol state.Volume) (*state.Snapshot, error) {
+	snapshot := state.Snapshot{
+		Name:         name,
+		ID:           snapshotID,
+		VolID:        vol.VolID,
+		CreationTime: time.Now(),
+		SizeBytes:    vol.VolSize,
+		ReadyToUse:   true,
+	}
+
+	if vol.Metadata["type"] == utils.SpdkVolumeTypeName {
+		// The AIO bdev of an SPDK volume cannot be quiesced, SPDK only
+		// snapshots the lvols consistently.
+		if !isSPDKLvolVolume(vol) {
+			return nil, status.Errorf(codes.FailedPrecondition, "SPDK volume %s is not backed by an lvol and cannot be snapshotted", vol.VolID)
+		}
+
+		bdevName := vol.Metadata["bdevName"]
+		result, err := spdkrpc.Call("bdev_lvol_snapshot", map[string]any{"lvol_name": bdevName, "snapshot_name": "snapshot-" + snapshotID})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "bdev_lvol_snapshot of %s failed: %v", bdevName, err)
+		}
+		if snapshot.BdevName, _ = result.(string); snapshot.BdevName == "" {
+			return nil, status.Errorf(codes.Internal, "bdev_lvol_snapshot of %s returned no snapshot: %v", bdevName, result)
+		}
+		klog.Infof("[SPDK] lvol snapshot %s created for volume %s", snapshot.BdevName, vol.VolID)
+	} else {
+		dataPath := dv.getVolumeDataPath(vol)
+		if _, err := os.Stat(dataPath); err == nil {
+			snapshot.Path = dv.getSnapshotPath(snapshotID)
+			// The raw disk of a published volume is written to
+			// while it is copied, only a reflink captures it at
+			// once.
+			copyBlockFile := utils.CopyBlockFile
+			if !vol.Published.Empty() {
+				copyBlockFile = utils.ReflinkBlockFile
+			}
+			if err := copyBlockFile(dataPath, snapshot.Path); err != nil {
+				if !vol.Published.Empty() {
+					return nil, status.Errorf(codes.FailedPrecondition, "cannot snapshot volume %s while it is published: %v", vol.VolID, err)
+				}
+				return nil, status.Errorf(codes.Internal, "failed to snapshot volume %s: %v", vol.VolID, err)
+			}
+		} else if !os.IsNotExist(err) {
+			return nil, status.Errorf(codes.Internal, "stat raw disk %s failed: %v", dataPath, err)
+		}
+	}
+
+	klog.Infof("adding snapshot: %s = %+v", snapshotID, snapshot)
+	if err := dv.state.UpdateSnapshot(snapshot); err != nil {
+		removeSnapshotData(snapshot.Path)
+		if snapshot.BdevName != "" {
+			if delErr := spdkDeleteLvol(snapshot.BdevName); delErr != nil {
+				klog.Warningf("[SPDK] failed to delete lvol snapshot %s: %v", snapshot.BdevName, delErr)
+			}
+		}
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+// deleteSnapshot deletes the data of the snapshot and removes it from the list.
+func (dv *directVolume) deleteSnapshot(snapshotID string) error {
+	snapshot, err := dv.state.GetSnapshotByID(snapshotID)
+	if err != nil {
+		klog.Warning("deleteSnapshot with Snapshot not found.")
+		// Return OK if the snapshot is not found.
+		return nil
+	}
+
+	if snapshot.Path != "" {
+		if err := os.Remove(snapshot.Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if snapshot.BdevName != "" {
+		if err := spdkDeleteLvol(snapshot.BdevName); err != nil {
+			return err
+		}
+	}
+	return dv.state.DeleteSnapshot(snapshotID)
+}
+
+// loadFromSnapshot restores the data of the snapshot to the raw disk destPath,
+// grown to the requested size.
+func (dv *directVolume) loadFromSnapshot(size int64, snapshotID, destPath string) error {
+	snapshot, err := dv.state.GetSnapshotByID(snapshotID)
+	if err != nil {
+		klog.Error("loadFromSnapshot failed with get snapshot by ID error Snapshot not found")
+		return err
+	}
+	if snapshot.SizeBytes > size {
+		return status.Errorf(codes.InvalidArgument, "snapshot %v size %v is greater than requested volume size %v", snapshotID, snapshot.SizeBytes, size)
+	}
+
+	if snapshot.BdevName != "" {
+		return status.Errorf(codes.InvalidArgument, "snapshot %v of an SPDK lvol can only be restored to an SPDK volume backed by an lvol", snapshotID)
+	}
+
+	// A snapshot without data restores an empty volume
+	if snapshot.Path == "" {
+		return nil
+	}
+
+	if err := utils.CopyBlockFile(snapshot.Path, destPath); err != nil {
+		return status.Errorf(codes.Internal, "failed to restore snapshot %v: %v", snapshotID, err)
+	}
+	if err := os.Truncate(destPath, size); err != nil {
+		removeSnapshotData(destPath)
+		return status.Errorf(codes.Internal, "failed to resize restored snapshot %v: %v", snapshotID, err)
+	}
+
+	return nil
+}
+
+// removeSnapshotData removes the data restored from a snapshot for a volume
+// which could not be created.
+func removeSnapshotData(path string) {
+	if path == "" {
+		return
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		klog.Warningf("failed to remove restored snapshot data %s: %v", path, err)
+	}
+}
@@ -47,6 +47,13 @@ func (dv *directVolume) GetPluginCapabilities(ctx context.Context, req *csi.GetP
 				},
 			},
 		},
+		{
+			Type: &csi.PluginCapability_VolumeExpansion_{
+				VolumeExpansion: &csi.PluginCapability_VolumeExpansion{
+					Type: csi.PluginCapability_VolumeExpansion_ONLINE,
+				},
+			},
+		},
 	}
 	if dv.config.EnableTopology {
 		caps = append(caps, &csi.PluginCapability{
@@ -347,18 +347,142 @@ func (dv *directVolume) NodeGetCapabilities(ctx context.Context, req *csi.NodeGe
 				},
 			},
 		},
+		{
+			Type: &csi.NodeServiceCapability_Rpc{
+				Rpc: &csi.NodeServiceCapability_RPC{
+					Type: csi.NodeServiceCapability_RPC_EXPAND_VOLUME,
+				},
+			},
+		},
+		{
+			Type: &csi.NodeServiceCapability_Rpc{
+				Rpc: &csi.NodeServiceCapability_RPC{
+					Type: csi.NodeServiceCapability_RPC_GET_VOLUME_STATS,
+				},
+			},
+		},
+		{
+			Type: &csi.NodeServiceCapability_Rpc{
+				Rpc: &csi.NodeServiceCapability_RPC{
+					Type: csi.NodeServiceCapability_RPC_VOLUME_CONDITION,
+				},
+			},
+		},
 	}
 
 	return &csi.NodeGetCapabilitiesResponse{Capabilities: caps}, nil
 }
 
-func (dv *directVolume) NodeGetVolumeStats(ctx context.Context, in *csi.NodeGetVolumeStatsRequest) (*csi.NodeGetVolumeStatsResponse, error) {
-	return &csi.NodeGetVolumeStatsResponse{}, nil
+// The sandbox using a volume and the stats of the volume in the guest, they
+// can be faked in tests.
+var (
+	getSandboxIDForVolume = utils.GetSandboxIDForVolume
+	guestVolumeStats      = utils.GuestVolumeStats
+)
+
+func (dv *directVolume) NodeGetVolumeStats(ctx context.Context, req *csi.NodeGetVolumeStatsRequest) (*csi.NodeGetVolumeStatsResponse, error) {
+	volumeID := req.GetVolumeId()
+	if len(volumeID) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Volume ID missing in request")
+	}
+	volumePath := req.GetVolumePath()
+	if len(volumePath) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Volume path missing in request")
+	}
+
+	// The shim and SPDK are queried without the driver lock, the other
+	// volumes do not have to wait for them.
+	dv.mutex.Lock()
+	vol, err := dv.state.GetVolumeByID(volumeID)
+	if err != nil {
+		dv.mutex.Unlock()
+		return nil, err
+	}
+	health := dv.volumeHealth(vol)
+	dv.mutex.Unlock()
+
+	resp := &csi.NodeGetVolumeStatsResponse{
+		VolumeCondition: health.condition(),
+	}
+
+	// The usage of a volume is only known while a sandbox uses it, it is
+	// reported by the guest, as well as the condition of the volume in the
+	// guest.
+	sandboxID, err := getSandboxIDForVolume(volumePath)
+	if err != nil {
+		return resp, nil
+	}
+	stats, err := guestVolumeStats(sandboxID, volumePath)
+	if err != nil {
+		// The volume is unusable if the guest cannot be reached.
+		if !resp.VolumeCondition.Abnormal {
+			resp.VolumeCondition = &csi.VolumeCondition{
+				Abnormal: true,
+				Message:  fmt.Sprintf("get stats of volume in sandbox %s failed: %v", sandboxID, err),
+			}
+		}
+		return resp, nil
+	}
+
+	for _, usage := range stats.Usage {
+		resp.Usage = append(resp.Usage, &csi.VolumeUsage{
+			Available: int64(usage.Available),
+			Total:     int64(usage.Total),
+			Used:      int64(usage.Used),
+			Unit:      csi.VolumeUsage_Unit(usage.Unit),
+		})
+	}
+	if guestCondition := stats.VolumeCondition; guestCondition != nil && guestCondition.Abnormal && !resp.VolumeCondition.Abnormal {
+		resp.VolumeCondition = &csi.VolumeCondition{Abnormal: true, Message: guestCondition.Message}
+	}
+
+	return resp, nil
 }
 
 func (dv *directVolume) NodeExpandVolume(ctx context.Context, req *csi.NodeExpandVolumeRequest) (*csi.NodeExpandVolumeResponse, error) {
+	klog.V(4).Infof("node expand volume with request %v", req)
+
+	volumeID := req.GetVolumeId()
+	if len(volumeID) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Volume ID missing in request")
+	}
+	volumePath := req.GetVolumePath()
+	if len(volumePath) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Volume path missing in request")
+	}
+
+	dv.mutex.Lock()
+	defer dv.mutex.Unlock()
 
-	return &csi.NodeExpandVolumeResponse{}, nil
+	vol, err := dv.state.GetVolumeByID(volumeID)
+	if err != nil {
+		return nil, err
+	}
+	if capacity := req.GetCapacityRange().GetRequiredBytes(); capacity > vol.VolSize {
+		return nil, status.Errorf(codes.FailedPrecondition, "volume %q is not expanded to %d bytes yet", volumeID, capacity)
+	}
+
+	// A volume used by a sandbox is resized online in the guest, otherwise
+	// its filesystem is grown on the host before a guest mounts it.
+	if sandboxID, err := utils.GetSandboxIDForVolume(volumePath); err == nil {
+		if err := utils.ResizeGuestVolume(sandboxID, volumePath, uint64(vol.VolSize)); err != nil {
+			return nil, status.Errorf(codes.Internal, "resize volume %q in sandbox %s failed: %v", volumeID, sandboxID, err)
+		}
+		klog.Infof("directvolume: volume %s has been resized in sandbox %s.", volumePath, sandboxID)
+	} else {
+		var err error
+		if isSPDKLvolVolume(vol) {
+			err = withSPDKNbdDevice(vol.Metadata["bdevName"], dv.config.safeMounter.ResizeOffline)
+		} else {
+			err = dv.config.safeMounter.ResizeOffline(dv.getVolumeDataPath(vol))
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "resize volume %q failed: %v", volumeID, err)
+		}
+		klog.Infof("directvolume: volume %s has been resized on the host.", volumePath)
+	}
+
+	return &csi.NodeExpandVolumeResponse{CapacityBytes: vol.VolSize}, nil
 }
 
 func (dv *directVolume) stageSPDKVolume(req *csi.NodeStageVolumeRequest, volumeID string) (*csi.NodeStageVolumeResponse, error) {
@@ -369,19 +493,30 @@ func (dv *directVolume) stageSPDKVolume(req *csi.NodeStageVolumeRequest, volumeI
 
 	bdevName := vol.Metadata["bdevName"]
 	backingFile := vol.Metadata["backingFile"]
-	if bdevName == "" || backingFile == "" {
+	isLvol := isSPDKLvolVolume(vol)
+	if bdevName == "" || (backingFile == "" && !isLvol) {
 		return nil, status.Errorf(codes.InvalidArgument, "missing bdevName/backingFile for volume %s", volumeID)
 	}
 
-	// backing file format
+	// backing file format, an lvol is formatted through an NBD device
 	fsType := req.VolumeContext[utils.KataContainersDirectFsType]
 	if fsType == "" {
 		fsType = utils.DefaultFsType
 	}
-	if err := dv.config.safeMounter.SafeFormatWithFstype(backingFile, fsType, []string{}); err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to format %s: %v", backingFile, err)
+	format := func(devicePath string) error {
+		return dv.config.safeMounter.SafeFormatWithFstype(devicePath, fsType, []string{})
+	}
+	if isLvol {
+		if err := withSPDKNbdDevice(bdevName, format); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to format lvol %s: %v", bdevName, err)
+		}
+		klog.Infof("[SPDK] formatted lvol %s with fsType=%s", bdevName, fsType)
+	} else {
+		if err := format(backingFile); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to format %s: %v", backingFile, err)
+		}
+		klog.Infof("[SPDK] formatted %s with fsType=%s", backingFile, fsType)
 	}
-	klog.Infof("[SPDK] formatted %s with fsType=%s", backingFile, fsType)
 
 	// vhost-blk controller
 	ctrlrName := fmt.Sprintf("vhost-%s", volumeID[:8])
@@ -426,10 +561,25 @@ func (dv *directVolume) stageSPDKVolume(req *csi.NodeStageVolumeRequest, volumeI
 
 func (dv *directVolume) stageDirectVolume(req *csi.NodeStageVolumeRequest, volumeID string) (*csi.NodeStageVolumeResponse, error) {
 	capacityInBytes := req.VolumeContext[utils.CapabilityInBytes]
-	devicePath, err := utils.CreateDirectBlockDevice(volumeID, capacityInBytes, dv.config.StoragePath)
-	if err != nil {
-		errMsg := status.Errorf(codes.Internal, "setup storage for volume '%s' failed", volumeID)
-		return &csi.NodeStageVolumeResponse{}, errMsg
+	var devicePath *string
+	// The volume context keeps the capacity the volume was created with:
+	// the raw disk of an expanded volume keeps its name, and a volume
+	// expanded before it is staged is created with its current size.
+	if vol, err := dv.state.GetVolumeByID(volumeID); err == nil {
+		if dp := vol.Metadata["devicePath"]; dp != "" {
+			if _, err := os.Stat(dp); err == nil {
+				devicePath = &dp
+			}
+		}
+		capacityInBytes = strconv.FormatInt(vol.VolSize, 10)
+	}
+	if devicePath == nil {
+		var err error
+		devicePath, err = utils.CreateDirectBlockDevice(volumeID, capacityInBytes, dv.config.StoragePath)
+		if err != nil {
+			errMsg := status.Errorf(codes.Internal, "setup storage for volume '%s' failed", volumeID)
+			return &csi.NodeStageVolumeResponse{}, errMsg
+		}
 	}
 
 	// /full_path_on_host/VolumeId/
@@ -487,6 +637,10 @@ func (dv *directVolume) stageDirectVolume(req *csi.NodeStageVolumeRequest, volum
 	}
 
 	volInStat.Staged.Add(stagingTargetPath)
+	if volInStat.Metadata == nil {
+		volInStat.Metadata = map[string]string{}
+	}
+	volInStat.Metadata["devicePath"] = *devicePath
 	if err := dv.state.UpdateVolume(volInStat); err != nil {
 		return nil, err
 	}
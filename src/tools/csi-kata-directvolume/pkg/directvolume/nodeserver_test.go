@@ -7,6 +7,7 @@ package directvolume
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -192,3 +193,129 @@ func TestSPDKStage(t *testing.T) {
 	require.Contains(t, callsJoined.String(), "vhost_get_controllers",
 		"should call vhost_get_controllers at least once")
 }
+
+func TestNodeExpandVolumeAndStats(t *testing.T) {
+	tmp := t.TempDir()
+
+	oldRaw := utils.SpdkRawDiskDir
+	utils.SpdkRawDiskDir = filepath.Join(tmp, "rawdisks")
+	defer func() { utils.SpdkRawDiskDir = oldRaw }()
+
+	oldCall := spdkrpc.Call
+	fs := newFakeSpdkNS()
+	spdkrpc.Call = fs.fn
+	defer func() { spdkrpc.Call = oldCall }()
+
+	cfg := Config{
+		DriverName:    "directvolume.csi.katacontainers.io",
+		Endpoint:      "unix:///tmp/fake.sock",
+		NodeID:        "node-test",
+		StoragePath:   filepath.Join(tmp, "stor"),
+		StateDir:      filepath.Join(tmp, "st"),
+		MaxVolumeSize: 1 << 40,
+		SpdkRawPath:   filepath.Join(tmp, "spdk-raw"),
+		SpdkVhostPath: filepath.Join(tmp, "spdk-vhost"),
+	}
+	dv, err := NewDirectVolumeDriver(cfg)
+	require.NoError(t, err)
+
+	createResp, err := dv.CreateVolume(context.TODO(), &csi.CreateVolumeRequest{
+		Name:          "vol-spdk",
+		CapacityRange: &csi.CapacityRange{RequiredBytes: 8 << 20},
+		Parameters: map[string]string{
+			utils.KataContainersDirectVolumeType: utils.SpdkVolumeTypeName,
+			utils.KataContainersDirectFsType:     "ext4",
+		},
+		VolumeCapabilities: []*csi.VolumeCapability{mountCap()},
+	})
+	require.NoError(t, err)
+	volID := createResp.GetVolume().GetVolumeId()
+
+	stagePath := filepath.Join(tmp, "stage", volID)
+	require.NoError(t, os.MkdirAll(stagePath, 0o750))
+	_, err = dv.NodeStageVolume(context.TODO(), &csi.NodeStageVolumeRequest{
+		VolumeId:          volID,
+		StagingTargetPath: stagePath,
+		VolumeCapability:  mountCap(),
+		VolumeContext:     createResp.GetVolume().GetVolumeContext(),
+	})
+	require.NoError(t, err)
+
+	nodeExpand := func(size int64) (*csi.NodeExpandVolumeResponse, error) {
+		return dv.NodeExpandVolume(context.TODO(), &csi.NodeExpandVolumeRequest{
+			VolumeId:      volID,
+			VolumePath:    filepath.Join(tmp, "target", volID),
+			CapacityRange: &csi.CapacityRange{RequiredBytes: size},
+		})
+	}
+
+	// The node does not expand a volume the controller did not
+	_, err = nodeExpand(16 << 20)
+	require.Error(t, err)
+
+	_, err = dv.ControllerExpandVolume(context.TODO(), &csi.ControllerExpandVolumeRequest{
+		VolumeId:      volID,
+		CapacityRange: &csi.CapacityRange{RequiredBytes: 16 << 20},
+	})
+	require.NoError(t, err)
+
+	// A volume used by no sandbox has its filesystem grown on the host
+	resp, err := nodeExpand(16 << 20)
+	require.NoError(t, err)
+	require.EqualValues(t, 16<<20, resp.GetCapacityBytes())
+
+	_, err = dv.NodeExpandVolume(context.TODO(), &csi.NodeExpandVolumeRequest{VolumeId: volID})
+	require.Error(t, err)
+
+	// The usage of a volume used by no sandbox is unknown
+	stats, err := dv.NodeGetVolumeStats(context.TODO(), &csi.NodeGetVolumeStatsRequest{
+		VolumeId:   volID,
+		VolumePath: filepath.Join(tmp, "target", volID),
+	})
+	require.NoError(t, err)
+	require.Empty(t, stats.GetUsage())
+	require.False(t, stats.GetVolumeCondition().GetAbnormal())
+
+	// The stats of a volume used by a sandbox come from its guest, the
+	// shim being queried without the driver lock
+	oldGetSandboxID, oldGuestStats := getSandboxIDForVolume, guestVolumeStats
+	defer func() { getSandboxIDForVolume, guestVolumeStats = oldGetSandboxID, oldGuestStats }()
+	getSandboxIDForVolume = func(string) (string, error) { return "sandbox", nil }
+	guestVolumeStats = func(sandboxID, volumePath string) (*utils.VolumeStats, error) {
+		if !dv.mutex.TryLock() {
+			return nil, errors.New("driver locked")
+		}
+		dv.mutex.Unlock()
+		return &utils.VolumeStats{Usage: []utils.VolumeUsage{{Available: 3, Total: 4, Used: 1, Unit: 1}}}, nil
+	}
+	stats, err = dv.NodeGetVolumeStats(context.TODO(), &csi.NodeGetVolumeStatsRequest{
+		VolumeId:   volID,
+		VolumePath: filepath.Join(tmp, "target", volID),
+	})
+	require.NoError(t, err)
+	require.Len(t, stats.GetUsage(), 1)
+	require.EqualValues(t, 4, stats.GetUsage()[0].GetTotal())
+	require.False(t, stats.GetVolumeCondition().GetAbnormal())
+
+	// A volume whose guest cannot be reached is abnormal
+	guestVolumeStats = func(sandboxID, volumePath string) (*utils.VolumeStats, error) {
+		return nil, errors.New("no shim management socket found")
+	}
+	stats, err = dv.NodeGetVolumeStats(context.TODO(), &csi.NodeGetVolumeStatsRequest{
+		VolumeId:   volID,
+		VolumePath: filepath.Join(tmp, "target", volID),
+	})
+	require.NoError(t, err)
+	require.Empty(t, stats.GetUsage())
+	require.True(t, stats.GetVolumeCondition().GetAbnormal())
+	require.Contains(t, stats.GetVolumeCondition().GetMessage(), "no shim management socket found")
+	getSandboxIDForVolume = oldGetSandboxID
+
+	require.NoError(t, os.Remove(filepath.Join(utils.SpdkRawDiskDir, "vol-spdk.raw")))
+	stats, err = dv.NodeGetVolumeStats(context.TODO(), &csi.NodeGetVolumeStatsRequest{
+		VolumeId:   volID,
+		VolumePath: filepath.Join(tmp, "target", volID),
+	})
+	require.NoError(t, err)
+	require.True(t, stats.GetVolumeCondition().GetAbnormal())
+}
@@ -13,6 +13,7 @@ import (
 	"encoding/json"
 	"errors"
 	"os"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -41,6 +42,21 @@ type Volume struct {
 	Metadata map[string]string
 }
 
+type Snapshot struct {
+	Name  string
+	ID    string
+	VolID string
+	// Path is the file holding the data of the snapshot, empty when
+	// the volume had no data yet.
+	Path string
+	// BdevName is the SPDK lvol snapshot of an SPDK volume backed by
+	// an lvol.
+	BdevName     string
+	CreationTime time.Time
+	SizeBytes    int64
+	ReadyToUse   bool
+}
+
 // State is the interface that the rest of the code has to use to
 // access and change state. All error messages contain gRPC
 // status codes and can be returned without wrapping.
@@ -65,10 +81,32 @@ type State interface {
 	// volume ID. It is not an error when such a volume
 	// does not exist.
 	DeleteVolume(volID string) error
+
+	// GetSnapshotByID retrieves a snapshot by its unique ID or returns
+	// an error including that ID when not found.
+	GetSnapshotByID(snapshotID string) (Snapshot, error)
+
+	// GetSnapshotByName retrieves a snapshot by its name or returns
+	// an error including that name when not found.
+	GetSnapshotByName(name string) (Snapshot, error)
+
+	// GetSnapshots returns all currently existing snapshots.
+	GetSnapshots() []Snapshot
+
+	// UpdateSnapshot updates the existing snapshot,
+	// identified by its snapshot ID, or adds it if it does
+	// not exist yet.
+	UpdateSnapshot(snapshot Snapshot) error
+
+	// DeleteSnapshot deletes the snapshot with the given
+	// snapshot ID. It is not an error when such a snapshot
+	// does not exist.
+	DeleteSnapshot(snapshotID string) error
 }
 
 type resources struct {
-	Volumes []Volume
+	Volumes   []Volume
+	Snapshots []Snapshot
 }
 
 type state struct {
@@ -103,6 +141,7 @@ func (s *state) dump() error {
 
 func (s *state) restore() error {
 	s.Volumes = nil
+	s.Snapshots = nil
 	data, err := os.ReadFile(s.statefilePath)
 	switch {
 	case errors.Is(err, os.ErrNotExist):
@@ -161,3 +200,48 @@ func (s *state) DeleteVolume(volID string) error {
 	}
 	return nil
 }
+
+func (s *state) GetSnapshotByID(snapshotID string) (Snapshot, error) {
+	for _, snapshot := range s.Snapshots {
+		if snapshot.ID == snapshotID {
+			return snapshot, nil
+		}
+	}
+	return Snapshot{}, status.Errorf(codes.NotFound, "snapshot id %s does not exist in the snapshots list", snapshotID)
+}
+
+func (s *state) GetSnapshotByName(name string) (Snapshot, error) {
+	for _, snapshot := range s.Snapshots {
+		if snapshot.Name == name {
+			return snapshot, nil
+		}
+	}
+	return Snapshot{}, status.Errorf(codes.NotFound, "snapshot name %s does not exist in the snapshots list", name)
+}
+
+func (s *state) GetSnapshots() []Snapshot {
+	snapshots := make([]Snapshot, len(s.Snapshots))
+	copy(snapshots, s.Snapshots)
+	return snapshots
+}
+
+func (s *state) UpdateSnapshot(update Snapshot) error {
+	for i, snapshot := range s.Snapshots {
+		if snapshot.ID == update.ID {
+			s.Snapshots[i] = update
+			return s.dump()
+		}
+	}
+	s.Snapshots = append(s.Snapshots, update)
+	return s.dump()
+}
+
+func (s *state) DeleteSnapshot(snapshotID string) error {
+	for i, snapshot := range s.Snapshots {
+		if snapshot.ID == snapshotID {
+			s.Snapshots = append(s.Snapshots[:i], s.Snapshots[i+1:]...)
+			return s.dump()
+		}
+	}
+	return nil
+}
@@ -46,3 +46,35 @@ func TestVolumes(t *testing.T) {
 
 	require.Empty(t, s.GetVolumes(), "final volumes")
 }
+
+func TestSnapshots(t *testing.T) {
+	tmp := t.TempDir()
+	statefileName := path.Join(tmp, "state.json")
+
+	s, err := New(statefileName)
+	require.NoError(t, err, "construct state")
+	require.Empty(t, s.GetSnapshots(), "initial snapshots")
+
+	_, err = s.GetSnapshotByID("foo")
+	require.Equal(t, codes.NotFound, status.Convert(err).Code(), "GetSnapshotByID of non-existent snapshot")
+	require.Contains(t, status.Convert(err).Message(), "foo")
+
+	err = s.UpdateSnapshot(Snapshot{ID: "foo", Name: "bar", VolID: "vol"})
+	require.NoError(t, err, "add snapshot")
+
+	s, err = New(statefileName)
+	require.NoError(t, err, "reconstruct state")
+	snapshot, err := s.GetSnapshotByID("foo")
+	require.NoError(t, err, "get existing snapshot by ID")
+	require.Equal(t, "vol", snapshot.VolID)
+	_, err = s.GetSnapshotByName("bar")
+	require.NoError(t, err, "get existing snapshot by name")
+
+	err = s.DeleteSnapshot("foo")
+	require.NoError(t, err, "delete existing snapshot")
+
+	err = s.DeleteSnapshot("foo")
+	require.NoError(t, err, "delete non-existent snapshot")
+
+	require.Empty(t, s.GetSnapshots(), "final snapshots")
+}
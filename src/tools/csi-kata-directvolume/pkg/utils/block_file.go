@@ -0,0 +1,99 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+	"k8s.io/klog/v2"
+)
+
+// copyChunkSize is the size of the chunks of a sparse copy, the chunks full
+// of zeroes are left as holes.
+const copyChunkSize = MiB
+
+// CopyBlockFile copies the raw disk src to dst, sharing its blocks with a
+// reflink when the filesystem supports it, or with a sparse copy otherwise.
+// dst is replaced only once the copy completed.
+func CopyBlockFile(src, dst string) error {
+	return copyBlockFile(src, dst, true)
+}
+
+// ReflinkBlockFile copies the raw disk src to dst with a reflink, which
+// captures src at once even while it is written to. It fails when the
+// filesystem does not support reflinks.
+func ReflinkBlockFile(src, dst string) error {
+	return copyBlockFile(src, dst, false)
+}
+
+func copyBlockFile(src, dst string, sparse bool) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), PERM); err != nil {
+		return err
+	}
+
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0640)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+
+	if err := unix.IoctlFileClone(int(out.Fd()), int(in.Fd())); err != nil {
+		if !sparse {
+			out.Close()
+			return fmt.Errorf("reflink %s to %s: %w", src, dst, err)
+		}
+		klog.V(4).Infof("reflink of %s failed, doing a sparse copy: %v", src, err)
+		if err := sparseCopy(in, out); err != nil {
+			out.Close()
+			return fmt.Errorf("copy %s to %s: %w", src, dst, err)
+		}
+	}
+
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
+
+func sparseCopy(in, out *os.File) error {
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	zeroes := make([]byte, copyChunkSize)
+	buf := make([]byte, copyChunkSize)
+	var offset int64
+	for {
+		n, err := io.ReadFull(in, buf)
+		if n > 0 && !bytes.Equal(buf[:n], zeroes[:n]) {
+			if _, err := out.WriteAt(buf[:n], offset); err != nil {
+				return err
+			}
+		}
+		offset += int64(n)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return out.Truncate(info.Size())
+}
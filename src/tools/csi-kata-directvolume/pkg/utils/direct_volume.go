@@ -17,10 +17,11 @@ import (
 )
 
 const (
-	mountInfoFileName        = "mountInfo.json"
-	kataDirectVolumeRootPath = "/run/kata-containers/shared/direct-volumes"
+	mountInfoFileName = "mountInfo.json"
 )
 
+var kataDirectVolumeRootPath = "/run/kata-containers/shared/direct-volumes"
+
 // MountInfo contains the information needed by Kata to consume a host block device and mount it as a filesystem inside the guest VM.
 type MountInfo struct {
 	// The type of the volume (ie. block)
@@ -63,3 +64,36 @@ func Add(volumePath string, mountInfo string) error {
 func Remove(volumePath string) error {
 	return os.RemoveAll(filepath.Join(kataDirectVolumeRootPath, b64.URLEncoding.EncodeToString([]byte(volumePath))))
 }
+
+// VolumeMountInfo retrieves the mount info of a direct volume.
+func VolumeMountInfo(volumePath string) (*MountInfo, error) {
+	mountInfoFilePath := filepath.Join(kataDirectVolumeRootPath, b64.URLEncoding.EncodeToString([]byte(volumePath)), mountInfoFileName)
+	if _, err := os.Stat(mountInfoFilePath); err != nil {
+		return nil, err
+	}
+	buf, err := os.ReadFile(mountInfoFilePath)
+	if err != nil {
+		return nil, err
+	}
+	var mountInfo MountInfo
+	if err := json.Unmarshal(buf, &mountInfo); err != nil {
+		return nil, err
+	}
+	return &mountInfo, nil
+}
+
+// GetSandboxIDForVolume returns the id of the sandbox using the direct volume.
+func GetSandboxIDForVolume(volumePath string) (string, error) {
+	files, err := os.ReadDir(filepath.Join(kataDirectVolumeRootPath, b64.URLEncoding.EncodeToString([]byte(volumePath))))
+	if err != nil {
+		return "", err
+	}
+	// Find the id of the first sandbox.
+	// We expect a direct-assigned volume is associated with only a sandbox at a time.
+	for _, file := range files {
+		if file.Name() != mountInfoFileName {
+			return file.Name(), nil
+		}
+	}
+	return "", fmt.Errorf("no sandbox found for %s", volumePath)
+}
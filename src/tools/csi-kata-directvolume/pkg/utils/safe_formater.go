@@ -130,3 +130,35 @@ func (mounter *SafeMountFormater) SafeFormatWithFstype(source string, fstype str
 
 	return nil
 }
+
+// ResizeOffline grows the filesystem of the unmounted disk to the size of the
+// disk. Only the ext filesystems can be grown offline, an unformatted disk is
+// formatted at its full size when it is staged.
+func (mounter *SafeMountFormater) ResizeOffline(source string) error {
+	existingFormat, err := mounter.GetDiskFormat(source)
+	if err != nil {
+		return fmt.Errorf("failed to get disk format of disk %s: %v", source, err)
+	}
+
+	switch existingFormat {
+	case "":
+		return nil
+	case "ext2", "ext3", "ext4":
+	default:
+		return status.Errorf(codes.FailedPrecondition, "%s filesystem of disk %s cannot be resized offline", existingFormat, source)
+	}
+
+	// resize2fs requires the filesystem to be checked since it was last mounted.
+	if output, err := mounter.Exec.Command("e2fsck", "-f", "-p", source).CombinedOutput(); err != nil {
+		ee, isExitError := err.(utilexec.ExitError)
+		if !isExitError || ee.ExitStatus() != fsckErrorsCorrected {
+			return fmt.Errorf("e2fsck of disk %s failed: %v, output: %s", source, err, string(output))
+		}
+	}
+	if output, err := mounter.Exec.Command("resize2fs", source).CombinedOutput(); err != nil {
+		return fmt.Errorf("resize2fs of disk %s failed: %v, output: %s", source, err, string(output))
+	}
+
+	klog.Infof("Disk %s filesystem resized offline", source)
+	return nil
+}
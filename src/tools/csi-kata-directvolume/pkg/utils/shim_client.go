@@ -0,0 +1,165 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// The direct volume endpoints of the shim management server, see
+// src/runtime/pkg/containerd-shim-v2/shim_management.go.
+const (
+	DirectVolumePathKey   = "path"
+	DirectVolumeStatURL   = "/direct-volume/stats"
+	DirectVolumeResizeURL = "/direct-volume/resize"
+)
+
+const shimSocketName = "shim-monitor.sock"
+
+// shimTimeout is the timeout of the requests to the shim management server.
+const shimTimeout = 10 * time.Second
+
+// shimSandboxesPaths are the paths where the go and rust runtimes store the
+// sandboxes, and their shim management sockets.
+var shimSandboxesPaths = []string{"/run/vc/sbs", "/run/kata"}
+
+// ResizeRequest is the request to the shim to resize a direct volume.
+type ResizeRequest struct {
+	VolumePath string
+	Size       uint64
+}
+
+// The units of the volume usage, as the csi.VolumeUsage_Unit values.
+const (
+	VolumeUsageBytes  int32 = 1
+	VolumeUsageInodes int32 = 2
+)
+
+// VolumeUsage is the usage of a volume in the guest.
+type VolumeUsage struct {
+	Available uint64 `json:"available,omitempty"`
+	Total     uint64 `json:"total,omitempty"`
+	Used      uint64 `json:"used,omitempty"`
+	Unit      int32  `json:"unit,omitempty"`
+}
+
+// VolumeCondition is the condition of a volume in the guest.
+type VolumeCondition struct {
+	Abnormal bool   `json:"abnormal,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// VolumeStats are the stats of a direct volume returned by the shim, the
+// JSON encoding of the agent VolumeStatsResponse.
+type VolumeStats struct {
+	Usage           []VolumeUsage    `json:"usage,omitempty"`
+	VolumeCondition *VolumeCondition `json:"volume_condition,omitempty"`
+}
+
+// shimSocketPath returns the path of the shim management socket of the sandbox.
+func shimSocketPath(sandboxID string) (string, error) {
+	for _, dir := range shimSandboxesPaths {
+		socketPath := filepath.Join(dir, sandboxID, shimSocketName)
+		if _, err := os.Stat(socketPath); err == nil {
+			return socketPath, nil
+		}
+	}
+	return "", fmt.Errorf("no shim management socket found for sandbox %s", sandboxID)
+}
+
+// buildShimClient returns an http client talking to the shim of the sandbox.
+func buildShimClient(sandboxID string, timeout time.Duration) (*http.Client, error) {
+	socketPath, err := shimSocketPath(sandboxID)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		DisableKeepAlives: true,
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socketPath)
+		},
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
+
+// ResizeGuestVolume asks the shim of the sandbox using the direct volume
+// mounted at volumePath to resize the volume in the guest.
+func ResizeGuestVolume(sandboxID, volumePath string, size uint64) error {
+	mountInfo, err := VolumeMountInfo(volumePath)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(ResizeRequest{
+		VolumePath: mountInfo.Device,
+		Size:       size,
+	})
+	if err != nil {
+		return err
+	}
+
+	client, err := buildShimClient(sandboxID, shimTimeout)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Post(fmt.Sprintf("http://shim%s", DirectVolumeResizeURL), "application/json", bytes.NewBuffer(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("error sending post: url: %s, status code: %d, response data: %s", DirectVolumeResizeURL, resp.StatusCode, string(data))
+	}
+	return nil
+}
+
+// GuestVolumeStats asks the shim of the sandbox using the direct volume
+// mounted at volumePath for the stats of the volume in the guest.
+func GuestVolumeStats(sandboxID, volumePath string) (*VolumeStats, error) {
+	mountInfo, err := VolumeMountInfo(volumePath)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := buildShimClient(sandboxID, shimTimeout)
+	if err != nil {
+		return nil, err
+	}
+	urlPath := fmt.Sprintf("%s?%s=%s", DirectVolumeStatURL, DirectVolumePathKey, url.PathEscape(mountInfo.Device))
+	resp, err := client.Get(fmt.Sprintf("http://shim%s", urlPath))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error sending get: url: %s, status code: %d, response data: %s", DirectVolumeStatURL, resp.StatusCode, string(data))
+	}
+
+	var stats VolumeStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2026 Kata Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package utils
+
+import (
+	b64 "encoding/base64"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShimClient(t *testing.T) {
+	tmp := t.TempDir()
+
+	oldRoot := kataDirectVolumeRootPath
+	kataDirectVolumeRootPath = filepath.Join(tmp, "direct-volumes")
+	defer func() { kataDirectVolumeRootPath = oldRoot }()
+
+	oldPaths := shimSandboxesPaths
+	shimSandboxesPaths = []string{filepath.Join(tmp, "vc"), filepath.Join(tmp, "kata")}
+	defer func() { shimSandboxesPaths = oldPaths }()
+
+	volumePath := filepath.Join(tmp, "target")
+	require.NoError(t, AddDirectVolume(volumePath, MountInfo{
+		VolumeType: DirectVolumeTypeName,
+		Device:     "/dev/vda",
+		FsType:     "ext4",
+	}))
+
+	// The volume is not used by a sandbox yet
+	_, err := GetSandboxIDForVolume(volumePath)
+	require.Error(t, err)
+
+	volumeDir := filepath.Join(kataDirectVolumeRootPath, b64.URLEncoding.EncodeToString([]byte(volumePath)))
+	require.NoError(t, os.WriteFile(filepath.Join(volumeDir, "sandbox"), nil, 0o600))
+	sandboxID, err := GetSandboxIDForVolume(volumePath)
+	require.NoError(t, err)
+	require.Equal(t, "sandbox", sandboxID)
+
+	// The shim of the sandbox is not running
+	require.Error(t, ResizeGuestVolume(sandboxID, volumePath, 4<<20))
+
+	// The shim of the rust runtime is found as well
+	socketPath := filepath.Join(tmp, "kata", sandboxID, shimSocketName)
+	require.NoError(t, os.MkdirAll(filepath.Dir(socketPath), 0o750))
+	l, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	requests := make(chan ResizeRequest, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc(DirectVolumeResizeURL, func(w http.ResponseWriter, r *http.Request) {
+		var req ResizeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Size == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		requests <- req
+	})
+	mux.HandleFunc(DirectVolumeStatURL, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get(DirectVolumePathKey) != "/dev/vda" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"usage":[{"available":3,"total":4,"used":1,"unit":1},{"total":8,"used":8,"unit":2}],"volume_condition":{"message":"OK"}}`))
+	})
+	server := &http.Server{Handler: mux}
+	go server.Serve(l)
+	defer server.Close()
+
+	require.NoError(t, ResizeGuestVolume(sandboxID, volumePath, 4<<20))
+	require.Equal(t, ResizeRequest{VolumePath: "/dev/vda", Size: 4 << 20}, <-requests)
+
+	require.Error(t, ResizeGuestVolume(sandboxID, volumePath, 0))
+
+	stats, err := GuestVolumeStats(sandboxID, volumePath)
+	require.NoError(t, err)
+	require.Equal(t, &VolumeStats{
+		Usage: []VolumeUsage{
+			{Available: 3, Total: 4, Used: 1, Unit: VolumeUsageBytes},
+			{Total: 8, Used: 8, Unit: VolumeUsageInodes},
+		},
+		VolumeCondition: &VolumeCondition{Message: "OK"},
+	}, stats)
+}
@@ -176,6 +176,12 @@ func GetStoragePath(storagePath, volID string) (string, error) {
 	return upperPath, nil
 }
 
+// GetDirectBlockDevicePath returns the path of the raw disk of the direct volume.
+// storagePath/62a268d9-893a-11ee-97cb-d89d6725e7b0/directvol-rawdisk.2048M
+func GetDirectBlockDevicePath(storagePath, volID string, capacityInBytes int64) string {
+	return filepath.Join(storagePath, volID, fmt.Sprintf("directvol-rawdisk.%dM", capacityInBytes/MiB))
+}
+
 // createVolume create the directory for the direct volume.
 // It returns the volume path or err if one occurs.
 func CreateDirectBlockDevice(volID, capacityInBytesStr, storagePath string) (*string, error) {
@@ -187,6 +193,7 @@ func CreateDirectBlockDevice(volID, capacityInBytesStr, storagePath string) (*st
 	}
 
 	diskSize := fmt.Sprintf("%dM", capacityInBytes/MiB)
+	devicePath := GetDirectBlockDevicePath(storagePath, volID, capacityInBytes)
 	upperDir, err := SetupStoragePath(storagePath, volID)
 	if err != nil {
 		klog.Errorf("setup storage path failed with error: %v", err)
@@ -198,8 +205,6 @@ func CreateDirectBlockDevice(volID, capacityInBytesStr, storagePath string) (*st
 		}
 	}
 
-	// storagePath/62a268d9-893a-11ee-97cb-d89d6725e7b0/directvol-rawdisk.2048M
-	devicePath := filepath.Join(*upperDir, fmt.Sprintf("directvol-rawdisk.%s", diskSize))
 	if _, err = os.Stat(devicePath); !os.IsNotExist(err) {
 		klog.Warning("direct block device exists, just skip creating it.")
 		return &devicePath, nil
@@ -15,6 +15,7 @@ default GetDiagnosticDataRequest := false
 # Default values, returned by OPA when rules cannot be evaluated to true.
 default AddARPNeighborsRequest := false
 default AddSwapRequest := false
+default CloneDirectoryRequest := false
 default CloseStdinRequest := false
 default CopyFileRequest := false
 default CreateContainerRequest := false
@@ -30,6 +31,7 @@ default OnlineCPUMemRequest := true
 default PauseContainerRequest := false
 default ReadStreamRequest := false
 default RemoveContainerRequest := true
+default RemovePathRequest := false
 default RemoveStaleVirtiofsShareMountsRequest := true
 default ReseedRandomDevRequest := false
 default ResumeContainerRequest := false
@@ -1584,6 +1586,48 @@ allow_copy_file if {
     print("allow_copy_file symlink: true")
 }
 
+allow_copy_file if {
+    print("allow_copy_file volume key")
+
+    input.file_type == "Regular"
+    allow_volume_key_path(input.path)
+
+    print("allow_copy_file volume key: true")
+}
+
+CloneDirectoryRequest if {
+    print("CloneDirectoryRequest: input =", input)
+
+    # Both directories are below the top-level of the shared directory, from which we mount.
+    allow_copy_file_path(input.path, ".*/.+")
+    allow_copy_file_path(input.source, ".*/.+")
+
+    print("CloneDirectoryRequest: true")
+}
+
+RemovePathRequest if {
+    print("RemovePathRequest: input =", input)
+
+    # The top-level of the shared directory, from which we mount, is not removed.
+    allow_copy_file_path(input.path, ".*/.+")
+
+    print("RemovePathRequest: true")
+}
+
+RemovePathRequest if {
+    print("RemovePathRequest volume key: input =", input)
+
+    allow_volume_key_path(input.path)
+
+    print("RemovePathRequest volume key: true")
+}
+
+# The keys of the encrypted volumes are named after the base64url encoded
+# volume source, directly in the keys directory.
+allow_volume_key_path(path) if {
+    regex.match(concat("", ["^", policy_data.common.kpath, "/[A-Za-z0-9_=-]+$"]), path)
+}
+
 allow_copy_file_path(path, regex_suffix) if {
     check_directory_traversal(path)
 
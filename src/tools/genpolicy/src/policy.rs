@@ -432,6 +432,9 @@ pub struct CommonData {
     /// Path to the shared sandbox storage - e.g., "/run/kata-containers/sandbox/storage".
     pub spath: String,
 
+    /// Path to the keys of the encrypted volumes - e.g., "/run/kata-containers/sandbox/keys".
+    pub kpath: String,
+
     /// Regex for an IPv4 address.
     pub ipv4_a: String,
 
@@ -12,8 +12,9 @@ mod tests {
     use std::str;
 
     use protocols::agent::{
-        AddARPNeighborsRequest, CreateContainerRequest, CreateSandboxRequest, ExecProcessRequest,
-        RemoveContainerRequest, UpdateInterfaceRequest, UpdateRoutesRequest,
+        AddARPNeighborsRequest, CloneDirectoryRequest, CreateContainerRequest,
+        CreateSandboxRequest, ExecProcessRequest, RemoveContainerRequest, RemovePathRequest,
+        UpdateInterfaceRequest, UpdateRoutesRequest,
     };
     use serde::{Deserialize, Serialize};
 
@@ -26,6 +27,8 @@ mod tests {
     #[allow(clippy::enum_variant_names)] // The tags need to match the entrypoint logged by the agent.
     enum TestRequest {
         CopyFileRequest(PolicyCopyFileRequest),
+        CloneDirectoryRequest(CloneDirectoryRequest),
+        RemovePathRequest(RemovePathRequest),
         CreateContainerRequest(CreateContainerRequest),
         CreateSandboxRequest(CreateSandboxRequest),
         ExecProcessRequest(ExecProcessRequest),
@@ -39,6 +42,8 @@ mod tests {
         fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
             match self {
                 TestRequest::CopyFileRequest(_) => write!(f, "CopyFileRequest"),
+                TestRequest::CloneDirectoryRequest(_) => write!(f, "CloneDirectoryRequest"),
+                TestRequest::RemovePathRequest(_) => write!(f, "RemovePathRequest"),
                 TestRequest::CreateContainerRequest(_) => write!(f, "CreateContainerRequest"),
                 TestRequest::CreateSandboxRequest(_) => write!(f, "CreateSandboxRequest"),
                 TestRequest::ExecProcessRequest(_) => write!(f, "ExecProcessRequest"),